@@ -17,7 +17,17 @@ func main() {
 		SilenceUsage: true,
 	}
 	rootCmd.AddCommand(
+		newChangelogCmd(),
 		newLintCmd(),
+		newMetadataCmd(),
+		newRepoCmd(),
+		newSearchCmd(),
+		newSecurityReportCmd(),
+		newShowCmd(),
+		newSubscribeCmd(),
+		newUnsubscribeCmd(),
+		newValuesCmd(),
+		newVerifyCmd(),
 		newVersionCmd(),
 	)
 
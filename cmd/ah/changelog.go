@@ -0,0 +1,366 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// conventionalCommitRE is a regexp used to parse the subject of commits
+	// following the conventional commits specification.
+	conventionalCommitRE = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s+(.+)$`)
+
+	// pullRequestRE is a regexp used to extract the pull request number
+	// referenced in a commit subject, like the ones added by GitHub when
+	// squashing pull requests.
+	pullRequestRE = regexp.MustCompile(`\s*\(#(\d+)\)`)
+
+	// changeKindsOrder represents the order in which changes are listed,
+	// grouped by kind.
+	changeKindsOrder = []string{"added", "changed", "deprecated", "removed", "fixed", "security"}
+
+	// commitTypesChangeKinds maps conventional commits types to changes kinds.
+	// Commits of types not included here are not part of the changelog.
+	commitTypesChangeKinds = map[string]string{
+		"feat":      "added",
+		"change":    "changed",
+		"perf":      "changed",
+		"refactor":  "changed",
+		"deprecate": "deprecated",
+		"remove":    "removed",
+		"revert":    "removed",
+		"fix":       "fixed",
+		"security":  "security",
+		"sec":       "security",
+	}
+
+	// errNoMetadataFound indicates that no file where the changes can be
+	// written was found in the package's path.
+	errNoMetadataFound = errors.New("no Chart.yaml or artifacthub-pkg.yml file found in package path")
+)
+
+// changelogOptions represents the options that can be passed to the changelog
+// command.
+type changelogOptions struct {
+	path    string
+	since   string
+	until   string
+	repoURL string
+	dryRun  bool
+}
+
+// newChangelogCmd creates a new changelog command.
+func newChangelogCmd() *cobra.Command {
+	opts := &changelogOptions{}
+	changelogCmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "Generate a package's changes from its git history",
+		Long: `Generate a package's changes from its git history
+
+The conventional commits that modified the package's path between the refs
+provided are mapped to changes, which are written to the artifacthub.io/changes
+annotation of the Chart.yaml file (Helm charts) or to the changes field of the
+artifacthub-pkg.yml file. Commits that do not follow the conventional commits
+specification, or whose types are not relevant for users (docs, chore, ci,
+test, etc), are ignored.
+
+Commit types are mapped to changes kinds as follows:
+
+  feat                                added
+  change, perf, refactor              changed
+  deprecate                           deprecated
+  remove, revert                      removed
+  fix                                 fixed
+  security, sec, fix(security)        security`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return changelog(opts, cmd.OutOrStdout())
+		},
+	}
+	changelogCmd.Flags().StringVarP(&opts.path, "path", "p", ".", "package's path")
+	changelogCmd.Flags().StringVar(&opts.since, "since", "", "git ref (usually the tag of the previous version) from which changes will be collected")
+	changelogCmd.Flags().StringVar(&opts.until, "until", "HEAD", "git ref until which changes will be collected")
+	changelogCmd.Flags().StringVar(&opts.repoURL, "repo-url", "", "repository url used to build the pull requests links (origin remote by default)")
+	changelogCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "print the changes without writing them")
+	_ = changelogCmd.MarkFlagRequired("since")
+	return changelogCmd
+}
+
+// changelog collects the changes introduced in the package provided between
+// the refs provided, writing them in the package's metadata.
+func changelog(opts *changelogOptions, w io.Writer) error {
+	// Locate file where changes will be written
+	mdFile, err := getChangesFile(opts.path)
+	if err != nil {
+		return err
+	}
+
+	// Collect changes from git history
+	changes, err := getChangesFromGit(opts)
+	if err != nil {
+		return err
+	}
+
+	// Print preview
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "No changes found since %s\n", opts.since)
+		return nil
+	}
+	fmt.Fprintf(w, "Changes since %s (%d):\n\n", opts.since, len(changes))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, change := range changes {
+		description := change.Description
+		if len(change.Links) > 0 {
+			links := make([]string, 0, len(change.Links))
+			for _, link := range change.Links {
+				links = append(links, link.Name)
+			}
+			description += fmt.Sprintf(" (%s)", strings.Join(links, ", "))
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", change.Kind, description)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if opts.dryRun {
+		return nil
+	}
+
+	// Write changes
+	if err := writeChanges(mdFile, changes); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nChanges written to %s\n", mdFile)
+	return nil
+}
+
+// getChangesFile returns the file where the changes of the package available
+// in the path provided must be written.
+func getChangesFile(pkgPath string) (string, error) {
+	for _, name := range []string{
+		"Chart.yaml",
+		hub.PackageMetadataFile + ".yml",
+		hub.PackageMetadataFile + ".yaml",
+	} {
+		file := filepath.Join(pkgPath, name)
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		}
+	}
+	return "", errNoMetadataFound
+}
+
+// getChangesFromGit returns the changes found in the conventional commits that
+// modified the package's path between the refs provided.
+func getChangesFromGit(opts *changelogOptions) ([]*hub.Change, error) {
+	// Open git repository and resolve refs
+	absPath, err := filepath.Abs(opts.path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := git.PlainOpenWithOptions(absPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("error opening git repository: %w", err)
+	}
+	since, err := r.ResolveRevision(plumbing.Revision(opts.since))
+	if err != nil {
+		return nil, fmt.Errorf("error resolving ref %s: %w", opts.since, err)
+	}
+	until, err := r.ResolveRevision(plumbing.Revision(opts.until))
+	if err != nil {
+		return nil, fmt.Errorf("error resolving ref %s: %w", opts.until, err)
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("error getting git worktree: %w", err)
+	}
+	pkgPath, err := filepath.Rel(wt.Filesystem.Root(), absPath)
+	if err != nil {
+		return nil, err
+	}
+	pkgPath = filepath.ToSlash(pkgPath)
+	repoURL := opts.repoURL
+	if repoURL == "" {
+		repoURL = getOriginURL(r)
+	}
+
+	// Commits already included in the since ref are skipped
+	seen := make(map[plumbing.Hash]struct{})
+	iter, err := r.Log(&git.LogOptions{From: *since})
+	if err != nil {
+		return nil, fmt.Errorf("error reading git log: %w", err)
+	}
+	err = iter.ForEach(func(c *object.Commit) error {
+		seen[c.Hash] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading git log: %w", err)
+	}
+
+	// Collect changes from commits that modified the package's path
+	logOpts := &git.LogOptions{From: *until}
+	if pkgPath != "." {
+		logOpts.PathFilter = func(p string) bool {
+			return p == pkgPath || strings.HasPrefix(p, pkgPath+"/")
+		}
+	}
+	iter, err = r.Log(logOpts)
+	if err != nil {
+		return nil, fmt.Errorf("error reading git log: %w", err)
+	}
+	var changes []*hub.Change
+	err = iter.ForEach(func(c *object.Commit) error {
+		if _, ok := seen[c.Hash]; ok {
+			return nil
+		}
+		if change := parseConventionalCommit(c.Message, repoURL); change != nil {
+			changes = append(changes, change)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading git log: %w", err)
+	}
+
+	// Sort changes chronologically, grouped by kind
+	for i, j := 0, len(changes)-1; i < j; i, j = i+1, j-1 {
+		changes[i], changes[j] = changes[j], changes[i]
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return kindOrder(changes[i].Kind) < kindOrder(changes[j].Kind)
+	})
+	return changes, nil
+}
+
+// parseConventionalCommit returns the change described by the commit message
+// provided, or nil when the commit does not follow the conventional commits
+// specification or its type is not relevant for users.
+func parseConventionalCommit(message, repoURL string) *hub.Change {
+	subject := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+	m := conventionalCommitRE.FindStringSubmatch(subject)
+	if m == nil {
+		return nil
+	}
+	commitType, scope, description := strings.ToLower(m[1]), strings.ToLower(m[2]), m[4]
+	kind, ok := commitTypesChangeKinds[commitType]
+	if !ok {
+		return nil
+	}
+	if commitType == "fix" && scope == "security" {
+		kind = "security"
+	}
+	change := &hub.Change{Kind: kind}
+
+	// Extract pull request reference
+	if pr := pullRequestRE.FindStringSubmatch(description); pr != nil {
+		description = pullRequestRE.ReplaceAllString(description, "")
+		if repoURL != "" {
+			change.Links = append(change.Links, &hub.Link{
+				Name: "PR #" + pr[1],
+				URL:  fmt.Sprintf("%s/pull/%s", repoURL, pr[1]),
+			})
+		}
+	}
+	description = strings.TrimSpace(description)
+	if description != "" {
+		change.Description = strings.ToUpper(description[:1]) + description[1:]
+	}
+	if pkg.ValidateChange(change) != nil {
+		return nil
+	}
+	return change
+}
+
+// getOriginURL returns the https url of the origin remote of the git
+// repository provided, or an empty string when it's not available.
+func getOriginURL(r *git.Repository) string {
+	remote, err := r.Remote(git.DefaultRemoteName)
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	u := remote.Config().URLs[0]
+	if strings.HasPrefix(u, "git@") {
+		u = "https://" + strings.Replace(strings.TrimPrefix(u, "git@"), ":", "/", 1)
+	}
+	if !strings.HasPrefix(u, "https://") {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(u, "/"), ".git")
+}
+
+// kindOrder returns the position of the change kind provided in the changes
+// list.
+func kindOrder(kind string) int {
+	for i, k := range changeKindsOrder {
+		if k == kind {
+			return i
+		}
+	}
+	return len(changeKindsOrder)
+}
+
+// writeChanges writes the changes provided to the Chart.yaml or package
+// metadata file provided, preserving the rest of its content.
+func writeChanges(mdFile string, changes []*hub.Change) error {
+	entries := make([]*changeEntry, 0, len(changes))
+	for _, change := range changes {
+		entries = append(entries, &changeEntry{
+			Kind:        change.Kind,
+			Description: change.Description,
+			Links:       change.Links,
+		})
+	}
+	data, err := os.ReadFile(mdFile)
+	if err != nil {
+		return fmt.Errorf("error reading metadata file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("error parsing metadata file: %w", err)
+	}
+
+	if filepath.Base(mdFile) == "Chart.yaml" {
+		// Helm charts changes are provided using an annotation
+		v, err := yaml.Marshal(entries)
+		if err != nil {
+			return err
+		}
+		if err := setChartAnnotations(&doc, map[string]string{changesAnnotation: string(v)}); err != nil {
+			return err
+		}
+	} else {
+		if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			return errors.New("invalid metadata file")
+		}
+		var v yaml.Node
+		if err := v.Encode(entries); err != nil {
+			return err
+		}
+		setMappingValue(doc.Content[0], "changes", &v)
+	}
+
+	data, err = encodeYAML(&doc)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(mdFile, data, 0644); err != nil {
+		return fmt.Errorf("error writing metadata file: %w", err)
+	}
+	return nil
+}
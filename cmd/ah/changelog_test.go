@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	t.Parallel()
+	repoURL := "https://github.com/org/repo"
+	testCases := []struct {
+		message  string
+		expected *hub.Change
+	}{
+		{"feat: support for ingress", &hub.Change{Kind: "added", Description: "Support for ingress"}},
+		{"feat(chart)!: new values layout\n\nBREAKING CHANGE: ...", &hub.Change{Kind: "added", Description: "New values layout"}},
+		{"fix: crash on startup (#12)", &hub.Change{Kind: "fixed", Description: "Crash on startup", Links: []*hub.Link{
+			{Name: "PR #12", URL: "https://github.com/org/repo/pull/12"},
+		}}},
+		{"fix(security): bump base image", &hub.Change{Kind: "security", Description: "Bump base image"}},
+		{"perf: faster rendering", &hub.Change{Kind: "changed", Description: "Faster rendering"}},
+		{"deprecate: old values", &hub.Change{Kind: "deprecated", Description: "Old values"}},
+		{"revert: feature x", &hub.Change{Kind: "removed", Description: "Feature x"}},
+		{"docs: update readme", nil},
+		{"chore(deps): bump dependency", nil},
+		{"Update chart", nil},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, parseConventionalCommit(tc.message, repoURL), tc.message)
+	}
+}
+
+func TestChangelogCmd(t *testing.T) {
+	// Setup git repository
+	repoPath := t.TempDir()
+	r, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	_, err = r.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{"git@github.com:org/repo.git"},
+	})
+	require.NoError(t, err)
+	wt, err := r.Worktree()
+	require.NoError(t, err)
+	ts := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	commit := func(file, content, message, tag string) {
+		t.Helper()
+		p := filepath.Join(repoPath, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0600))
+		_, err := wt.Add(file)
+		require.NoError(t, err)
+		ts = ts.Add(time.Hour)
+		h, err := wt.Commit(message, &git.CommitOptions{
+			Author: &object.Signature{Name: "user", Email: "user@email.com", When: ts},
+		})
+		require.NoError(t, err)
+		if tag != "" {
+			_, err = r.CreateTag(tag, h, nil)
+			require.NoError(t, err)
+		}
+	}
+	commit("chart/Chart.yaml", "# Chart\napiVersion: v2\nname: chart\nversion: 1.0.0\n", "chore: initial chart", "chart-1.0.0")
+	commit("pkg/artifacthub-pkg.yml", "version: 1.0.0\nname: pkg\n", "feat: initial package", "pkg-1.0.0")
+	commit("chart/templates/ingress.yaml", "kind: Ingress\n", "feat: support for ingress (#10)", "")
+	commit("chart/README.md", "# Chart\n", "docs: add readme", "")
+	commit("chart/templates/deployment.yaml", "kind: Deployment\n", "fix(security): run as non root (#11)", "")
+	commit("chart/values.yaml", "replicas: 1\n", "fix: default replicas", "chart-1.1.0")
+	commit("pkg/policy.rego", "package main\n", "fix: policy typo", "")
+	commit("chart/templates/service.yaml", "kind: Service\n", "feat: add service", "")
+
+	t.Run("dry run", func(t *testing.T) {
+		var b bytes.Buffer
+		opts := &changelogOptions{
+			path:   filepath.Join(repoPath, "chart"),
+			since:  "chart-1.0.0",
+			until:  "HEAD",
+			dryRun: true,
+		}
+		require.NoError(t, changelog(opts, &b))
+		assert.Equal(t, `Changes since chart-1.0.0 (4):
+
+  added     Support for ingress (PR #10)
+  added     Add service
+  fixed     Default replicas
+  security  Run as non root (PR #11)
+`, b.String())
+		data, err := os.ReadFile(filepath.Join(repoPath, "chart", "Chart.yaml"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "annotations")
+	})
+
+	t.Run("changes annotation written", func(t *testing.T) {
+		var b bytes.Buffer
+		opts := &changelogOptions{
+			path:  filepath.Join(repoPath, "chart"),
+			since: "chart-1.0.0",
+			until: "chart-1.1.0",
+		}
+		require.NoError(t, changelog(opts, &b))
+		var md struct {
+			Annotations map[string]string `yaml:"annotations"`
+		}
+		data, err := os.ReadFile(filepath.Join(repoPath, "chart", "Chart.yaml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "# Chart\n")
+		require.NoError(t, yaml.Unmarshal(data, &md))
+		var changes []*hub.Change
+		require.NoError(t, yaml.Unmarshal([]byte(md.Annotations[changesAnnotation]), &changes))
+		assert.Len(t, changes, 3)
+		assert.Equal(t, "https://github.com/org/repo/pull/10", changes[0].Links[0].URL)
+	})
+
+	t.Run("changes field written", func(t *testing.T) {
+		var b bytes.Buffer
+		opts := &changelogOptions{
+			path:    filepath.Join(repoPath, "pkg"),
+			since:   "pkg-1.0.0",
+			until:   "HEAD",
+			repoURL: "https://github.com/org/other",
+		}
+		require.NoError(t, changelog(opts, &b))
+		data, err := os.ReadFile(filepath.Join(repoPath, "pkg", "artifacthub-pkg.yml"))
+		require.NoError(t, err)
+		assert.Equal(t, `version: 1.0.0
+name: pkg
+changes:
+  - kind: fixed
+    description: Policy typo
+`, string(data))
+	})
+
+	t.Run("no metadata file found", func(t *testing.T) {
+		opts := &changelogOptions{path: repoPath, since: "chart-1.0.0", until: "HEAD"}
+		err := changelog(opts, &bytes.Buffer{})
+		assert.Equal(t, errNoMetadataFound, err)
+	})
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// defaultHubURL represents the base url of the hub used by default by the
+	// client commands.
+	defaultHubURL = "https://artifacthub.io"
+
+	// Environment variables that can be used to configure the client commands.
+	hubURLEnvVar       = "AH_HUB_URL"
+	apiKeyIDEnvVar     = "AH_API_KEY_ID"
+	apiKeySecretEnvVar = "AH_API_KEY_SECRET" // #nosec
+
+	// clientRequestsTimeout represents the timeout used in the requests to
+	// the hub API.
+	clientRequestsTimeout = 30 * time.Second
+)
+
+var (
+	// errAPIKeyRequired indicates that the operation requested requires an
+	// API key and it was not provided.
+	errAPIKeyRequired = errors.New("an api key is required for this operation (see --api-key-id and --api-key-secret)")
+
+	// errInvalidPackageRef indicates that the package reference provided is
+	// not valid.
+	errInvalidPackageRef = errors.New("invalid package reference (expected format: kind/repo/package[@version])")
+)
+
+// hubClientOptions represents the options used to setup a hub client.
+type hubClientOptions struct {
+	// url represents the base url of the hub.
+	url string
+
+	// apiKeyID represents the id of the API key used to authenticate requests.
+	apiKeyID string
+
+	// apiKeySecret represents the secret of the API key used to authenticate
+	// requests.
+	apiKeySecret string
+}
+
+// addFlags adds the flags used to setup the hub client to the flag set
+// provided. Environment variables are used as default values when available.
+func (o *hubClientOptions) addFlags(fs *pflag.FlagSet) {
+	hubURL := os.Getenv(hubURLEnvVar)
+	if hubURL == "" {
+		hubURL = defaultHubURL
+	}
+	fs.StringVar(&o.url, "hub-url", hubURL, "hub base url (env: "+hubURLEnvVar+")")
+	fs.StringVar(&o.apiKeyID, "api-key-id", os.Getenv(apiKeyIDEnvVar), "API key id (env: "+apiKeyIDEnvVar+")")
+	fs.StringVar(&o.apiKeySecret, "api-key-secret", os.Getenv(apiKeySecretEnvVar), "API key secret (env: "+apiKeySecretEnvVar+")")
+}
+
+// hubClient is a client for the hub HTTP API.
+type hubClient struct {
+	baseURL      string
+	apiKeyID     string
+	apiKeySecret string
+	hc           hub.HTTPClient
+}
+
+// newHubClient creates a new hubClient instance.
+func newHubClient(opts *hubClientOptions) *hubClient {
+	return &hubClient{
+		baseURL:      strings.TrimSuffix(opts.url, "/"),
+		apiKeyID:     opts.apiKeyID,
+		apiKeySecret: opts.apiKeySecret,
+		hc:           &http.Client{Timeout: clientRequestsTimeout},
+	}
+}
+
+// requireAPIKey returns an error if the client has not been provided an API
+// key.
+func (c *hubClient) requireAPIKey() error {
+	if c.apiKeyID == "" || c.apiKeySecret == "" {
+		return errAPIKeyRequired
+	}
+	return nil
+}
+
+// do sends a request to the hub API endpoint provided, returning the response
+// body. When a body is provided, it is encoded as JSON.
+func (c *hubClient) do(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	body interface{},
+) ([]byte, error) {
+	// Prepare request
+	u := c.baseURL + "/api/v1" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKeyID != "" && c.apiKeySecret != "" {
+		req.Header.Set(hub.APIKeyIDHeader, c.apiKeyID)
+		req.Header.Set(hub.APIKeySecretHeader, c.apiKeySecret)
+	}
+
+	// Do request and process response
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(data, &apiErr); err == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("%s (status code: %d)", apiErr.Message, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
+	}
+	return data, nil
+}
+
+// getPackage returns the package identified by the reference provided.
+func (c *hubClient) getPackage(ctx context.Context, ref *packageRef) (*hub.Package, []byte, error) {
+	path := fmt.Sprintf("/packages/%s/%s/%s",
+		url.PathEscape(ref.kind),
+		url.PathEscape(ref.repo),
+		url.PathEscape(ref.name),
+	)
+	if ref.version != "" {
+		path += "/" + url.PathEscape(ref.version)
+	}
+	data, err := c.do(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting package: %w", err)
+	}
+	var p *hub.Package
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, nil, fmt.Errorf("error decoding package: %w", err)
+	}
+	return p, data, nil
+}
+
+// packageRef represents a reference to a package in the hub, using the format
+// kind/repo/package[@version].
+type packageRef struct {
+	kind    string
+	repo    string
+	name    string
+	version string
+}
+
+// parsePackageRef parses the package reference provided.
+func parsePackageRef(s string) (*packageRef, error) {
+	ref := &packageRef{}
+	if i := strings.LastIndex(s, "@"); i != -1 {
+		ref.version = s[i+1:]
+		s = s[:i]
+		if ref.version == "" {
+			return nil, errInvalidPackageRef
+		}
+	}
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 {
+		return nil, errInvalidPackageRef
+	}
+	for _, part := range parts {
+		if part == "" {
+			return nil, errInvalidPackageRef
+		}
+	}
+	if _, err := hub.GetKindFromName(parts[0]); err != nil {
+		return nil, err
+	}
+	ref.kind, ref.repo, ref.name = parts[0], parts[1], parts[2]
+	return ref, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (r *packageRef) String() string {
+	s := fmt.Sprintf("%s/%s/%s", r.kind, r.repo, r.name)
+	if r.version != "" {
+		s += "@" + r.version
+	}
+	return s
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPkgID   = "00000000-0000-0000-0000-000000000001"
+	testPkgJSON = `{
+	"package_id": "00000000-0000-0000-0000-000000000001",
+	"name": "pkg1",
+	"version": "1.0.0",
+	"app_version": "2.0.0",
+	"description": "Package 1",
+	"license": "Apache-2.0",
+	"available_versions": [{"version": "1.0.0"}, {"version": "0.9.0"}],
+	"repository": {"kind": 0, "name": "repo1", "url": "https://repo1.url"}
+}`
+)
+
+func TestParsePackageRef(t *testing.T) {
+	t.Run("valid references", func(t *testing.T) {
+		t.Parallel()
+		ref, err := parsePackageRef("helm/repo1/pkg1")
+		require.NoError(t, err)
+		assert.Equal(t, &packageRef{kind: "helm", repo: "repo1", name: "pkg1"}, ref)
+
+		ref, err = parsePackageRef("helm/repo1/pkg1@1.0.0")
+		require.NoError(t, err)
+		assert.Equal(t, &packageRef{kind: "helm", repo: "repo1", name: "pkg1", version: "1.0.0"}, ref)
+		assert.Equal(t, "helm/repo1/pkg1@1.0.0", ref.String())
+	})
+
+	t.Run("invalid references", func(t *testing.T) {
+		t.Parallel()
+		for _, s := range []string{"", "helm/repo1", "helm/repo1/pkg1/extra", "helm//pkg1", "helm/repo1/pkg1@"} {
+			_, err := parsePackageRef(s)
+			assert.ErrorIs(t, err, errInvalidPackageRef, s)
+		}
+		_, err := parsePackageRef("invalid/repo1/pkg1")
+		assert.Error(t, err)
+	})
+}
+
+func TestClientCmds(t *testing.T) {
+	// Setup hub API
+	var lastReq *http.Request
+	var lastReqBody []byte
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/packages/search", func(w http.ResponseWriter, r *http.Request) {
+		lastReq = r
+		fmt.Fprintf(w, `{"packages": [%s]}`, testPkgJSON)
+	})
+	mux.HandleFunc("/api/v1/packages/helm/repo1/pkg1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testPkgJSON)
+	})
+	mux.HandleFunc("/api/v1/packages/helm/repo1/pkg2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "not found"}`)
+	})
+	mux.HandleFunc("/api/v1/packages/"+testPkgID+"/1.0.0/values", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "key: value\n")
+	})
+	mux.HandleFunc("/api/v1/packages/"+testPkgID+"/1.0.0/values/validate", func(w http.ResponseWriter, r *http.Request) {
+		var input *hub.ValuesValidationInput
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		if input.Values == "key: value\n" {
+			fmt.Fprint(w, `{"valid": true, "errors": [], "warnings": []}`)
+			return
+		}
+		fmt.Fprint(w, `{
+			"valid": false,
+			"errors": [{"pointer": "/key", "message": "Invalid type. Expected: string, given: integer"}],
+			"warnings": [{"pointer": "/other", "message": "unknown key other"}]
+		}`)
+	})
+	mux.HandleFunc("/api/v1/packages/"+testPkgID+"/1.0.0/security-report", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"image1:1.0.0": {"Results": [{"Target": "target1", "Vulnerabilities": [{
+			"VulnerabilityID": "CVE-2022-0001",
+			"PkgName": "pkg",
+			"InstalledVersion": "1.0",
+			"FixedVersion": "1.1",
+			"Severity": "HIGH"
+		}]}]}}`)
+	})
+	mux.HandleFunc("/api/v1/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		lastReq = r
+		lastReqBody, _ = io.ReadAll(r.Body)
+		if r.Header.Get(hub.APIKeyIDHeader) != "keyID" || r.Header.Get(hub.APIKeySecretHeader) != "keySecret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	// Helper to run commands against the hub API
+	run := func(t *testing.T, cmd interface {
+		SetArgs([]string)
+		SetOut(io.Writer)
+		Execute() error
+	}, args ...string) (string, error) {
+		t.Helper()
+		var b bytes.Buffer
+		cmd.SetOut(&b)
+		cmd.SetArgs(append(args, "--hub-url", s.URL))
+		err := cmd.Execute()
+		return b.String(), err
+	}
+
+	t.Run("search", func(t *testing.T) {
+		cmd := newSearchCmd()
+		cmd.SilenceUsage = true
+		out, err := run(t, cmd, "nginx", "--kind", "helm", "--kind", "olm", "--org", "org1", "--official", "--has-provenance", "--image", "quay.io/org/img", "--signed", "--max-severity", "high", "--kubernetes-version", "1.24.0", "--helm-type", "application", "--updated-within", "30", "--limit", "5")
+		require.NoError(t, err)
+		qs := lastReq.URL.Query()
+		assert.Equal(t, "nginx", qs.Get("ts_query_web"))
+		assert.Equal(t, []string{"0", "3"}, qs["kind"])
+		assert.Equal(t, []string{"org1"}, qs["org"])
+		assert.Equal(t, "true", qs.Get("official"))
+		assert.Equal(t, "", qs.Get("deprecated"))
+		assert.Equal(t, "true", qs.Get("has_provenance"))
+		assert.Equal(t, "quay.io/org/img", qs.Get("image"))
+		assert.Equal(t, "true", qs.Get("signed"))
+		assert.Equal(t, "", qs.Get("has_security_report"))
+		assert.Equal(t, "high", qs.Get("max_severity"))
+		assert.Equal(t, "1.24.0", qs.Get("kubernetes_version"))
+		assert.Equal(t, []string{"application"}, qs["helm_type"])
+		assert.Equal(t, "30", qs.Get("updated_within"))
+		assert.Equal(t, "5", qs.Get("limit"))
+		assert.Contains(t, out, "KIND  REPOSITORY  NAME  VERSION  DESCRIPTION\n")
+		assert.Contains(t, out, "helm  repo1       pkg1  1.0.0    Package 1\n")
+	})
+
+	t.Run("search: invalid kind", func(t *testing.T) {
+		cmd := newSearchCmd()
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		_, err := run(t, cmd, "--kind", "invalid")
+		assert.Error(t, err)
+	})
+
+	t.Run("show", func(t *testing.T) {
+		cmd := newShowCmd()
+		out, err := run(t, cmd, "helm/repo1/pkg1")
+		require.NoError(t, err)
+		assert.Contains(t, out, "Name:")
+		assert.Contains(t, out, "pkg1\n")
+		assert.Contains(t, out, "Repository URL:")
+		assert.Contains(t, out, "1.0.0, 0.9.0\n")
+
+		cmd = newShowCmd()
+		out, err = run(t, cmd, "helm/repo1/pkg1", "--output", "json")
+		require.NoError(t, err)
+		var p *hub.Package
+		require.NoError(t, json.Unmarshal([]byte(out), &p))
+		assert.Equal(t, testPkgID, p.PackageID)
+	})
+
+	t.Run("show: package not found", func(t *testing.T) {
+		cmd := newShowCmd()
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		_, err := run(t, cmd, "helm/repo1/pkg2")
+		assert.EqualError(t, err, "error getting package: not found (status code: 404)")
+	})
+
+	t.Run("values", func(t *testing.T) {
+		cmd := newValuesCmd()
+		out, err := run(t, cmd, "helm/repo1/pkg1")
+		require.NoError(t, err)
+		assert.Equal(t, "key: value\n", out)
+	})
+
+	t.Run("values validate", func(t *testing.T) {
+		validFile := filepath.Join(t.TempDir(), "valid.yaml")
+		require.NoError(t, os.WriteFile(validFile, []byte("key: value\n"), 0o600))
+		invalidFile := filepath.Join(t.TempDir(), "invalid.yaml")
+		require.NoError(t, os.WriteFile(invalidFile, []byte("key: 1\nother: value\n"), 0o600))
+
+		cmd := newValuesCmd()
+		out, err := run(t, cmd, "validate", "helm/repo1/pkg1", "--file", validFile)
+		require.NoError(t, err)
+		assert.Equal(t, "Values are valid for pkg1 1.0.0\n", out)
+
+		cmd = newValuesCmd()
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		out, err = run(t, cmd, "validate", "helm/repo1/pkg1", "--file", invalidFile)
+		assert.EqualError(t, err, "values are not valid for pkg1 1.0.0")
+		assert.Equal(t, "error: /key: Invalid type. Expected: string, given: integer\nwarning: /other: unknown key other\n", out)
+	})
+
+	t.Run("security-report", func(t *testing.T) {
+		cmd := newSecurityReportCmd()
+		out, err := run(t, cmd, "helm/repo1/pkg1")
+		require.NoError(t, err)
+		assert.Contains(t, out, "Image: image1:1.0.0\n")
+		assert.Contains(t, out, "target1  HIGH      CVE-2022-0001  pkg      1.0        1.1")
+	})
+
+	t.Run("subscribe: api key required", func(t *testing.T) {
+		cmd := newSubscribeCmd()
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		_, err := run(t, cmd, "helm/repo1/pkg1", "--api-key-id", "", "--api-key-secret", "")
+		assert.Equal(t, errAPIKeyRequired, err)
+	})
+
+	t.Run("subscribe", func(t *testing.T) {
+		cmd := newSubscribeCmd()
+		out, err := run(t, cmd, "helm/repo1/pkg1", "--event", "security-alert", "--api-key-id", "keyID", "--api-key-secret", "keySecret")
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, lastReq.Method)
+		assert.JSONEq(t, `{"user_id": "", "package_id": "`+testPkgID+`", "event_kind": 1}`, string(lastReqBody))
+		assert.Equal(t, "Subscribed to security-alert events of helm/repo1/pkg1\n", out)
+	})
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		cmd := newUnsubscribeCmd()
+		out, err := run(t, cmd, "helm/repo1/pkg1", "--api-key-id", "keyID", "--api-key-secret", "keySecret")
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodDelete, lastReq.Method)
+		assert.Equal(t, testPkgID, lastReq.URL.Query().Get("package_id"))
+		assert.Equal(t, "0", lastReq.URL.Query().Get("event_kind"))
+		assert.Equal(t, "Unsubscribed from new-release events of helm/repo1/pkg1\n", out)
+	})
+
+	t.Run("unsubscribe: invalid api key", func(t *testing.T) {
+		cmd := newUnsubscribeCmd()
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		_, err := run(t, cmd, "helm/repo1/pkg1", "--api-key-id", "keyID", "--api-key-secret", "invalid")
+		assert.EqualError(t, err, "error deleting subscription: unexpected status code received: 401")
+	})
+}
@@ -1,29 +1,36 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/artifacthub/hub/internal/tracker/source/container"
 	"github.com/artifacthub/hub/internal/tracker/source/generic"
 	"github.com/artifacthub/hub/internal/tracker/source/helm"
 	"github.com/artifacthub/hub/internal/tracker/source/helmplugin"
 	"github.com/artifacthub/hub/internal/tracker/source/krew"
 	"github.com/artifacthub/hub/internal/tracker/source/olm"
 	"github.com/artifacthub/hub/internal/tracker/source/tekton"
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/hashicorp/go-multierror"
 	"github.com/spf13/cobra"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/plugin"
 )
 
@@ -34,6 +41,10 @@ const (
 	warning     = '!'
 	provided    = "PROVIDED"
 	notProvided = "*** NOT PROVIDED ***"
+
+	// httpClientTimeout represents the timeout used by the http client when
+	// fetching remote content (i.e. container images readme files).
+	httpClientTimeout = 10 * time.Second
 )
 
 // lintDesc represents the long description of the lint command.
@@ -60,8 +71,23 @@ type lintOptions struct {
 	// kind represents the repository kind.
 	kind string
 
-	// path represents the base path to walk looking for packages.
+	// path represents the base path to walk looking for packages. For
+	// container images, it represents the path of an OCI image layout.
 	path string
+
+	// image represents a reference to a container image in a registry.
+	image string
+
+	// url represents the url of a remote repository. When provided, the
+	// tracking of the repository is simulated instead of linting a local
+	// path.
+	url string
+
+	// output represents the format used to print the lint report.
+	output string
+
+	// hc represents the http client used to fetch remote content.
+	hc hub.HTTPClient
 }
 
 // lintReport represents the results of checking all the packages found in the
@@ -76,22 +102,28 @@ type lintReport struct {
 type lintReportEntry struct {
 	pkg    *hub.Package
 	path   string
+	file   string
 	result *multierror.Error
 }
 
 // newLintCmd creates a new lint command.
 func newLintCmd() *cobra.Command {
-	opts := &lintOptions{}
+	opts := &lintOptions{
+		hc: &http.Client{Timeout: httpClientTimeout},
+	}
 	lintCmd := &cobra.Command{
 		Use:   "lint",
 		Short: "Check the repository's packages are ready for Artifact Hub",
 		Long:  lintDesc,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return lint(opts, &output{cmd.OutOrStdout()})
+			return lint(opts, &output{Writer: cmd.OutOrStdout()})
 		},
 	}
-	lintCmd.Flags().StringVarP(&opts.kind, "kind", "k", "helm", "repository kind: coredns, falco, helm, helm-plugin, keda-scaler, keptn, krew, kubewarden, olm, opa, tbaction, tekton-task, tekton-pipeline")
-	lintCmd.Flags().StringVarP(&opts.path, "path", "p", ".", "repository's packages path")
+	lintCmd.Flags().StringVarP(&opts.kind, "kind", "k", "helm", "repository kind: container, coredns, falco, gatekeeper, helm, helm-plugin, keda-scaler, keptn, krew, kubewarden, olm, opa, tbaction, tekton-task, tekton-pipeline")
+	lintCmd.Flags().StringVarP(&opts.path, "path", "p", ".", "repository's packages path (OCI image layout path for container images)")
+	lintCmd.Flags().StringVarP(&opts.image, "image", "i", "", "container image reference in a registry (container images only)")
+	lintCmd.Flags().StringVarP(&opts.url, "url", "u", "", "remote repository url (simulates the tracking of the repository)")
+	lintCmd.Flags().StringVarP(&opts.output, "output", "o", outputText, "output format: text, json, sarif, junit")
 	return lintCmd
 }
 
@@ -106,17 +138,31 @@ func lint(opts *lintOptions, out *output) error {
 	if err != nil {
 		return err
 	}
+	switch opts.output {
+	case "", outputText, outputJSON, outputSARIF, outputJUnit:
+	default:
+		return fmt.Errorf("output format not supported: %s", opts.output)
+	}
+	if opts.url != "" {
+		return lintRemote(opts, kind, out)
+	}
 	var report *lintReport
 	switch kind {
 	case
 		hub.CoreDNS,
 		hub.Falco,
+		hub.Gatekeeper,
 		hub.KedaScaler,
 		hub.Keptn,
 		hub.Kubewarden,
 		hub.OPA,
 		hub.TBAction:
 		report = lintGeneric(opts.path, kind)
+	case hub.Container:
+		report, err = lintContainer(opts)
+		if err != nil {
+			return err
+		}
 	case hub.Helm:
 		report = lintHelm(opts.path)
 	case hub.HelmPlugin:
@@ -135,7 +181,19 @@ func lint(opts *lintOptions, out *output) error {
 	if len(report.entries) == 0 {
 		return errNoPackagesFound
 	}
-	out.printReport(report)
+	switch opts.output {
+	case outputJSON:
+		err = out.printReportJSON(report)
+	case outputSARIF:
+		err = out.printReportSARIF(report)
+	case outputJUnit:
+		err = out.printReportJUnit(report)
+	default:
+		out.printReport(report)
+	}
+	if err != nil {
+		return err
+	}
 	for _, entry := range report.entries {
 		if entry.result.ErrorOrNil() != nil {
 			return errLintFailed
@@ -159,12 +217,13 @@ func lintGeneric(basePath string, kind hub.RepositoryKind) *lintReport {
 
 		// Initialize report entry. If a package is found in the current path,
 		// errors found while processing it will be added to the report.
+		mdFilePath := filepath.Join(pkgPath, hub.PackageMetadataFile)
 		e := &lintReportEntry{
 			path: pkgPath,
+			file: getMetadataFilePath(mdFilePath),
 		}
 
 		// Get package version metadata and prepare entry package
-		mdFilePath := filepath.Join(pkgPath, hub.PackageMetadataFile)
 		md, err := pkg.GetPackageMetadata(kind, mdFilePath)
 		if err != nil {
 			if errors.Is(err, os.ErrNotExist) {
@@ -175,6 +234,10 @@ func lintGeneric(basePath string, kind hub.RepositoryKind) *lintReport {
 			e.pkg, err = generic.PreparePackage(&hub.Repository{Kind: kind}, md, pkgPath)
 			if err != nil {
 				e.result = multierror.Append(e.result, err)
+			} else if kind == hub.Gatekeeper {
+				if err := generic.ValidateGatekeeperPackage(pkgPath); err != nil {
+					e.result = multierror.Append(e.result, err)
+				}
 			}
 		}
 
@@ -185,6 +248,81 @@ func lintGeneric(basePath string, kind hub.RepositoryKind) *lintReport {
 	return report
 }
 
+// lintContainer checks if the container images available in the OCI image
+// layout or registry reference provided are ready to be processed by the
+// container tracker source and listed on Artifact Hub.
+func lintContainer(opts *lintOptions) (*lintReport, error) {
+	report := &lintReport{}
+	ctx := context.Background()
+
+	// Get metadata of the images to check, keyed by tag
+	var repoURL, entryPath, entryFile string
+	var mds map[string]map[string]string
+	if opts.image != "" {
+		ref, err := name.ParseReference(opts.image)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image reference: %w", err)
+		}
+		repoURL = hub.RepositoryOCIPrefix + ref.Context().Name()
+		entryPath = opts.image
+		md, err := container.GetMetadata(ctx, nil, &hub.Repository{}, opts.image)
+		if err != nil {
+			return nil, fmt.Errorf("error getting image metadata: %w", err)
+		}
+		mds = map[string]map[string]string{ref.Identifier(): md}
+	} else {
+		layoutPath, err := filepath.Abs(opts.path)
+		if err != nil {
+			return nil, err
+		}
+		repoURL = hub.RepositoryOCIPrefix + filepath.Base(layoutPath)
+		entryPath = opts.path
+		entryFile = filepath.Join(opts.path, "index.json")
+		mds, err = container.GetLayoutMetadata(opts.path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return report, nil
+			}
+			return nil, fmt.Errorf("error reading oci image layout: %w", err)
+		}
+	}
+	tags := make([]string, 0, len(mds))
+	for tag := range mds {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	// Prepare a package for each of the images found
+	for _, tag := range tags {
+		md := mds[tag]
+		e := &lintReportEntry{
+			path: entryPath,
+			file: entryFile,
+		}
+		var err error
+		repository := &hub.Repository{
+			Kind: hub.Container,
+			URL:  repoURL,
+		}
+		e.pkg, err = container.PreparePackageFromMetadata(repository, tag, md)
+		if err != nil {
+			e.result = multierror.Append(e.result, err)
+		}
+		if err := container.ValidateMetadata(md); err != nil {
+			e.result = multierror.Append(e.result, err)
+		}
+		readme, err := container.GetReadme(ctx, opts.hc, md)
+		if err != nil {
+			e.result = multierror.Append(e.result, err)
+		} else {
+			e.pkg.Readme = readme
+		}
+		report.entries = append(report.entries, e)
+	}
+
+	return report, nil
+}
+
 // lintHelm checks if the Helm charts available in the path provided are ready
 // to be processed by the Helm tracker source and listed on Artifact Hub.
 func lintHelm(basePath string) *lintReport {
@@ -202,6 +340,7 @@ func lintHelm(basePath string) *lintReport {
 		// errors found while processing it will be added to the report.
 		e := &lintReportEntry{
 			path: chartPath,
+			file: filepath.Join(chartPath, chartutil.ChartfileName),
 		}
 
 		// Try loading chart in the current path (may or may not be found)
@@ -252,12 +391,13 @@ func lintHelmPlugin(basePath string) *lintReport {
 
 		// Initialize report entry. If a package is found in the current path,
 		// errors found while processing it will be added to the report.
+		mdFilePath := filepath.Join(pkgPath, plugin.PluginFileName)
 		e := &lintReportEntry{
 			path: pkgPath,
+			file: mdFilePath,
 		}
 
 		// Get Helm plugin metadata and prepare package
-		mdFilePath := filepath.Join(pkgPath, plugin.PluginFileName)
 		md, err := helmplugin.GetMetadata(mdFilePath)
 		if err != nil {
 			if errors.Is(err, os.ErrNotExist) {
@@ -304,6 +444,7 @@ func lintKrew(basePath string) *lintReport {
 		pluginPath := filepath.Join(pluginsPath, file.Name())
 		e := &lintReportEntry{
 			path: pluginPath,
+			file: pluginPath,
 		}
 
 		// Get Krew plugin manifest and prepare package
@@ -411,6 +552,7 @@ func lintTekton(basePath string, kind hub.RepositoryKind) *lintReport {
 			pkgPath := path.Join(pkgBasePath, v.Name())
 			e := &lintReportEntry{
 				path: pkgPath,
+				file: path.Join(pkgPath, pkgName+".yaml"),
 			}
 
 			// Get package manifest
@@ -441,9 +583,24 @@ func lintTekton(basePath string, kind hub.RepositoryKind) *lintReport {
 	return report
 }
 
+// getMetadataFilePath returns the path of the package metadata file provided
+// including its extension, as it can be either .yml or .yaml.
+func getMetadataFilePath(mdFilePath string) string {
+	for _, extension := range []string{".yml", ".yaml"} {
+		if _, err := os.Stat(mdFilePath + extension); err == nil {
+			return mdFilePath + extension
+		}
+	}
+	return mdFilePath + ".yml"
+}
+
 // output represents a wrapper around an io.Writer used to print lint reports.
 type output struct {
 	io.Writer
+
+	// fieldsNotProvided keeps track of the labels of the fields marked with a
+	// warning while printing the package details.
+	fieldsNotProvided []string
 }
 
 // printReport prints the provided lint report to the receiver output.
@@ -515,7 +672,7 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 	if pkg.Readme != "" {
 		fmt.Fprintf(out, "%c Readme: %s\n", success, provided)
 	} else {
-		fmt.Fprintf(out, "%c Readme: %s\n", warning, notProvided)
+		out.printNotProvided("Readme")
 	}
 
 	// Keywords
@@ -525,7 +682,7 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 			fmt.Fprintf(out, "  - %s\n", keyword)
 		}
 	} else {
-		fmt.Fprintf(out, "%c Keywords: %s\n", warning, notProvided)
+		out.printNotProvided("Keywords")
 	}
 
 	// Links
@@ -535,7 +692,7 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 			fmt.Fprintf(out, "  - Name: %s | URL: %s\n", l.Name, l.URL)
 		}
 	} else {
-		fmt.Fprintf(out, "%c Links: %s\n", warning, notProvided)
+		out.printNotProvided("Links")
 	}
 
 	// Maintainers
@@ -545,7 +702,7 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 			fmt.Fprintf(out, "  - Name: %s | Email: %s\n", m.Name, m.Email)
 		}
 	} else {
-		fmt.Fprintf(out, "%c Maintainers: %s\n", warning, notProvided)
+		out.printNotProvided("Maintainers")
 	}
 
 	// Containers images
@@ -555,7 +712,7 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 			fmt.Fprintf(out, "  - Name: %s | Image: %s\n", i.Name, i.Image)
 		}
 	} else {
-		fmt.Fprintf(out, "%c Containers images: %s\n", warning, notProvided)
+		out.printNotProvided("Containers images")
 	}
 
 	// Changes
@@ -571,7 +728,7 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 			}
 		}
 	} else {
-		fmt.Fprintf(out, "%c Changes: %s\n", warning, notProvided)
+		out.printNotProvided("Changes")
 	}
 
 	// Recommendations
@@ -581,7 +738,7 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 			fmt.Fprintf(out, "  - %s\n", r.URL)
 		}
 	} else {
-		fmt.Fprintf(out, "%c Recommendations: %s\n", warning, notProvided)
+		out.printNotProvided("Recommendations")
 	}
 
 	// Screenshots
@@ -591,7 +748,7 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 			fmt.Fprintf(out, "      - Title: %s | URL: %s\n", s.Title, s.URL)
 		}
 	} else {
-		fmt.Fprintf(out, "%c Screenshots: %s\n", warning, notProvided)
+		out.printNotProvided("Screenshots")
 	}
 
 	// Operator
@@ -607,6 +764,7 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 	case
 		hub.CoreDNS,
 		hub.Falco,
+		hub.Gatekeeper,
 		hub.KedaScaler,
 		hub.Keptn,
 		hub.Kubewarden,
@@ -617,7 +775,7 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 		if pkg.Install != "" {
 			fmt.Fprintf(out, "%c Install: %s\n", success, provided)
 		} else {
-			fmt.Fprintf(out, "%c Install: %s\n", warning, notProvided)
+			out.printNotProvided("Install")
 		}
 
 		switch pkg.Repository.Kind {
@@ -627,6 +785,21 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 			for name := range pkg.Data[generic.FalcoRulesKey].(map[string]string) {
 				fmt.Fprintf(out, "  - %s\n", name)
 			}
+		case hub.Gatekeeper:
+			// Template and examples
+			fmt.Fprintf(out, "%c Template: %s\n", success, provided)
+			examples, _ := pkg.Data[generic.GatekeeperExamplesKey].([]*generic.GKExample)
+			if len(examples) > 0 {
+				fmt.Fprintf(out, "%c Examples:\n", success)
+				for _, e := range examples {
+					fmt.Fprintf(out, "  - %s\n", e.Name)
+					for _, c := range e.Cases {
+						fmt.Fprintf(out, "    - Name: %s | Path: %s\n", c.Name, c.Path)
+					}
+				}
+			} else {
+				out.printNotProvided("Examples")
+			}
 		case hub.OPA:
 			// Policies files
 			fmt.Fprintf(out, "%c Policies: %s\n", success, provided)
@@ -634,6 +807,26 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 				fmt.Fprintf(out, "  - %s\n", name)
 			}
 		}
+	case hub.Container:
+		// Platforms
+		if platforms, ok := pkg.Data["platforms"].([]string); ok && len(platforms) > 0 {
+			fmt.Fprintf(out, "%c Platforms:\n", success)
+			for _, platform := range platforms {
+				fmt.Fprintf(out, "  - %s\n", platform)
+			}
+		} else {
+			out.printNotProvided("Platforms")
+		}
+
+		// Alternative locations
+		if locations, ok := pkg.Data["alternativeLocations"].([]string); ok && len(locations) > 0 {
+			fmt.Fprintf(out, "%c Alternative locations:\n", success)
+			for _, location := range locations {
+				fmt.Fprintf(out, "  - %s\n", location)
+			}
+		} else {
+			out.printNotProvided("Alternative locations")
+		}
 	case hub.Helm:
 		out.print("Sign key", pkg.SignKey)
 
@@ -641,7 +834,7 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 		if pkg.ValuesSchema != nil {
 			fmt.Fprintf(out, "%c Values schema: %s\n", success, provided)
 		} else {
-			fmt.Fprintf(out, "%c Values schema: %s\n", warning, notProvided)
+			out.printNotProvided("Values schema")
 		}
 	case hub.Krew:
 		// Platforms
@@ -653,7 +846,7 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 					fmt.Fprintf(out, "  - %s\n", platform)
 				}
 			} else {
-				fmt.Fprintf(out, "%c Platforms: %s\n", warning, notProvided)
+				out.printNotProvided("Platforms")
 			}
 		}
 	case hub.OLM:
@@ -666,11 +859,18 @@ func (out *output) printPkgDetails(pkg *hub.Package) {
 				fmt.Fprintf(out, "  - %s -> %s\n", channel.Name, channel.Version)
 			}
 		} else {
-			fmt.Fprintf(out, "%c Channels: %s\n", warning, notProvided)
+			out.printNotProvided("Channels")
 		}
 	}
 }
 
+// printNotProvided prints the label provided with a warning mark indicating
+// that the value was not provided, keeping track of it.
+func (out *output) printNotProvided(label string) {
+	fmt.Fprintf(out, "%c %s: %s\n", warning, label, notProvided)
+	out.fieldsNotProvided = append(out.fieldsNotProvided, label)
+}
+
 // print is a helper function used to print the label and values provided with
 // a success or warning mark that indicates if the value was provided or not.
 func (out *output) print(label string, value interface{}) {
@@ -679,13 +879,13 @@ func (out *output) print(label string, value interface{}) {
 		if value != "" {
 			fmt.Fprintf(out, "%c %s: %s\n", success, label, value)
 		} else {
-			fmt.Fprintf(out, "%c %s: %s\n", warning, label, notProvided)
+			out.printNotProvided(label)
 		}
 	case reflect.Ptr:
 		if !reflect.ValueOf(value).IsNil() {
 			fmt.Fprintf(out, "%c %s: %s\n", success, label, provided)
 		} else {
-			fmt.Fprintf(out, "%c %s: %s\n", warning, label, notProvided)
+			out.printNotProvided(label)
 		}
 	}
 }
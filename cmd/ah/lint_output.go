@@ -0,0 +1,395 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/hashicorp/go-multierror"
+)
+
+// Output formats supported by the lint command.
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputSARIF = "sarif"
+	outputJUnit = "junit"
+)
+
+// Lint issues severities.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// Lint rules identifiers.
+const (
+	ruleInvalidValue         = "invalid-value"
+	ruleMissingOptionalField = "missing-optional-field"
+	rulePackageError         = "package-error"
+	ruleRequiredField        = "required-field"
+)
+
+// lintRules represents the rules that may be reported by the lint command,
+// along with a short description of each of them.
+var lintRules = []struct {
+	id          string
+	description string
+}{
+	{ruleInvalidValue, "Invalid value provided"},
+	{ruleMissingOptionalField, "Optional field not provided"},
+	{rulePackageError, "Error processing package"},
+	{ruleRequiredField, "Required field not provided"},
+}
+
+// lintIssue represents an issue found in a package while linting it.
+type lintIssue struct {
+	RuleID   string `json:"rule_id"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// getEntryIssues returns the issues found in the lint report entry provided.
+// Errors are flattened, so that each of them is reported individually, and
+// optional fields not provided are reported as warnings.
+func getEntryIssues(e *lintReportEntry) []*lintIssue {
+	var issues []*lintIssue
+	if e.result != nil {
+		for _, err := range e.result.Errors {
+			for _, fe := range flattenError("", err) {
+				issues = append(issues, &lintIssue{
+					RuleID:   getErrorRuleID(fe.err),
+					Severity: severityError,
+					Message:  fe.msg,
+				})
+			}
+		}
+	}
+	if e.pkg != nil && e.result.ErrorOrNil() == nil {
+		for _, label := range getFieldsNotProvided(e.pkg) {
+			issues = append(issues, &lintIssue{
+				RuleID:   ruleMissingOptionalField,
+				Severity: severityWarning,
+				Message:  fmt.Sprintf("%s not provided", label),
+			})
+		}
+	}
+	return issues
+}
+
+// flatError represents an error obtained by flattening a multierror, along
+// with the message that should be reported for it.
+type flatError struct {
+	msg string
+	err error
+}
+
+// flattenError returns the errors wrapped in the error provided when it
+// contains a multierror, adding the prefix of the wrapping error to each of
+// their messages.
+func flattenError(prefix string, err error) []*flatError {
+	var merr *multierror.Error
+	if !errors.As(err, &merr) || len(merr.Errors) == 0 {
+		return []*flatError{{msg: prefix + strings.TrimSpace(err.Error()), err: err}}
+	}
+	prefix += strings.TrimSuffix(err.Error(), merr.Error())
+	var errs []*flatError
+	for _, err := range merr.Errors {
+		errs = append(errs, flattenError(prefix, err)...)
+	}
+	return errs
+}
+
+// getErrorRuleID returns the identifier of the rule the error provided
+// relates to.
+func getErrorRuleID(err error) string {
+	switch {
+	case errors.Is(err, pkg.ErrFieldNotProvided):
+		return ruleRequiredField
+	case errors.Is(err, pkg.ErrInvalidValue):
+		return ruleInvalidValue
+	default:
+		return rulePackageError
+	}
+}
+
+// getFieldsNotProvided returns the labels of the optional fields that were
+// not provided in the package. They are collected while printing the package
+// details in text format, so they always match the ones marked with a warning
+// in that output.
+func getFieldsNotProvided(p *hub.Package) []string {
+	out := &output{Writer: io.Discard}
+	out.printPkgDetails(p)
+	return out.fieldsNotProvided
+}
+
+// getEntryLocation returns the location of the file that should be used when
+// reporting the issues found in the entry provided.
+func getEntryLocation(e *lintReportEntry) string {
+	if e.file != "" {
+		return filepath.ToSlash(e.file)
+	}
+	return filepath.ToSlash(e.path)
+}
+
+// lintJSONReport represents the lint report in JSON format.
+type lintJSONReport struct {
+	Packages []*lintJSONReportEntry `json:"packages"`
+	Summary  lintJSONReportSummary  `json:"summary"`
+}
+
+// lintJSONReportEntry represents an entry of the lint report in JSON format.
+type lintJSONReportEntry struct {
+	Name    string       `json:"name"`
+	Version string       `json:"version"`
+	Path    string       `json:"path"`
+	File    string       `json:"file"`
+	Passed  bool         `json:"passed"`
+	Issues  []*lintIssue `json:"issues"`
+}
+
+// lintJSONReportSummary represents the summary of the lint report in JSON
+// format.
+type lintJSONReportSummary struct {
+	Packages           int `json:"packages"`
+	PackagesWithErrors int `json:"packages_with_errors"`
+}
+
+// printReportJSON prints the provided lint report to the receiver output in
+// JSON format.
+func (out *output) printReportJSON(report *lintReport) error {
+	r := &lintJSONReport{
+		Packages: make([]*lintJSONReportEntry, 0, len(report.entries)),
+	}
+	for _, e := range report.entries {
+		je := &lintJSONReportEntry{
+			Path:   filepath.ToSlash(e.path),
+			File:   getEntryLocation(e),
+			Passed: e.result.ErrorOrNil() == nil,
+			Issues: getEntryIssues(e),
+		}
+		if e.pkg != nil {
+			je.Name = e.pkg.Name
+			je.Version = e.pkg.Version
+		}
+		if je.Issues == nil {
+			je.Issues = []*lintIssue{}
+		}
+		if !je.Passed {
+			r.Summary.PackagesWithErrors++
+		}
+		r.Packages = append(r.Packages, je)
+	}
+	r.Summary.Packages = len(report.entries)
+	return out.encodeJSON(r)
+}
+
+// sarifReport represents a lint report in SARIF format (only the subset of
+// the specification used by the lint command is supported).
+type sarifReport struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+// sarifRun represents a SARIF run.
+type sarifRun struct {
+	Tool    *sarifTool     `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+// sarifTool represents a SARIF tool.
+type sarifTool struct {
+	Driver *sarifDriver `json:"driver"`
+}
+
+// sarifDriver represents a SARIF tool driver.
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	InformationURI string       `json:"informationUri"`
+	Version        string       `json:"version,omitempty"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+// sarifRule represents a SARIF reporting descriptor.
+type sarifRule struct {
+	ID               string        `json:"id"`
+	ShortDescription *sarifMessage `json:"shortDescription"`
+}
+
+// sarifResult represents a SARIF result.
+type sarifResult struct {
+	RuleID    string           `json:"ruleId"`
+	RuleIndex int              `json:"ruleIndex"`
+	Level     string           `json:"level"`
+	Message   *sarifMessage    `json:"message"`
+	Locations []*sarifLocation `json:"locations"`
+}
+
+// sarifMessage represents a SARIF message.
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// sarifLocation represents a SARIF location.
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+	} `json:"physicalLocation"`
+}
+
+// printReportSARIF prints the provided lint report to the receiver output in
+// SARIF format.
+func (out *output) printReportSARIF(report *lintReport) error {
+	driver := &sarifDriver{
+		Name:           "ah",
+		InformationURI: "https://artifacthub.io/docs/topics/cli/",
+		Version:        version,
+	}
+	rulesIndex := make(map[string]int)
+	for i, rule := range lintRules {
+		driver.Rules = append(driver.Rules, &sarifRule{
+			ID:               rule.id,
+			ShortDescription: &sarifMessage{Text: rule.description},
+		})
+		rulesIndex[rule.id] = i
+	}
+	run := &sarifRun{
+		Tool:    &sarifTool{Driver: driver},
+		Results: []*sarifResult{},
+	}
+	for _, e := range report.entries {
+		location := &sarifLocation{}
+		location.PhysicalLocation.ArtifactLocation.URI = getEntryLocation(e)
+		for _, issue := range getEntryIssues(e) {
+			msg := issue.Message
+			if e.pkg != nil {
+				msg = fmt.Sprintf("%s (package: %s version: %s)", msg, e.pkg.Name, e.pkg.Version)
+			}
+			run.Results = append(run.Results, &sarifResult{
+				RuleID:    issue.RuleID,
+				RuleIndex: rulesIndex[issue.RuleID],
+				Level:     issue.Severity,
+				Message:   &sarifMessage{Text: msg},
+				Locations: []*sarifLocation{location},
+			})
+		}
+	}
+	return out.encodeJSON(&sarifReport{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []*sarifRun{run},
+	})
+}
+
+// junitTestSuites represents a lint report in JUnit XML format.
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite represents a JUnit test suite.
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	TestCases []*junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase represents a JUnit test case. Each package linted is
+// represented by a test case.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+// junitFailure represents a JUnit test case failure.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+// junitOutput represents the output of a JUnit test case.
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+// printReportJUnit prints the provided lint report to the receiver output in
+// JUnit XML format. Errors are reported as failures, whereas warnings are
+// included in the test case output.
+func (out *output) printReportJUnit(report *lintReport) error {
+	suite := &junitTestSuite{
+		Name:  "ah lint",
+		Tests: len(report.entries),
+	}
+	for _, e := range report.entries {
+		tc := &junitTestCase{
+			Name:      "name: ? version: ?",
+			ClassName: filepath.ToSlash(e.path),
+			File:      getEntryLocation(e),
+		}
+		if e.pkg != nil {
+			tc.Name = fmt.Sprintf("%s %s", e.pkg.Name, e.pkg.Version)
+		}
+		var errs, warnings []string
+		for _, issue := range getEntryIssues(e) {
+			line := fmt.Sprintf("[%s] %s", issue.RuleID, issue.Message)
+			if issue.Severity == severityError {
+				errs = append(errs, line)
+			} else {
+				warnings = append(warnings, line)
+			}
+		}
+		if len(errs) > 0 {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d error(s) occurred", len(errs)),
+				Type:    severityError,
+				Text:    strings.Join(errs, "\n"),
+			}
+			suite.Failures++
+		}
+		if len(warnings) > 0 {
+			tc.SystemOut = &junitOutput{Text: strings.Join(warnings, "\n")}
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	fmt.Fprint(out, xml.Header)
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+	err := enc.Encode(&junitTestSuites{
+		Name:     suite.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []*junitTestSuite{suite},
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+// encodeJSON writes the JSON encoding of the value provided to the receiver
+// output.
+func (out *output) encodeJSON(v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/img"
+	"github.com/artifacthub/hub/internal/oci"
+	"github.com/artifacthub/hub/internal/repo"
+	"github.com/artifacthub/hub/internal/tracker"
+	svg "github.com/h2non/go-is-svg"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+)
+
+// remoteLintResult represents the result of simulating the tracking of a
+// remote repository.
+type remoteLintResult struct {
+	registered []*hub.Package
+	ignored    []*hub.Package
+	errors     []*hub.ErrorRecord
+}
+
+// lintRemote simulates the tracking of the remote repository provided, using
+// the same tracker source the tracker would use for its kind. Only the
+// services that would persist data (database, images storage) are replaced by
+// in-memory stand-ins. The result is printed to the output provided.
+func lintRemote(opts *lintOptions, kind hub.RepositoryKind, out *output) error {
+	if opts.output != "" && opts.output != outputText {
+		return fmt.Errorf("output format not supported in remote mode: %s", opts.output)
+	}
+	result, err := trackRemote(context.Background(), opts.hc, &hub.Repository{
+		Name: "remote",
+		Kind: kind,
+		URL:  opts.url,
+	})
+	if err != nil {
+		return err
+	}
+	out.printRemoteResult(opts.url, kind, result)
+	for _, e := range result.errors {
+		if !isRemoteLintWarning(e) {
+			return errLintFailed
+		}
+	}
+	return nil
+}
+
+// isRemoteLintWarning checks if the error provided is just a warning, as it
+// does not prevent the package it belongs to from being registered.
+func isRemoteLintWarning(e *hub.ErrorRecord) bool {
+	switch e.Class {
+	case hub.ErrorClassLogo, hub.ErrorClassProvenance, hub.ErrorClassSignature:
+		return true
+	default:
+		return false
+	}
+}
+
+// trackRemote runs the tracker for the repository provided, returning the
+// packages that would be registered or ignored and the errors found.
+func trackRemote(ctx context.Context, hc hub.HTTPClient, r *hub.Repository) (*remoteLintResult, error) {
+	// Setup tracker services
+	cfg := viper.New()
+	cfg.Set("tracker.bypassDigestCheck", true)
+	op := oci.NewPuller(cfg)
+	rm := &remoteRepositoryManager{RepositoryManager: repo.NewManager(cfg, nil, nil, hc)}
+	pm := &remotePackageManager{}
+	ec := &remoteErrorsCollector{}
+	var ts *remoteTrackerSource
+	svc := &hub.TrackerServices{
+		Ctx: ctx,
+		Cfg: cfg,
+		Rm:  rm,
+		Pm:  pm,
+		Rc:  repo.NewCloner(hc),
+		Oe:  &repo.OLMOCIExporter{},
+		Ec:  ec,
+		Hc:  hc,
+		Op:  op,
+		Is:  &remoteImageStore{hc: hc},
+		Sc:  oci.NewSignatureChecker(cfg, op),
+		SetupTrackerSource: func(i *hub.TrackerSourceInput) hub.TrackerSource {
+			ts = &remoteTrackerSource{TrackerSource: tracker.SetupSource(i)}
+			return ts
+		},
+	}
+
+	// Track repository
+	if err := tracker.New(svc, r, zerolog.Nop()).Run(); err != nil {
+		return nil, fmt.Errorf("error tracking repository: %w", err)
+	}
+
+	// Prepare result
+	result := &remoteLintResult{
+		registered: pm.registered,
+		errors:     ec.errors,
+	}
+	if ts != nil {
+		for _, p := range ts.packagesAvailable {
+			if tracker.ShouldIgnorePackage(rm.md, p.Name, p.Version) {
+				result.ignored = append(result.ignored, p)
+			}
+		}
+	}
+	sortPackages(result.registered)
+	sortPackages(result.ignored)
+	sort.SliceStable(result.errors, func(i, j int) bool {
+		if result.errors[i].PackageName != result.errors[j].PackageName {
+			return result.errors[i].PackageName < result.errors[j].PackageName
+		}
+		return result.errors[i].Version < result.errors[j].Version
+	})
+	return result, nil
+}
+
+// sortPackages sorts the packages provided by name and version.
+func sortPackages(pkgs []*hub.Package) {
+	sort.Slice(pkgs, func(i, j int) bool {
+		if pkgs[i].Name != pkgs[j].Name {
+			return pkgs[i].Name < pkgs[j].Name
+		}
+		return pkgs[i].Version < pkgs[j].Version
+	})
+}
+
+// printRemoteResult prints the result of simulating the tracking of a remote
+// repository to the receiver output.
+func (out *output) printRemoteResult(url string, kind hub.RepositoryKind, result *remoteLintResult) {
+	fmt.Fprintf(out, "\n%s\n", strings.Repeat("-", sepLen))
+	fmt.Fprintf(out, "Tracking simulation: %s (%s)\n", url, hub.GetKindName(kind))
+	fmt.Fprintf(out, "%s\n\n", strings.Repeat("-", sepLen))
+
+	// Registered packages
+	fmt.Fprintf(out, "Packages that would be registered: %d\n\n", len(result.registered))
+	for _, p := range result.registered {
+		fmt.Fprintf(out, "  %c %s %s\n", success, p.Name, p.Version)
+	}
+
+	// Ignored packages
+	fmt.Fprintf(out, "\nPackages skipped by ignore entries: %d\n\n", len(result.ignored))
+	for _, p := range result.ignored {
+		fmt.Fprintf(out, "  %c %s %s\n", warning, p.Name, p.Version)
+	}
+
+	// Errors
+	fmt.Fprintf(out, "\nErrors (packages rejected and warnings): %d\n\n", len(result.errors))
+	for _, e := range result.errors {
+		mark := failure
+		if isRemoteLintWarning(e) {
+			mark = warning
+		}
+		fmt.Fprintf(out, "  %c [%s] %s\n", mark, e.Class, strings.TrimSpace(e.Message))
+	}
+
+	fmt.Fprintf(out, "\n%s\n", strings.Repeat("-", sepLen))
+	fmt.Fprintf(out, "\n%d package(s) registered, %d package(s) ignored, %d error(s)\n\n",
+		len(result.registered), len(result.ignored), len(result.errors))
+}
+
+// remoteTrackerSource is a wrapper around a hub.TrackerSource that keeps track
+// of the packages available returned by it.
+type remoteTrackerSource struct {
+	hub.TrackerSource
+	packagesAvailable map[string]*hub.Package
+}
+
+// GetPackagesAvailable implements the hub.TrackerSource interface.
+func (s *remoteTrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error) {
+	packagesAvailable, err := s.TrackerSource.GetPackagesAvailable()
+	s.packagesAvailable = packagesAvailable
+	return packagesAvailable, err
+}
+
+// remoteRepositoryManager is a hub.RepositoryManager implementation that
+// relies on the repository manager provided to get data from the remote
+// repository, but does not read or write any data from the database. The
+// repository metadata obtained is kept, as it's needed to prepare the result.
+type remoteRepositoryManager struct {
+	hub.RepositoryManager
+	md *hub.RepositoryMetadata
+}
+
+// GetMetadata implements the hub.RepositoryManager interface.
+func (m *remoteRepositoryManager) GetMetadata(r *hub.Repository, basePath string) (*hub.RepositoryMetadata, error) {
+	md, err := m.RepositoryManager.GetMetadata(r, basePath)
+	m.md = md
+	return md, err
+}
+
+// GetPackagesDigest implements the hub.RepositoryManager interface.
+func (m *remoteRepositoryManager) GetPackagesDigest(ctx context.Context, repositoryID string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+// SetVerifiedPublisher implements the hub.RepositoryManager interface.
+func (m *remoteRepositoryManager) SetVerifiedPublisher(ctx context.Context, repositoryID string, verified bool) error {
+	return nil
+}
+
+// UpdateDigest implements the hub.RepositoryManager interface.
+func (m *remoteRepositoryManager) UpdateDigest(ctx context.Context, repositoryID, digest string) error {
+	return nil
+}
+
+// remotePackageManager is a hub.PackageManager implementation that keeps the
+// packages registered in memory.
+type remotePackageManager struct {
+	hub.PackageManager
+	mu         sync.Mutex
+	registered []*hub.Package
+}
+
+// Register implements the hub.PackageManager interface.
+func (m *remotePackageManager) Register(ctx context.Context, p *hub.Package) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.registered = append(m.registered, p)
+	return nil
+}
+
+// Unregister implements the hub.PackageManager interface.
+func (m *remotePackageManager) Unregister(ctx context.Context, p *hub.Package) error {
+	return nil
+}
+
+// remoteErrorsCollector is a hub.ErrorsCollector implementation that keeps
+// the errors collected in memory.
+type remoteErrorsCollector struct {
+	mu     sync.Mutex
+	errors []*hub.ErrorRecord
+}
+
+// Append implements the hub.ErrorsCollector interface.
+func (ec *remoteErrorsCollector) Append(repositoryID string, err error) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.errors = append(ec.errors, hub.NewErrorRecord(err))
+}
+
+// Flush implements the hub.ErrorsCollector interface.
+func (ec *remoteErrorsCollector) Flush() {}
+
+// Init implements the hub.ErrorsCollector interface.
+func (ec *remoteErrorsCollector) Init(repositoryID string) {}
+
+// SetTrackingRunStats implements the hub.ErrorsCollector interface.
+func (ec *remoteErrorsCollector) SetTrackingRunStats(repositoryID string, stats *hub.TrackingRunStats) {
+}
+
+// remoteImageStore is an img.Store implementation that downloads and checks
+// images like the real store does, keeping them in memory.
+type remoteImageStore struct {
+	hc     hub.HTTPClient
+	mu     sync.Mutex
+	images map[string][]byte
+}
+
+// DownloadAndSaveImage implements the img.Store interface.
+func (s *remoteImageStore) DownloadAndSaveImage(ctx context.Context, imageURL string) (string, error) {
+	data, err := img.Download(ctx, s.hc, imageURL)
+	if err != nil {
+		return "", err
+	}
+	return s.SaveImage(ctx, data)
+}
+
+// GetImage implements the img.Store interface.
+func (s *remoteImageStore) GetImage(ctx context.Context, imageID, version string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.images[imageID]
+	if !ok {
+		return nil, hub.ErrNotFound
+	}
+	return data, nil
+}
+
+// SaveImage implements the img.Store interface.
+func (s *remoteImageStore) SaveImage(ctx context.Context, data []byte) (string, error) {
+	// Images that are not svg must be decodable to generate their versions
+	if !svg.Is(data) {
+		if _, err := img.GenerateVersions(data); err != nil {
+			return "", err
+		}
+	}
+	sum := sha256.Sum256(data)
+	imageID := hex.EncodeToString(sum[:])
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.images == nil {
+		s.images = make(map[string][]byte)
+	}
+	s.images[imageID] = data
+	return imageID, nil
+}
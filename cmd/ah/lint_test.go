@@ -4,11 +4,17 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/artifacthub/hub/internal/tests"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -121,6 +127,18 @@ func TestLintCmd(t *testing.T) {
 			"no packages found",
 			errNoPackagesFound,
 		},
+		{
+			"gatekeeper",
+			"test18",
+			"one package found, no errors",
+			nil,
+		},
+		{
+			"gatekeeper",
+			"test19",
+			"one package found, one with errors (constraint kind mismatch)",
+			errLintFailed,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -155,3 +173,232 @@ func TestLintCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestLintCmdOutputFormats(t *testing.T) {
+	testCases := []struct {
+		kind          string
+		path          string
+		output        string
+		expectedError error
+	}{
+		{"helm", "test1", outputJSON, nil},
+		{"helm", "test1", outputSARIF, nil},
+		{"helm", "test1", outputJUnit, nil},
+		{"opa", "test7", outputJSON, errLintFailed},
+		{"opa", "test7", outputSARIF, errLintFailed},
+		{"opa", "test7", outputJUnit, errLintFailed},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(fmt.Sprintf("%s: %s (%s)", tc.kind, tc.path, tc.output), func(t *testing.T) {
+			t.Parallel()
+
+			// Prepare command and execute it
+			var b bytes.Buffer
+			cmd := newLintCmd()
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			cmd.SetOut(&b)
+			cmd.SetArgs([]string{
+				"--kind", tc.kind,
+				"--path", filepath.Join("testdata", "lint", tc.path, "pkgs"),
+				"--output", tc.output,
+			})
+			cmdErr := cmd.Execute()
+
+			// Read command output and check it matches what we expect
+			cmdOutput, err := io.ReadAll(&b)
+			require.NoError(t, err)
+			goldenPath := filepath.Join("testdata", "lint", tc.path, fmt.Sprintf("output.%s.golden", tc.output))
+			if *update {
+				// Update tests golden files
+				golden, err := os.Create(goldenPath)
+				require.NoError(t, err)
+				_, err = golden.Write(cmdOutput)
+				require.NoError(t, err)
+			}
+			expectedOutput, err := os.ReadFile(goldenPath)
+			require.NoError(t, err)
+			assert.Equal(t, string(expectedOutput), string(cmdOutput))
+			assert.Equal(t, tc.expectedError, cmdErr)
+		})
+	}
+
+	t.Run("output format not supported", func(t *testing.T) {
+		t.Parallel()
+		cmd := newLintCmd()
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		cmd.SetOut(io.Discard)
+		cmd.SetArgs([]string{"--path", filepath.Join("testdata", "lint", "test1", "pkgs"), "--output", "invalid"})
+		err := cmd.Execute()
+		assert.EqualError(t, err, "output format not supported: invalid")
+	})
+}
+
+func TestLintCmdContainer(t *testing.T) {
+	testCases := []struct {
+		path          string
+		desc          string
+		expectedError error
+	}{
+		{
+			"test20",
+			"one image found, no errors",
+			nil,
+		},
+		{
+			"test21",
+			"one image found, one with errors",
+			errLintFailed,
+		},
+		{
+			"test4",
+			"no oci image layout found",
+			errNoPackagesFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			// Setup http client mock used to fetch the readme file
+			hc := &tests.HTTPClientMock{}
+			hc.On("Do", mock.Anything).Return(&http.Response{
+				Body:       io.NopCloser(strings.NewReader("Readme content in markdown format")),
+				StatusCode: http.StatusOK,
+			}, nil).Maybe()
+
+			// Lint container images in the oci image layout provided
+			var b bytes.Buffer
+			opts := &lintOptions{
+				kind: "container",
+				path: filepath.Join("testdata", "lint", tc.path, "pkgs"),
+				hc:   hc,
+			}
+			lintErr := lint(opts, &output{Writer: &b})
+
+			// Check command output matches what we expect
+			goldenPath := filepath.Join("testdata", "lint", tc.path, "output.golden")
+			if tc.expectedError == errNoPackagesFound {
+				assert.Empty(t, b.Bytes())
+			} else {
+				if *update {
+					// Update tests golden files
+					golden, err := os.Create(goldenPath)
+					require.NoError(t, err)
+					_, err = golden.Write(b.Bytes())
+					require.NoError(t, err)
+				}
+				expectedOutput, err := os.ReadFile(goldenPath)
+				require.NoError(t, err)
+				assert.Equal(t, expectedOutput, b.Bytes())
+			}
+			assert.Equal(t, tc.expectedError, lintErr)
+			hc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLintCmdRemote(t *testing.T) {
+	// Setup remote Helm repository
+	mux := http.NewServeMux()
+	var repoURL string
+	includeInvalidPackage := true
+	mux.HandleFunc("/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		invalidPackage := ""
+		if includeInvalidPackage {
+			invalidPackage = fmt.Sprintf(`
+  pkg2:
+    - name: pkg2
+      version: 1.0.0
+      digest: digest3
+      urls:
+        - %s/pkg2-1.0.0.tgz`, repoURL)
+		}
+		fmt.Fprintf(w, `apiVersion: v1
+entries:
+  artifact-hub:
+    - name: artifact-hub
+      version: 0.19.0
+      digest: digest1
+      urls:
+        - %[1]s/artifact-hub-0.19.0.tgz
+  pkg1:
+    - name: pkg1
+      version: 1.0.0
+      digest: digest2
+      urls:
+        - %[1]s/pkg1-1.0.0.tgz%[2]s
+`, repoURL, invalidPackage)
+	})
+	mux.HandleFunc("/artifacthub-repo.yml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `ignore:
+  - name: artifact-hub
+`)
+	})
+	for _, chart := range []string{"artifact-hub-0.19.0.tgz", "pkg1-1.0.0.tgz"} {
+		chartPath := filepath.Join("..", "..", "internal", "tracker", "source", "helm", "testdata", chart)
+		mux.HandleFunc("/"+chart, func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, chartPath)
+		})
+	}
+	s := httptest.NewServer(mux)
+	defer s.Close()
+	repoURL = s.URL
+
+	// Requests to other hosts (i.e. logos) are sent to the test server
+	sURL, _ := url.Parse(s.URL)
+	hc := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req.URL.Scheme = sURL.Scheme
+			req.URL.Host = sURL.Host
+			return http.DefaultTransport.RoundTrip(req)
+		}),
+	}
+
+	opts := &lintOptions{
+		kind: "helm",
+		url:  s.URL,
+		hc:   hc,
+	}
+
+	t.Run("some packages rejected", func(t *testing.T) {
+		includeInvalidPackage = true
+		var b bytes.Buffer
+		err := lint(opts, &output{Writer: &b})
+		assert.Equal(t, errLintFailed, err)
+		cmdOutput := b.String()
+		assert.Contains(t, cmdOutput, "Packages that would be registered: 1\n\n  ✓ pkg1 1.0.0\n")
+		assert.Contains(t, cmdOutput, "Packages skipped by ignore entries: 1\n\n  ! artifact-hub 0.19.0\n")
+		assert.Contains(t, cmdOutput, "Errors (packages rejected and warnings): 3\n")
+		assert.Contains(t, cmdOutput, "! [logo] error getting logo image https://artifacthub.github.io/hub/chart/logo.png: unexpected status code received: 404 (package: artifact-hub version: 0.19.0)")
+		assert.Contains(t, cmdOutput, "! [logo] error getting logo image http://icon.url: unexpected status code received: 404 (package: pkg1 version: 1.0.0)")
+		assert.Contains(t, cmdOutput, "✗ [package] error preparing package: error loading chart")
+		assert.Contains(t, cmdOutput, "(package: pkg2 version: 1.0.0)")
+		assert.Contains(t, cmdOutput, "1 package(s) registered, 1 package(s) ignored, 3 error(s)")
+	})
+
+	t.Run("only warnings found", func(t *testing.T) {
+		includeInvalidPackage = false
+		var b bytes.Buffer
+		err := lint(opts, &output{Writer: &b})
+		assert.NoError(t, err)
+		cmdOutput := b.String()
+		assert.Contains(t, cmdOutput, "Packages that would be registered: 1\n\n  ✓ pkg1 1.0.0\n")
+		assert.Contains(t, cmdOutput, "Packages skipped by ignore entries: 1\n\n  ! artifact-hub 0.19.0\n")
+		assert.Contains(t, cmdOutput, "1 package(s) registered, 1 package(s) ignored, 2 error(s)")
+	})
+}
+
+// roundTripperFunc is an adapter to allow the use of ordinary functions as
+// http round trippers.
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip implements the http.RoundTripper interface.
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
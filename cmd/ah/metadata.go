@@ -0,0 +1,726 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/license"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/artifacthub/hub/internal/tracker/source/helm"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/satori/uuid"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+const (
+	// defaultPkgVersion represents the version used when generating the
+	// metadata file of a new package.
+	defaultPkgVersion = "0.1.0"
+
+	// Helm annotations written by the annotate subcommand.
+	changesAnnotation         = "artifacthub.io/changes"
+	imagesAnnotation          = "artifacthub.io/images"
+	licenseAnnotation         = "artifacthub.io/license"
+	operatorAnnotation        = "artifacthub.io/operator"
+	prereleaseAnnotation      = "artifacthub.io/prerelease"
+	securityUpdatesAnnotation = "artifacthub.io/containsSecurityUpdates"
+)
+
+var (
+	// containersImagesRE is a regexp used to extract containers images from
+	// kubernetes manifests files.
+	containersImagesRE = regexp.MustCompile(`^\s+(?:-\s+)?image:\s+(\S+)`)
+
+	// errKindNotDetected indicates that the kind of the package could not be
+	// detected from the files available in its path.
+	errKindNotDetected = errors.New("unable to detect the package kind, please provide it using the --kind flag")
+
+	// licenseFiles represents the files that will be checked, in order, when
+	// detecting the license of a package.
+	licenseFiles = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "COPYING"}
+)
+
+// changeEntry represents an entry of the changes annotation.
+type changeEntry struct {
+	Kind        string      `yaml:"kind,omitempty"`
+	Description string      `yaml:"description"`
+	Links       []*hub.Link `yaml:"links,omitempty"`
+}
+
+// imageEntry represents an entry of the images annotation.
+type imageEntry struct {
+	Name  string `yaml:"name"`
+	Image string `yaml:"image"`
+}
+
+// metadataGenerateOptions represents the options that can be passed to the
+// metadata generate command.
+type metadataGenerateOptions struct {
+	path   string
+	kind   string
+	images []string
+	stdout bool
+	force  bool
+}
+
+// metadataAnnotateOptions represents the options that can be passed to the
+// metadata annotate command.
+type metadataAnnotateOptions struct {
+	path                    string
+	changes                 []string
+	containsSecurityUpdates bool
+	operator                bool
+	prerelease              bool
+	stdout                  bool
+}
+
+// metadataRepoFileOptions represents the options that can be passed to the
+// metadata repo-file command.
+type metadataRepoFileOptions struct {
+	path         string
+	repositoryID string
+	owners       []string
+	stdout       bool
+	force        bool
+}
+
+// newMetadataCmd creates a new metadata command.
+func newMetadataCmd() *cobra.Command {
+	metadataCmd := &cobra.Command{
+		Use:   "metadata",
+		Short: "Generate Artifact Hub metadata files",
+	}
+	metadataCmd.AddCommand(
+		newMetadataGenerateCmd(),
+		newMetadataAnnotateCmd(),
+		newMetadataRepoFileCmd(),
+	)
+	return metadataCmd
+}
+
+// newMetadataGenerateCmd creates a new metadata generate command.
+func newMetadataGenerateCmd() *cobra.Command {
+	opts := &metadataGenerateOptions{}
+	generateCmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate the artifacthub-pkg.yml file of a package",
+		Long: `Generate the artifacthub-pkg.yml file of a package
+
+The kind of the package, its license, readme and the containers images it uses
+are inferred from the files available in the package's path. The generated
+file is a skeleton that should be reviewed before publishing the package.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return metadataGenerate(opts, cmd.OutOrStdout())
+		},
+	}
+	generateCmd.Flags().StringVarP(&opts.path, "path", "p", ".", "package's path")
+	generateCmd.Flags().StringVarP(&opts.kind, "kind", "k", "", "package kind (detected when not provided): coredns, falco, gatekeeper, keda-scaler, keptn, kubewarden, opa, tbaction")
+	generateCmd.Flags().StringArrayVarP(&opts.images, "image", "i", nil, "container image used by the package ([name=]reference, can be repeated)")
+	generateCmd.Flags().BoolVar(&opts.stdout, "stdout", false, "print the metadata file instead of writing it")
+	generateCmd.Flags().BoolVar(&opts.force, "force", false, "overwrite the metadata file if it already exists")
+	return generateCmd
+}
+
+// metadataGenerate generates the metadata file of the package available in
+// the path provided.
+func metadataGenerate(opts *metadataGenerateOptions, w io.Writer) error {
+	// Get package kind
+	var kind hub.RepositoryKind
+	var err error
+	if opts.kind != "" {
+		kind, err = hub.GetKindFromName(opts.kind)
+	} else {
+		kind, err = detectPackageKind(opts.path)
+	}
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case
+		hub.CoreDNS,
+		hub.Falco,
+		hub.Gatekeeper,
+		hub.KedaScaler,
+		hub.Keptn,
+		hub.Kubewarden,
+		hub.OPA,
+		hub.TBAction:
+	case hub.Helm:
+		return errors.New("helm charts metadata is provided using annotations in the Chart.yaml file, please use the annotate subcommand")
+	default:
+		return fmt.Errorf("kind not supported: %s", hub.GetKindName(kind))
+	}
+
+	// Prepare package metadata
+	md, err := preparePackageMetadata(opts, kind)
+	if err != nil {
+		return err
+	}
+	data, err := marshalMetadata(md)
+	if err != nil {
+		return err
+	}
+	mdFile := filepath.Join(opts.path, hub.PackageMetadataFile)
+	if err := writeMetadataFile(mdFile, data, opts.stdout, opts.force, w); err != nil {
+		return err
+	}
+
+	// Let the user know about the fields that must still be reviewed
+	if err := pkg.ValidatePackageMetadata(kind, md); err != nil && !opts.stdout {
+		fmt.Fprintf(w, "\nThe metadata file must be reviewed before publishing the package:\n%s", err)
+	}
+	return nil
+}
+
+// detectPackageKind detects the kind of the package available in the path
+// provided from the files it contains.
+func detectPackageKind(pkgPath string) (hub.RepositoryKind, error) {
+	entries, err := os.ReadDir(pkgPath)
+	if err != nil {
+		return -1, fmt.Errorf("error reading package path: %w", err)
+	}
+	files := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			files[e.Name()] = struct{}{}
+		}
+	}
+	hasFile := func(name string) bool {
+		_, ok := files[name]
+		return ok
+	}
+	hasFileWithSuffix := func(suffix string) bool {
+		for name := range files {
+			if strings.HasSuffix(name, suffix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case hasFile("Chart.yaml"):
+		return hub.Helm, nil
+	case hasFile("template.yaml"):
+		return hub.Gatekeeper, nil
+	case hasFileWithSuffix("-rules.yaml"):
+		return hub.Falco, nil
+	case hasFileWithSuffix(".rego"):
+		return hub.OPA, nil
+	case hasFile("metadata.yml") || hasFile("policy.wasm"):
+		return hub.Kubewarden, nil
+	default:
+		return -1, errKindNotDetected
+	}
+}
+
+// preparePackageMetadata prepares a package metadata skeleton from the files
+// available in the path provided in the options.
+func preparePackageMetadata(opts *metadataGenerateOptions, kind hub.RepositoryKind) (*hub.PackageMetadata, error) {
+	absPath, err := filepath.Abs(opts.path)
+	if err != nil {
+		return nil, err
+	}
+	md := &hub.PackageMetadata{
+		Version:   defaultPkgVersion,
+		Name:      filepath.Base(absPath),
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		License:   detectLicense(opts.path),
+	}
+
+	// Display name and description are extracted from the README file
+	md.DisplayName = md.Name
+	readme, err := os.ReadFile(filepath.Join(opts.path, "README.md"))
+	if err == nil {
+		title, description := parseReadme(readme)
+		if title != "" {
+			md.DisplayName = title
+		}
+		md.Description = description
+	}
+
+	// Containers images
+	images, err := parseImagesFlag(opts.images, kind)
+	if err != nil {
+		return nil, err
+	}
+	if kind != hub.Kubewarden {
+		refs, err := findContainersImages(opts.path)
+		if err != nil {
+			return nil, err
+		}
+	L:
+		for _, ref := range refs {
+			for _, image := range images {
+				if image.Image == ref {
+					continue L
+				}
+			}
+			images = append(images, &hub.ContainerImage{Name: imageName(ref), Image: ref})
+		}
+	}
+	md.ContainersImages = images
+
+	return md, nil
+}
+
+// detectLicense detects the license of the package available in the path
+// provided, returning an empty string when no license file is found.
+func detectLicense(pkgPath string) string {
+	for _, file := range licenseFiles {
+		data, err := os.ReadFile(filepath.Join(pkgPath, file))
+		if err == nil {
+			return license.Detect(data)
+		}
+	}
+	return ""
+}
+
+// parseReadme extracts the title and the first paragraph from the readme
+// content provided.
+func parseReadme(readme []byte) (title, description string) {
+	var paragraph []string
+	s := bufio.NewScanner(bytes.NewReader(readme))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "":
+			if len(paragraph) > 0 {
+				return title, strings.Join(paragraph, " ")
+			}
+		case strings.HasPrefix(line, "#"):
+			if len(paragraph) > 0 {
+				return title, strings.Join(paragraph, " ")
+			}
+			if title == "" {
+				title = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			}
+		case strings.HasPrefix(line, "[!["), strings.HasPrefix(line, "!["), strings.HasPrefix(line, "<"):
+			// Skip badges, images and html content
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	return title, strings.Join(paragraph, " ")
+}
+
+// parseImagesFlag parses the containers images provided using the image flag.
+func parseImagesFlag(values []string, kind hub.RepositoryKind) ([]*hub.ContainerImage, error) {
+	images := make([]*hub.ContainerImage, 0, len(values))
+	for i, v := range values {
+		var n, ref string
+		if before, after, found := strings.Cut(v, "="); found {
+			n, ref = before, after
+		} else {
+			ref = v
+		}
+		if _, err := name.ParseReference(ref); err != nil {
+			return nil, fmt.Errorf("invalid image reference %s: %w", ref, err)
+		}
+		if n == "" {
+			switch {
+			case kind == hub.Kubewarden && i == 0:
+				n = "policy"
+			case kind == hub.Kubewarden && i == 1:
+				n = "policy-alternative-location"
+			default:
+				n = imageName(ref)
+			}
+		}
+		images = append(images, &hub.ContainerImage{Name: n, Image: ref})
+	}
+	return images, nil
+}
+
+// findContainersImages returns the containers images references found in the
+// yaml files available in the path provided.
+func findContainersImages(pkgPath string) ([]string, error) {
+	var refs []string
+	seen := make(map[string]struct{})
+	err := filepath.WalkDir(pkgPath, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != pkgPath && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(d.Name())
+		if (ext != ".yaml" && ext != ".yml") || strings.HasPrefix(d.Name(), hub.PackageMetadataFile) {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			result := containersImagesRE.FindStringSubmatch(s.Text())
+			if result == nil {
+				continue
+			}
+			ref := strings.Trim(result[1], `"'`)
+			if _, ok := seen[ref]; ok {
+				continue
+			}
+			if _, err := name.ParseReference(ref); err != nil {
+				continue
+			}
+			seen[ref] = struct{}{}
+			refs = append(refs, ref)
+		}
+		return s.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error looking for containers images: %w", err)
+	}
+	sort.Strings(refs)
+	return refs, nil
+}
+
+// imageName returns the name used for the container image reference
+// provided, which matches the last element of the image repository.
+func imageName(ref string) string {
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		return ref
+	}
+	return path.Base(r.Context().RepositoryStr())
+}
+
+// newMetadataAnnotateCmd creates a new metadata annotate command.
+func newMetadataAnnotateCmd() *cobra.Command {
+	opts := &metadataAnnotateOptions{}
+	annotateCmd := &cobra.Command{
+		Use:   "annotate",
+		Short: "Write Artifact Hub annotations into a Helm chart's Chart.yaml file",
+		Long: `Write Artifact Hub annotations into a Helm chart's Chart.yaml file
+
+The containers images used by the chart are extracted from the manifests
+rendered using the default values, and the license is detected from the
+chart's license file when it hasn't been annotated yet. Changes and other
+annotations can be provided using flags.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return metadataAnnotate(opts, cmd.OutOrStdout())
+		},
+	}
+	annotateCmd.Flags().StringVarP(&opts.path, "path", "p", ".", "chart's path")
+	annotateCmd.Flags().StringArrayVarP(&opts.changes, "change", "c", nil, "change introduced in this version ([kind:]description, can be repeated)")
+	annotateCmd.Flags().BoolVar(&opts.containsSecurityUpdates, "contains-security-updates", false, "this version contains security updates")
+	annotateCmd.Flags().BoolVar(&opts.operator, "operator", false, "the chart installs an operator")
+	annotateCmd.Flags().BoolVar(&opts.prerelease, "prerelease", false, "this version is a pre-release")
+	annotateCmd.Flags().BoolVar(&opts.stdout, "stdout", false, "print the resulting Chart.yaml file instead of writing it")
+	return annotateCmd
+}
+
+// metadataAnnotate writes some Artifact Hub annotations into the Chart.yaml
+// file of the chart available in the path provided.
+func metadataAnnotate(opts *metadataAnnotateOptions, w io.Writer) error {
+	chrt, err := loader.Load(opts.path)
+	if err != nil {
+		return fmt.Errorf("error loading chart: %w", err)
+	}
+	annotations := make(map[string]string)
+
+	// License
+	if _, ok := chrt.Metadata.Annotations[licenseAnnotation]; !ok {
+		if l := detectLicense(opts.path); l != "" {
+			annotations[licenseAnnotation] = l
+		}
+	}
+
+	// Containers images
+	refs, err := helm.ExtractContainersImages(chrt)
+	if err != nil {
+		return fmt.Errorf("error extracting containers images: %w", err)
+	}
+	if len(refs) > 0 {
+		images := make([]*imageEntry, 0, len(refs))
+		for _, ref := range refs {
+			images = append(images, &imageEntry{Name: imageName(ref), Image: ref})
+		}
+		data, err := yaml.Marshal(images)
+		if err != nil {
+			return err
+		}
+		annotations[imagesAnnotation] = string(data)
+	}
+
+	// Changes
+	if len(opts.changes) > 0 {
+		changes := make([]*changeEntry, 0, len(opts.changes))
+		for _, v := range opts.changes {
+			change := parseChangeFlag(v)
+			if err := pkg.ValidateChange(change); err != nil {
+				return err
+			}
+			changes = append(changes, &changeEntry{Kind: change.Kind, Description: change.Description})
+		}
+		data, err := yaml.Marshal(changes)
+		if err != nil {
+			return err
+		}
+		annotations[changesAnnotation] = string(data)
+	}
+
+	// Flags
+	if opts.containsSecurityUpdates {
+		annotations[securityUpdatesAnnotation] = "true"
+	}
+	if opts.operator {
+		annotations[operatorAnnotation] = "true"
+	}
+	if opts.prerelease {
+		annotations[prereleaseAnnotation] = "true"
+	}
+
+	// Update Chart.yaml file, preserving its existing content
+	chartFile := filepath.Join(opts.path, "Chart.yaml")
+	data, err := os.ReadFile(chartFile)
+	if err != nil {
+		return fmt.Errorf("error reading Chart.yaml file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("error parsing Chart.yaml file: %w", err)
+	}
+	if err := setChartAnnotations(&doc, annotations); err != nil {
+		return err
+	}
+	data, err = encodeYAML(&doc)
+	if err != nil {
+		return err
+	}
+	if opts.stdout {
+		_, err := w.Write(data)
+		return err
+	}
+	if err := os.WriteFile(chartFile, data, 0644); err != nil {
+		return fmt.Errorf("error writing Chart.yaml file: %w", err)
+	}
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fmt.Fprintf(w, "Annotations written to %s: %s\n", chartFile, strings.Join(keys, ", "))
+	return nil
+}
+
+// parseChangeFlag parses a change provided using the change flag. The change
+// kind is optional and can be provided as a prefix of the description.
+func parseChangeFlag(v string) *hub.Change {
+	if kind, description, found := strings.Cut(v, ":"); found {
+		change := &hub.Change{Kind: strings.TrimSpace(kind), Description: strings.TrimSpace(description)}
+		if pkg.ValidateChange(change) == nil {
+			pkg.NormalizeChange(change)
+			return change
+		}
+	}
+	return &hub.Change{Description: strings.TrimSpace(v)}
+}
+
+// setChartAnnotations sets the annotations provided in the Chart.yaml
+// document node provided.
+func setChartAnnotations(doc *yaml.Node, annotations map[string]string) error {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return errors.New("invalid Chart.yaml file")
+	}
+	root := doc.Content[0]
+	annotationsNode := mappingValue(root, "annotations")
+	if annotationsNode == nil || annotationsNode.Kind != yaml.MappingNode {
+		annotationsNode = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		setMappingValue(root, "annotations", annotationsNode)
+	}
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: annotations[k]}
+		if strings.Contains(annotations[k], "\n") {
+			valueNode.Style = yaml.LiteralStyle
+		} else {
+			valueNode.Style = yaml.DoubleQuotedStyle
+		}
+		setMappingValue(annotationsNode, k, valueNode)
+	}
+	return nil
+}
+
+// mappingValue returns the value node of the key provided in the mapping node
+// provided, or nil when the key is not found.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets the value node of the key provided in the mapping node
+// provided, appending the key when it doesn't exist yet.
+func setMappingValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// newMetadataRepoFileCmd creates a new metadata repo-file command.
+func newMetadataRepoFileCmd() *cobra.Command {
+	opts := &metadataRepoFileOptions{}
+	repoFileCmd := &cobra.Command{
+		Use:   "repo-file",
+		Short: "Generate the artifacthub-repo.yml file of a repository",
+		Long: `Generate the artifacthub-repo.yml file of a repository
+
+A new repository ID is generated when none is provided. Please note that the
+repository ID must match the one assigned by Artifact Hub to claim the
+ownership of a repository or to become a verified publisher.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return metadataRepoFile(opts, cmd.OutOrStdout())
+		},
+	}
+	repoFileCmd.Flags().StringVarP(&opts.path, "path", "p", ".", "repository's path")
+	repoFileCmd.Flags().StringVar(&opts.repositoryID, "repository-id", "", "repository ID (a new one is generated when not provided)")
+	repoFileCmd.Flags().StringArrayVar(&opts.owners, "owner", nil, "repository owner ([name:]email, can be repeated)")
+	repoFileCmd.Flags().BoolVar(&opts.stdout, "stdout", false, "print the metadata file instead of writing it")
+	repoFileCmd.Flags().BoolVar(&opts.force, "force", false, "overwrite the metadata file if it already exists")
+	return repoFileCmd
+}
+
+// metadataRepoFile generates the metadata file of a repository.
+func metadataRepoFile(opts *metadataRepoFileOptions, w io.Writer) error {
+	md := &hub.RepositoryMetadata{
+		RepositoryID: opts.repositoryID,
+	}
+	if md.RepositoryID == "" {
+		md.RepositoryID = uuid.NewV4().String()
+	} else if _, err := uuid.FromString(md.RepositoryID); err != nil {
+		return fmt.Errorf("invalid repository id: %w", err)
+	}
+	for _, v := range opts.owners {
+		owner := &hub.Owner{}
+		if i := strings.LastIndex(v, ":"); i >= 0 {
+			owner.Name, owner.Email = strings.TrimSpace(v[:i]), strings.TrimSpace(v[i+1:])
+		} else {
+			owner.Email = strings.TrimSpace(v)
+		}
+		if !strings.Contains(owner.Email, "@") {
+			return fmt.Errorf("invalid owner %s: [name:]email expected", v)
+		}
+		md.Owners = append(md.Owners, owner)
+	}
+	data, err := marshalMetadata(md)
+	if err != nil {
+		return err
+	}
+	mdFile := filepath.Join(opts.path, hub.RepositoryMetadataFile)
+	return writeMetadataFile(mdFile, data, opts.stdout, opts.force, w)
+}
+
+// marshalMetadata marshals the metadata provided as yaml, omitting the fields
+// that have not been set.
+func marshalMetadata(v interface{}) ([]byte, error) {
+	var n yaml.Node
+	if err := n.Encode(v); err != nil {
+		return nil, fmt.Errorf("error encoding metadata: %w", err)
+	}
+	pruneYAMLNode(&n)
+	return encodeYAML(&n)
+}
+
+// pruneYAMLNode removes from the mapping nodes the keys whose values are
+// null, empty or false.
+func pruneYAMLNode(n *yaml.Node) {
+	for _, c := range n.Content {
+		pruneYAMLNode(c)
+	}
+	if n.Kind != yaml.MappingNode {
+		return
+	}
+	content := make([]*yaml.Node, 0, len(n.Content))
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if isEmptyYAMLNode(n.Content[i+1]) {
+			continue
+		}
+		content = append(content, n.Content[i], n.Content[i+1])
+	}
+	n.Content = content
+}
+
+// isEmptyYAMLNode checks if the yaml node provided holds an empty value.
+func isEmptyYAMLNode(n *yaml.Node) bool {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return n.Tag == "!!null" || (n.Tag == "!!bool" && n.Value == "false") || (n.Tag == "!!str" && n.Value == "")
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(n.Content) == 0
+	}
+	return false
+}
+
+// encodeYAML encodes the yaml node provided using two spaces indentation.
+func encodeYAML(n *yaml.Node) ([]byte, error) {
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(n); err != nil {
+		return nil, fmt.Errorf("error encoding yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// writeMetadataFile writes the metadata file provided (path without the
+// extension), or prints it to the writer provided when requested. An existing
+// metadata file (using the .yml or .yaml extension) is only overwritten when
+// forced. Otherwise the file is written using the .yml extension.
+func writeMetadataFile(mdFileBase string, data []byte, stdout, force bool, w io.Writer) error {
+	if stdout {
+		_, err := w.Write(data)
+		return err
+	}
+	mdFile := mdFileBase + ".yml"
+	for _, extension := range []string{".yml", ".yaml"} {
+		if _, err := os.Stat(mdFileBase + extension); err == nil {
+			if !force {
+				return fmt.Errorf("file %s already exists (use --force to overwrite it)", mdFileBase+extension)
+			}
+			mdFile = mdFileBase + extension
+			break
+		}
+	}
+	if err := os.WriteFile(mdFile, data, 0644); err != nil {
+		return fmt.Errorf("error writing metadata file: %w", err)
+	}
+	fmt.Fprintf(w, "Metadata file written to %s\n", mdFile)
+	return nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/artifacthub/hub/internal/tracker/source/helm"
+	"github.com/satori/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+func TestMetadataGenerateCmd(t *testing.T) {
+	t.Run("package metadata generated", func(t *testing.T) {
+		t.Parallel()
+		var b bytes.Buffer
+		opts := &metadataGenerateOptions{
+			path:   filepath.Join("testdata", "metadata", "opa-pkg"),
+			images: []string{"policy=ghcr.io/org/policy:1.0.0"},
+			stdout: true,
+		}
+		require.NoError(t, metadataGenerate(opts, &b))
+
+		var md *hub.PackageMetadata
+		require.NoError(t, yaml.Unmarshal(b.Bytes(), &md))
+		require.NoError(t, pkg.ValidatePackageMetadata(hub.OPA, md))
+		_, err := time.Parse(time.RFC3339, md.CreatedAt)
+		require.NoError(t, err)
+		md.CreatedAt = ""
+		assert.Equal(t, &hub.PackageMetadata{
+			Version:     "0.1.0",
+			Name:        "opa-pkg",
+			DisplayName: "OPA policies",
+			Description: "Set of OPA policies used to validate kubernetes deployments.",
+			License:     "Apache-2.0",
+			ContainersImages: []*hub.ContainerImage{
+				{Name: "policy", Image: "ghcr.io/org/policy:1.0.0"},
+				{Name: "sidecar", Image: "ghcr.io/org/sidecar:1.0.0"},
+				{Name: "nginx", Image: "nginx:1.23.0"},
+			},
+		}, md)
+		assert.NotContains(t, b.String(), "readme")
+	})
+
+	t.Run("metadata file written", func(t *testing.T) {
+		t.Parallel()
+		pkgPath := copyTestdata(t, filepath.Join("testdata", "metadata", "opa-pkg"))
+		opts := &metadataGenerateOptions{path: pkgPath}
+		var b bytes.Buffer
+		require.NoError(t, metadataGenerate(opts, &b))
+		mdFile := filepath.Join(pkgPath, "artifacthub-pkg.yml")
+		assert.Equal(t, "Metadata file written to "+mdFile+"\n", b.String())
+		_, err := pkg.GetPackageMetadata(hub.OPA, filepath.Join(pkgPath, hub.PackageMetadataFile))
+		require.NoError(t, err)
+
+		// The metadata file is not overwritten unless requested
+		err = metadataGenerate(opts, &b)
+		assert.EqualError(t, err, "file "+mdFile+" already exists (use --force to overwrite it)")
+		opts.force = true
+		require.NoError(t, metadataGenerate(opts, &b))
+	})
+
+	t.Run("existing metadata file using the yaml extension", func(t *testing.T) {
+		t.Parallel()
+		pkgPath := copyTestdata(t, filepath.Join("testdata", "metadata", "opa-pkg"))
+		mdFile := filepath.Join(pkgPath, "artifacthub-pkg.yaml")
+		require.NoError(t, os.WriteFile(mdFile, []byte("version: 0.1.0\n"), 0600))
+		opts := &metadataGenerateOptions{path: pkgPath}
+		var b bytes.Buffer
+
+		// The metadata file is not overwritten unless requested
+		err := metadataGenerate(opts, &b)
+		assert.EqualError(t, err, "file "+mdFile+" already exists (use --force to overwrite it)")
+		opts.force = true
+		require.NoError(t, metadataGenerate(opts, &b))
+		assert.Equal(t, "Metadata file written to "+mdFile+"\n", b.String())
+		assert.NoFileExists(t, filepath.Join(pkgPath, "artifacthub-pkg.yml"))
+		_, err = pkg.GetPackageMetadata(hub.OPA, filepath.Join(pkgPath, hub.PackageMetadataFile))
+		require.NoError(t, err)
+	})
+
+	t.Run("kind not detected", func(t *testing.T) {
+		t.Parallel()
+		opts := &metadataGenerateOptions{path: filepath.Join("testdata", "metadata", "unknown"), stdout: true}
+		err := metadataGenerate(opts, &bytes.Buffer{})
+		assert.Equal(t, errKindNotDetected, err)
+	})
+
+	t.Run("helm charts must use the annotate subcommand", func(t *testing.T) {
+		t.Parallel()
+		opts := &metadataGenerateOptions{path: filepath.Join("testdata", "metadata", "chart"), stdout: true}
+		err := metadataGenerate(opts, &bytes.Buffer{})
+		assert.ErrorContains(t, err, "please use the annotate subcommand")
+	})
+
+	t.Run("kubewarden policy image named after flag position", func(t *testing.T) {
+		t.Parallel()
+		var b bytes.Buffer
+		opts := &metadataGenerateOptions{
+			path:   filepath.Join("testdata", "metadata", "unknown"),
+			kind:   "kubewarden",
+			images: []string{"ghcr.io/org/policy:1.0.0"},
+			stdout: true,
+		}
+		require.NoError(t, metadataGenerate(opts, &b))
+		var md *hub.PackageMetadata
+		require.NoError(t, yaml.Unmarshal(b.Bytes(), &md))
+		assert.Equal(t, []*hub.ContainerImage{{Name: "policy", Image: "ghcr.io/org/policy:1.0.0"}}, md.ContainersImages)
+	})
+}
+
+func TestMetadataAnnotateCmd(t *testing.T) {
+	chartPath := copyTestdata(t, filepath.Join("testdata", "metadata", "chart"))
+	opts := &metadataAnnotateOptions{
+		path:       chartPath,
+		changes:    []string{"added: Support for ingress", "Some change: with colons"},
+		prerelease: true,
+	}
+	var b bytes.Buffer
+	require.NoError(t, metadataAnnotate(opts, &b))
+	assert.Contains(t, b.String(), "artifacthub.io/changes, artifacthub.io/images, artifacthub.io/license, artifacthub.io/prerelease\n")
+
+	// Existing content is preserved
+	data, err := os.ReadFile(filepath.Join(chartPath, "Chart.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Chart used to test the metadata annotate command\n")
+
+	// Annotations are valid
+	chrt, err := loader.Load(chartPath)
+	require.NoError(t, err)
+	p := &hub.Package{}
+	require.NoError(t, helm.EnrichPackageFromAnnotations(p, chrt.Metadata.Annotations))
+	assert.Equal(t, "Apache-2.0", p.License)
+	assert.True(t, p.Prerelease)
+	assert.Equal(t, []*hub.ContainerImage{{Name: "nginx", Image: "nginx:1.23.0"}}, p.ContainersImages)
+	assert.Equal(t, []*hub.Change{
+		{Kind: "added", Description: "Support for ingress"},
+		{Description: "Some change: with colons"},
+	}, p.Changes)
+	assert.Len(t, p.Links, 1)
+}
+
+func TestMetadataRepoFileCmd(t *testing.T) {
+	t.Run("repository metadata generated", func(t *testing.T) {
+		t.Parallel()
+		var b bytes.Buffer
+		opts := &metadataRepoFileOptions{
+			owners: []string{"user1@email.com", "User 2:user2@email.com"},
+			stdout: true,
+		}
+		require.NoError(t, metadataRepoFile(opts, &b))
+		var md *hub.RepositoryMetadata
+		require.NoError(t, yaml.Unmarshal(b.Bytes(), &md))
+		_, err := uuid.FromString(md.RepositoryID)
+		require.NoError(t, err)
+		assert.Equal(t, []*hub.Owner{
+			{Email: "user1@email.com"},
+			{Name: "User 2", Email: "user2@email.com"},
+		}, md.Owners)
+		assert.NotContains(t, b.String(), "ignore")
+	})
+
+	t.Run("repository id provided", func(t *testing.T) {
+		t.Parallel()
+		var b bytes.Buffer
+		opts := &metadataRepoFileOptions{
+			repositoryID: "00000000-0000-0000-0000-000000000001",
+			stdout:       true,
+		}
+		require.NoError(t, metadataRepoFile(opts, &b))
+		assert.Equal(t, "repositoryID: 00000000-0000-0000-0000-000000000001\n", b.String())
+
+		opts.repositoryID = "invalid"
+		assert.Error(t, metadataRepoFile(opts, &b))
+	})
+
+	t.Run("invalid owner", func(t *testing.T) {
+		t.Parallel()
+		opts := &metadataRepoFileOptions{owners: []string{"User 1"}, stdout: true}
+		err := metadataRepoFile(opts, &bytes.Buffer{})
+		assert.EqualError(t, err, "invalid owner User 1: [name:]email expected")
+	})
+}
+
+// copyTestdata copies the testdata directory provided into a temporary
+// directory, returning its path.
+func copyTestdata(t *testing.T, src string) string {
+	t.Helper()
+	dst := filepath.Join(t.TempDir(), filepath.Base(src))
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, p[len(src):])
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0600)
+	})
+	require.NoError(t, err)
+	return dst
+}
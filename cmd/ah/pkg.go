@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	trivy "github.com/aquasecurity/trivy/pkg/types"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/spf13/cobra"
+)
+
+// pkgOptions represents the options that can be passed to the commands that
+// operate on a given package.
+type pkgOptions struct {
+	client hubClientOptions
+	output string
+}
+
+// newShowCmd creates a new show command.
+func newShowCmd() *cobra.Command {
+	opts := &pkgOptions{}
+	showCmd := &cobra.Command{
+		Use:   "show kind/repo/package[@version]",
+		Short: "Show the details of a package",
+		Long: `Show the details of a package
+
+When no version is provided, the latest version available is used.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return show(cmd.Context(), opts, args[0], cmd.OutOrStdout())
+		},
+	}
+	opts.client.addFlags(showCmd.Flags())
+	showCmd.Flags().StringVarP(&opts.output, "output", "o", outputText, "output format: text, json")
+	return showCmd
+}
+
+// show prints the details of the package provided to the writer provided.
+func show(ctx context.Context, opts *pkgOptions, pkgRef string, w io.Writer) error {
+	if opts.output != outputText && opts.output != outputJSON {
+		return fmt.Errorf("output format not supported: %s", opts.output)
+	}
+	ref, err := parsePackageRef(pkgRef)
+	if err != nil {
+		return err
+	}
+	p, data, err := newHubClient(&opts.client).getPackage(ctx, ref)
+	if err != nil {
+		return err
+	}
+	if opts.output == outputJSON {
+		_, err := w.Write(data)
+		return err
+	}
+
+	// Print package details
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	printField := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", label, value)
+		}
+	}
+	printField("Name", p.Name)
+	printField("Display name", p.DisplayName)
+	printField("Version", p.Version)
+	printField("App version", p.AppVersion)
+	if p.Repository != nil {
+		printField("Kind", hub.GetKindName(p.Repository.Kind))
+		printField("Repository", p.Repository.Name)
+		printField("Repository URL", p.Repository.URL)
+	}
+	printField("Description", p.Description)
+	printField("License", p.License)
+	printField("Home URL", p.HomeURL)
+	printField("Keywords", strings.Join(p.Keywords, ", "))
+	printField("Signatures", strings.Join(p.Signatures, ", "))
+	if p.Deprecated {
+		printField("Deprecated", "true")
+	}
+	if p.SecurityReportSummary != nil {
+		s := p.SecurityReportSummary
+		printField("Vulnerabilities", fmt.Sprintf("critical: %d, high: %d, medium: %d, low: %d, unknown: %d",
+			s.Critical, s.High, s.Medium, s.Low, s.Unknown))
+	}
+	if len(p.AvailableVersions) > 0 {
+		versions := make([]string, 0, len(p.AvailableVersions))
+		for _, v := range p.AvailableVersions {
+			versions = append(versions, v.Version)
+		}
+		printField("Available versions", strings.Join(versions, ", "))
+	}
+	return tw.Flush()
+}
+
+// newValuesCmd creates a new values command.
+func newValuesCmd() *cobra.Command {
+	opts := &pkgOptions{}
+	valuesCmd := &cobra.Command{
+		Use:   "values kind/repo/package[@version]",
+		Short: "Show the default values of a Helm chart",
+		Long: `Show the default values of a Helm chart
+
+When no version is provided, the latest version available is used.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return values(cmd.Context(), opts, args[0], cmd.OutOrStdout())
+		},
+	}
+	opts.client.addFlags(valuesCmd.Flags())
+	valuesCmd.AddCommand(newValuesValidateCmd())
+	return valuesCmd
+}
+
+// valuesValidateOptions represents the options that can be passed to the
+// values validate command.
+type valuesValidateOptions struct {
+	pkgOptions
+	file string
+}
+
+// newValuesValidateCmd creates a new values validate command.
+func newValuesValidateCmd() *cobra.Command {
+	opts := &valuesValidateOptions{}
+	validateCmd := &cobra.Command{
+		Use:   "validate kind/repo/package[@version]",
+		Short: "Validate a values file against a Helm chart",
+		Long: `Validate a values file against a Helm chart
+
+The values provided are validated against the chart's values schema (once
+merged with the default values). Keys that are neither in the default values
+nor in the values schema are reported as warnings. When no version is provided,
+the latest version available is used.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return validateValues(cmd.Context(), opts, args[0], cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+	opts.client.addFlags(validateCmd.Flags())
+	validateCmd.Flags().StringVarP(&opts.file, "file", "f", "", "values file to validate (use - to read from stdin)")
+	validateCmd.Flags().StringVarP(&opts.output, "output", "o", outputText, "output format: text, json")
+	_ = validateCmd.MarkFlagRequired("file")
+	return validateCmd
+}
+
+// validateValues validates the values file provided against the Helm chart
+// provided, printing the issues found to the writer provided. An error is
+// returned when the values are not valid.
+func validateValues(ctx context.Context, opts *valuesValidateOptions, pkgRef string, r io.Reader, w io.Writer) error {
+	if opts.output != outputText && opts.output != outputJSON {
+		return fmt.Errorf("output format not supported: %s", opts.output)
+	}
+	ref, err := parsePackageRef(pkgRef)
+	if err != nil {
+		return err
+	}
+	var valuesData []byte
+	if opts.file == "-" {
+		valuesData, err = io.ReadAll(r)
+	} else {
+		valuesData, err = os.ReadFile(opts.file)
+	}
+	if err != nil {
+		return fmt.Errorf("error reading values file: %w", err)
+	}
+
+	// Validate values
+	c := newHubClient(&opts.client)
+	p, _, err := c.getPackage(ctx, ref)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/packages/%s/%s/values/validate", p.PackageID, url.PathEscape(p.Version))
+	input := &hub.ValuesValidationInput{Values: string(valuesData)}
+	data, err := c.do(ctx, http.MethodPost, path, nil, input)
+	if err != nil {
+		return fmt.Errorf("error validating values: %w", err)
+	}
+	var v *hub.ValuesValidation
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("error decoding values validation: %w", err)
+	}
+
+	// Print validation result
+	if opts.output == outputJSON {
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	} else {
+		printIssues := func(level string, issues []*hub.ValuesValidationIssue) {
+			for _, issue := range issues {
+				pointer := issue.Pointer
+				if pointer == "" {
+					pointer = "/"
+				}
+				fmt.Fprintf(w, "%s: %s: %s\n", level, pointer, issue.Message)
+			}
+		}
+		printIssues("error", v.Errors)
+		printIssues("warning", v.Warnings)
+		if v.Valid {
+			fmt.Fprintf(w, "Values are valid for %s %s\n", p.Name, p.Version)
+		}
+	}
+	if !v.Valid {
+		return fmt.Errorf("values are not valid for %s %s", p.Name, p.Version)
+	}
+	return nil
+}
+
+// values prints the default values of the Helm chart provided to the writer
+// provided.
+func values(ctx context.Context, opts *pkgOptions, pkgRef string, w io.Writer) error {
+	ref, err := parsePackageRef(pkgRef)
+	if err != nil {
+		return err
+	}
+	c := newHubClient(&opts.client)
+	p, _, err := c.getPackage(ctx, ref)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/packages/%s/%s/values", p.PackageID, url.PathEscape(p.Version))
+	data, err := c.do(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return fmt.Errorf("error getting values: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// newSecurityReportCmd creates a new security-report command.
+func newSecurityReportCmd() *cobra.Command {
+	opts := &pkgOptions{}
+	securityReportCmd := &cobra.Command{
+		Use:   "security-report kind/repo/package[@version]",
+		Short: "Show the security report of a package",
+		Long: `Show the security report of a package
+
+The security report contains the vulnerabilities found in the containers images
+used by the package. When no version is provided, the latest version available
+is used.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return securityReport(cmd.Context(), opts, args[0], cmd.OutOrStdout())
+		},
+	}
+	opts.client.addFlags(securityReportCmd.Flags())
+	securityReportCmd.Flags().StringVarP(&opts.output, "output", "o", outputText, "output format: text, json")
+	return securityReportCmd
+}
+
+// securityReport prints the security report of the package provided to the
+// writer provided.
+func securityReport(ctx context.Context, opts *pkgOptions, pkgRef string, w io.Writer) error {
+	if opts.output != outputText && opts.output != outputJSON {
+		return fmt.Errorf("output format not supported: %s", opts.output)
+	}
+	ref, err := parsePackageRef(pkgRef)
+	if err != nil {
+		return err
+	}
+	c := newHubClient(&opts.client)
+	p, _, err := c.getPackage(ctx, ref)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/packages/%s/%s/security-report", p.PackageID, url.PathEscape(p.Version))
+	data, err := c.do(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return fmt.Errorf("error getting security report: %w", err)
+	}
+	if opts.output == outputJSON {
+		_, err := w.Write(data)
+		return err
+	}
+	var report map[string]*trivy.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return fmt.Errorf("error decoding security report: %w", err)
+	}
+	if len(report) == 0 {
+		fmt.Fprintf(w, "No security report available for %s %s\n", p.Name, p.Version)
+		return nil
+	}
+
+	// Print vulnerabilities found in each of the images
+	images := make([]string, 0, len(report))
+	for image := range report {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	for _, image := range images {
+		fmt.Fprintf(w, "Image: %s\n\n", image)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TARGET\tSEVERITY\tID\tPACKAGE\tINSTALLED\tFIXED")
+		if report[image] != nil {
+			for _, r := range report[image].Results {
+				for _, v := range r.Vulnerabilities {
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+						r.Target,
+						v.Severity,
+						v.VulnerabilityID,
+						v.PkgName,
+						v.InstalledVersion,
+						v.FixedVersion,
+					)
+				}
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
@@ -0,0 +1,524 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// repoSearchLimit represents the maximum number of repositories that can
+	// be requested in a single repositories search request.
+	repoSearchLimit = 60
+
+	// keepCredentials is a special value that can be used when updating a
+	// repository to keep the credentials currently stored.
+	keepCredentials = "="
+)
+
+// Actions that can be applied to a repository when reconciling it.
+const (
+	repoActionAdd      = "add"
+	repoActionDelete   = "delete"
+	repoActionTransfer = "transfer"
+	repoActionUpdate   = "update"
+)
+
+// repoApplyDesc represents the long description of the repo apply command.
+var repoApplyDesc = `Apply a declarative list of repositories
+
+Use this command to reconcile the repositories defined in the file provided
+with the ones registered in the hub. Repositories not registered yet will be
+added, the ones whose settings differ will be updated and the ones owned by a
+different publisher will be transferred. When the --prune flag is provided,
+repositories owned by the publishers referenced in the file that are not
+defined on it will be deleted.
+
+Example file:
+
+  repositories:
+    - name: my-charts
+      displayName: My charts
+      org: my-org
+      kind: helm
+      url: https://charts.example.com
+      credentials:
+        usernameEnv: CHARTS_USERNAME
+        passwordEnv: CHARTS_PASSWORD
+    - name: my-policies
+      kind: opa
+      url: https://github.com/user/policies
+      branch: main
+      scannerDisabled: true
+
+Repositories without an org are owned by the user owning the API key. The
+credentials are read from the environment variables provided.`
+
+// repoManifest represents a declarative list of repositories.
+type repoManifest struct {
+	Repositories []*repoManifestEntry `json:"repositories"`
+}
+
+// repoManifestEntry represents a repository in a repositories manifest.
+type repoManifestEntry struct {
+	Name            string                   `json:"name"`
+	DisplayName     string                   `json:"displayName"`
+	Org             string                   `json:"org"`
+	Kind            string                   `json:"kind"`
+	URL             string                   `json:"url"`
+	Branch          string                   `json:"branch"`
+	Disabled        bool                     `json:"disabled"`
+	ScannerDisabled bool                     `json:"scannerDisabled"`
+	Credentials     *repoManifestCredentials `json:"credentials"`
+}
+
+// repoManifestCredentials represents the environment variables that contain
+// the credentials of a repository.
+type repoManifestCredentials struct {
+	UsernameEnv string `json:"usernameEnv"`
+	PasswordEnv string `json:"passwordEnv"`
+}
+
+// repoApplyOptions represents the options that can be passed to the repo apply
+// command.
+type repoApplyOptions struct {
+	client hubClientOptions
+	file   string
+	dryRun bool
+	prune  bool
+}
+
+// repoChange represents a change that must be applied to a repository to
+// reconcile it with its desired state.
+type repoChange struct {
+	action   string
+	r        *hub.Repository
+	owner    string
+	newOwner string
+	details  []string
+}
+
+// newRepoCmd creates a new repo command.
+func newRepoCmd() *cobra.Command {
+	repoCmd := &cobra.Command{
+		Use:   "repo",
+		Short: "Manage repositories in the hub",
+	}
+	repoCmd.AddCommand(newRepoApplyCmd())
+	return repoCmd
+}
+
+// newRepoApplyCmd creates a new repo apply command.
+func newRepoApplyCmd() *cobra.Command {
+	opts := &repoApplyOptions{}
+	applyCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Apply a declarative list of repositories",
+		Long:  repoApplyDesc,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return repoApply(cmd.Context(), opts, cmd.OutOrStdout())
+		},
+	}
+	opts.client.addFlags(applyCmd.Flags())
+	applyCmd.Flags().StringVarP(&opts.file, "file", "f", "", "repositories file")
+	applyCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "print the changes that would be applied without applying them")
+	applyCmd.Flags().BoolVar(&opts.prune, "prune", false, "delete repositories owned by the publishers in the file not defined on it")
+	_ = applyCmd.MarkFlagRequired("file")
+	return applyCmd
+}
+
+// repoApply reconciles the repositories defined in the file provided with the
+// ones registered in the hub.
+func repoApply(ctx context.Context, opts *repoApplyOptions, w io.Writer) error {
+	// Read desired repositories
+	desired, err := readRepoManifest(opts.file)
+	if err != nil {
+		return err
+	}
+	c := newHubClient(&opts.client)
+	if err := c.requireAPIKey(); err != nil {
+		return err
+	}
+
+	// Get repositories currently registered for the owners in the manifest
+	var userAlias string
+	owners := make(map[string]struct{})
+	for _, e := range desired {
+		owners[e.OrganizationName] = struct{}{}
+	}
+	if _, ok := owners[""]; ok {
+		userAlias, err = c.getUserAlias(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	current := make(map[string]*hub.Repository)
+	for owner := range owners {
+		qs := url.Values{}
+		if owner != "" {
+			qs.Set("org", owner)
+		} else {
+			qs.Set("user", userAlias)
+		}
+		repos, err := c.searchRepositories(ctx, qs)
+		if err != nil {
+			return err
+		}
+		for _, r := range repos {
+			current[r.Name] = r
+		}
+	}
+
+	// Look for desired repositories owned by other publishers
+	for _, e := range desired {
+		if _, ok := current[e.Name]; ok {
+			continue
+		}
+		qs := url.Values{}
+		qs.Set("name", "^"+regexp.QuoteMeta(e.Name)+"$")
+		repos, err := c.searchRepositories(ctx, qs)
+		if err != nil {
+			return err
+		}
+		for _, r := range repos {
+			if r.Name == e.Name {
+				current[r.Name] = r
+			}
+		}
+	}
+
+	// Prepare and print the changes needed
+	changes, err := getRepoChanges(desired, current, owners, userAlias, opts.prune)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No changes needed")
+		return nil
+	}
+	printRepoChanges(w, changes)
+	if opts.dryRun {
+		return nil
+	}
+
+	// Apply changes
+	fmt.Fprintln(w)
+	var errs *multierror.Error
+	for _, ch := range changes {
+		if err := c.applyRepoChange(ctx, ch); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("error applying %s to repository %s: %w", ch.action, ch.r.Name, err))
+			fmt.Fprintf(w, "%c %s %s: %s\n", failure, ch.action, ch.r.Name, err)
+			continue
+		}
+		fmt.Fprintf(w, "%c %s %s\n", success, ch.action, ch.r.Name)
+	}
+	return errs.ErrorOrNil()
+}
+
+// readRepoManifest reads the repositories manifest file provided, returning
+// the desired repositories defined on it.
+func readRepoManifest(file string) ([]*hub.Repository, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading repositories file: %w", err)
+	}
+	var m *repoManifest
+	if err := yaml.UnmarshalStrict(data, &m); err != nil {
+		return nil, fmt.Errorf("error parsing repositories file: %w", err)
+	}
+	if m == nil || len(m.Repositories) == 0 {
+		return nil, errors.New("no repositories found in repositories file")
+	}
+
+	var errs *multierror.Error
+	repos := make([]*hub.Repository, 0, len(m.Repositories))
+	names := make(map[string]struct{}, len(m.Repositories))
+	for i, e := range m.Repositories {
+		// Validate entry
+		if e.Name == "" {
+			errs = multierror.Append(errs, fmt.Errorf("repository %d: name not provided", i))
+			continue
+		}
+		if _, ok := names[e.Name]; ok {
+			errs = multierror.Append(errs, fmt.Errorf("repository %s: duplicated", e.Name))
+			continue
+		}
+		names[e.Name] = struct{}{}
+		kind, err := hub.GetKindFromName(e.Kind)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("repository %s: %w", e.Name, err))
+			continue
+		}
+		if e.URL == "" {
+			errs = multierror.Append(errs, fmt.Errorf("repository %s: url not provided", e.Name))
+			continue
+		}
+
+		// Prepare repository
+		r := &hub.Repository{
+			Name:             e.Name,
+			DisplayName:      e.DisplayName,
+			URL:              e.URL,
+			Branch:           e.Branch,
+			Kind:             kind,
+			OrganizationName: e.Org,
+			Disabled:         e.Disabled,
+			ScannerDisabled:  e.ScannerDisabled,
+			AuthUser:         keepCredentials,
+			AuthPass:         keepCredentials,
+		}
+		if e.Credentials != nil {
+			r.AuthUser, r.AuthPass = "", ""
+			if e.Credentials.UsernameEnv != "" {
+				r.AuthUser = os.Getenv(e.Credentials.UsernameEnv)
+				if r.AuthUser == "" {
+					errs = multierror.Append(errs, fmt.Errorf("repository %s: environment variable %s not set", e.Name, e.Credentials.UsernameEnv))
+				}
+			}
+			if e.Credentials.PasswordEnv != "" {
+				r.AuthPass = os.Getenv(e.Credentials.PasswordEnv)
+				if r.AuthPass == "" {
+					errs = multierror.Append(errs, fmt.Errorf("repository %s: environment variable %s not set", e.Name, e.Credentials.PasswordEnv))
+				}
+			}
+			r.Private = r.AuthUser != "" || r.AuthPass != ""
+		}
+		repos = append(repos, r)
+	}
+	if errs.ErrorOrNil() != nil {
+		return nil, fmt.Errorf("invalid repositories file: %w", errs)
+	}
+	return repos, nil
+}
+
+// getRepoChanges returns the changes that must be applied to the current
+// repositories to reconcile them with the desired ones.
+func getRepoChanges(
+	desired []*hub.Repository,
+	current map[string]*hub.Repository,
+	owners map[string]struct{},
+	userAlias string,
+	prune bool,
+) ([]*repoChange, error) {
+	var changes []*repoChange
+	var errs *multierror.Error
+
+	// Add, transfer or update repositories defined in the manifest
+	desiredNames := make(map[string]struct{}, len(desired))
+	for _, d := range desired {
+		desiredNames[d.Name] = struct{}{}
+		c, ok := current[d.Name]
+		if !ok {
+			changes = append(changes, &repoChange{
+				action: repoActionAdd,
+				r:      d,
+				owner:  d.OrganizationName,
+			})
+			continue
+		}
+		if c.Kind != d.Kind {
+			errs = multierror.Append(errs, fmt.Errorf(
+				"repository %s: kind cannot be changed (%s -> %s)",
+				d.Name, hub.GetKindName(c.Kind), hub.GetKindName(d.Kind),
+			))
+			continue
+		}
+		owner := c.OrganizationName
+		if owner == "" && c.UserAlias != userAlias {
+			owner = "user:" + c.UserAlias
+		}
+		if owner != d.OrganizationName {
+			changes = append(changes, &repoChange{
+				action:   repoActionTransfer,
+				r:        d,
+				owner:    c.OrganizationName,
+				newOwner: d.OrganizationName,
+				details:  []string{fmt.Sprintf("owner: %s -> %s", ownerName(owner), ownerName(d.OrganizationName))},
+			})
+		}
+		if details := diffRepositories(c, d); len(details) > 0 {
+			d.Data = c.Data
+			changes = append(changes, &repoChange{
+				action:  repoActionUpdate,
+				r:       d,
+				owner:   d.OrganizationName,
+				details: details,
+			})
+		}
+	}
+
+	// Delete repositories not defined in the manifest when requested
+	if prune {
+		var names []string
+		for name, c := range current {
+			if _, ok := desiredNames[name]; ok {
+				continue
+			}
+			if _, ok := owners[c.OrganizationName]; !ok {
+				continue
+			}
+			if c.OrganizationName == "" && c.UserAlias != userAlias {
+				continue
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			changes = append(changes, &repoChange{
+				action: repoActionDelete,
+				r:      current[name],
+				owner:  current[name].OrganizationName,
+			})
+		}
+	}
+
+	if errs.ErrorOrNil() != nil {
+		return nil, errs
+	}
+	return changes, nil
+}
+
+// diffRepositories returns the differences found between the current and the
+// desired repositories provided. Credentials are only compared by presence,
+// as the current ones are not available.
+func diffRepositories(c, d *hub.Repository) []string {
+	var details []string
+	diff := func(field string, cv, dv interface{}) {
+		if cv != dv {
+			details = append(details, fmt.Sprintf("%s: %v -> %v", field, cv, dv))
+		}
+	}
+	diff("display name", c.DisplayName, d.DisplayName)
+	diff("url", c.URL, d.URL)
+	diff("branch", c.Branch, d.Branch)
+	diff("disabled", c.Disabled, d.Disabled)
+	diff("scanner disabled", c.ScannerDisabled, d.ScannerDisabled)
+	if d.AuthUser != keepCredentials {
+		diff("private", c.Private, d.Private)
+	}
+	return details
+}
+
+// ownerName returns the name of the owner provided to be displayed.
+func ownerName(owner string) string {
+	if owner == "" {
+		return "user"
+	}
+	return owner
+}
+
+// printRepoChanges prints the changes provided to the writer provided.
+func printRepoChanges(w io.Writer, changes []*repoChange) {
+	marks := map[string]rune{
+		repoActionAdd:      '+',
+		repoActionDelete:   '-',
+		repoActionTransfer: '>',
+		repoActionUpdate:   '~',
+	}
+	for _, ch := range changes {
+		fmt.Fprintf(w, "%c %s %s (%s)\n", marks[ch.action], ch.action, ch.r.Name, ownerName(ch.owner))
+		for _, d := range ch.details {
+			fmt.Fprintf(w, "    %s\n", d)
+		}
+	}
+	var counts []string
+	for _, action := range []string{repoActionAdd, repoActionUpdate, repoActionTransfer, repoActionDelete} {
+		var n int
+		for _, ch := range changes {
+			if ch.action == action {
+				n++
+			}
+		}
+		counts = append(counts, fmt.Sprintf("%d to %s", n, action))
+	}
+	fmt.Fprintf(w, "\nPlan: %s\n", strings.Join(counts, ", "))
+}
+
+// applyRepoChange applies the change provided using the hub API.
+func (c *hubClient) applyRepoChange(ctx context.Context, ch *repoChange) error {
+	// Transfers are requested using the endpoint of the target owner, as the
+	// user may not have access to the endpoints of the current one
+	owner := ch.owner
+	if ch.action == repoActionTransfer {
+		owner = ch.newOwner
+	}
+	basePath := "/repositories/user"
+	if owner != "" {
+		basePath = "/repositories/org/" + url.PathEscape(owner)
+	}
+	repoPath := basePath + "/" + url.PathEscape(ch.r.Name)
+
+	var err error
+	switch ch.action {
+	case repoActionAdd:
+		r := *ch.r
+		if r.AuthUser == keepCredentials {
+			r.AuthUser, r.AuthPass = "", ""
+		}
+		_, err = c.do(ctx, http.MethodPost, basePath, nil, &r)
+	case repoActionDelete:
+		_, err = c.do(ctx, http.MethodDelete, repoPath, nil, nil)
+	case repoActionTransfer:
+		qs := url.Values{}
+		if ch.newOwner != "" {
+			qs.Set("org", ch.newOwner)
+		}
+		_, err = c.do(ctx, http.MethodPut, repoPath+"/transfer", qs, nil)
+	case repoActionUpdate:
+		_, err = c.do(ctx, http.MethodPut, repoPath, nil, ch.r)
+	}
+	return err
+}
+
+// getUserAlias returns the alias of the user owning the API key.
+func (c *hubClient) getUserAlias(ctx context.Context) (string, error) {
+	data, err := c.do(ctx, http.MethodGet, "/users/profile", nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("error getting user profile: %w", err)
+	}
+	var profile struct {
+		Alias string `json:"alias"`
+	}
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return "", fmt.Errorf("error decoding user profile: %w", err)
+	}
+	return profile.Alias, nil
+}
+
+// searchRepositories returns all the repositories matching the search query
+// provided, requesting as many pages as needed.
+func (c *hubClient) searchRepositories(ctx context.Context, qs url.Values) ([]*hub.Repository, error) {
+	var repos []*hub.Repository
+	for offset := 0; ; offset += repoSearchLimit {
+		qs.Set("limit", strconv.Itoa(repoSearchLimit))
+		qs.Set("offset", strconv.Itoa(offset))
+		data, err := c.do(ctx, http.MethodGet, "/repositories/search", qs, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error searching repositories: %w", err)
+		}
+		var page []*hub.Repository
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("error decoding repositories: %w", err)
+		}
+		repos = append(repos, page...)
+		if len(page) < repoSearchLimit {
+			break
+		}
+	}
+	return repos, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoApplyCmd(t *testing.T) {
+	t.Setenv("AH_TEST_REPO3_USERNAME", "username")
+	t.Setenv("AH_TEST_REPO3_PASSWORD", "password")
+
+	// Setup hub API
+	var mu sync.Mutex
+	var changes []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/users/profile", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"alias": "user1"}`)
+	})
+	mux.HandleFunc("/api/v1/repositories/search", func(w http.ResponseWriter, r *http.Request) {
+		qs := r.URL.Query()
+		switch {
+		case qs.Get("org") == "org1":
+			fmt.Fprint(w, `[
+				{"name": "repo1", "display_name": "Repo 1", "kind": 0, "url": "https://repo1.url", "organization_name": "org1"},
+				{"name": "repo5", "kind": 0, "url": "https://repo5.url", "organization_name": "org1"}
+			]`)
+		case qs.Get("user") == "user1":
+			fmt.Fprint(w, `[{"name": "repo4", "kind": 0, "url": "https://repo4.url", "user_alias": "user1"}]`)
+		case qs.Get("name") == `^repo3$`:
+			fmt.Fprint(w, `[{"name": "repo3", "kind": 0, "url": "https://repo3.url", "organization_name": "org2"}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	})
+	mux.HandleFunc("/api/v1/repositories/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		change := fmt.Sprintf("%s %s", r.Method, r.URL.Path)
+		if r.URL.RawQuery != "" {
+			change += "?" + r.URL.RawQuery
+		}
+		if len(body) > 0 {
+			var repo *hub.Repository
+			require.NoError(t, json.Unmarshal(body, &repo))
+			change += fmt.Sprintf(" %s|%s|%s|%s|%t", repo.DisplayName, repo.URL, repo.AuthUser, repo.AuthPass, repo.ScannerDisabled)
+		}
+		mu.Lock()
+		changes = append(changes, change)
+		mu.Unlock()
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	// Helper to run the command against the hub API
+	run := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+		var b bytes.Buffer
+		cmd := newRepoApplyCmd()
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		cmd.SetOut(&b)
+		cmd.SetArgs(append(args,
+			"--hub-url", s.URL,
+			"--api-key-id", "keyID",
+			"--api-key-secret", "keySecret",
+		))
+		err := cmd.Execute()
+		return b.String(), err
+	}
+	reposFile := filepath.Join("testdata", "repo", "repos.yaml")
+
+	t.Run("dry run", func(t *testing.T) {
+		changes = nil
+		out, err := run(t, "-f", reposFile, "--dry-run", "--prune")
+		require.NoError(t, err)
+		assert.Equal(t, `~ update repo1 (org1)
+    display name: Repo 1 -> Repository 1
++ add repo2 (org1)
+> transfer repo3 (org2)
+    owner: org2 -> user
+~ update repo3 (user)
+    private: false -> true
+- delete repo5 (org1)
+
+Plan: 1 to add, 2 to update, 1 to transfer, 1 to delete
+`, out)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		changes = nil
+		out, err := run(t, "-f", reposFile)
+		require.NoError(t, err)
+		assert.Contains(t, out, "Plan: 1 to add, 2 to update, 1 to transfer, 0 to delete\n")
+		assert.Equal(t, []string{
+			"PUT /api/v1/repositories/org/org1/repo1 Repository 1|https://repo1.url|=|=|false",
+			"POST /api/v1/repositories/org/org1 |https://github.com/org1/repo2|||true",
+			"PUT /api/v1/repositories/user/repo3/transfer",
+			"PUT /api/v1/repositories/user/repo3 |https://repo3.url|username|password|false",
+		}, changes)
+	})
+
+	t.Run("apply with prune", func(t *testing.T) {
+		changes = nil
+		_, err := run(t, "-f", reposFile, "--prune")
+		require.NoError(t, err)
+		assert.Contains(t, changes, "DELETE /api/v1/repositories/org/org1/repo5")
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		reposFile := filepath.Join(t.TempDir(), "repos.yaml")
+		require.NoError(t, os.WriteFile(reposFile, []byte(`
+repositories:
+  - name: repo1
+    kind: invalid
+    url: https://repo1.url
+  - name: repo2
+    kind: helm
+`), 0o600))
+		_, err := run(t, "-f", reposFile)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "repository repo1: invalid kind name")
+		assert.Contains(t, err.Error(), "repository repo2: url not provided")
+	})
+
+	t.Run("kind cannot be changed", func(t *testing.T) {
+		reposFile := filepath.Join(t.TempDir(), "repos.yaml")
+		require.NoError(t, os.WriteFile(reposFile, []byte(`
+repositories:
+  - name: repo1
+    org: org1
+    kind: opa
+    url: https://repo1.url
+`), 0o600))
+		_, err := run(t, "-f", reposFile)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "repository repo1: kind cannot be changed (helm -> opa)")
+	})
+}
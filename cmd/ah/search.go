@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/spf13/cobra"
+)
+
+// searchDesc represents the long description of the search command.
+var searchDesc = `Search for packages in the hub
+
+Use this command to search for packages using the same filters available in the
+hub's search API. The query provided (if any) is matched against the packages
+names, descriptions, keywords, etc.`
+
+// searchOptions represents the options that can be passed to the search
+// command.
+type searchOptions struct {
+	client            hubClientOptions
+	kinds             []string
+	users             []string
+	orgs              []string
+	repos             []string
+	licenses          []string
+	capabilities      []string
+	helmTypes         []string
+	verifiedPublisher bool
+	official          bool
+	operators         bool
+	deprecated        bool
+	hasProvenance     bool
+	signed            bool
+	hasSecurityReport bool
+	maxSeverity       string
+	kubernetesVersion string
+	updatedWithin     int
+	image             string
+	sort              string
+	limit             int
+	offset            int
+	output            string
+}
+
+// searchResult represents the result of a packages search.
+type searchResult struct {
+	Packages []*hub.Package `json:"packages"`
+}
+
+// newSearchCmd creates a new search command.
+func newSearchCmd() *cobra.Command {
+	opts := &searchOptions{}
+	searchCmd := &cobra.Command{
+		Use:   "search [query]",
+		Short: "Search for packages in the hub",
+		Long:  searchDesc,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var query string
+			if len(args) > 0 {
+				query = args[0]
+			}
+			return search(cmd.Context(), opts, query, cmd.OutOrStdout())
+		},
+	}
+	opts.client.addFlags(searchCmd.Flags())
+	searchCmd.Flags().StringSliceVarP(&opts.kinds, "kind", "k", nil, "repository kind (can be provided multiple times)")
+	searchCmd.Flags().StringSliceVar(&opts.users, "user", nil, "user alias of the publisher")
+	searchCmd.Flags().StringSliceVar(&opts.orgs, "org", nil, "organization name of the publisher")
+	searchCmd.Flags().StringSliceVar(&opts.repos, "repo", nil, "repository name")
+	searchCmd.Flags().StringSliceVar(&opts.licenses, "license", nil, "package license")
+	searchCmd.Flags().StringSliceVar(&opts.capabilities, "capabilities", nil, "operator capabilities")
+	searchCmd.Flags().StringSliceVar(&opts.helmTypes, "helm-type", nil, "helm chart type: application, library")
+	searchCmd.Flags().BoolVar(&opts.verifiedPublisher, "verified-publisher", false, "only packages from verified publishers")
+	searchCmd.Flags().BoolVar(&opts.official, "official", false, "only official packages")
+	searchCmd.Flags().BoolVar(&opts.operators, "operators", false, "only operators")
+	searchCmd.Flags().BoolVar(&opts.deprecated, "deprecated", false, "include deprecated packages")
+	searchCmd.Flags().BoolVar(&opts.hasProvenance, "has-provenance", false, "only packages with SLSA provenance")
+	searchCmd.Flags().BoolVar(&opts.signed, "signed", false, "only signed packages")
+	searchCmd.Flags().BoolVar(&opts.hasSecurityReport, "has-security-report", false, "only packages with a security report")
+	searchCmd.Flags().StringVar(&opts.maxSeverity, "max-severity", "", "only packages without vulnerabilities above this severity: none, low, medium or high")
+	searchCmd.Flags().StringVar(&opts.kubernetesVersion, "kubernetes-version", "", "only packages compatible with this Kubernetes version")
+	searchCmd.Flags().IntVar(&opts.updatedWithin, "updated-within", 0, "only packages updated within this number of days")
+	searchCmd.Flags().StringVar(&opts.image, "image", "", "only packages using this container image (any tag or digest unless provided)")
+	searchCmd.Flags().StringVar(&opts.sort, "sort", "", "sort criteria: relevance, stars")
+	searchCmd.Flags().IntVar(&opts.limit, "limit", 20, "number of packages to return")
+	searchCmd.Flags().IntVar(&opts.offset, "offset", 0, "number of packages to skip")
+	searchCmd.Flags().StringVarP(&opts.output, "output", "o", outputText, "output format: text, json")
+	return searchCmd
+}
+
+// search searches for packages using the options provided, printing the
+// results to the writer provided.
+func search(ctx context.Context, opts *searchOptions, query string, w io.Writer) error {
+	if opts.output != outputText && opts.output != outputJSON {
+		return fmt.Errorf("output format not supported: %s", opts.output)
+	}
+	qs, err := buildSearchQuery(opts, query)
+	if err != nil {
+		return err
+	}
+
+	// Search packages
+	c := newHubClient(&opts.client)
+	data, err := c.do(ctx, http.MethodGet, "/packages/search", qs, nil)
+	if err != nil {
+		return fmt.Errorf("error searching packages: %w", err)
+	}
+	if opts.output == outputJSON {
+		_, err := w.Write(data)
+		return err
+	}
+	var result *searchResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("error decoding search results: %w", err)
+	}
+
+	// Print results
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tREPOSITORY\tNAME\tVERSION\tDESCRIPTION")
+	for _, p := range result.Packages {
+		var kind, repoName string
+		if p.Repository != nil {
+			kind = hub.GetKindName(p.Repository.Kind)
+			repoName = p.Repository.Name
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", kind, repoName, p.Name, p.Version, p.Description)
+	}
+	return tw.Flush()
+}
+
+// buildSearchQuery builds the query string of a packages search request from
+// the options provided.
+func buildSearchQuery(opts *searchOptions, query string) (url.Values, error) {
+	qs := url.Values{}
+	if query != "" {
+		qs.Set("ts_query_web", query)
+	}
+	for _, kindName := range opts.kinds {
+		kind, err := hub.GetKindFromName(kindName)
+		if err != nil {
+			return nil, err
+		}
+		qs.Add("kind", strconv.Itoa(int(kind)))
+	}
+	for key, values := range map[string][]string{
+		"user":         opts.users,
+		"org":          opts.orgs,
+		"repo":         opts.repos,
+		"license":      opts.licenses,
+		"capabilities": opts.capabilities,
+		"helm_type":    opts.helmTypes,
+	} {
+		for _, v := range values {
+			qs.Add(key, v)
+		}
+	}
+	for key, v := range map[string]bool{
+		"verified_publisher":  opts.verifiedPublisher,
+		"official":            opts.official,
+		"operators":           opts.operators,
+		"deprecated":          opts.deprecated,
+		"has_provenance":      opts.hasProvenance,
+		"signed":              opts.signed,
+		"has_security_report": opts.hasSecurityReport,
+	} {
+		if v {
+			qs.Set(key, "true")
+		}
+	}
+	for key, v := range map[string]string{
+		"max_severity":       opts.maxSeverity,
+		"kubernetes_version": opts.kubernetesVersion,
+		"image":              opts.image,
+	} {
+		if v != "" {
+			qs.Set(key, v)
+		}
+	}
+	if opts.updatedWithin > 0 {
+		qs.Set("updated_within", strconv.Itoa(opts.updatedWithin))
+	}
+	if opts.sort != "" {
+		qs.Set("sort", opts.sort)
+	}
+	qs.Set("limit", strconv.Itoa(opts.limit))
+	qs.Set("offset", strconv.Itoa(opts.offset))
+	qs.Set("facets", "false")
+	return qs, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/spf13/cobra"
+)
+
+// subscriptionEventKinds represents the events kinds users can subscribe to
+// on a package, keyed by the name used in the command line.
+var subscriptionEventKinds = map[string]hub.EventKind{
+	"new-release":    hub.NewRelease,
+	"security-alert": hub.SecurityAlert,
+}
+
+// subscriptionOptions represents the options that can be passed to the
+// subscribe and unsubscribe commands.
+type subscriptionOptions struct {
+	client hubClientOptions
+	event  string
+}
+
+// newSubscribeCmd creates a new subscribe command.
+func newSubscribeCmd() *cobra.Command {
+	opts := &subscriptionOptions{}
+	subscribeCmd := &cobra.Command{
+		Use:   "subscribe kind/repo/package",
+		Short: "Subscribe to a package's events",
+		Long: `Subscribe to a package's events
+
+Notifications will be sent to the email of the user that owns the API key
+provided.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return subscribe(cmd.Context(), opts, args[0], true, cmd.OutOrStdout())
+		},
+	}
+	opts.client.addFlags(subscribeCmd.Flags())
+	subscribeCmd.Flags().StringVarP(&opts.event, "event", "e", "new-release", "event kind: new-release, security-alert")
+	return subscribeCmd
+}
+
+// newUnsubscribeCmd creates a new unsubscribe command.
+func newUnsubscribeCmd() *cobra.Command {
+	opts := &subscriptionOptions{}
+	unsubscribeCmd := &cobra.Command{
+		Use:   "unsubscribe kind/repo/package",
+		Short: "Unsubscribe from a package's events",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return subscribe(cmd.Context(), opts, args[0], false, cmd.OutOrStdout())
+		},
+	}
+	opts.client.addFlags(unsubscribeCmd.Flags())
+	unsubscribeCmd.Flags().StringVarP(&opts.event, "event", "e", "new-release", "event kind: new-release, security-alert")
+	return unsubscribeCmd
+}
+
+// subscribe subscribes or unsubscribes the user owning the API key provided
+// to the events of the kind provided on the package provided.
+func subscribe(ctx context.Context, opts *subscriptionOptions, pkgRef string, add bool, w io.Writer) error {
+	eventKind, ok := subscriptionEventKinds[opts.event]
+	if !ok {
+		return fmt.Errorf("invalid event kind: %s", opts.event)
+	}
+	ref, err := parsePackageRef(pkgRef)
+	if err != nil {
+		return err
+	}
+	c := newHubClient(&opts.client)
+	if err := c.requireAPIKey(); err != nil {
+		return err
+	}
+	p, _, err := c.getPackage(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	// Add or delete subscription
+	if add {
+		s := &hub.Subscription{
+			PackageID: p.PackageID,
+			EventKind: eventKind,
+		}
+		if _, err := c.do(ctx, http.MethodPost, "/subscriptions", nil, s); err != nil {
+			return fmt.Errorf("error adding subscription: %w", err)
+		}
+		fmt.Fprintf(w, "Subscribed to %s events of %s\n", opts.event, ref)
+	} else {
+		qs := url.Values{}
+		qs.Set("package_id", p.PackageID)
+		qs.Set("event_kind", strconv.Itoa(int(eventKind)))
+		if _, err := c.do(ctx, http.MethodDelete, "/subscriptions", qs, nil); err != nil {
+			return fmt.Errorf("error deleting subscription: %w", err)
+		}
+		fmt.Fprintf(w, "Unsubscribed from %s events of %s\n", opts.event, ref)
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/artifacthub/hub/internal/oci"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	csremote "github.com/sigstore/cosign/pkg/oci/remote"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/provenance"
+	"sigs.k8s.io/yaml"
+)
+
+// signKeyAnnotation represents the Helm annotation used to provide the key
+// used to sign the chart.
+const signKeyAnnotation = "artifacthub.io/signKey"
+
+var (
+	// errNoValidSignature indicates that none of the signatures of the OCI
+	// artifact could be verified with the key provided.
+	errNoValidSignature = errors.New("no valid signature found for the key provided")
+
+	// errSignKeyMismatch indicates that the fingerprint of the key used to
+	// sign the package does not match the one of the package's sign key.
+	errSignKeyMismatch = errors.New("signer fingerprint does not match the package's sign key fingerprint")
+)
+
+// verifyOptions represents the options that can be passed to the verify
+// subcommands.
+type verifyOptions struct {
+	keyring     string
+	prov        string
+	key         string
+	fingerprint string
+}
+
+// newVerifyCmd creates a new verify command.
+func newVerifyCmd() *cobra.Command {
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the signatures of a package",
+	}
+	verifyCmd.AddCommand(
+		newVerifyChartCmd(),
+		newVerifyImageCmd(),
+	)
+	return verifyCmd
+}
+
+// newVerifyChartCmd creates a new verify chart command.
+func newVerifyChartCmd() *cobra.Command {
+	opts := &verifyOptions{}
+	chartCmd := &cobra.Command{
+		Use:   "chart path/to/chart.tgz",
+		Short: "Verify a Helm chart archive against its provenance file",
+		Long: `Verify a Helm chart archive against its provenance file
+
+The chart's signature is verified using the public keys in the keyring
+provided. The fingerprint of the key used to sign the chart is compared with
+the one in the artifacthub.io/signKey annotation, when available.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return verifyChart(opts, args[0], cmd.OutOrStdout())
+		},
+	}
+	chartCmd.Flags().StringVar(&opts.keyring, "keyring", "", "keyring containing the public keys used to verify the chart")
+	chartCmd.Flags().StringVar(&opts.prov, "prov", "", "provenance file (defaults to the chart archive path with the .prov extension)")
+	chartCmd.Flags().StringVar(&opts.fingerprint, "fingerprint", "", "expected signer fingerprint (overrides the artifacthub.io/signKey annotation)")
+	_ = chartCmd.MarkFlagRequired("keyring")
+	return chartCmd
+}
+
+// verifyChart verifies the chart archive provided against its provenance
+// file, printing the verification details to the writer provided.
+func verifyChart(opts *verifyOptions, chartPath string, w io.Writer) error {
+	provPath := opts.prov
+	if provPath == "" {
+		provPath = chartPath + ".prov"
+	}
+
+	// Verify chart signature
+	sig, err := provenance.NewFromKeyring(opts.keyring, "")
+	if err != nil {
+		return fmt.Errorf("error loading keyring: %w", err)
+	}
+	ver, err := sig.Verify(chartPath, provPath)
+	if err != nil {
+		return fmt.Errorf("error verifying chart: %w", err)
+	}
+	identities := make([]string, 0, len(ver.SignedBy.Identities))
+	for identity := range ver.SignedBy.Identities {
+		identities = append(identities, identity)
+	}
+	sort.Strings(identities)
+	fingerprint := fmt.Sprintf("%X", ver.SignedBy.PrimaryKey.Fingerprint)
+
+	// Get expected fingerprint from the chart's sign key when needed
+	expectedFingerprint := opts.fingerprint
+	var signKeyURL string
+	if expectedFingerprint == "" {
+		chrt, err := loader.Load(chartPath)
+		if err != nil {
+			return fmt.Errorf("error loading chart: %w", err)
+		}
+		if v, ok := chrt.Metadata.Annotations[signKeyAnnotation]; ok {
+			var signKey struct {
+				Fingerprint string `json:"fingerprint"`
+				URL         string `json:"url"`
+			}
+			if err := yaml.Unmarshal([]byte(v), &signKey); err != nil {
+				return fmt.Errorf("invalid sign key annotation: %w", err)
+			}
+			expectedFingerprint, signKeyURL = signKey.Fingerprint, signKey.URL
+		}
+	}
+
+	// Print verification details
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Chart:\t%s\n", filepath.Base(chartPath))
+	fmt.Fprintf(tw, "Hash:\t%s\n", ver.FileHash)
+	fmt.Fprintf(tw, "Signed by:\t%s\n", strings.Join(identities, ", "))
+	fmt.Fprintf(tw, "Fingerprint:\t%s\n", fingerprint)
+	return printSignKeyCheck(tw, fingerprint, expectedFingerprint, signKeyURL)
+}
+
+// newVerifyImageCmd creates a new verify image command.
+func newVerifyImageCmd() *cobra.Command {
+	opts := &verifyOptions{}
+	imageCmd := &cobra.Command{
+		Use:   "image reference",
+		Short: "Verify the cosign signatures of an OCI artifact",
+		Long: `Verify the cosign signatures of an OCI artifact
+
+The signatures of the OCI artifact (a container image, a Helm chart or any
+other OCI artifact) are verified using the cosign public key provided. Only
+signatures created with a key are supported, and the transparency log is not
+checked.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return verifyImage(cmd.Context(), opts, args[0], cmd.OutOrStdout())
+		},
+	}
+	imageCmd.Flags().StringVar(&opts.key, "key", "", "cosign public key used to verify the signatures")
+	imageCmd.Flags().StringVar(&opts.fingerprint, "fingerprint", "", "expected signer fingerprint")
+	_ = imageCmd.MarkFlagRequired("key")
+	return imageCmd
+}
+
+// verifyImage verifies the cosign signatures of the OCI artifact provided,
+// printing the verification details to the writer provided.
+func verifyImage(ctx context.Context, opts *verifyOptions, image string, w io.Writer) error {
+	// Load public key
+	data, err := os.ReadFile(opts.key)
+	if err != nil {
+		return fmt.Errorf("error reading key: %w", err)
+	}
+	pubKey, fingerprint, err := oci.ParsePublicKey(data)
+	if err != nil {
+		return err
+	}
+
+	// Get artifact digest and signatures
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return fmt.Errorf("invalid image reference: %w", err)
+	}
+	options := oci.PrepareRemoteOptions(ctx, nil, ref, "", "")
+	desc, err := remote.Head(ref, options...)
+	if err != nil {
+		return fmt.Errorf("error getting artifact digest: %w", err)
+	}
+	sigTag, err := csremote.SignatureTag(ref.Context().Digest(desc.Digest.String()), csremote.WithRemoteOptions(options...))
+	if err != nil {
+		return err
+	}
+	sigs, err := csremote.Signatures(sigTag, csremote.WithRemoteOptions(options...))
+	if err != nil {
+		return fmt.Errorf("error getting signatures: %w", err)
+	}
+	signatures, err := sigs.Get()
+	if err != nil {
+		return fmt.Errorf("error getting signatures: %w", err)
+	}
+
+	// Verify signatures
+	var verified int
+	for _, s := range signatures {
+		payload, err := s.Payload()
+		if err != nil {
+			continue
+		}
+		b64sig, err := s.Base64Signature()
+		if err != nil {
+			continue
+		}
+		if oci.VerifyCosignPayload(pubKey, payload, b64sig, desc.Digest.String()) == nil {
+			verified++
+		}
+	}
+	if verified == 0 {
+		return errNoValidSignature
+	}
+
+	// Print verification details
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Artifact:\t%s\n", ref.Context().Digest(desc.Digest.String()))
+	fmt.Fprintf(tw, "Signatures:\t%d verified (%d found)\n", verified, len(signatures))
+	fmt.Fprintf(tw, "Fingerprint:\t%s\n", fingerprint)
+	return printSignKeyCheck(tw, fingerprint, opts.fingerprint, "")
+}
+
+// printSignKeyCheck prints the result of comparing the signer fingerprint
+// with the expected one, returning an error if they don't match.
+func printSignKeyCheck(tw *tabwriter.Writer, fingerprint, expected, url string) error {
+	normalize := func(s string) string {
+		return strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	}
+	var err error
+	switch {
+	case expected == "":
+		fmt.Fprintf(tw, "Sign key:\tnot provided\n")
+	case normalize(expected) == fingerprint:
+		fmt.Fprintf(tw, "Sign key:\t%s (match)\n", normalize(expected))
+	default:
+		fmt.Fprintf(tw, "Sign key:\t%s (mismatch)\n", normalize(expected))
+		err = errSignKeyMismatch
+	}
+	if url != "" {
+		fmt.Fprintf(tw, "Sign key URL:\t%s\n", url)
+	}
+	if err == nil {
+		fmt.Fprintf(tw, "Result:\tverified\n")
+	}
+	if ferr := tw.Flush(); ferr != nil {
+		return ferr
+	}
+	return err
+}
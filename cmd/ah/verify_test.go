@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/oci"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sigstore/cosign/pkg/oci/empty"
+	"github.com/sigstore/cosign/pkg/oci/mutate"
+	"github.com/sigstore/cosign/pkg/oci/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp" //nolint:staticcheck
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/provenance"
+)
+
+func TestVerifyChartCmd(t *testing.T) {
+	// Setup signing key and keyring
+	dir := t.TempDir()
+	entity, err := openpgp.NewEntity("User 1", "", "user1@email.com", nil)
+	require.NoError(t, err)
+	keyring := filepath.Join(dir, "pubring.gpg")
+	f, err := os.Create(keyring)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(f))
+	require.NoError(t, f.Close())
+	fingerprint := fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)
+
+	// Helper to prepare a signed chart archive
+	prepareChart := func(t *testing.T, signKeyFingerprint string) string {
+		t.Helper()
+		chrt := &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion: chart.APIVersionV2,
+				Name:       "pkg1",
+				Version:    "1.0.0",
+			},
+		}
+		if signKeyFingerprint != "" {
+			chrt.Metadata.Annotations = map[string]string{
+				signKeyAnnotation: fmt.Sprintf("fingerprint: %s\nurl: https://key.url\n", signKeyFingerprint),
+			}
+		}
+		chartPath, err := chartutil.Save(chrt, t.TempDir())
+		require.NoError(t, err)
+		signatory := &provenance.Signatory{Entity: entity}
+		prov, err := signatory.ClearSign(chartPath)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(chartPath+".prov", []byte(prov), 0600))
+		return chartPath
+	}
+
+	t.Run("chart verified, sign key matches", func(t *testing.T) {
+		t.Parallel()
+		chartPath := prepareChart(t, fingerprint)
+		var b bytes.Buffer
+		require.NoError(t, verifyChart(&verifyOptions{keyring: keyring}, chartPath, &b))
+		out := b.String()
+		assert.Contains(t, out, "Chart:         pkg1-1.0.0.tgz\n")
+		assert.Contains(t, out, "Hash:          sha256:")
+		assert.Contains(t, out, "Signed by:     User 1 <user1@email.com>\n")
+		assert.Contains(t, out, "Fingerprint:   "+fingerprint+"\n")
+		assert.Contains(t, out, "Sign key:      "+fingerprint+" (match)\n")
+		assert.Contains(t, out, "Sign key URL:  https://key.url\n")
+		assert.Contains(t, out, "Result:        verified\n")
+	})
+
+	t.Run("chart verified, sign key not provided", func(t *testing.T) {
+		t.Parallel()
+		chartPath := prepareChart(t, "")
+		var b bytes.Buffer
+		require.NoError(t, verifyChart(&verifyOptions{keyring: keyring}, chartPath, &b))
+		assert.Contains(t, b.String(), "Sign key:     not provided\n")
+	})
+
+	t.Run("chart verified, sign key mismatch", func(t *testing.T) {
+		t.Parallel()
+		chartPath := prepareChart(t, "0000000000000000000000000000000000000000")
+		var b bytes.Buffer
+		err := verifyChart(&verifyOptions{keyring: keyring}, chartPath, &b)
+		assert.Equal(t, errSignKeyMismatch, err)
+		assert.Contains(t, b.String(), "(mismatch)\n")
+		assert.NotContains(t, b.String(), "Result:")
+	})
+
+	t.Run("chart modified after signing", func(t *testing.T) {
+		t.Parallel()
+		chartPath := prepareChart(t, "")
+		provPath := filepath.Join(t.TempDir(), "pkg1.prov")
+		data, err := os.ReadFile(chartPath + ".prov")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(provPath, data, 0600))
+		require.NoError(t, os.WriteFile(chartPath, []byte("modified"), 0600))
+		err = verifyChart(&verifyOptions{keyring: keyring, prov: provPath}, chartPath, &bytes.Buffer{})
+		assert.ErrorContains(t, err, "error verifying chart")
+	})
+}
+
+func TestVerifyImageCmd(t *testing.T) {
+	ctx := context.Background()
+
+	// Setup registry and push image
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	host := strings.TrimPrefix(s.URL, "http://")
+	ref, err := name.ParseReference(host + "/repo:1.0.0")
+	require.NoError(t, err)
+	img, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+	digest, err := img.Digest()
+	require.NoError(t, err)
+
+	// Setup keys
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	writePublicKey := func(t *testing.T, k *ecdsa.PrivateKey) string {
+		t.Helper()
+		der, err := x509.MarshalPKIXPublicKey(&k.PublicKey)
+		require.NoError(t, err)
+		keyFile := filepath.Join(t.TempDir(), "cosign.pub")
+		data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+		require.NoError(t, os.WriteFile(keyFile, data, 0600))
+		return keyFile
+	}
+	keyFile := writePublicKey(t, privKey)
+	keyData, err := os.ReadFile(keyFile)
+	require.NoError(t, err)
+	_, fingerprint, err := oci.ParsePublicKey(keyData)
+	require.NoError(t, err)
+
+	// Sign image
+	payload := []byte(fmt.Sprintf(
+		`{"critical":{"identity":{"docker-reference":"%s/repo"},"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"},"optional":null}`,
+		host, digest,
+	))
+	hash := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, privKey, hash[:])
+	require.NoError(t, err)
+	cosignSig, err := static.NewSignature(payload, base64.StdEncoding.EncodeToString(sig))
+	require.NoError(t, err)
+	sigs, err := mutate.AppendSignatures(empty.Signatures(), cosignSig)
+	require.NoError(t, err)
+	sigTag, err := name.NewTag(fmt.Sprintf("%s/repo:%s-%s.sig", host, digest.Algorithm, digest.Hex))
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(sigTag, sigs))
+
+	t.Run("image verified", func(t *testing.T) {
+		var b bytes.Buffer
+		opts := &verifyOptions{key: keyFile, fingerprint: strings.ToLower(fingerprint)}
+		require.NoError(t, verifyImage(ctx, opts, ref.String(), &b))
+		out := b.String()
+		assert.Contains(t, out, fmt.Sprintf("Artifact:     %s/repo@%s\n", host, digest))
+		assert.Contains(t, out, "Signatures:   1 verified (1 found)\n")
+		assert.Contains(t, out, "Fingerprint:  "+fingerprint+"\n")
+		assert.Contains(t, out, "(match)\n")
+		assert.Contains(t, out, "Result:       verified\n")
+	})
+
+	t.Run("no valid signature for key", func(t *testing.T) {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		opts := &verifyOptions{key: writePublicKey(t, otherKey)}
+		err = verifyImage(ctx, opts, ref.String(), &bytes.Buffer{})
+		assert.Equal(t, errNoValidSignature, err)
+	})
+
+	t.Run("unsigned image", func(t *testing.T) {
+		ref2, err := name.ParseReference(host + "/repo:2.0.0")
+		require.NoError(t, err)
+		img2, err := random.Image(1024, 1)
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(ref2, img2))
+		err = verifyImage(ctx, &verifyOptions{key: keyFile}, ref2.String(), &bytes.Buffer{})
+		assert.Equal(t, errNoValidSignature, err)
+	})
+}
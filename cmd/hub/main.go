@@ -12,6 +12,7 @@ import (
 
 	"github.com/artifacthub/hub/internal/apikey"
 	"github.com/artifacthub/hub/internal/authz"
+	"github.com/artifacthub/hub/internal/change"
 	"github.com/artifacthub/hub/internal/email"
 	"github.com/artifacthub/hub/internal/event"
 	"github.com/artifacthub/hub/internal/handlers"
@@ -24,6 +25,7 @@ import (
 	"github.com/artifacthub/hub/internal/repo"
 	"github.com/artifacthub/hub/internal/stats"
 	"github.com/artifacthub/hub/internal/subscription"
+	"github.com/artifacthub/hub/internal/tracker/source/helm"
 	"github.com/artifacthub/hub/internal/user"
 	"github.com/artifacthub/hub/internal/util"
 	"github.com/artifacthub/hub/internal/webhook"
@@ -32,6 +34,9 @@ import (
 )
 
 func main() {
+	// Run as a chart render worker when requested
+	helm.HandleRenderWorker()
+
 	// Setup configuration and logger
 	cfg, err := util.SetupConfig("hub")
 	if err != nil {
@@ -69,6 +74,7 @@ func main() {
 		WebhookManager:      webhook.NewManager(db),
 		APIKeyManager:       apikey.NewManager(db),
 		StatsManager:        stats.NewManager(db),
+		ChangeManager:       change.NewManager(db),
 		ImageStore:          pg.NewImageStore(cfg, db, hc),
 		Authorizer:          az,
 		HTTPClient:          hc,
@@ -109,6 +115,11 @@ func main() {
 	wg.Add(1)
 	go vt.Flusher(ctx, &wg)
 
+	// Launch changes pruner
+	changesPruner := change.NewPruner(db, change.WithRetention(cfg.GetDuration("server.changesRetention")))
+	wg.Add(1)
+	go changesPruner.Run(ctx, &wg)
+
 	// Setup and launch events dispatcher
 	eSvc := &event.Services{
 		DB:                  db,
@@ -69,13 +69,21 @@ func main() {
 		log.Fatal().Err(err).Msg("image store setup failed")
 	}
 	ec := repo.NewErrorsCollector(rm, repo.Tracker)
+	var clonerOpts []func(c *repo.Cloner)
+	if cachePath := cfg.GetString("tracker.cloneCache.path"); cachePath != "" {
+		cache, err := repo.NewCloneCache(cachePath, int64(cfg.GetSizeInBytes("tracker.cloneCache.maxSize")))
+		if err != nil {
+			log.Fatal().Err(err).Msg("clone cache setup failed")
+		}
+		clonerOpts = append(clonerOpts, repo.WithCloneCache(cache))
+	}
 	op := oci.NewPuller(cfg)
 	svc := &hub.TrackerServices{
 		Ctx:                ctx,
 		Cfg:                cfg,
 		Rm:                 rm,
 		Pm:                 pm,
-		Rc:                 &repo.Cloner{},
+		Rc:                 repo.NewCloner(hc, clonerOpts...),
 		Oe:                 &repo.OLMOCIExporter{},
 		Ec:                 ec,
 		Hc:                 hc,
@@ -123,14 +131,14 @@ L:
 				t := tracker.New(svc, r, logger)
 				if err := t.Run(); err != nil {
 					logger.Error().Err(err).Send()
-					svc.Ec.Append(r.RepositoryID, err.Error())
+					svc.Ec.Append(r.RepositoryID, &hub.RepositoryError{Class: hub.ErrorClassRepository, Err: err})
 				}
 			}()
 			select {
 			case <-done:
 			case <-time.After(cfg.GetDuration("tracker.repositoryTimeout")):
 				logger.Error().Err(errTimeout).Send()
-				svc.Ec.Append(r.RepositoryID, errTimeout.Error())
+				svc.Ec.Append(r.RepositoryID, &hub.RepositoryError{Class: hub.ErrorClassTimeout, Err: errTimeout})
 			}
 		}(r)
 	}
@@ -7,6 +7,11 @@
 {{ template "api_keys/get_user_api_keys.sql" }}
 {{ template "api_keys/update_api_key.sql" }}
 
+{{ template "changes/get_change_data.sql" }}
+{{ template "changes/get_changes.sql" }}
+{{ template "changes/get_changes_export.sql" }}
+{{ template "changes/prune_changes.sql" }}
+
 {{ template "events/get_pending_event.sql" }}
 
 {{ template "images/get_image.sql" }}
@@ -39,7 +44,10 @@
 {{ template "packages/get_helm_exporter_dump.sql" }}
 {{ template "packages/get_package.sql" }}
 {{ template "packages/get_package_changelog.sql" }}
+{{ template "packages/get_package_dependencies.sql" }}
+{{ template "packages/get_package_dependents.sql" }}
 {{ template "packages/get_package_summary.sql" }}
+{{ template "packages/get_packages_by_image.sql" }}
 {{ template "packages/get_packages_starred_by_user.sql" }}
 {{ template "packages/get_package_stars.sql" }}
 {{ template "packages/get_package_views.sql" }}
@@ -49,10 +57,12 @@
 {{ template "packages/get_snapshots_to_scan.sql" }}
 {{ template "packages/is_latest.sql" }}
 {{ template "packages/register_package.sql" }}
+{{ template "packages/register_snapshot_dependencies.sql" }}
 {{ template "packages/search_packages.sql" }}
 {{ template "packages/search_packages_monocular.sql" }}
 {{ template "packages/semver_gt.sql" }}
 {{ template "packages/semver_gte.sql" }}
+{{ template "packages/semver_satisfies.sql" }}
 {{ template "packages/toggle_star.sql" }}
 {{ template "packages/unregister_package.sql" }}
 {{ template "packages/update_packages_views.sql" }}
@@ -62,6 +72,7 @@
 {{ template "repositories/delete_repository.sql" }}
 {{ template "repositories/get_repository_by_name.sql" }}
 {{ template "repositories/get_repository_packages_digest.sql" }}
+{{ template "repositories/get_repository_tracking_runs.sql" }}
 {{ template "repositories/search_repositories.sql" }}
 {{ template "repositories/set_last_scanning_results.sql" }}
 {{ template "repositories/set_last_tracking_results.sql" }}
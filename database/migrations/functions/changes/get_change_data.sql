@@ -0,0 +1,35 @@
+-- get_change_data returns the current state of the entity identified by the
+-- input provided as a json object. Nothing is returned if the entity does not
+-- exist anymore.
+create or replace function get_change_data(
+    p_entity_kind text,
+    p_repository_id uuid,
+    p_package_id uuid,
+    p_version text
+)
+returns json as $$
+begin
+    case p_entity_kind
+    when 'repository' then
+        return (select get_repository_summary(p_repository_id));
+    when 'package' then
+        return (select get_package_summary(jsonb_build_object('package_id', p_package_id)));
+    when 'package_version' then
+        return (select get_package(jsonb_build_object('package_id', p_package_id, 'version', p_version)));
+    when 'security_report' then
+        return (
+            select json_strip_nulls(json_build_object(
+                'summary', security_report_summary,
+                'created_at', floor(extract(epoch from security_report_created_at)),
+                'report', security_report
+            ))
+            from snapshot
+            where package_id = p_package_id
+            and version = p_version
+            and security_report_summary is not null
+        );
+    else
+        return null;
+    end case;
+end
+$$ language plpgsql;
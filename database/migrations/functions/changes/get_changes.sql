@@ -0,0 +1,57 @@
+-- get_changes returns the changes registered after the cursor provided as a
+-- json array, alongside the cursor that should be used to get the next ones.
+-- Changes are sorted by the transaction that registered them. Only changes
+-- registered by transactions that finished before the oldest one still in
+-- progress started are returned, so that no changes are committed later
+-- behind the cursor returned. Cursors older than the most recent change
+-- pruned are rejected, as some of the changes after them may be missing.
+create or replace function get_changes(p_since jsonb, p_limit int)
+returns table(data json, next_cursor jsonb) as $$
+begin
+    if p_since is not null and exists (
+        select 1 from change_pruned cp
+        where (cp.tx_id, cp.change_id) > ((p_since->>'tx_id')::bigint, (p_since->>'change_id')::bigint)
+    ) then
+        raise 'cursor expired';
+    end if;
+
+    return query
+    with changes_page as (
+        select *
+        from change
+        where tx_id < txid_snapshot_xmin(txid_current_snapshot())
+        and
+            case when p_since is not null then
+                (tx_id, change_id) > ((p_since->>'tx_id')::bigint, (p_since->>'change_id')::bigint)
+            else true end
+        order by tx_id asc, change_id asc
+        limit p_limit
+    )
+    select
+        (
+            select coalesce(json_agg(json_strip_nulls(json_build_object(
+                'kind', entity_kind,
+                'operation', operation,
+                'repository_id', repository_id,
+                'package_id', package_id,
+                'version', version,
+                'ts', floor(extract(epoch from created_at)),
+                'data', (
+                    case when operation <> 'delete' then
+                        get_change_data(entity_kind, repository_id, package_id, version)
+                    end
+                )
+            )) order by tx_id asc, change_id asc), '[]')
+            from changes_page
+        ),
+        coalesce(
+            (
+                select jsonb_build_object('tx_id', tx_id, 'change_id', change_id)
+                from changes_page
+                order by tx_id desc, change_id desc
+                limit 1
+            ),
+            p_since
+        );
+end
+$$ language plpgsql;
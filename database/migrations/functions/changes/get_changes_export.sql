@@ -0,0 +1,97 @@
+-- get_changes_export returns a page of the catalog export as newline delimited
+-- json. The export includes records representing the current state of all
+-- repositories, packages, packages versions and security reports, in that
+-- order. Alongside the records, the cursor to get the next page of the export
+-- is returned, as well as the cursor that should be used to get the changes
+-- registered since the export was started.
+create or replace function get_changes_export(p_cursor jsonb, p_limit int)
+returns table(data text, next_cursor jsonb, since jsonb) as $$
+declare
+    v_entity_kinds text[] := array['repository', 'package', 'package_version', 'security_report'];
+    v_entity_kind text := coalesce(p_cursor->>'kind', 'repository');
+    v_after jsonb := p_cursor->'after';
+    v_since jsonb := coalesce(
+        p_cursor->'since',
+        jsonb_build_object('tx_id', txid_snapshot_xmin(txid_current_snapshot()), 'change_id', 0)
+    );
+    v_next_entity_kind text;
+begin
+    if array_position(v_entity_kinds, v_entity_kind) is null then
+        raise 'invalid entity kind: %', v_entity_kind;
+    end if;
+    v_next_entity_kind := v_entity_kinds[array_position(v_entity_kinds, v_entity_kind) + 1];
+
+    return query
+    with entities as (
+        select r.repository_id, null::uuid as package_id, null::text as version
+        from repository r
+        where v_entity_kind = 'repository'
+        and
+            case when v_after is not null then
+                r.repository_id > (v_after->>'repository_id')::uuid
+            else true end
+        union all
+        select p.repository_id, p.package_id, null
+        from package p
+        where v_entity_kind = 'package'
+        and
+            case when v_after is not null then
+                p.package_id > (v_after->>'package_id')::uuid
+            else true end
+        union all
+        select null, s.package_id, s.version
+        from snapshot s
+        where v_entity_kind in ('package_version', 'security_report')
+        and
+            case when v_entity_kind = 'security_report' then
+                s.security_report_summary is not null
+            else true end
+        and
+            case when v_after is not null then
+                (s.package_id, s.version) > ((v_after->>'package_id')::uuid, v_after->>'version')
+            else true end
+    ), entities_page as (
+        select *, row_number() over (order by package_id asc, version asc, repository_id asc) as n
+        from (
+            select *
+            from entities
+            order by package_id asc, version asc, repository_id asc
+            limit p_limit + 1
+        ) e
+    )
+    select
+        (
+            select coalesce(string_agg(jsonb_strip_nulls(jsonb_build_object(
+                'kind', v_entity_kind,
+                'operation', 'create',
+                'repository_id', repository_id,
+                'package_id', package_id,
+                'version', version,
+                'data', get_change_data(v_entity_kind, repository_id, package_id, version)
+            ))::text, E'\n' order by n), '')
+            from entities_page
+            where n <= p_limit
+        ),
+        (
+            case
+                when exists (select 1 from entities_page where n = p_limit + 1) then (
+                    select jsonb_build_object(
+                        'kind', v_entity_kind,
+                        'after', jsonb_strip_nulls(jsonb_build_object(
+                            'repository_id', repository_id,
+                            'package_id', package_id,
+                            'version', version
+                        )),
+                        'since', v_since
+                    )
+                    from entities_page
+                    where n = p_limit
+                )
+                when v_next_entity_kind is not null then
+                    jsonb_build_object('kind', v_next_entity_kind, 'since', v_since)
+                else null
+            end
+        ),
+        v_since;
+end
+$$ language plpgsql;
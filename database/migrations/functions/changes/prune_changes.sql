@@ -0,0 +1,29 @@
+-- prune_changes deletes the changes registered before the date provided. The
+-- most recent change deleted is tracked, so that requests to get the changes
+-- registered after it can be rejected, as some of them may be missing.
+create or replace function prune_changes(p_lock_key bigint, p_before timestamptz)
+returns void as $$
+    -- Make sure only one pruning is processed at a time
+    select pg_advisory_xact_lock(p_lock_key);
+
+    -- Delete changes registered before the date provided
+    with deleted_changes as (
+        delete from change
+        where created_at < p_before
+        returning tx_id, change_id
+    )
+    insert into change_pruned (tx_id, change_id)
+    select tx_id, change_id
+    from deleted_changes
+    order by tx_id desc, change_id desc
+    limit 1;
+
+    -- Keep track only of the most recent change deleted
+    delete from change_pruned
+    where (tx_id, change_id) < (
+        select tx_id, change_id
+        from change_pruned
+        order by tx_id desc, change_id desc
+        limit 1
+    );
+$$ language sql;
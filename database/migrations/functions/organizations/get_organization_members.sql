@@ -1,11 +1,14 @@
 -- get_organization_members returns the members of the organization provided as
--- a json array.
+-- a json array. Results can be paginated using a limit and an offset or a
+-- cursor, which is returned along with the results when there are more
+-- members available.
 create or replace function get_organization_members(
     p_requesting_user_id uuid,
     p_org_name text,
     p_limit int,
-    p_offset int
-) returns table(data json, total_count bigint) as $$
+    p_offset int,
+    p_cursor jsonb
+) returns table(data json, total_count bigint, next_cursor jsonb) as $$
 begin
     if not user_belongs_to_organization(p_requesting_user_id, p_org_name) then
         raise insufficient_privilege;
@@ -13,26 +16,65 @@ begin
 
     return query
     with organization_members as (
-        select u.alias, u.first_name, u.last_name, uo.confirmed
+        select
+            u.alias,
+            u.first_name,
+            u.last_name,
+            uo.confirmed,
+            -- Sort key equivalent to first_name, last_name asc (nulls last)
+            u.first_name is null as first_name_null,
+            coalesce(u.first_name, '') as first_name_key,
+            u.last_name is null as last_name_null,
+            coalesce(u.last_name, '') as last_name_key
         from "user" u
         join user__organization uo using (user_id)
         join organization o using (organization_id)
         where o.name = p_org_name
+    ), organization_members_page as (
+        select *, row_number() over (
+            order by first_name_null, first_name_key, last_name_null, last_name_key, alias
+        ) as n
+        from (
+            select *
+            from organization_members
+            where
+                case when p_cursor is not null then
+                    (first_name_null, first_name_key, last_name_null, last_name_key, alias) > (
+                        (p_cursor->>'first_name_null')::boolean,
+                        p_cursor->>'first_name_key',
+                        (p_cursor->>'last_name_null')::boolean,
+                        p_cursor->>'last_name_key',
+                        p_cursor->>'alias'
+                    )
+                else true end
+            order by first_name_null, first_name_key, last_name_null, last_name_key, alias
+            limit (case when p_limit = 0 then null else p_limit + 1 end)
+            offset p_offset
+        ) om
     )
     select
-        coalesce(json_agg(json_strip_nulls(json_build_object(
-            'alias', alias,
-            'first_name', first_name,
-            'last_name', last_name,
-            'confirmed', confirmed
-        ))), '[]'),
-        (select count(*) from organization_members)
-    from (
-        select *
-        from organization_members
-        order by first_name, last_name asc
-        limit (case when p_limit = 0 then null else p_limit end)
-        offset p_offset
-    ) u;
+        (
+            select coalesce(json_agg(json_strip_nulls(json_build_object(
+                'alias', alias,
+                'first_name', first_name,
+                'last_name', last_name,
+                'confirmed', confirmed
+            )) order by n), '[]')
+            from organization_members_page
+            where p_limit = 0 or n <= p_limit
+        ),
+        (select count(*) from organization_members),
+        (
+            select jsonb_build_object(
+                'first_name_null', first_name_null,
+                'first_name_key', first_name_key,
+                'last_name_null', last_name_null,
+                'last_name_key', last_name_key,
+                'alias', alias
+            )
+            from organization_members_page
+            where p_limit > 0 and n = p_limit
+            and exists (select 1 from organization_members_page where n = p_limit + 1)
+        );
 end
 $$ language plpgsql;
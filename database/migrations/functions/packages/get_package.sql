@@ -92,6 +92,8 @@ begin
         'recommendations', s.recommendations,
         'screenshots', s.screenshots,
         'sign_key', s.sign_key,
+        'signature_verification', s.signature_verification,
+        'provenance', s.provenance,
         'repository', (select get_repository_summary(r.repository_id)),
         'stats', json_build_object(
             'subscriptions', (select count(*) from subscription where package_id = v_package_id),
@@ -0,0 +1,23 @@
+-- get_package_dependencies returns the dependencies of the package version
+-- provided as a json array. Dependencies linked to packages in the hub include
+-- some information about them.
+create or replace function get_package_dependencies(p_package_id uuid, p_version text)
+returns setof json as $$
+    select coalesce(json_agg(json_strip_nulls(json_build_object(
+        'kind', d.kind,
+        'name', d.name,
+        'required_version', d.required_version,
+        'repository_url', d.repository_url,
+        'package', case when p.package_id is not null then json_build_object(
+            'package_id', p.package_id,
+            'name', p.name,
+            'normalized_name', p.normalized_name,
+            'version', d.dependency_version,
+            'repository', (select get_repository_summary(p.repository_id))
+        ) end
+    )) order by d.kind asc, d.name asc, p.name asc), '[]')
+    from snapshot_dependency d
+    left join package p on p.package_id = d.dependency_package_id
+    where d.package_id = p_package_id
+    and d.version = p_version;
+$$ language sql;
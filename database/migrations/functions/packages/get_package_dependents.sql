@@ -0,0 +1,55 @@
+-- get_package_dependents returns the packages that depend on the package
+-- provided as a json array. Only the latest version of the dependent packages
+-- is considered. When a version range is provided, only the dependents whose
+-- dependency has been resolved to a version of the package that satisfies it
+-- will be returned.
+create or replace function get_package_dependents(p_input jsonb)
+returns table(data json, total_count bigint) as $$
+    with dependents as (
+        select distinct on (p.package_id)
+            p.package_id,
+            p.name,
+            p.normalized_name,
+            s.version,
+            s.app_version,
+            d.kind,
+            d.required_version,
+            d.dependency_version,
+            s.ts,
+            p.repository_id
+        from snapshot_dependency d
+        join snapshot s using (package_id, version)
+        join package p using (package_id)
+        where d.dependency_package_id = (p_input->>'package_id')::uuid
+        and s.version = p.latest_version
+        and
+            case when p_input ? 'version_range' then
+                d.dependency_version is not null
+                and semver_satisfies(d.dependency_version, p_input->>'version_range')
+            else
+                true
+            end
+        order by p.package_id, d.kind asc, d.name asc
+    )
+    select
+        coalesce(json_agg(json_strip_nulls(json_build_object(
+            'package_id', package_id,
+            'name', name,
+            'normalized_name', normalized_name,
+            'version', version,
+            'app_version', app_version,
+            'kind', kind,
+            'required_version', required_version,
+            'dependency_version', dependency_version,
+            'ts', floor(extract(epoch from ts)),
+            'repository', (select get_repository_summary(repository_id))
+        ))), '[]'),
+        (select count(*) from dependents)
+    from (
+        select *
+        from dependents
+        order by name asc, ts desc
+        limit (case when (p_input->>'limit')::int = 0 then null else (p_input->>'limit')::int end)
+        offset coalesce((p_input->>'offset')::int, 0)
+    ) dp
+$$ language sql;
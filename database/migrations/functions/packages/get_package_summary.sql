@@ -33,6 +33,8 @@ begin
         'deprecated', s.deprecated,
         'signed', s.signed,
         'signatures', s.signatures,
+        'signature_verification', s.signature_verification,
+        'provenance', s.provenance,
         'security_report_summary', s.security_report_summary,
         'all_containers_images_whitelisted', are_all_containers_images_whitelisted(s.containers_images),
         'production_organizations_count', (select nullif(
@@ -0,0 +1,51 @@
+-- get_packages_by_image returns the packages versions that use the container
+-- image provided as a json array. Any tag or digest of the image will match,
+-- unless one of them is included in the image reference provided.
+create or replace function get_packages_by_image(p_input jsonb)
+returns table(data json, total_count bigint) as $$
+    with packages_versions as (
+        select distinct on (s.package_id, s.version)
+            p.package_id,
+            p.name,
+            p.normalized_name,
+            s.version,
+            s.app_version,
+            s.version = p.latest_version as latest,
+            si.image,
+            s.ts,
+            p.repository_id
+        from parse_image_ref(p_input->>'image') q
+        join snapshot_image si using (repository)
+        join snapshot s using (package_id, version)
+        join package p using (package_id)
+        where (q.tag is null or si.tag = q.tag)
+        and (q.digest is null or si.digest = q.digest)
+        and
+            case when (p_input->>'latest_only')::boolean = true then
+                s.version = p.latest_version
+            else
+                true
+            end
+        order by s.package_id, s.version, si.image
+    )
+    select
+        coalesce(json_agg(json_strip_nulls(json_build_object(
+            'package_id', package_id,
+            'name', name,
+            'normalized_name', normalized_name,
+            'version', version,
+            'app_version', app_version,
+            'latest', latest,
+            'image', image,
+            'ts', floor(extract(epoch from ts)),
+            'repository', (select get_repository_summary(repository_id))
+        ))), '[]'),
+        (select count(*) from packages_versions)
+    from (
+        select *
+        from packages_versions
+        order by name asc, ts desc
+        limit (case when (p_input->>'limit')::int = 0 then null else (p_input->>'limit')::int end)
+        offset coalesce((p_input->>'offset')::int, 0)
+    ) pv
+$$ language sql;
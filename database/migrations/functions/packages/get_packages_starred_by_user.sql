@@ -1,22 +1,40 @@
 -- get_packages_starred_by_user returns the packages starred by the user as a
--- json array.
-create or replace function get_packages_starred_by_user(p_user_id uuid, p_limit int, p_offset int)
-returns table(data json, total_count bigint) as $$
+-- json array. Results can be paginated using a limit and an offset or a
+-- cursor, which is returned along with the results when there are more
+-- packages available.
+create or replace function get_packages_starred_by_user(p_user_id uuid, p_limit int, p_offset int, p_cursor jsonb)
+returns table(data json, total_count bigint, next_cursor jsonb) as $$
     with user_starred_packages as (
         select p.package_id, p.name
         from package p
         join user_starred_package usp using (package_id)
         where usp.user_id = p_user_id
+    ), user_starred_packages_page as (
+        select *, row_number() over (order by name asc, package_id asc) as n
+        from (
+            select *
+            from user_starred_packages
+            where
+                case when p_cursor is not null then
+                    (name, package_id) > (p_cursor->>'name', (p_cursor->>'package_id')::uuid)
+                else true end
+            order by name asc, package_id asc
+            limit (case when p_limit = 0 then null else p_limit + 1 end)
+            offset p_offset
+        ) usp
     )
     select
-        coalesce(json_agg(pkgJSON), '[]'),
-        (select count(*) from user_starred_packages)
-    from (
-        select pkgJSON
-        from user_starred_packages usp
-        cross join get_package_summary(jsonb_build_object('package_id', usp.package_id)) as pkgJSON
-        order by usp.name asc
-        limit (case when p_limit = 0 then null else p_limit end)
-        offset p_offset
-    ) ps
+        (
+            select coalesce(json_agg(pkgJSON order by uspp.n), '[]')
+            from user_starred_packages_page uspp
+            cross join get_package_summary(jsonb_build_object('package_id', uspp.package_id)) as pkgJSON
+            where p_limit = 0 or uspp.n <= p_limit
+        ),
+        (select count(*) from user_starred_packages),
+        (
+            select jsonb_build_object('name', name, 'package_id', package_id)
+            from user_starred_packages_page
+            where p_limit > 0 and n = p_limit
+            and exists (select 1 from user_starred_packages_page where n = p_limit + 1)
+        );
 $$ language sql;
@@ -24,6 +24,7 @@ returns setof json as $$
         join repository r using (repository_id)
         where containers_images is not null
         and r.scanner_disabled = false
+        and (r.data is null or not r.data ? 'federation') -- Mirrored packages are scanned upstream
         and s.ts > (current_timestamp - '1 year'::interval)
         and (
             security_report is null
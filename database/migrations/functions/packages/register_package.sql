@@ -22,6 +22,7 @@ declare
     v_previous_latest_version text;
     v_previous_latest_version_ts timestamptz;
     v_repository_disabled boolean;
+    v_repository_federated boolean;
     v_repository_kind_id integer;
     v_ts timestamptz;
     v_ts_publisher text[];
@@ -34,8 +35,13 @@ begin
     end if;
 
     -- Get some repository information (some of it for tsdoc)
-    select r.disabled, array[r.name, r.display_name], array[u.alias, o.name, o.display_name, v_provider], repository_kind_id
-    into v_repository_disabled, v_ts_repository, v_ts_publisher, v_repository_kind_id
+    select
+        r.disabled,
+        coalesce(r.data ? 'federation', false),
+        array[r.name, r.display_name],
+        array[u.alias, o.name, o.display_name, v_provider],
+        repository_kind_id
+    into v_repository_disabled, v_repository_federated, v_ts_repository, v_ts_publisher, v_repository_kind_id
     from repository r
     left join "user" u using (user_id)
     left join organization o using (organization_id)
@@ -172,6 +178,8 @@ begin
         recommendations,
         screenshots,
         sign_key,
+        signature_verification,
+        provenance,
         relative_path,
         ts
     ) values (
@@ -206,6 +214,8 @@ begin
         nullif(p_pkg->'recommendations', 'null'),
         nullif(p_pkg->'screenshots', 'null'),
         nullif(p_pkg->'sign_key', 'null'),
+        nullif(p_pkg->'signature_verification', 'null'),
+        nullif(p_pkg->'provenance', 'null'),
         nullif(p_pkg->>'relative_path', ''),
         v_ts
     )
@@ -240,9 +250,46 @@ begin
         recommendations = excluded.recommendations,
         screenshots = excluded.screenshots,
         sign_key = excluded.sign_key,
+        signature_verification = excluded.signature_verification,
+        provenance = excluded.provenance,
         relative_path = excluded.relative_path,
         ts = v_ts;
 
+    -- Security report summary provided with the package version. It's only
+    -- trusted for packages mirrored from a remote Artifact Hub instance, as
+    -- they are not scanned locally
+    if v_repository_federated and nullif(p_pkg->'security_report_summary', 'null') is not null then
+        update snapshot set
+            security_report_summary = p_pkg->'security_report_summary',
+            security_report_created_at = coalesce(
+                to_timestamp((p_pkg->>'security_report_created_at')::int),
+                current_timestamp
+            )
+        where package_id = v_package_id
+        and version = v_version;
+    end if;
+
+    -- Containers images used by the package version (indexed to allow finding
+    -- the packages that use a given image)
+    delete from snapshot_image where package_id = v_package_id and version = v_version;
+    insert into snapshot_image (package_id, version, image, repository, tag, digest)
+    select
+        v_package_id,
+        v_version,
+        e->>'image',
+        r.repository,
+        case when r.tag is null and r.digest is null then 'latest' else r.tag end,
+        r.digest
+    from jsonb_array_elements(
+        case when jsonb_typeof(p_pkg->'containers_images') = 'array' then p_pkg->'containers_images' end
+    ) e
+    cross join parse_image_ref(e->>'image') r
+    where nullif(e->>'image', '') is not null;
+
+    -- Dependencies of the package version (linked to other packages in the hub
+    -- when possible)
+    perform register_snapshot_dependencies(v_package_id, v_version);
+
     -- Register new release event if package's latest version has been updated
     v_latest_version_updated := false;
     case v_repository_kind_id
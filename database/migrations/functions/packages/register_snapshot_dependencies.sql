@@ -0,0 +1,179 @@
+-- register_snapshot_dependencies registers the dependencies of the package
+-- version provided, linking them to the packages in the hub that satisfy them
+-- when possible. Helm charts dependencies are resolved using the repository
+-- url (preferring an exact match) and the name of the chart, picking the
+-- highest version that satisfies the version range, whereas the APIs required
+-- by OLM operators are resolved to the operators that own them. The
+-- dependencies of other packages on the package provided (or satisfied by it)
+-- are resolved again as well, as the version registered may change the version
+-- that satisfies them.
+create or replace function register_snapshot_dependencies(p_package_id uuid, p_version text)
+returns void as $$
+declare
+    v_name text;
+    v_repository_id uuid;
+    v_repository_kind_id int;
+    v_repository_url text;
+    v_data jsonb;
+    v_crds jsonb;
+begin
+    -- Get package version information
+    select p.name, r.repository_id, r.repository_kind_id, r.url, s.data, s.crds
+    into v_name, v_repository_id, v_repository_kind_id, v_repository_url, v_data, v_crds
+    from snapshot s
+    join package p using (package_id)
+    join repository r using (repository_id)
+    where s.package_id = p_package_id
+    and s.version = p_version;
+
+    -- Register package version dependencies
+    delete from snapshot_dependency where package_id = p_package_id and version = p_version;
+    case v_repository_kind_id
+        when 0 then -- Helm
+            insert into snapshot_dependency (
+                package_id,
+                version,
+                kind,
+                name,
+                required_version,
+                repository_url,
+                dependency_package_id,
+                dependency_version
+            )
+            select
+                p_package_id,
+                p_version,
+                'chart',
+                dep->>'name',
+                nullif(dep->>'version', ''),
+                nullif(dep->>'repository', ''),
+                dp.package_id,
+                (
+                    select s.version
+                    from snapshot s
+                    where s.package_id = dp.package_id
+                    and semver_satisfies(s.version, dep->>'version')
+                    order by (regexp_match(s.version, '^v?(\d+)\.(\d+)\.(\d+)'))::int[] desc nulls last, s.ts desc
+                    limit 1
+                )
+            from jsonb_array_elements(
+                case when jsonb_typeof(v_data->'dependencies') = 'array' then v_data->'dependencies' end
+            ) dep
+            left join lateral (
+                select p.package_id
+                from package p
+                join repository r using (repository_id)
+                where p.name = dep->>'name'
+                and r.repository_kind_id = 0
+                and
+                    case when nullif(dep->>'repository', '') is null or starts_with(dep->>'repository', 'file://') then
+                        r.repository_id = v_repository_id
+                    else
+                        trim(trailing from r.url, '/') in (
+                            trim(trailing from dep->>'repository', '/'),
+                            trim(trailing from dep->>'repository', '/') || '/' || (dep->>'name')
+                        )
+                    end
+                order by trim(trailing from r.url, '/') = trim(trailing from dep->>'repository', '/') desc, p.package_id asc
+                limit 1
+            ) dp on true
+            where nullif(dep->>'name', '') is not null;
+        when 3 then -- OLM
+            insert into snapshot_dependency (
+                package_id,
+                version,
+                kind,
+                name,
+                required_version,
+                dependency_package_id,
+                dependency_version
+            )
+            select
+                p_package_id,
+                p_version,
+                'api',
+                api->>'name',
+                nullif(api->>'version', ''),
+                op.package_id,
+                op.latest_version
+            from jsonb_array_elements(
+                case when jsonb_typeof(v_data->'requiredAPIs') = 'array' then v_data->'requiredAPIs' end
+            ) api
+            left join lateral (
+                select p.package_id, p.latest_version
+                from package p
+                join repository r using (repository_id)
+                join snapshot s on s.package_id = p.package_id and s.version = p.latest_version
+                where r.repository_kind_id = 3
+                and p.package_id <> p_package_id
+                and jsonb_typeof(s.crds) = 'array'
+                and exists (
+                    select 1 from jsonb_array_elements(s.crds) crd
+                    where crd->>'name' = api->>'name'
+                )
+                order by p.name asc
+                limit 1
+            ) op on true
+            where nullif(api->>'name', '') is not null;
+        else
+    end case;
+
+    -- Resolve again the dependencies of other packages on this package (or
+    -- satisfied by it)
+    case v_repository_kind_id
+        when 0 then -- Helm
+            update snapshot_dependency d set
+                dependency_package_id = p_package_id,
+                dependency_version = (
+                    select s.version
+                    from snapshot s
+                    where s.package_id = p_package_id
+                    and semver_satisfies(s.version, d.required_version)
+                    order by (regexp_match(s.version, '^v?(\d+)\.(\d+)\.(\d+)'))::int[] desc nulls last, s.ts desc
+                    limit 1
+                )
+            where d.kind = 'chart'
+            and d.package_id <> p_package_id
+            and (
+                d.dependency_package_id = p_package_id
+                or (
+                    d.dependency_package_id is null
+                    and d.name = v_name
+                    and
+                        case when d.repository_url is null or starts_with(d.repository_url, 'file://') then
+                            exists (
+                                select 1 from package p
+                                where p.package_id = d.package_id
+                                and p.repository_id = v_repository_id
+                            )
+                        else
+                            trim(trailing from v_repository_url, '/') in (
+                                trim(trailing from d.repository_url, '/'),
+                                trim(trailing from d.repository_url, '/') || '/' || d.name
+                            )
+                        end
+                )
+            );
+        when 3 then -- OLM
+            update snapshot_dependency d set
+                dependency_package_id = p_package_id,
+                dependency_version = (
+                    select latest_version from package where package_id = p_package_id
+                )
+            where d.kind = 'api'
+            and d.package_id <> p_package_id
+            and (
+                d.dependency_package_id = p_package_id
+                or (
+                    d.dependency_package_id is null
+                    and jsonb_typeof(v_crds) = 'array'
+                    and exists (
+                        select 1 from jsonb_array_elements(v_crds) crd
+                        where crd->>'name' = d.name
+                    )
+                )
+            );
+        else
+    end case;
+end
+$$ language plpgsql;
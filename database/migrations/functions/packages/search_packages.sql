@@ -1,7 +1,10 @@
 -- search_packages searches packages in the database that match the criteria in
--- the query provided.
+-- the query provided. Results can be paginated using a limit and an offset or a
+-- cursor, which is returned along with the results when there are more
+-- packages available. The cursor includes the sort used, as it can only be
+-- used with the same sort.
 create or replace function search_packages(p_input jsonb)
-returns table(data json, total_count bigint) as $$
+returns table(data json, total_count bigint, next_cursor jsonb) as $$
 declare
     v_repository_kinds int[];
     v_users text[];
@@ -9,11 +12,14 @@ declare
     v_repositories text[];
     v_licenses text[];
     v_capabilities text[];
+    v_helm_types text[];
     v_facets boolean := (p_input->>'facets')::boolean;
     v_tsquery_web tsquery := websearch_to_tsquery(p_input->>'ts_query_web');
     v_tsquery_web_with_prefix_matching tsquery;
     v_tsquery tsquery := to_tsquery(p_input->>'ts_query');
     v_sort text := coalesce(p_input->>'sort', 'relevance');
+    v_cursor jsonb := p_input->'cursor';
+    v_limit int := (p_input->>'limit')::int;
 begin
     -- Prepare filters for later use
     select array_agg(e::int) into v_repository_kinds
@@ -28,6 +34,8 @@ begin
     from jsonb_array_elements_text(p_input->'licenses') e;
     select array_agg(e::text) into v_capabilities
     from jsonb_array_elements_text(p_input->'capabilities') e;
+    select array_agg(e::text) into v_helm_types
+    from jsonb_array_elements_text(p_input->'helm_types') e;
 
     -- Prepare v_tsquery_web_with_prefix_matching
     if v_tsquery_web is not null then
@@ -62,9 +70,14 @@ begin
             s.deprecated,
             s.signed,
             s.signatures,
+            s.signature_verification,
+            s.provenance,
             s.security_report_summary,
             s.containers_images,
             s.ts,
+            case when r.repository_kind_id = 0 then
+                coalesce(nullif(s.data->>'type', ''), 'application')
+            end as helm_type,
             r.repository_id,
             r.repository_kind_id,
             rk.name as repository_kind_name,
@@ -116,6 +129,62 @@ begin
             else
                 (s.deprecated is null or s.deprecated = false)
             end
+        and
+            case when p_input ? 'signed' and (p_input->>'signed')::boolean = true then
+                s.signed = true
+            else
+                true
+            end
+        and
+            case when p_input ? 'has_security_report' and (p_input->>'has_security_report')::boolean = true then
+                s.security_report_summary is not null
+            else
+                true
+            end
+        and
+            case when p_input ? 'max_severity' then
+                s.security_report_summary is not null
+                and (
+                    select coalesce(sum((s.security_report_summary->>severity)::int), 0) = 0
+                    from unnest(
+                        case p_input->>'max_severity'
+                            when 'none' then array['critical', 'high', 'medium', 'low']
+                            when 'low' then array['critical', 'high', 'medium']
+                            when 'medium' then array['critical', 'high']
+                            when 'high' then array['critical']
+                            else array[]::text[]
+                        end
+                    ) as severity
+                )
+            else
+                true
+            end
+        and
+            case when p_input ? 'kubernetes_version' then
+                semver_satisfies(p_input->>'kubernetes_version', s.data->>'kubeVersion')
+            else
+                true
+            end
+        and
+            case when p_input ? 'updated_within' then
+                s.ts >= current_timestamp - make_interval(days => (p_input->>'updated_within')::int)
+            else
+                true
+            end
+        and
+            case when p_input ? 'image' then
+                exists (
+                    select 1
+                    from parse_image_ref(p_input->>'image') q
+                    join snapshot_image si using (repository)
+                    where si.package_id = p.package_id
+                    and si.version = s.version
+                    and (q.tag is null or si.tag = q.tag)
+                    and (q.digest is null or si.digest = q.digest)
+                )
+            else
+                true
+            end
     ), filtered_packages as (
         select * from filtered_packages_excluding_facets_filters
         where
@@ -140,6 +209,67 @@ begin
         and
             case when cardinality(v_capabilities) > 0
             then capabilities = any(v_capabilities) else true end
+        and
+            case when cardinality(v_helm_types) > 0
+            then helm_type = any(v_helm_types) else true end
+        and
+            case when p_input ? 'has_provenance' and (p_input->>'has_provenance')::boolean = true then
+                provenance is not null
+            else
+                true
+            end
+    ), sorted_packages as (
+        select
+            fp.*,
+            (case when v_tsquery_web is not null then
+                trunc(ts_rank(ts_filter(tsdoc, '{a}'), v_tsquery_web, 1)::numeric, 2) +
+                trunc(ts_rank('{0.1, 0.2, 0.2, 1.0}', ts_filter(tsdoc, '{b,c}'), v_tsquery_web)::numeric, 2)
+            else 1 end) as relevance,
+            (case
+                when repository_official = true or package_official = true
+                then true else false
+            end) as official
+        from filtered_packages fp
+    ), sorted_packages_keys as (
+        select
+            sp.*,
+            (case when v_sort = 'stars' then stars else relevance end)::numeric as sort_key_1,
+            (case when v_sort = 'stars' then relevance else stars end)::numeric as sort_key_2
+        from sorted_packages sp
+    ), packages_page as (
+        select *, row_number() over (
+            order by
+                sort_key_1 desc,
+                sort_key_2 desc,
+                official desc,
+                verified_publisher desc,
+                name asc,
+                package_id asc
+        ) as n
+        from (
+            select *
+            from sorted_packages_keys
+            where
+                case when v_cursor is not null then
+                    (-sort_key_1, -sort_key_2, not official, not verified_publisher, name, package_id) > (
+                        -(v_cursor->>'sort_key_1')::numeric,
+                        -(v_cursor->>'sort_key_2')::numeric,
+                        not (v_cursor->>'official')::boolean,
+                        not (v_cursor->>'verified_publisher')::boolean,
+                        v_cursor->>'name',
+                        (v_cursor->>'package_id')::uuid
+                    )
+                else true end
+            order by
+                sort_key_1 desc,
+                sort_key_2 desc,
+                official desc,
+                verified_publisher desc,
+                name asc,
+                package_id asc
+            limit v_limit + 1
+            offset (p_input->>'offset')::int
+        ) spk
     )
     select
         json_strip_nulls(json_build_object(
@@ -159,6 +289,8 @@ begin
                     'deprecated', deprecated,
                     'signed', signed,
                     'signatures', signatures,
+                    'signature_verification', signature_verification,
+                    'provenance', provenance,
                     'security_report_summary', security_report_summary,
                     'all_containers_images_whitelisted', are_all_containers_images_whitelisted(containers_images),
                     'production_organizations_count', (
@@ -179,30 +311,9 @@ begin
                         'organization_name', organization_name,
                         'organization_display_name', organization_display_name
                     )
-                )), '[]')
-                from (
-                    select * from (
-                        select
-                            fp.*,
-                            (case when v_tsquery_web is not null then
-                                trunc(ts_rank(ts_filter(tsdoc, '{a}'), v_tsquery_web, 1)::numeric, 2) +
-                                trunc(ts_rank('{0.1, 0.2, 0.2, 1.0}', ts_filter(tsdoc, '{b,c}'), v_tsquery_web)::numeric, 2)
-                            else 1 end) as relevance,
-                            (case
-                                when repository_official = true or package_official = true
-                                then true else false
-                            end) as official
-                        from filtered_packages fp
-                    ) as fpe
-                    order by
-                        case when v_sort = 'relevance' then (relevance, stars) end desc,
-                        case when v_sort = 'stars' then (stars, relevance) end desc,
-                        official desc,
-                        verified_publisher desc,
-                        name asc
-                    limit (p_input->>'limit')::int
-                    offset (p_input->>'offset')::int
-                ) filtered_packages_paginated
+                ) order by filtered_packages_paginated.n), '[]')
+                from packages_page filtered_packages_paginated
+                where v_limit is null or n <= v_limit
             ),
             'facets', case when v_facets then (
                 select json_build_array(
@@ -379,10 +490,63 @@ begin
                                 ) as capabilities_breakdown
                             )
                         )
+                    ),
+                    (
+                        select json_build_object(
+                            'title', 'Chart type',
+                            'filter_key', 'helm_type',
+                            'options', (
+                                select coalesce(json_agg(json_build_object(
+                                    'id', helm_type,
+                                    'name', initcap(helm_type),
+                                    'total', total
+                                )), '[]')
+                                from (
+                                    select helm_type, count(*) as total
+                                    from filtered_packages_excluding_facets_filters
+                                    where helm_type is not null
+                                    group by helm_type
+                                    order by total desc, helm_type asc
+                                ) as helm_types_breakdown
+                            )
+                        )
+                    ),
+                    (
+                        select json_build_object(
+                            'title', 'Provenance',
+                            'filter_key', 'has_provenance',
+                            'options', (
+                                select coalesce(json_agg(json_build_object(
+                                    'id', 'true',
+                                    'name', 'With provenance',
+                                    'total', total
+                                )), '[]')
+                                from (
+                                    select count(*) as total
+                                    from filtered_packages_excluding_facets_filters
+                                    where provenance is not null
+                                    having count(*) > 0
+                                ) as provenance_breakdown
+                            )
+                        )
                     )
                 )
             ) else null end
         )),
-        (select count(*) from filtered_packages);
+        (select count(*) from filtered_packages),
+        (
+            select jsonb_build_object(
+                'sort', v_sort,
+                'sort_key_1', sort_key_1,
+                'sort_key_2', sort_key_2,
+                'official', official,
+                'verified_publisher', verified_publisher,
+                'name', name,
+                'package_id', package_id
+            )
+            from packages_page
+            where n = v_limit
+            and exists (select 1 from packages_page where n = v_limit + 1)
+        );
 end
 $$ language plpgsql;
@@ -0,0 +1,123 @@
+-- semver_satisfies checks if the semver provided satisfies the constraint
+-- provided (i.e. ">=1.19.0-0 <1.26.0", "~1.20" or "^1.19 || ^2.0"). Partial
+-- versions and x-ranges are supported. When no constraint is provided, the
+-- version is considered to satisfy it. Otherwise, versions or constraints with
+-- numeric identifiers out of range are considered not to satisfy it.
+create or replace function semver_satisfies(p_version text, p_constraint text)
+returns boolean as $$
+declare
+    v_group text;
+    v_token text;
+    v_parts text[];
+    v_op text;
+    v_nums int[];
+    v_specified int;
+    v_base text;
+    v_next text;
+    v_upper text;
+    v_ok boolean;
+    v_group_ok boolean;
+begin
+    if nullif(trim(p_constraint), '') is null then
+        return true;
+    end if;
+    if exists (
+        select 1 from unnest(regexp_match(p_version, '(\d+)\.(\d+)\.(\d+)')) as n
+        where length(n) > 9
+    ) then
+        return false;
+    end if;
+
+    -- The constraint is satisfied if any of the groups (separated by ||) is
+    -- satisfied. All comparisons in a group must be satisfied.
+    foreach v_group in array string_to_array(p_constraint, '||') loop
+        v_group_ok := true;
+        foreach v_token in array regexp_split_to_array(
+            trim(regexp_replace(v_group, '(>=|<=|!=|>|<|=|~|\^)\s+', '\1', 'g')),
+            '[\s,]+'
+        ) loop
+            continue when v_token = '';
+
+            -- Parse comparison
+            v_parts := regexp_match(
+                v_token,
+                '^(>=|<=|!=|>|<|=|~|\^)?v?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?$'
+            );
+            if v_parts is null or exists (
+                select 1 from unnest(v_parts[2:4]) as n
+                where length(n) > 9
+            ) then
+                v_group_ok := false;
+                exit;
+            end if;
+            v_op := coalesce(v_parts[1], '=');
+            v_nums := array[0, 0, 0];
+            v_specified := 0;
+            for i in 1..3 loop
+                exit when v_parts[i+1] is null or v_parts[i+1] ~ '^[xX*]$';
+                v_nums[i] := v_parts[i+1]::int;
+                v_specified := i;
+            end loop;
+            v_base := array_to_string(v_nums, '.') || coalesce(v_parts[5], '');
+            v_next := case v_specified
+                when 1 then (v_nums[1] + 1) || '.0.0-0'
+                when 2 then v_nums[1] || '.' || (v_nums[2] + 1) || '.0-0'
+                else null
+            end;
+
+            -- Evaluate comparison
+            case v_op
+                when '=', '!=' then
+                    if v_specified = 3 then
+                        v_ok := semver_gte(p_version, v_base) and semver_gte(v_base, p_version);
+                    else
+                        v_ok := semver_gte(p_version, v_base) and (v_next is null or not semver_gte(p_version, v_next));
+                    end if;
+                    if v_op = '!=' then
+                        v_ok := not v_ok;
+                    end if;
+                when '>=' then
+                    v_ok := semver_gte(p_version, v_base);
+                when '>' then
+                    if v_specified = 3 then
+                        v_ok := semver_gt(p_version, v_base);
+                    else
+                        v_ok := v_next is not null and semver_gte(p_version, v_next);
+                    end if;
+                when '<' then
+                    v_ok := not semver_gte(p_version, v_base);
+                when '<=' then
+                    if v_specified = 3 then
+                        v_ok := not semver_gt(p_version, v_base);
+                    else
+                        v_ok := v_next is null or not semver_gte(p_version, v_next);
+                    end if;
+                when '~' then
+                    v_upper := case
+                        when v_specified = 0 then null
+                        when v_specified = 1 then (v_nums[1] + 1) || '.0.0-0'
+                        else v_nums[1] || '.' || (v_nums[2] + 1) || '.0-0'
+                    end;
+                    v_ok := semver_gte(p_version, v_base) and (v_upper is null or not semver_gte(p_version, v_upper));
+                when '^' then
+                    v_upper := case
+                        when v_specified = 0 then null
+                        when v_nums[1] > 0 or v_specified = 1 then (v_nums[1] + 1) || '.0.0-0'
+                        when v_nums[2] > 0 or v_specified = 2 then '0.' || (v_nums[2] + 1) || '.0-0'
+                        else '0.0.' || (v_nums[3] + 1) || '-0'
+                    end;
+                    v_ok := semver_gte(p_version, v_base) and (v_upper is null or not semver_gte(p_version, v_upper));
+            end case;
+            if v_ok is not true then
+                v_group_ok := false;
+                exit;
+            end if;
+        end loop;
+        if v_group_ok then
+            return true;
+        end if;
+    end loop;
+
+    return false;
+end
+$$ language plpgsql immutable;
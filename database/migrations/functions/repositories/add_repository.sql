@@ -24,6 +24,7 @@ begin
         branch,
         auth_user,
         auth_pass,
+        encrypted_git_auth,
         disabled,
         scanner_disabled,
         data,
@@ -37,6 +38,7 @@ begin
         nullif(p_repository->>'branch', ''),
         nullif(p_repository->>'auth_user', ''),
         nullif(p_repository->>'auth_pass', ''),
+        nullif(nullif(p_repository->>'encrypted_git_auth', '='), ''),
         (p_repository->>'disabled')::boolean,
         (p_repository->>'scanner_disabled')::boolean,
         nullif(p_repository->'data', 'null'),
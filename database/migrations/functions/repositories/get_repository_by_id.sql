@@ -8,9 +8,10 @@ returns setof json as $$
         'display_name', r.display_name,
         'url', r.url,
         'branch', r.branch,
-        'private', (case when r.auth_user is not null or r.auth_pass is not null then true else null end),
+        'private', (case when r.auth_user is not null or r.auth_pass is not null or r.encrypted_git_auth is not null then true else null end),
         'auth_user', (case when p_include_credentials then r.auth_user else null end),
         'auth_pass', (case when p_include_credentials then r.auth_pass else null end),
+        'encrypted_git_auth', (case when p_include_credentials then r.encrypted_git_auth else null end),
         'kind', r.repository_kind_id,
         'verified_publisher', r.verified_publisher,
         'official', r.official,
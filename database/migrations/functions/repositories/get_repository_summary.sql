@@ -8,7 +8,7 @@ returns setof json as $$
         'display_name', r.display_name,
         'url', r.url,
         'private', (
-            case when r.auth_user is not null or r.auth_pass is not null then true
+            case when r.auth_user is not null or r.auth_pass is not null or r.encrypted_git_auth is not null then true
             else false end
         ),
         'kind', r.repository_kind_id,
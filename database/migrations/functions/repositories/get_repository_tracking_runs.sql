@@ -0,0 +1,23 @@
+-- get_repository_tracking_runs returns the most recent tracking runs of the
+-- repository identified by the name provided as a json array.
+create or replace function get_repository_tracking_runs(p_repository_name text)
+returns setof json as $$
+    select coalesce(
+        json_agg(json_build_object(
+            'tracking_run_id', rtr.repository_tracking_run_id,
+            'started_at', floor(extract(epoch from rtr.started_at)),
+            'finished_at', floor(extract(epoch from rtr.finished_at)),
+            'packages_registered', rtr.packages_registered,
+            'packages_unregistered', rtr.packages_unregistered,
+            'packages_skipped', rtr.packages_skipped,
+            'errors_count', rtr.errors_count,
+            'errors_truncated', rtr.errors_truncated,
+            'errors', rtr.errors
+        ) order by rtr.finished_at desc, rtr.started_at desc) filter (where rtr.repository_tracking_run_id is not null),
+        '[]'
+    )
+    from repository r
+    left join repository_tracking_run rtr using (repository_id)
+    where r.name = p_repository_name
+    group by r.repository_id;
+$$ language sql;
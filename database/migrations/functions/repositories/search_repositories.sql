@@ -27,6 +27,7 @@ begin
             r.branch,
             r.auth_user,
             r.auth_pass,
+            r.encrypted_git_auth,
             r.repository_kind_id,
             r.verified_publisher,
             r.official,
@@ -67,9 +68,10 @@ begin
             'display_name', display_name,
             'url', url,
             'branch', branch,
-            'private', (case when auth_user is not null or auth_pass is not null then true else null end),
+            'private', (case when auth_user is not null or auth_pass is not null or encrypted_git_auth is not null then true else null end),
             'auth_user', (case when v_include_credentials then auth_user else null end),
             'auth_pass', (case when v_include_credentials then auth_pass else null end),
+            'encrypted_git_auth', (case when v_include_credentials then encrypted_git_auth else null end),
             'kind', repository_kind_id,
             'verified_publisher', verified_publisher,
             'official', official,
@@ -1,9 +1,12 @@
 -- set_last_tracking_results updates the timestamp and errors of the last
--- tracking.
+-- tracking, registering the tracking run provided (when available) and
+-- keeping only the most recent ones.
 create or replace function set_last_tracking_results(
     p_repository_id uuid,
     p_last_tracking_errors text,
-    p_tracking_errors_event_enabled boolean
+    p_tracking_errors_event_enabled boolean,
+    p_tracking_run jsonb,
+    p_tracking_runs_to_keep int
 )
 returns void as $$
 declare
@@ -26,5 +29,40 @@ begin
 		last_tracking_ts = current_timestamp,
 		last_tracking_errors = v_last_tracking_errors
 	where repository_id = p_repository_id;
+
+    -- Register tracking run and remove the oldest ones
+    if p_tracking_run is not null then
+        insert into repository_tracking_run (
+            repository_id,
+            started_at,
+            finished_at,
+            packages_registered,
+            packages_unregistered,
+            packages_skipped,
+            errors,
+            errors_count,
+            errors_truncated
+        ) values (
+            p_repository_id,
+            coalesce(to_timestamp((p_tracking_run->>'started_at')::bigint), current_timestamp),
+            coalesce(to_timestamp((p_tracking_run->>'finished_at')::bigint), current_timestamp),
+            coalesce((p_tracking_run->>'packages_registered')::int, 0),
+            coalesce((p_tracking_run->>'packages_unregistered')::int, 0),
+            coalesce((p_tracking_run->>'packages_skipped')::int, 0),
+            nullif(p_tracking_run->'errors', 'null'),
+            coalesce((p_tracking_run->>'errors_count')::int, 0),
+            coalesce((p_tracking_run->>'errors_truncated')::boolean, false)
+        );
+
+        delete from repository_tracking_run
+        where repository_id = p_repository_id
+        and repository_tracking_run_id not in (
+            select repository_tracking_run_id
+            from repository_tracking_run
+            where repository_id = p_repository_id
+            order by finished_at desc, started_at desc
+            limit p_tracking_runs_to_keep
+        );
+    end if;
 end
 $$ language plpgsql;
@@ -9,6 +9,7 @@ declare
     v_scanner_disabled boolean;
     v_auth_user text;
     v_auth_pass text;
+    v_encrypted_git_auth text;
 begin
     -- Get some information about the repository
     select
@@ -16,13 +17,15 @@ begin
         disabled,
         scanner_disabled,
         auth_user,
-        auth_pass
+        auth_pass,
+        encrypted_git_auth
     into
         v_repository_id,
         v_disabled,
         v_scanner_disabled,
         v_auth_user,
-        v_auth_pass
+        v_auth_pass,
+        v_encrypted_git_auth
     from repository r
     where r.name = p_repository->>'name'
     for update;
@@ -60,6 +63,12 @@ begin
                 else nullif(p_repository->>'auth_pass', '')
             end
         ),
+        encrypted_git_auth = (
+            case
+                when (p_repository->>'encrypted_git_auth' = '=') then v_encrypted_git_auth
+                else nullif(p_repository->>'encrypted_git_auth', '')
+            end
+        ),
         disabled = (p_repository->>'disabled')::boolean,
         scanner_disabled = (p_repository->>'scanner_disabled')::boolean,
         data = nullif(p_repository->'data', 'null')
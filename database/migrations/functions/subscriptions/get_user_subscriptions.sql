@@ -1,7 +1,9 @@
 -- get_user_subscriptions returns all the subscriptions for the provided user
--- as a json array.
-create or replace function get_user_subscriptions(p_user_id uuid, p_limit int, p_offset int)
-returns table(data json, total_count bigint) as $$
+-- as a json array. Results can be paginated using a limit and an offset or a
+-- cursor, which is returned along with the results when there are more
+-- subscriptions available.
+create or replace function get_user_subscriptions(p_user_id uuid, p_limit int, p_offset int, p_cursor jsonb)
+returns table(data json, total_count bigint, next_cursor jsonb) as $$
     with user_subscriptions as (
         select
             p.package_id,
@@ -15,27 +17,44 @@ returns table(data json, total_count bigint) as $$
         where p.package_id in (
             select distinct(package_id) from subscription where user_id = p_user_id
         )
+    ), user_subscriptions_page as (
+        select *, row_number() over (order by normalized_name asc, package_id asc) as n
+        from (
+            select *
+            from user_subscriptions
+            where
+                case when p_cursor is not null then
+                    (normalized_name, package_id) >
+                    (p_cursor->>'normalized_name', (p_cursor->>'package_id')::uuid)
+                else true end
+            order by normalized_name asc, package_id asc
+            limit (case when p_limit = 0 then null else p_limit + 1 end)
+            offset p_offset
+        ) us
     )
     select
-        coalesce(json_agg(json_strip_nulls(json_build_object(
-            'package_id', package_id,
-            'name', name,
-            'normalized_name', normalized_name,
-            'logo_image_id', logo_image_id,
-            'repository', (select get_repository_summary(repository_id)),
-            'event_kinds', (
-                select json_agg(distinct(event_kind_id))
-                from subscription
-                where package_id = sp.package_id
-                and user_id = p_user_id
-            )
-        ))), '[]'),
-        (select count(*) from user_subscriptions)
-    from (
-        select *
-        from user_subscriptions
-        order by normalized_name asc
-        limit (case when p_limit = 0 then null else p_limit end)
-        offset p_offset
-    ) sp;
+        (
+            select coalesce(json_agg(json_strip_nulls(json_build_object(
+                'package_id', package_id,
+                'name', name,
+                'normalized_name', normalized_name,
+                'logo_image_id', logo_image_id,
+                'repository', (select get_repository_summary(repository_id)),
+                'event_kinds', (
+                    select json_agg(distinct(event_kind_id))
+                    from subscription
+                    where package_id = sp.package_id
+                    and user_id = p_user_id
+                )
+            )) order by sp.n), '[]')
+            from user_subscriptions_page sp
+            where p_limit = 0 or sp.n <= p_limit
+        ),
+        (select count(*) from user_subscriptions),
+        (
+            select jsonb_build_object('normalized_name', normalized_name, 'package_id', package_id)
+            from user_subscriptions_page
+            where p_limit > 0 and n = p_limit
+            and exists (select 1 from user_subscriptions_page where n = p_limit + 1)
+        );
 $$ language sql;
@@ -1,7 +1,14 @@
 -- get_org_webhooks returns the webhooks that belong to the organization
--- provided if the requesting user belongs to it.
-create or replace function get_org_webhooks(p_user_id uuid, p_org_name text, p_limit int, p_offset int)
-returns table(data json, total_count bigint) as $$
+-- provided if the requesting user belongs to it. Results can be paginated
+-- using a limit and an offset or a cursor, which is returned along with the
+-- results when there are more webhooks available.
+create or replace function get_org_webhooks(
+    p_user_id uuid,
+    p_org_name text,
+    p_limit int,
+    p_offset int,
+    p_cursor jsonb
+) returns table(data json, total_count bigint, next_cursor jsonb) as $$
     with org_webhooks as (
         select wh.webhook_id, wh.name
         from webhook wh
@@ -10,16 +17,32 @@ returns table(data json, total_count bigint) as $$
         where o.name = p_org_name
         and uo.user_id = p_user_id
         and uo.confirmed = true
+    ), org_webhooks_page as (
+        select *, row_number() over (order by name asc, webhook_id asc) as n
+        from (
+            select *
+            from org_webhooks
+            where
+                case when p_cursor is not null then
+                    (name, webhook_id) > (p_cursor->>'name', (p_cursor->>'webhook_id')::uuid)
+                else true end
+            order by name asc, webhook_id asc
+            limit (case when p_limit = 0 then null else p_limit + 1 end)
+            offset p_offset
+        ) ow
     )
     select
-        coalesce(json_agg(whJSON), '[]'),
-        (select count(*) from org_webhooks)
-    from (
-        select whJSON
-        from org_webhooks ow
-        cross join get_webhook(null::uuid, ow.webhook_id) as whJSON
-        order by ow.name asc
-        limit (case when p_limit = 0 then null else p_limit end)
-        offset p_offset
-    ) whs;
+        (
+            select coalesce(json_agg(whJSON order by owp.n), '[]')
+            from org_webhooks_page owp
+            cross join get_webhook(null::uuid, owp.webhook_id) as whJSON
+            where p_limit = 0 or owp.n <= p_limit
+        ),
+        (select count(*) from org_webhooks),
+        (
+            select jsonb_build_object('name', name, 'webhook_id', webhook_id)
+            from org_webhooks_page
+            where p_limit > 0 and n = p_limit
+            and exists (select 1 from org_webhooks_page where n = p_limit + 1)
+        );
 $$ language sql;
@@ -1,20 +1,38 @@
 -- get_user_webhooks returns the webhooks that belong to the requesting user.
-create or replace function get_user_webhooks(p_user_id uuid, p_limit int, p_offset int)
-returns table(data json, total_count bigint) as $$
+-- Results can be paginated using a limit and an offset or a cursor, which is
+-- returned along with the results when there are more webhooks available.
+create or replace function get_user_webhooks(p_user_id uuid, p_limit int, p_offset int, p_cursor jsonb)
+returns table(data json, total_count bigint, next_cursor jsonb) as $$
     with user_webhooks as (
         select webhook_id, name
         from webhook
         where user_id = p_user_id
+    ), user_webhooks_page as (
+        select *, row_number() over (order by name asc, webhook_id asc) as n
+        from (
+            select *
+            from user_webhooks
+            where
+                case when p_cursor is not null then
+                    (name, webhook_id) > (p_cursor->>'name', (p_cursor->>'webhook_id')::uuid)
+                else true end
+            order by name asc, webhook_id asc
+            limit (case when p_limit = 0 then null else p_limit + 1 end)
+            offset p_offset
+        ) uw
     )
     select
-        coalesce(json_agg(whJSON), '[]'),
-        (select count(*) from user_webhooks)
-    from (
-        select whJSON
-        from user_webhooks uw
-        cross join get_webhook(null::uuid, uw.webhook_id) as whJSON
-        order by uw.name asc
-        limit (case when p_limit = 0 then null else p_limit end)
-        offset p_offset
-    ) whs;
+        (
+            select coalesce(json_agg(whJSON order by uwp.n), '[]')
+            from user_webhooks_page uwp
+            cross join get_webhook(null::uuid, uwp.webhook_id) as whJSON
+            where p_limit = 0 or uwp.n <= p_limit
+        ),
+        (select count(*) from user_webhooks),
+        (
+            select jsonb_build_object('name', name, 'webhook_id', webhook_id)
+            from user_webhooks_page
+            where p_limit > 0 and n = p_limit
+            and exists (select 1 from user_webhooks_page where n = p_limit + 1)
+        );
 $$ language sql;
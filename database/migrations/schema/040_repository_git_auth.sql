@@ -0,0 +1,5 @@
+alter table repository add column encrypted_git_auth text;
+
+---- create above / drop below ----
+
+alter table repository drop column encrypted_git_auth;
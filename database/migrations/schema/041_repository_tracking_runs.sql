@@ -0,0 +1,18 @@
+create table if not exists repository_tracking_run (
+    repository_tracking_run_id uuid primary key default gen_random_uuid(),
+    repository_id uuid not null references repository on delete cascade,
+    started_at timestamptz not null,
+    finished_at timestamptz default current_timestamp not null,
+    packages_registered integer default 0 not null,
+    packages_unregistered integer default 0 not null,
+    packages_skipped integer default 0 not null,
+    errors jsonb,
+    errors_count integer default 0 not null,
+    errors_truncated boolean default false not null
+);
+
+create index repository_tracking_run_repository_id_idx on repository_tracking_run (repository_id);
+
+---- create above / drop below ----
+
+drop table if exists repository_tracking_run;
@@ -0,0 +1,5 @@
+alter table snapshot add column signature_verification jsonb;
+
+---- create above / drop below ----
+
+alter table snapshot drop column if exists signature_verification;
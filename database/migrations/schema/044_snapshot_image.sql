@@ -0,0 +1,70 @@
+-- parse_image_ref parses the container image reference provided, returning its
+-- normalized repository (including the registry) as well as the tag and the
+-- digest when present.
+create or replace function parse_image_ref(p_ref text)
+returns table(repository text, tag text, digest text) as $$
+declare
+    v_name text := trim(p_ref);
+    v_tag text;
+    v_digest text;
+    v_domain text;
+begin
+    -- Digest
+    if position('@' in v_name) > 0 then
+        v_digest := nullif(split_part(v_name, '@', 2), '');
+        v_name := split_part(v_name, '@', 1);
+    end if;
+
+    -- Tag (colons before the last slash belong to the registry port)
+    v_tag := substring(v_name from ':([^/:]+)$');
+    if v_tag is not null then
+        v_name := left(v_name, length(v_name) - length(v_tag) - 1);
+    end if;
+
+    -- Repository (Docker Hub registry and library namespace are implicit)
+    v_name := lower(v_name);
+    v_domain := split_part(v_name, '/', 1);
+    if position('/' in v_name) = 0 or (v_domain !~ '[.:]' and v_domain <> 'localhost') then
+        v_name := 'docker.io/' || v_name;
+    end if;
+    v_name := regexp_replace(v_name, '^(index|registry-1)\.docker\.io/', 'docker.io/');
+    if v_name ~ '^docker\.io/[^/]+$' then
+        v_name := regexp_replace(v_name, '^docker\.io/', 'docker.io/library/');
+    end if;
+
+    return query select v_name, v_tag, v_digest;
+end
+$$ language plpgsql immutable;
+
+create table if not exists snapshot_image (
+    package_id uuid not null,
+    version text not null,
+    image text not null check (image <> ''),
+    repository text not null check (repository <> ''),
+    tag text check (tag <> ''),
+    digest text check (digest <> ''),
+    foreign key (package_id, version) references snapshot on delete cascade
+);
+
+create index snapshot_image_package_id_version_idx on snapshot_image (package_id, version);
+create index snapshot_image_repository_idx on snapshot_image (repository);
+
+insert into snapshot_image (package_id, version, image, repository, tag, digest)
+select
+    s.package_id,
+    s.version,
+    e->>'image',
+    r.repository,
+    case when r.tag is null and r.digest is null then 'latest' else r.tag end,
+    r.digest
+from snapshot s
+cross join jsonb_array_elements(
+    case when jsonb_typeof(s.containers_images) = 'array' then s.containers_images end
+) e
+cross join parse_image_ref(e->>'image') r
+where nullif(e->>'image', '') is not null;
+
+---- create above / drop below ----
+
+drop table if exists snapshot_image;
+drop function if exists parse_image_ref(text);
@@ -0,0 +1,56 @@
+create table if not exists snapshot_dependency (
+    package_id uuid not null,
+    version text not null,
+    kind text not null check (kind in ('chart', 'api')),
+    name text not null check (name <> ''),
+    required_version text check (required_version <> ''),
+    repository_url text check (repository_url <> ''),
+    dependency_package_id uuid references package on delete set null,
+    dependency_version text check (dependency_version <> ''),
+    foreign key (package_id, version) references snapshot on delete cascade
+);
+
+create index snapshot_dependency_package_id_version_idx on snapshot_dependency (package_id, version);
+create index snapshot_dependency_dependency_package_id_idx on snapshot_dependency (dependency_package_id);
+create index snapshot_dependency_name_idx on snapshot_dependency (name) where dependency_package_id is null;
+
+-- Dependencies of the existing Helm charts versions (the dependencies versions
+-- will be resolved when the packages or their dependencies are registered again)
+insert into snapshot_dependency (package_id, version, kind, name, required_version, repository_url, dependency_package_id)
+select
+    s.package_id,
+    s.version,
+    'chart',
+    dep->>'name',
+    nullif(dep->>'version', ''),
+    nullif(dep->>'repository', ''),
+    (
+        select dp.package_id
+        from package dp
+        join repository dr using (repository_id)
+        where dp.name = dep->>'name'
+        and dr.repository_kind_id = 0
+        and
+            case when nullif(dep->>'repository', '') is null or starts_with(dep->>'repository', 'file://') then
+                dr.repository_id = r.repository_id
+            else
+                trim(trailing from dr.url, '/') in (
+                    trim(trailing from dep->>'repository', '/'),
+                    trim(trailing from dep->>'repository', '/') || '/' || (dep->>'name')
+                )
+            end
+        order by trim(trailing from dr.url, '/') = trim(trailing from dep->>'repository', '/') desc, dp.package_id asc
+        limit 1
+    )
+from snapshot s
+join package p using (package_id)
+join repository r using (repository_id)
+cross join jsonb_array_elements(
+    case when jsonb_typeof(s.data->'dependencies') = 'array' then s.data->'dependencies' end
+) dep
+where r.repository_kind_id = 0
+and nullif(dep->>'name', '') is not null;
+
+---- create above / drop below ----
+
+drop table if exists snapshot_dependency;
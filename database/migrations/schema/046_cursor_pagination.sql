@@ -0,0 +1,8 @@
+drop function if exists get_packages_starred_by_user(uuid, int, int);
+drop function if exists get_organization_members(uuid, text, int, int);
+drop function if exists get_user_subscriptions(uuid, int, int);
+drop function if exists get_user_webhooks(uuid, int, int);
+drop function if exists get_org_webhooks(uuid, text, int, int);
+drop function if exists search_packages(jsonb);
+
+---- create above / drop below ----
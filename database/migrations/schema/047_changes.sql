@@ -0,0 +1,192 @@
+create table if not exists change (
+    change_id bigserial primary key,
+    tx_id bigint not null default txid_current(),
+    entity_kind text not null check (entity_kind in ('repository', 'package', 'package_version', 'security_report')),
+    operation text not null check (operation in ('create', 'update', 'delete')),
+    repository_id uuid,
+    package_id uuid,
+    version text,
+    created_at timestamptz default current_timestamp not null
+);
+
+create index change_tx_id_change_id_idx on change (tx_id, change_id);
+
+create or replace function register_repository_change()
+returns trigger as $$
+begin
+    if tg_op = 'INSERT' then
+        insert into change (entity_kind, operation, repository_id)
+        values ('repository', 'create', new.repository_id);
+    elsif tg_op = 'UPDATE' then
+        insert into change (entity_kind, operation, repository_id)
+        values ('repository', 'update', new.repository_id);
+    else
+        insert into change (entity_kind, operation, repository_id)
+        values ('repository', 'delete', old.repository_id);
+    end if;
+    return null;
+end
+$$ language plpgsql;
+
+create trigger trigger_repository_created_or_deleted
+after insert or delete on repository
+for each row
+execute function register_repository_change();
+
+create trigger trigger_repository_updated
+after update on repository
+for each row
+when (
+    (
+        old.name,
+        old.display_name,
+        old.url,
+        old.branch,
+        old.verified_publisher,
+        old.official,
+        old.disabled,
+        old.scanner_disabled,
+        old.repository_kind_id,
+        old.user_id,
+        old.organization_id,
+        old.data,
+        (old.auth_user is null and old.auth_pass is null and old.encrypted_git_auth is null)
+    ) is distinct from (
+        new.name,
+        new.display_name,
+        new.url,
+        new.branch,
+        new.verified_publisher,
+        new.official,
+        new.disabled,
+        new.scanner_disabled,
+        new.repository_kind_id,
+        new.user_id,
+        new.organization_id,
+        new.data,
+        (new.auth_user is null and new.auth_pass is null and new.encrypted_git_auth is null)
+    )
+)
+execute function register_repository_change();
+
+create or replace function register_package_change()
+returns trigger as $$
+begin
+    if tg_op = 'INSERT' then
+        insert into change (entity_kind, operation, repository_id, package_id)
+        values ('package', 'create', new.repository_id, new.package_id);
+    elsif tg_op = 'UPDATE' then
+        insert into change (entity_kind, operation, repository_id, package_id)
+        values ('package', 'update', new.repository_id, new.package_id);
+    else
+        insert into change (entity_kind, operation, repository_id, package_id)
+        values ('package', 'delete', old.repository_id, old.package_id);
+    end if;
+    return null;
+end
+$$ language plpgsql;
+
+create trigger trigger_package_created_or_deleted
+after insert or delete on package
+for each row
+execute function register_package_change();
+
+create trigger trigger_package_updated
+after update on package
+for each row
+when (
+    (
+        old.name,
+        old.latest_version,
+        old.logo_url,
+        old.logo_image_id,
+        old.is_operator,
+        old.channels,
+        old.default_channel,
+        old.official,
+        old.repository_id
+    ) is distinct from (
+        new.name,
+        new.latest_version,
+        new.logo_url,
+        new.logo_image_id,
+        new.is_operator,
+        new.channels,
+        new.default_channel,
+        new.official,
+        new.repository_id
+    )
+)
+execute function register_package_change();
+
+create or replace function register_snapshot_change()
+returns trigger as $$
+declare
+    v_security_report_keys text[] := array[
+        'security_report',
+        'security_report_alert_digest',
+        'security_report_created_at',
+        'security_report_summary'
+    ];
+    v_old_security_report_exists boolean;
+    v_new_security_report_exists boolean;
+begin
+    if tg_op in ('UPDATE', 'DELETE') then
+        v_old_security_report_exists := old.security_report_summary is not null;
+    end if;
+    if tg_op in ('INSERT', 'UPDATE') then
+        v_new_security_report_exists := new.security_report_summary is not null;
+    end if;
+
+    if tg_op = 'INSERT' then
+        insert into change (entity_kind, operation, package_id, version)
+        values ('package_version', 'create', new.package_id, new.version);
+        if v_new_security_report_exists then
+            insert into change (entity_kind, operation, package_id, version)
+            values ('security_report', 'create', new.package_id, new.version);
+        end if;
+    elsif tg_op = 'UPDATE' then
+        if (to_jsonb(old) - v_security_report_keys) is distinct from (to_jsonb(new) - v_security_report_keys) then
+            insert into change (entity_kind, operation, package_id, version)
+            values ('package_version', 'update', new.package_id, new.version);
+        end if;
+        if (v_old_security_report_exists or v_new_security_report_exists)
+        and (old.security_report, old.security_report_summary)
+        is distinct from (new.security_report, new.security_report_summary) then
+            insert into change (entity_kind, operation, package_id, version)
+            values ('security_report', (
+                case
+                    when not v_old_security_report_exists then 'create'
+                    when not v_new_security_report_exists then 'delete'
+                    else 'update'
+                end
+            ), new.package_id, new.version);
+        end if;
+    else
+        if v_old_security_report_exists then
+            insert into change (entity_kind, operation, package_id, version)
+            values ('security_report', 'delete', old.package_id, old.version);
+        end if;
+        insert into change (entity_kind, operation, package_id, version)
+        values ('package_version', 'delete', old.package_id, old.version);
+    end if;
+    return null;
+end
+$$ language plpgsql;
+
+create trigger trigger_snapshot_changed
+after insert or update or delete on snapshot
+for each row
+execute function register_snapshot_change();
+
+---- create above / drop below ----
+
+drop trigger if exists trigger_snapshot_changed on snapshot;
+drop trigger if exists trigger_package_updated on package;
+drop trigger if exists trigger_package_created_or_deleted on package;
+drop trigger if exists trigger_repository_updated on repository;
+drop trigger if exists trigger_repository_created_or_deleted on repository;
+drop function if exists register_snapshot_change;
+drop function if exists register_package_change;
+drop function if exists register_repository_change;
+drop table if exists change;
@@ -0,0 +1,11 @@
+create index change_created_at_idx on change (created_at);
+
+create table if not exists change_pruned (
+    tx_id bigint not null,
+    change_id bigint not null
+);
+
+---- create above / drop below ----
+
+drop table if exists change_pruned;
+drop index if exists change_created_at_idx;
@@ -0,0 +1,157 @@
+-- Start transaction and plan tests
+begin;
+select plan(7);
+
+-- Declare some variables
+\set org1ID '00000000-0000-0000-0000-000000000001'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+
+-- Seed some data
+insert into organization (organization_id, name, display_name, description, home_url)
+values (:'org1ID', 'org1', 'Organization 1', 'Description 1', 'https://org1.com');
+insert into repository (repository_id, name, display_name, url, repository_kind_id, organization_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', 0, :'org1ID');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package1ID', 'package1', '1.0.0', :'repo1ID');
+insert into snapshot (package_id, version, security_report_summary, ts)
+values (:'package1ID', '1.0.0', '{"high": 2}', '2020-06-16 11:20:34+02');
+insert into snapshot (package_id, version, ts)
+values (:'package1ID', '0.0.9', '2020-06-16 11:20:33+02');
+
+-- Changes are registered when entities are created, updated or deleted
+select results_eq(
+    $$
+        select entity_kind, operation, repository_id, package_id, version
+        from change
+        order by change_id asc
+    $$,
+    $$
+        values
+            ('repository', 'create', '00000000-0000-0000-0000-000000000001'::uuid, null::uuid, null),
+            ('package', 'create', '00000000-0000-0000-0000-000000000001'::uuid, '00000000-0000-0000-0000-000000000001'::uuid, null),
+            ('package_version', 'create', null::uuid, '00000000-0000-0000-0000-000000000001'::uuid, '1.0.0'),
+            ('security_report', 'create', null::uuid, '00000000-0000-0000-0000-000000000001'::uuid, '1.0.0'),
+            ('package_version', 'create', null::uuid, '00000000-0000-0000-0000-000000000001'::uuid, '0.0.9')
+    $$,
+    'Changes should be registered when entities are created'
+);
+delete from change;
+update repository set last_tracking_ts = current_timestamp where repository_id = :'repo1ID';
+update package set stars = 10 where package_id = :'package1ID';
+update snapshot set security_report_created_at = current_timestamp where package_id = :'package1ID';
+select is_empty(
+    $$ select * from change $$,
+    'No changes should be registered when fields not exposed are updated'
+);
+update repository set display_name = 'Repo 1 updated' where repository_id = :'repo1ID';
+update package set latest_version = '0.0.9' where package_id = :'package1ID';
+update snapshot set security_report_summary = '{"high": 3}' where package_id = :'package1ID' and version = '1.0.0';
+update snapshot set description = 'description' where package_id = :'package1ID' and version = '0.0.9';
+delete from snapshot where package_id = :'package1ID' and version = '1.0.0';
+select results_eq(
+    $$
+        select entity_kind, operation, version
+        from change
+        order by change_id asc
+    $$,
+    $$
+        values
+            ('repository', 'update', null),
+            ('package', 'update', null),
+            ('security_report', 'update', '1.0.0'),
+            ('package_version', 'update', '0.0.9'),
+            ('security_report', 'delete', '1.0.0'),
+            ('package_version', 'delete', '1.0.0')
+    $$,
+    'Changes should be registered when entities are updated or deleted'
+);
+
+-- Changes registered by the current transaction are not returned yet
+select results_eq(
+    $$ select data::jsonb, next_cursor from get_changes(null, 10) $$,
+    $$ values ('[]'::jsonb, null::jsonb) $$,
+    'No changes expected as the transaction that registered them is still in progress'
+);
+
+-- Changes registered by finished transactions are returned
+delete from change;
+insert into change (change_id, tx_id, entity_kind, operation, repository_id, package_id, version, created_at)
+values
+    (3, 1, 'package_version', 'update', null, :'package1ID', '0.0.9', '2020-06-16 11:20:35+02'),
+    (1, 2, 'package', 'delete', :'repo1ID', '00000000-0000-0000-0000-000000000002', null, '2020-06-16 11:20:36+02'),
+    (2, 2, 'repository', 'update', :'repo1ID', null, null, '2020-06-16 11:20:36+02');
+select results_eq(
+    $$
+        select
+            (
+                select jsonb_agg(c - 'data')
+                from jsonb_array_elements(data::jsonb) c
+            ),
+            (data::jsonb->0->'data'->>'version'),
+            next_cursor
+        from get_changes(null, 2)
+    $$,
+    $$
+        values (
+            '[
+                {
+                    "kind": "package_version",
+                    "operation": "update",
+                    "package_id": "00000000-0000-0000-0000-000000000001",
+                    "version": "0.0.9",
+                    "ts": 1592299235
+                },
+                {
+                    "kind": "package",
+                    "operation": "delete",
+                    "repository_id": "00000000-0000-0000-0000-000000000001",
+                    "package_id": "00000000-0000-0000-0000-000000000002",
+                    "ts": 1592299236
+                }
+            ]'::jsonb,
+            '0.0.9',
+            '{"tx_id": 2, "change_id": 1}'::jsonb
+        )
+    $$,
+    'First two changes should be returned sorted by transaction'
+);
+select results_eq(
+    $$
+        select
+            (
+                select jsonb_agg(c - 'data')
+                from jsonb_array_elements(data::jsonb) c
+            ),
+            (data::jsonb->0->'data'->>'name'),
+            next_cursor
+        from get_changes('{"tx_id": 2, "change_id": 1}', 2)
+    $$,
+    $$
+        values (
+            '[
+                {
+                    "kind": "repository",
+                    "operation": "update",
+                    "repository_id": "00000000-0000-0000-0000-000000000001",
+                    "ts": 1592299236
+                }
+            ]'::jsonb,
+            'repo1',
+            '{"tx_id": 2, "change_id": 2}'::jsonb
+        )
+    $$,
+    'Only the last change should be returned when using the cursor of the first page'
+);
+
+-- Cursors older than the most recent change pruned are rejected
+insert into change_pruned (tx_id, change_id) values (2, 1);
+select throws_ok(
+    $$ select * from get_changes('{"tx_id": 1, "change_id": 3}', 2) $$,
+    'cursor expired',
+    'Cursors older than the most recent change pruned should be rejected'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
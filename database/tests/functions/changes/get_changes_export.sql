@@ -0,0 +1,167 @@
+-- Start transaction and plan tests
+begin;
+select plan(5);
+
+-- Declare some variables
+\set org1ID '00000000-0000-0000-0000-000000000001'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+\set package2ID '00000000-0000-0000-0000-000000000002'
+
+-- Seed some data
+insert into organization (organization_id, name, display_name, description, home_url)
+values (:'org1ID', 'org1', 'Organization 1', 'Description 1', 'https://org1.com');
+insert into repository (repository_id, name, display_name, url, repository_kind_id, organization_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', 0, :'org1ID');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package1ID', 'package1', '1.0.0', :'repo1ID');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package2ID', 'package2', '1.0.0', :'repo1ID');
+insert into snapshot (package_id, version, security_report_summary, ts)
+values (:'package1ID', '1.0.0', '{"high": 2}', '2020-06-16 11:20:34+02');
+insert into snapshot (package_id, version, ts)
+values (:'package2ID', '1.0.0', '2020-06-16 11:20:34+02');
+
+-- Run some tests
+select results_eq(
+    $$
+        select
+            (
+                select jsonb_agg(r::jsonb - 'data')
+                from regexp_split_to_table(data, E'\n') r
+            ),
+            next_cursor - 'since',
+            next_cursor->'since' = since
+        from get_changes_export(null, 10)
+    $$,
+    $$
+        values (
+            '[
+                {
+                    "kind": "repository",
+                    "operation": "create",
+                    "repository_id": "00000000-0000-0000-0000-000000000001"
+                }
+            ]'::jsonb,
+            '{"kind": "package"}'::jsonb,
+            true
+        )
+    $$,
+    'Repositories should be exported first'
+);
+select results_eq(
+    $$
+        select
+            (
+                select jsonb_agg(r::jsonb - 'data')
+                from regexp_split_to_table(data, E'\n') r
+            ),
+            next_cursor - 'since'
+        from get_changes_export('{"kind": "package", "since": {"tx_id": 1, "change_id": 0}}', 1)
+    $$,
+    $$
+        values (
+            '[
+                {
+                    "kind": "package",
+                    "operation": "create",
+                    "repository_id": "00000000-0000-0000-0000-000000000001",
+                    "package_id": "00000000-0000-0000-0000-000000000001"
+                }
+            ]'::jsonb,
+            '{
+                "kind": "package",
+                "after": {
+                    "repository_id": "00000000-0000-0000-0000-000000000001",
+                    "package_id": "00000000-0000-0000-0000-000000000001"
+                }
+            }'::jsonb
+        )
+    $$,
+    'First package should be exported and the cursor should point to the next one'
+);
+select results_eq(
+    $$
+        select
+            (
+                select jsonb_agg(r::jsonb->>'package_id')
+                from regexp_split_to_table(data, E'\n') r
+            ),
+            next_cursor,
+            since
+        from get_changes_export('{
+            "kind": "package",
+            "after": {
+                "repository_id": "00000000-0000-0000-0000-000000000001",
+                "package_id": "00000000-0000-0000-0000-000000000001"
+            },
+            "since": {"tx_id": 1, "change_id": 0}
+        }', 1)
+    $$,
+    $$
+        values (
+            '["00000000-0000-0000-0000-000000000002"]'::jsonb,
+            '{"kind": "package_version", "since": {"tx_id": 1, "change_id": 0}}'::jsonb,
+            '{"tx_id": 1, "change_id": 0}'::jsonb
+        )
+    $$,
+    'Last package should be exported and the cursor should point to the packages versions'
+);
+select results_eq(
+    $$
+        select
+            (
+                select jsonb_agg(jsonb_build_object(
+                    'package_id', r::jsonb->>'package_id',
+                    'version', r::jsonb->>'version',
+                    'data_version', r::jsonb->'data'->>'version'
+                ))
+                from regexp_split_to_table(data, E'\n') r
+            ),
+            next_cursor - 'since'
+        from get_changes_export('{"kind": "package_version", "since": {"tx_id": 1, "change_id": 0}}', 10)
+    $$,
+    $$
+        values (
+            '[
+                {
+                    "package_id": "00000000-0000-0000-0000-000000000001",
+                    "version": "1.0.0",
+                    "data_version": "1.0.0"
+                },
+                {
+                    "package_id": "00000000-0000-0000-0000-000000000002",
+                    "version": "1.0.0",
+                    "data_version": "1.0.0"
+                }
+            ]'::jsonb,
+            '{"kind": "security_report"}'::jsonb
+        )
+    $$,
+    'Packages versions should be exported'
+);
+select results_eq(
+    $$
+        select data::jsonb, next_cursor
+        from get_changes_export('{"kind": "security_report", "since": {"tx_id": 1, "change_id": 0}}', 10)
+    $$,
+    $$
+        values (
+            '{
+                "kind": "security_report",
+                "operation": "create",
+                "package_id": "00000000-0000-0000-0000-000000000001",
+                "version": "1.0.0",
+                "data": {
+                    "summary": {"high": 2}
+                }
+            }'::jsonb,
+            null::jsonb
+        )
+    $$,
+    'Only packages versions with a security report should be exported, ending the export'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
@@ -0,0 +1,41 @@
+-- Start transaction and plan tests
+begin;
+select plan(4);
+
+-- Declare some variables
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+
+-- Seed some data
+insert into change (change_id, tx_id, entity_kind, operation, repository_id, created_at)
+values
+    (1, 1, 'repository', 'update', :'repo1ID', '2020-06-16 11:20:34+02'),
+    (2, 1, 'repository', 'update', :'repo1ID', '2020-06-16 11:20:34+02'),
+    (3, 2, 'repository', 'update', :'repo1ID', '2020-06-16 11:20:35+02'),
+    (4, 3, 'repository', 'update', :'repo1ID', '2020-06-16 11:20:36+02');
+
+-- Run some tests
+select prune_changes(2, '2020-06-16 11:20:35+02');
+select results_eq(
+    $$ select change_id from change order by change_id asc $$,
+    $$ values (3::bigint), (4::bigint) $$,
+    'Changes registered before the date provided should be deleted'
+);
+select results_eq(
+    $$ select tx_id, change_id from change_pruned $$,
+    $$ values (1::bigint, 2::bigint) $$,
+    'Most recent change deleted should be tracked'
+);
+select prune_changes(2, '2020-06-16 11:20:37+02');
+select is_empty(
+    $$ select * from change $$,
+    'All changes should be deleted'
+);
+select results_eq(
+    $$ select tx_id, change_id from change_pruned $$,
+    $$ values (3::bigint, 4::bigint) $$,
+    'Only the most recent change deleted should be tracked'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
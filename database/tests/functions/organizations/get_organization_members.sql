@@ -24,7 +24,7 @@ insert into user__organization (user_id, organization_id, confirmed) values(:'us
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_organization_members('00000000-0000-0000-0000-000000000001', 'org1', 0, 0)
+        from get_organization_members('00000000-0000-0000-0000-000000000001', 'org1', 0, 0, null)
     $$,
     $$
         values (
@@ -50,7 +50,7 @@ select results_eq(
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_organization_members('00000000-0000-0000-0000-000000000001', 'org1', 1, 1)
+        from get_organization_members('00000000-0000-0000-0000-000000000001', 'org1', 1, 1, null)
     $$,
     $$
         values (
@@ -70,7 +70,7 @@ select results_eq(
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_organization_members('00000000-0000-0000-0000-000000000001', 'org1', 0, 2)
+        from get_organization_members('00000000-0000-0000-0000-000000000001', 'org1', 0, 2, null)
     $$,
     $$
         values ('[]'::jsonb, 2)
@@ -78,7 +78,7 @@ select results_eq(
     'No members expected when using an offset of 2'
 );
 select throws_ok(
-    $$ select * from get_organization_members('00000000-0000-0000-0000-000000000001', 'org2', 0, 0) $$,
+    $$ select * from get_organization_members('00000000-0000-0000-0000-000000000001', 'org2', 0, 0, null) $$,
     42501,
     'insufficient_privilege',
     'User1 should not be able to get organization2 members'
@@ -107,6 +107,8 @@ insert into snapshot (
     recommendations,
     screenshots,
     sign_key,
+    signature_verification,
+    provenance,
     relative_path,
     ts
 ) values (
@@ -153,6 +155,8 @@ insert into snapshot (
         }
     ]'::jsonb,
     '{"fingerprint": "0011223344", "url": "https://key.url"}',
+    '{"status": "verified", "kind": "prov", "signer": "User 1 <user1@email.com>", "fingerprint": "0011223344"}',
+    '{"predicate_type": "https://slsa.dev/provenance/v1", "builder_id": "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v1.9.0", "source_repo": "https://github.com/org/repo", "source_commit": "abcdef", "build_level": 3}',
     'path1/path2',
     '2020-06-16 11:20:34+02'
 );
@@ -363,6 +367,19 @@ select is(
             "fingerprint": "0011223344",
             "url": "https://key.url"
         },
+        "signature_verification": {
+            "status": "verified",
+            "kind": "prov",
+            "signer": "User 1 <user1@email.com>",
+            "fingerprint": "0011223344"
+        },
+        "provenance": {
+            "predicate_type": "https://slsa.dev/provenance/v1",
+            "builder_id": "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v1.9.0",
+            "source_repo": "https://github.com/org/repo",
+            "source_commit": "abcdef",
+            "build_level": 3
+        },
         "repository": {
             "repository_id": "00000000-0000-0000-0000-000000000001",
             "kind": 0,
@@ -510,6 +527,19 @@ select is(
             "fingerprint": "0011223344",
             "url": "https://key.url"
         },
+        "signature_verification": {
+            "status": "verified",
+            "kind": "prov",
+            "signer": "User 1 <user1@email.com>",
+            "fingerprint": "0011223344"
+        },
+        "provenance": {
+            "predicate_type": "https://slsa.dev/provenance/v1",
+            "builder_id": "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v1.9.0",
+            "source_repo": "https://github.com/org/repo",
+            "source_commit": "abcdef",
+            "build_level": 3
+        },
         "repository": {
             "repository_id": "00000000-0000-0000-0000-000000000001",
             "kind": 0,
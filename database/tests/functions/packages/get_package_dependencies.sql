@@ -0,0 +1,82 @@
+-- Start transaction and plan tests
+begin;
+select plan(2);
+
+-- Declare some variables
+\set org1ID '00000000-0000-0000-0000-000000000001'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+\set package2ID '00000000-0000-0000-0000-000000000002'
+
+-- Seed some data
+insert into organization (organization_id, name, display_name)
+values (:'org1ID', 'org1', 'Organization 1');
+insert into repository (repository_id, name, display_name, url, repository_kind_id, organization_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', 0, :'org1ID');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package1ID', 'common', '1.2.0', :'repo1ID');
+insert into snapshot (package_id, version) values (:'package1ID', '1.2.0');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package2ID', 'app', '1.0.0', :'repo1ID');
+insert into snapshot (package_id, version) values (:'package2ID', '1.0.0');
+insert into snapshot (package_id, version) values (:'package2ID', '0.9.0');
+insert into snapshot_dependency (
+    package_id,
+    version,
+    kind,
+    name,
+    required_version,
+    repository_url,
+    dependency_package_id,
+    dependency_version
+) values
+    (:'package2ID', '1.0.0', 'chart', 'common', '^1.0.0', 'https://repo1.com', :'package1ID', '1.2.0'),
+    (:'package2ID', '1.0.0', 'chart', 'redis', '17.x', 'https://charts.bitnami.com/bitnami', null, null);
+
+-- Run some tests
+select is(
+    get_package_dependencies(:'package2ID', '1.0.0')::jsonb,
+    '[
+        {
+            "kind": "chart",
+            "name": "common",
+            "required_version": "^1.0.0",
+            "repository_url": "https://repo1.com",
+            "package": {
+                "package_id": "00000000-0000-0000-0000-000000000001",
+                "name": "common",
+                "normalized_name": "common",
+                "version": "1.2.0",
+                "repository": {
+                    "repository_id": "00000000-0000-0000-0000-000000000001",
+                    "name": "repo1",
+                    "display_name": "Repo 1",
+                    "url": "https://repo1.com",
+                    "private": false,
+                    "kind": 0,
+                    "verified_publisher": false,
+                    "official": false,
+                    "scanner_disabled": false,
+                    "organization_name": "org1",
+                    "organization_display_name": "Organization 1"
+                }
+            }
+        },
+        {
+            "kind": "chart",
+            "name": "redis",
+            "required_version": "17.x",
+            "repository_url": "https://charts.bitnami.com/bitnami"
+        }
+    ]'::jsonb,
+    'Dependencies of package2 version 1.0.0 expected'
+);
+select is(
+    get_package_dependencies(:'package2ID', '0.9.0')::jsonb,
+    '[]'::jsonb,
+    'No dependencies expected for package2 version 0.9.0'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
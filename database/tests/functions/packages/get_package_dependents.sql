@@ -0,0 +1,160 @@
+-- Start transaction and plan tests
+begin;
+select plan(3);
+
+-- Declare some variables
+\set org1ID '00000000-0000-0000-0000-000000000001'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+\set package2ID '00000000-0000-0000-0000-000000000002'
+\set package3ID '00000000-0000-0000-0000-000000000003'
+
+-- Seed some data
+insert into organization (organization_id, name, display_name)
+values (:'org1ID', 'org1', 'Organization 1');
+insert into repository (repository_id, name, display_name, url, repository_kind_id, organization_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', 0, :'org1ID');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package1ID', 'common', '2.0.0', :'repo1ID');
+insert into snapshot (package_id, version) values (:'package1ID', '1.2.0');
+insert into snapshot (package_id, version) values (:'package1ID', '2.0.0');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package2ID', 'app1', '1.0.0', :'repo1ID');
+insert into snapshot (package_id, version, app_version, ts)
+values (:'package2ID', '1.0.0', '3.0.0', '2020-06-16 11:20:34+02');
+insert into snapshot (package_id, version, ts)
+values (:'package2ID', '0.9.0', '2020-06-16 11:20:33+02');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package3ID', 'app2', '2.0.0', :'repo1ID');
+insert into snapshot (package_id, version, ts)
+values (:'package3ID', '2.0.0', '2020-06-16 11:20:35+02');
+insert into snapshot_dependency (
+    package_id,
+    version,
+    kind,
+    name,
+    required_version,
+    repository_url,
+    dependency_package_id,
+    dependency_version
+) values
+    (:'package2ID', '1.0.0', 'chart', 'common', '^1.0.0', 'https://repo1.com', :'package1ID', '1.2.0'),
+    (:'package2ID', '0.9.0', 'chart', 'common', '^1.0.0', 'https://repo1.com', :'package1ID', '1.2.0'),
+    (:'package3ID', '2.0.0', 'chart', 'common', '2.x', 'https://repo1.com', :'package1ID', '2.0.0');
+
+-- Run some tests
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from get_package_dependents('{
+            "package_id": "00000000-0000-0000-0000-000000000001",
+            "limit": 10
+        }')
+    $$,
+    $$
+        values (
+            '[{
+                "package_id": "00000000-0000-0000-0000-000000000002",
+                "name": "app1",
+                "normalized_name": "app1",
+                "version": "1.0.0",
+                "app_version": "3.0.0",
+                "kind": "chart",
+                "required_version": "^1.0.0",
+                "dependency_version": "1.2.0",
+                "ts": 1592299234,
+                "repository": {
+                    "repository_id": "00000000-0000-0000-0000-000000000001",
+                    "name": "repo1",
+                    "display_name": "Repo 1",
+                    "url": "https://repo1.com",
+                    "private": false,
+                    "kind": 0,
+                    "verified_publisher": false,
+                    "official": false,
+                    "scanner_disabled": false,
+                    "organization_name": "org1",
+                    "organization_display_name": "Organization 1"
+                }
+            }, {
+                "package_id": "00000000-0000-0000-0000-000000000003",
+                "name": "app2",
+                "normalized_name": "app2",
+                "version": "2.0.0",
+                "kind": "chart",
+                "required_version": "2.x",
+                "dependency_version": "2.0.0",
+                "ts": 1592299235,
+                "repository": {
+                    "repository_id": "00000000-0000-0000-0000-000000000001",
+                    "name": "repo1",
+                    "display_name": "Repo 1",
+                    "url": "https://repo1.com",
+                    "private": false,
+                    "kind": 0,
+                    "verified_publisher": false,
+                    "official": false,
+                    "scanner_disabled": false,
+                    "organization_name": "org1",
+                    "organization_display_name": "Organization 1"
+                }
+            }]'::jsonb,
+            2
+        )
+    $$,
+    'All dependents expected (latest versions only)'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from get_package_dependents('{
+            "package_id": "00000000-0000-0000-0000-000000000001",
+            "version_range": "< 2.0.0",
+            "limit": 10
+        }')
+    $$,
+    $$
+        values (
+            '[{
+                "package_id": "00000000-0000-0000-0000-000000000002",
+                "name": "app1",
+                "normalized_name": "app1",
+                "version": "1.0.0",
+                "app_version": "3.0.0",
+                "kind": "chart",
+                "required_version": "^1.0.0",
+                "dependency_version": "1.2.0",
+                "ts": 1592299234,
+                "repository": {
+                    "repository_id": "00000000-0000-0000-0000-000000000001",
+                    "name": "repo1",
+                    "display_name": "Repo 1",
+                    "url": "https://repo1.com",
+                    "private": false,
+                    "kind": 0,
+                    "verified_publisher": false,
+                    "official": false,
+                    "scanner_disabled": false,
+                    "organization_name": "org1",
+                    "organization_display_name": "Organization 1"
+                }
+            }]'::jsonb,
+            1
+        )
+    $$,
+    'Version range < 2.0.0: app1 expected'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from get_package_dependents('{
+            "package_id": "00000000-0000-0000-0000-000000000002",
+            "limit": 10
+        }')
+    $$,
+    $$
+        values ('[]'::jsonb, 0)
+    $$,
+    'No dependents expected for app1'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
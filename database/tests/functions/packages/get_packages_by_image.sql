@@ -0,0 +1,284 @@
+-- Start transaction and plan tests
+begin;
+select plan(6);
+
+-- Declare some variables
+\set org1ID '00000000-0000-0000-0000-000000000001'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+\set package2ID '00000000-0000-0000-0000-000000000002'
+
+-- Seed some data
+insert into organization (organization_id, name, display_name)
+values (:'org1ID', 'org1', 'Organization 1');
+insert into repository (repository_id, name, display_name, url, repository_kind_id, organization_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', 0, :'org1ID');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package1ID', 'package1', '1.0.0', :'repo1ID');
+insert into snapshot (package_id, version, app_version, ts)
+values (:'package1ID', '1.0.0', '12.1.0', '2020-06-16 11:20:34+02');
+insert into snapshot (package_id, version, app_version, ts)
+values (:'package1ID', '0.0.9', '12.0.0', '2020-06-16 11:20:33+02');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package2ID', 'package2', '2.0.0', :'repo1ID');
+insert into snapshot (package_id, version, app_version, ts)
+values (:'package2ID', '2.0.0', '13.0.0', '2020-06-16 11:20:35+02');
+insert into snapshot_image (package_id, version, image, repository, tag, digest) values
+    (:'package1ID', '1.0.0', 'quay.io/org/img:1.0.0', 'quay.io/org/img', '1.0.0', null),
+    (:'package1ID', '0.0.9', 'quay.io/org/img:0.9.0', 'quay.io/org/img', '0.9.0', null),
+    (:'package2ID', '2.0.0', 'quay.io/org/img@sha256:0123456789', 'quay.io/org/img', null, 'sha256:0123456789'),
+    (:'package2ID', '2.0.0', 'nginx:1.23', 'docker.io/library/nginx', '1.23', null);
+
+-- Run some tests
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from get_packages_by_image('{"image": "quay.io/org/img", "limit": 10}')
+    $$,
+    $$
+        values (
+            '[{
+                        "package_id": "00000000-0000-0000-0000-000000000001",
+                        "name": "package1",
+                        "normalized_name": "package1",
+                        "version": "1.0.0",
+                        "app_version": "12.1.0",
+                        "latest": true,
+                        "image": "quay.io/org/img:1.0.0",
+                        "ts": 1592299234,
+                        "repository": {
+                            "repository_id": "00000000-0000-0000-0000-000000000001",
+                            "name": "repo1",
+                            "display_name": "Repo 1",
+                            "url": "https://repo1.com",
+                            "private": false,
+                            "kind": 0,
+                            "verified_publisher": false,
+                            "official": false,
+                            "scanner_disabled": false,
+                            "organization_name": "org1",
+                            "organization_display_name": "Organization 1"
+                        }
+                    }, {
+                        "package_id": "00000000-0000-0000-0000-000000000001",
+                        "name": "package1",
+                        "normalized_name": "package1",
+                        "version": "0.0.9",
+                        "app_version": "12.0.0",
+                        "latest": false,
+                        "image": "quay.io/org/img:0.9.0",
+                        "ts": 1592299233,
+                        "repository": {
+                            "repository_id": "00000000-0000-0000-0000-000000000001",
+                            "name": "repo1",
+                            "display_name": "Repo 1",
+                            "url": "https://repo1.com",
+                            "private": false,
+                            "kind": 0,
+                            "verified_publisher": false,
+                            "official": false,
+                            "scanner_disabled": false,
+                            "organization_name": "org1",
+                            "organization_display_name": "Organization 1"
+                        }
+                    }, {
+                        "package_id": "00000000-0000-0000-0000-000000000002",
+                        "name": "package2",
+                        "normalized_name": "package2",
+                        "version": "2.0.0",
+                        "app_version": "13.0.0",
+                        "latest": true,
+                        "image": "quay.io/org/img@sha256:0123456789",
+                        "ts": 1592299235,
+                        "repository": {
+                            "repository_id": "00000000-0000-0000-0000-000000000001",
+                            "name": "repo1",
+                            "display_name": "Repo 1",
+                            "url": "https://repo1.com",
+                            "private": false,
+                            "kind": 0,
+                            "verified_publisher": false,
+                            "official": false,
+                            "scanner_disabled": false,
+                            "organization_name": "org1",
+                            "organization_display_name": "Organization 1"
+                        }
+                    }]'::jsonb,
+            3
+        )
+    $$,
+    'Any tag or digest of the image: all packages versions expected'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from get_packages_by_image('{"image": "quay.io/org/img", "latest_only": true, "limit": 10}')
+    $$,
+    $$
+        values (
+            '[{
+                        "package_id": "00000000-0000-0000-0000-000000000001",
+                        "name": "package1",
+                        "normalized_name": "package1",
+                        "version": "1.0.0",
+                        "app_version": "12.1.0",
+                        "latest": true,
+                        "image": "quay.io/org/img:1.0.0",
+                        "ts": 1592299234,
+                        "repository": {
+                            "repository_id": "00000000-0000-0000-0000-000000000001",
+                            "name": "repo1",
+                            "display_name": "Repo 1",
+                            "url": "https://repo1.com",
+                            "private": false,
+                            "kind": 0,
+                            "verified_publisher": false,
+                            "official": false,
+                            "scanner_disabled": false,
+                            "organization_name": "org1",
+                            "organization_display_name": "Organization 1"
+                        }
+                    }, {
+                        "package_id": "00000000-0000-0000-0000-000000000002",
+                        "name": "package2",
+                        "normalized_name": "package2",
+                        "version": "2.0.0",
+                        "app_version": "13.0.0",
+                        "latest": true,
+                        "image": "quay.io/org/img@sha256:0123456789",
+                        "ts": 1592299235,
+                        "repository": {
+                            "repository_id": "00000000-0000-0000-0000-000000000001",
+                            "name": "repo1",
+                            "display_name": "Repo 1",
+                            "url": "https://repo1.com",
+                            "private": false,
+                            "kind": 0,
+                            "verified_publisher": false,
+                            "official": false,
+                            "scanner_disabled": false,
+                            "organization_name": "org1",
+                            "organization_display_name": "Organization 1"
+                        }
+                    }]'::jsonb,
+            2
+        )
+    $$,
+    'Latest only: latest packages versions expected'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from get_packages_by_image('{"image": "quay.io/org/img:0.9.0", "limit": 10}')
+    $$,
+    $$
+        values (
+            '[{
+                        "package_id": "00000000-0000-0000-0000-000000000001",
+                        "name": "package1",
+                        "normalized_name": "package1",
+                        "version": "0.0.9",
+                        "app_version": "12.0.0",
+                        "latest": false,
+                        "image": "quay.io/org/img:0.9.0",
+                        "ts": 1592299233,
+                        "repository": {
+                            "repository_id": "00000000-0000-0000-0000-000000000001",
+                            "name": "repo1",
+                            "display_name": "Repo 1",
+                            "url": "https://repo1.com",
+                            "private": false,
+                            "kind": 0,
+                            "verified_publisher": false,
+                            "official": false,
+                            "scanner_disabled": false,
+                            "organization_name": "org1",
+                            "organization_display_name": "Organization 1"
+                        }
+                    }]'::jsonb,
+            1
+        )
+    $$,
+    'Image tag: package1 version 0.0.9 expected'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from get_packages_by_image('{"image": "docker.io/library/nginx", "limit": 10}')
+    $$,
+    $$
+        values (
+            '[{
+                        "package_id": "00000000-0000-0000-0000-000000000002",
+                        "name": "package2",
+                        "normalized_name": "package2",
+                        "version": "2.0.0",
+                        "app_version": "13.0.0",
+                        "latest": true,
+                        "image": "nginx:1.23",
+                        "ts": 1592299235,
+                        "repository": {
+                            "repository_id": "00000000-0000-0000-0000-000000000001",
+                            "name": "repo1",
+                            "display_name": "Repo 1",
+                            "url": "https://repo1.com",
+                            "private": false,
+                            "kind": 0,
+                            "verified_publisher": false,
+                            "official": false,
+                            "scanner_disabled": false,
+                            "organization_name": "org1",
+                            "organization_display_name": "Organization 1"
+                        }
+                    }]'::jsonb,
+            1
+        )
+    $$,
+    'Normalized image: package2 expected'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from get_packages_by_image('{"image": "quay.io/org/img", "limit": 1, "offset": 1}')
+    $$,
+    $$
+        values (
+            '[{
+                        "package_id": "00000000-0000-0000-0000-000000000001",
+                        "name": "package1",
+                        "normalized_name": "package1",
+                        "version": "0.0.9",
+                        "app_version": "12.0.0",
+                        "latest": false,
+                        "image": "quay.io/org/img:0.9.0",
+                        "ts": 1592299233,
+                        "repository": {
+                            "repository_id": "00000000-0000-0000-0000-000000000001",
+                            "name": "repo1",
+                            "display_name": "Repo 1",
+                            "url": "https://repo1.com",
+                            "private": false,
+                            "kind": 0,
+                            "verified_publisher": false,
+                            "official": false,
+                            "scanner_disabled": false,
+                            "organization_name": "org1",
+                            "organization_display_name": "Organization 1"
+                        }
+                    }]'::jsonb,
+            3
+        )
+    $$,
+    'Pagination: second package version expected'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from get_packages_by_image('{"image": "quay.io/org/other", "limit": 10}')
+    $$,
+    $$
+        values (
+            '[]'::jsonb,
+            0
+        )
+    $$,
+    'Image not used by any package: no packages expected'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
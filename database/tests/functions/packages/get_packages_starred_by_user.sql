@@ -155,7 +155,7 @@ insert into user_starred_package (user_id, package_id) values (:'user1ID', :'pac
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_packages_starred_by_user('00000000-0000-0000-0000-000000000001', 0, 0)
+        from get_packages_starred_by_user('00000000-0000-0000-0000-000000000001', 0, 0, null)
     $$,
     $$
         values(
@@ -218,7 +218,7 @@ select results_eq(
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_packages_starred_by_user('00000000-0000-0000-0000-000000000001', 1, 1)
+        from get_packages_starred_by_user('00000000-0000-0000-0000-000000000001', 1, 1, null)
     $$,
     $$
         values(
@@ -256,7 +256,7 @@ select results_eq(
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_packages_starred_by_user('00000000-0000-0000-0000-000000000002', 0, 0)
+        from get_packages_starred_by_user('00000000-0000-0000-0000-000000000002', 0, 0, null)
     $$,
     $$
         values('[]'::jsonb, 0)
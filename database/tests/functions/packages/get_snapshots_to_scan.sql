@@ -9,12 +9,14 @@ select plan(2);
 \set repo2ID '00000000-0000-0000-0000-000000000002'
 \set repo3ID '00000000-0000-0000-0000-000000000003'
 \set repo4ID '00000000-0000-0000-0000-000000000004'
+\set repo5ID '00000000-0000-0000-0000-000000000005'
 \set package1ID '00000000-0000-0000-0000-000000000001'
 \set package2ID '00000000-0000-0000-0000-000000000002'
 \set package3ID '00000000-0000-0000-0000-000000000003'
 \set package4ID '00000000-0000-0000-0000-000000000004'
 \set package5ID '00000000-0000-0000-0000-000000000005'
 \set package6ID '00000000-0000-0000-0000-000000000006'
+\set package7ID '00000000-0000-0000-0000-000000000007'
 
 -- No snapshots at this point
 select is(
@@ -35,6 +37,8 @@ insert into repository (repository_id, name, display_name, url, scanner_disabled
 values (:'repo3ID', 'repo3', 'Repo 3', 'https://repo3.com', true, 0, :'org1ID');
 insert into repository (repository_id, name, display_name, url, repository_kind_id, organization_id)
 values (:'repo4ID', 'repo4', 'Repo 4', 'https://repo4.com', 13, :'org1ID');
+insert into repository (repository_id, name, display_name, url, repository_kind_id, organization_id, data)
+values (:'repo5ID', 'repo5', 'Repo 5', 'https://repo5.com', 0, :'org1ID', '{"federation": {}}');
 insert into package (
     package_id,
     name,
@@ -219,6 +223,28 @@ insert into snapshot (
     current_timestamp - '2 weeks'::interval,
     '2010-06-16 11:20:30+02'
 );
+insert into package (
+    package_id,
+    name,
+    latest_version,
+    repository_id
+) values (
+    :'package7ID',
+    'package7',
+    '1.0.0',
+    :'repo5ID'
+);
+insert into snapshot (
+    package_id,
+    version,
+    containers_images,
+    created_at
+) values (
+    :'package7ID',
+    '1.0.0',
+    '[{"image": "quay.io/org/pkg7:1.0.0"}]',
+    '2020-06-16 11:20:29+02'
+);
 
 -- Run some tests
 select is(
@@ -0,0 +1,49 @@
+-- Start transaction and plan tests
+begin;
+select plan(8);
+
+-- Run some tests
+select results_eq(
+    $$ select * from parse_image_ref('quay.io/org/img:1.0.0') $$,
+    $$ values ('quay.io/org/img', '1.0.0', null::text) $$,
+    'Image with tag'
+);
+select results_eq(
+    $$ select * from parse_image_ref('quay.io/org/img') $$,
+    $$ values ('quay.io/org/img', null::text, null::text) $$,
+    'Image without tag'
+);
+select results_eq(
+    $$ select * from parse_image_ref('quay.io/org/img@sha256:0123456789') $$,
+    $$ values ('quay.io/org/img', null::text, 'sha256:0123456789') $$,
+    'Image with digest'
+);
+select results_eq(
+    $$ select * from parse_image_ref('quay.io/org/img:1.0.0@sha256:0123456789') $$,
+    $$ values ('quay.io/org/img', '1.0.0', 'sha256:0123456789') $$,
+    'Image with tag and digest'
+);
+select results_eq(
+    $$ select * from parse_image_ref('localhost:5000/org/img:1.0.0') $$,
+    $$ values ('localhost:5000/org/img', '1.0.0', null::text) $$,
+    'Image in registry with port'
+);
+select results_eq(
+    $$ select * from parse_image_ref('nginx:1.23') $$,
+    $$ values ('docker.io/library/nginx', '1.23', null::text) $$,
+    'Docker Hub official image'
+);
+select results_eq(
+    $$ select * from parse_image_ref('org/img') $$,
+    $$ values ('docker.io/org/img', null::text, null::text) $$,
+    'Docker Hub image'
+);
+select results_eq(
+    $$ select * from parse_image_ref('index.docker.io/Org/Img:V1') $$,
+    $$ values ('docker.io/org/img', 'V1', null::text) $$,
+    'Docker Hub image with legacy registry name'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
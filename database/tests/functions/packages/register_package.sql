@@ -1,6 +1,6 @@
 -- Start transaction and plan tests
 begin;
-select plan(14);
+select plan(18);
 
 -- Declare some variables
 \set org1ID '00000000-0000-0000-0000-000000000001'
@@ -114,6 +114,19 @@ select register_package('
         "fingerprint": "0011223344",
         "url": "https://key.url"
     },
+    "signature_verification": {
+        "status": "verified",
+        "kind": "prov",
+        "signer": "User 1 <user1@email.com>",
+        "fingerprint": "0011223344"
+    },
+    "provenance": {
+        "predicate_type": "https://slsa.dev/provenance/v1",
+        "builder_id": "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v1.9.0",
+        "source_repo": "https://github.com/org/repo",
+        "source_commit": "abcdef",
+        "build_level": 3
+    },
     "relative_path": "path1/path2",
     "repository": {
         "repository_id": "00000000-0000-0000-0000-000000000001"
@@ -185,6 +198,8 @@ select results_eq(
             s.recommendations,
             s.screenshots,
             s.sign_key,
+            s.signature_verification,
+            s.provenance,
             s.relative_path,
             s.ts
         from snapshot s
@@ -234,6 +249,8 @@ select results_eq(
                 }
             ]'::jsonb,
             '{"fingerprint": "0011223344", "url": "https://key.url"}'::jsonb,
+            '{"status": "verified", "kind": "prov", "signer": "User 1 <user1@email.com>", "fingerprint": "0011223344"}'::jsonb,
+            '{"predicate_type": "https://slsa.dev/provenance/v1", "builder_id": "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v1.9.0", "source_repo": "https://github.com/org/repo", "source_commit": "abcdef", "build_level": 3}'::jsonb,
             'path1/path2',
             '2020-06-16 11:20:34+02'::timestamptz
         )
@@ -258,6 +275,17 @@ select results_eq(
     $$,
     'Maintainers should exist'
 );
+select results_eq(
+    $$
+        select si.image, si.repository, si.tag, si.digest
+        from snapshot_image si
+        join package p using (package_id)
+        where p.name = 'package1'
+        and si.version = '1.0.0'
+    $$,
+    $$ values ('quay.io/org/img:1.0.0', 'quay.io/org/img', '1.0.0', null::text) $$,
+    'Snapshot images should exist'
+);
 select is_empty(
     $$
         select *
@@ -541,6 +569,82 @@ select is_empty(
     'No new release event should exist for package1 version 0.0.9'
 );
 
+-- Register a version including a security report summary in a repository
+-- that is not federated (summary is ignored)
+select register_package('
+{
+    "name": "package1",
+    "display_name": "Package 1",
+    "version": "0.0.7",
+    "digest": "digest-package1-0.0.7",
+    "security_report_summary": {
+        "high": 2
+    },
+    "security_report_created_at": 1592299232,
+    "repository": {
+        "repository_id": "00000000-0000-0000-0000-000000000001"
+    }
+}
+');
+select results_eq(
+    $$
+        select s.security_report_summary
+        from snapshot s
+        join package p using (package_id)
+        where name='package1'
+        and version='0.0.7'
+    $$,
+    $$
+        values (null::jsonb)
+    $$,
+    'Security report summary provided should be ignored when the repository is not federated'
+);
+
+-- Register a version including a security report summary in a federated
+-- repository (i.e. mirrored)
+update repository set data = '{"federation": {}}' where repository_id = :'repo1ID';
+select register_package('
+{
+    "name": "package1",
+    "display_name": "Package 1",
+    "version": "0.0.8",
+    "digest": "digest-package1-0.0.8",
+    "security_report_summary": {
+        "high": 2
+    },
+    "security_report_created_at": 1592299232,
+    "repository": {
+        "repository_id": "00000000-0000-0000-0000-000000000001"
+    }
+}
+');
+select results_eq(
+    $$
+        select s.security_report_summary, s.security_report_created_at
+        from snapshot s
+        join package p using (package_id)
+        where name='package1'
+        and version='0.0.8'
+    $$,
+    $$
+        values ('{"high": 2}'::jsonb, '2020-06-16 11:20:32+02'::timestamptz)
+    $$,
+    'Security report summary provided should have been stored'
+);
+select results_eq(
+    $$
+        select s.security_report_summary
+        from snapshot s
+        join package p using (package_id)
+        where name='package1'
+        and version='0.0.9'
+    $$,
+    $$
+        values (null::jsonb)
+    $$,
+    'No security report summary expected when it is not provided'
+);
+
 -- Disable repository and check that trying to register a package raises an error
 update repository set disabled = true where repository_id = :'repo1ID';
 select throws_ok(
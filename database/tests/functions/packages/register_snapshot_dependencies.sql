@@ -0,0 +1,153 @@
+-- Start transaction and plan tests
+begin;
+select plan(6);
+
+-- Declare some variables
+\set org1ID '00000000-0000-0000-0000-000000000001'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
+\set repo3ID '00000000-0000-0000-0000-000000000003'
+\set repo4ID '00000000-0000-0000-0000-000000000004'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+\set package2ID '00000000-0000-0000-0000-000000000002'
+\set package3ID '00000000-0000-0000-0000-000000000003'
+\set package4ID '00000000-0000-0000-0000-000000000004'
+\set package5ID '00000000-0000-0000-0000-000000000005'
+
+-- Seed some data
+insert into organization (organization_id, name, display_name)
+values (:'org1ID', 'org1', 'Organization 1');
+insert into repository (repository_id, name, display_name, url, repository_kind_id, organization_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', 0, :'org1ID');
+insert into repository (repository_id, name, display_name, url, repository_kind_id, organization_id)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://repo2.com', 0, :'org1ID');
+insert into repository (repository_id, name, display_name, url, repository_kind_id, organization_id)
+values (:'repo3ID', 'repo3', 'Repo 3', 'https://github.com/org/operators', 3, :'org1ID');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package1ID', 'common', '2.0.0', :'repo1ID');
+insert into snapshot (package_id, version) values (:'package1ID', '1.0.0');
+insert into snapshot (package_id, version) values (:'package1ID', '1.2.0');
+insert into snapshot (package_id, version) values (:'package1ID', '2.0.0');
+insert into repository (repository_id, name, display_name, url, repository_kind_id, organization_id)
+values (:'repo4ID', 'repo4', 'Repo 4', 'https://repo1.com/common', 0, :'org1ID');
+insert into package (package_id, name, latest_version, repository_id)
+values ('00000000-0000-0000-0000-000000000007', 'common', '1.1.0', :'repo4ID');
+insert into snapshot (package_id, version) values ('00000000-0000-0000-0000-000000000007', '1.1.0');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package2ID', 'app', '1.0.0', :'repo2ID');
+insert into snapshot (package_id, version, data) values (:'package2ID', '1.0.0', '{
+    "dependencies": [
+        {"name": "common", "version": "^1.0.0", "repository": "https://repo1.com/"},
+        {"name": "redis", "version": "17.x", "repository": "https://repo1.com"},
+        {"name": "local", "version": "0.1.0", "repository": "file://charts/local"}
+    ]
+}');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package3ID', 'redis', '17.3.0', :'repo1ID');
+insert into snapshot (package_id, version) values (:'package3ID', '17.3.0');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package4ID', 'etcd-operator', '0.9.4', :'repo3ID');
+insert into snapshot (package_id, version, crds) values (:'package4ID', '0.9.4', '[
+    {"name": "etcdclusters.etcd.database.coreos.com", "version": "v1beta2", "kind": "EtcdCluster"}
+]');
+insert into package (package_id, name, latest_version, repository_id)
+values (:'package5ID', 'app-operator', '1.0.0', :'repo3ID');
+insert into snapshot (package_id, version, data) values (:'package5ID', '1.0.0', '{
+    "requiredAPIs": [
+        {"name": "etcdclusters.etcd.database.coreos.com", "version": "v1beta2", "kind": "EtcdCluster"},
+        {"name": "pods.metrics.k8s.io", "version": "v1beta1", "kind": "PodMetrics"}
+    ]
+}');
+
+-- Run some tests
+select register_snapshot_dependencies(:'package2ID', '1.0.0');
+select results_eq(
+    $$
+        select kind, name, required_version, repository_url, dependency_package_id, dependency_version
+        from snapshot_dependency
+        where package_id = '00000000-0000-0000-0000-000000000002'
+        order by name asc
+    $$,
+    $$
+        values
+            ('chart', 'common', '^1.0.0', 'https://repo1.com/', '00000000-0000-0000-0000-000000000001'::uuid, '1.2.0'),
+            ('chart', 'local', '0.1.0', 'file://charts/local', null::uuid, null::text),
+            ('chart', 'redis', '17.x', 'https://repo1.com', '00000000-0000-0000-0000-000000000003'::uuid, '17.3.0')
+    $$,
+    'Helm chart dependencies should be registered and linked to the packages satisfying them'
+);
+select register_snapshot_dependencies(:'package2ID', '1.0.0');
+select results_eq(
+    $$
+        select count(*)::int from snapshot_dependency
+        where package_id = '00000000-0000-0000-0000-000000000002'
+    $$,
+    $$ values (3) $$,
+    'Registering the dependencies again should replace the existing ones'
+);
+select register_snapshot_dependencies(:'package5ID', '1.0.0');
+select results_eq(
+    $$
+        select kind, name, required_version, dependency_package_id, dependency_version
+        from snapshot_dependency
+        where package_id = '00000000-0000-0000-0000-000000000005'
+        order by name asc
+    $$,
+    $$
+        values
+            ('api', 'etcdclusters.etcd.database.coreos.com', 'v1beta2', '00000000-0000-0000-0000-000000000004'::uuid, '0.9.4'),
+            ('api', 'pods.metrics.k8s.io', 'v1beta1', null::uuid, null::text)
+    $$,
+    'OLM required APIs should be registered and linked to the operators owning them'
+);
+
+-- Register a package version satisfying a pending dependency
+insert into package (package_id, name, latest_version, repository_id)
+values ('00000000-0000-0000-0000-000000000006', 'local', '0.1.0', :'repo2ID');
+insert into snapshot (package_id, version) values ('00000000-0000-0000-0000-000000000006', '0.1.0');
+select register_snapshot_dependencies('00000000-0000-0000-0000-000000000006', '0.1.0');
+select results_eq(
+    $$
+        select dependency_package_id, dependency_version
+        from snapshot_dependency
+        where package_id = '00000000-0000-0000-0000-000000000002'
+        and name = 'local'
+    $$,
+    $$ values ('00000000-0000-0000-0000-000000000006'::uuid, '0.1.0') $$,
+    'Pending dependencies satisfied by the package registered should be linked to it'
+);
+
+-- Register a new version of a package other packages depend on
+insert into snapshot (package_id, version) values (:'package1ID', '1.5.0');
+select register_snapshot_dependencies(:'package1ID', '1.5.0');
+select results_eq(
+    $$
+        select dependency_package_id, dependency_version
+        from snapshot_dependency
+        where package_id = '00000000-0000-0000-0000-000000000002'
+        and name = 'common'
+    $$,
+    $$ values ('00000000-0000-0000-0000-000000000001'::uuid, '1.5.0') $$,
+    'Dependencies on the package registered should be resolved again'
+);
+
+-- Register a new version of a package with dependencies pending to be resolved
+-- (i.e. registered by the migration)
+update snapshot_dependency set dependency_version = null
+where package_id = :'package2ID' and name = 'redis';
+insert into snapshot (package_id, version) values (:'package3ID', '17.4.0');
+select register_snapshot_dependencies(:'package3ID', '17.4.0');
+select results_eq(
+    $$
+        select dependency_package_id, dependency_version
+        from snapshot_dependency
+        where package_id = '00000000-0000-0000-0000-000000000002'
+        and name = 'redis'
+    $$,
+    $$ values ('00000000-0000-0000-0000-000000000003'::uuid, '17.4.0') $$,
+    'Dependencies versions pending to be resolved should be resolved'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
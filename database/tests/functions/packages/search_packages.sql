@@ -1,6 +1,6 @@
 -- Start transaction and plan tests
 begin;
-select plan(29);
+select plan(40);
 
 -- Declare some variables
 \set user1ID '00000000-0000-0000-0000-000000000001'
@@ -390,6 +390,20 @@ select results_eq(
                             "name": "basic install",
                             "total": 1
                         }]
+                    },
+                    {
+                        "title": "Chart type",
+                        "filter_key": "helm_type",
+                        "options": [{
+                            "id": "application",
+                            "name": "Application",
+                            "total": 2
+                        }]
+                    },
+                    {
+                        "title": "Provenance",
+                        "filter_key": "has_provenance",
+                        "options": []
                     }
                 ]
             }'::jsonb,
@@ -860,6 +874,20 @@ select results_eq(
                             "name": "basic install",
                             "total": 1
                         }]
+                    },
+                    {
+                        "title": "Chart type",
+                        "filter_key": "helm_type",
+                        "options": [{
+                            "id": "application",
+                            "name": "Application",
+                            "total": 2
+                        }]
+                    },
+                    {
+                        "title": "Provenance",
+                        "filter_key": "has_provenance",
+                        "options": []
                     }
                 ]
             }'::jsonb,
@@ -952,6 +980,20 @@ select results_eq(
                             "name": "basic install",
                             "total": 1
                         }]
+                    },
+                    {
+                        "title": "Chart type",
+                        "filter_key": "helm_type",
+                        "options": [{
+                            "id": "application",
+                            "name": "Application",
+                            "total": 1
+                        }]
+                    },
+                    {
+                        "title": "Provenance",
+                        "filter_key": "has_provenance",
+                        "options": []
                     }
                 ]
             }'::jsonb,
@@ -976,6 +1018,258 @@ select results_eq(
     $$,
     'TSQueryWeb: kw9 (inexistent) | No packages or facets expected'
 );
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from search_packages('{
+            "has_provenance": true
+        }')
+    $$,
+    $$
+        values (
+            '{
+                "packages": []
+            }'::jsonb,
+            0
+        )
+    $$,
+    'HasProvenance: true | No packages expected (none with provenance)'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from search_packages('{
+            "signed": true,
+            "deprecated": true
+        }')
+    $$,
+    $$
+        values (
+            '{
+                "packages": [
+                    {
+                        "package_id": "00000000-0000-0000-0000-000000000002",
+                        "name": "package2",
+                        "normalized_name": "package2",
+                        "stars": 11,
+                        "official": true,
+                        "display_name": "Package 2",
+                        "description": "description",
+                        "logo_image_id": "00000000-0000-0000-0000-000000000002",
+                        "version": "1.0.0",
+                        "app_version": "12.1.0",
+                        "deprecated": true,
+                        "signed": true,
+                        "signatures": ["cosign"],
+                        "all_containers_images_whitelisted": false,
+                        "production_organizations_count": 0,
+                        "ts": 1592299234,
+                        "repository": {
+                            "repository_id": "00000000-0000-0000-0000-000000000002",
+                            "kind": 0,
+                            "name": "repo2",
+                            "display_name": "Repo 2",
+                            "url": "https://repo2.com",
+                            "verified_publisher": false,
+                            "official": false,
+                            "scanner_disabled": false,
+                            "organization_name": "org1",
+                            "organization_display_name": "Organization 1"
+                        }
+                    }
+                ]
+            }'::jsonb,
+            1
+        )
+    $$,
+    'Signed: true Deprecated: true | Package 2 expected'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from search_packages('{
+            "has_security_report": true
+        }')
+    $$,
+    $$
+        values (
+            '{
+                "packages": [
+                    {
+                        "package_id": "00000000-0000-0000-0000-000000000003",
+                        "name": "package3",
+                        "normalized_name": "package3",
+                        "stars": 0,
+                        "display_name": "Package 3",
+                        "description": "description",
+                        "logo_image_id": "00000000-0000-0000-0000-000000000003",
+                        "version": "1.0.0",
+                        "security_report_summary": {
+                            "high": 2,
+                            "medium": 1
+                        },
+                        "all_containers_images_whitelisted": true,
+                        "production_organizations_count": 0,
+                        "ts": 1592299234,
+                        "repository": {
+                            "repository_id": "00000000-0000-0000-0000-000000000003",
+                            "kind": 1,
+                            "name": "repo3",
+                            "display_name": "Repo 3",
+                            "url": "https://repo3.com",
+                            "verified_publisher": false,
+                            "official": false,
+                            "scanner_disabled": false,
+                            "organization_name": "org1",
+                            "organization_display_name": "Organization 1"
+                        }
+                    }
+                ]
+            }'::jsonb,
+            1
+        )
+    $$,
+    'HasSecurityReport: true | Package 3 expected'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from search_packages('{
+            "max_severity": "high"
+        }')
+    $$,
+    $$
+        values (
+            '{
+                "packages": [
+                    {
+                        "package_id": "00000000-0000-0000-0000-000000000003",
+                        "name": "package3",
+                        "normalized_name": "package3",
+                        "stars": 0,
+                        "display_name": "Package 3",
+                        "description": "description",
+                        "logo_image_id": "00000000-0000-0000-0000-000000000003",
+                        "version": "1.0.0",
+                        "security_report_summary": {
+                            "high": 2,
+                            "medium": 1
+                        },
+                        "all_containers_images_whitelisted": true,
+                        "production_organizations_count": 0,
+                        "ts": 1592299234,
+                        "repository": {
+                            "repository_id": "00000000-0000-0000-0000-000000000003",
+                            "kind": 1,
+                            "name": "repo3",
+                            "display_name": "Repo 3",
+                            "url": "https://repo3.com",
+                            "verified_publisher": false,
+                            "official": false,
+                            "scanner_disabled": false,
+                            "organization_name": "org1",
+                            "organization_display_name": "Organization 1"
+                        }
+                    }
+                ]
+            }'::jsonb,
+            1
+        )
+    $$,
+    'MaxSeverity: high | Package 3 expected (no critical vulnerabilities)'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from search_packages('{
+            "max_severity": "medium"
+        }')
+    $$,
+    $$
+        values (
+            '{
+                "packages": []
+            }'::jsonb,
+            0
+        )
+    $$,
+    'MaxSeverity: medium | No packages expected (package 3 has high vulnerabilities)'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from search_packages('{
+            "repositories": [
+                "repo1"
+            ],
+            "kubernetes_version": "1.24.0"
+        }')
+    $$,
+    $$
+        values (
+            '{
+                "packages": [
+                    {
+                        "package_id": "00000000-0000-0000-0000-000000000001",
+                        "name": "package1",
+                        "normalized_name": "package1",
+                        "stars": 10,
+                        "official": false,
+                        "display_name": "Package 1",
+                        "description": "description",
+                        "logo_image_id": "00000000-0000-0000-0000-000000000001",
+                        "version": "1.0.0",
+                        "app_version": "12.1.0",
+                        "license": "Apache-2.0",
+                        "production_organizations_count": 1,
+                        "ts": 1592299234,
+                        "repository": {
+                            "repository_id": "00000000-0000-0000-0000-000000000001",
+                            "kind": 0,
+                            "name": "repo1",
+                            "display_name": "Repo 1",
+                            "url": "https://repo1.com",
+                            "verified_publisher": true,
+                            "official": true,
+                            "scanner_disabled": false,
+                            "user_alias": "user1"
+                        }
+                    }
+                ]
+            }'::jsonb,
+            1
+        )
+    $$,
+    'Repo: repo1 KubernetesVersion: 1.24.0 | Package 1 expected (no kubeVersion constraint)'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from search_packages('{
+            "helm_types": [
+                "library"
+            ]
+        }')
+    $$,
+    $$
+        values (
+            '{
+                "packages": []
+            }'::jsonb,
+            0
+        )
+    $$,
+    'HelmTypes: library | No packages expected'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer from search_packages('{
+            "updated_within": 1
+        }')
+    $$,
+    $$
+        values (
+            '{
+                "packages": []
+            }'::jsonb,
+            0
+        )
+    $$,
+    'UpdatedWithin: 1 | No packages expected'
+);
 
 -- Tests with kind and repositories filters
 select results_eq(
@@ -1124,6 +1418,20 @@ select results_eq(
                             "name": "basic install",
                             "total": 1
                         }]
+                    },
+                    {
+                        "title": "Chart type",
+                        "filter_key": "helm_type",
+                        "options": [{
+                            "id": "application",
+                            "name": "Application",
+                            "total": 2
+                        }]
+                    },
+                    {
+                        "title": "Provenance",
+                        "filter_key": "has_provenance",
+                        "options": []
                     }
                 ]
             }'::jsonb,
@@ -1193,6 +1501,20 @@ select results_eq(
                             "name": "basic install",
                             "total": 1
                         }]
+                    },
+                    {
+                        "title": "Chart type",
+                        "filter_key": "helm_type",
+                        "options": [{
+                            "id": "application",
+                            "name": "Application",
+                            "total": 1
+                        }]
+                    },
+                    {
+                        "title": "Provenance",
+                        "filter_key": "has_provenance",
+                        "options": []
                     }
                 ]
             }'::jsonb,
@@ -1261,6 +1583,20 @@ select results_eq(
                             "name": "basic install",
                             "total": 1
                         }]
+                    },
+                    {
+                        "title": "Chart type",
+                        "filter_key": "helm_type",
+                        "options": [{
+                            "id": "application",
+                            "name": "Application",
+                            "total": 1
+                        }]
+                    },
+                    {
+                        "title": "Provenance",
+                        "filter_key": "has_provenance",
+                        "options": []
                     }
                 ]
             }'::jsonb,
@@ -1329,6 +1665,20 @@ select results_eq(
                             "name": "basic install",
                             "total": 1
                         }]
+                    },
+                    {
+                        "title": "Chart type",
+                        "filter_key": "helm_type",
+                        "options": [{
+                            "id": "application",
+                            "name": "Application",
+                            "total": 1
+                        }]
+                    },
+                    {
+                        "title": "Provenance",
+                        "filter_key": "has_provenance",
+                        "options": []
                     }
                 ]
             }'::jsonb,
@@ -1814,6 +2164,54 @@ select results_eq(
     $$,
     'Limit: 1 Offset: 1 TSQueryWeb: kw1 | Package 2 expected'
 );
+select is(
+    (
+        select data->'packages'->0->>'name'
+        from search_packages(jsonb_build_object(
+            'limit', 1,
+            'ts_query_web', 'kw1',
+            'deprecated', true,
+            'cursor', (
+                select next_cursor
+                from search_packages('{
+                    "limit": 1,
+                    "offset": 0,
+                    "ts_query_web": "kw1",
+                    "deprecated": true
+                }')
+            )
+        ))
+    ),
+    'package2',
+    'Limit: 1 Cursor: first page TSQueryWeb: kw1 | Package 2 expected'
+);
+select is(
+    (
+        select next_cursor->>'sort'
+        from search_packages('{
+            "limit": 1,
+            "offset": 0,
+            "ts_query_web": "kw1",
+            "deprecated": true,
+            "sort": "stars"
+        }')
+    ),
+    'stars',
+    'Limit: 1 Offset: 0 TSQueryWeb: kw1 Sort: stars | Cursor including the sort expected'
+);
+select is(
+    (
+        select next_cursor
+        from search_packages('{
+            "limit": 1,
+            "offset": 1,
+            "ts_query_web": "kw1",
+            "deprecated": true
+        }')
+    ),
+    null::jsonb,
+    'Limit: 1 Offset: 1 TSQueryWeb: kw1 | No cursor expected as there are no more packages'
+);
 select results_eq(
     $$
         select data::jsonb, total_count::integer from search_packages('{
@@ -1902,6 +2300,20 @@ select results_eq(
                             "name": "basic install",
                             "total": 1
                         }]
+                    },
+                    {
+                        "title": "Chart type",
+                        "filter_key": "helm_type",
+                        "options": [{
+                            "id": "application",
+                            "name": "Application",
+                            "total": 2
+                        }]
+                    },
+                    {
+                        "title": "Provenance",
+                        "filter_key": "has_provenance",
+                        "options": []
                     }
                 ]
             }'::jsonb,
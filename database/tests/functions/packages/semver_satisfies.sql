@@ -0,0 +1,29 @@
+-- Start transaction and plan tests
+begin;
+select plan(20);
+
+-- Run some tests
+select is(semver_satisfies('1.24.0', null), true, 'No constraint');
+select is(semver_satisfies('1.24.0', ''), true, 'Empty constraint');
+select is(semver_satisfies('1.24.0', '>=1.19.0-0'), true, 'Greater or equal than');
+select is(semver_satisfies('1.18.3', '>=1.19.0-0'), false, 'Not greater or equal than');
+select is(semver_satisfies('1.24.0', '>= 1.19.0-0, < 1.25.0-0'), true, 'Within range (comma and spaces)');
+select is(semver_satisfies('1.25.0', '>=1.19.0-0 <1.25.0-0'), false, 'Out of range (upper bound)');
+select is(semver_satisfies('1.24.5', '1.24'), true, 'Partial version');
+select is(semver_satisfies('1.25.0', '1.24.x'), false, 'X-range');
+select is(semver_satisfies('1.24.5', '~1.24.2'), true, 'Tilde range');
+select is(semver_satisfies('1.25.0', '~1.24.2'), false, 'Out of tilde range');
+select is(semver_satisfies('1.30.0', '^1.19'), true, 'Caret range');
+select is(semver_satisfies('2.0.0', '^1.19'), false, 'Out of caret range');
+select is(semver_satisfies('1.24.0', '>1.23'), true, 'Greater than partial version');
+select is(semver_satisfies('1.23.5', '<=1.23'), true, 'Less or equal than partial version');
+select is(semver_satisfies('1.24.0', '!=1.24.0'), false, 'Not equal');
+select is(semver_satisfies('1.24.0', '<1.20.0 || >=1.24.0'), true, 'Any of the groups');
+select is(semver_satisfies('1.22.0', '<1.20.0 || >=1.24.0'), false, 'None of the groups');
+select is(semver_satisfies('1.24.0', 'invalid'), false, 'Invalid constraint');
+select is(semver_satisfies('1.24.0', '>=99999999999'), false, 'Constraint version out of range');
+select is(semver_satisfies('99999999999.0.0', '>=1.19'), false, 'Version out of range');
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
@@ -0,0 +1,98 @@
+-- Start transaction and plan tests
+begin;
+select plan(3);
+
+-- Declare some variables
+\set user1ID '00000000-0000-0000-0000-000000000001'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set run1ID '00000000-0000-0000-0000-000000000001'
+\set run2ID '00000000-0000-0000-0000-000000000002'
+
+-- Repository does not exist
+select is_empty(
+    $$ select get_repository_tracking_runs('repo1') $$,
+    'No rows are returned when the repository does not exist'
+);
+
+-- Seed some data
+insert into "user" (user_id, alias, email)
+values (:'user1ID', 'user1', 'user1@email.com');
+insert into repository (repository_id, name, display_name, url, repository_kind_id, user_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', 0, :'user1ID');
+
+-- No tracking runs registered yet
+select is(
+    get_repository_tracking_runs('repo1')::jsonb,
+    '[]'::jsonb,
+    'An empty json array is returned when there are no tracking runs'
+);
+
+-- Seed some tracking runs
+insert into repository_tracking_run (
+    repository_tracking_run_id,
+    repository_id,
+    started_at,
+    finished_at,
+    packages_registered,
+    packages_unregistered,
+    packages_skipped,
+    errors,
+    errors_count,
+    errors_truncated
+) values (
+    :'run1ID',
+    :'repo1ID',
+    '2020-06-16 11:20:30+02',
+    '2020-06-16 11:20:34+02',
+    2,
+    1,
+    0,
+    null,
+    0,
+    false
+), (
+    :'run2ID',
+    :'repo1ID',
+    '2020-06-16 12:20:30+02',
+    '2020-06-16 12:20:34+02',
+    0,
+    0,
+    3,
+    '[{"package_name": "pkg1", "version": "1.0.0", "class": "package", "message": "error1"}]',
+    101,
+    true
+);
+
+-- Tracking runs are returned (most recent first)
+select is(
+    get_repository_tracking_runs('repo1')::jsonb,
+    '[
+        {
+            "tracking_run_id": "00000000-0000-0000-0000-000000000002",
+            "started_at": 1592302830,
+            "finished_at": 1592302834,
+            "packages_registered": 0,
+            "packages_unregistered": 0,
+            "packages_skipped": 3,
+            "errors_count": 101,
+            "errors_truncated": true,
+            "errors": [{"package_name": "pkg1", "version": "1.0.0", "class": "package", "message": "error1"}]
+        },
+        {
+            "tracking_run_id": "00000000-0000-0000-0000-000000000001",
+            "started_at": 1592299230,
+            "finished_at": 1592299234,
+            "packages_registered": 2,
+            "packages_unregistered": 1,
+            "packages_skipped": 0,
+            "errors_count": 0,
+            "errors_truncated": false,
+            "errors": null
+        }
+    ]'::jsonb,
+    'Tracking runs are returned as a json array, most recent first'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
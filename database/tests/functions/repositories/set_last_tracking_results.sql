@@ -1,6 +1,6 @@
 -- Start transaction and plan tests
 begin;
-select plan(15);
+select plan(19);
 
 -- Declare some variables
 \set user1ID '00000000-0000-0000-0000-000000000001'
@@ -21,7 +21,7 @@ select is(count(*), 0::bigint, 'No tracking errors events should have been regis
 from event where repository_id=:'repo1ID' and event_kind_id = 2;
 
 -- Set last tracking results and run some more tests
-select set_last_tracking_results(:'repo1ID', '', true);
+select set_last_tracking_results(:'repo1ID', '', true, null, 2);
 select isnt(last_tracking_ts, null, 'Last tracking ts should have been set')
 from repository where name = 'repo1';
 select is(last_tracking_errors, null, 'Last tracking errors should have been set to null')
@@ -30,38 +30,96 @@ select is(count(*), 0::bigint, 'No tracking errors events should have been regis
 from event where repository_id=:'repo1ID' and event_kind_id = 2;
 
 -- Set last tracking results again and run some more tests
-select set_last_tracking_results(:'repo1ID', 'some errors', true);
+select set_last_tracking_results(:'repo1ID', 'some errors', true, null, 2);
 select is(last_tracking_errors, 'some errors', 'Last tracking errors should have been set to some errors')
 from repository where name = 'repo1';
 select is(count(*), 1::bigint, 'One tracking error event should have been registered')
 from event where repository_id=:'repo1ID' and event_kind_id = 2;
 
 -- Set last tracking results again with the same error and run some more tests
-select set_last_tracking_results(:'repo1ID', 'some errors', true);
+select set_last_tracking_results(:'repo1ID', 'some errors', true, null, 2);
 select is(count(*), 1::bigint, 'No more tracking error events should have been registered')
 from event where repository_id=:'repo1ID' and event_kind_id = 2;
 
 -- Set last tracking results again with another error and run some more tests
-select set_last_tracking_results(:'repo1ID', 'some new errors', true);
+select set_last_tracking_results(:'repo1ID', 'some new errors', true, null, 2);
 select is(last_tracking_errors, 'some new errors', 'Last tracking errors should have been set to some new errors')
 from repository where name = 'repo1';
 select is(count(*), 2::bigint, 'One more tracking error event should have been registered (total 2 now)')
 from event where repository_id=:'repo1ID' and event_kind_id = 2;
 
 -- Set last tracking results again with no errors and run some more tests
-select set_last_tracking_results(:'repo1ID', '', true);
+select set_last_tracking_results(:'repo1ID', '', true, null, 2);
 select is(last_tracking_errors, null, 'Last tracking errors should have been set to null')
 from repository where name = 'repo1';
 select is(count(*), 2::bigint, 'No more tracking error events should have been registered')
 from event where repository_id=:'repo1ID' and event_kind_id = 2;
 
 -- Set last tracking results again with another error and run some more tests
-select set_last_tracking_results(:'repo1ID', 'some new errors', false);
+select set_last_tracking_results(:'repo1ID', 'some new errors', false, null, 2);
 select is(last_tracking_errors, 'some new errors', 'Last tracking errors should have been set to some new errors')
 from repository where name = 'repo1';
 select is(count(*), 2::bigint, 'No more tracking error events should have been registered')
 from event where repository_id=:'repo1ID' and event_kind_id = 2;
 
+select is(count(*), 0::bigint, 'No tracking runs should have been registered')
+from repository_tracking_run where repository_id=:'repo1ID';
+
+-- Set last tracking results including some tracking runs and run some more tests
+select set_last_tracking_results(:'repo1ID', '', true, '{
+    "started_at": 1592299234,
+    "finished_at": 1592299244,
+    "packages_registered": 2,
+    "packages_unregistered": 1,
+    "packages_skipped": 5,
+    "errors_count": 0,
+    "errors_truncated": false
+}', 2);
+select results_eq(
+    $$
+        select
+            extract(epoch from started_at)::bigint,
+            extract(epoch from finished_at)::bigint,
+            packages_registered,
+            packages_unregistered,
+            packages_skipped,
+            errors,
+            errors_count
+        from repository_tracking_run
+        where repository_id = '00000000-0000-0000-0000-000000000001'
+    $$,
+    $$
+        values (1592299234::bigint, 1592299244::bigint, 2, 1, 5, null::jsonb, 0)
+    $$,
+    'Tracking run should have been registered'
+);
+select set_last_tracking_results(:'repo1ID', 'error1', true, '{
+    "started_at": 1592299235,
+    "errors": [{"class": "generic", "message": "error1"}],
+    "errors_count": 1
+}', 2);
+select set_last_tracking_results(:'repo1ID', 'error2', true, '{
+    "started_at": 1592299236,
+    "errors": [{"class": "generic", "message": "error2"}],
+    "errors_count": 1
+}', 2);
+select is(count(*), 2::bigint, 'Only the two most recent tracking runs should have been kept')
+from repository_tracking_run where repository_id=:'repo1ID';
+select results_eq(
+    $$
+        select errors
+        from repository_tracking_run
+        where repository_id = '00000000-0000-0000-0000-000000000001'
+        order by started_at asc
+    $$,
+    $$
+        values
+            ('[{"class": "generic", "message": "error1"}]'::jsonb),
+            ('[{"class": "generic", "message": "error2"}]'::jsonb)
+    $$,
+    'Oldest tracking run should have been removed'
+);
+
 -- Finish tests and rollback transaction
 select * from finish();
 rollback;
@@ -43,7 +43,7 @@ values (:'user1ID', :'package2ID', 0);
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_user_subscriptions('00000000-0000-0000-0000-000000000001', 0, 0)
+        from get_user_subscriptions('00000000-0000-0000-0000-000000000001', 0, 0, null)
     $$,
     $$
         values (
@@ -96,7 +96,7 @@ select results_eq(
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_user_subscriptions('00000000-0000-0000-0000-000000000001', 1, 1)
+        from get_user_subscriptions('00000000-0000-0000-0000-000000000001', 1, 1, null)
     $$,
     $$
         values (
@@ -130,7 +130,7 @@ select results_eq(
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_user_subscriptions('00000000-0000-0000-0000-000000000002', 0, 0)
+        from get_user_subscriptions('00000000-0000-0000-0000-000000000002', 0, 0, null)
     $$,
     $$
         values ('[]'::jsonb, 0)
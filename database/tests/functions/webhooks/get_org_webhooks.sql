@@ -92,7 +92,7 @@ insert into webhook__package (webhook_id, package_id) values (:'webhook2ID', :'p
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_org_webhooks('00000000-0000-0000-0000-000000000001', 'org1', 0, 0)
+        from get_org_webhooks('00000000-0000-0000-0000-000000000001', 'org1', 0, 0, null)
     $$,
     $$
         values(
@@ -173,7 +173,7 @@ select results_eq(
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_org_webhooks('00000000-0000-0000-0000-000000000001', 'org1', 1, 1)
+        from get_org_webhooks('00000000-0000-0000-0000-000000000001', 'org1', 1, 1, null)
     $$,
     $$
         values(
@@ -220,7 +220,7 @@ select results_eq(
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_org_webhooks('00000000-0000-0000-0000-000000000002', 'org1', 0, 0)
+        from get_org_webhooks('00000000-0000-0000-0000-000000000002', 'org1', 0, 0, null)
     $$,
     $$
         values('[]'::jsonb, 0)
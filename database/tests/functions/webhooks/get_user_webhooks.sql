@@ -1,6 +1,6 @@
 -- Start transaction and plan tests
 begin;
-select plan(3);
+select plan(5);
 
 -- Declare some variables
 \set user1ID '00000000-0000-0000-0000-000000000001'
@@ -88,7 +88,7 @@ insert into webhook__package (webhook_id, package_id) values (:'webhook2ID', :'p
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_user_webhooks('00000000-0000-0000-0000-000000000001', 0, 0)
+        from get_user_webhooks('00000000-0000-0000-0000-000000000001', 0, 0, null)
     $$,
     $$
         values(
@@ -169,7 +169,7 @@ select results_eq(
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_user_webhooks('00000000-0000-0000-0000-000000000001', 1, 1)
+        from get_user_webhooks('00000000-0000-0000-0000-000000000001', 1, 1, null)
     $$,
     $$
         values(
@@ -216,7 +216,69 @@ select results_eq(
 select results_eq(
     $$
         select data::jsonb, total_count::integer
-        from get_user_webhooks('00000000-0000-0000-0000-000000000002', 0, 0)
+        from get_user_webhooks(
+            '00000000-0000-0000-0000-000000000001',
+            1,
+            0,
+            '{"name": "webhook1", "webhook_id": "00000000-0000-0000-0000-000000000001"}'
+        )
+    $$,
+    $$
+        values(
+            '[
+                {
+                    "webhook_id": "00000000-0000-0000-0000-000000000002",
+                    "name": "webhook2",
+                    "description": "description",
+                    "url": "http://webhook2.url",
+                    "secret": "very",
+                    "content_type": "application/json",
+                    "template": "custom payload",
+                    "active": true,
+                    "event_kinds": [1],
+                    "packages": [
+                        {
+                            "package_id": "00000000-0000-0000-0000-000000000001",
+                            "name": "Package 1",
+                            "normalized_name": "package-1",
+                            "stars": 0,
+                            "version": "1.0.0",
+                            "logo_image_id": "00000000-0000-0000-0000-000000000001",
+                            "ts": 1592299234,
+                            "repository": {
+                                "repository_id": "00000000-0000-0000-0000-000000000001",
+                                "kind": 0,
+                                "name": "repo1",
+                                "display_name": "Repo 1",
+                                "url": "https://repo1.com",
+                                "private": false,
+                                "verified_publisher": false,
+                                "official": false,
+                                "scanner_disabled": false,
+                                "user_alias": "user1"
+                            }
+                        }
+                    ]
+                }
+            ]'::jsonb,
+            2)
+    $$,
+    'Only webhook2 owned by user1 should be returned when using a limit of 1 and the cursor of the first page'
+);
+select results_eq(
+    $$
+        select next_cursor
+        from get_user_webhooks('00000000-0000-0000-0000-000000000001', 1, 0, null)
+    $$,
+    $$
+        values ('{"name": "webhook1", "webhook_id": "00000000-0000-0000-0000-000000000001"}'::jsonb)
+    $$,
+    'Cursor pointing to webhook1 should be returned when there are more webhooks available'
+);
+select results_eq(
+    $$
+        select data::jsonb, total_count::integer
+        from get_user_webhooks('00000000-0000-0000-0000-000000000002', 0, 0, null)
     $$,
     $$
         values('[]'::jsonb, 0)
@@ -1,6 +1,6 @@
 -- Start transaction and plan tests
 begin;
-select plan(185);
+select plan(213);
 
 -- Check default_text_search_config is correct
 select results_eq(
@@ -17,6 +17,8 @@ select has_extension('pg_partman');
 
 -- Check expected tables exist
 select has_table('api_key');
+select has_table('change');
+select has_table('change_pruned');
 select has_table('delete_user_code');
 select has_table('email_verification_code');
 select has_table('event');
@@ -34,8 +36,11 @@ select has_table('password_reset_code');
 select has_table('production_usage');
 select has_table('repository');
 select has_table('repository_kind');
+select has_table('repository_tracking_run');
 select has_table('session');
 select has_table('snapshot');
+select has_table('snapshot_dependency');
+select has_table('snapshot_image');
 select has_table('subscription');
 select has_table('user');
 select has_table('user_starred_package');
@@ -54,6 +59,20 @@ select columns_are('api_key', array[
     'user_id',
     'created_at'
 ]);
+select columns_are('change', array[
+    'change_id',
+    'tx_id',
+    'entity_kind',
+    'operation',
+    'repository_id',
+    'package_id',
+    'version',
+    'created_at'
+]);
+select columns_are('change_pruned', array[
+    'tx_id',
+    'change_id'
+]);
 select columns_are('delete_user_code', array[
     'delete_user_code_id',
     'user_id',
@@ -176,12 +195,25 @@ select columns_are('repository', array[
     'data',
     'repository_kind_id',
     'user_id',
-    'organization_id'
+    'organization_id',
+    'encrypted_git_auth'
 ]);
 select columns_are('repository_kind', array[
     'repository_kind_id',
     'name'
 ]);
+select columns_are('repository_tracking_run', array[
+    'repository_tracking_run_id',
+    'repository_id',
+    'started_at',
+    'finished_at',
+    'packages_registered',
+    'packages_unregistered',
+    'packages_skipped',
+    'errors',
+    'errors_count',
+    'errors_truncated'
+]);
 select columns_are('session', array[
     'session_id',
     'user_id',
@@ -227,9 +259,29 @@ select columns_are('snapshot', array[
     'recommendations',
     'screenshots',
     'sign_key',
+    'signature_verification',
     'signatures',
+    'provenance',
     'relative_path'
 ]);
+select columns_are('snapshot_dependency', array[
+    'package_id',
+    'version',
+    'kind',
+    'name',
+    'required_version',
+    'repository_url',
+    'dependency_package_id',
+    'dependency_version'
+]);
+select columns_are('snapshot_image', array[
+    'package_id',
+    'version',
+    'image',
+    'repository',
+    'tag',
+    'digest'
+]);
 select columns_are('subscription', array[
     'user_id',
     'package_id',
@@ -291,6 +343,11 @@ select columns_are('webhook__package', array[
 select indexes_are('api_key', array[
     'api_key_pkey'
 ]);
+select indexes_are('change', array[
+    'change_pkey',
+    'change_tx_id_change_id_idx',
+    'change_created_at_idx'
+]);
 select indexes_are('delete_user_code', array[
     'delete_user_code_pkey',
     'delete_user_code_user_id_key'
@@ -360,6 +417,10 @@ select indexes_are('repository', array[
 select indexes_are('repository_kind', array[
     'repository_kind_pkey'
 ]);
+select indexes_are('repository_tracking_run', array[
+    'repository_tracking_run_pkey',
+    'repository_tracking_run_repository_id_idx'
+]);
 select indexes_are('session', array[
     'session_pkey'
 ]);
@@ -367,6 +428,15 @@ select indexes_are('snapshot', array[
     'snapshot_pkey',
     'snapshot_not_deprecated_with_readme_idx'
 ]);
+select indexes_are('snapshot_dependency', array[
+    'snapshot_dependency_package_id_version_idx',
+    'snapshot_dependency_dependency_package_id_idx',
+    'snapshot_dependency_name_idx'
+]);
+select indexes_are('snapshot_image', array[
+    'snapshot_image_package_id_version_idx',
+    'snapshot_image_repository_idx'
+]);
 select indexes_are('subscription', array[
     'subscription_pkey',
     'subscription_package_id_idx'
@@ -402,6 +472,14 @@ select has_function('delete_api_key');
 select has_function('get_api_key');
 select has_function('get_user_api_keys');
 select has_function('update_api_key');
+-- Changes
+select has_function('get_change_data');
+select has_function('get_changes');
+select has_function('get_changes_export');
+select has_function('prune_changes');
+select has_function('register_package_change');
+select has_function('register_repository_change');
+select has_function('register_snapshot_change');
 -- Authz
 select has_function('notify_authorization_policies_updates');
 -- Events
@@ -437,7 +515,10 @@ select has_function('get_harbor_replication_dump');
 select has_function('get_helm_exporter_dump');
 select has_function('get_package');
 select has_function('get_package_changelog');
+select has_function('get_package_dependencies');
+select has_function('get_package_dependents');
 select has_function('get_package_summary');
+select has_function('get_packages_by_image');
 select has_function('get_packages_starred_by_user');
 select has_function('get_package_stars');
 select has_function('get_package_views');
@@ -446,11 +527,14 @@ select has_function('get_production_usage');
 select has_function('get_random_packages');
 select has_function('get_snapshots_to_scan');
 select has_function('is_latest');
+select has_function('parse_image_ref');
 select has_function('register_package');
+select has_function('register_snapshot_dependencies');
 select has_function('search_packages');
 select has_function('search_packages_monocular');
 select has_function('semver_gt');
 select has_function('semver_gte');
+select has_function('semver_satisfies');
 select has_function('toggle_star');
 select has_function('update_snapshot_security_report');
 select has_function('unregister_package');
@@ -461,6 +545,7 @@ select has_function('get_repository_by_id');
 select has_function('get_repository_by_name');
 select has_function('get_repository_packages_digest');
 select has_function('get_repository_summary');
+select has_function('get_repository_tracking_runs');
 select has_function('search_repositories');
 select has_function('set_last_scanning_results');
 select has_function('set_last_tracking_results');
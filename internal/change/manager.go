@@ -0,0 +1,129 @@
+package change
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/util"
+	"github.com/satori/uuid"
+)
+
+const (
+	// Database queries
+	getChangesDBQ       = `select * from get_changes($1::jsonb, $2::int)`
+	getChangesExportDBQ = `select * from get_changes_export($1::jsonb, $2::int)`
+
+	// maxLimit represents the maximum number of entries that can be requested
+	// at once.
+	maxLimit = 1000
+)
+
+var (
+	// ErrCursorExpired indicates that the cursor provided is older than the
+	// changes retention period, so some of the changes registered after it may
+	// not be available anymore. Clients should start again from the export.
+	ErrCursorExpired = errors.New("cursor expired, please start again from /changes/export")
+
+	// errCursorExpiredDB represents the error returned from the database when
+	// the cursor provided is older than the most recent change pruned.
+	errCursorExpiredDB = errors.New("ERROR: cursor expired (SQLSTATE P0001)")
+)
+
+// changesCursor represents the key of the cursor used to get the changes
+// registered after it.
+type changesCursor struct {
+	TxID     int64 `json:"tx_id"`
+	ChangeID int64 `json:"change_id"`
+}
+
+// exportCursor represents the key of the cursor used to paginate the catalog
+// export.
+type exportCursor struct {
+	Kind  string             `json:"kind"`
+	After *exportCursorAfter `json:"after,omitempty"`
+	Since *changesCursor     `json:"since"`
+}
+
+// exportCursorAfter represents the last entity of the export page the cursor
+// was built from.
+type exportCursorAfter struct {
+	RepositoryID uuid.UUID `json:"repository_id,omitempty"`
+	PackageID    uuid.UUID `json:"package_id,omitempty"`
+	Version      string    `json:"version,omitempty"`
+}
+
+// Manager provides an API to access the changes registered in the database
+// as well as to export the catalog.
+type Manager struct {
+	db hub.DB
+}
+
+// NewManager creates a new Manager instance.
+func NewManager(db hub.DB) *Manager {
+	return &Manager{
+		db: db,
+	}
+}
+
+// Export returns a page of the catalog export as newline delimited json. The
+// export starts from the beginning when no cursor is provided.
+func (m *Manager) Export(ctx context.Context, cursor string, limit int) (*hub.ChangesExport, error) {
+	// Validate input
+	if limit <= 0 || limit > maxLimit {
+		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid limit (0 < l <= 1000)")
+	}
+	var c exportCursor
+	cursorKey, err := util.DecodeCursor(cursor, &c)
+	if err != nil {
+		return nil, err
+	}
+	if cursorKey != nil {
+		switch c.Kind {
+		case "repository", "package", "package_version", "security_report":
+		default:
+			return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid cursor")
+		}
+	}
+
+	// Get export page from database
+	var data, nextCursorKey, sinceCursorKey []byte
+	err = m.db.QueryRow(ctx, getChangesExportDBQ, cursorKey, limit).Scan(&data, &nextCursorKey, &sinceCursorKey)
+	if err != nil {
+		return nil, err
+	}
+	return &hub.ChangesExport{
+		Data:        data,
+		NextCursor:  util.EncodeCursor(nextCursorKey),
+		SinceCursor: util.EncodeCursor(sinceCursorKey),
+	}, nil
+}
+
+// GetJSON returns the changes registered after the cursor provided as a json
+// array, alongside the cursor that should be used to get the next ones. All
+// changes available are returned from the beginning when no cursor is
+// provided. Cursors older than the changes retention period are rejected.
+func (m *Manager) GetJSON(ctx context.Context, since string, limit int) (*hub.JSONQueryResult, error) {
+	// Validate input
+	if limit <= 0 || limit > maxLimit {
+		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid limit (0 < l <= 1000)")
+	}
+	sinceKey, err := util.DecodeCursor(since, &changesCursor{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Get changes from database
+	var dataJSON, nextCursorKey []byte
+	if err := m.db.QueryRow(ctx, getChangesDBQ, sinceKey, limit).Scan(&dataJSON, &nextCursorKey); err != nil {
+		if err.Error() == errCursorExpiredDB.Error() {
+			return nil, ErrCursorExpired
+		}
+		return nil, err
+	}
+	return &hub.JSONQueryResult{
+		Data:       dataJSON,
+		NextCursor: util.EncodeCursor(nextCursorKey),
+	}, nil
+}
@@ -0,0 +1,169 @@
+package change
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/artifacthub/hub/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []struct {
+			errMsg string
+			cursor string
+			limit  int
+		}{
+			{
+				"invalid limit",
+				"",
+				0,
+			},
+			{
+				"invalid limit",
+				"",
+				1001,
+			},
+			{
+				"invalid cursor",
+				"invalid!",
+				10,
+			},
+			{
+				"invalid cursor",
+				util.EncodeCursor([]byte(`{"kind": "other", "since": {"tx_id": 1, "change_id": 0}}`)),
+				10,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.errMsg, func(t *testing.T) {
+				t.Parallel()
+				m := NewManager(nil)
+				export, err := m.Export(ctx, tc.cursor, tc.limit)
+				assert.True(t, errors.Is(err, hub.ErrInvalidInput))
+				assert.Contains(t, err.Error(), tc.errMsg)
+				assert.Nil(t, export)
+			})
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getChangesExportDBQ, []byte(nil), 10).Return(nil, tests.ErrFakeDB)
+		m := NewManager(db)
+
+		export, err := m.Export(ctx, "", 10)
+		assert.Equal(t, tests.ErrFakeDB, err)
+		assert.Nil(t, export)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("export page returned successfully", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getChangesExportDBQ, []byte(`{"kind": "package", "since": {"tx_id": 1, "change_id": 0}}`), 10).Return([]interface{}{
+			[]byte("data"),
+			[]byte(`{"kind": "package_version"}`),
+			[]byte(`{"tx_id": 1, "change_id": 0}`),
+		}, nil)
+		m := NewManager(db)
+
+		export, err := m.Export(ctx, util.EncodeCursor([]byte(`{"kind": "package", "since": {"tx_id": 1, "change_id": 0}}`)), 10)
+		assert.NoError(t, err)
+		assert.Equal(t, &hub.ChangesExport{
+			Data:        []byte("data"),
+			NextCursor:  util.EncodeCursor([]byte(`{"kind": "package_version"}`)),
+			SinceCursor: util.EncodeCursor([]byte(`{"tx_id": 1, "change_id": 0}`)),
+		}, export)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetJSON(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []struct {
+			errMsg string
+			since  string
+			limit  int
+		}{
+			{
+				"invalid limit",
+				"",
+				0,
+			},
+			{
+				"invalid limit",
+				"",
+				1001,
+			},
+			{
+				"invalid cursor",
+				"invalid!",
+				10,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.errMsg, func(t *testing.T) {
+				t.Parallel()
+				m := NewManager(nil)
+				result, err := m.GetJSON(ctx, tc.since, tc.limit)
+				assert.True(t, errors.Is(err, hub.ErrInvalidInput))
+				assert.Contains(t, err.Error(), tc.errMsg)
+				assert.Nil(t, result)
+			})
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getChangesDBQ, []byte(nil), 10).Return(nil, tests.ErrFakeDB)
+		m := NewManager(db)
+
+		result, err := m.GetJSON(ctx, "", 10)
+		assert.Equal(t, tests.ErrFakeDB, err)
+		assert.Nil(t, result)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("cursor expired", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getChangesDBQ, []byte(`{"tx_id": 1, "change_id": 2}`), 10).Return(nil, errCursorExpiredDB)
+		m := NewManager(db)
+
+		result, err := m.GetJSON(ctx, util.EncodeCursor([]byte(`{"tx_id": 1, "change_id": 2}`)), 10)
+		assert.Equal(t, ErrCursorExpired, err)
+		assert.Nil(t, result)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("changes returned successfully", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getChangesDBQ, []byte(`{"tx_id": 1, "change_id": 2}`), 10).Return([]interface{}{
+			[]byte("dataJSON"),
+			[]byte(`{"tx_id": 2, "change_id": 5}`),
+		}, nil)
+		m := NewManager(db)
+
+		result, err := m.GetJSON(ctx, util.EncodeCursor([]byte(`{"tx_id": 1, "change_id": 2}`)), 10)
+		assert.NoError(t, err)
+		assert.Equal(t, &hub.JSONQueryResult{
+			Data:       []byte("dataJSON"),
+			NextCursor: util.EncodeCursor([]byte(`{"tx_id": 2, "change_id": 5}`)),
+		}, result)
+		db.AssertExpectations(t)
+	})
+}
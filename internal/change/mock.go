@@ -0,0 +1,27 @@
+package change
+
+import (
+	"context"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/mock"
+)
+
+// ManagerMock is a mock implementation of the ChangeManager interface.
+type ManagerMock struct {
+	mock.Mock
+}
+
+// Export implements the ChangeManager interface.
+func (m *ManagerMock) Export(ctx context.Context, cursor string, limit int) (*hub.ChangesExport, error) {
+	args := m.Called(ctx, cursor, limit)
+	data, _ := args.Get(0).(*hub.ChangesExport)
+	return data, args.Error(1)
+}
+
+// GetJSON implements the ChangeManager interface.
+func (m *ManagerMock) GetJSON(ctx context.Context, since string, limit int) (*hub.JSONQueryResult, error) {
+	args := m.Called(ctx, since, limit)
+	data, _ := args.Get(0).(*hub.JSONQueryResult)
+	return data, args.Error(1)
+}
@@ -0,0 +1,86 @@
+package change
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// Database queries
+	pruneChangesDBQ = `select prune_changes($1::bigint, $2::timestamptz)`
+
+	// defaultRetention represents how long changes will be kept in the
+	// database before being pruned.
+	defaultRetention = 30 * 24 * time.Hour
+
+	// defaultPruneFrequency represents how often changes older than the
+	// retention period will be pruned.
+	defaultPruneFrequency = 1 * time.Hour
+)
+
+// Pruner periodically deletes from the database the changes older than the
+// retention period. Clients whose cursors are older than that are expected to
+// start again from the catalog export.
+type Pruner struct {
+	db             hub.DB
+	retention      time.Duration
+	pruneFrequency time.Duration
+}
+
+// NewPruner creates a new Pruner instance.
+func NewPruner(db hub.DB, opts ...func(p *Pruner)) *Pruner {
+	p := &Pruner{
+		db:             db,
+		retention:      defaultRetention,
+		pruneFrequency: defaultPruneFrequency,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// WithRetention allows configuring how long changes are kept. Non positive
+// values are ignored.
+func WithRetention(d time.Duration) func(p *Pruner) {
+	return func(p *Pruner) {
+		if d > 0 {
+			p.retention = d
+		}
+	}
+}
+
+// WithPruneFrequency allows configuring the pruner frequency.
+func WithPruneFrequency(d time.Duration) func(p *Pruner) {
+	return func(p *Pruner) {
+		p.pruneFrequency = d
+	}
+}
+
+// Run handles the periodic pruning of the changes older than the retention
+// period. It'll keep running until the context provided is done.
+func (p *Pruner) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for {
+		select {
+		case <-time.After(p.pruneFrequency):
+			if err := p.prune(ctx); err != nil {
+				log.Error().Err(err).Msg("error pruning changes")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// prune deletes the changes older than the retention period.
+func (p *Pruner) prune(ctx context.Context) error {
+	_, err := p.db.Exec(ctx, pruneChangesDBQ, util.DBLockKeyPruneChanges, time.Now().Add(-p.retention))
+	return err
+}
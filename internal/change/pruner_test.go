@@ -0,0 +1,65 @@
+package change
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/artifacthub/hub/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPruner(t *testing.T) {
+	t.Run("custom retention and prune frequency", func(t *testing.T) {
+		t.Parallel()
+		p := NewPruner(&tests.DBMock{}, WithRetention(24*time.Hour), WithPruneFrequency(2*time.Second))
+		assert.Equal(t, 24*time.Hour, p.retention)
+		assert.Equal(t, 2*time.Second, p.pruneFrequency)
+	})
+
+	t.Run("non positive retention ignored", func(t *testing.T) {
+		t.Parallel()
+		p := NewPruner(&tests.DBMock{}, WithRetention(0))
+		assert.Equal(t, defaultRetention, p.retention)
+	})
+
+	t.Run("ctx cancelled before pruning, nothing pruned", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		ctx, cancel := context.WithCancel(context.Background())
+		var wg sync.WaitGroup
+
+		p := NewPruner(db)
+		wg.Add(1)
+		go p.Run(ctx, &wg)
+		cancel()
+		wg.Wait()
+		db.AssertExpectations(t)
+	})
+
+	t.Run("changes pruned by timer", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		var once sync.Once
+		pruned := make(chan struct{})
+		retention := 24 * time.Hour
+		db.On("Exec", mock.Anything, pruneChangesDBQ, util.DBLockKeyPruneChanges, mock.MatchedBy(func(before time.Time) bool {
+			return time.Since(before) >= retention && time.Since(before) < retention+time.Minute
+		})).Run(func(args mock.Arguments) {
+			once.Do(func() { close(pruned) })
+		}).Return(nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		var wg sync.WaitGroup
+
+		p := NewPruner(db, WithRetention(retention), WithPruneFrequency(10*time.Millisecond))
+		wg.Add(1)
+		go p.Run(ctx, &wg)
+		<-pruned
+		cancel()
+		wg.Wait()
+		db.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,100 @@
+package change
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/artifacthub/hub/internal/change"
+	"github.com/artifacthub/hub/internal/handlers/helpers"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// ChangesSince represents a header used to provide the cursor that can be
+	// used to get the changes registered since the export was started.
+	ChangesSince = "Changes-Since"
+
+	defaultLimit = 100
+)
+
+// Handlers represents a group of http handlers in charge of handling changes
+// operations.
+type Handlers struct {
+	changeManager hub.ChangeManager
+	logger        zerolog.Logger
+}
+
+// NewHandlers creates a new Handlers instance.
+func NewHandlers(changeManager hub.ChangeManager) *Handlers {
+	return &Handlers{
+		changeManager: changeManager,
+		logger:        log.With().Str("handlers", "change").Logger(),
+	}
+}
+
+// Export is an http handler that returns a page of the catalog export as
+// newline delimited json.
+func (h *Handlers) Export(w http.ResponseWriter, r *http.Request) {
+	limit, err := getLimit(r.URL.Query())
+	if err != nil {
+		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "Export").Msg("invalid query")
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	export, err := h.changeManager.Export(r.Context(), r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "Export").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	if export.NextCursor != "" {
+		w.Header().Set(helpers.PaginationNextCursor, export.NextCursor)
+	}
+	w.Header().Set(ChangesSince, export.SinceCursor)
+	w.Header().Set("Cache-Control", helpers.BuildCacheControlHeader(0))
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	_, _ = w.Write(export.Data)
+}
+
+// Get is an http handler that returns the changes registered after the cursor
+// provided. Expired cursors are rejected with a 410 Gone status code.
+func (h *Handlers) Get(w http.ResponseWriter, r *http.Request) {
+	limit, err := getLimit(r.URL.Query())
+	if err != nil {
+		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "Get").Msg("invalid query")
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	result, err := h.changeManager.GetJSON(r.Context(), r.URL.Query().Get("since"), limit)
+	if err != nil {
+		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "Get").Send()
+		if errors.Is(err, change.ErrCursorExpired) {
+			helpers.RenderErrorWithCodeJSON(w, err, http.StatusGone)
+		} else {
+			helpers.RenderErrorJSON(w, err)
+		}
+		return
+	}
+	if result.NextCursor != "" {
+		w.Header().Set(helpers.PaginationNextCursor, result.NextCursor)
+	}
+	helpers.RenderJSON(w, result.Data, 0, http.StatusOK)
+}
+
+// getLimit extracts the limit from the query string values provided, using
+// the default one when it is not present.
+func getLimit(qs url.Values) (int, error) {
+	if qs.Get("limit") == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.Atoi(qs.Get("limit"))
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid limit: %s", hub.ErrInvalidInput, qs.Get("limit"))
+	}
+	return limit, nil
+}
@@ -0,0 +1,187 @@
+package change
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/change"
+	"github.com/artifacthub/hub/internal/handlers/helpers"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	os.Exit(m.Run())
+}
+
+func TestExport(t *testing.T) {
+	t.Run("invalid limit", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?limit=a", nil)
+
+		hw := newHandlersWrapper()
+		hw.h.Export(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		hw.cm.AssertExpectations(t)
+	})
+
+	t.Run("error exporting catalog", func(t *testing.T) {
+		testCases := []struct {
+			err                error
+			expectedStatusCode int
+		}{
+			{
+				hub.ErrInvalidInput,
+				http.StatusBadRequest,
+			},
+			{
+				tests.ErrFakeDB,
+				http.StatusInternalServerError,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.err.Error(), func(t *testing.T) {
+				t.Parallel()
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/?cursor=cursor1", nil)
+
+				hw := newHandlersWrapper()
+				hw.cm.On("Export", r.Context(), "cursor1", defaultLimit).Return(nil, tc.err)
+				hw.h.Export(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+				hw.cm.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("export page returned successfully", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?cursor=cursor1&limit=10", nil)
+
+		hw := newHandlersWrapper()
+		hw.cm.On("Export", r.Context(), "cursor1", 10).Return(&hub.ChangesExport{
+			Data:        []byte("record1\nrecord2"),
+			NextCursor:  "cursor2",
+			SinceCursor: "since",
+		}, nil)
+		hw.h.Export(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/x-ndjson", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(0), h.Get("Cache-Control"))
+		assert.Equal(t, "cursor2", h.Get(helpers.PaginationNextCursor))
+		assert.Equal(t, "since", h.Get(ChangesSince))
+		assert.Equal(t, []byte("record1\nrecord2"), data)
+		hw.cm.AssertExpectations(t)
+	})
+}
+
+func TestGet(t *testing.T) {
+	t.Run("invalid limit", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?limit=a", nil)
+
+		hw := newHandlersWrapper()
+		hw.h.Get(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		hw.cm.AssertExpectations(t)
+	})
+
+	t.Run("error getting changes", func(t *testing.T) {
+		testCases := []struct {
+			err                error
+			expectedStatusCode int
+		}{
+			{
+				hub.ErrInvalidInput,
+				http.StatusBadRequest,
+			},
+			{
+				change.ErrCursorExpired,
+				http.StatusGone,
+			},
+			{
+				tests.ErrFakeDB,
+				http.StatusInternalServerError,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.err.Error(), func(t *testing.T) {
+				t.Parallel()
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/?since=cursor1", nil)
+
+				hw := newHandlersWrapper()
+				hw.cm.On("GetJSON", r.Context(), "cursor1", defaultLimit).Return(nil, tc.err)
+				hw.h.Get(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+				hw.cm.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("changes returned successfully", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?since=cursor1&limit=10", nil)
+
+		hw := newHandlersWrapper()
+		hw.cm.On("GetJSON", r.Context(), "cursor1", 10).Return(&hub.JSONQueryResult{
+			Data:       []byte("dataJSON"),
+			NextCursor: "cursor2",
+		}, nil)
+		hw.h.Get(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(0), h.Get("Cache-Control"))
+		assert.Equal(t, "cursor2", h.Get(helpers.PaginationNextCursor))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.cm.AssertExpectations(t)
+	})
+}
+
+type handlersWrapper struct {
+	cm *change.ManagerMock
+	h  *Handlers
+}
+
+func newHandlersWrapper() *handlersWrapper {
+	cm := &change.ManagerMock{}
+
+	return &handlersWrapper{
+		cm: cm,
+		h:  NewHandlers(cm),
+	}
+}
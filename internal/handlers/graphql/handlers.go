@@ -0,0 +1,171 @@
+package graphql
+
+import (
+	"context"
+	_ "embed" // Used by the GraphQL schema
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/artifacthub/hub/internal/handlers/helpers"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxBodySize represents the maximum size of the body of the GraphQL
+	// requests.
+	maxBodySize = 1 << 20
+
+	// maxQueryCost represents the maximum cost of a GraphQL query. Each
+	// operation executed to resolve the query (i.e. a database query) costs
+	// one unit.
+	maxQueryCost = 250
+
+	// maxQueryDepth represents the maximum depth of a GraphQL query.
+	maxQueryDepth = 8
+
+	// maxQueryLength represents the maximum length of a GraphQL query.
+	maxQueryLength = 10000
+)
+
+var (
+	// errComplexityLimitExceeded indicates that the cost of the query exceeds
+	// the maximum allowed.
+	errComplexityLimitExceeded = errors.New("query complexity limit exceeded")
+
+	// errInternal represents an unexpected error resolving a query. Details
+	// about the error are logged, but not returned to the requester.
+	errInternal = errors.New("internal error")
+
+	// schemaSDL represents the GraphQL schema definition.
+	//go:embed schema.graphql
+	schemaSDL string
+)
+
+// costKey represents the key used for the query cost budget in the context.
+type costKey struct{}
+
+// request represents a GraphQL request.
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Handlers represents a group of http handlers in charge of handling GraphQL
+// requests.
+type Handlers struct {
+	pkgManager  hub.PackageManager
+	repoManager hub.RepositoryManager
+	orgManager  hub.OrganizationManager
+	schema      *graphql.Schema
+	logger      zerolog.Logger
+}
+
+// NewHandlers creates a new Handlers instance.
+func NewHandlers(
+	pkgManager hub.PackageManager,
+	repoManager hub.RepositoryManager,
+	orgManager hub.OrganizationManager,
+) *Handlers {
+	h := &Handlers{
+		pkgManager:  pkgManager,
+		repoManager: repoManager,
+		orgManager:  orgManager,
+		logger:      log.With().Str("handlers", "graphql").Logger(),
+	}
+	h.schema = graphql.MustParseSchema(
+		schemaSDL,
+		&queryResolver{h: h},
+		graphql.MaxDepth(maxQueryDepth),
+	)
+	return h
+}
+
+// Query is an http handler that executes the GraphQL query provided. Queries
+// can be provided in the query string (GET) or in the request body (POST).
+func (h *Handlers) Query(w http.ResponseWriter, r *http.Request) {
+	// Extract request
+	var req request
+	if r.Method == http.MethodGet {
+		req.Query = r.FormValue("query")
+		req.OperationName = r.FormValue("operationName")
+		if variables := r.FormValue("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				err = fmt.Errorf("%w: invalid variables", hub.ErrInvalidInput)
+				h.logger.Error().Err(err).Str("method", "Query").Send()
+				helpers.RenderErrorJSON(w, err)
+				return
+			}
+		}
+	} else {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.logger.Error().Err(err).Str("method", "Query").Msg(hub.ErrInvalidInput.Error())
+			helpers.RenderErrorJSON(w, hub.ErrInvalidInput)
+			return
+		}
+	}
+	if req.Query == "" {
+		err := fmt.Errorf("%w: query not provided", hub.ErrInvalidInput)
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	if len(req.Query) > maxQueryLength {
+		err := fmt.Errorf("%w: query too long (max: %d)", hub.ErrInvalidInput, maxQueryLength)
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+
+	// Execute query
+	budget := int64(maxQueryCost)
+	ctx := context.WithValue(r.Context(), costKey{}, &budget)
+	resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	dataJSON, err := json.Marshal(resp)
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "Query").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+
+	// Responses to authenticated requests or including errors are not cached
+	var cacheMaxAge = helpers.DefaultAPICacheMaxAge
+	if r.Method != http.MethodGet || r.Context().Value(hub.UserIDKey) != nil || len(resp.Errors) > 0 {
+		cacheMaxAge = 0
+	}
+	helpers.RenderJSON(w, dataJSON, cacheMaxAge, http.StatusOK)
+}
+
+// charge charges the cost provided to the query budget available in the
+// context, returning an error if the budget has been exhausted.
+func charge(ctx context.Context, cost int64) error {
+	budget, ok := ctx.Value(costKey{}).(*int64)
+	if !ok {
+		return nil
+	}
+	if atomic.AddInt64(budget, -cost) < 0 {
+		return errComplexityLimitExceeded
+	}
+	return nil
+}
+
+// handleError logs the error provided and returns the error that should be
+// sent to the requester.
+func (h *Handlers) handleError(err error, resolver string) error {
+	switch {
+	case errors.Is(err, hub.ErrInvalidInput),
+		errors.Is(err, hub.ErrInsufficientPrivilege),
+		errors.Is(err, hub.ErrNotFound),
+		errors.Is(err, errComplexityLimitExceeded),
+		errors.Is(err, errUnauthenticated):
+		return err
+	default:
+		h.logger.Error().Err(err).Str("resolver", resolver).Send()
+		return errInternal
+	}
+}
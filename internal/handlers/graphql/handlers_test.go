@@ -0,0 +1,401 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/handlers/helpers"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/org"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/artifacthub/hub/internal/repo"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	os.Exit(m.Run())
+}
+
+func TestQuery(t *testing.T) {
+	pkgJSON := []byte(`{
+		"package_id": "00000000-0000-0000-0000-000000000001",
+		"name": "pkg1",
+		"normalized_name": "pkg1",
+		"version": "1.0.0",
+		"keywords": ["kw1"],
+		"available_versions": [{"version": "1.0.0", "ts": 1}],
+		"security_report_summary": {"critical": 1, "high": 2},
+		"production_organizations_count": 3,
+		"repository": {
+			"repository_id": "00000000-0000-0000-0000-000000000002",
+			"name": "repo1",
+			"kind": 0,
+			"url": "https://repo1.url",
+			"auth_user": "user",
+			"auth_pass": "pass",
+			"organization_name": "org1",
+			"organization_display_name": "Organization 1"
+		}
+	}`)
+	searchJSON := func(n int) []byte {
+		packages := make([]string, 0, n)
+		for i := 0; i < n; i++ {
+			packages = append(packages, fmt.Sprintf(`{
+				"package_id": "00000000-0000-0000-0000-%012d",
+				"name": "pkg%d",
+				"version": "1.0.0",
+				"repository": {"name": "repo1"}
+			}`, i, i))
+		}
+		return []byte(fmt.Sprintf(`{"packages": [%s]}`, strings.Join(packages, ",")))
+	}
+
+	t.Run("invalid request body", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader("{"))
+
+		hw := newHandlersWrapper()
+		hw.h.Query(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("invalid variables", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?query={__typename}&variables=invalid", nil)
+
+		hw := newHandlersWrapper()
+		hw.h.Query(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("query not provided", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader("{}"))
+
+		hw := newHandlersWrapper()
+		hw.h.Query(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("query too long", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		query := "{" + strings.Repeat(" ", maxQueryLength) + "__typename}"
+		r, _ := http.NewRequest("GET", "/?query="+url.QueryEscape(query), nil)
+
+		hw := newHandlersWrapper()
+		hw.h.Query(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("query depth exceeded", func(t *testing.T) {
+		t.Parallel()
+		query := `{ organization(name: "org1") { packages { items { repository { organization {
+			repositories { items { packages { items { name } } } } } } } } } }`
+		hw := newHandlersWrapper()
+		resp := hw.post(t, query, nil)
+
+		assert.Nil(t, resp.Data)
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0].Message, "exceeds max depth")
+	})
+
+	t.Run("get package succeeded", func(t *testing.T) {
+		t.Parallel()
+		query := `query($id: ID) {
+			package(id: $id) {
+				name
+				keywords
+				versions { version }
+				securityReportSummary { critical high low }
+				productionOrganizationsCount
+				stars { count starredByUser }
+				repository { name url organization { name displayName } }
+			}
+		}`
+		vars := map[string]interface{}{"id": "00000000-0000-0000-0000-000000000001"}
+
+		hw := newHandlersWrapper()
+		hw.pm.On("GetJSON", mock.Anything, &hub.GetPackageInput{
+			PackageID: "00000000-0000-0000-0000-000000000001",
+		}).Return(pkgJSON, nil)
+		hw.pm.On("GetStarsJSON", mock.Anything, "00000000-0000-0000-0000-000000000001").
+			Return([]byte(`{"stars": 4}`), nil)
+		resp := hw.post(t, query, vars)
+
+		assert.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{
+			"package": {
+				"name": "pkg1",
+				"keywords": ["kw1"],
+				"versions": [{"version": "1.0.0"}],
+				"securityReportSummary": {"critical": 1, "high": 2, "low": 0},
+				"productionOrganizationsCount": 3,
+				"stars": {"count": 4, "starredByUser": null},
+				"repository": {
+					"name": "repo1",
+					"url": "https://repo1.url",
+					"organization": {"name": "org1", "displayName": "Organization 1"}
+				}
+			}
+		}`, string(resp.Data))
+		hw.pm.AssertExpectations(t)
+	})
+
+	t.Run("package not found", func(t *testing.T) {
+		t.Parallel()
+		query := `{ package(repositoryName: "repo1", name: "pkg1") { name } }`
+
+		hw := newHandlersWrapper()
+		hw.pm.On("GetJSON", mock.Anything, &hub.GetPackageInput{
+			RepositoryName: "repo1",
+			PackageName:    "pkg1",
+		}).Return(nil, hub.ErrNotFound)
+		resp := hw.post(t, query, nil)
+
+		assert.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"package": null}`, string(resp.Data))
+		hw.pm.AssertExpectations(t)
+	})
+
+	t.Run("error getting package", func(t *testing.T) {
+		t.Parallel()
+		query := `{ package(repositoryName: "repo1", name: "pkg1") { name } }`
+
+		hw := newHandlersWrapper()
+		hw.pm.On("GetJSON", mock.Anything, mock.Anything).Return(nil, tests.ErrFakeDB)
+		resp := hw.post(t, query, nil)
+
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, errInternal.Error(), resp.Errors[0].Message)
+		hw.pm.AssertExpectations(t)
+	})
+
+	t.Run("search packages succeeded", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		query := `{ packages(query: "kw1", kinds: [0], limit: 1) { totalCount items { name keywords } } }`
+		r, _ := http.NewRequest("GET", "/?query="+url.QueryEscape(query), nil)
+
+		hw := newHandlersWrapper()
+		hw.pm.On("SearchJSON", mock.Anything, &hub.SearchPackageInput{
+			TSQueryWeb:      "kw1",
+			RepositoryKinds: []hub.RepositoryKind{hub.Helm},
+			Limit:           1,
+		}).Return(&hub.JSONQueryResult{Data: searchJSON(1), TotalCount: 2}, nil)
+		hw.pm.On("GetJSON", mock.Anything, &hub.GetPackageInput{
+			PackageID: "00000000-0000-0000-0000-000000000000",
+			Version:   "1.0.0",
+		}).Return(pkgJSON, nil)
+		hw.h.Query(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		assert.JSONEq(t, `{
+			"data": {
+				"packages": {
+					"totalCount": 2,
+					"items": [{"name": "pkg0", "keywords": ["kw1"]}]
+				}
+			}
+		}`, string(data))
+		hw.pm.AssertExpectations(t)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		t.Parallel()
+		query := `{ repositories(limit: 100) { totalCount } }`
+
+		hw := newHandlersWrapper()
+		resp := hw.post(t, query, nil)
+
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0].Message, "invalid limit")
+	})
+
+	t.Run("query complexity limit exceeded", func(t *testing.T) {
+		t.Parallel()
+		query := `{ repositories(limit: 10) { items { packages(limit: 60) { items { stars { count } } } } } }`
+
+		hw := newHandlersWrapper()
+		repositories := make([]*hub.Repository, 0, 10)
+		for i := 0; i < 10; i++ {
+			repositories = append(repositories, &hub.Repository{Name: fmt.Sprintf("repo%d", i)})
+		}
+		hw.rm.On("Search", mock.Anything, mock.Anything).
+			Return(&hub.SearchRepositoryResult{Repositories: repositories, TotalCount: 10}, nil)
+		hw.pm.On("SearchJSON", mock.Anything, mock.Anything).
+			Return(&hub.JSONQueryResult{Data: searchJSON(60), TotalCount: 60}, nil)
+		hw.pm.On("GetStarsJSON", mock.Anything, mock.Anything).Return([]byte(`{"stars": 1}`), nil)
+		resp := hw.post(t, query, nil)
+
+		require.NotEmpty(t, resp.Errors)
+		assert.Equal(t, errComplexityLimitExceeded.Error(), resp.Errors[0].Message)
+		assert.LessOrEqual(t, len(hw.rm.Calls)+len(hw.pm.Calls), maxQueryCost)
+	})
+
+	t.Run("production usage requires authentication", func(t *testing.T) {
+		t.Parallel()
+		query := `{ package(name: "pkg1", repositoryName: "repo1") { productionUsage { usedInProduction } } }`
+
+		hw := newHandlersWrapper()
+		hw.pm.On("GetJSON", mock.Anything, mock.Anything).Return(pkgJSON, nil)
+		resp := hw.post(t, query, nil)
+
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, errUnauthenticated.Error(), resp.Errors[0].Message)
+		hw.pm.AssertExpectations(t)
+	})
+
+	t.Run("production usage succeeded", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		query := `{ package(name: "pkg1", repositoryName: "repo1") {
+			productionUsage { organization { name displayName } usedInProduction }
+		} }`
+		body, _ := json.Marshal(map[string]string{"query": query})
+		r, _ := http.NewRequest("POST", "/", strings.NewReader(string(body)))
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("GetJSON", mock.Anything, mock.Anything).Return(pkgJSON, nil)
+		hw.pm.On("GetProductionUsageJSON", mock.Anything, "repo1", "pkg1").Return([]byte(`[
+			{"name": "org1", "display_name": "Organization 1", "used_in_production": true}
+		]`), nil)
+		hw.h.Query(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, helpers.BuildCacheControlHeader(0), h.Get("Cache-Control"))
+		assert.JSONEq(t, `{
+			"data": {
+				"package": {
+					"productionUsage": [
+						{"organization": {"name": "org1", "displayName": "Organization 1"}, "usedInProduction": true}
+					]
+				}
+			}
+		}`, string(data))
+		hw.pm.AssertExpectations(t)
+	})
+
+	t.Run("get organization succeeded", func(t *testing.T) {
+		t.Parallel()
+		query := `{ organization(name: "org1") { name homeUrl repositories(limit: 1) { items { name } } } }`
+
+		hw := newHandlersWrapper()
+		hw.om.On("GetJSON", mock.Anything, "org1").
+			Return([]byte(`{"name": "org1", "home_url": "https://org1.url"}`), nil)
+		hw.rm.On("Search", mock.Anything, &hub.SearchRepositoryInput{Orgs: []string{"org1"}, Limit: 1}).
+			Return(&hub.SearchRepositoryResult{
+				Repositories: []*hub.Repository{{Name: "repo1"}},
+				TotalCount:   1,
+			}, nil)
+		resp := hw.post(t, query, nil)
+
+		assert.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{
+			"organization": {
+				"name": "org1",
+				"homeUrl": "https://org1.url",
+				"repositories": {"items": [{"name": "repo1"}]}
+			}
+		}`, string(resp.Data))
+		hw.om.AssertExpectations(t)
+		hw.rm.AssertExpectations(t)
+	})
+
+	t.Run("get repository succeeded", func(t *testing.T) {
+		t.Parallel()
+		query := `{ repository(name: "repo1") { name kind userAlias } }`
+
+		hw := newHandlersWrapper()
+		hw.rm.On("GetByName", mock.Anything, "repo1", false).
+			Return(&hub.Repository{Name: "repo1", Kind: hub.OLM, UserAlias: "user1"}, nil)
+		resp := hw.post(t, query, nil)
+
+		assert.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"repository": {"name": "repo1", "kind": 3, "userAlias": "user1"}}`, string(resp.Data))
+		hw.rm.AssertExpectations(t)
+	})
+}
+
+// response represents a GraphQL response.
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type handlersWrapper struct {
+	pm *pkg.ManagerMock
+	rm *repo.ManagerMock
+	om *org.ManagerMock
+	h  *Handlers
+}
+
+func newHandlersWrapper() *handlersWrapper {
+	pm := &pkg.ManagerMock{}
+	rm := &repo.ManagerMock{}
+	om := &org.ManagerMock{}
+
+	return &handlersWrapper{
+		pm: pm,
+		rm: rm,
+		om: om,
+		h:  NewHandlers(pm, rm, om),
+	}
+}
+
+// post sends the query provided to the GraphQL handler and returns the
+// response.
+func (hw *handlersWrapper) post(t *testing.T, query string, vars map[string]interface{}) *response {
+	t.Helper()
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": vars})
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/", strings.NewReader(string(body)))
+	hw.h.Query(w, r)
+	res := w.Result()
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var resp *response
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+	return resp
+}
@@ -0,0 +1,675 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// maxLimit represents the maximum number of items that can be requested in a
+// single page.
+const maxLimit = 60
+
+// errUnauthenticated indicates that the field requested requires an
+// authenticated request.
+var errUnauthenticated = errors.New("authentication required")
+
+// paginationArgs represents the arguments used to request a page of items.
+type paginationArgs struct {
+	Limit  int32
+	Offset int32
+}
+
+// validate checks the pagination arguments are valid.
+func (a paginationArgs) validate() error {
+	if a.Limit <= 0 || a.Limit > maxLimit {
+		return fmt.Errorf("%w: invalid limit (0 < l <= %d)", hub.ErrInvalidInput, maxLimit)
+	}
+	if a.Offset < 0 {
+		return fmt.Errorf("%w: invalid offset (o >= 0)", hub.ErrInvalidInput)
+	}
+	return nil
+}
+
+// queryResolver resolves the root query fields.
+type queryResolver struct {
+	h *Handlers
+}
+
+// Organization resolves the organization field.
+func (r *queryResolver) Organization(ctx context.Context, args struct{ Name string }) (*organizationResolver, error) {
+	o := newOrganizationResolver(r.h, &hub.Organization{Name: args.Name})
+	if _, err := o.load(ctx); err != nil {
+		if errors.Is(err, hub.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return o, nil
+}
+
+// Package resolves the package field.
+func (r *queryResolver) Package(ctx context.Context, args struct {
+	ID             *graphql.ID
+	RepositoryName *string
+	Name           *string
+	Version        *string
+}) (*packageResolver, error) {
+	input := &hub.GetPackageInput{}
+	if args.ID != nil {
+		input.PackageID = string(*args.ID)
+	}
+	if args.RepositoryName != nil {
+		input.RepositoryName = *args.RepositoryName
+	}
+	if args.Name != nil {
+		input.PackageName = *args.Name
+	}
+	if args.Version != nil {
+		input.Version = *args.Version
+	}
+	p, err := r.h.getPackage(ctx, input)
+	if err != nil {
+		if errors.Is(err, hub.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, r.h.handleError(err, "Package")
+	}
+	return newPackageResolver(r.h, p, true), nil
+}
+
+// Packages resolves the packages field.
+func (r *queryResolver) Packages(ctx context.Context, args struct {
+	Query             *string
+	Kinds             *[]int32
+	Orgs              *[]string
+	Repositories      *[]string
+	Official          bool
+	VerifiedPublisher bool
+	Deprecated        bool
+	Sort              *string
+	paginationArgs
+}) (*packagesPageResolver, error) {
+	input := &hub.SearchPackageInput{
+		Official:          args.Official,
+		VerifiedPublisher: args.VerifiedPublisher,
+		Deprecated:        args.Deprecated,
+	}
+	if args.Query != nil {
+		input.TSQueryWeb = *args.Query
+	}
+	if args.Kinds != nil {
+		for _, kind := range *args.Kinds {
+			input.RepositoryKinds = append(input.RepositoryKinds, hub.RepositoryKind(kind))
+		}
+	}
+	if args.Orgs != nil {
+		input.Orgs = *args.Orgs
+	}
+	if args.Repositories != nil {
+		input.Repositories = *args.Repositories
+	}
+	if args.Sort != nil {
+		input.Sort = *args.Sort
+	}
+	return r.h.searchPackages(ctx, input, args.paginationArgs)
+}
+
+// Repositories resolves the repositories field.
+func (r *queryResolver) Repositories(ctx context.Context, args struct {
+	Name  *string
+	Kinds *[]int32
+	Orgs  *[]string
+	Users *[]string
+	paginationArgs
+}) (*repositoriesPageResolver, error) {
+	input := &hub.SearchRepositoryInput{}
+	if args.Name != nil {
+		input.Name = *args.Name
+	}
+	if args.Kinds != nil {
+		for _, kind := range *args.Kinds {
+			input.Kinds = append(input.Kinds, hub.RepositoryKind(kind))
+		}
+	}
+	if args.Orgs != nil {
+		input.Orgs = *args.Orgs
+	}
+	if args.Users != nil {
+		input.Users = *args.Users
+	}
+	return r.h.searchRepositories(ctx, input, args.paginationArgs)
+}
+
+// Repository resolves the repository field.
+func (r *queryResolver) Repository(ctx context.Context, args struct{ Name string }) (*repositoryResolver, error) {
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	repo, err := r.h.repoManager.GetByName(ctx, args.Name, false)
+	if err != nil {
+		if errors.Is(err, hub.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, r.h.handleError(err, "Repository")
+	}
+	return &repositoryResolver{h: r.h, r: repo}, nil
+}
+
+// changeResolver resolves the fields of a package version's change.
+type changeResolver struct {
+	c *hub.Change
+}
+
+func (r *changeResolver) Kind() *string       { return optionalString(r.c.Kind) }
+func (r *changeResolver) Description() string { return r.c.Description }
+func (r *changeResolver) Links() []*linkResolver {
+	links := make([]*linkResolver, 0, len(r.c.Links))
+	for _, l := range r.c.Links {
+		links = append(links, &linkResolver{l})
+	}
+	return links
+}
+
+// changelogEntryResolver resolves the fields of a package's changelog entry.
+type changelogEntryResolver struct {
+	vc *hub.VersionChanges
+}
+
+func (r *changelogEntryResolver) Version() string               { return r.vc.Version }
+func (r *changelogEntryResolver) TS() int32                     { return int32(r.vc.TS) }
+func (r *changelogEntryResolver) ContainsSecurityUpdates() bool { return r.vc.ContainsSecurityUpdates }
+func (r *changelogEntryResolver) Prerelease() bool              { return r.vc.Prerelease }
+func (r *changelogEntryResolver) Changes() []*changeResolver {
+	changes := make([]*changeResolver, 0, len(r.vc.Changes))
+	for _, c := range r.vc.Changes {
+		changes = append(changes, &changeResolver{c})
+	}
+	return changes
+}
+
+// containerImageResolver resolves the fields of a package's container image.
+type containerImageResolver struct {
+	i *hub.ContainerImage
+}
+
+func (r *containerImageResolver) Name() *string     { return optionalString(r.i.Name) }
+func (r *containerImageResolver) Image() string     { return r.i.Image }
+func (r *containerImageResolver) Whitelisted() bool { return r.i.Whitelisted }
+
+// linkResolver resolves the fields of a link.
+type linkResolver struct {
+	l *hub.Link
+}
+
+func (r *linkResolver) Name() string { return r.l.Name }
+func (r *linkResolver) URL() string  { return r.l.URL }
+
+// organizationResolver resolves the fields of an organization. Some fields
+// require loading the organization details, which is done only once and only
+// when any of them is requested.
+type organizationResolver struct {
+	h    *Handlers
+	o    *hub.Organization
+	once sync.Once
+	full *hub.Organization
+	err  error
+}
+
+// newOrganizationResolver creates a new organizationResolver instance.
+func newOrganizationResolver(h *Handlers, o *hub.Organization) *organizationResolver {
+	return &organizationResolver{h: h, o: o}
+}
+
+// load loads the organization details.
+func (r *organizationResolver) load(ctx context.Context) (*hub.Organization, error) {
+	r.once.Do(func() {
+		if err := charge(ctx, 1); err != nil {
+			r.err = err
+			return
+		}
+		dataJSON, err := r.h.orgManager.GetJSON(ctx, r.o.Name)
+		if err != nil {
+			r.err = r.h.handleError(err, "Organization")
+			return
+		}
+		o := &hub.Organization{}
+		if err := json.Unmarshal(dataJSON, &o); err != nil {
+			r.err = r.h.handleError(err, "Organization")
+			return
+		}
+		r.full = o
+	})
+	return r.full, r.err
+}
+
+func (r *organizationResolver) Name() string { return r.o.Name }
+
+func (r *organizationResolver) DisplayName(ctx context.Context) (*string, error) {
+	if r.o.DisplayName != "" {
+		return &r.o.DisplayName, nil
+	}
+	o, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return optionalString(o.DisplayName), nil
+}
+
+func (r *organizationResolver) Description(ctx context.Context) (*string, error) {
+	o, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return optionalString(o.Description), nil
+}
+
+func (r *organizationResolver) HomeURL(ctx context.Context) (*string, error) {
+	o, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return optionalString(o.HomeURL), nil
+}
+
+func (r *organizationResolver) LogoImageID(ctx context.Context) (*string, error) {
+	o, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return optionalString(o.LogoImageID), nil
+}
+
+func (r *organizationResolver) Packages(ctx context.Context, args paginationArgs) (*packagesPageResolver, error) {
+	input := &hub.SearchPackageInput{Orgs: []string{r.o.Name}}
+	return r.h.searchPackages(ctx, input, args)
+}
+
+func (r *organizationResolver) Repositories(
+	ctx context.Context,
+	args paginationArgs,
+) (*repositoriesPageResolver, error) {
+	input := &hub.SearchRepositoryInput{Orgs: []string{r.o.Name}}
+	return r.h.searchRepositories(ctx, input, args)
+}
+
+// packageData represents the package data returned by the package manager,
+// including some fields not available in hub.Package.
+type packageData struct {
+	hub.Package
+	ProductionOrganizationsCount int `json:"production_organizations_count"`
+}
+
+// packageResolver resolves the fields of a package. Packages returned by
+// searches only contain a summary of the package, so some fields require
+// loading the package details, which is done only once and only when any of
+// them is requested.
+type packageResolver struct {
+	h    *Handlers
+	p    *packageData
+	once sync.Once
+	full *packageData
+	err  error
+}
+
+// newPackageResolver creates a new packageResolver instance.
+func newPackageResolver(h *Handlers, p *packageData, full bool) *packageResolver {
+	r := &packageResolver{h: h, p: p}
+	if full {
+		// Package details already available, no need to load them
+		r.full = p
+		r.once.Do(func() {})
+	}
+	return r
+}
+
+// load loads the package details.
+func (r *packageResolver) load(ctx context.Context) (*packageData, error) {
+	r.once.Do(func() {
+		r.full, r.err = r.h.getPackage(ctx, &hub.GetPackageInput{
+			PackageID: r.p.PackageID,
+			Version:   r.p.Version,
+		})
+		if r.err != nil {
+			r.err = r.h.handleError(r.err, "Package")
+		}
+	})
+	return r.full, r.err
+}
+
+func (r *packageResolver) ID() graphql.ID         { return graphql.ID(r.p.PackageID) }
+func (r *packageResolver) Name() string           { return r.p.Name }
+func (r *packageResolver) NormalizedName() string { return r.p.NormalizedName }
+func (r *packageResolver) DisplayName() *string   { return optionalString(r.p.DisplayName) }
+func (r *packageResolver) Description() *string   { return optionalString(r.p.Description) }
+func (r *packageResolver) LogoImageID() *string   { return optionalString(r.p.LogoImageID) }
+func (r *packageResolver) Version() string        { return r.p.Version }
+func (r *packageResolver) AppVersion() *string    { return optionalString(r.p.AppVersion) }
+func (r *packageResolver) License() *string       { return optionalString(r.p.License) }
+func (r *packageResolver) Official() bool         { return r.p.Official }
+func (r *packageResolver) Deprecated() bool       { return r.p.Deprecated }
+func (r *packageResolver) Signed() bool           { return r.p.Signed }
+func (r *packageResolver) TS() int32              { return int32(r.p.TS) }
+func (r *packageResolver) ProductionOrganizationsCount() int32 {
+	return int32(r.p.ProductionOrganizationsCount)
+}
+
+func (r *packageResolver) HomeURL(ctx context.Context) (*string, error) {
+	p, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return optionalString(p.HomeURL), nil
+}
+
+func (r *packageResolver) Keywords(ctx context.Context) ([]string, error) {
+	p, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if p.Keywords == nil {
+		return []string{}, nil
+	}
+	return p.Keywords, nil
+}
+
+func (r *packageResolver) ContainsSecurityUpdates(ctx context.Context) (bool, error) {
+	p, err := r.load(ctx)
+	if err != nil {
+		return false, err
+	}
+	return p.ContainsSecurityUpdates, nil
+}
+
+func (r *packageResolver) Prerelease(ctx context.Context) (bool, error) {
+	p, err := r.load(ctx)
+	if err != nil {
+		return false, err
+	}
+	return p.Prerelease, nil
+}
+
+func (r *packageResolver) Repository() *repositoryResolver {
+	return &repositoryResolver{h: r.h, r: r.p.Repository}
+}
+
+func (r *packageResolver) Versions(ctx context.Context) ([]*packageVersionResolver, error) {
+	p, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]*packageVersionResolver, 0, len(p.AvailableVersions))
+	for _, v := range p.AvailableVersions {
+		versions = append(versions, &packageVersionResolver{v})
+	}
+	return versions, nil
+}
+
+func (r *packageResolver) Changelog(ctx context.Context) ([]*changelogEntryResolver, error) {
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	changelog, err := r.h.pkgManager.GetChangelog(ctx, r.p.PackageID)
+	if err != nil {
+		return nil, r.h.handleError(err, "Changelog")
+	}
+	entries := make([]*changelogEntryResolver, 0, len(*changelog))
+	for _, vc := range *changelog {
+		entries = append(entries, &changelogEntryResolver{vc})
+	}
+	return entries, nil
+}
+
+func (r *packageResolver) ContainersImages(ctx context.Context) ([]*containerImageResolver, error) {
+	p, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	images := make([]*containerImageResolver, 0, len(p.ContainersImages))
+	for _, i := range p.ContainersImages {
+		images = append(images, &containerImageResolver{i})
+	}
+	return images, nil
+}
+
+func (r *packageResolver) SecurityReportSummary() *securityReportSummaryResolver {
+	if r.p.SecurityReportSummary == nil {
+		return nil
+	}
+	return &securityReportSummaryResolver{r.p.SecurityReportSummary}
+}
+
+func (r *packageResolver) Stars(ctx context.Context) (*packageStarsResolver, error) {
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	dataJSON, err := r.h.pkgManager.GetStarsJSON(ctx, r.p.PackageID)
+	if err != nil {
+		return nil, r.h.handleError(err, "Stars")
+	}
+	var stars struct {
+		Stars         int   `json:"stars"`
+		StarredByUser *bool `json:"starred_by_user"`
+	}
+	if err := json.Unmarshal(dataJSON, &stars); err != nil {
+		return nil, r.h.handleError(err, "Stars")
+	}
+	return &packageStarsResolver{count: stars.Stars, starredByUser: stars.StarredByUser}, nil
+}
+
+func (r *packageResolver) ProductionUsage(ctx context.Context) ([]*productionUsageResolver, error) {
+	if ctx.Value(hub.UserIDKey) == nil {
+		return nil, errUnauthenticated
+	}
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	dataJSON, err := r.h.pkgManager.GetProductionUsageJSON(ctx, r.p.Repository.Name, r.p.Name)
+	if err != nil {
+		return nil, r.h.handleError(err, "ProductionUsage")
+	}
+	var usage []*struct {
+		hub.Organization
+		UsedInProduction bool `json:"used_in_production"`
+	}
+	if err := json.Unmarshal(dataJSON, &usage); err != nil {
+		return nil, r.h.handleError(err, "ProductionUsage")
+	}
+	resolvers := make([]*productionUsageResolver, 0, len(usage))
+	for _, u := range usage {
+		resolvers = append(resolvers, &productionUsageResolver{
+			o:    newOrganizationResolver(r.h, &u.Organization),
+			used: u.UsedInProduction,
+		})
+	}
+	return resolvers, nil
+}
+
+// packageStarsResolver resolves the fields of a package's stars.
+type packageStarsResolver struct {
+	count         int
+	starredByUser *bool
+}
+
+func (r *packageStarsResolver) Count() int32         { return int32(r.count) }
+func (r *packageStarsResolver) StarredByUser() *bool { return r.starredByUser }
+
+// packageVersionResolver resolves the fields of a package's version.
+type packageVersionResolver struct {
+	v *hub.Version
+}
+
+func (r *packageVersionResolver) Version() string { return r.v.Version }
+func (r *packageVersionResolver) TS() int32       { return int32(r.v.TS) }
+
+// packagesPageResolver resolves the fields of a page of packages.
+type packagesPageResolver struct {
+	totalCount int
+	items      []*packageResolver
+}
+
+func (r *packagesPageResolver) TotalCount() int32         { return int32(r.totalCount) }
+func (r *packagesPageResolver) Items() []*packageResolver { return r.items }
+
+// productionUsageResolver resolves the fields of the production usage of a
+// package by one of the organizations the user belongs to.
+type productionUsageResolver struct {
+	o    *organizationResolver
+	used bool
+}
+
+func (r *productionUsageResolver) Organization() *organizationResolver { return r.o }
+func (r *productionUsageResolver) UsedInProduction() bool              { return r.used }
+
+// repositoriesPageResolver resolves the fields of a page of repositories.
+type repositoriesPageResolver struct {
+	totalCount int
+	items      []*repositoryResolver
+}
+
+func (r *repositoriesPageResolver) TotalCount() int32            { return int32(r.totalCount) }
+func (r *repositoriesPageResolver) Items() []*repositoryResolver { return r.items }
+
+// repositoryResolver resolves the fields of a repository. Credentials and
+// other sensitive fields of the repository are never exposed.
+type repositoryResolver struct {
+	h *Handlers
+	r *hub.Repository
+}
+
+func (r *repositoryResolver) ID() graphql.ID          { return graphql.ID(r.r.RepositoryID) }
+func (r *repositoryResolver) Name() string            { return r.r.Name }
+func (r *repositoryResolver) DisplayName() *string    { return optionalString(r.r.DisplayName) }
+func (r *repositoryResolver) URL() string             { return r.r.URL }
+func (r *repositoryResolver) Kind() int32             { return int32(r.r.Kind) }
+func (r *repositoryResolver) VerifiedPublisher() bool { return r.r.VerifiedPublisher }
+func (r *repositoryResolver) Official() bool          { return r.r.Official }
+func (r *repositoryResolver) UserAlias() *string      { return optionalString(r.r.UserAlias) }
+
+func (r *repositoryResolver) Organization() *organizationResolver {
+	if r.r.OrganizationName == "" {
+		return nil
+	}
+	return newOrganizationResolver(r.h, &hub.Organization{
+		Name:        r.r.OrganizationName,
+		DisplayName: r.r.OrganizationDisplayName,
+	})
+}
+
+func (r *repositoryResolver) Packages(ctx context.Context, args paginationArgs) (*packagesPageResolver, error) {
+	input := &hub.SearchPackageInput{Repositories: []string{r.r.Name}}
+	return r.h.searchPackages(ctx, input, args)
+}
+
+// securityReportSummaryResolver resolves the fields of a package's security
+// report summary.
+type securityReportSummaryResolver struct {
+	s *hub.SecurityReportSummary
+}
+
+func (r *securityReportSummaryResolver) Critical() int32 { return int32(r.s.Critical) }
+func (r *securityReportSummaryResolver) High() int32     { return int32(r.s.High) }
+func (r *securityReportSummaryResolver) Medium() int32   { return int32(r.s.Medium) }
+func (r *securityReportSummaryResolver) Low() int32      { return int32(r.s.Low) }
+func (r *securityReportSummaryResolver) Unknown() int32  { return int32(r.s.Unknown) }
+
+// getPackage returns the details of the package identified by the input
+// provided.
+func (h *Handlers) getPackage(ctx context.Context, input *hub.GetPackageInput) (*packageData, error) {
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	dataJSON, err := h.pkgManager.GetJSON(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	p := &packageData{}
+	if err := json.Unmarshal(dataJSON, &p); err != nil {
+		return nil, err
+	}
+	if p.Repository == nil {
+		p.Repository = &hub.Repository{}
+	}
+	return p, nil
+}
+
+// searchPackages searches packages using the input and pagination arguments
+// provided.
+func (h *Handlers) searchPackages(
+	ctx context.Context,
+	input *hub.SearchPackageInput,
+	args paginationArgs,
+) (*packagesPageResolver, error) {
+	if err := args.validate(); err != nil {
+		return nil, err
+	}
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	input.Limit = int(args.Limit)
+	input.Offset = int(args.Offset)
+	result, err := h.pkgManager.SearchJSON(ctx, input)
+	if err != nil {
+		return nil, h.handleError(err, "Packages")
+	}
+	var data struct {
+		Packages []*packageData `json:"packages"`
+	}
+	if err := json.Unmarshal(result.Data, &data); err != nil {
+		return nil, h.handleError(err, "Packages")
+	}
+	page := &packagesPageResolver{
+		totalCount: result.TotalCount,
+		items:      make([]*packageResolver, 0, len(data.Packages)),
+	}
+	for _, p := range data.Packages {
+		if p.Repository == nil {
+			p.Repository = &hub.Repository{}
+		}
+		page.items = append(page.items, newPackageResolver(h, p, false))
+	}
+	return page, nil
+}
+
+// searchRepositories searches repositories using the input and pagination
+// arguments provided.
+func (h *Handlers) searchRepositories(
+	ctx context.Context,
+	input *hub.SearchRepositoryInput,
+	args paginationArgs,
+) (*repositoriesPageResolver, error) {
+	if err := args.validate(); err != nil {
+		return nil, err
+	}
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	input.Limit = int(args.Limit)
+	input.Offset = int(args.Offset)
+	result, err := h.repoManager.Search(ctx, input)
+	if err != nil {
+		return nil, h.handleError(err, "Repositories")
+	}
+	page := &repositoriesPageResolver{
+		totalCount: result.TotalCount,
+		items:      make([]*repositoryResolver, 0, len(result.Repositories)),
+	}
+	for _, repo := range result.Repositories {
+		page.items = append(page.items, &repositoryResolver{h: h, r: repo})
+	}
+	return page, nil
+}
+
+// optionalString returns a pointer to the string provided, or nil if the
+// string is empty.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -6,10 +6,13 @@ import (
 	"net"
 	"net/http"
 	"path"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/artifacthub/hub/internal/handlers/apikey"
+	"github.com/artifacthub/hub/internal/handlers/change"
+	"github.com/artifacthub/hub/internal/handlers/graphql"
 	"github.com/artifacthub/hub/internal/handlers/helpers"
 	"github.com/artifacthub/hub/internal/handlers/org"
 	"github.com/artifacthub/hub/internal/handlers/pkg"
@@ -38,6 +41,11 @@ const csrfHeader = "X-CSRF-Token"
 var (
 	xForwardedFor = http.CanonicalHeaderKey("X-Forwarded-For")
 
+	// readOnlyPOSTPathRE represents a regular expression used to match the
+	// paths of the API endpoints that use the POST method but don't modify
+	// anything.
+	readOnlyPOSTPathRE = regexp.MustCompile(`^/api/v1/(graphql/?|packages/[^/]+/[^/]+/(render|values/validate))$`)
+
 	// WebhooksHTTPClientTimeout represents the timeout of the http client used
 	// to handle the webhooks requests.
 	WebhooksHTTPClientTimeout = 60 * time.Second
@@ -53,6 +61,7 @@ type Services struct {
 	WebhookManager      hub.WebhookManager
 	APIKeyManager       hub.APIKeyManager
 	StatsManager        hub.StatsManager
+	ChangeManager       hub.ChangeManager
 	ImageStore          img.Store
 	Authorizer          hub.Authorizer
 	HTTPClient          hub.HTTPClient
@@ -75,6 +84,7 @@ type Handlers struct {
 	Router  http.Handler
 
 	Organizations *org.Handlers
+	GraphQL       *graphql.Handlers
 	Users         *user.Handlers
 	Packages      *pkg.Handlers
 	Repositories  *repo.Handlers
@@ -83,6 +93,7 @@ type Handlers struct {
 	APIKeys       *apikey.Handlers
 	Static        *static.Handlers
 	Stats         *stats.Handlers
+	Changes       *change.Handlers
 }
 
 // Setup creates a new Handlers instance.
@@ -98,6 +109,7 @@ func Setup(ctx context.Context, cfg *viper.Viper, svc *Services) (*Handlers, err
 		logger:  log.With().Str("handlers", "root").Logger(),
 
 		Organizations: org.NewHandlers(svc.OrganizationManager, svc.Authorizer, cfg),
+		GraphQL:       graphql.NewHandlers(svc.PackageManager, svc.RepositoryManager, svc.OrganizationManager),
 		Users:         userHandlers,
 		Repositories:  repo.NewHandlers(cfg, svc.RepositoryManager),
 		Packages: pkg.NewHandlers(
@@ -116,6 +128,7 @@ func Setup(ctx context.Context, cfg *viper.Viper, svc *Services) (*Handlers, err
 		APIKeys: apikey.NewHandlers(svc.APIKeyManager),
 		Static:  static.NewHandlers(cfg, svc.ImageStore),
 		Stats:   stats.NewHandlers(svc.StatsManager),
+		Changes: change.NewHandlers(svc.ChangeManager),
 	}
 	h.setupRouter()
 	return h, nil
@@ -178,6 +191,13 @@ func (h *Handlers) setupRouter() {
 			w.Header().Set(csrfHeader, csrf.Token(r))
 		})
 
+		// GraphQL
+		r.Route("/graphql", func(r chi.Router) {
+			r.Use(h.Users.InjectUserID)
+			r.Get("/", h.GraphQL.Query)
+			r.Post("/", h.GraphQL.Query)
+		})
+
 		// Users
 		r.Route("/users", func(r chi.Router) {
 			r.Post("/", h.Users.RegisterUser)
@@ -235,6 +255,7 @@ func (h *Handlers) setupRouter() {
 		// Repositories
 		r.Route("/repositories", func(r chi.Router) {
 			r.With(h.Users.InjectUserID).Get("/search", h.Repositories.Search)
+			r.Get("/{repoName}/tracking-runs", h.Repositories.GetTrackingRuns)
 			r.Group(func(r chi.Router) {
 				r.Use(h.Users.RequireLogin)
 				r.Route("/user", func(r chi.Router) {
@@ -281,15 +302,24 @@ func (h *Handlers) setupRouter() {
 				r.With(h.Users.InjectUserID).Get("/", h.Packages.GetStars)
 				r.With(h.Users.RequireLogin).Put("/", h.Packages.ToggleStar)
 			})
+			r.Get("/{packageID}/{version}/dependencies", h.Packages.GetDependencies)
+			r.Get("/{packageID}/{version}/provenance", h.Packages.GetSnapshotProvenance)
+			r.Post("/{packageID}/{version}/render", h.Packages.Render)
 			r.Get("/{packageID}/{version}/security-report", h.Packages.GetSnapshotSecurityReport)
 			r.Get("/{packageID}/{version}/values", h.Packages.GetChartValues)
+			r.Post("/{packageID}/{version}/values/validate", h.Packages.ValidateValues)
 			r.Get("/{packageID}/{version}/values-schema", h.Packages.GetValuesSchema)
 			r.Get("/{packageID}/{version}/templates", h.Packages.GetChartTemplates)
 			r.Post("/{packageID}/{version}/views", h.Packages.TrackView)
 			r.Get("/{packageID}/views", h.Packages.GetViews)
 			r.Get("/{packageID}/changelog", h.Packages.GetChangelog)
+			r.Get("/{packageID}/dependents", h.Packages.GetDependents)
+			r.Get("/{packageID}/diff", h.Packages.GetDiff)
 		})
 
+		// Images
+		r.With(corsMW).Get("/images/{ref}/packages", h.Packages.GetByImage)
+
 		// Subscriptions
 		r.Route("/subscriptions", func(r chi.Router) {
 			r.Use(h.Users.RequireLogin)
@@ -353,6 +383,12 @@ func (h *Handlers) setupRouter() {
 		// Stats
 		r.Get("/stats", h.Stats.Get)
 
+		// Changes
+		r.Route("/changes", func(r chi.Router) {
+			r.Get("/", h.Changes.Get)
+			r.Get("/export", h.Changes.Export)
+		})
+
 		// Harbor replication
 		//
 		// This endpoint is used by the Harbor replication Artifact Hub adapter.
@@ -474,9 +510,16 @@ func (h *Handlers) MetricsCollector(next http.Handler) http.Handler {
 func csrfSkipper(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Skip checks for requests authenticated using API keys
-		if r.Header.Get(user.APIKeyIDHeader) != "" && r.Header.Get(user.APIKeySecretHeader) != "" {
+		if r.Header.Get(hub.APIKeyIDHeader) != "" && r.Header.Get(hub.APIKeySecretHeader) != "" {
 			r = csrf.UnsafeSkipCheck(r)
 		}
+		// Skip checks for read-only POST requests (i.e. to render a chart)
+		// made by anonymous users, as nothing can be done on their behalf
+		if r.Method == "POST" && readOnlyPOSTPathRE.MatchString(r.URL.Path) {
+			if _, err := r.Cookie(user.SessionCookieName); err != nil {
+				r = csrf.UnsafeSkipCheck(r)
+			}
+		}
 		// Skip checks for requests using GET or HEAD methods, except requests
 		// to /api/v1/csrf, which is the endpoint used to get the token that
 		// should be provided on subsequent POST, PUT or DELETE API requests.
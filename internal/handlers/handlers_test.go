@@ -1,14 +1,71 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/artifacthub/hub/internal/handlers/user"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func TestCSRF(t *testing.T) {
+	// Setup handlers
+	webBuildPath := t.TempDir()
+	err := os.WriteFile(filepath.Join(webBuildPath, "index.html"), []byte("index"), 0600)
+	require.NoError(t, err)
+	cfg := viper.New()
+	cfg.Set("server.webBuildPath", webBuildPath)
+	cfg.Set("server.csrf.authKey", "01234567890123456789012345678901")
+	h, err := Setup(context.Background(), cfg, &Services{
+		PackageManager: &pkg.ManagerMock{},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		path             string
+		withSession      bool
+		expectedRejected bool
+	}{
+		{"/api/v1/graphql", false, false},
+		{"/api/v1/graphql", true, true},
+		{"/api/v1/packages/pkg/1.0.0/render", false, false},
+		{"/api/v1/packages/pkg/1.0.0/render", true, true},
+		{"/api/v1/packages/pkg/1.0.0/values/validate", false, false},
+		{"/api/v1/packages/pkg/1.0.0/values/validate", true, true},
+		{"/api/v1/packages/pkg/1.0.0/views", false, true},
+		{"/api/v1/users", false, true},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(fmt.Sprintf("%s (session: %t)", tc.path, tc.withSession), func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest("POST", tc.path, strings.NewReader("{"))
+			if tc.withSession {
+				r.AddCookie(&http.Cookie{Name: user.SessionCookieName, Value: "session"})
+			}
+			h.Router.ServeHTTP(w, r)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			if tc.expectedRejected {
+				assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+			} else {
+				assert.NotEqual(t, http.StatusForbidden, resp.StatusCode)
+			}
+		})
+	}
+}
+
 func TestRealIP(t *testing.T) {
 	checkRemoteAddr := func(expectedRemoteAddr string) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
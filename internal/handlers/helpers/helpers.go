@@ -1,6 +1,7 @@
 package helpers
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/artifacthub/hub/internal/hub"
@@ -28,6 +30,10 @@ const (
 	// PaginationTotalCount represents a header used to indicate the number of
 	// entries available for pagination purposes.
 	PaginationTotalCount = "Pagination-Total-Count"
+
+	// PaginationNextCursor represents a header used to provide the cursor
+	// that can be used to fetch the next page of results.
+	PaginationNextCursor = "Pagination-Next-Cursor"
 )
 
 // BuildCacheControlHeader builds an http cache header using the max age
@@ -70,6 +76,21 @@ func GetPagination(qs url.Values, defaultLimit, maxLimit int) (*hub.Pagination,
 	}, nil
 }
 
+// GetCursorPagination is a helper that extracts the pagination information
+// from the query string values provided, including the cursor. A cursor cannot
+// be used in combination with an offset.
+func GetCursorPagination(qs url.Values, defaultLimit, maxLimit int) (*hub.Pagination, error) {
+	p, err := GetPagination(qs, defaultLimit, maxLimit)
+	if err != nil {
+		return nil, err
+	}
+	p.Cursor = qs.Get("cursor")
+	if p.Cursor != "" && p.Offset != 0 {
+		return nil, errors.New("cursor and offset cannot be used together")
+	}
+	return p, nil
+}
+
 // RenderJSON is a helper to write the json data provided to the given http
 // response writer, setting the appropriate content type, cache and status code.
 func RenderJSON(w http.ResponseWriter, dataJSON []byte, cacheMaxAge time.Duration, code int) {
@@ -79,6 +100,22 @@ func RenderJSON(w http.ResponseWriter, dataJSON []byte, cacheMaxAge time.Duratio
 	_, _ = w.Write(dataJSON)
 }
 
+// RenderJSONWithETag is a helper to write the json data provided to the given
+// http response writer, like RenderJSON does, including a strong ETag header
+// computed from the data. When the ETag matches any of the ones provided in
+// the request's If-None-Match header, a 304 status code is returned instead
+// and the data is not written.
+func RenderJSONWithETag(w http.ResponseWriter, r *http.Request, dataJSON []byte, cacheMaxAge time.Duration) {
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(dataJSON))
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.Header().Set("Cache-Control", BuildCacheControlHeader(cacheMaxAge))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	RenderJSON(w, dataJSON, cacheMaxAge, http.StatusOK)
+}
+
 // RenderErrorJSON is a helper to write the error provided to the given http
 // response writer as json setting the appropriate content type.
 func RenderErrorJSON(w http.ResponseWriter, err error) {
@@ -122,3 +159,16 @@ func writeErrorJSON(w io.Writer, msg string) {
 	}
 	_ = json.NewEncoder(w).Encode(data)
 }
+
+// etagMatches checks if the etag provided matches any of the ones included in
+// the If-None-Match header value provided. As recommended for If-None-Match,
+// the weak comparison function is used.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
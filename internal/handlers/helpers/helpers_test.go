@@ -45,6 +45,62 @@ func TestBuildCacheControlHeader(t *testing.T) {
 	}
 }
 
+func TestGetCursorPagination(t *testing.T) {
+	testCases := []struct {
+		qs                 url.Values
+		expectedPagination *hub.Pagination
+		expectedError      error
+	}{
+		{
+			map[string][]string{
+				"limit": {"aa"},
+			},
+			nil,
+			errors.New("invalid limit"),
+		},
+		{
+			map[string][]string{
+				"cursor": {"cursor"},
+				"offset": {"1"},
+			},
+			nil,
+			errors.New("cursor and offset cannot be used together"),
+		},
+		{
+			map[string][]string{
+				"offset": {"1"},
+			},
+			&hub.Pagination{
+				Limit:  5,
+				Offset: 1,
+			},
+			nil,
+		},
+		{
+			map[string][]string{
+				"cursor": {"cursor"},
+				"limit":  {"10"},
+			},
+			&hub.Pagination{
+				Limit:  10,
+				Cursor: "cursor",
+			},
+			nil,
+		},
+	}
+	for i, tc := range testCases {
+		tc := tc
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Parallel()
+			p, err := GetCursorPagination(tc.qs, 5, 10)
+			assert.Equal(t, tc.expectedPagination, p)
+			if tc.expectedError != nil {
+				assert.Contains(t, err.Error(), tc.expectedError.Error())
+			}
+		})
+	}
+}
+
 func TestGetPagination(t *testing.T) {
 	testCases := []struct {
 		qs                 url.Values
@@ -171,6 +227,47 @@ func TestRenderJSON(t *testing.T) {
 	}
 }
 
+func TestRenderJSONWithETag(t *testing.T) {
+	etag := `"4bd8446d186a1b95e8a807f50e2b0620f9d0de582382cc274a462fe0e8065ff5"`
+	testCases := []struct {
+		ifNoneMatch  string
+		expectedCode int
+	}{
+		{"", http.StatusOK},
+		{`"other"`, http.StatusOK},
+		{etag, http.StatusNotModified},
+		{"W/" + etag, http.StatusNotModified},
+		{`"other", ` + etag, http.StatusNotModified},
+		{"*", http.StatusNotModified},
+	}
+	for i, tc := range testCases {
+		tc := tc
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest("GET", "/", nil)
+			if tc.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			RenderJSONWithETag(w, r, []byte("dataJSON"), time.Hour)
+			resp := w.Result()
+			defer resp.Body.Close()
+			h := resp.Header
+			data, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			assert.Equal(t, etag, h.Get("ETag"))
+			assert.Equal(t, BuildCacheControlHeader(time.Hour), h.Get("Cache-Control"))
+			if tc.expectedCode == http.StatusOK {
+				assert.Equal(t, "application/json", h.Get("Content-Type"))
+				assert.Equal(t, []byte("dataJSON"), data)
+			} else {
+				assert.Empty(t, data)
+			}
+		})
+	}
+}
+
 func TestRenderErrorJSON(t *testing.T) {
 	testCases := []struct {
 		err                error
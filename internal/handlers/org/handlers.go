@@ -165,7 +165,7 @@ func (h *Handlers) GetByUser(w http.ResponseWriter, r *http.Request) {
 // GetMembers is an http handler that returns the members of the provided
 // organization.
 func (h *Handlers) GetMembers(w http.ResponseWriter, r *http.Request) {
-	p, err := helpers.GetPagination(r.URL.Query(), helpers.PaginationDefaultLimit, helpers.PaginationMaxLimit)
+	p, err := helpers.GetCursorPagination(r.URL.Query(), helpers.PaginationDefaultLimit, helpers.PaginationMaxLimit)
 	if err != nil {
 		err = fmt.Errorf("%w: %s", hub.ErrInvalidInput, err.Error())
 		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "GetMembers").Send()
@@ -180,6 +180,9 @@ func (h *Handlers) GetMembers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.Header().Set(helpers.PaginationTotalCount, strconv.Itoa(result.TotalCount))
+	if result.NextCursor != "" {
+		w.Header().Set(helpers.PaginationNextCursor, result.NextCursor)
+	}
 	helpers.RenderJSON(w, result.Data, 0, http.StatusOK)
 }
 
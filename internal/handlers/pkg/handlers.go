@@ -3,17 +3,21 @@ package pkg
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"sort"
 	"strconv"
+	"sync"
 	"text/template"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/artifacthub/hub/internal/handlers/helpers"
 	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/pkg"
 	"github.com/artifacthub/hub/internal/tracker/source/helm"
 	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/feeds"
@@ -22,11 +26,32 @@ import (
 	"github.com/spf13/viper"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"golang.org/x/time/rate"
 	"helm.sh/helm/v3/pkg/chart"
 )
 
 const (
 	searchDefaultLimit = 20
+
+	// renderMaxBodySize represents the maximum size of the body of the
+	// requests to render a chart.
+	renderMaxBodySize = 1 << 20
+
+	// renderRateLimit represents the rate at which each client can make
+	// requests to render charts.
+	renderRateLimit = rate.Limit(1)
+
+	// renderRateBurst represents the number of requests to render charts each
+	// client can make at once.
+	renderRateBurst = 5
+
+	// rateLimiterMaxClients represents the maximum number of clients tracked
+	// by a rate limiter.
+	rateLimiterMaxClients = 10000
+
+	// validateValuesMaxBodySize represents the maximum size of the body of
+	// the requests to validate some values against a chart.
+	validateValuesMaxBodySize = 1 << 20
 )
 
 // Handlers represents a group of http handlers in charge of handling packages
@@ -40,6 +65,7 @@ type Handlers struct {
 	op              hub.OCIPuller
 	vt              hub.ViewsTracker
 	tmplChangelogMD *template.Template
+	renderLimiter   *rateLimiter
 }
 
 // NewHandlers creates a new Handlers instance.
@@ -60,6 +86,7 @@ func NewHandlers(
 		op:              op,
 		vt:              vt,
 		tmplChangelogMD: setupChangelogMDTmpl(),
+		renderLimiter:   newRateLimiter(renderRateLimit, renderRateBurst),
 	}
 }
 
@@ -190,7 +217,53 @@ func (h *Handlers) Get(w http.ResponseWriter, r *http.Request) {
 		helpers.RenderErrorJSON(w, err)
 		return
 	}
-	helpers.RenderJSON(w, dataJSON, helpers.DefaultAPICacheMaxAge, http.StatusOK)
+	helpers.RenderJSONWithETag(w, r, dataJSON, helpers.DefaultAPICacheMaxAge)
+}
+
+// GetByImage is an http handler used to get the packages versions that use the
+// container image provided (url encoded).
+func (h *Handlers) GetByImage(w http.ResponseWriter, r *http.Request) {
+	input, err := buildGetByImageInput(chi.URLParam(r, "ref"), r.URL.Query())
+	if err != nil {
+		err = fmt.Errorf("%w: %s", hub.ErrInvalidInput, err.Error())
+		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "GetByImage").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	result, err := h.pkgManager.GetByImageJSON(r.Context(), input)
+	if err != nil {
+		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "GetByImage").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	w.Header().Set(helpers.PaginationTotalCount, strconv.Itoa(result.TotalCount))
+	helpers.RenderJSON(w, result.Data, helpers.DefaultAPICacheMaxAge, http.StatusOK)
+}
+
+// buildGetByImageInput builds the input used to get the packages using a
+// container image from the image reference and query string values provided.
+func buildGetByImageInput(ref string, qs url.Values) (*hub.GetPackagesByImageInput, error) {
+	image, err := url.PathUnescape(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %s", ref)
+	}
+	p, err := helpers.GetPagination(qs, helpers.PaginationDefaultLimit, helpers.PaginationMaxLimit)
+	if err != nil {
+		return nil, err
+	}
+	var latestOnly bool
+	if qs.Get("latest_only") != "" {
+		latestOnly, err = strconv.ParseBool(qs.Get("latest_only"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid latest only: %s", qs.Get("latest_only"))
+		}
+	}
+	return &hub.GetPackagesByImageInput{
+		Image:      image,
+		LatestOnly: latestOnly,
+		Limit:      p.Limit,
+		Offset:     p.Offset,
+	}, nil
 }
 
 // GetChangelog is an http handler used to get a package's changelog.
@@ -203,7 +276,7 @@ func (h *Handlers) GetChangelog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	dataJSON, _ := json.Marshal(changelog)
-	helpers.RenderJSON(w, dataJSON, helpers.DefaultAPICacheMaxAge, http.StatusOK)
+	helpers.RenderJSONWithETag(w, r, dataJSON, helpers.DefaultAPICacheMaxAge)
 }
 
 // GetChartTemplates is an http handler used to get the templates for a given
@@ -261,6 +334,91 @@ func (h *Handlers) GetChartValues(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(data)
 }
 
+// GetDependencies is an http handler used to get the dependencies of a
+// package's snapshot.
+func (h *Handlers) GetDependencies(w http.ResponseWriter, r *http.Request) {
+	packageID := chi.URLParam(r, "packageID")
+	version := chi.URLParam(r, "version")
+	dataJSON, err := h.pkgManager.GetDependenciesJSON(r.Context(), packageID, version)
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "GetDependencies").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	helpers.RenderJSON(w, dataJSON, helpers.DefaultAPICacheMaxAge, http.StatusOK)
+}
+
+// GetDependents is an http handler used to get the packages that depend on a
+// given package.
+func (h *Handlers) GetDependents(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	p, err := helpers.GetPagination(qs, helpers.PaginationDefaultLimit, helpers.PaginationMaxLimit)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", hub.ErrInvalidInput, err.Error())
+		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "GetDependents").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	input := &hub.GetPackageDependentsInput{
+		PackageID:    chi.URLParam(r, "packageID"),
+		VersionRange: qs.Get("version_range"),
+		Limit:        p.Limit,
+		Offset:       p.Offset,
+	}
+	result, err := h.pkgManager.GetDependentsJSON(r.Context(), input)
+	if err != nil {
+		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "GetDependents").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	w.Header().Set(helpers.PaginationTotalCount, strconv.Itoa(result.TotalCount))
+	helpers.RenderJSON(w, result.Data, helpers.DefaultAPICacheMaxAge, http.StatusOK)
+}
+
+// GetDiff is an http handler used to get the differences between two versions
+// of a package.
+func (h *Handlers) GetDiff(w http.ResponseWriter, r *http.Request) {
+	packageID := chi.URLParam(r, "packageID")
+	qs := r.URL.Query()
+	fromVersion, toVersion := qs.Get("from"), qs.Get("to")
+	if fromVersion == "" || toVersion == "" {
+		err := fmt.Errorf("%w: from and to versions must be provided", hub.ErrInvalidInput)
+		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "GetDiff").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+
+	// Collect the information needed from both versions
+	from, err := h.getDiffVersion(r.Context(), packageID, fromVersion)
+	if err != nil {
+		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "GetDiff").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	to, err := h.getDiffVersion(r.Context(), packageID, toVersion)
+	if err != nil {
+		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "GetDiff").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	changelog, err := h.pkgManager.GetChangelog(r.Context(), packageID)
+	if err != nil {
+		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "GetDiff").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+
+	// Compare versions and return the differences found
+	d, err := pkg.Diff(from, to, *changelog)
+	if err != nil {
+		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "GetDiff").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	dataJSON, _ := json.Marshal(d)
+	helpers.RenderJSON(w, dataJSON, helpers.DefaultAPICacheMaxAge, http.StatusOK)
+}
+
 // GetHarborReplicationDump is an http handler used to get a summary of all
 // available packages versions of kind Helm in the hub database so that they
 // can be synchronized in Harbor.
@@ -313,6 +471,20 @@ func (h *Handlers) GetRandom(w http.ResponseWriter, r *http.Request) {
 	helpers.RenderJSON(w, dataJSON, helpers.DefaultAPICacheMaxAge, http.StatusOK)
 }
 
+// GetSnapshotProvenance is an http handler used to get the SLSA provenance of
+// a package's snapshot.
+func (h *Handlers) GetSnapshotProvenance(w http.ResponseWriter, r *http.Request) {
+	packageID := chi.URLParam(r, "packageID")
+	version := chi.URLParam(r, "version")
+	dataJSON, err := h.pkgManager.GetSnapshotProvenanceJSON(r.Context(), packageID, version)
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "GetSnapshotProvenanceJSON").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	helpers.RenderJSON(w, dataJSON, helpers.DefaultAPICacheMaxAge, http.StatusOK)
+}
+
 // GetSnapshotSecurityReport is an http handler used to get the security report
 // of a package's snapshot.
 func (h *Handlers) GetSnapshotSecurityReport(w http.ResponseWriter, r *http.Request) {
@@ -324,13 +496,13 @@ func (h *Handlers) GetSnapshotSecurityReport(w http.ResponseWriter, r *http.Requ
 		helpers.RenderErrorJSON(w, err)
 		return
 	}
-	helpers.RenderJSON(w, dataJSON, helpers.DefaultAPICacheMaxAge, http.StatusOK)
+	helpers.RenderJSONWithETag(w, r, dataJSON, helpers.DefaultAPICacheMaxAge)
 }
 
 // GetStarredByUser is an http handler used to get the packages starred by the
 // user doing the request.
 func (h *Handlers) GetStarredByUser(w http.ResponseWriter, r *http.Request) {
-	p, err := helpers.GetPagination(r.URL.Query(), helpers.PaginationDefaultLimit, helpers.PaginationMaxLimit)
+	p, err := helpers.GetCursorPagination(r.URL.Query(), helpers.PaginationDefaultLimit, helpers.PaginationMaxLimit)
 	if err != nil {
 		err = fmt.Errorf("%w: %s", hub.ErrInvalidInput, err.Error())
 		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "GetStarredByUser").Send()
@@ -344,6 +516,9 @@ func (h *Handlers) GetStarredByUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.Header().Set(helpers.PaginationTotalCount, strconv.Itoa(result.TotalCount))
+	if result.NextCursor != "" {
+		w.Header().Set(helpers.PaginationNextCursor, result.NextCursor)
+	}
 	helpers.RenderJSON(w, result.Data, 0, http.StatusOK)
 }
 
@@ -447,6 +622,44 @@ func (h *Handlers) InjectIndexMeta(next http.Handler) http.Handler {
 	})
 }
 
+// Render is an http handler used to render a Helm chart package snapshot,
+// using the values and capabilities provided in the request body. The rate at
+// which each client can render charts is limited.
+func (h *Handlers) Render(w http.ResponseWriter, r *http.Request) {
+	if !h.renderLimiter.allow(r) {
+		helpers.RenderErrorWithCodeJSON(w, errors.New("too many requests"), http.StatusTooManyRequests)
+		return
+	}
+	input := &hub.RenderChartInput{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, renderMaxBodySize)).Decode(&input); err != nil {
+		h.logger.Error().Err(err).Str("method", "Render").Msg(hub.ErrInvalidInput.Error())
+		helpers.RenderErrorJSON(w, hub.ErrInvalidInput)
+		return
+	}
+
+	// Get chart's archive from original source
+	chrt, err := h.getChartArchive(
+		r.Context(),
+		chi.URLParam(r, "packageID"),
+		chi.URLParam(r, "version"),
+	)
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "Render").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+
+	// Render chart and return the result
+	result, err := helm.RenderChart(r.Context(), chrt, input)
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "Render").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	dataJSON, _ := json.Marshal(result)
+	helpers.RenderJSON(w, dataJSON, 0, http.StatusOK)
+}
+
 // RssFeed is an http handler used to get the RSS feed of a given package.
 func (h *Handlers) RssFeed(w http.ResponseWriter, r *http.Request) {
 	// Get package details
@@ -523,6 +736,9 @@ func (h *Handlers) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.Header().Set(helpers.PaginationTotalCount, strconv.Itoa(result.TotalCount))
+	if result.NextCursor != "" {
+		w.Header().Set(helpers.PaginationNextCursor, result.NextCursor)
+	}
 	helpers.RenderJSON(w, result.Data, helpers.DefaultAPICacheMaxAge, http.StatusOK)
 }
 
@@ -563,6 +779,40 @@ func (h *Handlers) TrackView(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// ValidateValues is an http handler used to validate the values provided in
+// the request body against the values schema and default values of a given
+// Helm chart package snapshot.
+func (h *Handlers) ValidateValues(w http.ResponseWriter, r *http.Request) {
+	input := &hub.ValuesValidationInput{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, validateValuesMaxBodySize)).Decode(&input); err != nil {
+		h.logger.Error().Err(err).Str("method", "ValidateValues").Msg(hub.ErrInvalidInput.Error())
+		helpers.RenderErrorJSON(w, hub.ErrInvalidInput)
+		return
+	}
+
+	// Get chart's archive from original source
+	chrt, err := h.getChartArchive(
+		r.Context(),
+		chi.URLParam(r, "packageID"),
+		chi.URLParam(r, "version"),
+	)
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "ValidateValues").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+
+	// Validate values and return the result
+	result, err := helm.ValidateValues(chrt, input)
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "ValidateValues").Send()
+		helpers.RenderErrorJSON(w, err)
+		return
+	}
+	dataJSON, _ := json.Marshal(result)
+	helpers.RenderJSON(w, dataJSON, 0, http.StatusOK)
+}
+
 // getChartArchive is a helper function used to download a chart's archive from
 // the original source.
 func (h *Handlers) getChartArchive(ctx context.Context, packageID, version string) (*chart.Chart, error) {
@@ -610,6 +860,39 @@ func (h *Handlers) getChartArchive(ctx context.Context, packageID, version strin
 	return chrt, nil
 }
 
+// getDiffVersion is a helper function used to collect the information about a
+// package version needed to compare it with another version. For Helm charts,
+// the default values and templates are loaded from the chart's archive.
+func (h *Handlers) getDiffVersion(ctx context.Context, packageID, version string) (*pkg.DiffVersion, error) {
+	p, err := h.pkgManager.Get(ctx, &hub.GetPackageInput{
+		PackageID: packageID,
+		Version:   version,
+	})
+	if err != nil {
+		return nil, err
+	}
+	valuesSchema, err := h.pkgManager.GetValuesSchemaJSON(ctx, packageID, version)
+	if err != nil && !errors.Is(err, hub.ErrNotFound) {
+		return nil, err
+	}
+	dv := &pkg.DiffVersion{
+		Pkg:          p,
+		ValuesSchema: valuesSchema,
+	}
+	if p.Repository.Kind == hub.Helm {
+		chrt, err := h.getChartArchive(ctx, packageID, version)
+		if err != nil {
+			return nil, err
+		}
+		dv.Values = chrt.Values
+		dv.Templates = make(map[string]string, len(chrt.Templates))
+		for _, file := range chrt.Templates {
+			dv.Templates[file.Name] = string(file.Data)
+		}
+	}
+	return dv, nil
+}
+
 // buildSearchInput builds a packages search query from a map of query string
 // values, validating them as they are extracted.
 func buildSearchInput(qs url.Values) (*hub.SearchPackageInput, error) {
@@ -635,6 +918,12 @@ func buildSearchInput(qs url.Values) (*hub.SearchPackageInput, error) {
 		}
 	}
 
+	// Cursor
+	cursor := qs.Get("cursor")
+	if cursor != "" && offset != 0 {
+		return nil, errors.New("cursor and offset cannot be used together")
+	}
+
 	// Facets
 	var facets bool
 	if qs.Get("facets") != "" {
@@ -695,6 +984,64 @@ func buildSearchInput(qs url.Values) (*hub.SearchPackageInput, error) {
 		}
 	}
 
+	// Only display packages with provenance attestations
+	var hasProvenance bool
+	if qs.Get("has_provenance") != "" {
+		var err error
+		hasProvenance, err = strconv.ParseBool(qs.Get("has_provenance"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid has provenance: %s", qs.Get("has_provenance"))
+		}
+	}
+
+	// Only display signed packages
+	var signed bool
+	if qs.Get("signed") != "" {
+		var err error
+		signed, err = strconv.ParseBool(qs.Get("signed"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid signed: %s", qs.Get("signed"))
+		}
+	}
+
+	// Only display packages with a security report
+	var hasSecurityReport bool
+	if qs.Get("has_security_report") != "" {
+		var err error
+		hasSecurityReport, err = strconv.ParseBool(qs.Get("has_security_report"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid has security report: %s", qs.Get("has_security_report"))
+		}
+	}
+
+	// Only display charts compatible with the Kubernetes version provided
+	var kubernetesVersion string
+	if qs.Get("kubernetes_version") != "" {
+		v, err := semver.NewVersion(qs.Get("kubernetes_version"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubernetes version: %s", qs.Get("kubernetes_version"))
+		}
+		kubernetesVersion = v.String()
+	}
+
+	// Only display packages updated within the number of days provided
+	var updatedWithin int
+	if qs.Get("updated_within") != "" {
+		var err error
+		updatedWithin, err = strconv.Atoi(qs.Get("updated_within"))
+		if err != nil || updatedWithin < 0 {
+			return nil, fmt.Errorf("invalid updated within: %s", qs.Get("updated_within"))
+		}
+	}
+
+	// Only display packages with no vulnerabilities above the severity provided
+	maxSeverity := qs.Get("max_severity")
+	switch maxSeverity {
+	case "", "none", "low", "medium", "high":
+	default:
+		return nil, fmt.Errorf("invalid max severity: %s", maxSeverity)
+	}
+
 	return &hub.SearchPackageInput{
 		Limit:             limit,
 		Offset:            offset,
@@ -709,9 +1056,18 @@ func buildSearchInput(qs url.Values) (*hub.SearchPackageInput, error) {
 		Official:          official,
 		Operators:         operators,
 		Deprecated:        deprecated,
+		HasProvenance:     hasProvenance,
+		Signed:            signed,
+		HasSecurityReport: hasSecurityReport,
+		MaxSeverity:       maxSeverity,
+		KubernetesVersion: kubernetesVersion,
+		UpdatedWithin:     updatedWithin,
+		Image:             qs.Get("image"),
 		Licenses:          qs["license"],
 		Capabilities:      qs["capabilities"],
+		HelmTypes:         qs["helm_type"],
 		Sort:              qs.Get("sort"),
+		Cursor:            cursor,
 	}, nil
 }
 
@@ -737,3 +1093,45 @@ func contains(l []string, e string) bool {
 	}
 	return false
 }
+
+// rateLimiter limits the rate at which each client (identified by its IP
+// address) can perform a given operation.
+type rateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// newRateLimiter creates a new rateLimiter instance.
+func newRateLimiter(limit rate.Limit, burst int) *rateLimiter {
+	return &rateLimiter{
+		limit:    limit,
+		burst:    burst,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// allow checks if the client that made the request provided is allowed to
+// perform the operation now.
+func (l *rateLimiter) allow(r *http.Request) bool {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limiter, ok := l.limiters[ip]
+	if !ok {
+		// Forget all clients when too many are being tracked, which at worst
+		// allows them to start again with a full burst
+		if len(l.limiters) >= rateLimiterMaxClients {
+			l.limiters = make(map[string]*rate.Limiter)
+		}
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[ip] = limiter
+	}
+	return limiter.Allow()
+}
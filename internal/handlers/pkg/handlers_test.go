@@ -31,6 +31,7 @@ import (
 )
 
 func TestMain(m *testing.M) {
+	helm.HandleRenderWorker()
 	zerolog.SetGlobalLevel(zerolog.Disabled)
 	os.Exit(m.Run())
 }
@@ -378,9 +379,123 @@ func TestGet(t *testing.T) {
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 		assert.Equal(t, "application/json", h.Get("Content-Type"))
 		assert.Equal(t, helpers.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		assert.NotEmpty(t, h.Get("ETag"))
 		assert.Equal(t, []byte("dataJSON"), data)
 		hw.assertExpectations(t)
 	})
+
+	t.Run("package not modified", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.Header.Set("If-None-Match", `"4bd8446d186a1b95e8a807f50e2b0620f9d0de582382cc274a462fe0e8065ff5"`)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("GetJSON", r.Context(), getPkgInput).Return([]byte("dataJSON"), nil)
+		hw.h.Get(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+		assert.Empty(t, data)
+		hw.assertExpectations(t)
+	})
+}
+
+func TestGetByImage(t *testing.T) {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"ref"},
+			Values: []string{"quay.io%2Forg%2Fimg"},
+		},
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []struct {
+			desc   string
+			ref    string
+			params string
+		}{
+			{"invalid image", "quay.io%2", ""},
+			{"invalid limit", "quay.io%2Forg%2Fimg", "limit=z"},
+			{"invalid offset", "quay.io%2Forg%2Fimg", "offset=z"},
+			{"invalid latest only", "quay.io%2Forg%2Fimg", "latest_only=z"},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.desc, func(t *testing.T) {
+				t.Parallel()
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/?"+tc.params, nil)
+				rctx := &chi.Context{
+					URLParams: chi.RouteParams{
+						Keys:   []string{"ref"},
+						Values: []string{tc.ref},
+					},
+				}
+				r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+				hw := newHandlersWrapper()
+				hw.h.GetByImage(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				hw.assertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("get packages by image succeeded", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?latest_only=true&limit=10&offset=1", nil)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("GetByImageJSON", r.Context(), &hub.GetPackagesByImageInput{
+			Image:      "quay.io/org/img",
+			LatestOnly: true,
+			Limit:      10,
+			Offset:     1,
+		}).Return(&hub.JSONQueryResult{
+			Data:       []byte("dataJSON"),
+			TotalCount: 1,
+		}, nil)
+		hw.h.GetByImage(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, h.Get(helpers.PaginationTotalCount), "1")
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.assertExpectations(t)
+	})
+
+	t.Run("error getting packages by image", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("GetByImageJSON", r.Context(), &hub.GetPackagesByImageInput{
+			Image: "quay.io/org/img",
+			Limit: helpers.PaginationDefaultLimit,
+		}).Return(nil, tests.ErrFakeDB)
+		hw.h.GetByImage(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.assertExpectations(t)
+	})
 }
 
 func TestGetChangelog(t *testing.T) {
@@ -642,6 +757,263 @@ func TestGetChartTemplates(t *testing.T) {
 	})
 }
 
+func TestGetDependencies(t *testing.T) {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"packageID", "version"},
+			Values: []string{"pkg1", "1.0.0"},
+		},
+	}
+
+	t.Run("get dependencies succeeded", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("GetDependenciesJSON", r.Context(), "pkg1", "1.0.0").Return([]byte("dataJSON"), nil)
+		hw.h.GetDependencies(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.assertExpectations(t)
+	})
+
+	t.Run("error getting dependencies", func(t *testing.T) {
+		testCases := []struct {
+			err                error
+			expectedStatusCode int
+		}{
+			{
+				hub.ErrInvalidInput,
+				http.StatusBadRequest,
+			},
+			{
+				tests.ErrFakeDB,
+				http.StatusInternalServerError,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.err.Error(), func(t *testing.T) {
+				t.Parallel()
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/", nil)
+				r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+				hw := newHandlersWrapper()
+				hw.pm.On("GetDependenciesJSON", r.Context(), "pkg1", "1.0.0").Return(nil, tc.err)
+				hw.h.GetDependencies(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+				hw.assertExpectations(t)
+			})
+		}
+	})
+}
+
+func TestGetDependents(t *testing.T) {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"packageID"},
+			Values: []string{"pkg1"},
+		},
+	}
+
+	t.Run("invalid pagination params", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?limit=z", nil)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.h.GetDependents(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		hw.assertExpectations(t)
+	})
+
+	t.Run("get dependents succeeded", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?version_range=%3C2.0.0&limit=10&offset=1", nil)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("GetDependentsJSON", r.Context(), &hub.GetPackageDependentsInput{
+			PackageID:    "pkg1",
+			VersionRange: "<2.0.0",
+			Limit:        10,
+			Offset:       1,
+		}).Return(&hub.JSONQueryResult{
+			Data:       []byte("dataJSON"),
+			TotalCount: 1,
+		}, nil)
+		hw.h.GetDependents(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, h.Get(helpers.PaginationTotalCount), "1")
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.assertExpectations(t)
+	})
+
+	t.Run("error getting dependents", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("GetDependentsJSON", r.Context(), &hub.GetPackageDependentsInput{
+			PackageID: "pkg1",
+			Limit:     helpers.PaginationDefaultLimit,
+		}).Return(nil, tests.ErrFakeDB)
+		hw.h.GetDependents(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.assertExpectations(t)
+	})
+}
+
+func TestGetDiff(t *testing.T) {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"packageID"},
+			Values: []string{"pkg1"},
+		},
+	}
+	getPkg1Input := &hub.GetPackageInput{PackageID: "pkg1", Version: "1.0.0"}
+	getPkg2Input := &hub.GetPackageInput{PackageID: "pkg1", Version: "1.1.0"}
+	p1 := &hub.Package{
+		Version: "1.0.0",
+		Data: map[string]interface{}{
+			"manifestRaw": "kind: Task\nname: task1\n",
+		},
+		Repository: &hub.Repository{
+			Kind: hub.TektonTask,
+		},
+	}
+	p2 := &hub.Package{
+		Version: "1.1.0",
+		Data: map[string]interface{}{
+			"manifestRaw": "kind: Task\nname: task2\n",
+		},
+		ContainersImages: []*hub.ContainerImage{
+			{Image: "registry.io/image:1.1.0"},
+		},
+		Repository: &hub.Repository{
+			Kind: hub.TektonTask,
+		},
+	}
+
+	t.Run("from and to versions not provided", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?from=1.0.0", nil)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.h.GetDiff(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		hw.assertExpectations(t)
+	})
+
+	t.Run("error getting package version", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?from=1.0.0&to=1.1.0", nil)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("Get", r.Context(), getPkg1Input).Return(nil, hub.ErrNotFound)
+		hw.h.GetDiff(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.assertExpectations(t)
+	})
+
+	t.Run("error getting changelog", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?from=1.0.0&to=1.1.0", nil)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("Get", r.Context(), getPkg1Input).Return(p1, nil)
+		hw.pm.On("Get", r.Context(), getPkg2Input).Return(p2, nil)
+		hw.pm.On("GetValuesSchemaJSON", r.Context(), "pkg1", "1.0.0").Return(nil, nil)
+		hw.pm.On("GetValuesSchemaJSON", r.Context(), "pkg1", "1.1.0").Return(nil, nil)
+		hw.pm.On("GetChangelog", r.Context(), "pkg1").Return(nil, tests.ErrFakeDB)
+		hw.h.GetDiff(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.assertExpectations(t)
+	})
+
+	t.Run("get diff succeeded", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?from=1.0.0&to=1.1.0", nil)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("Get", r.Context(), getPkg1Input).Return(p1, nil)
+		hw.pm.On("Get", r.Context(), getPkg2Input).Return(p2, nil)
+		hw.pm.On("GetValuesSchemaJSON", r.Context(), "pkg1", "1.0.0").Return(nil, nil)
+		hw.pm.On("GetValuesSchemaJSON", r.Context(), "pkg1", "1.1.0").Return(nil, hub.ErrNotFound)
+		hw.pm.On("GetChangelog", r.Context(), "pkg1").Return(&hub.Changelog{
+			{Version: "1.1.0", Changes: []*hub.Change{{Description: "Change 1"}}},
+			{Version: "1.0.0", Changes: []*hub.Change{{Description: "Change 0"}}},
+		}, nil)
+		hw.h.GetDiff(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		var d *hub.PackageDiff
+		assert.NoError(t, json.Unmarshal(data, &d))
+		assert.Equal(t, "1.0.0", d.From)
+		assert.Equal(t, "1.1.0", d.To)
+		assert.Equal(t, hub.DiffModified, d.Manifest.Kind)
+		assert.Equal(t, []*hub.ValueChange{
+			{Path: "registry.io/image", Kind: hub.DiffAdded, To: "registry.io/image:1.1.0"},
+		}, d.ContainersImages)
+		assert.Len(t, d.Changes, 1)
+		assert.Equal(t, "1.1.0", d.Changes[0].Version)
+		hw.assertExpectations(t)
+	})
+}
+
 func TestGetHarborReplicationDump(t *testing.T) {
 	t.Run("get harbor replication dump succeeded", func(t *testing.T) {
 		t.Parallel()
@@ -799,6 +1171,52 @@ func TestGetRandom(t *testing.T) {
 	})
 }
 
+func TestGetSnapshotProvenance(t *testing.T) {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"packageID", "version"},
+			Values: []string{"pkg1", "1.0.0"},
+		},
+	}
+
+	t.Run("get snapshot provenance succeeded", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("GetSnapshotProvenanceJSON", r.Context(), "pkg1", "1.0.0").Return([]byte("dataJSON"), nil)
+		hw.h.GetSnapshotProvenance(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.assertExpectations(t)
+	})
+
+	t.Run("error getting snapshot provenance", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("GetSnapshotProvenanceJSON", r.Context(), "pkg1", "1.0.0").Return(nil, tests.ErrFakeDB)
+		hw.h.GetSnapshotProvenance(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.assertExpectations(t)
+	})
+}
+
 func TestGetSnapshotSecurityReport(t *testing.T) {
 	rctx := &chi.Context{
 		URLParams: chi.RouteParams{
@@ -1255,6 +1673,127 @@ func TestInjectIndexMeta(t *testing.T) {
 	}
 }
 
+func TestRender(t *testing.T) {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"packageID", "version"},
+			Values: []string{"pkg", "1.0.0"},
+		},
+	}
+	getPkgInput := &hub.GetPackageInput{
+		PackageID: "pkg",
+		Version:   "1.0.0",
+	}
+	p1ContentURL := "https://content.url/p1.tgz"
+	p1 := &hub.Package{
+		ContentURL: p1ContentURL,
+		Repository: &hub.Repository{
+			Kind: hub.Helm,
+			URL:  "https://repo.url",
+		},
+	}
+
+	t.Run("too many requests", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader("{}"))
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.h.renderLimiter = newRateLimiter(0, 0)
+		hw.h.Render(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		hw.assertExpectations(t)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader("{"))
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.h.Render(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		hw.assertExpectations(t)
+	})
+
+	t.Run("get chart archive failed", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader("{}"))
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("Get", r.Context(), getPkgInput).Return(nil, tests.ErrFakeDB)
+		hw.h.Render(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.assertExpectations(t)
+	})
+
+	t.Run("invalid values provided", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader(`{"values": "key: ["}`))
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("Get", r.Context(), getPkgInput).Return(p1, nil)
+		tgzReq, _ := http.NewRequest("GET", p1ContentURL, nil)
+		tgzReq = tgzReq.WithContext(r.Context())
+		tgzReq.Header.Set("Accept-Encoding", "*")
+		f, _ := os.Open("testdata/pkg1-1.0.0.tgz")
+		hw.hc.On("Do", tgzReq).Return(&http.Response{
+			Body:       f,
+			StatusCode: http.StatusOK,
+		}, nil)
+		hw.h.Render(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		hw.assertExpectations(t)
+	})
+
+	t.Run("chart rendered", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader(`{"values": "key: custom"}`))
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("Get", r.Context(), getPkgInput).Return(p1, nil)
+		tgzReq, _ := http.NewRequest("GET", p1ContentURL, nil)
+		tgzReq = tgzReq.WithContext(r.Context())
+		tgzReq.Header.Set("Accept-Encoding", "*")
+		f, _ := os.Open("testdata/pkg1-1.0.0.tgz")
+		hw.hc.On("Do", tgzReq).Return(&http.Response{
+			Body:       f,
+			StatusCode: http.StatusOK,
+		}, nil)
+		hw.h.Render(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		expectedData := []byte(`{"manifests":[{"name":"pkg1/templates/template.yaml","content":"key: custom\n"}],"errors":[],"containers_images":[]}`)
+		assert.Equal(t, expectedData, data)
+		hw.assertExpectations(t)
+	})
+}
+
 func TestRssFeed(t *testing.T) {
 	t.Run("error getting rss feed package", func(t *testing.T) {
 		testCases := []struct {
@@ -1396,6 +1935,14 @@ func TestSearch(t *testing.T) {
 			{"invalid official", "official=z"},
 			{"invalid operators", "operators=z"},
 			{"invalid deprecated", "deprecated=z"},
+			{"invalid has provenance", "has_provenance=z"},
+			{"invalid signed", "signed=z"},
+			{"invalid has security report", "has_security_report=z"},
+			{"invalid kubernetes version", "kubernetes_version=z"},
+			{"invalid updated within", "updated_within=z"},
+			{"invalid updated within (negative)", "updated_within=-1"},
+			{"invalid max severity", "max_severity=critical"},
+			{"cursor and offset used together", "cursor=c&offset=1"},
 		}
 		for _, tc := range testCases {
 			tc := tc
@@ -1449,10 +1996,19 @@ func TestSearch(t *testing.T) {
 		v.Set("official", "true")
 		v.Set("operators", "true")
 		v.Set("deprecated", "true")
+		v.Set("has_provenance", "true")
+		v.Set("signed", "true")
+		v.Set("has_security_report", "true")
+		v.Set("max_severity", "high")
+		v.Set("kubernetes_version", "v1.24")
+		v.Set("updated_within", "30")
+		v.Set("image", "quay.io/org/img")
 		v.Add("license", "l1")
 		v.Add("license", "l2")
 		v.Add("capabilities", "c1")
 		v.Add("capabilities", "c2")
+		v.Add("helm_type", "application")
+		v.Add("helm_type", "library")
 		v.Set("sort", "stars")
 		r, _ := http.NewRequest("GET", "/?"+v.Encode(), nil)
 
@@ -1471,8 +2027,16 @@ func TestSearch(t *testing.T) {
 			Official:          true,
 			Operators:         true,
 			Deprecated:        true,
+			HasProvenance:     true,
+			Signed:            true,
+			HasSecurityReport: true,
+			MaxSeverity:       "high",
+			KubernetesVersion: "1.24.0",
+			UpdatedWithin:     30,
+			Image:             "quay.io/org/img",
 			Licenses:          []string{"l1", "l2"},
 			Capabilities:      []string{"c1", "c2"},
+			HelmTypes:         []string{"application", "library"},
 			Sort:              "stars",
 		}).Return(&hub.JSONQueryResult{
 			Data:       []byte("dataJSON"),
@@ -1492,6 +2056,34 @@ func TestSearch(t *testing.T) {
 		hw.assertExpectations(t)
 	})
 
+	t.Run("valid request using a cursor, search succeeded", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?limit=10&cursor=cursor1", nil)
+
+		hw := newHandlersWrapper()
+		hw.pm.On("SearchJSON", r.Context(), &hub.SearchPackageInput{
+			Limit:           10,
+			RepositoryKinds: []hub.RepositoryKind{},
+			Cursor:          "cursor1",
+		}).Return(&hub.JSONQueryResult{
+			Data:       []byte("dataJSON"),
+			TotalCount: 30,
+			NextCursor: "cursor2",
+		}, nil)
+		hw.h.Search(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "30", h.Get(helpers.PaginationTotalCount))
+		assert.Equal(t, "cursor2", h.Get(helpers.PaginationNextCursor))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.assertExpectations(t)
+	})
+
 	t.Run("error searching packages", func(t *testing.T) {
 		t.Parallel()
 		w := httptest.NewRecorder()
@@ -1649,6 +2241,87 @@ func TestTrackView(t *testing.T) {
 	})
 }
 
+func TestValidateValues(t *testing.T) {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"packageID", "version"},
+			Values: []string{"pkg", "1.0.0"},
+		},
+	}
+	getPkgInput := &hub.GetPackageInput{
+		PackageID: "pkg",
+		Version:   "1.0.0",
+	}
+	p1ContentURL := "https://content.url/p1.tgz"
+	p1 := &hub.Package{
+		ContentURL: p1ContentURL,
+		Repository: &hub.Repository{
+			Kind: hub.Helm,
+			URL:  "https://repo.url",
+		},
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader("{"))
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.h.ValidateValues(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		hw.assertExpectations(t)
+	})
+
+	t.Run("get chart archive failed", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader("{}"))
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("Get", r.Context(), getPkgInput).Return(nil, tests.ErrFakeDB)
+		hw.h.ValidateValues(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.assertExpectations(t)
+	})
+
+	t.Run("values validated", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader(`{"values": "key: custom\nother: value\n"}`))
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		hw := newHandlersWrapper()
+		hw.pm.On("Get", r.Context(), getPkgInput).Return(p1, nil)
+		tgzReq, _ := http.NewRequest("GET", p1ContentURL, nil)
+		tgzReq = tgzReq.WithContext(r.Context())
+		tgzReq.Header.Set("Accept-Encoding", "*")
+		f, _ := os.Open("testdata/pkg1-1.0.0.tgz")
+		hw.hc.On("Do", tgzReq).Return(&http.Response{
+			Body:       f,
+			StatusCode: http.StatusOK,
+		}, nil)
+		hw.h.ValidateValues(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		expectedData := []byte(`{"valid":true,"errors":[],"warnings":[{"pointer":"/other","message":"unknown key other: not found in the default values nor in the values schema"}]}`)
+		assert.Equal(t, expectedData, data)
+		hw.assertExpectations(t)
+	})
+}
+
 func TestGetChartArchive(t *testing.T) {
 	ctx := context.Background()
 	packageID := "packageID"
@@ -2057,6 +2730,21 @@ func TestBuildURL(t *testing.T) {
 	}
 }
 
+func TestRateLimiter(t *testing.T) {
+	t.Parallel()
+	newRequest := func(remoteAddr string) *http.Request {
+		r, _ := http.NewRequest("POST", "/", nil)
+		r.RemoteAddr = remoteAddr
+		return r
+	}
+
+	l := newRateLimiter(0, 2)
+	assert.True(t, l.allow(newRequest("192.0.2.1:1234")))
+	assert.True(t, l.allow(newRequest("192.0.2.1:5678")))
+	assert.False(t, l.allow(newRequest("192.0.2.1:1234")))
+	assert.True(t, l.allow(newRequest("192.0.2.2:")))
+}
+
 type handlersWrapper struct {
 	pm *pkg.ManagerMock
 	rm *repo.ManagerMock
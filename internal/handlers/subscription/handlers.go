@@ -110,7 +110,7 @@ func (h *Handlers) GetByPackage(w http.ResponseWriter, r *http.Request) {
 // GetByUser is an http handler that returns the subscriptions of the user
 // doing the request.
 func (h *Handlers) GetByUser(w http.ResponseWriter, r *http.Request) {
-	p, err := helpers.GetPagination(r.URL.Query(), helpers.PaginationDefaultLimit, helpers.PaginationMaxLimit)
+	p, err := helpers.GetCursorPagination(r.URL.Query(), helpers.PaginationDefaultLimit, helpers.PaginationMaxLimit)
 	if err != nil {
 		err = fmt.Errorf("%w: %s", hub.ErrInvalidInput, err.Error())
 		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "GetByUser").Send()
@@ -124,6 +124,9 @@ func (h *Handlers) GetByUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.Header().Set(helpers.PaginationTotalCount, strconv.Itoa(result.TotalCount))
+	if result.NextCursor != "" {
+		w.Header().Set(helpers.PaginationNextCursor, result.NextCursor)
+	}
 	helpers.RenderJSON(w, result.Data, 0, http.StatusOK)
 }
 
@@ -36,20 +36,16 @@ import (
 )
 
 const (
-	// APIKeyIDHeader represents the header used to provide an API key ID.
-	APIKeyIDHeader = "X-API-KEY-ID" // #nosec
-
-	// APIKeySecretHeader represents the header used to provide an API key
-	// secret.
-	APIKeySecretHeader = "X-API-KEY-SECRET" // #nosec
-
 	// SessionApprovedHeader represents the header used to indicate a client if
 	// a session is approved or not. When a user has enabled TFA, sessions need
 	// be approved by providing a TFA passcode to the session validation
 	// endpoint. If TFA is not enabled, sessions will be approved on creation.
 	SessionApprovedHeader = "X-SESSION-APPROVED"
 
-	sessionCookieName    = "sid"
+	// SessionCookieName represents the name of the cookie used to store the
+	// user's session.
+	SessionCookieName = "sid"
+
 	oauthStateCookieName = "oas"
 	sessionDuration      = 30 * 24 * time.Hour
 	oauthFailedURL       = "/oauth-failed"
@@ -145,13 +141,13 @@ func (h *Handlers) ApproveSession(w http.ResponseWriter, r *http.Request) {
 
 	// Extract sessionID from cookie
 	var sessionID string
-	cookie, err := r.Cookie(sessionCookieName)
+	cookie, err := r.Cookie(SessionCookieName)
 	if err != nil {
 		h.logger.Error().Err(err).Str("method", "ApproveSession").Msg("session cookie not found")
 		helpers.RenderErrorWithCodeJSON(w, errInvalidSession, http.StatusUnauthorized)
 		return
 	}
-	if err = h.sc.Decode(sessionCookieName, cookie.Value, &sessionID); err != nil {
+	if err = h.sc.Decode(SessionCookieName, cookie.Value, &sessionID); err != nil {
 		h.logger.Error().Err(err).Str("method", "ApproveSession").Msg("sessionID decoding failed")
 		helpers.RenderErrorWithCodeJSON(w, errInvalidSession, http.StatusUnauthorized)
 		return
@@ -254,7 +250,7 @@ func (h *Handlers) DeleteUser(w http.ResponseWriter, r *http.Request) {
 
 	// Request browser to delete session cookie
 	cookie := &http.Cookie{
-		Name:    sessionCookieName,
+		Name:    SessionCookieName,
 		Path:    "/",
 		Expires: time.Now().Add(-24 * time.Hour),
 	}
@@ -308,7 +304,8 @@ func (h *Handlers) GetProfile(w http.ResponseWriter, r *http.Request) {
 }
 
 // InjectUserID is a middleware that injects the id of the user doing the
-// request into the request context when a valid session id is provided.
+// request into the request context when a valid API key or session id is
+// provided.
 func (h *Handlers) InjectUserID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var userID string
@@ -323,13 +320,25 @@ func (h *Handlers) InjectUserID(next http.Handler) http.Handler {
 			}
 		}()
 
+		// Use API key based authentication if API key is provided
+		apiKeyID := r.Header.Get(hub.APIKeyIDHeader)
+		apiKeySecret := r.Header.Get(hub.APIKeySecretHeader)
+		if apiKeyID != "" && apiKeySecret != "" {
+			checkAPIKeyOutput, err := h.apiKeyManager.Check(r.Context(), apiKeyID, apiKeySecret)
+			if err != nil || !checkAPIKeyOutput.Valid {
+				return
+			}
+			userID = checkAPIKeyOutput.UserID
+			return
+		}
+
 		// Extract and validate cookie from request
-		cookie, err := r.Cookie(sessionCookieName)
+		cookie, err := r.Cookie(SessionCookieName)
 		if err != nil {
 			return
 		}
 		var sessionID string
-		if err = h.sc.Decode(sessionCookieName, cookie.Value, &sessionID); err != nil {
+		if err = h.sc.Decode(SessionCookieName, cookie.Value, &sessionID); err != nil {
 			return
 		}
 
@@ -382,14 +391,14 @@ func (h *Handlers) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Generate and set session cookie
-	encodedSessionID, err := h.sc.Encode(sessionCookieName, session.SessionID)
+	encodedSessionID, err := h.sc.Encode(SessionCookieName, session.SessionID)
 	if err != nil {
 		h.logger.Error().Err(err).Str("method", "Login").Msg("sessionID encoding failed")
 		helpers.RenderErrorJSON(w, err)
 		return
 	}
 	cookie := &http.Cookie{
-		Name:     sessionCookieName,
+		Name:     SessionCookieName,
 		Value:    encodedSessionID,
 		Path:     "/",
 		Expires:  time.Now().Add(sessionDuration),
@@ -407,10 +416,10 @@ func (h *Handlers) Login(w http.ResponseWriter, r *http.Request) {
 // Logout is an http handler used to log a user out.
 func (h *Handlers) Logout(w http.ResponseWriter, r *http.Request) {
 	// Delete user session
-	cookie, err := r.Cookie(sessionCookieName)
+	cookie, err := r.Cookie(SessionCookieName)
 	if err == nil {
 		var sessionID string
-		err = h.sc.Decode(sessionCookieName, cookie.Value, &sessionID)
+		err = h.sc.Decode(SessionCookieName, cookie.Value, &sessionID)
 		if err == nil {
 			err = h.userManager.DeleteSession(r.Context(), sessionID)
 			if err != nil {
@@ -421,7 +430,7 @@ func (h *Handlers) Logout(w http.ResponseWriter, r *http.Request) {
 
 	// Request browser to delete session cookie
 	cookie = &http.Cookie{
-		Name:    sessionCookieName,
+		Name:    SessionCookieName,
 		Path:    "/",
 		Expires: time.Now().Add(-24 * time.Hour),
 	}
@@ -493,14 +502,14 @@ func (h *Handlers) OauthCallback(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, oauthFailedURL, http.StatusSeeOther)
 		return
 	}
-	encodedSessionID, err := h.sc.Encode(sessionCookieName, session.SessionID)
+	encodedSessionID, err := h.sc.Encode(SessionCookieName, session.SessionID)
 	if err != nil {
 		logger.Error().Err(err).Msg("sessionID encoding failed")
 		http.Redirect(w, r, oauthFailedURL, http.StatusSeeOther)
 		return
 	}
 	sessionCookie := &http.Cookie{
-		Name:     sessionCookieName,
+		Name:     SessionCookieName,
 		Value:    encodedSessionID,
 		Path:     "/",
 		Expires:  time.Now().Add(sessionDuration),
@@ -790,8 +799,8 @@ func (h *Handlers) RequireLogin(next http.Handler) http.Handler {
 		var userID string
 
 		// Extract API key id and secret from header
-		apiKeyID := r.Header.Get(APIKeyIDHeader)
-		apiKeySecret := r.Header.Get(APIKeySecretHeader)
+		apiKeyID := r.Header.Get(hub.APIKeyIDHeader)
+		apiKeySecret := r.Header.Get(hub.APIKeySecretHeader)
 
 		// Use API key based authentication if API key is provided
 		if apiKeyID != "" && apiKeySecret != "" {
@@ -810,11 +819,11 @@ func (h *Handlers) RequireLogin(next http.Handler) http.Handler {
 			userID = checkAPIKeyOutput.UserID
 		} else {
 			// Use cookie based authentication
-			cookie, err := r.Cookie(sessionCookieName)
+			cookie, err := r.Cookie(SessionCookieName)
 			if err == nil {
 				// Extract and validate cookie from request
 				var sessionID string
-				if err = h.sc.Decode(sessionCookieName, cookie.Value, &sessionID); err != nil {
+				if err = h.sc.Decode(SessionCookieName, cookie.Value, &sessionID); err != nil {
 					h.logger.Error().Err(err).Str("method", "RequireLogin").Msg("sessionID decoding failed")
 					helpers.RenderErrorWithCodeJSON(w, errInvalidSession, http.StatusUnauthorized)
 					return
@@ -85,7 +85,7 @@ func TestApproveSession(t *testing.T) {
 		body := strings.NewReader(`{"passcode": "123456"}`)
 		r, _ := http.NewRequest("PUT", "/", body)
 		r.AddCookie(&http.Cookie{
-			Name:  sessionCookieName,
+			Name:  SessionCookieName,
 			Value: "invalidValue",
 		})
 
@@ -105,9 +105,9 @@ func TestApproveSession(t *testing.T) {
 
 		hw := newHandlersWrapper()
 		hw.um.On("ApproveSession", r.Context(), sessionID, "123456").Return(tests.ErrFake)
-		encodedSessionID, _ := hw.h.sc.Encode(sessionCookieName, sessionID)
+		encodedSessionID, _ := hw.h.sc.Encode(SessionCookieName, sessionID)
 		r.AddCookie(&http.Cookie{
-			Name:  sessionCookieName,
+			Name:  SessionCookieName,
 			Value: encodedSessionID,
 		})
 		hw.h.ApproveSession(w, r)
@@ -125,9 +125,9 @@ func TestApproveSession(t *testing.T) {
 
 		hw := newHandlersWrapper()
 		hw.um.On("ApproveSession", r.Context(), sessionID, "123456").Return(nil)
-		encodedSessionID, _ := hw.h.sc.Encode(sessionCookieName, sessionID)
+		encodedSessionID, _ := hw.h.sc.Encode(SessionCookieName, sessionID)
 		r.AddCookie(&http.Cookie{
-			Name:  sessionCookieName,
+			Name:  SessionCookieName,
 			Value: encodedSessionID,
 		})
 		hw.h.ApproveSession(w, r)
@@ -391,7 +391,7 @@ func TestDeleteUser(t *testing.T) {
 		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
 		require.Len(t, resp.Cookies(), 1)
 		cookie := resp.Cookies()[0]
-		assert.Equal(t, sessionCookieName, cookie.Name)
+		assert.Equal(t, SessionCookieName, cookie.Name)
 		assert.Equal(t, "/", cookie.Path)
 		assert.True(t, cookie.Expires.Before(time.Now().Add(-24*time.Hour)))
 		hw.um.AssertExpectations(t)
@@ -580,6 +580,59 @@ func TestInjectUserID(t *testing.T) {
 		}
 	}
 
+	t.Run("error checking api key", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.Header.Add(hub.APIKeyIDHeader, "keyID")
+		r.Header.Add(hub.APIKeySecretHeader, "secret")
+
+		hw := newHandlersWrapper()
+		hw.am.On("Check", r.Context(), "keyID", "secret").Return(nil, tests.ErrFakeDB)
+		hw.h.InjectUserID(checkUserID(nil)).ServeHTTP(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		hw.am.AssertExpectations(t)
+	})
+
+	t.Run("invalid api key provided", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.Header.Add(hub.APIKeyIDHeader, "keyID")
+		r.Header.Add(hub.APIKeySecretHeader, "secret")
+
+		hw := newHandlersWrapper()
+		hw.am.On("Check", r.Context(), "keyID", "secret").
+			Return(&hub.CheckAPIKeyOutput{Valid: false}, nil)
+		hw.h.InjectUserID(checkUserID(nil)).ServeHTTP(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		hw.am.AssertExpectations(t)
+	})
+
+	t.Run("inject user id using api key succeeded", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.Header.Add(hub.APIKeyIDHeader, "keyID")
+		r.Header.Add(hub.APIKeySecretHeader, "secret")
+
+		hw := newHandlersWrapper()
+		hw.am.On("Check", r.Context(), "keyID", "secret").
+			Return(&hub.CheckAPIKeyOutput{UserID: "userID", Valid: true}, nil)
+		hw.h.InjectUserID(checkUserID("userID")).ServeHTTP(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		hw.am.AssertExpectations(t)
+	})
+
 	t.Run("session cookie not provided", func(t *testing.T) {
 		t.Parallel()
 		w := httptest.NewRecorder()
@@ -598,7 +651,7 @@ func TestInjectUserID(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest("GET", "/", nil)
 		r.AddCookie(&http.Cookie{
-			Name:  sessionCookieName,
+			Name:  SessionCookieName,
 			Value: "invalidValue",
 		})
 
@@ -616,9 +669,9 @@ func TestInjectUserID(t *testing.T) {
 		r, _ := http.NewRequest("GET", "/", nil)
 
 		hw := newHandlersWrapper()
-		encodedSessionID, _ := hw.h.sc.Encode(sessionCookieName, sessionID)
+		encodedSessionID, _ := hw.h.sc.Encode(SessionCookieName, sessionID)
 		r.AddCookie(&http.Cookie{
-			Name:  sessionCookieName,
+			Name:  SessionCookieName,
 			Value: encodedSessionID,
 		})
 		hw.um.On("CheckSession", r.Context(), mock.Anything, mock.Anything).
@@ -640,9 +693,9 @@ func TestInjectUserID(t *testing.T) {
 		hw.um.On("CheckSession", r.Context(), mock.Anything, mock.Anything).
 			Return(&hub.CheckSessionOutput{UserID: "", Valid: false}, nil)
 
-		encodedSessionID, _ := hw.h.sc.Encode(sessionCookieName, sessionID)
+		encodedSessionID, _ := hw.h.sc.Encode(SessionCookieName, sessionID)
 		r.AddCookie(&http.Cookie{
-			Name:  sessionCookieName,
+			Name:  SessionCookieName,
 			Value: encodedSessionID,
 		})
 		hw.h.InjectUserID(checkUserID(nil)).ServeHTTP(w, r)
@@ -661,9 +714,9 @@ func TestInjectUserID(t *testing.T) {
 		hw := newHandlersWrapper()
 		hw.um.On("CheckSession", r.Context(), mock.Anything, mock.Anything).
 			Return(&hub.CheckSessionOutput{UserID: "userID", Valid: true}, nil)
-		encodedSessionID, _ := hw.h.sc.Encode(sessionCookieName, sessionID)
+		encodedSessionID, _ := hw.h.sc.Encode(SessionCookieName, sessionID)
 		r.AddCookie(&http.Cookie{
-			Name:  sessionCookieName,
+			Name:  SessionCookieName,
 			Value: encodedSessionID,
 		})
 		hw.h.InjectUserID(checkUserID("userID")).ServeHTTP(w, r)
@@ -781,12 +834,12 @@ func TestLogin(t *testing.T) {
 		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
 		require.Len(t, resp.Cookies(), 1)
 		cookie := resp.Cookies()[0]
-		assert.Equal(t, sessionCookieName, cookie.Name)
+		assert.Equal(t, SessionCookieName, cookie.Name)
 		assert.Equal(t, "/", cookie.Path)
 		assert.True(t, cookie.HttpOnly)
 		assert.False(t, cookie.Secure)
 		var cookieSessionID string
-		err := hw.h.sc.Decode(sessionCookieName, cookie.Value, &cookieSessionID)
+		err := hw.h.sc.Decode(SessionCookieName, cookie.Value, &cookieSessionID)
 		require.NoError(t, err)
 		assert.Equal(t, sessionID, cookieSessionID)
 		assert.Equal(t, "true", h.Get(SessionApprovedHeader))
@@ -815,12 +868,12 @@ func TestLogin(t *testing.T) {
 		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
 		require.Len(t, resp.Cookies(), 1)
 		cookie := resp.Cookies()[0]
-		assert.Equal(t, sessionCookieName, cookie.Name)
+		assert.Equal(t, SessionCookieName, cookie.Name)
 		assert.Equal(t, "/", cookie.Path)
 		assert.True(t, cookie.HttpOnly)
 		assert.False(t, cookie.Secure)
 		var cookieSessionID string
-		err := hw.h.sc.Decode(sessionCookieName, cookie.Value, &cookieSessionID)
+		err := hw.h.sc.Decode(SessionCookieName, cookie.Value, &cookieSessionID)
 		require.NoError(t, err)
 		assert.Equal(t, sessionID, cookieSessionID)
 		assert.Equal(t, "false", h.Get(SessionApprovedHeader))
@@ -841,7 +894,7 @@ func TestLogout(t *testing.T) {
 			{
 				"no session cookie provided",
 				&http.Cookie{
-					Name:  sessionCookieName,
+					Name:  SessionCookieName,
 					Value: "invalidValue",
 				},
 			},
@@ -864,7 +917,7 @@ func TestLogout(t *testing.T) {
 				assert.Equal(t, http.StatusNoContent, resp.StatusCode)
 				require.Len(t, resp.Cookies(), 1)
 				cookie := resp.Cookies()[0]
-				assert.Equal(t, sessionCookieName, cookie.Name)
+				assert.Equal(t, SessionCookieName, cookie.Name)
 				assert.True(t, cookie.Expires.Before(time.Now().Add(-24*time.Hour)))
 			})
 		}
@@ -893,9 +946,9 @@ func TestLogout(t *testing.T) {
 
 				hw := newHandlersWrapper()
 				hw.um.On("DeleteSession", r.Context(), "sessionID").Return(tc.err)
-				encodedSessionID, _ := hw.h.sc.Encode(sessionCookieName, "sessionID")
+				encodedSessionID, _ := hw.h.sc.Encode(SessionCookieName, "sessionID")
 				r.AddCookie(&http.Cookie{
-					Name:  sessionCookieName,
+					Name:  SessionCookieName,
 					Value: encodedSessionID,
 				})
 				hw.h.Logout(w, r)
@@ -905,7 +958,7 @@ func TestLogout(t *testing.T) {
 				assert.Equal(t, http.StatusNoContent, resp.StatusCode)
 				require.Len(t, resp.Cookies(), 1)
 				cookie := resp.Cookies()[0]
-				assert.Equal(t, sessionCookieName, cookie.Name)
+				assert.Equal(t, SessionCookieName, cookie.Name)
 				assert.Equal(t, "/", cookie.Path)
 				assert.True(t, cookie.Expires.Before(time.Now().Add(-24*time.Hour)))
 				hw.um.AssertExpectations(t)
@@ -1229,8 +1282,8 @@ func TestRequireLogin(t *testing.T) {
 					t.Parallel()
 					w := httptest.NewRecorder()
 					r, _ := http.NewRequest("GET", "/", nil)
-					r.Header.Add(APIKeyIDHeader, tc.apiKeyID)
-					r.Header.Add(APIKeySecretHeader, tc.apiKeySecret)
+					r.Header.Add(hub.APIKeyIDHeader, tc.apiKeyID)
+					r.Header.Add(hub.APIKeySecretHeader, tc.apiKeySecret)
 
 					hw := newHandlersWrapper()
 					hw.h.RequireLogin(http.HandlerFunc(testsOK)).ServeHTTP(w, r)
@@ -1250,8 +1303,8 @@ func TestRequireLogin(t *testing.T) {
 			t.Parallel()
 			w := httptest.NewRecorder()
 			r, _ := http.NewRequest("GET", "/", nil)
-			r.Header.Add(APIKeyIDHeader, apiKeyID)
-			r.Header.Add(APIKeySecretHeader, apiKeySecret)
+			r.Header.Add(hub.APIKeyIDHeader, apiKeyID)
+			r.Header.Add(hub.APIKeySecretHeader, apiKeySecret)
 
 			hw := newHandlersWrapper()
 			hw.am.On("Check", r.Context(), apiKeyID, apiKeySecret).Return(nil, tests.ErrFakeDB)
@@ -1271,8 +1324,8 @@ func TestRequireLogin(t *testing.T) {
 			t.Parallel()
 			w := httptest.NewRecorder()
 			r, _ := http.NewRequest("GET", "/", nil)
-			r.Header.Add(APIKeyIDHeader, apiKeyID)
-			r.Header.Add(APIKeySecretHeader, apiKeySecret)
+			r.Header.Add(hub.APIKeyIDHeader, apiKeyID)
+			r.Header.Add(hub.APIKeySecretHeader, apiKeySecret)
 
 			hw := newHandlersWrapper()
 			hw.am.On("Check", r.Context(), apiKeyID, apiKeySecret).
@@ -1293,8 +1346,8 @@ func TestRequireLogin(t *testing.T) {
 			t.Parallel()
 			w := httptest.NewRecorder()
 			r, _ := http.NewRequest("GET", "/", nil)
-			r.Header.Add(APIKeyIDHeader, apiKeyID)
-			r.Header.Add(APIKeySecretHeader, apiKeySecret)
+			r.Header.Add(hub.APIKeyIDHeader, apiKeyID)
+			r.Header.Add(hub.APIKeySecretHeader, apiKeySecret)
 
 			hw := newHandlersWrapper()
 			hw.am.On("Check", r.Context(), apiKeyID, apiKeySecret).
@@ -1314,7 +1367,7 @@ func TestRequireLogin(t *testing.T) {
 			w := httptest.NewRecorder()
 			r, _ := http.NewRequest("GET", "/", nil)
 			r.AddCookie(&http.Cookie{
-				Name:  sessionCookieName,
+				Name:  SessionCookieName,
 				Value: "invalidValue",
 			})
 
@@ -1338,9 +1391,9 @@ func TestRequireLogin(t *testing.T) {
 			hw := newHandlersWrapper()
 			hw.um.On("CheckSession", r.Context(), sessionID, sessionDuration).
 				Return(nil, tests.ErrFakeDB)
-			encodedSessionID, _ := hw.h.sc.Encode(sessionCookieName, sessionID)
+			encodedSessionID, _ := hw.h.sc.Encode(SessionCookieName, sessionID)
 			r.AddCookie(&http.Cookie{
-				Name:  sessionCookieName,
+				Name:  SessionCookieName,
 				Value: encodedSessionID,
 			})
 			hw.h.RequireLogin(http.HandlerFunc(testsOK)).ServeHTTP(w, r)
@@ -1363,9 +1416,9 @@ func TestRequireLogin(t *testing.T) {
 			hw := newHandlersWrapper()
 			hw.um.On("CheckSession", r.Context(), sessionID, sessionDuration).
 				Return(&hub.CheckSessionOutput{UserID: "", Valid: false}, nil)
-			encodedSessionID, _ := hw.h.sc.Encode(sessionCookieName, sessionID)
+			encodedSessionID, _ := hw.h.sc.Encode(SessionCookieName, sessionID)
 			r.AddCookie(&http.Cookie{
-				Name:  sessionCookieName,
+				Name:  SessionCookieName,
 				Value: encodedSessionID,
 			})
 			hw.h.RequireLogin(http.HandlerFunc(testsOK)).ServeHTTP(w, r)
@@ -1388,9 +1441,9 @@ func TestRequireLogin(t *testing.T) {
 			hw := newHandlersWrapper()
 			hw.um.On("CheckSession", r.Context(), sessionID, sessionDuration).
 				Return(&hub.CheckSessionOutput{UserID: "userID", Valid: true}, nil)
-			encodedSessionID, _ := hw.h.sc.Encode(sessionCookieName, sessionID)
+			encodedSessionID, _ := hw.h.sc.Encode(SessionCookieName, sessionID)
 			r.AddCookie(&http.Cookie{
-				Name:  sessionCookieName,
+				Name:  SessionCookieName,
 				Value: encodedSessionID,
 			})
 			hw.h.RequireLogin(http.HandlerFunc(testsOK)).ServeHTTP(w, r)
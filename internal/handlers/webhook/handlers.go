@@ -78,7 +78,7 @@ func (h *Handlers) Get(w http.ResponseWriter, r *http.Request) {
 // organization.
 func (h *Handlers) GetOwnedByOrg(w http.ResponseWriter, r *http.Request) {
 	orgName := chi.URLParam(r, "orgName")
-	p, err := helpers.GetPagination(r.URL.Query(), helpers.PaginationDefaultLimit, helpers.PaginationMaxLimit)
+	p, err := helpers.GetCursorPagination(r.URL.Query(), helpers.PaginationDefaultLimit, helpers.PaginationMaxLimit)
 	if err != nil {
 		err = fmt.Errorf("%w: %s", hub.ErrInvalidInput, err.Error())
 		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "GetOwnedByOrg").Send()
@@ -92,13 +92,16 @@ func (h *Handlers) GetOwnedByOrg(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.Header().Set(helpers.PaginationTotalCount, strconv.Itoa(result.TotalCount))
+	if result.NextCursor != "" {
+		w.Header().Set(helpers.PaginationNextCursor, result.NextCursor)
+	}
 	helpers.RenderJSON(w, result.Data, 0, http.StatusOK)
 }
 
 // GetOwnedByUser is an http handler that returns the webhooks owned by the
 // user doing the request.
 func (h *Handlers) GetOwnedByUser(w http.ResponseWriter, r *http.Request) {
-	p, err := helpers.GetPagination(r.URL.Query(), helpers.PaginationDefaultLimit, helpers.PaginationMaxLimit)
+	p, err := helpers.GetCursorPagination(r.URL.Query(), helpers.PaginationDefaultLimit, helpers.PaginationMaxLimit)
 	if err != nil {
 		err = fmt.Errorf("%w: %s", hub.ErrInvalidInput, err.Error())
 		h.logger.Error().Err(err).Str("query", r.URL.RawQuery).Str("method", "GetOwnedByUser").Send()
@@ -112,6 +115,9 @@ func (h *Handlers) GetOwnedByUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.Header().Set(helpers.PaginationTotalCount, strconv.Itoa(result.TotalCount))
+	if result.NextCursor != "" {
+		w.Header().Set(helpers.PaginationNextCursor, result.NextCursor)
+	}
 	helpers.RenderJSON(w, result.Data, 0, http.StatusOK)
 }
 
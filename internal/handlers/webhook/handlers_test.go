@@ -396,6 +396,34 @@ func TestGetOwnedByUser(t *testing.T) {
 		assert.Equal(t, []byte("dataJSON"), data)
 		hw.wm.AssertExpectations(t)
 	})
+
+	t.Run("get webhook owned by user using a cursor succeeded", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?limit=10&cursor=cursor1", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+
+		hw := newHandlersWrapper()
+		hw.wm.On("GetOwnedByUserJSON", r.Context(), &hub.Pagination{
+			Limit:  10,
+			Cursor: "cursor1",
+		}).Return(&hub.JSONQueryResult{
+			Data:       []byte("dataJSON"),
+			TotalCount: 30,
+			NextCursor: "cursor2",
+		}, nil)
+		hw.h.GetOwnedByUser(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "30", h.Get(helpers.PaginationTotalCount))
+		assert.Equal(t, "cursor2", h.Get(helpers.PaginationNextCursor))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.wm.AssertExpectations(t)
+	})
 }
 
 func TestTriggerTest(t *testing.T) {
@@ -2,6 +2,15 @@ package hub
 
 import "context"
 
+const (
+	// APIKeyIDHeader represents the header used to provide an API key ID.
+	APIKeyIDHeader = "X-API-KEY-ID" // #nosec
+
+	// APIKeySecretHeader represents the header used to provide an API key
+	// secret.
+	APIKeySecretHeader = "X-API-KEY-SECRET" // #nosec
+)
+
 // APIKey represents a key used to interact with the HTTP API.
 type APIKey struct {
 	APIKeyID  string `json:"api_key_id"`
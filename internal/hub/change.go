@@ -0,0 +1,20 @@
+package hub
+
+import "context"
+
+// ChangeManager describes the methods a ChangeManager implementation must
+// provide.
+type ChangeManager interface {
+	Export(ctx context.Context, cursor string, limit int) (*ChangesExport, error)
+	GetJSON(ctx context.Context, since string, limit int) (*JSONQueryResult, error)
+}
+
+// ChangesExport represents a page of the catalog export. Data contains
+// newline delimited json records. NextCursor points to the next page of the
+// export (it will be empty once the export is complete) and SinceCursor can
+// be used to get the changes registered since the export was started.
+type ChangesExport struct {
+	Data        []byte
+	NextCursor  string
+	SinceCursor string
+}
@@ -14,10 +14,75 @@ var (
 	ErrNotFound = errors.New("not found")
 )
 
+// Classes of the errors produced while processing repositories.
+const (
+	ErrorClassGeneric      = "generic"
+	ErrorClassLogo         = "logo"
+	ErrorClassMetadata     = "metadata"
+	ErrorClassPackage      = "package"
+	ErrorClassProvenance   = "provenance"
+	ErrorClassRegistration = "registration"
+	ErrorClassRepository   = "repository"
+	ErrorClassSignature    = "signature"
+	ErrorClassTimeout      = "timeout"
+)
+
 // ErrorsCollector interface defines the methods that an errors collector
 // implementation should provide.
 type ErrorsCollector interface {
-	Append(repositoryID string, err string)
+	Append(repositoryID string, err error)
 	Flush()
 	Init(repositoryID string)
+	SetTrackingRunStats(repositoryID string, stats *TrackingRunStats)
+}
+
+// ErrorRecord represents a structured error produced while processing a
+// repository.
+type ErrorRecord struct {
+	PackageName string `json:"package_name,omitempty"`
+	Version     string `json:"version,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Class       string `json:"class"`
+	Message     string `json:"message"`
+}
+
+// NewErrorRecord creates a new ErrorRecord from the error provided. The
+// details of the first RepositoryError found in the error's chain are used when
+// available.
+func NewErrorRecord(err error) *ErrorRecord {
+	rec := &ErrorRecord{
+		Class:   ErrorClassGeneric,
+		Message: err.Error(),
+	}
+	var rErr *RepositoryError
+	if errors.As(err, &rErr) {
+		rec.PackageName = rErr.PackageName
+		rec.Version = rErr.Version
+		rec.Path = rErr.Path
+		if rErr.Class != "" {
+			rec.Class = rErr.Class
+		}
+	}
+	return rec
+}
+
+// RepositoryError represents an error produced while processing a repository
+// that includes some details about what it relates to. The error message is
+// the one of the wrapped error.
+type RepositoryError struct {
+	PackageName string
+	Version     string
+	Path        string
+	Class       string
+	Err         error
+}
+
+// Error implements the error interface.
+func (e *RepositoryError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *RepositoryError) Unwrap() error {
+	return e.Err
 }
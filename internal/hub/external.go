@@ -34,12 +34,16 @@ type HTTPClient interface {
 type JSONQueryResult struct {
 	Data       []byte `json:"data"`
 	TotalCount int    `json:"total_count"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
-// Pagination defines some information about the results page to fetch.
+// Pagination defines some information about the results page to fetch. The
+// cursor, when provided, is an opaque value returned along with the previous
+// page of results.
 type Pagination struct {
-	Limit  int `json:"limit"`
-	Offset int `json:"offset"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // OCIPuller defines the methods an OCIPuller implementation must provide.
@@ -51,13 +55,24 @@ type OCIPuller interface {
 		username,
 		password string,
 	) (ocispec.Descriptor, []byte, error)
+	PullManifest(
+		ctx context.Context,
+		ref,
+		username,
+		password string,
+	) (*ocispec.Manifest, string, error)
 }
 
-// SignatureChecker is the interface that wraps the HasCosignSignature method,
-// used to check if the OCI artifact identified by the reference provided has a
-// cosign (sigstore) signature.
+// SignatureChecker defines the methods used to check if the OCI artifact
+// identified by the reference provided has a cosign (sigstore) signature, and
+// to verify it. VerifyCosignSignature returns nil when the OCI artifact is not
+// signed. The key is optional and only used for key-based signatures.
+// GetProvenance returns the SLSA provenance found in the in-toto attestations
+// attached to the OCI artifact, or nil when none is available.
 type OCISignatureChecker interface {
+	GetProvenance(ctx context.Context, ref, username, password string) (*Provenance, error)
 	HasCosignSignature(ctx context.Context, ref, username, password string) (bool, error)
+	VerifyCosignSignature(ctx context.Context, ref, username, password string, key []byte) (*SignatureVerification, error)
 }
 
 // OCITagsGetter is the interface that wraps the Tags method, used to get all
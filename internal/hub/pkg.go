@@ -23,6 +23,19 @@ type Change struct {
 // Changelog represents a package's changelog.
 type Changelog []*VersionChanges
 
+// Kinds of changes found when comparing two versions of a package.
+const (
+	// DiffAdded indicates that the item was added in the newer version.
+	DiffAdded = "added"
+
+	// DiffRemoved indicates that the item was removed in the newer version.
+	DiffRemoved = "removed"
+
+	// DiffModified indicates that the item exists in both versions but it
+	// has changed.
+	DiffModified = "modified"
+)
+
 // Channel represents a package's channel.
 type Channel struct {
 	Name    string `json:"name"`
@@ -37,6 +50,32 @@ type GetPackageInput struct {
 	Version        string `json:"version"`
 }
 
+// GetPackagesByImageInput represents the input used to get the packages using
+// a given container image.
+type GetPackagesByImageInput struct {
+	Image      string `json:"image"`
+	LatestOnly bool   `json:"latest_only"`
+	Limit      int    `json:"limit,omitempty"`
+	Offset     int    `json:"offset,omitempty"`
+}
+
+// FileChange represents a change in a file between two versions of a
+// package. The differences are provided in unified diff format.
+type FileChange struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Diff string `json:"diff"`
+}
+
+// GetPackageDependentsInput represents the input used to get the packages
+// that depend on a given package.
+type GetPackageDependentsInput struct {
+	PackageID    string `json:"package_id"`
+	VersionRange string `json:"version_range,omitempty"`
+	Limit        int    `json:"limit,omitempty"`
+	Offset       int    `json:"offset,omitempty"`
+}
+
 // Link represents a url associated with a package.
 type Link struct {
 	Name string `json:"name" yaml:"name"`
@@ -103,6 +142,8 @@ type Package struct {
 	Recommendations                []*Recommendation      `json:"recommendations"`
 	Screenshots                    []*Screenshot          `json:"screenshots"`
 	SignKey                        *SignKey               `json:"sign_key"`
+	SignatureVerification          *SignatureVerification `json:"signature_verification"`
+	Provenance                     *Provenance            `json:"provenance"`
 	Repository                     *Repository            `json:"repository"`
 	TS                             int64                  `json:"ts,omitempty"`
 	Stats                          *PackageStats          `json:"stats"`
@@ -110,18 +151,35 @@ type Package struct {
 	RelativePath                   string                 `json:"relative_path"`
 }
 
+// PackageDiff represents the differences between two versions of a package.
+type PackageDiff struct {
+	From             string         `json:"from"`
+	To               string         `json:"to"`
+	Values           []*ValueChange `json:"values"`
+	ValuesSchema     []*ValueChange `json:"values_schema"`
+	Templates        []*FileChange  `json:"templates"`
+	Manifest         *FileChange    `json:"manifest,omitempty"`
+	CRDs             []*ValueChange `json:"crds"`
+	ContainersImages []*ValueChange `json:"containers_images"`
+	Changes          Changelog      `json:"changes"`
+}
+
 // PackageManager describes the methods a PackageManager implementation must
 // provide.
 type PackageManager interface {
 	AddProductionUsage(ctx context.Context, repoName, pkgName, orgName string) error
 	DeleteProductionUsage(ctx context.Context, repoName, pkgName, orgName string) error
 	Get(ctx context.Context, input *GetPackageInput) (*Package, error)
+	GetByImageJSON(ctx context.Context, input *GetPackagesByImageInput) (*JSONQueryResult, error)
 	GetChangelog(ctx context.Context, pkgID string) (*Changelog, error)
+	GetDependenciesJSON(ctx context.Context, pkgID, version string) ([]byte, error)
+	GetDependentsJSON(ctx context.Context, input *GetPackageDependentsInput) (*JSONQueryResult, error)
 	GetHarborReplicationDumpJSON(ctx context.Context) ([]byte, error)
 	GetHelmExporterDumpJSON(ctx context.Context) ([]byte, error)
 	GetJSON(ctx context.Context, input *GetPackageInput) ([]byte, error)
 	GetProductionUsageJSON(ctx context.Context, repoName, pkgName string) ([]byte, error)
 	GetRandomJSON(ctx context.Context) ([]byte, error)
+	GetSnapshotProvenanceJSON(ctx context.Context, pkgID, version string) ([]byte, error)
 	GetSnapshotSecurityReportJSON(ctx context.Context, pkgID, version string) ([]byte, error)
 	GetSnapshotsToScan(ctx context.Context) ([]*SnapshotToScan, error)
 	GetStarredByUserJSON(ctx context.Context, p *Pagination) (*JSONQueryResult, error)
@@ -188,6 +246,27 @@ type Recommendation struct {
 	URL string `json:"url" yaml:"url"`
 }
 
+// RenderChartInput represents the input used to render a Helm chart.
+type RenderChartInput struct {
+	Values      string   `json:"values"`
+	KubeVersion string   `json:"kube_version"`
+	APIVersions []string `json:"api_versions"`
+}
+
+// RenderedChart represents the result of rendering a Helm chart.
+type RenderedChart struct {
+	Manifests        []*RenderedManifest `json:"manifests"`
+	Errors           []string            `json:"errors"`
+	ContainersImages []string            `json:"containers_images"`
+}
+
+// RenderedManifest represents a manifest generated when rendering a Helm
+// chart.
+type RenderedManifest struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
 // Screenshot represents a screenshot associated with a package.
 type Screenshot struct {
 	Title string `json:"title" yaml:"title"`
@@ -204,6 +283,16 @@ type SnapshotSecurityReport struct {
 	Summary       *SecurityReportSummary   `json:"summary"`
 }
 
+// Provenance represents the SLSA provenance of a package version, obtained
+// from the in-toto attestations attached to its OCI artifact.
+type Provenance struct {
+	PredicateType string `json:"predicate_type"`
+	BuilderID     string `json:"builder_id"`
+	SourceRepo    string `json:"source_repo,omitempty"`
+	SourceCommit  string `json:"source_commit,omitempty"`
+	BuildLevel    int    `json:"build_level"`
+}
+
 // SecurityReportSummary represents a summary of the security report.
 type SecurityReportSummary struct {
 	Critical int `json:"critical"`
@@ -219,6 +308,32 @@ type SignKey struct {
 	URL         string `json:"url" yaml:"url"`
 }
 
+// Signature verification statuses.
+const (
+	// SignatureVerified indicates that at least one of the signatures of the
+	// package version was verified successfully.
+	SignatureVerified = "verified"
+
+	// SignatureUnverified indicates that the package version is signed, but
+	// the signatures could not be verified (i.e. no sign key was provided).
+	SignatureUnverified = "unverified"
+
+	// SignatureInvalid indicates that the signatures of the package version
+	// were checked and none of them was valid.
+	SignatureInvalid = "invalid"
+)
+
+// SignatureVerification represents the result of verifying the signatures of
+// a package version.
+type SignatureVerification struct {
+	Status      string `json:"status"`
+	Kind        string `json:"kind,omitempty"`
+	Signer      string `json:"signer,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Issuer      string `json:"issuer,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
 // SnapshotToScan represents some information about a package's snapshot that
 // needs to be scanned for security vulnerabilities.
 type SnapshotToScan struct {
@@ -244,9 +359,48 @@ type SearchPackageInput struct {
 	Official          bool             `json:"official"`
 	Operators         bool             `json:"operators"`
 	Deprecated        bool             `json:"deprecated"`
+	HasProvenance     bool             `json:"has_provenance"`
+	Signed            bool             `json:"signed"`
+	HasSecurityReport bool             `json:"has_security_report"`
+	MaxSeverity       string           `json:"max_severity,omitempty"`
+	KubernetesVersion string           `json:"kubernetes_version,omitempty"`
+	UpdatedWithin     int              `json:"updated_within,omitempty"`
+	Image             string           `json:"image,omitempty"`
 	Licenses          []string         `json:"licenses,omitempty"`
 	Capabilities      []string         `json:"capabilities,omitempty"`
+	HelmTypes         []string         `json:"helm_types,omitempty"`
 	Sort              string           `json:"sort,omitempty"`
+	Cursor            string           `json:"-"`
+}
+
+// ValueChange represents a change in a value between two versions of a
+// package. The path identifies the value changed (i.e. image.tag).
+type ValueChange struct {
+	Path string      `json:"path"`
+	Kind string      `json:"kind"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// ValuesValidation represents the result of validating some values against a
+// Helm chart's values schema and default values.
+type ValuesValidation struct {
+	Valid    bool                     `json:"valid"`
+	Errors   []*ValuesValidationIssue `json:"errors"`
+	Warnings []*ValuesValidationIssue `json:"warnings"`
+}
+
+// ValuesValidationInput represents the input used to validate some values
+// against a Helm chart.
+type ValuesValidationInput struct {
+	Values string `json:"values"`
+}
+
+// ValuesValidationIssue represents an issue found when validating some values.
+// The pointer identifies the value the issue refers to (i.e. /image/tag).
+type ValuesValidationIssue struct {
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
 }
 
 // Version represents a package's version.
@@ -22,6 +22,11 @@ const (
 	// Tekton catalog versioning kinds
 	TektonDirBasedVersioning = "directory"
 	TektonGitBasedVersioning = "git"
+
+	// Git repositories tracking modes
+	GitTrackingBranch    = "branch"
+	GitTrackingLatestTag = "latest-tag"
+	GitTrackingRef       = "ref"
 )
 
 // ContainerImageData represents some data specific to repositories of the
@@ -42,6 +47,33 @@ type TektonData struct {
 	Versioning string `json:"versioning"` // Options: directory or git
 }
 
+// FederationData represents some data specific to repositories that mirror
+// the packages available in a remote Artifact Hub instance.
+type FederationData struct {
+	Federation *Federation `json:"federation,omitempty"`
+}
+
+// Federation represents the settings used to mirror packages from a remote
+// Artifact Hub instance. Only packages of the same kind as the repository are
+// mirrored, and they can be restricted to the remote repositories and
+// organizations provided.
+type Federation struct {
+	Repositories  []string `json:"repositories,omitempty"`
+	Organizations []string `json:"organizations,omitempty"`
+}
+
+// GitData represents some data specific to repositories hosted in git.
+type GitData struct {
+	Tracking *GitTracking `json:"git_tracking,omitempty"`
+}
+
+// GitTracking represents the settings used to decide which revision of a git
+// repository is tracked.
+type GitTracking struct {
+	Mode string `json:"mode"` // Options: branch, latest-tag or ref
+	Ref  string `json:"ref,omitempty"`
+}
+
 // RepositoryKind represents the kind of a given repository.
 type RepositoryKind int64
 
@@ -170,6 +202,29 @@ func GetKindFromName(kind string) (RepositoryKind, error) {
 	}
 }
 
+// GitAuth represents some git specific authentication settings that can be
+// used when cloning a repository. They are stored encrypted in the database.
+type GitAuth struct {
+	Username      string         `json:"username,omitempty"`
+	SSHKey        string         `json:"ssh_key,omitempty"`
+	SSHKnownHosts string         `json:"ssh_known_hosts,omitempty"`
+	GitHubApp     *GitHubAppAuth `json:"github_app,omitempty"`
+}
+
+// IsZero checks if no git auth settings have been provided.
+func (a *GitAuth) IsZero() bool {
+	return a.Username == "" && a.SSHKey == "" && a.SSHKnownHosts == "" && a.GitHubApp == nil
+}
+
+// GitHubAppAuth represents the credentials of a GitHub App installation, used
+// to mint short-lived tokens when cloning a repository.
+type GitHubAppAuth struct {
+	AppID          int64  `json:"app_id"`
+	InstallationID int64  `json:"installation_id"`
+	PrivateKey     string `json:"private_key"`
+	APIURL         string `json:"api_url,omitempty"`
+}
+
 // HelmIndexLoader interface defines the methods a Helm index loader
 // implementation should provide.
 type HelmIndexLoader interface {
@@ -212,6 +267,11 @@ type Repository struct {
 	Disabled                bool            `json:"disabled"`
 	ScannerDisabled         bool            `json:"scanner_disabled"`
 	Data                    json.RawMessage `json:"data,omitempty"`
+	GitAuth                 *GitAuth        `json:"git_auth,omitempty"`
+
+	// EncryptedGitAuth contains the git auth settings as stored in the
+	// database. It's only used internally by the repository manager.
+	EncryptedGitAuth string `json:"encrypted_git_auth,omitempty"`
 }
 
 // RepositoryCloner describes the methods a RepositoryCloner implementation
@@ -236,10 +296,11 @@ type RepositoryManager interface {
 	GetMetadata(r *Repository, basePath string) (*RepositoryMetadata, error)
 	GetPackagesDigest(ctx context.Context, repositoryID string) (map[string]string, error)
 	GetRemoteDigest(ctx context.Context, r *Repository) (string, error)
+	GetTrackingRunsJSON(ctx context.Context, name string) ([]byte, error)
 	Search(ctx context.Context, input *SearchRepositoryInput) (*SearchRepositoryResult, error)
 	SearchJSON(ctx context.Context, input *SearchRepositoryInput) (*JSONQueryResult, error)
 	SetLastScanningResults(ctx context.Context, repositoryID, errs string) error
-	SetLastTrackingResults(ctx context.Context, repositoryID, errs string) error
+	SetLastTrackingResults(ctx context.Context, repositoryID, errs string, run *TrackingRun) error
 	SetVerifiedPublisher(ctx context.Context, repositoryID string, verified bool) error
 	Transfer(ctx context.Context, name, orgName string, ownershipClaim bool) error
 	Update(ctx context.Context, r *Repository) error
@@ -280,3 +341,22 @@ type SearchRepositoryResult struct {
 	Repositories []*Repository
 	TotalCount   int
 }
+
+// TrackingRun represents a summary of a repository tracking run.
+type TrackingRun struct {
+	TrackingRunID string `json:"tracking_run_id,omitempty"`
+	StartedAt     int64  `json:"started_at"`
+	FinishedAt    int64  `json:"finished_at,omitempty"`
+	TrackingRunStats
+	ErrorsCount     int            `json:"errors_count"`
+	ErrorsTruncated bool           `json:"errors_truncated"`
+	Errors          []*ErrorRecord `json:"errors"`
+}
+
+// TrackingRunStats represents some stats about the packages processed in a
+// repository tracking run.
+type TrackingRunStats struct {
+	PackagesRegistered   int `json:"packages_registered"`
+	PackagesUnregistered int `json:"packages_unregistered"`
+	PackagesSkipped      int `json:"packages_skipped"`
+}
@@ -27,12 +27,36 @@ func (m *PullerMock) PullLayer(
 	return desc, data, args.Error(2)
 }
 
+// PullManifest implements the hub.OCIPuller interface.
+func (m *PullerMock) PullManifest(
+	ctx context.Context,
+	ref,
+	username,
+	password string,
+) (*ocispec.Manifest, string, error) {
+	args := m.Called(ctx, ref, username, password)
+	manifest, _ := args.Get(0).(*ocispec.Manifest)
+	return manifest, args.String(1), args.Error(2)
+}
+
 // SignatureCheckerMock is a mock implementation of the hub.OCISignatureChecker
 // interface.
 type SignatureCheckerMock struct {
 	mock.Mock
 }
 
+// GetProvenance implements the OCISignatureChecker interface.
+func (m *SignatureCheckerMock) GetProvenance(
+	ctx context.Context,
+	ref,
+	username,
+	password string,
+) (*hub.Provenance, error) {
+	args := m.Called(ctx, ref, username, password)
+	p, _ := args.Get(0).(*hub.Provenance)
+	return p, args.Error(1)
+}
+
 // HasCosignSignature implements the OCITagsGetter interface.
 func (m *SignatureCheckerMock) HasCosignSignature(
 	ctx context.Context,
@@ -44,6 +68,19 @@ func (m *SignatureCheckerMock) HasCosignSignature(
 	return args.Bool(0), args.Error(1)
 }
 
+// VerifyCosignSignature implements the OCISignatureChecker interface.
+func (m *SignatureCheckerMock) VerifyCosignSignature(
+	ctx context.Context,
+	ref,
+	username,
+	password string,
+	key []byte,
+) (*hub.SignatureVerification, error) {
+	args := m.Called(ctx, ref, username, password, key)
+	v, _ := args.Get(0).(*hub.SignatureVerification)
+	return v, args.Error(1)
+}
+
 // TagsGetterMock is a mock implementation of the hub.OCITagsGetter interface.
 type TagsGetterMock struct {
 	mock.Mock
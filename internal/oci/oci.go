@@ -2,7 +2,10 @@ package oci
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"sort"
 	"strings"
 
@@ -11,6 +14,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	csremote "github.com/sigstore/cosign/pkg/oci/remote"
 	"github.com/sigstore/cosign/pkg/types"
@@ -104,6 +108,33 @@ func (p *Puller) PullLayer(
 	return ocispec.Descriptor{}, nil, ErrLayerNotFound
 }
 
+// PullManifest pulls the manifest of the OCI artifact at the given reference,
+// returning it along with its digest.
+func (p *Puller) PullManifest(
+	ctx context.Context,
+	imageRef,
+	username,
+	password string,
+) (*ocispec.Manifest, string, error) {
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return nil, "", err
+	}
+	desc, err := remote.Get(ref, PrepareRemoteOptions(ctx, p.cfg, ref, username, password)...)
+	if err != nil {
+		var terr *transport.Error
+		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+			err = ErrArtifactNotFound
+		}
+		return nil, "", err
+	}
+	var manifest *ocispec.Manifest
+	if err := json.Unmarshal(desc.Manifest, &manifest); err != nil {
+		return nil, "", fmt.Errorf("error unmarshaling manifest: %w", err)
+	}
+	return manifest, desc.Digest.String(), nil
+}
+
 // SignatureChecker is a hub.OCISignatureChecker implementation.
 type SignatureChecker struct {
 	cfg *viper.Viper
@@ -203,18 +234,27 @@ func PrepareRemoteOptions(
 	if ctx != nil {
 		options = append(options, remote.WithContext(ctx))
 	}
+	if auth := authenticator(cfg, ref, username, password); auth != authn.Anonymous {
+		options = append(options, remote.WithAuth(auth))
+	}
+	return options
+}
+
+// authenticator returns the authenticator that should be used to interact
+// with the registry of the reference provided.
+func authenticator(cfg *viper.Viper, ref name.Reference, username, password string) authn.Authenticator {
 	if username != "" || password != "" {
-		options = append(options, remote.WithAuth(&authn.Basic{
+		return &authn.Basic{
 			Username: username,
 			Password: password,
-		}))
+		}
 	} else if cfg != nil && registryIsDockerHub(ref) {
-		options = append(options, remote.WithAuth(&authn.Basic{
+		return &authn.Basic{
 			Username: cfg.GetString("creds.dockerUsername"),
 			Password: cfg.GetString("creds.dockerPassword"),
-		}))
+		}
 	}
-	return options
+	return authn.Anonymous
 }
 
 // registryIsDockerHub checks if the registry name of the reference provided is
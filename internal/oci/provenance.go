@@ -0,0 +1,530 @@
+package oci
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	csremote "github.com/sigstore/cosign/pkg/oci/remote"
+)
+
+const (
+	// maxAttestationSize represents the maximum size of the attestations
+	// layers that will be processed.
+	maxAttestationSize = 10 << 20
+
+	// githubActionsIssuer represents the OIDC issuer of the GitHub Actions
+	// workflows identity tokens.
+	githubActionsIssuer = "https://token.actions.githubusercontent.com"
+
+	inTotoPayloadType = "application/vnd.in-toto+json"
+	dsseMediaType     = "application/vnd.dsse.envelope.v1+json"
+	bundleMediaType   = "application/vnd.dev.sigstore.bundle"
+)
+
+var (
+	// slsaPredicateTypes represents the SLSA provenance predicate types
+	// supported.
+	slsaPredicateTypes = []string{
+		"https://slsa.dev/provenance/v0.1",
+		"https://slsa.dev/provenance/v0.2",
+		"https://slsa.dev/provenance/v1",
+	}
+
+	// slsaL3Builders represents the ids prefixes of the builders known to
+	// meet the SLSA build level 3 requirements.
+	slsaL3Builders = []string{
+		"https://github.com/slsa-framework/slsa-github-generator/",
+	}
+)
+
+// inTotoStatement represents an in-toto attestation statement.
+type inTotoStatement struct {
+	Type          string          `json:"_type"`
+	PredicateType string          `json:"predicateType"`
+	Subject       []inTotoSubject `json:"subject"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// inTotoSubject represents the subject of an in-toto attestation statement.
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// dsseEnvelope represents a DSSE envelope, used to sign in-toto statements.
+type dsseEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     string          `json:"payload"`
+	Signatures  []dsseSignature `json:"signatures"`
+}
+
+// dsseSignature represents a signature of a DSSE envelope.
+type dsseSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// attestation represents an attestation attached to an OCI artifact, along
+// with the signing certificate (and its chain) when available.
+type attestation struct {
+	data  []byte
+	cert  *x509.Certificate
+	chain []*x509.Certificate
+}
+
+// trustMaterial represents the material used to verify the signatures of the
+// attestations.
+type trustMaterial struct {
+	roots         *x509.CertPool
+	intermediates *x509.CertPool
+	identities    []*trustedIdentity
+}
+
+// GetProvenance returns the SLSA provenance of the OCI artifact identified by
+// the reference provided. In-toto attestations are discovered from the cosign
+// attestation tag (.att) and from the OCI referrers API. When more than one
+// SLSA provenance attestation is found, the one with the highest build level
+// is returned.
+//
+// Build levels above 1 are only awarded when the attestation signature is
+// verified using the signing certificate, that must chain up to the trust root
+// set in the tracker.cosign.trustRoot configuration entry. Level 3 requires
+// the attestation to be signed by a builder known to meet its requirements,
+// whereas level 2 requires the signer to be one of the trusted identities set
+// in the tracker.cosign.identities configuration entry.
+func (c *SignatureChecker) GetProvenance(
+	ctx context.Context,
+	ref,
+	username,
+	password string,
+) (*hub.Provenance, error) {
+	// Get artifact digest
+	artifactRef, err := name.ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	options := PrepareRemoteOptions(ctx, c.cfg, artifactRef, username, password)
+	desc, err := remote.Head(artifactRef, options...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting artifact digest: %w", err)
+	}
+	digestRef := artifactRef.Context().Digest(desc.Digest.String())
+
+	// Prepare verification material
+	tm := &trustMaterial{}
+	tm.roots, tm.intermediates, err = c.trustRoot()
+	if err != nil {
+		return nil, err
+	}
+	tm.identities, err = c.trustedIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	// Discover attestations
+	attestations, err := getCosignAttestations(digestRef, options)
+	if err != nil {
+		return nil, err
+	}
+	auth := authenticator(c.cfg, artifactRef, username, password)
+	referrersAttestations, err := getReferrersAttestations(ctx, digestRef, auth, options)
+	if err != nil {
+		return nil, err
+	}
+	attestations = append(attestations, referrersAttestations...)
+
+	// Select provenance with the highest build level
+	var provenance *hub.Provenance
+	for _, att := range attestations {
+		p, err := parseProvenance(att, desc.Digest, tm)
+		if err != nil || p == nil {
+			continue
+		}
+		if provenance == nil || p.BuildLevel > provenance.BuildLevel {
+			provenance = p
+		}
+	}
+	return provenance, nil
+}
+
+// getCosignAttestations returns the attestations attached to the artifact
+// using the cosign attestation tag.
+func getCosignAttestations(digestRef name.Digest, options []remote.Option) ([]*attestation, error) {
+	attRef, err := csremote.AttestationTag(digestRef, csremote.WithRemoteOptions(options...))
+	if err != nil {
+		return nil, err
+	}
+	atts, err := csremote.Signatures(attRef, csremote.WithRemoteOptions(options...))
+	if err != nil {
+		return nil, fmt.Errorf("error getting attestations: %w", err)
+	}
+	layers, err := atts.Get()
+	if err != nil {
+		return nil, fmt.Errorf("error getting attestations: %w", err)
+	}
+	attestations := make([]*attestation, 0, len(layers))
+	for _, l := range layers {
+		payload, err := l.Payload()
+		if err != nil {
+			continue
+		}
+		att := &attestation{data: payload}
+		if cert, err := l.Cert(); err == nil && cert != nil {
+			att.cert = cert
+			att.chain, _ = l.Chain()
+		}
+		attestations = append(attestations, att)
+	}
+	return attestations, nil
+}
+
+// getReferrersAttestations returns the attestations attached to the artifact
+// using the OCI referrers API. Registries not supporting this API are ignored.
+func getReferrersAttestations(
+	ctx context.Context,
+	digestRef name.Digest,
+	auth authn.Authenticator,
+	options []remote.Option,
+) ([]*attestation, error) {
+	// Get referrers index
+	index, err := getReferrers(ctx, digestRef, auth)
+	if err != nil || index == nil {
+		return nil, err
+	}
+
+	// Get attestations from the referrers manifests layers
+	var attestations []*attestation
+	for _, m := range index.Manifests {
+		if !isAttestationMediaType(m.ArtifactType) {
+			continue
+		}
+		img, err := remote.Image(digestRef.Context().Digest(m.Digest.String()), options...)
+		if err != nil {
+			return nil, fmt.Errorf("error getting referrer manifest: %w", err)
+		}
+		layers, err := img.Layers()
+		if err != nil {
+			return nil, fmt.Errorf("error getting referrer layers: %w", err)
+		}
+		for _, l := range layers {
+			mt, err := l.MediaType()
+			if err != nil || !isAttestationMediaType(string(mt)) {
+				continue
+			}
+			rc, err := l.Compressed()
+			if err != nil {
+				return nil, fmt.Errorf("error getting referrer layer: %w", err)
+			}
+			data, err := io.ReadAll(io.LimitReader(rc, maxAttestationSize))
+			rc.Close()
+			if err != nil {
+				return nil, fmt.Errorf("error reading referrer layer: %w", err)
+			}
+			attestations = append(attestations, &attestation{data: data})
+		}
+	}
+	return attestations, nil
+}
+
+// getReferrers returns the referrers index of the artifact provided. When the
+// registry does not support the referrers API, nil is returned.
+func getReferrers(ctx context.Context, digestRef name.Digest, auth authn.Authenticator) (*ocispec.Index, error) {
+	repo := digestRef.Context()
+	scopes := []string{repo.Scope(transport.PullScope)}
+	tr, err := transport.NewWithContext(ctx, repo.Registry, auth, http.DefaultTransport, scopes)
+	if err != nil {
+		return nil, fmt.Errorf("error setting up registry transport: %w", err)
+	}
+	u := url.URL{
+		Scheme: repo.Registry.Scheme(),
+		Host:   repo.RegistryStr(),
+		Path:   fmt.Sprintf("/v2/%s/referrers/%s", repo.RepositoryStr(), digestRef.DigestStr()),
+	}
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	req.Header.Set("Accept", ocispec.MediaTypeImageIndex)
+	resp, err := (&http.Client{Transport: tr}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error getting referrers: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusBadRequest, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("error getting referrers: unexpected status code received: %d", resp.StatusCode)
+	}
+	var index *ocispec.Index
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxAttestationSize)).Decode(&index); err != nil {
+		return nil, fmt.Errorf("error decoding referrers index: %w", err)
+	}
+	return index, nil
+}
+
+// isAttestationMediaType checks if the media type provided corresponds to an
+// in-toto attestation (raw, wrapped in a DSSE envelope or in a sigstore
+// bundle).
+func isAttestationMediaType(mt string) bool {
+	return mt == inTotoPayloadType || mt == dsseMediaType || strings.HasPrefix(mt, bundleMediaType)
+}
+
+// parseProvenance parses the attestation provided, returning the SLSA
+// provenance it contains. Nil is returned when the attestation is not a SLSA
+// provenance one or when its subject does not match the digest provided. The
+// trust material provided is used to verify the attestation signature, which
+// is required to award build levels above 1.
+func parseProvenance(att *attestation, digest v1.Hash, tm *trustMaterial) (*hub.Provenance, error) {
+	// Extract in-toto statement
+	data := att.data
+	cert, chain := att.cert, att.chain
+	var envelope *dsseEnvelope
+	var bundle struct {
+		DSSEEnvelope         *dsseEnvelope `json:"dsseEnvelope"`
+		VerificationMaterial struct {
+			Certificate *struct {
+				RawBytes []byte `json:"rawBytes"`
+			} `json:"certificate"`
+			X509CertificateChain struct {
+				Certificates []struct {
+					RawBytes []byte `json:"rawBytes"`
+				} `json:"certificates"`
+			} `json:"x509CertificateChain"`
+		} `json:"verificationMaterial"`
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, err
+	}
+	if bundle.DSSEEnvelope != nil {
+		envelope = bundle.DSSEEnvelope
+		var certsDER [][]byte
+		if bundle.VerificationMaterial.Certificate != nil {
+			certsDER = append(certsDER, bundle.VerificationMaterial.Certificate.RawBytes)
+		}
+		for _, c := range bundle.VerificationMaterial.X509CertificateChain.Certificates {
+			certsDER = append(certsDER, c.RawBytes)
+		}
+		if len(certsDER) > 0 {
+			certs, err := parseCertificatesDER(certsDER)
+			if err != nil {
+				return nil, fmt.Errorf("invalid bundle certificate: %w", err)
+			}
+			cert, chain = certs[0], certs[1:]
+		}
+	} else if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+	var payloadType string
+	if envelope != nil && envelope.PayloadType != "" {
+		if envelope.PayloadType != inTotoPayloadType {
+			return nil, nil
+		}
+		payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid envelope payload: %w", err)
+		}
+		data = payload
+		payloadType = envelope.PayloadType
+	}
+	var st *inTotoStatement
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, err
+	}
+
+	// Check it is a SLSA provenance statement about the artifact
+	if !isSLSAPredicateType(st.PredicateType) {
+		return nil, nil
+	}
+	var subjectFound bool
+	for _, s := range st.Subject {
+		if s.Digest[digest.Algorithm] == digest.Hex {
+			subjectFound = true
+			break
+		}
+	}
+	if !subjectFound {
+		return nil, nil
+	}
+
+	// Parse predicate
+	p := &hub.Provenance{PredicateType: st.PredicateType}
+	if strings.HasSuffix(st.PredicateType, "/v1") {
+		var pred struct {
+			BuildDefinition struct {
+				ExternalParameters struct {
+					Workflow struct {
+						Repository string `json:"repository"`
+					} `json:"workflow"`
+				} `json:"externalParameters"`
+				ResolvedDependencies []struct {
+					URI    string            `json:"uri"`
+					Digest map[string]string `json:"digest"`
+				} `json:"resolvedDependencies"`
+			} `json:"buildDefinition"`
+			RunDetails struct {
+				Builder struct {
+					ID string `json:"id"`
+				} `json:"builder"`
+			} `json:"runDetails"`
+		}
+		if err := json.Unmarshal(st.Predicate, &pred); err != nil {
+			return nil, fmt.Errorf("invalid predicate: %w", err)
+		}
+		p.BuilderID = pred.RunDetails.Builder.ID
+		p.SourceRepo = pred.BuildDefinition.ExternalParameters.Workflow.Repository
+		if len(pred.BuildDefinition.ResolvedDependencies) > 0 {
+			dep := pred.BuildDefinition.ResolvedDependencies[0]
+			if p.SourceRepo == "" {
+				p.SourceRepo = dep.URI
+			}
+			p.SourceCommit = gitCommit(dep.Digest)
+		}
+	} else {
+		var pred struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+			Invocation struct {
+				ConfigSource struct {
+					URI    string            `json:"uri"`
+					Digest map[string]string `json:"digest"`
+				} `json:"configSource"`
+			} `json:"invocation"`
+			Materials []struct {
+				URI    string            `json:"uri"`
+				Digest map[string]string `json:"digest"`
+			} `json:"materials"`
+		}
+		if err := json.Unmarshal(st.Predicate, &pred); err != nil {
+			return nil, fmt.Errorf("invalid predicate: %w", err)
+		}
+		p.BuilderID = pred.Builder.ID
+		p.SourceRepo = pred.Invocation.ConfigSource.URI
+		p.SourceCommit = gitCommit(pred.Invocation.ConfigSource.Digest)
+		if p.SourceRepo == "" && len(pred.Materials) > 0 {
+			p.SourceRepo = pred.Materials[0].URI
+			p.SourceCommit = gitCommit(pred.Materials[0].Digest)
+		}
+	}
+	if p.BuilderID == "" {
+		return nil, errors.New("invalid predicate: builder id not found")
+	}
+	p.SourceRepo = normalizeSourceRepo(p.SourceRepo)
+
+	// Build level
+	p.BuildLevel = 1
+	if envelope != nil && payloadType != "" {
+		signer, issuer, err := verifyEnvelope(envelope, data, cert, chain, tm)
+		if err == nil {
+			switch {
+			case isSLSAL3Builder(p.BuilderID) && signer == p.BuilderID && issuer == githubActionsIssuer:
+				p.BuildLevel = 3
+			case isIdentityTrusted(tm.identities, signer, issuer):
+				p.BuildLevel = 2
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// verifyEnvelope verifies the signatures of the DSSE envelope provided using
+// the signing certificate provided, which must chain up to the trust root. The
+// identity of the signer and the OIDC issuer that authenticated it are
+// returned when any of the signatures is valid.
+func verifyEnvelope(
+	envelope *dsseEnvelope,
+	payload []byte,
+	cert *x509.Certificate,
+	chain []*x509.Certificate,
+	tm *trustMaterial,
+) (signer, issuer string, err error) {
+	if len(envelope.Signatures) == 0 {
+		return "", "", errors.New("attestation not signed")
+	}
+	if cert == nil {
+		return "", "", errors.New("signing certificate not found")
+	}
+	if tm == nil || tm.roots == nil {
+		return "", "", errKeylessNotConfigured
+	}
+	if err := verifyCertificate(cert, chain, tm.roots, tm.intermediates); err != nil {
+		return "", "", err
+	}
+	pae := dssePAE(envelope.PayloadType, payload)
+	for _, s := range envelope.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		if err := verifySignature(cert.PublicKey, pae, sig); err == nil {
+			return certificateIdentity(cert), certificateIssuer(cert), nil
+		}
+	}
+	return "", "", errors.New("invalid attestation signature")
+}
+
+// dssePAE returns the DSSE pre-authentication encoding of the payload
+// provided, which is the data actually signed.
+func dssePAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// isSLSAL3Builder checks if the builder id provided belongs to a builder known
+// to meet the SLSA build level 3 requirements.
+func isSLSAL3Builder(builderID string) bool {
+	for _, prefix := range slsaL3Builders {
+		if strings.HasPrefix(builderID, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSLSAPredicateType checks if the predicate type provided is a SLSA
+// provenance one.
+func isSLSAPredicateType(predicateType string) bool {
+	for _, pt := range slsaPredicateTypes {
+		if predicateType == pt {
+			return true
+		}
+	}
+	return false
+}
+
+// gitCommit returns the git commit from the digest set provided.
+func gitCommit(digest map[string]string) string {
+	if commit, ok := digest["gitCommit"]; ok {
+		return commit
+	}
+	return digest["sha1"]
+}
+
+// normalizeSourceRepo removes the git+ prefix and the ref suffix from the
+// source repository uri provided.
+func normalizeSourceRepo(uri string) string {
+	uri = strings.TrimPrefix(uri, "git+")
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return uri
+	}
+	if i := strings.LastIndex(u.Path, "@"); i != -1 {
+		u.Path = u.Path[:i]
+		u.RawPath = ""
+	}
+	return u.String()
+}
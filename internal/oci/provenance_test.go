@@ -0,0 +1,422 @@
+package oci
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sigstore/cosign/pkg/oci/empty"
+	"github.com/sigstore/cosign/pkg/oci/mutate"
+	"github.com/sigstore/cosign/pkg/oci/static"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const slsaGeneratorBuilderID = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v1.9.0"
+
+func TestGetProvenance(t *testing.T) {
+	ctx := context.Background()
+
+	// Setup registry
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	host := strings.TrimPrefix(s.URL, "http://")
+
+	// Setup keys and certificates
+	pki := newTestPKI(t)
+	builder := pki.newSigner(t, slsaGeneratorBuilderID, githubActionsIssuer)
+
+	// Helper to push an image, attaching the attestation provided (if any)
+	pushImage := func(t *testing.T, tag string, attestation func(digest v1.Hash) []byte) string {
+		t.Helper()
+		ref, err := name.ParseReference(fmt.Sprintf("%s/repo:%s", host, tag))
+		require.NoError(t, err)
+		img, err := random.Image(1024, 1)
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(ref, img))
+		if attestation == nil {
+			return ref.String()
+		}
+		digest, err := img.Digest()
+		require.NoError(t, err)
+		att, err := static.NewAttestation(
+			attestation(digest),
+			static.WithLayerMediaType(dsseMediaType),
+			static.WithCertChain(builder.certPEM, pki.caPEM),
+		)
+		require.NoError(t, err)
+		atts, err := mutate.AppendSignatures(empty.Signatures(), att)
+		require.NoError(t, err)
+		attTag, err := name.NewTag(fmt.Sprintf("%s/repo:%s-%s.att", host, digest.Algorithm, digest.Hex))
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(attTag, atts))
+		return ref.String()
+	}
+	withoutAttRef := pushImage(t, "no-attestations", nil)
+	withAttRef := pushImage(t, "attestation", func(digest v1.Hash) []byte {
+		return dsse(t, slsaV1Statement(digest), builder)
+	})
+	otherAttRef := pushImage(t, "other-attestation", func(digest v1.Hash) []byte {
+		return dsse(t, statement(digest, "https://spdx.dev/Document", `{}`), builder)
+	})
+
+	cfg := viper.New()
+	cfg.Set("tracker.cosign.trustRoot", string(pki.caPEM))
+	sc := NewSignatureChecker(cfg, nil)
+
+	t.Run("no attestations found", func(t *testing.T) {
+		p, err := sc.GetProvenance(ctx, withoutAttRef, "", "")
+		require.NoError(t, err)
+		assert.Nil(t, p)
+	})
+
+	t.Run("no provenance attestations found", func(t *testing.T) {
+		p, err := sc.GetProvenance(ctx, otherAttRef, "", "")
+		require.NoError(t, err)
+		assert.Nil(t, p)
+	})
+
+	t.Run("provenance attestation found, trust root not configured", func(t *testing.T) {
+		p, err := NewSignatureChecker(viper.New(), nil).GetProvenance(ctx, withAttRef, "", "")
+		require.NoError(t, err)
+		assert.Equal(t, 1, p.BuildLevel)
+	})
+
+	t.Run("provenance attestation found", func(t *testing.T) {
+		p, err := sc.GetProvenance(ctx, withAttRef, "", "")
+		require.NoError(t, err)
+		assert.Equal(t, &hub.Provenance{
+			PredicateType: "https://slsa.dev/provenance/v1",
+			BuilderID:     slsaGeneratorBuilderID,
+			SourceRepo:    "https://github.com/org/repo",
+			SourceCommit:  "abcdef",
+			BuildLevel:    3,
+		}, p)
+	})
+}
+
+func TestParseProvenance(t *testing.T) {
+	t.Parallel()
+	digest := v1.Hash{Algorithm: "sha256", Hex: "0123456789abcdef"}
+	otherDigest := v1.Hash{Algorithm: "sha256", Hex: "fedcba9876543210"}
+	v02Statement := statement(digest, "https://slsa.dev/provenance/v0.2", `{
+		"builder": {"id": "https://github.com/actions/runner"},
+		"invocation": {
+			"configSource": {
+				"uri": "git+https://github.com/org/repo@refs/heads/main",
+				"digest": {"sha1": "abcdef"}
+			}
+		}
+	}`)
+	v1Provenance := func(buildLevel int) *hub.Provenance {
+		return &hub.Provenance{
+			PredicateType: "https://slsa.dev/provenance/v1",
+			BuilderID:     slsaGeneratorBuilderID,
+			SourceRepo:    "https://github.com/org/repo",
+			SourceCommit:  "abcdef",
+			BuildLevel:    buildLevel,
+		}
+	}
+	v02Provenance := func(buildLevel int) *hub.Provenance {
+		return &hub.Provenance{
+			PredicateType: "https://slsa.dev/provenance/v0.2",
+			BuilderID:     "https://github.com/actions/runner",
+			SourceRepo:    "https://github.com/org/repo",
+			SourceCommit:  "abcdef",
+			BuildLevel:    buildLevel,
+		}
+	}
+
+	// Setup keys, certificates and trust material
+	pki := newTestPKI(t)
+	builder := pki.newSigner(t, slsaGeneratorBuilderID, githubActionsIssuer)
+	impersonator := pki.newSigner(t, "https://github.com/org/repo/.github/workflows/release.yml@refs/heads/main", githubActionsIssuer)
+	user := pki.newSigner(t, "user1@email.com", "https://accounts.google.com")
+	untrustedPKI := newTestPKI(t)
+	untrustedBuilder := untrustedPKI.newSigner(t, slsaGeneratorBuilderID, githubActionsIssuer)
+	roots := x509.NewCertPool()
+	roots.AddCert(pki.caCert)
+	tm := &trustMaterial{
+		roots:         roots,
+		intermediates: x509.NewCertPool(),
+		identities: []*trustedIdentity{
+			{issuer: "https://accounts.google.com", subject: regexp.MustCompile(`^(?:.*@email\.com)$`)},
+		},
+	}
+	bundle := func(envelope []byte, s *testSigner) []byte {
+		return []byte(fmt.Sprintf(
+			`{"dsseEnvelope": %s, "verificationMaterial": {"certificate": {"rawBytes": "%s"}}}`,
+			envelope, base64.StdEncoding.EncodeToString(s.cert.Raw),
+		))
+	}
+
+	testCases := []struct {
+		desc               string
+		att                *attestation
+		tm                 *trustMaterial
+		expectedProvenance *hub.Provenance
+		expectedError      string
+	}{
+		{
+			"invalid attestation",
+			&attestation{data: []byte("{")},
+			tm,
+			nil,
+			"unexpected end of JSON input",
+		},
+		{
+			"not a provenance attestation",
+			builder.attestation(dsse(t, statement(digest, "https://spdx.dev/Document", `{}`), builder)),
+			tm,
+			nil,
+			"",
+		},
+		{
+			"provenance about another artifact",
+			builder.attestation(dsse(t, slsaV1Statement(otherDigest), builder)),
+			tm,
+			nil,
+			"",
+		},
+		{
+			"builder id not provided",
+			builder.attestation(dsse(t, statement(digest, "https://slsa.dev/provenance/v0.2", `{}`), builder)),
+			tm,
+			nil,
+			"builder id not found",
+		},
+		{
+			"v1 provenance, signed by known builder",
+			builder.attestation(dsse(t, slsaV1Statement(digest), builder)),
+			tm,
+			v1Provenance(3),
+			"",
+		},
+		{
+			"v1 provenance, signed by known builder, trust root not configured",
+			builder.attestation(dsse(t, slsaV1Statement(digest), builder)),
+			&trustMaterial{},
+			v1Provenance(1),
+			"",
+		},
+		{
+			"v1 provenance, signed by known builder, certificate not trusted",
+			untrustedBuilder.attestation(dsse(t, slsaV1Statement(digest), untrustedBuilder)),
+			tm,
+			v1Provenance(1),
+			"",
+		},
+		{
+			"v1 provenance, signed by another identity claiming to be a known builder",
+			impersonator.attestation(dsse(t, slsaV1Statement(digest), impersonator)),
+			tm,
+			v1Provenance(1),
+			"",
+		},
+		{
+			"v1 provenance, invalid signature",
+			builder.attestation(dsse(t, slsaV1Statement(digest), user)),
+			tm,
+			v1Provenance(1),
+			"",
+		},
+		{
+			"v1 provenance, signing certificate not available",
+			&attestation{data: dsse(t, slsaV1Statement(digest), builder)},
+			tm,
+			v1Provenance(1),
+			"",
+		},
+		{
+			"v1 provenance in sigstore bundle, signed by known builder",
+			&attestation{data: bundle(dsse(t, slsaV1Statement(digest), builder), builder)},
+			tm,
+			v1Provenance(3),
+			"",
+		},
+		{
+			"v1 provenance in sigstore bundle, not signed",
+			&attestation{data: []byte(fmt.Sprintf(`{"dsseEnvelope": %s}`, dsse(t, slsaV1Statement(digest), nil)))},
+			tm,
+			v1Provenance(1),
+			"",
+		},
+		{
+			"v0.2 provenance, signed by trusted identity",
+			user.attestation(dsse(t, v02Statement, user)),
+			tm,
+			v02Provenance(2),
+			"",
+		},
+		{
+			"v0.2 provenance, signed by untrusted identity",
+			impersonator.attestation(dsse(t, v02Statement, impersonator)),
+			tm,
+			v02Provenance(1),
+			"",
+		},
+		{
+			"v0.2 provenance, raw statement",
+			&attestation{data: v02Statement},
+			tm,
+			v02Provenance(1),
+			"",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			p, err := parseProvenance(tc.att, digest, tc.tm)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedProvenance, p)
+		})
+	}
+}
+
+func TestNormalizeSourceRepo(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "https://github.com/org/repo", normalizeSourceRepo("git+https://github.com/org/repo@refs/tags/v1.0.0"))
+	assert.Equal(t, "https://github.com/org/repo", normalizeSourceRepo("https://github.com/org/repo"))
+	assert.Equal(t, "", normalizeSourceRepo(""))
+}
+
+// statement returns an in-toto statement about the digest provided.
+func statement(digest v1.Hash, predicateType, predicate string) []byte {
+	return []byte(fmt.Sprintf(`{
+		"_type": "https://in-toto.io/Statement/v0.1",
+		"predicateType": "%s",
+		"subject": [{"name": "artifact", "digest": {"%s": "%s"}}],
+		"predicate": %s
+	}`, predicateType, digest.Algorithm, digest.Hex, predicate))
+}
+
+// slsaV1Statement returns a SLSA v1 provenance statement about the digest
+// provided, generated by the SLSA GitHub generator.
+func slsaV1Statement(digest v1.Hash) []byte {
+	return statement(digest, "https://slsa.dev/provenance/v1", fmt.Sprintf(`{
+		"buildDefinition": {
+			"externalParameters": {
+				"workflow": {"repository": "https://github.com/org/repo"}
+			},
+			"resolvedDependencies": [
+				{"uri": "git+https://github.com/org/repo@refs/heads/main", "digest": {"gitCommit": "abcdef"}}
+			]
+		},
+		"runDetails": {
+			"builder": {"id": "%s"}
+		}
+	}`, slsaGeneratorBuilderID))
+}
+
+// testPKI represents a certificate authority used to issue signing
+// certificates in tests.
+type testPKI struct {
+	caKey  *ecdsa.PrivateKey
+	caCert *x509.Certificate
+	caPEM  []byte
+}
+
+// newTestPKI creates a new testPKI instance.
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caCert, caPEM := createCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, &caKey.PublicKey, caKey)
+	return &testPKI{caKey: caKey, caCert: caCert, caPEM: caPEM}
+}
+
+// testSigner represents a keyless signer, identified by the subject and
+// issuer of its signing certificate.
+type testSigner struct {
+	key     *ecdsa.PrivateKey
+	cert    *x509.Certificate
+	certPEM []byte
+}
+
+// newSigner issues a signing certificate for the identity and issuer provided.
+func (pki *testPKI) newSigner(t *testing.T, identity, issuer string) *testSigner {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	issuerExt, err := asn1.Marshal(issuer)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		NotBefore:       time.Now().Add(-time.Minute),
+		NotAfter:        time.Now().Add(-time.Minute).Add(10 * time.Minute),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		ExtraExtensions: []pkix.Extension{{Id: oidIssuerV2, Value: issuerExt}},
+	}
+	if strings.Contains(identity, "@") && !strings.Contains(identity, "://") {
+		template.EmailAddresses = []string{identity}
+	} else {
+		u, err := url.Parse(identity)
+		require.NoError(t, err)
+		template.URIs = []*url.URL{u}
+	}
+	cert, certPEM := createCertificate(t, template, pki.caCert, &key.PublicKey, pki.caKey)
+	return &testSigner{key: key, cert: cert, certPEM: certPEM}
+}
+
+// attestation returns an attestation with the data provided and the signer's
+// certificate attached.
+func (s *testSigner) attestation(data []byte) *attestation {
+	return &attestation{data: data, cert: s.cert}
+}
+
+// dsse wraps the statement provided in a DSSE envelope, signed by the signer
+// provided (if any).
+func dsse(t *testing.T, statement []byte, signer *testSigner) []byte {
+	t.Helper()
+	env := dsseEnvelope{
+		PayloadType: inTotoPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(statement),
+	}
+	if signer != nil {
+		hash := sha256.Sum256(dssePAE(inTotoPayloadType, statement))
+		sig, err := ecdsa.SignASN1(rand.Reader, signer.key, hash[:])
+		require.NoError(t, err)
+		env.Signatures = []dsseSignature{{Sig: base64.StdEncoding.EncodeToString(sig)}}
+	}
+	data, err := json.Marshal(env)
+	require.NoError(t, err)
+	return data
+}
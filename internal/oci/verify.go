@@ -0,0 +1,424 @@
+package oci
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	csremote "github.com/sigstore/cosign/pkg/oci/remote"
+)
+
+var (
+	// errKeylessNotConfigured indicates that a keyless signature could not be
+	// verified because no trust root has been configured.
+	errKeylessNotConfigured = errors.New("keyless signature found but no trust root configured")
+
+	// errKeylessIdentitiesNotConfigured indicates that a keyless signature
+	// could not be verified because no trusted identities have been
+	// configured.
+	errKeylessIdentitiesNotConfigured = errors.New("keyless signature found but no trusted identities configured")
+
+	// errKeylessIdentityNotTrusted indicates that a keyless signature is valid
+	// but the identity of the signer is not one of the trusted ones.
+	errKeylessIdentityNotTrusted = errors.New("keyless signature signer identity not trusted")
+
+	// errNoKeyProvided indicates that a key-based signature could not be
+	// verified because no key was provided.
+	errNoKeyProvided = errors.New("key-based signature found but no key provided")
+
+	// Fulcio certificates extensions used to get the OIDC issuer.
+	oidIssuer   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// VerifyCosignSignature verifies the cosign (sigstore) signatures of the OCI
+// artifact identified by the reference provided. Key-based signatures are
+// verified using the key provided (PEM encoded), whereas keyless signatures
+// are verified using the trust root set in the tracker.cosign.trustRoot
+// configuration entry. When the artifact is not signed, nil is returned.
+//
+// Keyless signatures are only reported as verified when the signer identity
+// (subject and OIDC issuer of the signing certificate) matches one of the
+// trusted identities set in the tracker.cosign.identities configuration
+// entry. Otherwise they are reported as unverified, as any certificate issued
+// by the trust root (i.e. Fulcio) would be accepted. The transparency log
+// (Rekor) is not checked, so the signing certificates used in keyless
+// signatures are verified as of the time they were issued.
+func (c *SignatureChecker) VerifyCosignSignature(
+	ctx context.Context,
+	ref,
+	username,
+	password string,
+	key []byte,
+) (*hub.SignatureVerification, error) {
+	// Get artifact digest and signatures
+	artifactRef, err := name.ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	options := PrepareRemoteOptions(ctx, c.cfg, artifactRef, username, password)
+	desc, err := remote.Head(artifactRef, options...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting artifact digest: %w", err)
+	}
+	digest := desc.Digest.String()
+	signatureRef, err := csremote.SignatureTag(
+		artifactRef.Context().Digest(digest),
+		csremote.WithRemoteOptions(options...),
+	)
+	if err != nil {
+		return nil, err
+	}
+	sigs, err := csremote.Signatures(signatureRef, csremote.WithRemoteOptions(options...))
+	if err != nil {
+		return nil, fmt.Errorf("error getting signatures: %w", err)
+	}
+	signatures, err := sigs.Get()
+	if err != nil {
+		return nil, fmt.Errorf("error getting signatures: %w", err)
+	}
+	if len(signatures) == 0 {
+		return nil, nil
+	}
+
+	// Prepare verification material
+	var pubKey crypto.PublicKey
+	var fingerprint string
+	if len(key) > 0 {
+		pubKey, fingerprint, err = ParsePublicKey(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	roots, intermediates, err := c.trustRoot()
+	if err != nil {
+		return nil, err
+	}
+	identities, err := c.trustedIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify signatures, stopping as soon as one of them is valid
+	var invalidErr, unverifiedErr error
+	for _, s := range signatures {
+		payload, err := s.Payload()
+		if err != nil {
+			invalidErr = err
+			continue
+		}
+		b64sig, err := s.Base64Signature()
+		if err != nil {
+			invalidErr = err
+			continue
+		}
+		cert, err := s.Cert()
+		if err != nil {
+			invalidErr = err
+			continue
+		}
+
+		// Key-based signature
+		if cert == nil {
+			if pubKey == nil {
+				unverifiedErr = errNoKeyProvided
+				continue
+			}
+			if err := VerifyCosignPayload(pubKey, payload, b64sig, digest); err != nil {
+				invalidErr = err
+				continue
+			}
+			return &hub.SignatureVerification{
+				Status:      hub.SignatureVerified,
+				Kind:        Cosign,
+				Fingerprint: fingerprint,
+			}, nil
+		}
+
+		// Keyless signature
+		if roots == nil {
+			unverifiedErr = errKeylessNotConfigured
+			continue
+		}
+		chain, err := s.Chain()
+		if err != nil {
+			invalidErr = err
+			continue
+		}
+		if err := verifyCertificate(cert, chain, roots, intermediates); err != nil {
+			invalidErr = err
+			continue
+		}
+		if err := VerifyCosignPayload(cert.PublicKey, payload, b64sig, digest); err != nil {
+			invalidErr = err
+			continue
+		}
+		signer, issuer := certificateIdentity(cert), certificateIssuer(cert)
+		switch {
+		case len(identities) == 0:
+			unverifiedErr = errKeylessIdentitiesNotConfigured
+			continue
+		case !isIdentityTrusted(identities, signer, issuer):
+			unverifiedErr = errKeylessIdentityNotTrusted
+			continue
+		}
+		return &hub.SignatureVerification{
+			Status: hub.SignatureVerified,
+			Kind:   Cosign,
+			Signer: signer,
+			Issuer: issuer,
+		}, nil
+	}
+
+	if invalidErr != nil {
+		return &hub.SignatureVerification{
+			Status: hub.SignatureInvalid,
+			Kind:   Cosign,
+			Error:  invalidErr.Error(),
+		}, nil
+	}
+	return &hub.SignatureVerification{
+		Status: hub.SignatureUnverified,
+		Kind:   Cosign,
+		Error:  unverifiedErr.Error(),
+	}, nil
+}
+
+// trustRoot returns the root and intermediate certificates set in the
+// tracker.cosign.trustRoot configuration entry. Self-signed certificates are
+// considered roots. When no trust root has been configured, nil is returned.
+func (c *SignatureChecker) trustRoot() (roots, intermediates *x509.CertPool, err error) {
+	if c.cfg == nil || c.cfg.GetString("tracker.cosign.trustRoot") == "" {
+		return nil, nil, nil
+	}
+	certs, err := parseCertificates([]byte(c.cfg.GetString("tracker.cosign.trustRoot")))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid cosign trust root: %w", err)
+	}
+	roots, intermediates = x509.NewCertPool(), x509.NewCertPool()
+	for _, cert := range certs {
+		if cert.CheckSignatureFrom(cert) == nil {
+			roots.AddCert(cert)
+		} else {
+			intermediates.AddCert(cert)
+		}
+	}
+	return roots, intermediates, nil
+}
+
+// trustedIdentity represents an identity trusted to sign artifacts using
+// keyless signatures.
+type trustedIdentity struct {
+	issuer  string
+	subject *regexp.Regexp
+}
+
+// trustedIdentities returns the identities set in the tracker.cosign.identities
+// configuration entry. Each of them must provide the OIDC issuer and a regular
+// expression the signer identity (certificate subject) must match.
+func (c *SignatureChecker) trustedIdentities() ([]*trustedIdentity, error) {
+	if c.cfg == nil {
+		return nil, nil
+	}
+	var entries []struct {
+		Issuer  string `mapstructure:"issuer"`
+		Subject string `mapstructure:"subject"`
+	}
+	if err := c.cfg.UnmarshalKey("tracker.cosign.identities", &entries); err != nil {
+		return nil, fmt.Errorf("invalid cosign identities: %w", err)
+	}
+	identities := make([]*trustedIdentity, 0, len(entries))
+	for _, e := range entries {
+		if e.Issuer == "" || e.Subject == "" {
+			return nil, errors.New("invalid cosign identities: issuer and subject must be provided")
+		}
+		subject, err := regexp.Compile("^(?:" + e.Subject + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid cosign identities: %w", err)
+		}
+		identities = append(identities, &trustedIdentity{
+			issuer:  e.Issuer,
+			subject: subject,
+		})
+	}
+	return identities, nil
+}
+
+// isIdentityTrusted checks if the signer and issuer provided match any of the
+// trusted identities.
+func isIdentityTrusted(identities []*trustedIdentity, signer, issuer string) bool {
+	for _, id := range identities {
+		if id.issuer == issuer && id.subject.MatchString(signer) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyCertificate verifies that the signing certificate provided chains up
+// to one of the roots provided. As the transparency log is not checked, the
+// certificate is verified as of the time it was issued.
+func verifyCertificate(cert *x509.Certificate, chain []*x509.Certificate, roots, intermediates *x509.CertPool) error {
+	pool := intermediates.Clone()
+	for _, c := range chain {
+		pool.AddCert(c)
+	}
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: pool,
+		CurrentTime:   cert.NotBefore,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+	if err != nil {
+		return fmt.Errorf("invalid signing certificate: %w", err)
+	}
+	return nil
+}
+
+// certificateIdentity returns the identity of the signer from the subject
+// alternative name of the signing certificate provided.
+func certificateIdentity(cert *x509.Certificate) string {
+	switch {
+	case len(cert.EmailAddresses) > 0:
+		return cert.EmailAddresses[0]
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	}
+	return ""
+}
+
+// certificateIssuer returns the OIDC issuer that authenticated the signer from
+// the signing certificate provided.
+func certificateIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidIssuerV2):
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+		case ext.Id.Equal(oidIssuer):
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
+
+// parseCertificates parses the PEM encoded certificates provided.
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM encoded certificates found")
+	}
+	return certs, nil
+}
+
+// parseCertificatesDER parses the DER encoded certificates provided.
+func parseCertificatesDER(data [][]byte) ([]*x509.Certificate, error) {
+	certs := make([]*x509.Certificate, 0, len(data))
+	for _, der := range data {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// ParsePublicKey parses the PEM encoded public key provided, returning the
+// key and its fingerprint (sha256 of the DER encoded key).
+func ParsePublicKey(data []byte) (crypto.PublicKey, string, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, "", errors.New("invalid key: PEM encoded public key expected")
+	}
+	pubKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid key: %w", err)
+	}
+	sum := sha256.Sum256(block.Bytes)
+	return pubKey, strings.ToUpper(hex.EncodeToString(sum[:])), nil
+}
+
+// VerifyCosignPayload verifies the cosign signature of the payload provided,
+// checking as well that the payload signed refers to the digest provided.
+func VerifyCosignPayload(pubKey crypto.PublicKey, payload []byte, b64sig, digest string) error {
+	sig, err := base64.StdEncoding.DecodeString(b64sig)
+	if err != nil {
+		return err
+	}
+	if err := verifySignature(pubKey, payload, sig); err != nil {
+		return err
+	}
+
+	// Check the payload signed refers to the artifact being verified
+	var p struct {
+		Critical struct {
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+	if p.Critical.Image.DockerManifestDigest != digest {
+		return errors.New("signed payload digest mismatch")
+	}
+	return nil
+}
+
+// verifySignature verifies the signature of the data provided using the
+// public key provided.
+func verifySignature(pubKey crypto.PublicKey, data, sig []byte) error {
+	hash := sha256.Sum256(data)
+	switch k := pubKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, hash[:], sig) {
+			return errors.New("invalid signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, hash[:], sig); err != nil {
+			return err
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, data, sig) {
+			return errors.New("invalid signature")
+		}
+	default:
+		return errors.New("unsupported key type")
+	}
+	return nil
+}
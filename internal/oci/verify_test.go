@@ -0,0 +1,262 @@
+package oci
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sigstore/cosign/pkg/oci/empty"
+	"github.com/sigstore/cosign/pkg/oci/mutate"
+	"github.com/sigstore/cosign/pkg/oci/static"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyCosignSignature(t *testing.T) {
+	ctx := context.Background()
+
+	// Setup registry
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	host := strings.TrimPrefix(s.URL, "http://")
+
+	// Setup keys and certificates
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	pubKey := encodePublicKey(t, &privKey.PublicKey)
+	_, fingerprint, err := ParsePublicKey(pubKey)
+	require.NoError(t, err)
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caCert, caPEM := createCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, &caKey.PublicKey, caKey)
+	issuerExt, err := asn1.Marshal("https://accounts.google.com")
+	require.NoError(t, err)
+	_, signerPEM := createCertificate(t, &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		NotBefore:       time.Now().Add(-time.Minute),
+		NotAfter:        time.Now().Add(-time.Minute).Add(10 * time.Minute),
+		EmailAddresses:  []string{"user1@email.com"},
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		ExtraExtensions: []pkix.Extension{{Id: oidIssuerV2, Value: issuerExt}},
+	}, caCert, &privKey.PublicKey, caKey)
+
+	// Helper to push an image, signing it when requested
+	pushImage := func(t *testing.T, tag string, sign func(payload []byte) (string, []static.Option)) string {
+		t.Helper()
+		ref, err := name.ParseReference(fmt.Sprintf("%s/repo:%s", host, tag))
+		require.NoError(t, err)
+		img, err := random.Image(1024, 1)
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(ref, img))
+		if sign == nil {
+			return ref.String()
+		}
+		digest, err := img.Digest()
+		require.NoError(t, err)
+		payload := []byte(fmt.Sprintf(
+			`{"critical":{"identity":{"docker-reference":"%s/repo"},"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"},"optional":null}`,
+			host, digest,
+		))
+		b64sig, opts := sign(payload)
+		sig, err := static.NewSignature(payload, b64sig, opts...)
+		require.NoError(t, err)
+		sigs, err := mutate.AppendSignatures(empty.Signatures(), sig)
+		require.NoError(t, err)
+		sigTag, err := name.NewTag(fmt.Sprintf("%s/repo:%s-%s.sig", host, digest.Algorithm, digest.Hex))
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(sigTag, sigs))
+		return ref.String()
+	}
+	signWithKey := func(payload []byte) (string, []static.Option) {
+		hash := sha256.Sum256(payload)
+		sig, err := ecdsa.SignASN1(rand.Reader, privKey, hash[:])
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(sig), nil
+	}
+	signKeyless := func(payload []byte) (string, []static.Option) {
+		b64sig, _ := signWithKey(payload)
+		return b64sig, []static.Option{static.WithCertChain(signerPEM, caPEM)}
+	}
+	keyRef := pushImage(t, "key", signWithKey)
+	keylessRef := pushImage(t, "keyless", signKeyless)
+	unsignedRef := pushImage(t, "unsigned", nil)
+
+	// Setup signature checkers
+	sc := NewSignatureChecker(viper.New(), nil)
+	cfg := viper.New()
+	cfg.Set("tracker.cosign.trustRoot", string(caPEM))
+	scWithTrustRoot := NewSignatureChecker(cfg, nil)
+	cfg = viper.New()
+	cfg.Set("tracker.cosign.trustRoot", string(caPEM))
+	cfg.Set("tracker.cosign.identities", []map[string]string{
+		{"issuer": "https://accounts.google.com", "subject": `.*@email\.com`},
+	})
+	scWithIdentities := NewSignatureChecker(cfg, nil)
+
+	t.Run("unsigned artifact", func(t *testing.T) {
+		v, err := sc.VerifyCosignSignature(ctx, unsignedRef, "", "", pubKey)
+		require.NoError(t, err)
+		assert.Nil(t, v)
+	})
+
+	t.Run("key-based signature verified", func(t *testing.T) {
+		v, err := sc.VerifyCosignSignature(ctx, keyRef, "", "", pubKey)
+		require.NoError(t, err)
+		assert.Equal(t, &hub.SignatureVerification{
+			Status:      hub.SignatureVerified,
+			Kind:        Cosign,
+			Fingerprint: fingerprint,
+		}, v)
+	})
+
+	t.Run("key-based signature, no key provided", func(t *testing.T) {
+		v, err := sc.VerifyCosignSignature(ctx, keyRef, "", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, &hub.SignatureVerification{
+			Status: hub.SignatureUnverified,
+			Kind:   Cosign,
+			Error:  errNoKeyProvided.Error(),
+		}, v)
+	})
+
+	t.Run("key-based signature, different key provided", func(t *testing.T) {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		v, err := sc.VerifyCosignSignature(ctx, keyRef, "", "", encodePublicKey(t, &otherKey.PublicKey))
+		require.NoError(t, err)
+		assert.Equal(t, hub.SignatureInvalid, v.Status)
+	})
+
+	t.Run("keyless signature, no trust root configured", func(t *testing.T) {
+		v, err := sc.VerifyCosignSignature(ctx, keylessRef, "", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, &hub.SignatureVerification{
+			Status: hub.SignatureUnverified,
+			Kind:   Cosign,
+			Error:  errKeylessNotConfigured.Error(),
+		}, v)
+	})
+
+	t.Run("keyless signature, no trusted identities configured", func(t *testing.T) {
+		v, err := scWithTrustRoot.VerifyCosignSignature(ctx, keylessRef, "", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, &hub.SignatureVerification{
+			Status: hub.SignatureUnverified,
+			Kind:   Cosign,
+			Error:  errKeylessIdentitiesNotConfigured.Error(),
+		}, v)
+	})
+
+	t.Run("keyless signature, signer identity not trusted", func(t *testing.T) {
+		cfg := viper.New()
+		cfg.Set("tracker.cosign.trustRoot", string(caPEM))
+		cfg.Set("tracker.cosign.identities", []map[string]string{
+			{"issuer": "https://github.com/login/oauth", "subject": "user1@email.com"},
+		})
+		v, err := NewSignatureChecker(cfg, nil).VerifyCosignSignature(ctx, keylessRef, "", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, &hub.SignatureVerification{
+			Status: hub.SignatureUnverified,
+			Kind:   Cosign,
+			Error:  errKeylessIdentityNotTrusted.Error(),
+		}, v)
+	})
+
+	t.Run("keyless signature verified", func(t *testing.T) {
+		v, err := scWithIdentities.VerifyCosignSignature(ctx, keylessRef, "", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, &hub.SignatureVerification{
+			Status: hub.SignatureVerified,
+			Kind:   Cosign,
+			Signer: "user1@email.com",
+			Issuer: "https://accounts.google.com",
+		}, v)
+	})
+
+	t.Run("keyless signature, untrusted certificate", func(t *testing.T) {
+		otherCAKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		_, otherCAPEM := createCertificate(t, &x509.Certificate{
+			SerialNumber:          big.NewInt(3),
+			Subject:               pkix.Name{CommonName: "other root"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}, nil, &otherCAKey.PublicKey, otherCAKey)
+		cfg := viper.New()
+		cfg.Set("tracker.cosign.trustRoot", string(otherCAPEM))
+		v, err := NewSignatureChecker(cfg, nil).VerifyCosignSignature(ctx, keylessRef, "", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, hub.SignatureInvalid, v.Status)
+		assert.Contains(t, v.Error, "invalid signing certificate")
+	})
+}
+
+func TestCertificateIdentity(t *testing.T) {
+	t.Parallel()
+	u, _ := url.Parse("https://github.com/org/repo/.github/workflows/release.yml@refs/heads/main")
+	assert.Equal(t, "user1@email.com", certificateIdentity(&x509.Certificate{EmailAddresses: []string{"user1@email.com"}}))
+	assert.Equal(t, u.String(), certificateIdentity(&x509.Certificate{URIs: []*url.URL{u}}))
+	assert.Equal(t, "", certificateIdentity(&x509.Certificate{}))
+}
+
+// encodePublicKey returns the PEM encoded public key provided.
+func encodePublicKey(t *testing.T, pubKey crypto.PublicKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pubKey)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// createCertificate creates a certificate from the template provided, signed
+// by the parent certificate (self-signed when parent is nil).
+func createCertificate(
+	t *testing.T,
+	template, parent *x509.Certificate,
+	pubKey crypto.PublicKey,
+	signerKey crypto.Signer,
+) (*x509.Certificate, []byte) {
+	t.Helper()
+	if parent == nil {
+		parent = template
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pubKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
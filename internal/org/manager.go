@@ -30,7 +30,7 @@ const (
 	deleteOrgMemberDBQ   = `select delete_organization_member($1::uuid, $2::text, $3::text)`
 	getAuthzPolicyDBQ    = `select get_authorization_policy($1::uuid, $2::text)`
 	getOrgDBQ            = `select get_organization($1::text)`
-	getOrgMembersDBQ     = `select * from get_organization_members($1::uuid, $2::text, $3::int, $4::int)`
+	getOrgMembersDBQ     = `select * from get_organization_members($1::uuid, $2::text, $3::int, $4::int, $5::jsonb)`
 	getUserAliasDBQ      = `select alias from "user" where user_id = $1`
 	getUserEmailDBQ      = `select email from "user" where alias = $1`
 	getUserOrgsDBQ       = `select * from get_user_organizations($1::uuid, $2::int, $3::int)`
@@ -50,6 +50,16 @@ var invitationEmailTmpl string
 // organizationNameRE is a regexp used to validate an organization name.
 var organizationNameRE = regexp.MustCompile(`^[a-z0-9-]+$`)
 
+// membersCursor represents the key of the cursor used to paginate the members
+// of an organization.
+type membersCursor struct {
+	FirstNameNull bool   `json:"first_name_null"`
+	FirstNameKey  string `json:"first_name_key"`
+	LastNameNull  bool   `json:"last_name_null"`
+	LastNameKey   string `json:"last_name_key"`
+	Alias         string `json:"alias"`
+}
+
 // Manager provides an API to manage organizations.
 type Manager struct {
 	cfg  *viper.Viper
@@ -319,9 +329,13 @@ func (m *Manager) GetMembersJSON(
 	if orgName == "" {
 		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "organization name not provided")
 	}
+	cursorKey, err := util.DecodeCursor(p.Cursor, &membersCursor{})
+	if err != nil {
+		return nil, err
+	}
 
 	// Get organization members from database
-	return util.DBQueryJSONWithPagination(ctx, m.db, getOrgMembersDBQ, userID, orgName, p.Limit, p.Offset)
+	return util.DBQueryJSONWithCursor(ctx, m.db, getOrgMembersDBQ, userID, orgName, p.Limit, p.Offset, cursorKey)
 }
 
 // Update updates the provided organization in the database.
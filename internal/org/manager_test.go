@@ -736,7 +736,7 @@ func TestGetMembersJSON(t *testing.T) {
 	t.Run("database query succeeded", func(t *testing.T) {
 		t.Parallel()
 		db := &tests.DBMock{}
-		db.On("QueryRow", ctx, getOrgMembersDBQ, "userID", "orgName", 10, 1).
+		db.On("QueryRow", ctx, getOrgMembersDBQ, "userID", "orgName", 10, 1, []byte(nil)).
 			Return([]interface{}{[]byte("dataJSON"), 1}, nil)
 		m := NewManager(cfg, db, nil, nil)
 
@@ -766,7 +766,7 @@ func TestGetMembersJSON(t *testing.T) {
 			t.Run(tc.dbErr.Error(), func(t *testing.T) {
 				t.Parallel()
 				db := &tests.DBMock{}
-				db.On("QueryRow", ctx, getOrgMembersDBQ, "userID", "orgName", 10, 1).Return(nil, tc.dbErr)
+				db.On("QueryRow", ctx, getOrgMembersDBQ, "userID", "orgName", 10, 1, []byte(nil)).Return(nil, tc.dbErr)
 				m := NewManager(cfg, db, nil, nil)
 
 				result, err := m.GetMembersJSON(ctx, "orgName", p)
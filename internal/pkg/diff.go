@@ -0,0 +1,298 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// rawManifestKey represents the key used to store the raw manifest of the
+	// package in the package's data (Tekton tasks and pipelines and Krew
+	// plugins).
+	rawManifestKey = "manifestRaw"
+)
+
+// DiffVersion represents the information about a package version used to
+// compare it with another version.
+type DiffVersion struct {
+	Pkg          *hub.Package
+	Values       map[string]interface{}
+	ValuesSchema []byte
+	Templates    map[string]string
+}
+
+// Diff returns the differences between the two package versions provided.
+// The changelog entries of the versions released after the from version, up
+// to the to version, are included as well.
+func Diff(from, to *DiffVersion, changelog hub.Changelog) (*hub.PackageDiff, error) {
+	d := &hub.PackageDiff{
+		From:             from.Pkg.Version,
+		To:               to.Pkg.Version,
+		Values:           DiffValues(from.Values, to.Values),
+		Templates:        DiffFiles(from.Templates, to.Templates),
+		CRDs:             diffCRDs(from.Pkg.CRDs, to.Pkg.CRDs),
+		ContainersImages: diffContainersImages(from.Pkg.ContainersImages, to.Pkg.ContainersImages),
+		Changes:          changesBetween(changelog, from.Pkg, to.Pkg),
+	}
+
+	// Values schema
+	fromSchema, err := unmarshalValuesSchema(from.ValuesSchema)
+	if err != nil {
+		return nil, err
+	}
+	toSchema, err := unmarshalValuesSchema(to.ValuesSchema)
+	if err != nil {
+		return nil, err
+	}
+	d.ValuesSchema = DiffValues(fromSchema, toSchema)
+
+	// Manifest
+	fromManifest, toManifest := storedManifest(from.Pkg), storedManifest(to.Pkg)
+	if fromManifest != "" || toManifest != "" {
+		files := DiffFiles(
+			map[string]string{"manifest": fromManifest},
+			map[string]string{"manifest": toManifest},
+		)
+		if len(files) > 0 {
+			d.Manifest = files[0]
+		}
+	}
+
+	return d, nil
+}
+
+// storedManifest returns the manifest stored for the package provided. OLM
+// operators don't store their CSV, so their manifest is built from the
+// operator information stored instead (capabilities, CRDs examples and the
+// operator data, like the install modes supported).
+func storedManifest(p *hub.Package) string {
+	if manifest, ok := p.Data[rawManifestKey].(string); ok {
+		return manifest
+	}
+	if p.Repository == nil || p.Repository.Kind != hub.OLM {
+		return ""
+	}
+	manifest, err := yaml.Marshal(map[string]interface{}{
+		"capabilities": p.Capabilities,
+		"crdsExamples": p.CRDsExamples,
+		"data":         p.Data,
+	})
+	if err != nil {
+		return ""
+	}
+	return string(manifest)
+}
+
+// DiffValues returns the differences between the two values trees provided.
+// Nested objects are compared recursively, whereas any other value (arrays
+// included) is compared as a whole. Changes are sorted by path.
+func DiffValues(from, to map[string]interface{}) []*hub.ValueChange {
+	changes := make([]*hub.ValueChange, 0)
+	diffValues("", from, to, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// diffValues compares the values trees provided, appending the changes found
+// to the list of changes provided.
+func diffValues(prefix string, from, to map[string]interface{}, changes *[]*hub.ValueChange) {
+	for key, fromValue := range from {
+		path := joinValuePath(prefix, key)
+		toValue, ok := to[key]
+		if !ok {
+			*changes = append(*changes, &hub.ValueChange{Path: path, Kind: hub.DiffRemoved, From: fromValue})
+			continue
+		}
+		fromMap, fromIsMap := fromValue.(map[string]interface{})
+		toMap, toIsMap := toValue.(map[string]interface{})
+		switch {
+		case fromIsMap && toIsMap:
+			diffValues(path, fromMap, toMap, changes)
+		case !reflect.DeepEqual(fromValue, toValue):
+			*changes = append(*changes, &hub.ValueChange{
+				Path: path,
+				Kind: hub.DiffModified,
+				From: fromValue,
+				To:   toValue,
+			})
+		}
+	}
+	for key, toValue := range to {
+		if _, ok := from[key]; !ok {
+			*changes = append(*changes, &hub.ValueChange{
+				Path: joinValuePath(prefix, key),
+				Kind: hub.DiffAdded,
+				To:   toValue,
+			})
+		}
+	}
+}
+
+// joinValuePath appends the key provided to the values path prefix. Keys
+// containing dots are quoted to keep the path unambiguous.
+func joinValuePath(prefix, key string) string {
+	if strings.Contains(key, ".") {
+		return fmt.Sprintf("%s[%q]", prefix, key)
+	}
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// DiffFiles returns the differences between the two sets of files provided
+// (indexed by name). Files with the same content in both sets are omitted.
+func DiffFiles(from, to map[string]string) []*hub.FileChange {
+	names := make(map[string]struct{}, len(from)+len(to))
+	for name := range from {
+		names[name] = struct{}{}
+	}
+	for name := range to {
+		names[name] = struct{}{}
+	}
+	changes := make([]*hub.FileChange, 0)
+	for name := range names {
+		fromData, inFrom := from[name]
+		toData, inTo := to[name]
+		var kind string
+		switch {
+		case !inFrom:
+			kind = hub.DiffAdded
+		case !inTo:
+			kind = hub.DiffRemoved
+		case fromData != toData:
+			kind = hub.DiffModified
+		default:
+			continue
+		}
+		diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(fromData),
+			B:        difflib.SplitLines(toData),
+			FromFile: name,
+			ToFile:   name,
+			Context:  3,
+		})
+		changes = append(changes, &hub.FileChange{
+			Name: name,
+			Kind: kind,
+			Diff: diff,
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// diffCRDs returns the differences between the two lists of CRDs provided.
+// CRDs are identified by their name (or kind when no name is available).
+func diffCRDs(from, to []interface{}) []*hub.ValueChange {
+	index := func(crds []interface{}) map[string]interface{} {
+		m := make(map[string]interface{}, len(crds))
+		for i, crd := range crds {
+			id := fmt.Sprintf("%d", i)
+			if fields, ok := crd.(map[string]interface{}); ok {
+				if name, ok := fields["name"].(string); ok && name != "" {
+					id = name
+				} else if kind, ok := fields["kind"].(string); ok && kind != "" {
+					id = kind
+				}
+			}
+			m[id] = crd
+		}
+		return m
+	}
+	return diffIndexed(index(from), index(to))
+}
+
+// diffContainersImages returns the differences between the two lists of
+// containers images provided. Images are identified by their name when
+// available, or by their repository otherwise, so that a tag update is
+// reported as a modification.
+func diffContainersImages(from, to []*hub.ContainerImage) []*hub.ValueChange {
+	index := func(images []*hub.ContainerImage) map[string]interface{} {
+		m := make(map[string]interface{}, len(images))
+		for _, image := range images {
+			id := image.Name
+			if id == "" {
+				id = image.Image
+				if ref, err := name.ParseReference(image.Image); err == nil {
+					id = ref.Context().String()
+				}
+			}
+			m[id] = image.Image
+		}
+		return m
+	}
+	return diffIndexed(index(from), index(to))
+}
+
+// diffIndexed returns the differences between the two sets of items provided
+// (indexed by id). Changes are sorted by id.
+func diffIndexed(from, to map[string]interface{}) []*hub.ValueChange {
+	changes := make([]*hub.ValueChange, 0)
+	for id, fromItem := range from {
+		toItem, ok := to[id]
+		switch {
+		case !ok:
+			changes = append(changes, &hub.ValueChange{Path: id, Kind: hub.DiffRemoved, From: fromItem})
+		case !reflect.DeepEqual(fromItem, toItem):
+			changes = append(changes, &hub.ValueChange{Path: id, Kind: hub.DiffModified, From: fromItem, To: toItem})
+		}
+	}
+	for id, toItem := range to {
+		if _, ok := from[id]; !ok {
+			changes = append(changes, &hub.ValueChange{Path: id, Kind: hub.DiffAdded, To: toItem})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// changesBetween returns the changelog entries of the versions released after
+// the from version, up to the to version (included). Versions are compared
+// using semver when possible, falling back to the release date otherwise.
+func changesBetween(changelog hub.Changelog, from, to *hub.Package) hub.Changelog {
+	fromSV, fromErr := semver.NewVersion(from.Version)
+	toSV, toErr := semver.NewVersion(to.Version)
+	changes := make(hub.Changelog, 0)
+	for _, entry := range changelog {
+		sv, err := semver.NewVersion(entry.Version)
+		if fromErr == nil && toErr == nil && err == nil {
+			if sv.GreaterThan(fromSV) && !sv.GreaterThan(toSV) {
+				changes = append(changes, entry)
+			}
+			continue
+		}
+		if entry.TS > from.TS && entry.TS <= to.TS {
+			changes = append(changes, entry)
+		}
+	}
+	return changes
+}
+
+// unmarshalValuesSchema unmarshals the values schema provided. When no schema
+// is provided, nil is returned.
+func unmarshalValuesSchema(data []byte) (map[string]interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid values schema: %w", err)
+	}
+	return schema, nil
+}
@@ -0,0 +1,203 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid values schema", func(t *testing.T) {
+		t.Parallel()
+		from := &DiffVersion{Pkg: &hub.Package{Version: "1.0.0"}, ValuesSchema: []byte("{")}
+		to := &DiffVersion{Pkg: &hub.Package{Version: "1.1.0"}}
+		d, err := Diff(from, to, nil)
+		assert.ErrorContains(t, err, "invalid values schema")
+		assert.Nil(t, d)
+	})
+
+	t.Run("diff succeeded", func(t *testing.T) {
+		t.Parallel()
+		from := &DiffVersion{
+			Pkg: &hub.Package{
+				Version: "1.0.0",
+				CRDs: []interface{}{
+					map[string]interface{}{"name": "crd1.example.com", "version": "v1"},
+					map[string]interface{}{"name": "crd2.example.com", "version": "v1"},
+				},
+				ContainersImages: []*hub.ContainerImage{
+					{Image: "registry.io/image1:1.0.0"},
+					{Name: "tool", Image: "registry.io/tool:1.0.0"},
+				},
+			},
+			Values: map[string]interface{}{
+				"image": map[string]interface{}{
+					"tag":        "1.0.0",
+					"pullPolicy": "IfNotPresent",
+				},
+				"replicas": 1,
+				"annotations": map[string]interface{}{
+					"example.com/key": "value1",
+				},
+			},
+			ValuesSchema: []byte(`{"type": "object"}`),
+			Templates: map[string]string{
+				"templates/deployment.yaml": "kind: Deployment\nreplicas: 1\n",
+				"templates/service.yaml":    "kind: Service\n",
+				"templates/old.yaml":        "kind: ConfigMap\n",
+			},
+		}
+		to := &DiffVersion{
+			Pkg: &hub.Package{
+				Version: "1.1.0",
+				CRDs: []interface{}{
+					map[string]interface{}{"name": "crd1.example.com", "version": "v2"},
+				},
+				ContainersImages: []*hub.ContainerImage{
+					{Image: "registry.io/image1:1.1.0"},
+					{Name: "tool", Image: "registry.io/tool:1.0.0"},
+				},
+			},
+			Values: map[string]interface{}{
+				"image": map[string]interface{}{
+					"tag":        "1.1.0",
+					"pullPolicy": "IfNotPresent",
+				},
+				"annotations": map[string]interface{}{
+					"example.com/key": "value2",
+				},
+				"resources": map[string]interface{}{},
+			},
+			ValuesSchema: []byte(`{"type": "object", "required": ["image"]}`),
+			Templates: map[string]string{
+				"templates/deployment.yaml": "kind: Deployment\nreplicas: 2\n",
+				"templates/service.yaml":    "kind: Service\n",
+				"templates/new.yaml":        "kind: Secret\n",
+			},
+		}
+		changelog := hub.Changelog{
+			{Version: "2.0.0"},
+			{Version: "1.1.0"},
+			{Version: "1.0.1"},
+			{Version: "1.0.0"},
+		}
+		d, err := Diff(from, to, changelog)
+		require.NoError(t, err)
+
+		assert.Equal(t, "1.0.0", d.From)
+		assert.Equal(t, "1.1.0", d.To)
+		assert.Equal(t, []*hub.ValueChange{
+			{Path: `annotations["example.com/key"]`, Kind: hub.DiffModified, From: "value1", To: "value2"},
+			{Path: "image.tag", Kind: hub.DiffModified, From: "1.0.0", To: "1.1.0"},
+			{Path: "replicas", Kind: hub.DiffRemoved, From: 1},
+			{Path: "resources", Kind: hub.DiffAdded, To: map[string]interface{}{}},
+		}, d.Values)
+		assert.Equal(t, []*hub.ValueChange{
+			{Path: "required", Kind: hub.DiffAdded, To: []interface{}{"image"}},
+		}, d.ValuesSchema)
+		require.Len(t, d.Templates, 3)
+		assert.Equal(t, "templates/deployment.yaml", d.Templates[0].Name)
+		assert.Equal(t, hub.DiffModified, d.Templates[0].Kind)
+		assert.Contains(t, d.Templates[0].Diff, "-replicas: 1\n+replicas: 2\n")
+		assert.Equal(t, "templates/new.yaml", d.Templates[1].Name)
+		assert.Equal(t, hub.DiffAdded, d.Templates[1].Kind)
+		assert.Equal(t, "templates/old.yaml", d.Templates[2].Name)
+		assert.Equal(t, hub.DiffRemoved, d.Templates[2].Kind)
+		assert.Nil(t, d.Manifest)
+		assert.Equal(t, []*hub.ValueChange{
+			{
+				Path: "crd1.example.com",
+				Kind: hub.DiffModified,
+				From: map[string]interface{}{"name": "crd1.example.com", "version": "v1"},
+				To:   map[string]interface{}{"name": "crd1.example.com", "version": "v2"},
+			},
+			{
+				Path: "crd2.example.com",
+				Kind: hub.DiffRemoved,
+				From: map[string]interface{}{"name": "crd2.example.com", "version": "v1"},
+			},
+		}, d.CRDs)
+		assert.Equal(t, []*hub.ValueChange{
+			{
+				Path: "registry.io/image1",
+				Kind: hub.DiffModified,
+				From: "registry.io/image1:1.0.0",
+				To:   "registry.io/image1:1.1.0",
+			},
+		}, d.ContainersImages)
+		assert.Equal(t, hub.Changelog{
+			{Version: "1.1.0"},
+			{Version: "1.0.1"},
+		}, d.Changes)
+	})
+
+	t.Run("diff using stored manifests and release dates", func(t *testing.T) {
+		t.Parallel()
+		from := &DiffVersion{
+			Pkg: &hub.Package{
+				Version: "first",
+				TS:      10,
+				Data:    map[string]interface{}{rawManifestKey: "kind: Task\nstep: a\n"},
+			},
+		}
+		to := &DiffVersion{
+			Pkg: &hub.Package{
+				Version: "second",
+				TS:      20,
+				Data:    map[string]interface{}{rawManifestKey: "kind: Task\nstep: b\n"},
+			},
+		}
+		changelog := hub.Changelog{
+			{Version: "third", TS: 30},
+			{Version: "second", TS: 20},
+			{Version: "first", TS: 10},
+		}
+		d, err := Diff(from, to, changelog)
+		require.NoError(t, err)
+
+		require.NotNil(t, d.Manifest)
+		assert.Equal(t, hub.DiffModified, d.Manifest.Kind)
+		assert.Contains(t, d.Manifest.Diff, "-step: a\n+step: b\n")
+		assert.Empty(t, d.Values)
+		assert.Empty(t, d.Templates)
+		assert.Equal(t, hub.Changelog{{Version: "second", TS: 20}}, d.Changes)
+	})
+
+	t.Run("diff using the stored olm operators data", func(t *testing.T) {
+		t.Parallel()
+		from := &DiffVersion{
+			Pkg: &hub.Package{
+				Version:      "1.0.0",
+				Capabilities: "Basic Install",
+				CRDsExamples: []interface{}{
+					map[string]interface{}{"kind": "EtcdCluster", "spec": map[string]interface{}{"size": 3}},
+				},
+				Data:       map[string]interface{}{"isGlobalOperator": false},
+				Repository: &hub.Repository{Kind: hub.OLM},
+			},
+		}
+		to := &DiffVersion{
+			Pkg: &hub.Package{
+				Version:      "1.1.0",
+				Capabilities: "Full Lifecycle",
+				CRDsExamples: []interface{}{
+					map[string]interface{}{"kind": "EtcdCluster", "spec": map[string]interface{}{"size": 5}},
+				},
+				Data:       map[string]interface{}{"isGlobalOperator": true},
+				Repository: &hub.Repository{Kind: hub.OLM},
+			},
+		}
+		d, err := Diff(from, to, nil)
+		require.NoError(t, err)
+
+		require.NotNil(t, d.Manifest)
+		assert.Equal(t, hub.DiffModified, d.Manifest.Kind)
+		assert.Contains(t, d.Manifest.Diff, "-capabilities: Basic Install\n+capabilities: Full Lifecycle\n")
+		assert.Contains(t, d.Manifest.Diff, "-    size: 3\n+    size: 5\n")
+		assert.Contains(t, d.Manifest.Diff, "-  isGlobalOperator: false\n+  isGlobalOperator: true\n")
+	})
+}
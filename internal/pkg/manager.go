@@ -12,6 +12,7 @@ import (
 	"github.com/Masterminds/semver/v3"
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/util"
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/satori/uuid"
 )
 
@@ -22,13 +23,17 @@ const (
 	getHarborReplicationDumpDBQ     = `select get_harbor_replication_dump()`
 	getHelmExporterDumpDBQ          = `select get_helm_exporter_dump()`
 	getPkgDBQ                       = `select get_package($1::jsonb)`
+	getPkgsByImageDBQ               = `select * from get_packages_by_image($1::jsonb)`
 	getPkgChangelogDBQ              = `select get_package_changelog($1::uuid)`
+	getPkgDependenciesDBQ           = `select get_package_dependencies($1::uuid, $2::text)`
+	getPkgDependentsDBQ             = `select * from get_package_dependents($1::jsonb)`
 	getPkgStarsDBQ                  = `select get_package_stars($1::uuid, $2::uuid)`
 	getPkgSummaryDBQ                = `select get_package_summary($1::jsonb)`
 	getPkgViewsDBQ                  = `select get_package_views($1::uuid, $2::date, $3::date)`
-	getPkgsStarredByUserDBQ         = `select * from get_packages_starred_by_user($1::uuid, $2::int, $3::int)`
+	getPkgsStarredByUserDBQ         = `select * from get_packages_starred_by_user($1::uuid, $2::int, $3::int, $4::jsonb)`
 	getPkgsStatsDBQ                 = `select get_packages_stats()`
 	getProductionUsageDBQ           = `select get_production_usage($1::uuid, $2::text, $3::text)`
+	getSnapshotProvenanceDBQ        = `select provenance from snapshot where package_id = $1 and version = $2`
 	getSnapshotSecurityReportDBQ    = `select security_report from snapshot where package_id = $1 and version = $2`
 	getSnapshotsToScanDBQ           = `select get_snapshots_to_scan()`
 	getRandomPkgsDBQ                = `select get_random_packages()`
@@ -49,8 +54,34 @@ var (
 		"deep insights",
 		"auto pilot",
 	}
+	validSeverities = []string{
+		"none",
+		"low",
+		"medium",
+		"high",
+		"critical",
+	}
 )
 
+// searchCursor represents the key of the cursor used to paginate the packages
+// search results. The sort used is included, as the sort keys depend on it.
+type searchCursor struct {
+	Sort              string    `json:"sort"`
+	SortKey1          float64   `json:"sort_key_1"`
+	SortKey2          float64   `json:"sort_key_2"`
+	Official          bool      `json:"official"`
+	VerifiedPublisher bool      `json:"verified_publisher"`
+	Name              string    `json:"name"`
+	PackageID         uuid.UUID `json:"package_id"`
+}
+
+// starredCursor represents the key of the cursor used to paginate the
+// packages starred by a user.
+type starredCursor struct {
+	Name      string    `json:"name"`
+	PackageID uuid.UUID `json:"package_id"`
+}
+
 // Manager provides an API to manage packages.
 type Manager struct {
 	db hub.DB
@@ -92,6 +123,31 @@ func (m *Manager) Get(ctx context.Context, input *hub.GetPackageInput) (*hub.Pac
 	return p, nil
 }
 
+// GetByImageJSON returns a json array with the packages versions that use the
+// container image provided. The json array is built by the database.
+func (m *Manager) GetByImageJSON(
+	ctx context.Context,
+	input *hub.GetPackagesByImageInput,
+) (*hub.JSONQueryResult, error) {
+	// Validate input
+	if input.Image == "" {
+		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "image not provided")
+	}
+	if _, err := name.ParseReference(input.Image); err != nil {
+		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid image")
+	}
+	if input.Limit <= 0 || input.Limit > 60 {
+		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid limit (0 < l <= 60)")
+	}
+	if input.Offset < 0 {
+		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid offset (o >= 0)")
+	}
+
+	// Get packages from database
+	inputJSON, _ := json.Marshal(input)
+	return util.DBQueryJSONWithPagination(ctx, m.db, getPkgsByImageDBQ, inputJSON)
+}
+
 // GetChangelog returns the changelog for the package identified by the id
 // provided.
 func (m *Manager) GetChangelog(ctx context.Context, pkgID string) (*hub.Changelog, error) {
@@ -108,6 +164,49 @@ func (m *Manager) GetChangelog(ctx context.Context, pkgID string) (*hub.Changelo
 	return changelog, err
 }
 
+// GetDependenciesJSON returns the dependencies of the package's snapshot
+// identified by the package id and version provided. Dependencies linked to
+// other packages in the hub include some information about them.
+func (m *Manager) GetDependenciesJSON(ctx context.Context, pkgID, version string) ([]byte, error) {
+	// Validate input
+	if _, err := uuid.FromString(pkgID); err != nil {
+		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid package id")
+	}
+	if version == "" {
+		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "version not provided")
+	}
+
+	// Get dependencies from database
+	return util.DBQueryJSON(ctx, m.db, getPkgDependenciesDBQ, pkgID, version)
+}
+
+// GetDependentsJSON returns the packages that depend on the package provided.
+// Only the latest version of the dependent packages is considered.
+func (m *Manager) GetDependentsJSON(
+	ctx context.Context,
+	input *hub.GetPackageDependentsInput,
+) (*hub.JSONQueryResult, error) {
+	// Validate input
+	if _, err := uuid.FromString(input.PackageID); err != nil {
+		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid package id")
+	}
+	if input.VersionRange != "" {
+		if _, err := semver.NewConstraint(input.VersionRange); err != nil {
+			return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid version range")
+		}
+	}
+	if input.Limit <= 0 || input.Limit > 60 {
+		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid limit (0 < l <= 60)")
+	}
+	if input.Offset < 0 {
+		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid offset (o >= 0)")
+	}
+
+	// Get dependents from database
+	inputJSON, _ := json.Marshal(input)
+	return util.DBQueryJSONWithPagination(ctx, m.db, getPkgDependentsDBQ, inputJSON)
+}
+
 // GetHarborReplicationDumpJSON returns a json list with all packages versions
 // of kind Helm available so that they can be synchronized in Harbor.
 func (m *Manager) GetHarborReplicationDumpJSON(ctx context.Context) ([]byte, error) {
@@ -147,6 +246,12 @@ func (m *Manager) GetRandomJSON(ctx context.Context) ([]byte, error) {
 	return util.DBQueryJSON(ctx, m.db, getRandomPkgsDBQ)
 }
 
+// GetSnapshotProvenanceJSON returns the SLSA provenance of the package's
+// snapshot identified by the package id and version provided.
+func (m *Manager) GetSnapshotProvenanceJSON(ctx context.Context, pkgID, version string) ([]byte, error) {
+	return util.DBQueryJSON(ctx, m.db, getSnapshotProvenanceDBQ, pkgID, version)
+}
+
 // GetSnapshotSecurityReportJSON returns the security report of the package's
 // snapshot identified by the package id and version provided.
 func (m *Manager) GetSnapshotSecurityReportJSON(ctx context.Context, pkgID, version string) ([]byte, error) {
@@ -165,7 +270,11 @@ func (m *Manager) GetSnapshotsToScan(ctx context.Context) ([]*hub.SnapshotToScan
 // doing the request. The json object is built by the database.
 func (m *Manager) GetStarredByUserJSON(ctx context.Context, p *hub.Pagination) (*hub.JSONQueryResult, error) {
 	userID := ctx.Value(hub.UserIDKey).(string)
-	return util.DBQueryJSONWithPagination(ctx, m.db, getPkgsStarredByUserDBQ, userID, p.Limit, p.Offset)
+	cursorKey, err := util.DecodeCursor(p.Cursor, &starredCursor{})
+	if err != nil {
+		return nil, err
+	}
+	return util.DBQueryJSONWithCursor(ctx, m.db, getPkgsStarredByUserDBQ, userID, p.Limit, p.Offset, cursorKey)
 }
 
 // GetStarsJSON returns the number of stars of the given package, indicating as
@@ -323,10 +432,46 @@ func (m *Manager) SearchJSON(ctx context.Context, input *hub.SearchPackageInput)
 			return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid repository name")
 		}
 	}
+	if input.Image != "" {
+		if _, err := name.ParseReference(input.Image); err != nil {
+			return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid image")
+		}
+	}
+	if input.MaxSeverity != "" && !isValidSeverity(input.MaxSeverity) {
+		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid max severity (none|low|medium|high|critical)")
+	}
+	if input.UpdatedWithin < 0 {
+		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid updated within (d >= 0)")
+	}
+	for _, helmType := range input.HelmTypes {
+		if helmType != "application" && helmType != "library" {
+			return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid helm type (application|library)")
+		}
+	}
+	var cursor searchCursor
+	cursorKey, err := util.DecodeCursor(input.Cursor, &cursor)
+	if err != nil {
+		return nil, err
+	}
+	if cursorKey != nil {
+		sort := input.Sort
+		if sort == "" {
+			sort = "relevance"
+		}
+		if cursor.Sort != sort {
+			return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "cursor does not match the sort provided")
+		}
+	}
 
 	// Search packages in database
-	inputJSON, _ := json.Marshal(input)
-	return util.DBQueryJSONWithPagination(ctx, m.db, searchPkgsDBQ, inputJSON)
+	inputJSON, _ := json.Marshal(struct {
+		*hub.SearchPackageInput
+		Cursor json.RawMessage `json:"cursor,omitempty"`
+	}{
+		SearchPackageInput: input,
+		Cursor:             cursorKey,
+	})
+	return util.DBQueryJSONWithCursor(ctx, m.db, searchPkgsDBQ, inputJSON)
 }
 
 // SearchMonocularJSON returns a json object with the search results produced
@@ -425,3 +570,13 @@ func areValidCapabilities(capabilities string) bool {
 	}
 	return false
 }
+
+// isValidSeverity checks if the provided severity is valid.
+func isValidSeverity(severity string) bool {
+	for _, validOption := range validSeverities {
+		if severity == validOption {
+			return true
+		}
+	}
+	return false
+}
@@ -10,6 +10,7 @@ import (
 	trivy "github.com/aquasecurity/trivy/pkg/types"
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/tests"
+	"github.com/artifacthub/hub/internal/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -341,6 +342,94 @@ func TestGet(t *testing.T) {
 	})
 }
 
+func TestGetByImageJSON(t *testing.T) {
+	ctx := context.Background()
+	input := &hub.GetPackagesByImageInput{
+		Image:      "quay.io/org/img",
+		LatestOnly: true,
+		Limit:      10,
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []struct {
+			errMsg string
+			input  *hub.GetPackagesByImageInput
+		}{
+			{
+				"image not provided",
+				&hub.GetPackagesByImageInput{
+					Limit: 10,
+				},
+			},
+			{
+				"invalid image",
+				&hub.GetPackagesByImageInput{
+					Image: "quay.io/org/img:invalid:tag",
+					Limit: 10,
+				},
+			},
+			{
+				"invalid limit (0 < l <= 60)",
+				&hub.GetPackagesByImageInput{
+					Image: "quay.io/org/img",
+					Limit: 0,
+				},
+			},
+			{
+				"invalid limit (0 < l <= 60)",
+				&hub.GetPackagesByImageInput{
+					Image: "quay.io/org/img",
+					Limit: 100,
+				},
+			},
+			{
+				"invalid offset (o >= 0)",
+				&hub.GetPackagesByImageInput{
+					Image:  "quay.io/org/img",
+					Limit:  10,
+					Offset: -1,
+				},
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.errMsg, func(t *testing.T) {
+				t.Parallel()
+				m := NewManager(nil)
+				result, err := m.GetByImageJSON(ctx, tc.input)
+				assert.True(t, errors.Is(err, hub.ErrInvalidInput))
+				assert.Contains(t, err.Error(), tc.errMsg)
+				assert.Nil(t, result)
+			})
+		}
+	})
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getPkgsByImageDBQ, mock.Anything).Return([]interface{}{[]byte("dataJSON"), 1}, nil)
+		m := NewManager(db)
+
+		result, err := m.GetByImageJSON(ctx, input)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), result.Data)
+		assert.Equal(t, 1, result.TotalCount)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getPkgsByImageDBQ, mock.Anything).Return(nil, tests.ErrFakeDB)
+		m := NewManager(db)
+
+		result, err := m.GetByImageJSON(ctx, input)
+		assert.Equal(t, tests.ErrFakeDB, err)
+		assert.Nil(t, result)
+		db.AssertExpectations(t)
+	})
+}
+
 func TestGetChangelog(t *testing.T) {
 	ctx := context.Background()
 
@@ -467,6 +556,148 @@ func TestGetChangelog(t *testing.T) {
 	})
 }
 
+func TestGetDependenciesJSON(t *testing.T) {
+	ctx := context.Background()
+	pkgID := "00000000-0000-0000-0000-000000000001"
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []struct {
+			errMsg  string
+			pkgID   string
+			version string
+		}{
+			{
+				"invalid package id",
+				"invalid",
+				"1.0.0",
+			},
+			{
+				"version not provided",
+				pkgID,
+				"",
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.errMsg, func(t *testing.T) {
+				t.Parallel()
+				m := NewManager(nil)
+				dataJSON, err := m.GetDependenciesJSON(ctx, tc.pkgID, tc.version)
+				assert.True(t, errors.Is(err, hub.ErrInvalidInput))
+				assert.Contains(t, err.Error(), tc.errMsg)
+				assert.Nil(t, dataJSON)
+			})
+		}
+	})
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getPkgDependenciesDBQ, pkgID, "1.0.0").Return([]byte("dataJSON"), nil)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetDependenciesJSON(ctx, pkgID, "1.0.0")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getPkgDependenciesDBQ, pkgID, "1.0.0").Return(nil, tests.ErrFakeDB)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetDependenciesJSON(ctx, pkgID, "1.0.0")
+		assert.Equal(t, tests.ErrFakeDB, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetDependentsJSON(t *testing.T) {
+	ctx := context.Background()
+	input := &hub.GetPackageDependentsInput{
+		PackageID:    "00000000-0000-0000-0000-000000000001",
+		VersionRange: "< 2.0.0",
+		Limit:        10,
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []struct {
+			errMsg string
+			input  *hub.GetPackageDependentsInput
+		}{
+			{
+				"invalid package id",
+				&hub.GetPackageDependentsInput{
+					PackageID: "invalid",
+					Limit:     10,
+				},
+			},
+			{
+				"invalid version range",
+				&hub.GetPackageDependentsInput{
+					PackageID:    "00000000-0000-0000-0000-000000000001",
+					VersionRange: "invalid",
+					Limit:        10,
+				},
+			},
+			{
+				"invalid limit (0 < l <= 60)",
+				&hub.GetPackageDependentsInput{
+					PackageID: "00000000-0000-0000-0000-000000000001",
+					Limit:     100,
+				},
+			},
+			{
+				"invalid offset (o >= 0)",
+				&hub.GetPackageDependentsInput{
+					PackageID: "00000000-0000-0000-0000-000000000001",
+					Limit:     10,
+					Offset:    -1,
+				},
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.errMsg, func(t *testing.T) {
+				t.Parallel()
+				m := NewManager(nil)
+				result, err := m.GetDependentsJSON(ctx, tc.input)
+				assert.True(t, errors.Is(err, hub.ErrInvalidInput))
+				assert.Contains(t, err.Error(), tc.errMsg)
+				assert.Nil(t, result)
+			})
+		}
+	})
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getPkgDependentsDBQ, mock.Anything).Return([]interface{}{[]byte("dataJSON"), 1}, nil)
+		m := NewManager(db)
+
+		result, err := m.GetDependentsJSON(ctx, input)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), result.Data)
+		assert.Equal(t, 1, result.TotalCount)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getPkgDependentsDBQ, mock.Anything).Return(nil, tests.ErrFakeDB)
+		m := NewManager(db)
+
+		result, err := m.GetDependentsJSON(ctx, input)
+		assert.Equal(t, tests.ErrFakeDB, err)
+		assert.Nil(t, result)
+		db.AssertExpectations(t)
+	})
+}
+
 func TestGetHarborReplicationDumpJSON(t *testing.T) {
 	ctx := context.Background()
 
@@ -630,6 +861,34 @@ func TestGetRandomJSON(t *testing.T) {
 	})
 }
 
+func TestGetSnapshotProvenanceJSON(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getSnapshotProvenanceDBQ, "pkg1", "1.0.0").Return([]byte("dataJSON"), nil)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetSnapshotProvenanceJSON(ctx, "pkg1", "1.0.0")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getSnapshotProvenanceDBQ, "pkg1", "1.0.0").Return(nil, tests.ErrFakeDB)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetSnapshotProvenanceJSON(ctx, "pkg1", "1.0.0")
+		assert.Equal(t, tests.ErrFakeDB, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+}
+
 func TestGetSnapshotSecurityReportJSON(t *testing.T) {
 	ctx := context.Background()
 
@@ -719,7 +978,7 @@ func TestGetStarredByUserJSON(t *testing.T) {
 	t.Run("database query succeeded", func(t *testing.T) {
 		t.Parallel()
 		db := &tests.DBMock{}
-		db.On("QueryRow", ctx, getPkgsStarredByUserDBQ, "userID", 10, 1).
+		db.On("QueryRow", ctx, getPkgsStarredByUserDBQ, "userID", 10, 1, []byte(nil)).
 			Return([]interface{}{[]byte("dataJSON"), 1}, nil)
 		m := NewManager(db)
 
@@ -733,7 +992,7 @@ func TestGetStarredByUserJSON(t *testing.T) {
 	t.Run("database error", func(t *testing.T) {
 		t.Parallel()
 		db := &tests.DBMock{}
-		db.On("QueryRow", ctx, getPkgsStarredByUserDBQ, "userID", 10, 1).Return(nil, tests.ErrFakeDB)
+		db.On("QueryRow", ctx, getPkgsStarredByUserDBQ, "userID", 10, 1, []byte(nil)).Return(nil, tests.ErrFakeDB)
 		m := NewManager(db)
 
 		result, err := m.GetStarredByUserJSON(ctx, p)
@@ -1198,6 +1457,56 @@ func TestSearchJSON(t *testing.T) {
 					Repositories: []string{""},
 				},
 			},
+			{
+				"invalid image",
+				&hub.SearchPackageInput{
+					Limit: 10,
+					Image: "quay.io/org/img:invalid:tag",
+				},
+			},
+			{
+				"invalid max severity (none|low|medium|high|critical)",
+				&hub.SearchPackageInput{
+					Limit:       10,
+					MaxSeverity: "invalid",
+				},
+			},
+			{
+				"invalid updated within (d >= 0)",
+				&hub.SearchPackageInput{
+					Limit:         10,
+					UpdatedWithin: -1,
+				},
+			},
+			{
+				"invalid helm type (application|library)",
+				&hub.SearchPackageInput{
+					Limit:     10,
+					HelmTypes: []string{"invalid"},
+				},
+			},
+			{
+				"invalid cursor",
+				&hub.SearchPackageInput{
+					Limit:  10,
+					Cursor: "invalid!",
+				},
+			},
+			{
+				"invalid cursor",
+				&hub.SearchPackageInput{
+					Limit:  10,
+					Cursor: util.EncodeCursor([]byte(`{"name": "pkg1"}`)),
+				},
+			},
+			{
+				"cursor does not match the sort provided",
+				&hub.SearchPackageInput{
+					Limit:  10,
+					Sort:   "stars",
+					Cursor: util.EncodeCursor([]byte(`{"sort": "relevance", "sort_key_1": 1, "sort_key_2": 0, "official": false, "verified_publisher": true, "name": "pkg1", "package_id": "00000000-0000-0000-0000-000000000001"}`)),
+				},
+			},
 		}
 		for _, tc := range testCases {
 			tc := tc
@@ -1225,6 +1534,27 @@ func TestSearchJSON(t *testing.T) {
 		db.AssertExpectations(t)
 	})
 
+	t.Run("database query using a cursor succeeded", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, searchPkgsDBQ, mock.MatchedBy(func(inputJSON []byte) bool {
+			var input map[string]interface{}
+			_ = json.Unmarshal(inputJSON, &input)
+			return assert.ObjectsAreEqual("pkg1", input["cursor"].(map[string]interface{})["name"])
+		})).Return([]interface{}{[]byte("dataJSON"), 1, []byte(`{"name": "pkg2"}`)}, nil)
+		m := NewManager(db)
+
+		result, err := m.SearchJSON(ctx, &hub.SearchPackageInput{
+			Limit:  10,
+			Cursor: util.EncodeCursor([]byte(`{"sort": "relevance", "sort_key_1": 1, "sort_key_2": 0, "official": false, "verified_publisher": true, "name": "pkg1", "package_id": "00000000-0000-0000-0000-000000000001"}`)),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), result.Data)
+		assert.Equal(t, 1, result.TotalCount)
+		assert.Equal(t, util.EncodeCursor([]byte(`{"name": "pkg2"}`)), result.NextCursor)
+		db.AssertExpectations(t)
+	})
+
 	t.Run("database error", func(t *testing.T) {
 		t.Parallel()
 		db := &tests.DBMock{}
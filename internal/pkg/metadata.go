@@ -29,6 +29,13 @@ var (
 	// ErrInvalidMetadata indicates that the metadata provided is not valid.
 	ErrInvalidMetadata = errors.New("invalid metadata")
 
+	// ErrFieldNotProvided indicates that a required field was not provided.
+	ErrFieldNotProvided = errors.New("field not provided")
+
+	// ErrInvalidValue indicates that the value provided for a field is not
+	// valid.
+	ErrInvalidValue = errors.New("invalid value")
+
 	// validChangeKinds is the list of valid kinds that a pkg change can use.
 	validChangeKinds = []string{
 		"added",
@@ -116,41 +123,75 @@ func ValidatePackageMetadata(kind hub.RepositoryKind, md *hub.PackageMetadata) e
 	var errs *multierror.Error
 
 	if md.Version == "" {
-		errs = multierror.Append(errs, fmt.Errorf("%w: %s", ErrInvalidMetadata, "version not provided"))
+		errs = multierror.Append(errs, NewValidationError(ErrFieldNotProvided, "%w: %s", ErrInvalidMetadata, "version not provided"))
 	} else if _, err := semver.NewVersion(md.Version); err != nil {
-		errs = multierror.Append(errs, fmt.Errorf("%w: %s: %v", ErrInvalidMetadata, "invalid version (semver expected)", err))
+		errs = multierror.Append(errs, NewValidationError(ErrInvalidValue, "%w: %s: %v", ErrInvalidMetadata, "invalid version (semver expected)", err))
 	}
 	if md.Name == "" {
-		errs = multierror.Append(errs, fmt.Errorf("%w: %s", ErrInvalidMetadata, "name not provided"))
+		errs = multierror.Append(errs, NewValidationError(ErrFieldNotProvided, "%w: %s", ErrInvalidMetadata, "name not provided"))
 	}
 	if md.DisplayName == "" {
-		errs = multierror.Append(errs, fmt.Errorf("%w: %s", ErrInvalidMetadata, "display name not provided"))
+		errs = multierror.Append(errs, NewValidationError(ErrFieldNotProvided, "%w: %s", ErrInvalidMetadata, "display name not provided"))
 	}
 	if md.CreatedAt == "" {
-		errs = multierror.Append(errs, fmt.Errorf("%w: %s", ErrInvalidMetadata, "createdAt not provided"))
+		errs = multierror.Append(errs, NewValidationError(ErrFieldNotProvided, "%w: %s", ErrInvalidMetadata, "createdAt not provided"))
 	} else if _, err := time.Parse(time.RFC3339, md.CreatedAt); err != nil {
-		errs = multierror.Append(errs, fmt.Errorf("%w: %s: %v", ErrInvalidMetadata, "invalid createdAt (RFC3339 expected)", err))
+		errs = multierror.Append(errs, NewValidationError(ErrInvalidValue, "%w: %s: %v", ErrInvalidMetadata, "invalid createdAt (RFC3339 expected)", err))
 	}
 	if md.Description == "" {
-		errs = multierror.Append(errs, fmt.Errorf("%w: %s", ErrInvalidMetadata, "description not provided"))
+		errs = multierror.Append(errs, NewValidationError(ErrFieldNotProvided, "%w: %s", ErrInvalidMetadata, "description not provided"))
 	}
 	for _, maintainer := range md.Maintainers {
 		if maintainer.Email == "" {
-			errs = multierror.Append(errs, fmt.Errorf("%w: %s", ErrInvalidMetadata, "maintainer email not provided"))
+			errs = multierror.Append(errs, NewValidationError(ErrFieldNotProvided, "%w: %s", ErrInvalidMetadata, "maintainer email not provided"))
 		}
 	}
 	for _, change := range md.Changes {
 		if err := ValidateChange(change); err != nil {
-			errs = multierror.Append(errs, fmt.Errorf("%w: %v", ErrInvalidMetadata, err))
+			errs = multierror.Append(errs, NewValidationError(ErrInvalidValue, "%w: %v", ErrInvalidMetadata, err))
 		}
 	}
 	if err := ValidateContainersImages(kind, md.ContainersImages); err != nil {
-		errs = multierror.Append(errs, fmt.Errorf("%w: %v", ErrInvalidMetadata, err))
+		errs = multierror.Append(errs, NewValidationError(ErrInvalidValue, "%w: %v", ErrInvalidMetadata, err))
 	}
 
 	return errs.ErrorOrNil()
 }
 
+// ValidationError represents an error found while validating some package
+// data. It keeps the message of the error it wraps, but allows callers to find
+// out using errors.Is if a field was not provided (ErrFieldNotProvided) or if
+// its value is not valid (ErrInvalidValue).
+type ValidationError struct {
+	kind error
+	err  error
+}
+
+// NewValidationError creates a new validation error of the kind provided. The
+// wrapped error is built from the format and arguments provided, as it would
+// be done by fmt.Errorf.
+func NewValidationError(kind error, format string, a ...interface{}) error {
+	return &ValidationError{
+		kind: kind,
+		err:  fmt.Errorf(format, a...),
+	}
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the error wrapped by the validation error.
+func (e *ValidationError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether the validation error is of the kind provided.
+func (e *ValidationError) Is(target error) bool {
+	return target == e.kind
+}
+
 // ValidateChange validates if the provided change is valid.
 func ValidateChange(change *hub.Change) error {
 	var errs *multierror.Error
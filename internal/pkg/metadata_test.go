@@ -7,7 +7,9 @@ import (
 	"testing"
 
 	"github.com/artifacthub/hub/internal/hub"
+	"github.com/hashicorp/go-multierror"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetPackageMetadata(t *testing.T) {
@@ -525,6 +527,24 @@ func TestValidatePackageMetadata(t *testing.T) {
 		}
 	})
 
+	t.Run("validation errors can be classified", func(t *testing.T) {
+		t.Parallel()
+		err := ValidatePackageMetadata(hub.Helm, &hub.PackageMetadata{
+			Version:   "1.0.0",
+			Name:      "pkg1",
+			CreatedAt: "invalid",
+		})
+		var merr *multierror.Error
+		require.True(t, errors.As(err, &merr))
+		require.Len(t, merr.Errors, 3)
+		assert.True(t, errors.Is(merr.Errors[0], ErrFieldNotProvided))
+		assert.True(t, errors.Is(merr.Errors[1], ErrInvalidValue))
+		assert.True(t, errors.Is(merr.Errors[2], ErrFieldNotProvided))
+		for _, err := range merr.Errors {
+			assert.True(t, errors.Is(err, ErrInvalidMetadata))
+		}
+	})
+
 	t.Run("valid metadata", func(t *testing.T) {
 		testCases := []struct {
 			kind hub.RepositoryKind
@@ -31,6 +31,16 @@ func (m *ManagerMock) Get(ctx context.Context, input *hub.GetPackageInput) (*hub
 	return data, args.Error(1)
 }
 
+// GetByImageJSON implements the PackageManager interface.
+func (m *ManagerMock) GetByImageJSON(
+	ctx context.Context,
+	input *hub.GetPackagesByImageInput,
+) (*hub.JSONQueryResult, error) {
+	args := m.Called(ctx, input)
+	data, _ := args.Get(0).(*hub.JSONQueryResult)
+	return data, args.Error(1)
+}
+
 // GetChangelog implements the PackageManager interface.
 func (m *ManagerMock) GetChangelog(ctx context.Context, pkgID string) (*hub.Changelog, error) {
 	args := m.Called(ctx, pkgID)
@@ -38,6 +48,23 @@ func (m *ManagerMock) GetChangelog(ctx context.Context, pkgID string) (*hub.Chan
 	return data, args.Error(1)
 }
 
+// GetDependenciesJSON implements the PackageManager interface.
+func (m *ManagerMock) GetDependenciesJSON(ctx context.Context, pkgID, version string) ([]byte, error) {
+	args := m.Called(ctx, pkgID, version)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
+// GetDependentsJSON implements the PackageManager interface.
+func (m *ManagerMock) GetDependentsJSON(
+	ctx context.Context,
+	input *hub.GetPackageDependentsInput,
+) (*hub.JSONQueryResult, error) {
+	args := m.Called(ctx, input)
+	data, _ := args.Get(0).(*hub.JSONQueryResult)
+	return data, args.Error(1)
+}
+
 // GetHarborReplicationDumpJSON implements the PackageManager interface.
 func (m *ManagerMock) GetHarborReplicationDumpJSON(ctx context.Context) ([]byte, error) {
 	args := m.Called(ctx)
@@ -73,6 +100,13 @@ func (m *ManagerMock) GetRandomJSON(ctx context.Context) ([]byte, error) {
 	return data, args.Error(1)
 }
 
+// GetSnapshotProvenanceJSON implements the PackageManager interface.
+func (m *ManagerMock) GetSnapshotProvenanceJSON(ctx context.Context, pkgID, version string) ([]byte, error) {
+	args := m.Called(ctx, pkgID, version)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
 // GetSnapshotSecurityReportJSON implements the PackageManager interface.
 func (m *ManagerMock) GetSnapshotSecurityReportJSON(ctx context.Context, pkgID, version string) ([]byte, error) {
 	args := m.Called(ctx, pkgID, version)
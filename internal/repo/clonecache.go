@@ -0,0 +1,450 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// pinnedRefName represents the name of the reference used to keep the
+	// pinned commits fetched in the cached clones.
+	pinnedRefName = "refs/artifacthub/pinned"
+)
+
+var (
+	// errRemoteURLChanged indicates that the remote url of a cached clone
+	// does not match the one of the repository anymore.
+	errRemoteURLChanged = errors.New("remote url has changed")
+)
+
+// CloneCache is an on-disk cache of git repositories clones, keyed by the
+// repository id. Cached clones are updated incrementally before being copied
+// to the destination path requested, and the least recently used ones are
+// evicted when the cache grows over the maximum size configured.
+type CloneCache struct {
+	dir     string
+	maxSize int64
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex // K: repository id
+	inUse map[string]int         // K: repository id
+}
+
+// NewCloneCache creates a new CloneCache instance that will store the clones
+// in the directory provided.
+func NewCloneCache(dir string, maxSize int64) (*CloneCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("error creating clone cache directory: %w", err)
+	}
+	return &CloneCache{
+		dir:     dir,
+		maxSize: maxSize,
+		locks:   make(map[string]*sync.Mutex),
+		inUse:   make(map[string]int),
+	}, nil
+}
+
+// Checkout updates the cached clone of the repository provided (cloning it if
+// it is not available yet), checks out the revision requested and copies the
+// resulting working tree (including the git directory) to the destination
+// path. Corrupted clones are discarded and cloned again from scratch.
+func (c *CloneCache) Checkout(
+	ctx context.Context,
+	r *hub.Repository,
+	remoteURL string,
+	auth transport.AuthMethod,
+	refs []*plumbing.Reference,
+	ref *GitRef,
+	dst string,
+) error {
+	if r.RepositoryID == "" || strings.ContainsAny(r.RepositoryID, `/\.`) {
+		return fmt.Errorf("invalid repository id: %s", r.RepositoryID)
+	}
+	unlock := c.lock(r.RepositoryID)
+	defer unlock()
+
+	// Update cached clone, cloning it again from scratch if needed
+	path := filepath.Join(c.dir, r.RepositoryID)
+	err := c.update(ctx, path, remoteURL, auth, refs, ref)
+	if err != nil {
+		// Keep the cached clone if the update was interrupted
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !errors.Is(err, git.ErrRepositoryNotExists) {
+			log.Warn().Err(err).Str("repoID", r.RepositoryID).Msg("discarding cached clone")
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("error removing cached clone: %w", err)
+		}
+		if err := c.clone(ctx, path, remoteURL, auth, ref); err != nil {
+			_ = os.RemoveAll(path)
+			return err
+		}
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	// Copy working tree to the destination path
+	if err := copyClone(path, dst); err != nil {
+		return fmt.Errorf("error copying cached clone: %w", err)
+	}
+
+	// Evict least recently used clones if needed (this one is still in use)
+	c.evict()
+	return nil
+}
+
+// update fetches the latest changes into the cached clone located at the path
+// provided and checks out the revision requested.
+func (c *CloneCache) update(
+	ctx context.Context,
+	path string,
+	remoteURL string,
+	auth transport.AuthMethod,
+	refs []*plumbing.Reference,
+	ref *GitRef,
+) error {
+	gr, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+	remote, err := gr.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return err
+	}
+	if urls := remote.Config().URLs; len(urls) != 1 || urls[0] != remoteURL {
+		return errRemoteURLChanged
+	}
+	if err := fetch(ctx, gr, auth, ref); err != nil {
+		return err
+	}
+	if err := pruneTags(gr, refs); err != nil {
+		return fmt.Errorf("error pruning tags: %w", err)
+	}
+	return checkoutRef(gr, ref)
+}
+
+// clone clones the repository into the path provided and checks out the
+// revision requested.
+func (c *CloneCache) clone(
+	ctx context.Context,
+	path string,
+	remoteURL string,
+	auth transport.AuthMethod,
+	ref *GitRef,
+) error {
+	gr, err := git.PlainInit(path, false)
+	if err != nil {
+		return fmt.Errorf("error initializing repository: %w", err)
+	}
+	if _, err := gr.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{remoteURL},
+	}); err != nil {
+		return fmt.Errorf("error creating remote: %w", err)
+	}
+	if err := fetch(ctx, gr, auth, ref); err != nil {
+		return err
+	}
+	return checkoutRef(gr, ref)
+}
+
+// fetch fetches the branches and tags available in the remote into the git
+// repository provided. When the revision requested is not a branch or a tag
+// (i.e. a pinned commit or a custom ref), it is fetched explicitly as well, so
+// that it is available even if it can't be reached from any of them.
+func fetch(ctx context.Context, gr *git.Repository, auth transport.AuthMethod, ref *GitRef) error {
+	refSpecs := []config.RefSpec{"+refs/heads/*:refs/remotes/origin/*"}
+	switch {
+	case ref.Name == "":
+		refSpecs = append(refSpecs, config.RefSpec(ref.Hash.String()+":"+pinnedRefName))
+	case !ref.Name.IsBranch() && !ref.Name.IsTag():
+		refSpecs = append(refSpecs, config.RefSpec("+"+ref.Name+":"+ref.Name))
+	}
+	err := gr.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: refSpecs,
+		Auth:     auth,
+		Tags:     git.AllTags,
+		Force:    true,
+	})
+	if errors.Is(err, git.ErrExactSHA1NotSupported) {
+		err = gr.FetchContext(ctx, &git.FetchOptions{
+			RefSpecs: refSpecs[:1],
+			Auth:     auth,
+			Tags:     git.AllTags,
+			Force:    true,
+		})
+	}
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("error fetching changes: %w", err)
+	}
+	return nil
+}
+
+// lock acquires the lock of the cache entry of the repository provided,
+// marking it as in use so that it's not evicted. The function returned must
+// be called to release it.
+func (c *CloneCache) lock(repositoryID string) func() {
+	c.mu.Lock()
+	l, ok := c.locks[repositoryID]
+	if !ok {
+		l = &sync.Mutex{}
+		c.locks[repositoryID] = l
+	}
+	c.inUse[repositoryID]++
+	c.mu.Unlock()
+
+	l.Lock()
+	return c.unlockFunc(repositoryID, l)
+}
+
+// tryLock acquires the lock of the cache entry of the repository provided
+// only if it is not in use. The function returned must be called to release
+// it.
+func (c *CloneCache) tryLock(repositoryID string) (func(), bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.inUse[repositoryID]; ok {
+		return nil, false
+	}
+
+	// Entries not in use don't have any holders or waiters, so the lock can be
+	// acquired without blocking
+	l := &sync.Mutex{}
+	c.locks[repositoryID] = l
+	c.inUse[repositoryID]++
+	l.Lock()
+	return c.unlockFunc(repositoryID, l), true
+}
+
+// unlockFunc returns a function that releases the lock provided. Locks that
+// are not in use anymore are removed.
+func (c *CloneCache) unlockFunc(repositoryID string, l *sync.Mutex) func() {
+	return func() {
+		l.Unlock()
+		c.mu.Lock()
+		c.inUse[repositoryID]--
+		if c.inUse[repositoryID] == 0 {
+			delete(c.inUse, repositoryID)
+			delete(c.locks, repositoryID)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// evict removes the least recently used cache entries until the cache size is
+// below the maximum size configured. Entries in use are never evicted.
+func (c *CloneCache) evict() {
+	if c.maxSize <= 0 {
+		return
+	}
+
+	// Collect cache entries information (the cache isn't locked while doing
+	// it, so entries may be in use at this point)
+	type entry struct {
+		repositoryID string
+		path         string
+		size         int64
+		lastUsed     time.Time
+	}
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		log.Warn().Err(err).Msg("error reading clone cache directory")
+		return
+	}
+	var entries []*entry
+	var total int64
+	for _, de := range dirEntries {
+		if !de.IsDir() {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(c.dir, de.Name())
+		size := dirSize(path)
+		total += size
+		entries = append(entries, &entry{
+			repositoryID: de.Name(),
+			path:         path,
+			size:         size,
+			lastUsed:     info.ModTime(),
+		})
+	}
+
+	// Remove least recently used entries, skipping those in use
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUsed.Before(entries[j].lastUsed)
+	})
+	for _, e := range entries {
+		if total <= c.maxSize {
+			break
+		}
+		unlock, ok := c.tryLock(e.repositoryID)
+		if !ok {
+			continue
+		}
+		err := os.RemoveAll(e.path)
+		unlock()
+		if err != nil {
+			log.Warn().Err(err).Str("path", e.path).Msg("error evicting cached clone")
+			continue
+		}
+		total -= e.size
+	}
+}
+
+// checkoutRef checks out the revision provided in the git repository's
+// worktree, verifying that the objects needed are available and removing any
+// leftovers from previous checkouts.
+func checkoutRef(gr *git.Repository, ref *GitRef) error {
+	// Resolve commit (the hash may point to an annotated tag)
+	commit, err := gr.CommitObject(ref.Hash)
+	if err != nil {
+		tag, tErr := gr.TagObject(ref.Hash)
+		if tErr != nil {
+			return fmt.Errorf("error getting commit %s: %w", ref.Hash, err)
+		}
+		commit, err = tag.Commit()
+		if err != nil {
+			return fmt.Errorf("error getting tag %s commit: %w", ref.Hash, err)
+		}
+	}
+	if _, err := commit.Tree(); err != nil {
+		return fmt.Errorf("error getting commit %s tree: %w", commit.Hash, err)
+	}
+
+	// Checkout commit
+	wt, err := gr.Worktree()
+	if err != nil {
+		return fmt.Errorf("error getting worktree: %w", err)
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Hash: commit.Hash, Force: true}); err != nil {
+		return fmt.Errorf("error checking out commit %s: %w", commit.Hash, err)
+	}
+	if err := wt.Clean(&git.CleanOptions{Dir: true}); err != nil {
+		return fmt.Errorf("error cleaning worktree: %w", err)
+	}
+	return nil
+}
+
+// pruneTags removes the local tags that are not available in the remote
+// anymore, so that they are not processed (i.e. Tekton git based versioning).
+func pruneTags(gr *git.Repository, refs []*plumbing.Reference) error {
+	remoteTags := make(map[plumbing.ReferenceName]struct{})
+	for _, ref := range refs {
+		if ref.Name().IsTag() {
+			remoteTags[ref.Name()] = struct{}{}
+		}
+	}
+	tags, err := gr.Tags()
+	if err != nil {
+		return err
+	}
+	var stale []plumbing.ReferenceName
+	_ = tags.ForEach(func(tag *plumbing.Reference) error {
+		if _, ok := remoteTags[tag.Name()]; !ok {
+			stale = append(stale, tag.Name())
+		}
+		return nil
+	})
+	for _, name := range stale {
+		if err := gr.Storer.RemoveReference(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyClone copies the git clone located at the source path to the destination
+// path. Git objects are immutable, so they are hard linked when possible.
+func copyClone(src, dst string) error {
+	objectsPath := filepath.Join(src, git.GitDirName, "objects") + string(os.PathSeparator)
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0700)
+		case d.Type().IsRegular():
+			if strings.HasPrefix(path, objectsPath) {
+				if err := os.Link(path, target); err == nil {
+					return nil
+				}
+			}
+			return copyFile(path, target)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return nil
+		}
+	})
+}
+
+// copyFile copies the file at the source path to the destination path.
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// dirSize returns the total size of the regular files in the directory
+// provided.
+func dirSize(path string) int64 {
+	var size int64
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,248 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneCache(t *testing.T) {
+	// Use go-git's server implementation for local repositories, so that the
+	// git binary is not required
+	client.InstallProtocol("file", server.NewServer(server.NewFilesystemLoader(osfs.New("/"))))
+
+	ctx := context.Background()
+	r := &hub.Repository{RepositoryID: "00000000-0000-0000-0000-000000000001"}
+
+	t.Run("invalid repository id", func(t *testing.T) {
+		cache, err := NewCloneCache(t.TempDir(), 0)
+		require.NoError(t, err)
+		r := &hub.Repository{RepositoryID: "../repo"}
+		err = cache.Checkout(ctx, r, "", nil, nil, nil, t.TempDir())
+		assert.EqualError(t, err, "invalid repository id: ../repo")
+	})
+
+	t.Run("repository is cloned and then updated incrementally", func(t *testing.T) {
+		origin, originPath := newTestGitRepository(t)
+		commitFile(t, origin, originPath, "file", "v1")
+		createTag(t, origin, "v1.0.0")
+		cache, err := NewCloneCache(t.TempDir(), 0)
+		require.NoError(t, err)
+
+		// First checkout: repository is cloned
+		dst1 := t.TempDir()
+		refs, ref := resolveTestRef(t, origin, r)
+		err = cache.Checkout(ctx, r, gitDir(originPath), nil, refs, ref, dst1)
+		require.NoError(t, err)
+		assertFileContent(t, filepath.Join(dst1, "file"), "v1")
+		assert.Equal(t, []string{"v1.0.0"}, getTags(t, dst1))
+
+		// Second checkout: new changes are fetched and stale tags pruned
+		commitFile(t, origin, originPath, "file", "v2")
+		createTag(t, origin, "v2.0.0")
+		require.NoError(t, origin.DeleteTag("v1.0.0"))
+		dst2 := t.TempDir()
+		refs, ref = resolveTestRef(t, origin, r)
+		err = cache.Checkout(ctx, r, gitDir(originPath), nil, refs, ref, dst2)
+		require.NoError(t, err)
+		assertFileContent(t, filepath.Join(dst2, "file"), "v2")
+		assert.Equal(t, []string{"v2.0.0"}, getTags(t, dst2))
+
+		// Copies are independent from the cached clone
+		require.NoError(t, os.WriteFile(filepath.Join(dst2, "file"), []byte("modified"), 0600))
+		dst3 := t.TempDir()
+		err = cache.Checkout(ctx, r, gitDir(originPath), nil, refs, ref, dst3)
+		require.NoError(t, err)
+		assertFileContent(t, filepath.Join(dst3, "file"), "v2")
+	})
+
+	t.Run("corrupted clone is discarded and cloned again", func(t *testing.T) {
+		origin, originPath := newTestGitRepository(t)
+		commitFile(t, origin, originPath, "file", "v1")
+		cacheDir := t.TempDir()
+		cache, err := NewCloneCache(cacheDir, 0)
+		require.NoError(t, err)
+		refs, ref := resolveTestRef(t, origin, r)
+		require.NoError(t, cache.Checkout(ctx, r, gitDir(originPath), nil, refs, ref, t.TempDir()))
+
+		// Corrupt cached clone removing its objects
+		objectsPath := filepath.Join(cacheDir, r.RepositoryID, git.GitDirName, "objects")
+		require.NoError(t, os.RemoveAll(objectsPath))
+		require.NoError(t, os.MkdirAll(objectsPath, 0700))
+
+		commitFile(t, origin, originPath, "file", "v2")
+		refs, ref = resolveTestRef(t, origin, r)
+		dst := t.TempDir()
+		err = cache.Checkout(ctx, r, gitDir(originPath), nil, refs, ref, dst)
+		require.NoError(t, err)
+		assertFileContent(t, filepath.Join(dst, "file"), "v2")
+	})
+
+	t.Run("clone is discarded when the remote url changes", func(t *testing.T) {
+		origin1, originPath1 := newTestGitRepository(t)
+		commitFile(t, origin1, originPath1, "file", "origin1")
+		origin2, originPath2 := newTestGitRepository(t)
+		commitFile(t, origin2, originPath2, "file", "origin2")
+		cache, err := NewCloneCache(t.TempDir(), 0)
+		require.NoError(t, err)
+
+		refs, ref := resolveTestRef(t, origin1, r)
+		require.NoError(t, cache.Checkout(ctx, r, gitDir(originPath1), nil, refs, ref, t.TempDir()))
+		refs, ref = resolveTestRef(t, origin2, r)
+		dst := t.TempDir()
+		err = cache.Checkout(ctx, r, gitDir(originPath2), nil, refs, ref, dst)
+		require.NoError(t, err)
+		assertFileContent(t, filepath.Join(dst, "file"), "origin2")
+	})
+
+	t.Run("refs not reachable from any branch are fetched without cloning again", func(t *testing.T) {
+		origin, originPath := newTestGitRepository(t)
+		commitFile(t, origin, originPath, "file", "v1")
+		base, err := origin.Head()
+		require.NoError(t, err)
+		prRefName := plumbing.ReferenceName("refs/pull/1/head")
+		commitFile(t, origin, originPath, "file", "pr-v1")
+		setRef(t, origin, prRefName, plumbing.NewBranchReferenceName("master"), base.Hash())
+		cacheDir := t.TempDir()
+		cache, err := NewCloneCache(cacheDir, 0)
+		require.NoError(t, err)
+
+		// First checkout: repository is cloned
+		prHead, err := origin.Reference(prRefName, false)
+		require.NoError(t, err)
+		ref := &GitRef{Name: prRefName, Hash: prHead.Hash()}
+		dst1 := t.TempDir()
+		require.NoError(t, cache.Checkout(ctx, r, gitDir(originPath), nil, nil, ref, dst1))
+		assertFileContent(t, filepath.Join(dst1, "file"), "pr-v1")
+		marker := filepath.Join(cacheDir, r.RepositoryID, git.GitDirName, "marker")
+		require.NoError(t, os.WriteFile(marker, nil, 0600))
+
+		// Second checkout: new changes are fetched into the cached clone
+		wt, err := origin.Worktree()
+		require.NoError(t, err)
+		require.NoError(t, wt.Checkout(&git.CheckoutOptions{Hash: prHead.Hash(), Force: true}))
+		commitFile(t, origin, originPath, "file", "pr-v2")
+		head, err := origin.Head()
+		require.NoError(t, err)
+		setRef(t, origin, prRefName, plumbing.NewBranchReferenceName("master"), base.Hash())
+		ref = &GitRef{Name: prRefName, Hash: head.Hash()}
+		dst2 := t.TempDir()
+		require.NoError(t, cache.Checkout(ctx, r, gitDir(originPath), nil, nil, ref, dst2))
+		assertFileContent(t, filepath.Join(dst2, "file"), "pr-v2")
+		assert.FileExists(t, marker)
+	})
+
+	t.Run("least recently used clones are evicted", func(t *testing.T) {
+		origin, originPath := newTestGitRepository(t)
+		commitFile(t, origin, originPath, "file", "content")
+		cacheDir := t.TempDir()
+		cache, err := NewCloneCache(cacheDir, 1)
+		require.NoError(t, err)
+		refs, ref := resolveTestRef(t, origin, r)
+
+		r1 := &hub.Repository{RepositoryID: "00000000-0000-0000-0000-000000000001"}
+		require.NoError(t, cache.Checkout(ctx, r1, gitDir(originPath), nil, refs, ref, t.TempDir()))
+		assert.DirExists(t, filepath.Join(cacheDir, r1.RepositoryID))
+
+		past := time.Now().Add(-1 * time.Hour)
+		require.NoError(t, os.Chtimes(filepath.Join(cacheDir, r1.RepositoryID), past, past))
+		r2 := &hub.Repository{RepositoryID: "00000000-0000-0000-0000-000000000002"}
+		require.NoError(t, cache.Checkout(ctx, r2, gitDir(originPath), nil, refs, ref, t.TempDir()))
+		assert.NoDirExists(t, filepath.Join(cacheDir, r1.RepositoryID))
+	})
+}
+
+func newTestGitRepository(t *testing.T) (*git.Repository, string) {
+	t.Helper()
+	path := t.TempDir()
+	gr, err := git.PlainInit(path, false)
+	require.NoError(t, err)
+	cfg, err := gr.Config()
+	require.NoError(t, err)
+	require.NoError(t, gr.Storer.SetConfig(cfg))
+	return gr, path
+}
+
+func gitDir(repoPath string) string {
+	return filepath.Join(repoPath, git.GitDirName)
+}
+
+func commitFile(t *testing.T, gr *git.Repository, repoPath, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0600))
+	wt, err := gr.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Add(name)
+	require.NoError(t, err)
+	_, err = wt.Commit("commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@test.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+}
+
+func createTag(t *testing.T, gr *git.Repository, name string) {
+	t.Helper()
+	head, err := gr.Head()
+	require.NoError(t, err)
+	_, err = gr.CreateTag(name, head.Hash(), nil)
+	require.NoError(t, err)
+}
+
+// setRef points the ref provided to the current HEAD commit of the git
+// repository and then resets the branch provided to the hash given, so that
+// the ref is not reachable from it anymore.
+func setRef(t *testing.T, gr *git.Repository, name, branch plumbing.ReferenceName, hash plumbing.Hash) {
+	t.Helper()
+	head, err := gr.Head()
+	require.NoError(t, err)
+	require.NoError(t, gr.Storer.SetReference(plumbing.NewHashReference(name, head.Hash())))
+	require.NoError(t, gr.Storer.SetReference(plumbing.NewHashReference(branch, hash)))
+	require.NoError(t, gr.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branch)))
+}
+
+func resolveTestRef(t *testing.T, gr *git.Repository, r *hub.Repository) ([]*plumbing.Reference, *GitRef) {
+	t.Helper()
+	iter, err := gr.References()
+	require.NoError(t, err)
+	var refs []*plumbing.Reference
+	_ = iter.ForEach(func(ref *plumbing.Reference) error {
+		refs = append(refs, ref)
+		return nil
+	})
+	ref, err := ResolveGitRef(refs, r)
+	require.NoError(t, err)
+	return refs, ref
+}
+
+func getTags(t *testing.T, repoPath string) []string {
+	t.Helper()
+	gr, err := git.PlainOpen(repoPath)
+	require.NoError(t, err)
+	iter, err := gr.Tags()
+	require.NoError(t, err)
+	var tags []string
+	_ = iter.ForEach(func(ref *plumbing.Reference) error {
+		tags = append(tags, ref.Name().Short())
+		return nil
+	})
+	return tags
+}
+
+func assertFileContent(t *testing.T, path, expectedContent string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, expectedContent, string(data))
+}
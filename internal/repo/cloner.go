@@ -2,24 +2,52 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/util"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 )
 
 const (
 	// DefaultBranch represents the branch used by default when cloning a
-	// repository if no branch has been configured on it.
+	// repository if no branch has been configured on it and the remote does
+	// not advertise its default branch.
 	DefaultBranch = "master"
 )
 
 // Cloner is a hub.RepositoryCloner implementation.
-type Cloner struct{}
+type Cloner struct {
+	hc    hub.HTTPClient
+	cache *CloneCache
+}
+
+// WithCloneCache allows providing a specific CloneCache implementation for a
+// Cloner instance. When set, clones are kept between runs and updated
+// incrementally.
+func WithCloneCache(cache *CloneCache) func(c *Cloner) {
+	return func(c *Cloner) {
+		c.cache = cache
+	}
+}
+
+// NewCloner creates a new Cloner instance. The http client provided will be
+// used to mint GitHub App installation tokens when needed.
+func NewCloner(hc hub.HTTPClient, opts ...func(c *Cloner)) *Cloner {
+	c := &Cloner{
+		hc: hc,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
 
 // CloneRepository implements the hub.RepositoryCloner interface.
 func (c *Cloner) CloneRepository(ctx context.Context, r *hub.Repository) (string, string, error) {
@@ -35,36 +63,84 @@ func (c *Cloner) CloneRepository(ctx context.Context, r *hub.Repository) (string
 	}
 
 	// Clone git repository
+	hc := c.hc
+	if hc == nil {
+		hc = util.SetupHTTPClient(false, util.HTTPClientDefaultTimeout)
+	}
+	auth, cloneURL, err := GitAuthMethod(ctx, hc, r, repoBaseURL)
+	if err != nil {
+		return "", "", err
+	}
+	refs, err := ListGitRefs(ctx, cloneURL, auth)
+	if err != nil {
+		return "", "", err
+	}
+	ref, err := ResolveGitRef(refs, r)
+	if err != nil {
+		return "", "", err
+	}
 	tmpDir, err := os.MkdirTemp("", "artifact-hub")
 	if err != nil {
 		return "", "", fmt.Errorf("error creating temp dir: %w", err)
 	}
-	cloneOptions := &git.CloneOptions{
-		URL:           repoBaseURL,
-		ReferenceName: plumbing.NewBranchReferenceName(GetBranch(r)),
-		SingleBranch:  true,
-		Depth:         1,
+	switch {
+	case c.cache != nil:
+		err = c.cache.Checkout(ctx, r, cloneURL, auth, refs, ref, tmpDir)
+	case ref.Name == "":
+		err = fetchCommit(ctx, tmpDir, cloneURL, auth, ref.Hash)
+	default:
+		_, err = git.PlainCloneContext(ctx, tmpDir, false, &git.CloneOptions{
+			URL:           cloneURL,
+			Auth:          auth,
+			ReferenceName: ref.Name,
+			SingleBranch:  true,
+			Depth:         1,
+		})
 	}
-	if r.AuthPass != "" {
-		cloneOptions.Auth = &http.BasicAuth{
-			Username: "artifact-hub",
-			Password: r.AuthPass,
-		}
-	}
-	_, err = git.PlainCloneContext(ctx, tmpDir, false, cloneOptions)
 	if err != nil {
+		os.RemoveAll(tmpDir)
 		return "", "", err
 	}
 
 	return tmpDir, packagesPath, nil
 }
 
-// GetBranch returns the branch configured in the repository or the default one
-// if none was provided.
-func GetBranch(r *hub.Repository) string {
-	branch := r.Branch
-	if branch == "" {
-		branch = DefaultBranch
-	}
-	return branch
+// fetchCommit fetches the pinned commit provided from the remote git
+// repository and checks it out in the destination path. Only the commit
+// requested is fetched when the remote allows it, falling back to a full clone
+// otherwise.
+func fetchCommit(ctx context.Context, dst, url string, auth transport.AuthMethod, hash plumbing.Hash) error {
+	gr, err := git.PlainInit(dst, false)
+	if err != nil {
+		return fmt.Errorf("error initializing repository: %w", err)
+	}
+	if _, err := gr.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{url},
+	}); err != nil {
+		return fmt.Errorf("error creating remote: %w", err)
+	}
+	err = gr.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs:   []config.RefSpec{config.RefSpec(hash.String() + ":refs/heads/pinned")},
+		Auth:       auth,
+		Depth:      1,
+	})
+	if errors.Is(err, git.ErrExactSHA1NotSupported) {
+		err = gr.FetchContext(ctx, &git.FetchOptions{
+			RemoteName: git.DefaultRemoteName,
+			Auth:       auth,
+		})
+	}
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("error fetching commit %s: %w", hash, err)
+	}
+	wt, err := gr.Worktree()
+	if err != nil {
+		return fmt.Errorf("error getting worktree: %w", err)
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Hash: hash}); err != nil {
+		return fmt.Errorf("error checking out commit %s: %w", hash, err)
+	}
+	return nil
 }
@@ -0,0 +1,34 @@
+package repo
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchCommit(t *testing.T) {
+	// Use go-git's server implementation for local repositories, so that the
+	// git binary is not required
+	client.InstallProtocol("file", server.NewServer(server.NewFilesystemLoader(osfs.New("/"))))
+
+	origin, originPath := newTestGitRepository(t)
+	commitFile(t, origin, originPath, "file", "v1")
+	head, err := origin.Head()
+	require.NoError(t, err)
+	commitFile(t, origin, originPath, "file", "v2")
+
+	dst := t.TempDir()
+	err = fetchCommit(context.Background(), dst, gitDir(originPath), nil, head.Hash())
+	require.NoError(t, err)
+	assertFileContent(t, filepath.Join(dst, "file"), "v1")
+
+	err = fetchCommit(context.Background(), t.TempDir(), gitDir(originPath), nil, plumbing.NewHash("0123456789012345678901234567890123456789"))
+	assert.Error(t, err)
+}
@@ -2,9 +2,11 @@ package repo
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/rs/zerolog/log"
@@ -34,56 +36,92 @@ type ErrorsCollector struct {
 	rm   hub.RepositoryManager
 	kind ErrorsCollectorKind
 
-	mu     sync.Mutex
-	errors map[string][]string // K: repository id
+	mu      sync.Mutex
+	entries map[string]*collectorEntry // K: repository id
+}
+
+// collectorEntry represents the information collected for a repository.
+type collectorEntry struct {
+	initialized bool
+	startedAt   time.Time
+	finishedAt  time.Time
+	errors      []*hub.ErrorRecord
+	errorsCount int
+	stats       hub.TrackingRunStats
 }
 
 // NewErrorsCollector creates a new ErrorsCollector instance.
 func NewErrorsCollector(repoManager hub.RepositoryManager, kind ErrorsCollectorKind) *ErrorsCollector {
 	return &ErrorsCollector{
-		rm:     repoManager,
-		kind:   kind,
-		errors: make(map[string][]string),
+		rm:      repoManager,
+		kind:    kind,
+		entries: make(map[string]*collectorEntry),
 	}
 }
 
 // Append adds the error provided to the repository's list of errors.
-func (c *ErrorsCollector) Append(repositoryID string, err string) {
+func (c *ErrorsCollector) Append(repositoryID string, err error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if len(c.errors[repositoryID]) < maxErrorsPerRepository {
-		c.errors[repositoryID] = append(c.errors[repositoryID], err)
+	e := c.getEntry(repositoryID)
+	e.errorsCount++
+	if len(e.errors) < maxErrorsPerRepository {
+		e.errors = append(e.errors, hub.NewErrorRecord(err))
 	}
 }
 
-// Flush aggregates all errors collected per repository as a single text and
-// stores it in the database.
+// Flush aggregates all errors collected per repository and stores them in the
+// database. For trackers, a summary of the repository tracking run is stored
+// as well.
 func (c *ErrorsCollector) Flush() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	for repositoryID, errors := range c.errors {
-		// Sort error lines before flushing them. Packages can be processed in
-		// a repository concurrently, and the order the errors are produced is
+	for repositoryID, e := range c.entries {
+		// Sort errors before flushing them. Packages can be processed in a
+		// repository concurrently, and the order the errors are produced is
 		// not guaranteed. In order to be able to notify users when something
 		// goes wrong during repositories tracking or scanning, we need to be
 		// able to compare the errors produced among executions.
-		sort.Strings(errors)
+		sort.SliceStable(e.errors, func(i, j int) bool {
+			return e.errors[i].Message < e.errors[j].Message
+		})
 
 		var allErrors strings.Builder
-		for i, err := range errors {
-			allErrors.WriteString(err)
-			if i < len(errors)-1 {
+		for i, rec := range e.errors {
+			allErrors.WriteString(rec.Message)
+			if i < len(e.errors)-1 {
 				allErrors.WriteString("\n")
 			}
 		}
+		truncated := e.errorsCount > len(e.errors)
+		if truncated {
+			fmt.Fprintf(&allErrors, "\n(%d more errors not displayed)", e.errorsCount-len(e.errors))
+		}
+
 		var err error
 		switch c.kind {
 		case Scanner:
 			err = c.rm.SetLastScanningResults(context.Background(), repositoryID, allErrors.String())
 		case Tracker:
-			err = c.rm.SetLastTrackingResults(context.Background(), repositoryID, allErrors.String())
+			// Only repositories that were processed or failed have a run
+			var run *hub.TrackingRun
+			if e.initialized || e.errorsCount > 0 {
+				finishedAt := e.finishedAt
+				if finishedAt.IsZero() {
+					finishedAt = time.Now()
+				}
+				run = &hub.TrackingRun{
+					StartedAt:        e.startedAt.Unix(),
+					FinishedAt:       finishedAt.Unix(),
+					TrackingRunStats: e.stats,
+					ErrorsCount:      e.errorsCount,
+					ErrorsTruncated:  truncated,
+					Errors:           e.errors,
+				}
+			}
+			err = c.rm.SetLastTrackingResults(context.Background(), repositoryID, allErrors.String(), run)
 		}
 		if err != nil {
 			log.Error().Err(err).Str("repoID", repositoryID).Send()
@@ -98,7 +136,27 @@ func (c *ErrorsCollector) Init(repositoryID string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if _, ok := c.errors[repositoryID]; !ok {
-		c.errors[repositoryID] = nil
+	c.getEntry(repositoryID).initialized = true
+}
+
+// SetTrackingRunStats sets the stats of the tracking run of the repository
+// provided, marking it as finished.
+func (c *ErrorsCollector) SetTrackingRunStats(repositoryID string, stats *hub.TrackingRunStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.getEntry(repositoryID)
+	e.stats = *stats
+	e.finishedAt = time.Now()
+}
+
+// getEntry returns the entry of the repository provided, initializing it if
+// needed. The caller must hold the collector's lock.
+func (c *ErrorsCollector) getEntry(repositoryID string) *collectorEntry {
+	e, ok := c.entries[repositoryID]
+	if !ok {
+		e = &collectorEntry{startedAt: time.Now()}
+		c.entries[repositoryID] = e
 	}
+	return e
 }
@@ -2,46 +2,138 @@ package repo
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestCollector(t *testing.T) {
-	testCases := []struct {
-		kind         ErrorsCollectorKind
-		expectedCall string
-	}{
-		{
-			Scanner,
-			"SetLastScanningResults",
-		},
-		{
-			Tracker,
-			"SetLastTrackingResults",
-		},
-	}
-	for _, tc := range testCases {
-		tc := tc
-		t.Run(tc.expectedCall, func(t *testing.T) {
-			t.Parallel()
-
-			// Setup errors collector
-			rm := &ManagerMock{}
-			ec := NewErrorsCollector(rm, tc.kind)
-
-			// Initialize list of errors for repo1 (repo2 will be implicitly initialized)
-			ec.Init("repo1")
-
-			// Append some errors for both repositories
-			ec.Append("repo1", "error1")
-			ec.Append("repo1", "error2")
-			ec.Append("repo2", "error2")
-			ec.Append("repo2", "error1")
-
-			// Flush errors and check the results were set as expected
-			rm.On(tc.expectedCall, context.Background(), "repo1", "error1\nerror2").Return(nil)
-			rm.On(tc.expectedCall, context.Background(), "repo2", "error1\nerror2").Return(nil)
-			ec.Flush()
-			rm.AssertExpectations(t)
+	t.Run("scanner errors are flushed", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup errors collector
+		rm := &ManagerMock{}
+		ec := NewErrorsCollector(rm, Scanner)
+
+		// Initialize list of errors for repo1 (repo2 will be implicitly initialized)
+		ec.Init("repo1")
+
+		// Append some errors for both repositories
+		ec.Append("repo1", errors.New("error1"))
+		ec.Append("repo1", errors.New("error2"))
+		ec.Append("repo2", errors.New("error2"))
+		ec.Append("repo2", errors.New("error1"))
+
+		// Flush errors and check the results were set as expected
+		rm.On("SetLastScanningResults", context.Background(), "repo1", "error1\nerror2").Return(nil)
+		rm.On("SetLastScanningResults", context.Background(), "repo2", "error1\nerror2").Return(nil)
+		ec.Flush()
+		rm.AssertExpectations(t)
+	})
+
+	t.Run("tracker errors and tracking run are flushed", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup errors collector
+		rm := &ManagerMock{}
+		ec := NewErrorsCollector(rm, Tracker)
+
+		// Initialize list of errors for repo1 (repo2 will be implicitly initialized)
+		ec.Init("repo1")
+
+		// Append some errors for both repositories and set repo1 stats
+		ec.Append("repo1", errors.New("error1"))
+		ec.Append("repo1", &hub.RepositoryError{
+			PackageName: "pkg1",
+			Version:     "1.0.0",
+			Class:       hub.ErrorClassRegistration,
+			Err:         errors.New("error2"),
+		})
+		ec.Append("repo2", errors.New("error1"))
+		ec.SetTrackingRunStats("repo1", &hub.TrackingRunStats{
+			PackagesRegistered: 1,
+			PackagesSkipped:    2,
 		})
-	}
+
+		// Flush errors and check the results were set as expected
+		var run1, run2 *hub.TrackingRun
+		rm.On("SetLastTrackingResults", context.Background(), "repo1", "error1\nerror2", mock.Anything).
+			Run(func(args mock.Arguments) { run1 = args.Get(3).(*hub.TrackingRun) }).
+			Return(nil)
+		rm.On("SetLastTrackingResults", context.Background(), "repo2", "error1", mock.Anything).
+			Run(func(args mock.Arguments) { run2 = args.Get(3).(*hub.TrackingRun) }).
+			Return(nil)
+		ec.Flush()
+		rm.AssertExpectations(t)
+
+		assert.NotZero(t, run1.StartedAt)
+		assert.GreaterOrEqual(t, run1.FinishedAt, run1.StartedAt)
+		assert.Equal(t, hub.TrackingRunStats{PackagesRegistered: 1, PackagesSkipped: 2}, run1.TrackingRunStats)
+		assert.Equal(t, 2, run1.ErrorsCount)
+		assert.False(t, run1.ErrorsTruncated)
+		assert.Equal(t, []*hub.ErrorRecord{
+			{
+				Class:   hub.ErrorClassGeneric,
+				Message: "error1",
+			},
+			{
+				PackageName: "pkg1",
+				Version:     "1.0.0",
+				Class:       hub.ErrorClassRegistration,
+				Message:     "error2",
+			},
+		}, run1.Errors)
+		assert.Equal(t, 1, run2.ErrorsCount)
+		assert.NotZero(t, run2.FinishedAt)
+	})
+
+	t.Run("no tracking run is registered when the repository was not processed", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup errors collector
+		rm := &ManagerMock{}
+		ec := NewErrorsCollector(rm, Tracker)
+		ec.SetTrackingRunStats("repo1", &hub.TrackingRunStats{})
+
+		// Flush errors and check the results were set as expected
+		rm.On("SetLastTrackingResults", context.Background(), "repo1", "", (*hub.TrackingRun)(nil)).Return(nil)
+		ec.Flush()
+		rm.AssertExpectations(t)
+	})
+
+	t.Run("errors are truncated when the limit is reached", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup errors collector
+		rm := &ManagerMock{}
+		ec := NewErrorsCollector(rm, Tracker)
+
+		// Append more errors than the limit
+		var expectedErrs []string
+		for i := 0; i < maxErrorsPerRepository+5; i++ {
+			msg := fmt.Sprintf("error%03d", i)
+			ec.Append("repo1", errors.New(msg))
+			if i < maxErrorsPerRepository {
+				expectedErrs = append(expectedErrs, msg)
+			}
+		}
+		expectedErrs = append(expectedErrs, "(5 more errors not displayed)")
+
+		// Flush errors and check the results were set as expected
+		var run *hub.TrackingRun
+		rm.On("SetLastTrackingResults", context.Background(), "repo1", strings.Join(expectedErrs, "\n"), mock.Anything).
+			Run(func(args mock.Arguments) { run = args.Get(3).(*hub.TrackingRun) }).
+			Return(nil)
+		ec.Flush()
+		rm.AssertExpectations(t)
+
+		assert.Equal(t, maxErrorsPerRepository+5, run.ErrorsCount)
+		assert.True(t, run.ErrorsTruncated)
+		assert.Len(t, run.Errors, maxErrorsPerRepository)
+	})
 }
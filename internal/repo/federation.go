@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/spf13/viper"
+)
+
+var (
+	// ErrFederationNotAllowed indicates that the repository url provided does
+	// not point to any of the remote Artifact Hub instances allowed.
+	ErrFederationNotAllowed = errors.New("remote Artifact Hub instance not allowed")
+)
+
+// GetFederation returns the federation settings of the repository provided.
+// A nil value is returned when the repository does not mirror the packages of
+// a remote Artifact Hub instance.
+func GetFederation(r *hub.Repository) (*hub.Federation, error) {
+	var data *hub.FederationData
+	if r.Data != nil {
+		if err := json.Unmarshal(r.Data, &data); err != nil {
+			return nil, fmt.Errorf("invalid federation data: %w", err)
+		}
+	}
+	if data == nil {
+		return nil, nil
+	}
+	return data.Federation, nil
+}
+
+// IsFederated checks if the repository provided mirrors the packages of a
+// remote Artifact Hub instance.
+func IsFederated(r *hub.Repository) bool {
+	federation, err := GetFederation(r)
+	return err == nil && federation != nil
+}
+
+// IsFederationAllowed checks if the url provided points to one of the remote
+// Artifact Hub instances repositories are allowed to mirror packages from.
+// Some information provided by the remote instances, like the security reports
+// summaries, is trusted, so federation is disabled when no instances have been
+// explicitly allowed.
+func IsFederationAllowed(cfg *viper.Viper, u string) bool {
+	u = strings.TrimSuffix(strings.ToLower(u), "/")
+	for _, allowedURL := range cfg.GetStringSlice("federation.allowedURLs") {
+		if u == strings.TrimSuffix(strings.ToLower(allowedURL), "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateFederation validates the federation settings provided for the
+// repository.
+func validateFederation(cfg *viper.Viper, r *hub.Repository, federation *hub.Federation) error {
+	if !IsFederationAllowed(cfg, r.URL) {
+		return ErrFederationNotAllowed
+	}
+	if r.AuthUser != "" || r.AuthPass != "" || (r.GitAuth != nil && !r.GitAuth.IsZero()) {
+		return errors.New("credentials not supported")
+	}
+	for _, name := range federation.Repositories {
+		if name == "" {
+			return errors.New("invalid repository name")
+		}
+	}
+	for _, name := range federation.Organizations {
+		if name == "" {
+			return errors.New("invalid organization name")
+		}
+	}
+	return nil
+}
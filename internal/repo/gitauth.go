@@ -0,0 +1,224 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	// defaultGitHTTPUser represents the username used by default when using
+	// http basic auth to interact with a git repository.
+	defaultGitHTTPUser = "artifact-hub"
+
+	// defaultGitSSHUser represents the username used by default when using
+	// ssh to interact with a git repository.
+	defaultGitSSHUser = "git"
+
+	// gitHubAPIURL represents the default GitHub API url used to mint GitHub
+	// App installation tokens.
+	gitHubAPIURL = "https://api.github.com"
+
+	// gitHubAppTokenUser represents the username that must be used along with
+	// GitHub App installation tokens.
+	gitHubAppTokenUser = "x-access-token"
+)
+
+var (
+	// errNoKnownHosts indicates that no known hosts were provided along with
+	// an ssh key.
+	errNoKnownHosts = errors.New("ssh known hosts must be provided when using an ssh key")
+)
+
+// GitAuthMethod returns the auth method that should be used to interact with
+// the git repository provided, as well as the url that must be used with it.
+// When no credentials have been configured, a nil auth method is returned.
+func GitAuthMethod(
+	ctx context.Context,
+	hc hub.HTTPClient,
+	r *hub.Repository,
+	repoBaseURL string,
+) (transport.AuthMethod, string, error) {
+	a := r.GitAuth
+	if a == nil {
+		a = &hub.GitAuth{}
+	}
+
+	switch {
+	case a.SSHKey != "":
+		username := a.Username
+		if username == "" {
+			username = defaultGitSSHUser
+		}
+		auth, err := gitssh.NewPublicKeys(username, []byte(a.SSHKey), "")
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid ssh key: %w", err)
+		}
+		hostKeyCallback, err := newKnownHostsCallback(a.SSHKnownHosts)
+		if err != nil {
+			return nil, "", err
+		}
+		auth.HostKeyCallback = hostKeyCallback
+		return auth, toSSHURL(repoBaseURL, username), nil
+	case a.GitHubApp != nil:
+		token, err := getGitHubAppToken(ctx, hc, a.GitHubApp)
+		if err != nil {
+			return nil, "", fmt.Errorf("error getting github app installation token: %w", err)
+		}
+		return &githttp.BasicAuth{
+			Username: gitHubAppTokenUser,
+			Password: token,
+		}, repoBaseURL, nil
+	case r.AuthPass != "":
+		username := a.Username
+		if username == "" {
+			username = defaultGitHTTPUser
+		}
+		return &githttp.BasicAuth{
+			Username: username,
+			Password: r.AuthPass,
+		}, repoBaseURL, nil
+	default:
+		return nil, repoBaseURL, nil
+	}
+}
+
+// validateGitAuth validates the git auth settings provided.
+func validateGitAuth(a *hub.GitAuth) error {
+	if a.SSHKey != "" && a.GitHubApp != nil {
+		return errors.New("ssh key and github app credentials cannot be used at the same time")
+	}
+	if a.SSHKey != "" {
+		if _, err := ssh.ParsePrivateKey([]byte(a.SSHKey)); err != nil {
+			return fmt.Errorf("invalid ssh key: %w", err)
+		}
+		if _, err := newKnownHostsCallback(a.SSHKnownHosts); err != nil {
+			return err
+		}
+	}
+	if a.GitHubApp != nil {
+		if a.GitHubApp.AppID <= 0 {
+			return errors.New("invalid github app id")
+		}
+		if a.GitHubApp.InstallationID <= 0 {
+			return errors.New("invalid github app installation id")
+		}
+		if _, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(a.GitHubApp.PrivateKey)); err != nil {
+			return fmt.Errorf("invalid github app private key: %w", err)
+		}
+	}
+	return nil
+}
+
+// newKnownHostsCallback returns an ssh host key callback that only accepts the
+// hosts keys present in the known hosts data provided (known_hosts format).
+// Matching is delegated to the knownhosts package, so hashed entries and
+// patterns are supported as well.
+func newKnownHostsCallback(data string) (ssh.HostKeyCallback, error) {
+	// Check at least one valid entry has been provided
+	var entries int
+	rest := []byte(data)
+	for len(bytes.TrimSpace(rest)) > 0 {
+		var err error
+		_, _, _, _, rest, err = ssh.ParseKnownHosts(rest)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("invalid ssh known hosts: %w", err)
+		}
+		entries++
+	}
+	if entries == 0 {
+		return nil, errNoKnownHosts
+	}
+
+	// Setup callback from the known hosts data (the knownhosts package can
+	// only load them from files)
+	f, err := os.CreateTemp("", "known_hosts")
+	if err != nil {
+		return nil, fmt.Errorf("error creating known hosts file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(data); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error writing known hosts file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("error writing known hosts file: %w", err)
+	}
+	cb, err := knownhosts.New(f.Name())
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssh known hosts: %w", err)
+	}
+	return cb, nil
+}
+
+// toSSHURL converts the http based git repository url provided to its ssh
+// equivalent.
+func toSSHURL(repoBaseURL, username string) string {
+	return fmt.Sprintf("ssh://%s@%s", username, strings.TrimPrefix(repoBaseURL, "https://"))
+}
+
+// getGitHubAppToken mints a short-lived GitHub App installation token using
+// the credentials provided.
+func getGitHubAppToken(ctx context.Context, hc hub.HTTPClient, app *hub.GitHubAppAuth) (string, error) {
+	// Prepare app token (JWT)
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(app.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid private key: %w", err)
+	}
+	now := time.Now()
+	appToken, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Issuer:    strconv.FormatInt(app.AppID, 10),
+		IssuedAt:  jwt.NewNumericDate(now.Add(-1 * time.Minute)),
+		ExpiresAt: jwt.NewNumericDate(now.Add(9 * time.Minute)),
+	}).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	// Request installation token
+	apiURL := app.APIURL
+	if apiURL == "" {
+		apiURL = gitHubAPIURL
+	}
+	u := fmt.Sprintf("%s/app/installations/%d/access_tokens", strings.TrimSuffix(apiURL, "/"), app.InstallationID)
+	req, _ := http.NewRequestWithContext(ctx, "POST", u, nil)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+appToken)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
+	}
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("error decoding installation token: %w", err)
+	}
+	if result.Token == "" {
+		return "", errors.New("empty installation token received")
+	}
+	return result.Token, nil
+}
@@ -0,0 +1,228 @@
+package repo
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/tests"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestGitAuthMethod(t *testing.T) {
+	ctx := context.Background()
+	repoBaseURL := "https://github.com/org/repo"
+
+	t.Run("no credentials configured", func(t *testing.T) {
+		t.Parallel()
+		auth, u, err := GitAuthMethod(ctx, nil, &hub.Repository{}, repoBaseURL)
+		assert.NoError(t, err)
+		assert.Nil(t, auth)
+		assert.Equal(t, repoBaseURL, u)
+	})
+
+	t.Run("basic auth using default username", func(t *testing.T) {
+		t.Parallel()
+		r := &hub.Repository{AuthPass: "pass"}
+		auth, u, err := GitAuthMethod(ctx, nil, r, repoBaseURL)
+		assert.NoError(t, err)
+		assert.Equal(t, &githttp.BasicAuth{Username: defaultGitHTTPUser, Password: "pass"}, auth)
+		assert.Equal(t, repoBaseURL, u)
+	})
+
+	t.Run("basic auth using custom username", func(t *testing.T) {
+		t.Parallel()
+		r := &hub.Repository{
+			AuthPass: "pass",
+			GitAuth:  &hub.GitAuth{Username: "user"},
+		}
+		auth, _, err := GitAuthMethod(ctx, nil, r, repoBaseURL)
+		assert.NoError(t, err)
+		assert.Equal(t, &githttp.BasicAuth{Username: "user", Password: "pass"}, auth)
+	})
+
+	t.Run("ssh key", func(t *testing.T) {
+		t.Parallel()
+		sshKey, knownHosts := generateSSHTestData(t)
+		r := &hub.Repository{
+			GitAuth: &hub.GitAuth{
+				SSHKey:        sshKey,
+				SSHKnownHosts: knownHosts,
+			},
+		}
+		auth, u, err := GitAuthMethod(ctx, nil, r, repoBaseURL)
+		require.NoError(t, err)
+		assert.IsType(t, &gitssh.PublicKeys{}, auth)
+		assert.Equal(t, "ssh://git@github.com/org/repo", u)
+	})
+
+	t.Run("github app: error minting installation token", func(t *testing.T) {
+		t.Parallel()
+		hc := &tests.HTTPClientMock{}
+		hc.On("Do", mock.Anything).Return(&http.Response{
+			Body:       io.NopCloser(strings.NewReader("")),
+			StatusCode: http.StatusUnauthorized,
+		}, nil)
+		r := &hub.Repository{
+			GitAuth: &hub.GitAuth{
+				GitHubApp: &hub.GitHubAppAuth{
+					AppID:          1,
+					InstallationID: 2,
+					PrivateKey:     generateRSATestKey(t),
+				},
+			},
+		}
+		auth, _, err := GitAuthMethod(ctx, hc, r, repoBaseURL)
+		assert.Error(t, err)
+		assert.Nil(t, auth)
+		hc.AssertExpectations(t)
+	})
+
+	t.Run("github app: installation token minted successfully", func(t *testing.T) {
+		t.Parallel()
+		hc := &tests.HTTPClientMock{}
+		hc.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+			return req.Method == "POST" &&
+				req.URL.String() == "https://api.github.com/app/installations/2/access_tokens" &&
+				strings.HasPrefix(req.Header.Get("Authorization"), "Bearer ")
+		})).Return(&http.Response{
+			Body:       io.NopCloser(strings.NewReader(`{"token": "token"}`)),
+			StatusCode: http.StatusCreated,
+		}, nil)
+		r := &hub.Repository{
+			GitAuth: &hub.GitAuth{
+				GitHubApp: &hub.GitHubAppAuth{
+					AppID:          1,
+					InstallationID: 2,
+					PrivateKey:     generateRSATestKey(t),
+				},
+			},
+		}
+		auth, u, err := GitAuthMethod(ctx, hc, r, repoBaseURL)
+		assert.NoError(t, err)
+		assert.Equal(t, &githttp.BasicAuth{Username: gitHubAppTokenUser, Password: "token"}, auth)
+		assert.Equal(t, repoBaseURL, u)
+		hc.AssertExpectations(t)
+	})
+}
+
+func TestValidateGitAuth(t *testing.T) {
+	sshKey, knownHosts := generateSSHTestData(t)
+	rsaKey := generateRSATestKey(t)
+
+	testCases := []struct {
+		a           *hub.GitAuth
+		expectedErr string
+	}{
+		{
+			&hub.GitAuth{SSHKey: "invalid", SSHKnownHosts: knownHosts},
+			"invalid ssh key",
+		},
+		{
+			&hub.GitAuth{SSHKey: sshKey},
+			errNoKnownHosts.Error(),
+		},
+		{
+			&hub.GitAuth{SSHKey: sshKey, SSHKnownHosts: "# no hosts"},
+			errNoKnownHosts.Error(),
+		},
+		{
+			&hub.GitAuth{SSHKey: sshKey, SSHKnownHosts: knownHosts, GitHubApp: &hub.GitHubAppAuth{}},
+			"cannot be used at the same time",
+		},
+		{
+			&hub.GitAuth{GitHubApp: &hub.GitHubAppAuth{InstallationID: 1, PrivateKey: rsaKey}},
+			"invalid github app id",
+		},
+		{
+			&hub.GitAuth{GitHubApp: &hub.GitHubAppAuth{AppID: 1, PrivateKey: rsaKey}},
+			"invalid github app installation id",
+		},
+		{
+			&hub.GitAuth{GitHubApp: &hub.GitHubAppAuth{AppID: 1, InstallationID: 1, PrivateKey: "invalid"}},
+			"invalid github app private key",
+		},
+		{
+			&hub.GitAuth{SSHKey: sshKey, SSHKnownHosts: knownHosts},
+			"",
+		},
+		{
+			&hub.GitAuth{GitHubApp: &hub.GitHubAppAuth{AppID: 1, InstallationID: 1, PrivateKey: rsaKey}},
+			"",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.expectedErr, func(t *testing.T) {
+			t.Parallel()
+			err := validateGitAuth(tc.a)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestKnownHostsCallback(t *testing.T) {
+	t.Parallel()
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostKey, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	pub2, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ssh.NewPublicKey(pub2)
+	require.NoError(t, err)
+	knownHosts := "github.com " + string(ssh.MarshalAuthorizedKey(hostKey))
+
+	cb, err := newKnownHostsCallback(knownHosts)
+	require.NoError(t, err)
+	addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22}
+	assert.NoError(t, cb("github.com:22", addr, hostKey))
+	assert.Error(t, cb("github.com:22", addr, otherKey))
+	assert.Error(t, cb("gitlab.com:22", addr, hostKey))
+
+	hashedKnownHosts := knownhosts.HashHostname("github.com") + " " + string(ssh.MarshalAuthorizedKey(hostKey))
+	cb, err = newKnownHostsCallback(hashedKnownHosts)
+	require.NoError(t, err)
+	assert.NoError(t, cb("github.com:22", addr, hostKey))
+	assert.Error(t, cb("gitlab.com:22", addr, hostKey))
+}
+
+func generateSSHTestData(t *testing.T) (string, string) {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostKey, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	knownHosts := "github.com " + string(ssh.MarshalAuthorizedKey(hostKey))
+	return generateRSATestKey(t), knownHosts
+}
+
+func generateRSATestKey(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+}
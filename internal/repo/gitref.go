@@ -0,0 +1,190 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+var (
+	// commitHashRE is a regexp used to check if a git ref is a full commit
+	// hash.
+	commitHashRE = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+	// errNoSemverTags indicates that no semver tags were found in the git
+	// repository when tracking the latest tag.
+	errNoSemverTags = errors.New("no semver tags found in repository")
+)
+
+// GitRef represents a git revision resolved from the tracking settings of a
+// repository. Name will be empty when the revision is a pinned commit.
+type GitRef struct {
+	Name plumbing.ReferenceName
+	Hash plumbing.Hash
+}
+
+// GetGitTracking returns the git tracking settings of the repository provided.
+// When no settings have been provided, the repository's branch is tracked.
+func GetGitTracking(r *hub.Repository) (*hub.GitTracking, error) {
+	var data *hub.GitData
+	if r.Data != nil {
+		if err := json.Unmarshal(r.Data, &data); err != nil {
+			return nil, fmt.Errorf("invalid git repository data: %w", err)
+		}
+	}
+	if data == nil || data.Tracking == nil || data.Tracking.Mode == "" {
+		return &hub.GitTracking{Mode: hub.GitTrackingBranch}, nil
+	}
+	return data.Tracking, nil
+}
+
+// GetSourceRef returns the git ref that should be used when building links to
+// the repository's source files. The commit provided, when available, is the
+// revision resolved when the repository was cloned and is used when the ref
+// tracked is not known in advance (latest tag mode).
+func GetSourceRef(r *hub.Repository, commit string) string {
+	tracking, err := GetGitTracking(r)
+	if err != nil {
+		return GetBranch(r)
+	}
+	switch tracking.Mode {
+	case hub.GitTrackingLatestTag:
+		if commit != "" {
+			return commit
+		}
+		return "HEAD"
+	case hub.GitTrackingRef:
+		if commitHashRE.MatchString(tracking.Ref) {
+			return tracking.Ref
+		}
+		return plumbing.ReferenceName(tracking.Ref).Short()
+	default:
+		return GetBranch(r)
+	}
+}
+
+// GetBranch returns the branch configured in the repository or the default one
+// if none was provided.
+func GetBranch(r *hub.Repository) string {
+	branch := r.Branch
+	if branch == "" {
+		branch = DefaultBranch
+	}
+	return branch
+}
+
+// ListGitRefs returns the references available in the remote git repository
+// provided, using the auth method provided (see GitAuthMethod).
+func ListGitRefs(ctx context.Context, remoteURL string, auth transport.AuthMethod) ([]*plumbing.Reference, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		URLs: []string{remoteURL},
+	})
+	return remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+}
+
+// ResolveGitRef resolves the revision that should be tracked in the git
+// repository provided from the list of references available in the remote.
+func ResolveGitRef(refs []*plumbing.Reference, r *hub.Repository) (*GitRef, error) {
+	tracking, err := GetGitTracking(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch tracking.Mode {
+	case hub.GitTrackingBranch:
+		// Use the branch configured or the remote's default one (HEAD)
+		branch := r.Branch
+		if branch == "" {
+			branch = DefaultBranch
+			for _, ref := range refs {
+				if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference {
+					branch = ref.Target().Short()
+					break
+				}
+			}
+		}
+		name := plumbing.NewBranchReferenceName(branch)
+		for _, ref := range refs {
+			if ref.Name() == name {
+				return &GitRef{Name: name, Hash: ref.Hash()}, nil
+			}
+		}
+		return nil, fmt.Errorf("branch %s not found", branch)
+
+	case hub.GitTrackingLatestTag:
+		// Use the highest semver tag that is not a prerelease
+		var latest *GitRef
+		var latestSV *semver.Version
+		for _, ref := range refs {
+			if !ref.Name().IsTag() {
+				continue
+			}
+			sv, err := semver.NewVersion(ref.Name().Short())
+			if err != nil || sv.Prerelease() != "" {
+				continue
+			}
+			if latestSV == nil || sv.GreaterThan(latestSV) {
+				latest = &GitRef{Name: ref.Name(), Hash: ref.Hash()}
+				latestSV = sv
+			}
+		}
+		if latest == nil {
+			return nil, errNoSemverTags
+		}
+		return latest, nil
+
+	case hub.GitTrackingRef:
+		// Use the pinned commit or ref (full name, tag or branch)
+		if commitHashRE.MatchString(tracking.Ref) {
+			return &GitRef{Hash: plumbing.NewHash(tracking.Ref)}, nil
+		}
+		candidates := []plumbing.ReferenceName{
+			plumbing.ReferenceName(tracking.Ref),
+			plumbing.NewTagReferenceName(tracking.Ref),
+			plumbing.NewBranchReferenceName(tracking.Ref),
+		}
+		for _, name := range candidates {
+			for _, ref := range refs {
+				if ref.Name() == name && ref.Type() == plumbing.HashReference {
+					return &GitRef{Name: name, Hash: ref.Hash()}, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("ref %s not found", tracking.Ref)
+
+	default:
+		return nil, fmt.Errorf("invalid git tracking mode: %s", tracking.Mode)
+	}
+}
+
+// validateGitTracking validates the git tracking settings of the repository
+// provided.
+func validateGitTracking(r *hub.Repository) error {
+	tracking, err := GetGitTracking(r)
+	if err != nil {
+		return err
+	}
+	switch tracking.Mode {
+	case hub.GitTrackingBranch, hub.GitTrackingLatestTag:
+		if tracking.Ref != "" {
+			return fmt.Errorf("ref cannot be provided when using the %s tracking mode", tracking.Mode)
+		}
+	case hub.GitTrackingRef:
+		if tracking.Ref == "" {
+			return errors.New("ref must be provided when using the ref tracking mode")
+		}
+	default:
+		return fmt.Errorf("invalid git tracking mode: %s", tracking.Mode)
+	}
+	return nil
+}
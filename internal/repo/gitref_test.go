@@ -0,0 +1,183 @@
+package repo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveGitRef(t *testing.T) {
+	h1 := plumbing.NewHash("1111111111111111111111111111111111111111")
+	h2 := plumbing.NewHash("2222222222222222222222222222222222222222")
+	h3 := plumbing.NewHash("3333333333333333333333333333333333333333")
+	h4 := plumbing.NewHash("4444444444444444444444444444444444444444")
+	h5 := plumbing.NewHash("5555555555555555555555555555555555555555")
+	refs := []*plumbing.Reference{
+		plumbing.NewSymbolicReference(plumbing.HEAD, "refs/heads/main"),
+		plumbing.NewHashReference("refs/heads/main", h1),
+		plumbing.NewHashReference("refs/heads/develop", h2),
+		plumbing.NewHashReference("refs/tags/v1.0.0", h3),
+		plumbing.NewHashReference("refs/tags/v1.10.0", h4),
+		plumbing.NewHashReference("refs/tags/v2.0.0-rc.1", h5),
+		plumbing.NewHashReference("refs/tags/latest", h5),
+	}
+	withTracking := func(mode, ref string) json.RawMessage {
+		data, _ := json.Marshal(&hub.GitData{Tracking: &hub.GitTracking{Mode: mode, Ref: ref}})
+		return data
+	}
+
+	testCases := []struct {
+		desc        string
+		refs        []*plumbing.Reference
+		r           *hub.Repository
+		expectedRef *GitRef
+		expectedErr string
+	}{
+		{
+			"default branch detected from remote head",
+			refs,
+			&hub.Repository{},
+			&GitRef{Name: "refs/heads/main", Hash: h1},
+			"",
+		},
+		{
+			"default branch falls back to master when head is not advertised",
+			[]*plumbing.Reference{plumbing.NewHashReference("refs/heads/master", h1)},
+			&hub.Repository{},
+			&GitRef{Name: "refs/heads/master", Hash: h1},
+			"",
+		},
+		{
+			"branch configured",
+			refs,
+			&hub.Repository{Branch: "develop"},
+			&GitRef{Name: "refs/heads/develop", Hash: h2},
+			"",
+		},
+		{
+			"branch configured not found",
+			refs,
+			&hub.Repository{Branch: "missing"},
+			nil,
+			"branch missing not found",
+		},
+		{
+			"tekton data does not affect branch tracking",
+			refs,
+			&hub.Repository{Data: json.RawMessage(`{"versioning": "git"}`)},
+			&GitRef{Name: "refs/heads/main", Hash: h1},
+			"",
+		},
+		{
+			"latest semver tag, prereleases ignored",
+			refs,
+			&hub.Repository{Data: withTracking(hub.GitTrackingLatestTag, "")},
+			&GitRef{Name: "refs/tags/v1.10.0", Hash: h4},
+			"",
+		},
+		{
+			"latest semver tag, no tags available",
+			refs[:3],
+			&hub.Repository{Data: withTracking(hub.GitTrackingLatestTag, "")},
+			nil,
+			errNoSemverTags.Error(),
+		},
+		{
+			"pinned commit",
+			refs,
+			&hub.Repository{Data: withTracking(hub.GitTrackingRef, h5.String())},
+			&GitRef{Hash: h5},
+			"",
+		},
+		{
+			"pinned tag",
+			refs,
+			&hub.Repository{Data: withTracking(hub.GitTrackingRef, "v1.0.0")},
+			&GitRef{Name: "refs/tags/v1.0.0", Hash: h3},
+			"",
+		},
+		{
+			"pinned full ref",
+			refs,
+			&hub.Repository{Data: withTracking(hub.GitTrackingRef, "refs/heads/develop")},
+			&GitRef{Name: "refs/heads/develop", Hash: h2},
+			"",
+		},
+		{
+			"pinned ref not found",
+			refs,
+			&hub.Repository{Data: withTracking(hub.GitTrackingRef, "v9.9.9")},
+			nil,
+			"ref v9.9.9 not found",
+		},
+		{
+			"invalid tracking mode",
+			refs,
+			&hub.Repository{Data: withTracking("invalid", "")},
+			nil,
+			"invalid git tracking mode: invalid",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			ref, err := ResolveGitRef(tc.refs, tc.r)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				assert.Nil(t, ref)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedRef, ref)
+			}
+		})
+	}
+}
+
+func TestGetSourceRef(t *testing.T) {
+	commit := "0123456789012345678901234567890123456789"
+	testCases := []struct {
+		r           *hub.Repository
+		commit      string
+		expectedRef string
+	}{
+		{&hub.Repository{}, commit, "master"},
+		{&hub.Repository{Branch: "develop"}, commit, "develop"},
+		{
+			&hub.Repository{
+				Branch: "develop",
+				Data:   json.RawMessage(`{"git_tracking": {"mode": "ref", "ref": "refs/tags/v1.0.0"}}`),
+			},
+			commit,
+			"v1.0.0",
+		},
+		{
+			&hub.Repository{
+				Data: json.RawMessage(`{"git_tracking": {"mode": "ref", "ref": "` + commit + `"}}`),
+			},
+			"",
+			commit,
+		},
+		{
+			&hub.Repository{
+				Data: json.RawMessage(`{"git_tracking": {"mode": "latest-tag"}}`),
+			},
+			commit,
+			commit,
+		},
+		{
+			&hub.Repository{
+				Data: json.RawMessage(`{"git_tracking": {"mode": "latest-tag"}}`),
+			},
+			"",
+			"HEAD",
+		},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedRef, GetSourceRef(tc.r, tc.commit))
+	}
+}
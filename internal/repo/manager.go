@@ -19,12 +19,9 @@ import (
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/oci"
 	"github.com/artifacthub/hub/internal/util"
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/config"
-	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
-	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/rs/zerolog/log"
 	"github.com/satori/uuid"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v2"
@@ -39,10 +36,11 @@ const (
 	getRepoByIDDBQ            = `select get_repository_by_id($1::uuid, $2::boolean)`
 	getRepoByNameDBQ          = `select get_repository_by_name($1::text, $2::boolean)`
 	getRepoPkgsDigestDBQ      = `select get_repository_packages_digest($1::uuid)`
+	getRepoTrackingRunsDBQ    = `select get_repository_tracking_runs($1::text)`
 	getUserEmailDBQ           = `select email from "user" where user_id = $1`
 	searchRepositoriesDBQ     = `select * from search_repositories($1::jsonb)`
 	setLastScanningResultsDBQ = `select set_last_scanning_results($1::uuid, $2::text, $3::boolean)`
-	setLastTrackingResultsDBQ = `select set_last_tracking_results($1::uuid, $2::text, $3::boolean)`
+	setLastTrackingResultsDBQ = `select set_last_tracking_results($1::uuid, $2::text, $3::boolean, $4::jsonb, $5::int)`
 	setVerifiedPublisherDBQ   = `select set_verified_publisher($1::uuid, $2::boolean)`
 	transferRepoDBQ           = `select transfer_repository($1::text, $2::uuid, $3::text, $4::boolean)`
 	updateRepoDBQ             = `select update_repository($1::uuid, $2::jsonb)`
@@ -56,6 +54,10 @@ const (
 
 	artifacthubTag        = "artifacthub.io"
 	maxContainerImageTags = 10
+
+	// trackingRunsToKeep represents the number of tracking runs kept in the
+	// database for each repository.
+	trackingRunsToKeep = 25
 )
 
 var (
@@ -76,6 +78,15 @@ var (
 	// repository URL.
 	GitRepoURLRE = regexp.MustCompile(`^(https:\/\/([A-Za-z0-9_.-]+)\/[A-Za-z0-9_.-]+\/[A-Za-z0-9_.-]+)\/?(.*)$`)
 
+	// genericOCIKinds contains the generic repository kinds whose packages
+	// can be stored in an OCI registry.
+	genericOCIKinds = []hub.RepositoryKind{
+		hub.Falco,
+		hub.Gatekeeper,
+		hub.Kubewarden,
+		hub.OPA,
+	}
+
 	// validRepositoryKinds contains the repository kinds supported.
 	validRepositoryKinds = []hub.RepositoryKind{
 		hub.Container,
@@ -132,7 +143,7 @@ func NewManager(
 
 	// Setup repository cloner
 	if m.rc == nil {
-		m.rc = &Cloner{}
+		m.rc = NewCloner(hc)
 	}
 
 	return m
@@ -182,7 +193,7 @@ func (m *Manager) Add(ctx context.Context, orgName string, r *hub.Repository) er
 	if err := m.validateCredentials(r); err != nil {
 		return fmt.Errorf("%w: %s", hub.ErrInvalidInput, err.Error())
 	}
-	if err := validateData(r); err != nil {
+	if err := m.validateData(r); err != nil {
 		return fmt.Errorf("%w: %s", hub.ErrInvalidInput, err.Error())
 	}
 
@@ -198,8 +209,11 @@ func (m *Manager) Add(ctx context.Context, orgName string, r *hub.Repository) er
 	}
 
 	// Add repository to the database
-	rJSON, _ := json.Marshal(r)
-	_, err := m.db.Exec(ctx, addRepoDBQ, userID, orgName, rJSON)
+	rJSON, err := m.prepareRepositoryJSON(r)
+	if err != nil {
+		return err
+	}
+	_, err = m.db.Exec(ctx, addRepoDBQ, userID, orgName, rJSON)
 	if err != nil && err.Error() == util.ErrDBInsufficientPrivilege.Error() {
 		return hub.ErrInsufficientPrivilege
 	}
@@ -285,6 +299,10 @@ func (m *Manager) ClaimOwnership(ctx context.Context, repoName, orgName string)
 		hub.TBAction,
 		hub.TektonPipeline,
 		hub.TektonTask:
+		if SchemeIsOCI(u) {
+			// Metadata is pulled from the OCI registry
+			break
+		}
 		tmpDir, packagesPath, err := m.rc.CloneRepository(ctx, r)
 		if err != nil {
 			return err
@@ -361,8 +379,13 @@ func (m *Manager) GetByID(
 
 	// Get repository from database
 	var r *hub.Repository
-	err := util.DBQueryUnmarshal(ctx, m.db, &r, getRepoByIDDBQ, repositoryID, includeCredentials)
-	return r, err
+	if err := util.DBQueryUnmarshal(ctx, m.db, &r, getRepoByIDDBQ, repositoryID, includeCredentials); err != nil {
+		return nil, err
+	}
+	if err := m.decryptGitAuth(r); err != nil {
+		return nil, err
+	}
+	return r, nil
 }
 
 // GetByName returns the repository identified by the name provided.
@@ -378,8 +401,13 @@ func (m *Manager) GetByName(
 
 	// Get repository from database
 	var r *hub.Repository
-	err := util.DBQueryUnmarshal(ctx, m.db, &r, getRepoByNameDBQ, name, includeCredentials)
-	return r, err
+	if err := util.DBQueryUnmarshal(ctx, m.db, &r, getRepoByNameDBQ, name, includeCredentials); err != nil {
+		return nil, err
+	}
+	if err := m.decryptGitAuth(r); err != nil {
+		return nil, err
+	}
+	return r, nil
 }
 
 // GetMetadata reads and parses the metadata file of the repository provided.
@@ -389,6 +417,12 @@ func (m *Manager) GetMetadata(r *hub.Repository, basePath string) (*hub.Reposito
 	var data []byte
 	var err error
 
+	// Repositories mirroring a remote Artifact Hub instance do not provide a
+	// metadata file
+	if IsFederated(r) {
+		return nil, ErrMetadataNotFound
+	}
+
 	// Get metadata
 	mdFile := m.locateMetadataFile(r, basePath)
 	if strings.HasPrefix(mdFile, hub.RepositoryOCIPrefix) {
@@ -444,16 +478,22 @@ func (m *Manager) locateMetadataFile(r *hub.Repository, basePath string) string
 			mdFile = r.URL
 		}
 	case
-		hub.CoreDNS,
 		hub.Falco,
 		hub.Gatekeeper,
+		hub.Kubewarden,
+		hub.OPA:
+		if SchemeIsOCI(u) {
+			mdFile = r.URL
+		} else {
+			mdFile = filepath.Join(basePath, hub.RepositoryMetadataFile)
+		}
+	case
+		hub.CoreDNS,
 		hub.HelmPlugin,
 		hub.KedaScaler,
 		hub.Keptn,
 		hub.Krew,
-		hub.Kubewarden,
 		hub.OLM,
-		hub.OPA,
 		hub.TBAction,
 		hub.TektonPipeline,
 		hub.TektonTask:
@@ -522,6 +562,10 @@ func (m *Manager) GetRemoteDigest(ctx context.Context, r *hub.Repository) (strin
 	u, _ := url.Parse(r.URL)
 
 	switch {
+	case IsFederated(r):
+		// Do not track repo's digest for repositories mirroring a remote
+		// Artifact Hub instance, each package is checked individually
+
 	case r.Kind == hub.Helm:
 		switch {
 		case SchemeIsHTTP(u):
@@ -540,6 +584,14 @@ func (m *Manager) GetRemoteDigest(ctx context.Context, r *hub.Repository) (strin
 			digest = fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(versions, ","))))
 		}
 
+	case isGenericOCIKind(r.Kind) && SchemeIsOCI(u):
+		// Digest is obtained by hashing the list of versions available
+		versions, err := m.tg.Tags(ctx, r, true)
+		if err != nil {
+			return digest, err
+		}
+		digest = fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(versions, ","))))
+
 	case r.Kind == hub.OLM && SchemeIsOCI(u):
 		// Digest is obtained from the index image digest
 		refName := strings.TrimPrefix(r.URL, hub.RepositoryOCIPrefix)
@@ -565,34 +617,39 @@ func (m *Manager) GetRemoteDigest(ctx context.Context, r *hub.Repository) (strin
 			}
 		}
 
-		// Digest is obtained from the last commit in the repository
+		// Digest is obtained from the revision tracked in the repository
 		matches := GitRepoURLRE.FindStringSubmatch(r.URL)
-		repoBaseURL := matches[1]
-		remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
-			URLs: []string{repoBaseURL},
-		})
-		listOptions := &git.ListOptions{}
-		if r.AuthPass != "" {
-			listOptions.Auth = &githttp.BasicAuth{
-				Username: "artifact-hub",
-				Password: r.AuthPass,
-			}
+		auth, remoteURL, err := GitAuthMethod(ctx, m.hc, r, matches[1])
+		if err != nil {
+			return digest, err
 		}
-		refs, err := remote.List(listOptions)
+		refs, err := ListGitRefs(ctx, remoteURL, auth)
 		if err != nil {
 			return digest, err
 		}
-		branch := GetBranch(r)
-		for _, ref := range refs {
-			if ref.Name().IsBranch() && ref.Name().Short() == branch {
-				digest = ref.Hash().String()
-			}
+		ref, err := ResolveGitRef(refs, r)
+		if err != nil {
+			return digest, err
 		}
+		digest = ref.Hash.String()
 	}
 
 	return digest, nil
 }
 
+// GetTrackingRunsJSON returns the most recent tracking runs of the repository
+// identified by the name provided as a json array. The json array is built by
+// the database.
+func (m *Manager) GetTrackingRunsJSON(ctx context.Context, name string) ([]byte, error) {
+	// Validate input
+	if name == "" {
+		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "name not provided")
+	}
+
+	// Get repository tracking runs from database
+	return util.DBQueryJSON(ctx, m.db, getRepoTrackingRunsDBQ, name)
+}
+
 // Search searches for repositories in the database that the criteria defined
 // in the input provided.
 func (m *Manager) Search(
@@ -615,6 +672,17 @@ func (m *Manager) Search(
 		return nil, err
 	}
 
+	// Decrypt git auth settings. Repositories whose settings cannot be
+	// decrypted (i.e. after an encryption key rotation) are returned without
+	// them, so that they don't prevent the rest from being returned.
+	for _, r := range repositories {
+		if err := m.decryptGitAuth(r); err != nil {
+			log.Error().Err(err).Str("repository", r.Name).Msg("error preparing repository git auth settings")
+			r.GitAuth = nil
+			r.EncryptedGitAuth = ""
+		}
+	}
+
 	return &hub.SearchRepositoryResult{
 		Repositories: repositories,
 		TotalCount:   result.TotalCount,
@@ -652,8 +720,14 @@ func (m *Manager) SetLastScanningResults(ctx context.Context, repositoryID, errs
 }
 
 // SetLastTrackingResults updates the timestamp and errors of the last tracking
-// of the provided repository in the database.
-func (m *Manager) SetLastTrackingResults(ctx context.Context, repositoryID, errs string) error {
+// of the provided repository in the database. When a tracking run summary is
+// provided, it's added to the repository's tracking runs history.
+func (m *Manager) SetLastTrackingResults(
+	ctx context.Context,
+	repositoryID,
+	errs string,
+	run *hub.TrackingRun,
+) error {
 	// Validate input
 	if _, err := uuid.FromString(repositoryID); err != nil {
 		return fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid repository id")
@@ -661,7 +735,19 @@ func (m *Manager) SetLastTrackingResults(ctx context.Context, repositoryID, errs
 
 	// Update last tracking results in database
 	trackingErrorsEventsEnabled := m.cfg.GetBool("events.trackingErrors")
-	_, err := m.db.Exec(ctx, setLastTrackingResultsDBQ, repositoryID, errs, trackingErrorsEventsEnabled)
+	var runJSON []byte
+	if run != nil {
+		runJSON, _ = json.Marshal(run)
+	}
+	_, err := m.db.Exec(
+		ctx,
+		setLastTrackingResultsDBQ,
+		repositoryID,
+		errs,
+		trackingErrorsEventsEnabled,
+		runJSON,
+		trackingRunsToKeep,
+	)
 	return err
 }
 
@@ -739,7 +825,7 @@ func (m *Manager) Update(ctx context.Context, r *hub.Repository) error {
 	if err := m.validateCredentials(r); err != nil {
 		return fmt.Errorf("%w: %s", hub.ErrInvalidInput, err.Error())
 	}
-	if err := validateData(r); err != nil {
+	if err := m.validateData(r); err != nil {
 		return fmt.Errorf("%w: %s", hub.ErrInvalidInput, err.Error())
 	}
 
@@ -759,7 +845,10 @@ func (m *Manager) Update(ctx context.Context, r *hub.Repository) error {
 	}
 
 	// Update repository in database
-	rJSON, _ := json.Marshal(r)
+	rJSON, err := m.prepareRepositoryJSON(r)
+	if err != nil {
+		return err
+	}
 	_, err = m.db.Exec(ctx, updateRepoDBQ, userID, rJSON)
 	if err != nil && err.Error() == util.ErrDBInsufficientPrivilege.Error() {
 		return hub.ErrInsufficientPrivilege
@@ -788,6 +877,13 @@ func (m *Manager) validateURL(r *hub.Repository) error {
 	if u.User != nil {
 		return errors.New("urls with credentials not allowed")
 	}
+	if IsFederated(r) {
+		// The url must point to the remote Artifact Hub instance
+		if !SchemeIsHTTP(u) || u.Host == "" {
+			return errors.New("invalid url format")
+		}
+		return nil
+	}
 	switch r.Kind {
 	case hub.Container:
 		if !SchemeIsOCI(u) {
@@ -816,6 +912,9 @@ func (m *Manager) validateURL(r *hub.Repository) error {
 		if SchemeIsHTTP(u) && !GitRepoURLRE.MatchString(r.URL) {
 			return errors.New("invalid url format")
 		}
+		if SchemeIsOCI(u) && r.Kind != hub.OLM && !isGenericOCIKind(r.Kind) {
+			return errors.New("oci repositories not supported for this kind")
+		}
 	}
 	return nil
 }
@@ -823,14 +922,71 @@ func (m *Manager) validateURL(r *hub.Repository) error {
 // validateCredentials validates the credentials of the repository provided.
 func (m *Manager) validateCredentials(r *hub.Repository) error {
 	allowPrivateRepos := m.cfg.GetBool("server.allowPrivateRepositories")
-	if !allowPrivateRepos && (r.AuthUser != "" || r.AuthPass != "") {
+	hasGitAuth := r.GitAuth != nil && !r.GitAuth.IsZero()
+	if !allowPrivateRepos && (r.AuthUser != "" || r.AuthPass != "" || hasGitAuth) {
 		return errors.New("private repositories not allowed")
 	}
+	if hasGitAuth {
+		if !GitRepoURLRE.MatchString(r.URL) {
+			return errors.New("git auth settings only supported for git based repositories")
+		}
+		if m.cfg.GetString("db.encryptionKey") == "" {
+			return errors.New("git auth settings not supported: encryption key not configured")
+		}
+		if err := validateGitAuth(r.GitAuth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepareRepositoryJSON returns the json representation of the repository
+// provided that will be sent to the database. Git auth settings are encrypted
+// before storing them. A nil value means that the existing settings should be
+// kept, whereas an empty one means that they should be removed.
+func (m *Manager) prepareRepositoryJSON(r *hub.Repository) ([]byte, error) {
+	rCopy := *r
+	rCopy.GitAuth = nil
+	rCopy.EncryptedGitAuth = "="
+	if r.GitAuth != nil {
+		rCopy.EncryptedGitAuth = ""
+		if !r.GitAuth.IsZero() {
+			data, _ := json.Marshal(r.GitAuth)
+			encrypted, err := util.Encrypt(m.cfg.GetString("db.encryptionKey"), data)
+			if err != nil {
+				return nil, fmt.Errorf("error encrypting git auth settings: %w", err)
+			}
+			rCopy.EncryptedGitAuth = encrypted
+		}
+	}
+	return json.Marshal(rCopy)
+}
+
+// decryptGitAuth decrypts the git auth settings of the repository provided,
+// when available.
+func (m *Manager) decryptGitAuth(r *hub.Repository) error {
+	if r == nil || r.EncryptedGitAuth == "" {
+		return nil
+	}
+	data, err := util.Decrypt(m.cfg.GetString("db.encryptionKey"), r.EncryptedGitAuth)
+	if err != nil {
+		return fmt.Errorf("error decrypting git auth settings: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.GitAuth); err != nil {
+		return fmt.Errorf("error unmarshaling git auth settings: %w", err)
+	}
+	r.EncryptedGitAuth = ""
 	return nil
 }
 
 // validateData checks the kind specific data provided.
-func validateData(r *hub.Repository) error {
+func (m *Manager) validateData(r *hub.Repository) error {
+	if federation, err := GetFederation(r); err == nil && federation != nil {
+		if err := validateFederation(m.cfg, r, federation); err != nil {
+			return fmt.Errorf("invalid federation settings: %w", err)
+		}
+		return nil
+	}
 	switch r.Kind {
 	case hub.Container:
 		if r.Data != nil {
@@ -844,6 +1000,11 @@ func validateData(r *hub.Repository) error {
 		}
 		return nil
 	default:
+		if GitRepoURLRE.MatchString(r.URL) {
+			if err := validateGitTracking(r); err != nil {
+				return fmt.Errorf("invalid git tracking settings: %w", err)
+			}
+		}
 		return nil
 	}
 }
@@ -881,6 +1042,17 @@ func isSchemeSupported(u *url.URL) bool {
 	return SchemeIsHTTP(u) || SchemeIsOCI(u)
 }
 
+// isGenericOCIKind checks if the provided repository kind is one of the
+// generic kinds whose packages can be stored in an OCI registry.
+func isGenericOCIKind(kind hub.RepositoryKind) bool {
+	for _, k := range genericOCIKinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
+
 // isValidKind checks if the provided repository kind is valid.
 func isValidKind(kind hub.RepositoryKind) bool {
 	for _, validKind := range validRepositoryKinds {
@@ -28,7 +28,11 @@ import (
 
 const repoID = "00000000-0000-0000-0000-000000000001"
 
-var cfg = viper.New()
+var cfg = func() *viper.Viper {
+	cfg := viper.New()
+	cfg.Set("federation.allowedURLs", []string{"https://hub.url"})
+	return cfg
+}()
 
 func TestAdd(t *testing.T) {
 	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
@@ -165,6 +169,16 @@ func TestAdd(t *testing.T) {
 				},
 				nil,
 			},
+			{
+				"oci repositories not supported for this kind",
+				"org1",
+				&hub.Repository{
+					Kind: hub.Krew,
+					Name: "repo1",
+					URL:  "oci://registry.io/namespace/repo",
+				},
+				nil,
+			},
 			{
 				"the url provided does not point to a valid Helm repository",
 				"org1",
@@ -223,6 +237,61 @@ func TestAdd(t *testing.T) {
 				},
 				nil,
 			},
+			{
+				"invalid url format",
+				"org1",
+				&hub.Repository{
+					Kind: hub.Helm,
+					Name: "repo1",
+					URL:  "oci://registry.io/namespace/repo",
+					Data: json.RawMessage(`{"federation": {}}`),
+				},
+				nil,
+			},
+			{
+				"invalid federation settings: remote Artifact Hub instance not allowed",
+				"org1",
+				&hub.Repository{
+					Kind: hub.Helm,
+					Name: "repo1",
+					URL:  "https://other-hub.url",
+					Data: json.RawMessage(`{"federation": {"repositories": ["repo1"]}}`),
+				},
+				nil,
+			},
+			{
+				"invalid federation settings: invalid repository name",
+				"org1",
+				&hub.Repository{
+					Kind: hub.Helm,
+					Name: "repo1",
+					URL:  "https://hub.url",
+					Data: json.RawMessage(`{"federation": {"repositories": [""]}}`),
+				},
+				nil,
+			},
+			{
+				"invalid federation settings: invalid organization name",
+				"org1",
+				&hub.Repository{
+					Kind: hub.OPA,
+					Name: "repo1",
+					URL:  "https://hub.url",
+					Data: json.RawMessage(`{"federation": {"organizations": [""]}}`),
+				},
+				nil,
+			},
+			{
+				"invalid git tracking settings: ref must be provided when using the ref tracking mode",
+				"org1",
+				&hub.Repository{
+					Kind: hub.OPA,
+					Name: "repo1",
+					URL:  "https://github.com/org1/repo1/path",
+					Data: json.RawMessage(`{"git_tracking": {"mode": "ref"}}`),
+				},
+				nil,
+			},
 		}
 		for _, tc := range testCases {
 			tc := tc
@@ -268,6 +337,51 @@ func TestAdd(t *testing.T) {
 		az.AssertExpectations(t)
 	})
 
+	t.Run("git auth settings not supported without encryption key", func(t *testing.T) {
+		t.Parallel()
+		cfg := viper.New()
+		cfg.Set("server.allowPrivateRepositories", true)
+		r := &hub.Repository{
+			Name:    "repo1",
+			URL:     "https://github.com/org/repo",
+			Kind:    hub.OPA,
+			GitAuth: &hub.GitAuth{Username: "user"},
+		}
+		m := NewManager(cfg, nil, nil, nil)
+
+		err := m.Add(ctx, "", r)
+		assert.True(t, errors.Is(err, hub.ErrInvalidInput))
+		assert.Contains(t, err.Error(), "encryption key not configured")
+	})
+
+	t.Run("git auth settings are stored encrypted", func(t *testing.T) {
+		t.Parallel()
+		cfg := viper.New()
+		cfg.Set("server.allowPrivateRepositories", true)
+		cfg.Set("db.encryptionKey", "key")
+		r := &hub.Repository{
+			Name:    "repo1",
+			URL:     "https://github.com/org/repo",
+			Kind:    hub.OPA,
+			GitAuth: &hub.GitAuth{Username: "user"},
+		}
+		db := &tests.DBMock{}
+		db.On("Exec", ctx, addRepoDBQ, "userID", "", mock.MatchedBy(func(rJSON []byte) bool {
+			var stored map[string]interface{}
+			_ = json.Unmarshal(rJSON, &stored)
+			if _, ok := stored["git_auth"]; ok {
+				return false
+			}
+			data, err := util.Decrypt("key", stored["encrypted_git_auth"].(string))
+			return err == nil && string(data) == `{"username":"user"}`
+		})).Return(nil)
+		m := NewManager(cfg, db, nil, nil)
+
+		err := m.Add(ctx, "", r)
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+
 	t.Run("database error", func(t *testing.T) {
 		testCases := []struct {
 			r             *hub.Repository
@@ -340,6 +454,15 @@ func TestAdd(t *testing.T) {
 					Kind:        hub.OLM,
 				},
 			},
+			{
+				&hub.Repository{
+					Name:        "repo3",
+					DisplayName: "Repository 3",
+					URL:         "https://hub.url",
+					Kind:        hub.Helm,
+					Data:        json.RawMessage(`{"federation": {"repositories": ["repo1"]}}`),
+				},
+			},
 		}
 		for i, tc := range testCases {
 			tc := tc
@@ -354,7 +477,7 @@ func TestAdd(t *testing.T) {
 					Action:           hub.AddOrganizationRepository,
 				}).Return(nil)
 				l := &HelmIndexLoaderMock{}
-				if tc.r.Kind == hub.Helm {
+				if tc.r.Kind == hub.Helm && !IsFederated(tc.r) {
 					l.On("LoadIndex", tc.r).Return(nil, "", nil)
 				}
 				m := NewManager(cfg, db, az, nil, WithHelmIndexLoader(l))
@@ -799,6 +922,30 @@ func TestGetByID(t *testing.T) {
 		assert.True(t, r.Official)
 		db.AssertExpectations(t)
 	})
+
+	t.Run("database query succeeded, git auth settings decrypted", func(t *testing.T) {
+		t.Parallel()
+		cfg := viper.New()
+		cfg.Set("db.encryptionKey", "key")
+		encryptedGitAuth, _ := util.Encrypt("key", []byte(`{"username": "user"}`))
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getRepoByIDDBQ, repoID, true).Return([]byte(fmt.Sprintf(`
+		{
+			"repository_id": "00000000-0000-0000-0000-000000000001",
+			"name": "repo1",
+			"url": "https://github.com/org/repo",
+			"kind": 1,
+			"encrypted_git_auth": "%s"
+		}
+		`, encryptedGitAuth)), nil)
+		m := NewManager(cfg, db, nil, nil)
+
+		r, err := m.GetByID(context.Background(), repoID, true)
+		require.NoError(t, err)
+		assert.Equal(t, &hub.GitAuth{Username: "user"}, r.GitAuth)
+		assert.Empty(t, r.EncryptedGitAuth)
+		db.AssertExpectations(t)
+	})
 }
 
 func TestGetByName(t *testing.T) {
@@ -895,6 +1042,19 @@ func TestGetMetadata(t *testing.T) {
 		assert.Contains(t, err.Error(), "metadata not found")
 	})
 
+	t.Run("federated repository: metadata file not available", func(t *testing.T) {
+		t.Parallel()
+		m := NewManager(cfg, nil, nil, nil)
+
+		r := &hub.Repository{
+			Kind: hub.Helm,
+			URL:  repoURL,
+			Data: json.RawMessage(`{"federation": {}}`),
+		}
+		_, err := m.GetMetadata(r, "")
+		assert.True(t, errors.Is(err, ErrMetadataNotFound))
+	})
+
 	t.Run("local file: error unmarshaling repository metadata file", func(t *testing.T) {
 		t.Parallel()
 		m := NewManager(cfg, nil, nil, nil)
@@ -1211,6 +1371,11 @@ func TestGetRemoteDigest(t *testing.T) {
 		Name: "repo1",
 		URL:  "oci://myrepo.url/chart",
 	}
+	falcoOCI := &hub.Repository{
+		Kind: hub.Falco,
+		Name: "repo1",
+		URL:  "oci://myrepo.url/rules",
+	}
 
 	t.Run("helm-http: error loading index", func(t *testing.T) {
 		t.Parallel()
@@ -1259,6 +1424,81 @@ func TestGetRemoteDigest(t *testing.T) {
 		assert.Nil(t, err)
 		tg.AssertExpectations(t)
 	})
+
+	t.Run("generic-oci: error getting tags", func(t *testing.T) {
+		t.Parallel()
+		tg := &oci.TagsGetterMock{}
+		tg.On("Tags", ctx, falcoOCI, true).Return(nil, tests.ErrFake)
+		m := NewManager(cfg, nil, nil, nil, WithOCITagsGetter(tg))
+
+		digest, err := m.GetRemoteDigest(ctx, falcoOCI)
+		assert.Empty(t, digest)
+		assert.Equal(t, tests.ErrFake, err)
+		tg.AssertExpectations(t)
+	})
+
+	t.Run("generic-oci: success", func(t *testing.T) {
+		t.Parallel()
+		tg := &oci.TagsGetterMock{}
+		tg.On("Tags", ctx, falcoOCI, true).Return([]string{"2.0.0", "1.0.0"}, nil)
+		m := NewManager(cfg, nil, nil, nil, WithOCITagsGetter(tg))
+
+		digest, err := m.GetRemoteDigest(ctx, falcoOCI)
+		assert.Equal(t, "32b4478532e3fbd46940cfaa0b288bc328817cec9ef38e81c9d19a803bcff285", digest)
+		assert.Nil(t, err)
+		tg.AssertExpectations(t)
+	})
+
+	t.Run("federated: digest not tracked", func(t *testing.T) {
+		t.Parallel()
+		l := &HelmIndexLoaderMock{}
+		m := NewManager(cfg, nil, nil, nil, WithHelmIndexLoader(l))
+
+		digest, err := m.GetRemoteDigest(ctx, &hub.Repository{
+			Kind: hub.Helm,
+			Name: "repo1",
+			URL:  "https://hub.url",
+			Data: json.RawMessage(`{"federation": {}}`),
+		})
+		assert.Empty(t, digest)
+		assert.Nil(t, err)
+		l.AssertExpectations(t)
+	})
+}
+
+func TestGetTrackingRunsJSON(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("invalid input", func(t *testing.T) {
+		t.Parallel()
+		m := NewManager(cfg, nil, nil, nil)
+		_, err := m.GetTrackingRunsJSON(ctx, "")
+		assert.True(t, errors.Is(err, hub.ErrInvalidInput))
+	})
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getRepoTrackingRunsDBQ, "repo1").Return([]byte("dataJSON"), nil)
+		m := NewManager(cfg, db, nil, nil)
+
+		dataJSON, err := m.GetTrackingRunsJSON(ctx, "repo1")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getRepoTrackingRunsDBQ, "repo1").Return(nil, tests.ErrFakeDB)
+		m := NewManager(cfg, db, nil, nil)
+
+		dataJSON, err := m.GetTrackingRunsJSON(ctx, "repo1")
+		assert.Equal(t, tests.ErrFakeDB, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
 }
 
 func TestSearch(t *testing.T) {
@@ -1362,6 +1602,42 @@ func TestSearch(t *testing.T) {
 		db.AssertExpectations(t)
 	})
 
+	t.Run("repositories with git auth settings that cannot be decrypted are returned without them", func(t *testing.T) {
+		t.Parallel()
+		cfg := viper.New()
+		cfg.Set("db.encryptionKey", "key")
+		encryptedGitAuth, _ := util.Encrypt("key", []byte(`{"username": "user"}`))
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, searchRepositoriesDBQ, mock.Anything).Return([]interface{}{[]byte(fmt.Sprintf(`
+	[
+		{
+			"repository_id": "00000000-0000-0000-0000-000000000001",
+			"name": "repo1",
+			"kind": 1,
+			"encrypted_git_auth": "%s"
+		},
+		{
+			"repository_id": "00000000-0000-0000-0000-000000000002",
+			"name": "repo2",
+			"kind": 1,
+			"encrypted_git_auth": "invalid"
+		}
+	]
+	`, encryptedGitAuth)), 2}, nil)
+		m := NewManager(cfg, db, nil, nil)
+
+		result, err := m.Search(ctx, input)
+		require.NoError(t, err)
+		require.Len(t, result.Repositories, 2)
+		assert.Equal(t, 2, result.TotalCount)
+		assert.Equal(t, "repo1", result.Repositories[0].Name)
+		assert.Equal(t, &hub.GitAuth{Username: "user"}, result.Repositories[0].GitAuth)
+		assert.Equal(t, "repo2", result.Repositories[1].Name)
+		assert.Nil(t, result.Repositories[1].GitAuth)
+		assert.Empty(t, result.Repositories[1].EncryptedGitAuth)
+		db.AssertExpectations(t)
+	})
+
 	t.Run("database error", func(t *testing.T) {
 		t.Parallel()
 		db := &tests.DBMock{}
@@ -1472,21 +1748,45 @@ func TestSetLastScanningResults(t *testing.T) {
 
 func TestSetLastTrackingResults(t *testing.T) {
 	ctx := context.Background()
+	run := &hub.TrackingRun{
+		StartedAt: 1,
+		TrackingRunStats: hub.TrackingRunStats{
+			PackagesRegistered: 1,
+		},
+		ErrorsCount: 1,
+		Errors: []*hub.ErrorRecord{
+			{Class: hub.ErrorClassGeneric, Message: "errors"},
+		},
+	}
+	runJSON, _ := json.Marshal(run)
 
 	t.Run("invalid input", func(t *testing.T) {
 		t.Parallel()
 		m := NewManager(cfg, nil, nil, nil)
-		err := m.SetLastTrackingResults(ctx, "invalid", "errors")
+		err := m.SetLastTrackingResults(ctx, "invalid", "errors", nil)
 		assert.True(t, errors.Is(err, hub.ErrInvalidInput))
 	})
 
 	t.Run("database update succeeded", func(t *testing.T) {
 		t.Parallel()
 		db := &tests.DBMock{}
-		db.On("Exec", ctx, setLastTrackingResultsDBQ, repoID, "errors", false).Return(nil)
+		db.On("Exec", ctx, setLastTrackingResultsDBQ, repoID, "errors", false, []byte(nil), trackingRunsToKeep).
+			Return(nil)
+		m := NewManager(cfg, db, nil, nil)
+
+		err := m.SetLastTrackingResults(ctx, repoID, "errors", nil)
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database update with tracking run succeeded", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("Exec", ctx, setLastTrackingResultsDBQ, repoID, "errors", false, runJSON, trackingRunsToKeep).
+			Return(nil)
 		m := NewManager(cfg, db, nil, nil)
 
-		err := m.SetLastTrackingResults(ctx, repoID, "errors")
+		err := m.SetLastTrackingResults(ctx, repoID, "errors", run)
 		assert.NoError(t, err)
 		db.AssertExpectations(t)
 	})
@@ -1494,10 +1794,11 @@ func TestSetLastTrackingResults(t *testing.T) {
 	t.Run("database error", func(t *testing.T) {
 		t.Parallel()
 		db := &tests.DBMock{}
-		db.On("Exec", ctx, setLastTrackingResultsDBQ, repoID, "errors", false).Return(tests.ErrFakeDB)
+		db.On("Exec", ctx, setLastTrackingResultsDBQ, repoID, "errors", false, runJSON, trackingRunsToKeep).
+			Return(tests.ErrFakeDB)
 		m := NewManager(cfg, db, nil, nil)
 
-		err := m.SetLastTrackingResults(ctx, repoID, "errors")
+		err := m.SetLastTrackingResults(ctx, repoID, "errors", run)
 		assert.Equal(t, tests.ErrFakeDB, err)
 		db.AssertExpectations(t)
 	})
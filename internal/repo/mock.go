@@ -24,9 +24,10 @@ type ErrorsCollectorMock struct {
 	mock.Mock
 }
 
-// Append implements the ErrorsCollector interface.
-func (m *ErrorsCollectorMock) Append(repositoryID string, err string) {
-	m.Called(repositoryID, err)
+// Append implements the ErrorsCollector interface. The error message is used
+// as argument, so that expectations can be defined using it.
+func (m *ErrorsCollectorMock) Append(repositoryID string, err error) {
+	m.Called(repositoryID, err.Error())
 }
 
 // Flush implements the ErrorsCollector interface.
@@ -39,6 +40,11 @@ func (m *ErrorsCollectorMock) Init(repositoryID string) {
 	m.Called(repositoryID)
 }
 
+// SetTrackingRunStats implements the ErrorsCollector interface.
+func (m *ErrorsCollectorMock) SetTrackingRunStats(repositoryID string, stats *hub.TrackingRunStats) {
+	m.Called(repositoryID, stats)
+}
+
 // HelmIndexLoaderMock is a mock implementation of the HelmIndexLoader
 // interface.
 type HelmIndexLoaderMock struct {
@@ -126,6 +132,13 @@ func (m *ManagerMock) GetRemoteDigest(ctx context.Context, r *hub.Repository) (s
 	return args.String(0), args.Error(1)
 }
 
+// GetTrackingRunsJSON implements the RepositoryManager interface.
+func (m *ManagerMock) GetTrackingRunsJSON(ctx context.Context, name string) ([]byte, error) {
+	args := m.Called(ctx, name)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
 // Search implements the RepositoryManager interface.
 func (m *ManagerMock) Search(
 	ctx context.Context,
@@ -153,8 +166,13 @@ func (m *ManagerMock) SetLastScanningResults(ctx context.Context, repositoryID,
 }
 
 // SetLastTrackingResults implements the RepositoryManager interface.
-func (m *ManagerMock) SetLastTrackingResults(ctx context.Context, repositoryID, errs string) error {
-	args := m.Called(ctx, repositoryID, errs)
+func (m *ManagerMock) SetLastTrackingResults(
+	ctx context.Context,
+	repositoryID,
+	errs string,
+	run *hub.TrackingRun,
+) error {
+	args := m.Called(ctx, repositoryID, errs, run)
 	return args.Error(0)
 }
 
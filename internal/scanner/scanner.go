@@ -92,7 +92,7 @@ func (s *Scanner) Scan(sn *hub.SnapshotToScan) (*hub.SnapshotSecurityReport, err
 		imageReportJSON, err := s.is.ScanImage(image.Image)
 		if err != nil {
 			err := fmt.Errorf("error scanning image %s: %w (package %s:%s)", image.Image, err, sn.PackageName, sn.Version)
-			s.ec.Append(sn.RepositoryID, err.Error())
+			s.ec.Append(sn.RepositoryID, err)
 			return report, err
 		}
 		var imageReport *trivy.Report
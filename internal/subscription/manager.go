@@ -20,7 +20,7 @@ const (
 	getRepoSubscriptorsDBQ     = `select get_repository_subscriptors($1::uuid, $2::integer)`
 	getUserOptOutEntriesDBQ    = `select * from get_user_opt_out_entries($1::uuid, $2::int, $3::int)`
 	getUserPkgSubscriptionsDBQ = `select get_user_package_subscriptions($1::uuid, $2::uuid)`
-	getUserSubscriptionsDBQ    = `select * from get_user_subscriptions($1::uuid, $2::int, $3::int)`
+	getUserSubscriptionsDBQ    = `select * from get_user_subscriptions($1::uuid, $2::int, $3::int, $4::jsonb)`
 )
 
 var (
@@ -31,6 +31,13 @@ var (
 	}
 )
 
+// subscriptionsCursor represents the key of the cursor used to paginate the
+// subscriptions of a user.
+type subscriptionsCursor struct {
+	NormalizedName string    `json:"normalized_name"`
+	PackageID      uuid.UUID `json:"package_id"`
+}
+
 // Manager provides an API to manage subscriptions.
 type Manager struct {
 	db hub.DB
@@ -103,7 +110,11 @@ func (m *Manager) GetByPackageJSON(ctx context.Context, packageID string) ([]byt
 // as json array of objects.
 func (m *Manager) GetByUserJSON(ctx context.Context, p *hub.Pagination) (*hub.JSONQueryResult, error) {
 	userID := ctx.Value(hub.UserIDKey).(string)
-	return util.DBQueryJSONWithPagination(ctx, m.db, getUserSubscriptionsDBQ, userID, p.Limit, p.Offset)
+	cursorKey, err := util.DecodeCursor(p.Cursor, &subscriptionsCursor{})
+	if err != nil {
+		return nil, err
+	}
+	return util.DBQueryJSONWithCursor(ctx, m.db, getUserSubscriptionsDBQ, userID, p.Limit, p.Offset, cursorKey)
 }
 
 // GetOptOutListJSON returns all the opt-out entries of the user doing the
@@ -353,7 +353,7 @@ func TestGetByUserJSON(t *testing.T) {
 	t.Run("database query succeeded", func(t *testing.T) {
 		t.Parallel()
 		db := &tests.DBMock{}
-		db.On("QueryRow", ctx, getUserSubscriptionsDBQ, userID, 10, 1).
+		db.On("QueryRow", ctx, getUserSubscriptionsDBQ, userID, 10, 1, []byte(nil)).
 			Return([]interface{}{[]byte("dataJSON"), 1}, nil)
 		m := NewManager(db)
 
@@ -367,7 +367,7 @@ func TestGetByUserJSON(t *testing.T) {
 	t.Run("database error", func(t *testing.T) {
 		t.Parallel()
 		db := &tests.DBMock{}
-		db.On("QueryRow", ctx, getUserSubscriptionsDBQ, userID, 10, 1).Return(nil, tests.ErrFakeDB)
+		db.On("QueryRow", ctx, getUserSubscriptionsDBQ, userID, 10, 1, []byte(nil)).Return(nil, tests.ErrFakeDB)
 		m := NewManager(db)
 
 		result, err := m.GetByUserJSON(ctx, p)
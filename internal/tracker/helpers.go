@@ -6,8 +6,10 @@ import (
 	"regexp"
 
 	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/repo"
 	"github.com/artifacthub/hub/internal/tracker/source/container"
 	"github.com/artifacthub/hub/internal/tracker/source/falco"
+	"github.com/artifacthub/hub/internal/tracker/source/federation"
 	"github.com/artifacthub/hub/internal/tracker/source/generic"
 	"github.com/artifacthub/hub/internal/tracker/source/helm"
 	"github.com/artifacthub/hub/internal/tracker/source/helmplugin"
@@ -90,6 +92,12 @@ func GetRepositories(
 // SetupSource returns the tracker source that should be used for the
 // repository provided.
 func SetupSource(i *hub.TrackerSourceInput) hub.TrackerSource {
+	// Repositories mirroring a remote Artifact Hub instance use the same
+	// source regardless of their kind
+	if repo.IsFederated(i.Repository) {
+		return federation.NewTrackerSource(i)
+	}
+
 	var source hub.TrackerSource
 	switch i.Repository.Kind {
 	case hub.Container:
@@ -143,8 +151,9 @@ func setVerifiedPublisherFlag(
 	return nil
 }
 
-// shouldIgnorePackage checks if the package provided should be ignored.
-func shouldIgnorePackage(md *hub.RepositoryMetadata, name, version string) bool {
+// ShouldIgnorePackage checks if the package provided should be ignored
+// according to the ignore entries in the repository metadata provided.
+func ShouldIgnorePackage(md *hub.RepositoryMetadata, name, version string) bool {
 	if md == nil {
 		return false
 	}
@@ -2,6 +2,7 @@ package tracker
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
@@ -192,6 +193,14 @@ func TestSetupSource(t *testing.T) {
 			},
 			"*helm.TrackerSource",
 		},
+		{
+			&hub.Repository{
+				Kind: hub.Helm,
+				URL:  "https://hub.url",
+				Data: json.RawMessage(`{"federation": {}}`),
+			},
+			"*federation.TrackerSource",
+		},
 		{
 			&hub.Repository{
 				Kind: hub.HelmPlugin,
@@ -447,7 +456,7 @@ func TestShouldIgnorePackage(t *testing.T) {
 		t.Run(fmt.Sprintf("Test case %d", i), func(t *testing.T) {
 			t.Parallel()
 
-			result := shouldIgnorePackage(tc.md, tc.name, tc.version)
+			result := ShouldIgnorePackage(tc.md, tc.name, tc.version)
 			assert.Equal(t, tc.expectedResult, result)
 		})
 	}
@@ -20,9 +20,12 @@ import (
 	"github.com/artifacthub/hub/internal/oci"
 	"github.com/artifacthub/hub/internal/pkg"
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/hashicorp/go-multierror"
+	ispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/viper"
 )
 
@@ -61,7 +64,7 @@ var (
 	errUnsupportedMediaType = errors.New("image media type not supported")
 
 	// errInvalidAnnotation indicates that the annotation provided is not valid.
-	errInvalidAnnotation = errors.New("invalid annotation")
+	errInvalidAnnotation = pkg.NewValidationError(pkg.ErrInvalidValue, "invalid annotation")
 
 	// requiredMetadata represents the fields that must be present in the image
 	// metadata.
@@ -139,7 +142,11 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 			}()
 			p, err := PreparePackage(s.i.Svc.Ctx, s.i.Svc.Cfg, s.i.Svc.Hc, s.i.Svc.Is, s.i.Svc.Sc, s.i.Repository, tag)
 			if err != nil {
-				s.warn(fmt.Errorf("error preparing package (tag: %s): %w", tag, err))
+				s.warn(&hub.RepositoryError{
+					Version: tag,
+					Class:   hub.ErrorClassPackage,
+					Err:     fmt.Errorf("error preparing package (tag: %s): %w", tag, err),
+				})
 				return
 			}
 			mu.Lock()
@@ -156,7 +163,7 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 // logs it as a warning.
 func (s *TrackerSource) warn(err error) {
 	s.i.Svc.Logger.Warn().Err(err).Send()
-	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err.Error())
+	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err)
 }
 
 // PreparePackage prepares a package version from the metadata available in the
@@ -172,16 +179,77 @@ func PreparePackage(
 ) (*hub.Package, error) {
 	// Get container image metadata
 	imageRef := fmt.Sprintf("%s:%s", strings.TrimPrefix(r.URL, hub.RepositoryOCIPrefix), tag)
-	md, err := getMetadata(ctx, cfg, r, imageRef)
+	md, err := GetMetadata(ctx, cfg, r, imageRef)
 	if err != nil {
 		return nil, fmt.Errorf("error getting metadata: %w", err)
 	}
 
+	// Prepare package from metadata
+	var errs *multierror.Error
+	p, err := PreparePackageFromMetadata(r, tag, md)
+	if err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	// Readme
+	readme, err := GetReadme(ctx, hc, md)
+	if err != nil {
+		errs = multierror.Append(errs, err)
+	} else {
+		p.Readme = readme
+	}
+
+	// Store logo when available if requested
+	if p.LogoURL != "" {
+		logoImageID, err := is.DownloadAndSaveImage(ctx, p.LogoURL)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("error downloading logo image: %w", err))
+		} else {
+			p.LogoImageID = logoImageID
+		}
+	}
+
+	// Signature (keyless verification only, as no sign key can be provided
+	// for container images)
+	verification, err := sc.VerifyCosignSignature(
+		ctx,
+		imageRef,
+		r.AuthUser,
+		r.AuthPass,
+		nil,
+	)
+	if err != nil {
+		errs = multierror.Append(errs, fmt.Errorf("error checking cosign signature: %w", err))
+	} else if verification != nil {
+		p.Signed = true
+		p.Signatures = []string{oci.Cosign}
+		p.SignatureVerification = verification
+	}
+
+	// Provenance
+	provenance, err := sc.GetProvenance(ctx, imageRef, r.AuthUser, r.AuthPass)
+	if err != nil {
+		errs = multierror.Append(errs, fmt.Errorf("error getting provenance: %w", err))
+	} else {
+		p.Provenance = provenance
+	}
+
+	if errs.ErrorOrNil() != nil {
+		return nil, errs
+	}
+	return p, nil
+}
+
+// PreparePackageFromMetadata prepares a package version from the container
+// image metadata provided, validating it. The package returned is never nil,
+// even when some errors were found, so that the information available can
+// still be used (i.e. by the linter). The readme and logo are not fetched.
+func PreparePackageFromMetadata(r *hub.Repository, tag string, md map[string]string) (*hub.Package, error) {
 	// Check required metadata fields are present
 	var errs *multierror.Error
 	for _, key := range requiredMetadata {
 		if _, ok := md[key]; !ok {
-			errs = multierror.Append(errs, fmt.Errorf("required metadata field not provided: %s", key))
+			errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "required metadata field not provided: %s", key))
 		}
 	}
 
@@ -195,6 +263,7 @@ func PreparePackage(
 		Digest:      md[digestAnnotation],
 		AppVersion:  md[appVersionAnnotation],
 		License:     md[licenseAnnotation],
+		LogoURL:     md[logoURLAnnotation],
 		Provider:    md[vendorAnnotation],
 		ContainersImages: []*hub.ContainerImage{
 			{
@@ -215,16 +284,6 @@ func PreparePackage(
 		}
 	}
 
-	// Readme
-	if v, ok := md[readmeURLAnnotation]; ok {
-		data, err := getContent(ctx, hc, v)
-		if err != nil {
-			errs = multierror.Append(errs, fmt.Errorf("error getting readme file content: %w", err))
-		} else {
-			p.Readme = string(data)
-		}
-	}
-
 	// Keywords
 	if v, ok := md[keywordsAnnotation]; ok && v != "" {
 		var keywords []string
@@ -234,17 +293,6 @@ func PreparePackage(
 		p.Keywords = keywords
 	}
 
-	// Store logo when available if requested
-	if v, ok := md[logoURLAnnotation]; ok {
-		logoImageID, err := is.DownloadAndSaveImage(ctx, v)
-		if err != nil {
-			errs = multierror.Append(errs, fmt.Errorf("error downloading logo image: %w", err))
-		} else {
-			p.LogoURL = v
-			p.LogoImageID = logoImageID
-		}
-	}
-
 	// Links
 	var links []*hub.Link
 	if v, ok := md[documentationURLAnnotation]; ok {
@@ -315,30 +363,47 @@ func PreparePackage(
 		p.Data["alternativeLocations"] = alternativeLocations
 	}
 
-	// Signature
-	hasCosignSignature, err := sc.HasCosignSignature(
-		ctx,
-		imageRef,
-		r.AuthUser,
-		r.AuthPass,
-	)
-	if err != nil {
-		errs = multierror.Append(errs, fmt.Errorf("error checking cosign signature: %w", err))
-	} else if hasCosignSignature {
-		p.Signed = true
-		p.Signatures = []string{oci.Cosign}
+	return p, errs.ErrorOrNil()
+}
+
+// ValidateMetadata performs some additional checks on the container image
+// metadata provided, like verifying that all maintainers have a name or that
+// the alternative locations are valid image references. These checks are
+// stricter than the ones performed when the image is processed by the tracker,
+// so they are only used by the linter.
+func ValidateMetadata(md map[string]string) error {
+	var errs *multierror.Error
+
+	// Maintainers
+	if v, ok := md[maintainersAnnotation]; ok {
+		var maintainers []*hub.Maintainer
+		if err := json.Unmarshal([]byte(v), &maintainers); err == nil {
+			for _, m := range maintainers {
+				if m == nil || m.Name == "" {
+					errs = multierror.Append(errs, fmt.Errorf("%w: maintainer name not provided", errInvalidAnnotation))
+					break
+				}
+			}
+		}
 	}
 
-	if errs.ErrorOrNil() != nil {
-		return nil, errs
+	// Alternative locations
+	if v, ok := md[alternativeLocationsAnnotation]; ok && v != "" {
+		for _, l := range strings.Split(v, ",") {
+			l = strings.TrimSpace(l)
+			if _, err := name.ParseReference(l); err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("%w: invalid alternative location: %s", errInvalidAnnotation, l))
+			}
+		}
 	}
-	return p, nil
+
+	return errs.ErrorOrNil()
 }
 
-// getMetadata returns the metadata available in annotations and labels in the
+// GetMetadata returns the metadata available in annotations and labels in the
 // container image identified by the reference provided. Depending on the image
 // media type the metadata will be obtained from annotations or labels.
-func getMetadata(
+func GetMetadata(
 	ctx context.Context,
 	cfg *viper.Viper,
 	r *hub.Repository,
@@ -360,6 +425,94 @@ func getMetadata(
 	if err != nil {
 		return nil, err
 	}
+	index, err := desc.ImageIndex()
+	if err != nil {
+		index = nil
+	}
+	return getImageMetadata(image, index)
+}
+
+// GetLayoutMetadata returns the metadata of the container images available in
+// the OCI image layout located at the path provided, keyed by the image tag
+// (obtained from the org.opencontainers.image.ref.name annotation). When an
+// image index is found, the metadata is obtained from the image for the
+// linux/amd64 platform (or the first one available).
+func GetLayoutMetadata(layoutPath string) (map[string]map[string]string, error) {
+	lp, err := layout.FromPath(layoutPath)
+	if err != nil {
+		return nil, err
+	}
+	rootIndex, err := lp.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	rootIndexManifest, err := rootIndex.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	mds := make(map[string]map[string]string)
+	for _, m := range rootIndexManifest.Manifests {
+		tag := m.Annotations[ispec.AnnotationRefName]
+		if tag == "" {
+			tag = "latest"
+		}
+		var image v1.Image
+		var index v1.ImageIndex
+		switch {
+		case m.MediaType.IsIndex():
+			index, err = rootIndex.ImageIndex(m.Digest)
+			if err != nil {
+				return nil, err
+			}
+			image, err = getIndexImage(index)
+		case m.MediaType.IsImage():
+			image, err = rootIndex.Image(m.Digest)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		md, err := getImageMetadata(image, index)
+		if err != nil {
+			return nil, fmt.Errorf("error getting image %s metadata: %w", tag, err)
+		}
+		mds[tag] = md
+	}
+	return mds, nil
+}
+
+// getIndexImage returns the image for the linux/amd64 platform available in
+// the image index provided, or the first one available if not found.
+func getIndexImage(index v1.ImageIndex) (v1.Image, error) {
+	indexManifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	var selected *v1.Descriptor
+	for i, m := range indexManifest.Manifests {
+		if !m.MediaType.IsImage() {
+			continue
+		}
+		if selected == nil {
+			selected = &indexManifest.Manifests[i]
+		}
+		if m.Platform != nil && m.Platform.OS == "linux" && m.Platform.Architecture == "amd64" {
+			selected = &indexManifest.Manifests[i]
+			break
+		}
+	}
+	if selected == nil {
+		return nil, errors.New("no images found in index")
+	}
+	return index.Image(selected.Digest)
+}
+
+// getImageMetadata returns the metadata available in annotations and labels
+// in the container image provided. The supported platforms are obtained from
+// the image index when provided.
+func getImageMetadata(image v1.Image, index v1.ImageIndex) (map[string]string, error) {
 	manifest, err := image.Manifest()
 	if err != nil {
 		return nil, err
@@ -391,8 +544,7 @@ func getMetadata(
 	}
 
 	// Get supported platform from images index / manifest list when available
-	index, err := desc.ImageIndex()
-	if err == nil {
+	if index != nil {
 		indexManifest, err := index.IndexManifest()
 		if err == nil {
 			var platforms []string
@@ -408,6 +560,20 @@ func getMetadata(
 	return md, nil
 }
 
+// GetReadme returns the content of the readme file referenced in the container
+// image metadata provided, if any.
+func GetReadme(ctx context.Context, hc hub.HTTPClient, md map[string]string) (string, error) {
+	v, ok := md[readmeURLAnnotation]
+	if !ok {
+		return "", nil
+	}
+	data, err := getContent(ctx, hc, v)
+	if err != nil {
+		return "", fmt.Errorf("error getting readme file content: %w", err)
+	}
+	return string(data), nil
+}
+
 // getContent returns the content of the url provided.
 func getContent(
 	ctx context.Context,
@@ -2,8 +2,39 @@ package container
 
 import (
 	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTrackerSource(t *testing.T) {
 	// TODO(tegioz)
 }
+
+func TestValidateMetadata(t *testing.T) {
+	md := map[string]string{
+		createdAnnotation:              "2022-10-01T10:00:00Z",
+		descriptionAnnotation:          "Description",
+		readmeURLAnnotation:            "https://readme.url",
+		maintainersAnnotation:          `[{"email": "test@email.com"}]`,
+		alternativeLocationsAnnotation: "quay.io/org/Image1:",
+	}
+
+	t.Run("stricter checks fail", func(t *testing.T) {
+		t.Parallel()
+		err := ValidateMetadata(md)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid annotation: maintainer name not provided")
+		assert.Contains(t, err.Error(), "invalid annotation: invalid alternative location: quay.io/org/Image1:")
+	})
+
+	t.Run("package is still accepted by the tracker", func(t *testing.T) {
+		t.Parallel()
+		r := &hub.Repository{Kind: hub.Container, URL: "oci://quay.io/org/image1"}
+		p, err := PreparePackageFromMetadata(r, "1.0.0", md)
+		require.NoError(t, err)
+		assert.Len(t, p.Maintainers, 1)
+		assert.Equal(t, []string{"quay.io/org/Image1:"}, p.Data["alternativeLocations"])
+	})
+}
@@ -54,12 +54,20 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 		// Read and parse rules metadata file
 		data, err := os.ReadFile(pkgPath)
 		if err != nil {
-			s.warn(fmt.Errorf("error reading rules metadata file: %w", err))
+			s.warn(&hub.RepositoryError{
+				Path:  strings.TrimPrefix(pkgPath, s.i.BasePath),
+				Class: hub.ErrorClassMetadata,
+				Err:   fmt.Errorf("error reading rules metadata file: %w", err),
+			})
 			return nil
 		}
 		var md *RulesMetadata
 		if err = yaml.Unmarshal(data, &md); err != nil || md == nil {
-			s.warn(fmt.Errorf("error unmarshaling rules metadata file: %w", err))
+			s.warn(&hub.RepositoryError{
+				Path:  strings.TrimPrefix(pkgPath, s.i.BasePath),
+				Class: hub.ErrorClassMetadata,
+				Err:   fmt.Errorf("error unmarshaling rules metadata file: %w", err),
+			})
 			return nil
 		}
 
@@ -71,7 +79,13 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 		// Prepare and store package version
 		p, err := s.preparePackage(s.i.Repository, md, strings.TrimPrefix(pkgPath, s.i.BasePath))
 		if err != nil {
-			s.warn(fmt.Errorf("error preparing package: %w", err))
+			s.warn(&hub.RepositoryError{
+				PackageName: md.Name,
+				Version:     md.Version,
+				Path:        strings.TrimPrefix(pkgPath, s.i.BasePath),
+				Class:       hub.ErrorClassPackage,
+				Err:         fmt.Errorf("error preparing package: %w", err),
+			})
 			return nil
 		}
 		packagesAvailable[pkg.BuildKey(p)] = p
@@ -121,9 +135,9 @@ func (s *TrackerSource) preparePackage(r *hub.Repository, md *RulesMetadata, pkg
 	var sourceURL string
 	switch host {
 	case "github.com":
-		sourceURL = fmt.Sprintf("%s/blob/%s/%s%s", repoBaseURL, repo.GetBranch(r), pkgsPath, pkgPath)
+		sourceURL = fmt.Sprintf("%s/blob/%s/%s%s", repoBaseURL, repo.GetSourceRef(r, s.i.RepositoryDigest), pkgsPath, pkgPath)
 	case "gitlab.com":
-		sourceURL = fmt.Sprintf("%s/-/blob/%s/%s%s", repoBaseURL, repo.GetBranch(r), pkgsPath, pkgPath)
+		sourceURL = fmt.Sprintf("%s/-/blob/%s/%s%s", repoBaseURL, repo.GetSourceRef(r, s.i.RepositoryDigest), pkgsPath, pkgPath)
 	}
 	if sourceURL != "" {
 		p.Links = append(p.Links, &hub.Link{
@@ -139,7 +153,13 @@ func (s *TrackerSource) preparePackage(r *hub.Repository, md *RulesMetadata, pkg
 			p.LogoURL = md.Icon
 			p.LogoImageID = logoImageID
 		} else {
-			s.warn(fmt.Errorf("error getting package %s version %s logo image: %w", p.Name, p.Version, err))
+			s.warn(&hub.RepositoryError{
+				PackageName: p.Name,
+				Version:     p.Version,
+				Path:        pkgPath,
+				Class:       hub.ErrorClassLogo,
+				Err:         fmt.Errorf("error getting package %s version %s logo image: %w", p.Name, p.Version, err),
+			})
 		}
 	}
 
@@ -150,7 +170,7 @@ func (s *TrackerSource) preparePackage(r *hub.Repository, md *RulesMetadata, pkg
 // logs it as a warning.
 func (s *TrackerSource) warn(err error) {
 	s.i.Svc.Logger.Warn().Err(err).Send()
-	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err.Error())
+	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err)
 }
 
 // RulesMetadata represents some metadata for a Falco rules package.
@@ -0,0 +1,341 @@
+package federation
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/artifacthub/hub/internal/repo"
+)
+
+const (
+	// Number of remote packages processed concurrently
+	concurrency = 10
+
+	// Number of packages requested per remote search page
+	searchLimit = 60
+)
+
+var (
+	// errUnexpectedStatusCode indicates that the remote Artifact Hub instance
+	// returned an unexpected status code.
+	errUnexpectedStatusCode = errors.New("unexpected status code received")
+)
+
+// TrackerSource is a hub.TrackerSource implementation for repositories that
+// mirror the packages available in a remote Artifact Hub instance.
+type TrackerSource struct {
+	i *hub.TrackerSourceInput
+}
+
+// NewTrackerSource creates a new TrackerSource instance.
+func NewTrackerSource(i *hub.TrackerSourceInput) *TrackerSource {
+	return &TrackerSource{i: i}
+}
+
+// GetPackagesAvailable implements the TrackerSource interface.
+func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error) {
+	var mu sync.Mutex
+	packagesAvailable := make(map[string]*hub.Package)
+
+	// Get remote packages matching the federation settings
+	federation, err := repo.GetFederation(s.i.Repository)
+	if err != nil {
+		return nil, err
+	}
+	if federation == nil {
+		return nil, errors.New("federation settings not found")
+	}
+	if !repo.IsFederationAllowed(s.i.Svc.Cfg, s.i.Repository.URL) {
+		return nil, repo.ErrFederationNotAllowed
+	}
+	remotePkgs, err := s.searchPackages(federation)
+	if err != nil {
+		return nil, fmt.Errorf("error searching remote packages: %w", err)
+	}
+
+	// Iterate over remote packages and prepare their versions
+	limiter := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	remoteRepoByPkgName := make(map[string]string)
+	for _, rp := range remotePkgs {
+		// Return ASAP if context is cancelled
+		select {
+		case <-s.i.Svc.Ctx.Done():
+			wg.Wait()
+			return nil, s.i.Svc.Ctx.Err()
+		default:
+		}
+
+		// Packages names must be unique in the local repository, so only the
+		// first remote package found with a given name is mirrored
+		if remoteRepoName, ok := remoteRepoByPkgName[rp.Name]; ok {
+			s.warn(&hub.RepositoryError{
+				PackageName: rp.Name,
+				Class:       hub.ErrorClassPackage,
+				Err: fmt.Errorf(
+					"package %s from remote repository %s skipped: already mirrored from remote repository %s",
+					rp.Name, rp.Repository.Name, remoteRepoName,
+				),
+			})
+			continue
+		}
+		remoteRepoByPkgName[rp.Name] = rp.Repository.Name
+
+		// Prepare package versions
+		limiter <- struct{}{}
+		wg.Add(1)
+		go func(rp *hub.Package) {
+			defer func() {
+				<-limiter
+				wg.Done()
+			}()
+			defer func() {
+				if r := recover(); r != nil {
+					s.i.Svc.Logger.Error().Bytes("stacktrace", debug.Stack()).Interface("recover", r).Send()
+				}
+			}()
+			versions := s.preparePackageVersions(rp)
+			mu.Lock()
+			for key, p := range versions {
+				packagesAvailable[key] = p
+			}
+			mu.Unlock()
+		}(rp)
+	}
+	wg.Wait()
+
+	return packagesAvailable, nil
+}
+
+// searchPackages returns the remote packages matching the federation settings
+// provided, using the remote Artifact Hub instance search API.
+func (s *TrackerSource) searchPackages(federation *hub.Federation) ([]*hub.Package, error) {
+	qs := url.Values{}
+	qs.Set("kind", strconv.Itoa(int(s.i.Repository.Kind)))
+	qs.Set("deprecated", "true")
+	qs.Set("limit", strconv.Itoa(searchLimit))
+	for _, name := range federation.Repositories {
+		qs.Add("repo", name)
+	}
+	for _, name := range federation.Organizations {
+		qs.Add("org", name)
+	}
+
+	var remotePkgs []*hub.Package
+	for offset := 0; ; offset += searchLimit {
+		qs.Set("offset", strconv.Itoa(offset))
+		var result struct {
+			Packages []*hub.Package `json:"packages"`
+		}
+		if err := s.getJSON("/api/v1/packages/search?"+qs.Encode(), &result); err != nil {
+			return nil, err
+		}
+		remotePkgs = append(remotePkgs, result.Packages...)
+		if len(result.Packages) < searchLimit {
+			break
+		}
+	}
+	return remotePkgs, nil
+}
+
+// preparePackageVersions prepares the versions available of the remote
+// package provided. The latest version is always fetched from the remote
+// instance, so that updates to its security report summary can be mirrored.
+// Other versions are only fetched when they haven't been registered yet.
+//
+// When some information cannot be fetched from the remote instance, versions
+// already registered are kept so that they are not unregistered due to a
+// temporary error.
+func (s *TrackerSource) preparePackageVersions(rp *hub.Package) map[string]*hub.Package {
+	versions := make(map[string]*hub.Package)
+
+	// Get package's latest version, including the list of versions available
+	latest, err := s.getPackage(rp.Repository.Name, rp.Name, "")
+	if err != nil {
+		s.warn(&hub.RepositoryError{
+			PackageName: rp.Name,
+			Class:       hub.ErrorClassPackage,
+			Err:         fmt.Errorf("error getting remote package %s: %w", rp.Name, err),
+		})
+		for key := range s.i.PackagesRegistered {
+			if name, version := pkg.ParseKey(key); name == rp.Name {
+				versions[key] = s.notChanged(name, version)
+			}
+		}
+		return versions
+	}
+
+	// Prepare package versions
+	for _, v := range latest.AvailableVersions {
+		key := pkg.BuildKey(&hub.Package{Name: latest.Name, Version: v.Version})
+		_, registered := s.i.PackagesRegistered[key]
+		switch {
+		case v.Version == latest.Version:
+			versions[key] = s.preparePackage(latest)
+		case registered:
+			versions[key] = s.notChanged(latest.Name, v.Version)
+		default:
+			rpv, err := s.getPackage(rp.Repository.Name, rp.Name, v.Version)
+			if err != nil {
+				s.warn(&hub.RepositoryError{
+					PackageName: rp.Name,
+					Version:     v.Version,
+					Class:       hub.ErrorClassPackage,
+					Err:         fmt.Errorf("error getting remote package %s version %s: %w", rp.Name, v.Version, err),
+				})
+				continue
+			}
+			versions[key] = s.preparePackage(rpv)
+		}
+	}
+	return versions
+}
+
+// preparePackage prepares a local package version from the remote one
+// provided, attributing it to the remote instance it was mirrored from.
+func (s *TrackerSource) preparePackage(rp *hub.Package) *hub.Package {
+	p := *rp
+	p.PackageID = ""
+	p.LogoImageID = ""
+	p.AvailableVersions = nil
+	p.Stats = nil
+	p.ProductionOrganizations = nil
+	p.Repository = s.i.Repository
+
+	// Signatures and provenance reported by the remote instance are not
+	// mirrored, as they haven't been verified locally
+	p.Signed = false
+	p.Signatures = nil
+	p.SignatureVerification = nil
+	p.Provenance = nil
+
+	// Digest (the security report summary is included so that changes to it
+	// are mirrored as well)
+	summaryJSON, _ := json.Marshal(rp.SecurityReportSummary)
+	p.Digest = fmt.Sprintf("%x", sha256.Sum256([]byte(
+		fmt.Sprintf("%s:%s:%d", rp.Digest, summaryJSON, rp.SecurityReportCreatedAt),
+	)))
+
+	// Attribution to the upstream package
+	p.Data = make(map[string]interface{}, len(rp.Data)+1)
+	for k, v := range rp.Data {
+		p.Data[k] = v
+	}
+	upstream := map[string]interface{}{
+		"url":             s.buildURL(packagePath(rp.Repository, rp.Name)),
+		"package_id":      rp.PackageID,
+		"repository_name": rp.Repository.Name,
+	}
+	if rp.Repository.OrganizationName != "" {
+		upstream["organization_name"] = rp.Repository.OrganizationName
+	}
+	if rp.Repository.UserAlias != "" {
+		upstream["user_alias"] = rp.Repository.UserAlias
+	}
+	p.Data["upstream"] = upstream
+
+	// Logo (only downloaded when the package version will be registered)
+	if rp.LogoImageID != "" && s.i.PackagesRegistered[pkg.BuildKey(&p)] != p.Digest {
+		logoImageID, err := s.i.Svc.Is.DownloadAndSaveImage(s.i.Svc.Ctx, s.buildURL("/image/"+rp.LogoImageID))
+		if err != nil {
+			s.warn(&hub.RepositoryError{
+				PackageName: p.Name,
+				Version:     p.Version,
+				Class:       hub.ErrorClassLogo,
+				Err:         fmt.Errorf("error getting logo image %s: %w", rp.LogoImageID, err),
+			})
+		} else {
+			p.LogoImageID = logoImageID
+		}
+	}
+
+	return &p
+}
+
+// notChanged returns a package version that represents one already registered
+// that does not need to be registered again.
+func (s *TrackerSource) notChanged(name, version string) *hub.Package {
+	return &hub.Package{
+		Name:       name,
+		Version:    version,
+		Digest:     hub.HasNotChanged,
+		Repository: s.i.Repository,
+	}
+}
+
+// getPackage gets the package version provided from the remote Artifact Hub
+// instance. The latest version is returned when no version is provided.
+func (s *TrackerSource) getPackage(repoName, name, version string) (*hub.Package, error) {
+	p := path(s.i.Repository.Kind, repoName, name)
+	if version != "" {
+		p += "/" + url.PathEscape(version)
+	}
+	var rp *hub.Package
+	if err := s.getJSON("/api/v1/packages"+p, &rp); err != nil {
+		return nil, err
+	}
+	if rp == nil || rp.Repository == nil {
+		return nil, errors.New("invalid package data received")
+	}
+	return rp, nil
+}
+
+// getJSON gets the json document available at the path provided in the remote
+// Artifact Hub instance and unmarshals it into the value provided.
+func (s *TrackerSource) getJSON(p string, v interface{}) error {
+	req, err := http.NewRequest("GET", s.buildURL(p), nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(s.i.Svc.Ctx)
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.i.Svc.Hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %d", errUnexpectedStatusCode, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// buildURL builds an url for the path provided in the remote Artifact Hub
+// instance.
+func (s *TrackerSource) buildURL(p string) string {
+	return strings.TrimSuffix(s.i.Repository.URL, "/") + p
+}
+
+// warn is a helper that sends the error provided to the errors collector and
+// logs it as a warning.
+func (s *TrackerSource) warn(err error) {
+	s.i.Svc.Logger.Warn().Err(err).Send()
+	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err)
+}
+
+// packagePath returns the path of the remote package provided in the remote
+// Artifact Hub instance web application.
+func packagePath(r *hub.Repository, name string) string {
+	return "/packages" + path(r.Kind, r.Name, name)
+}
+
+// path returns the path that identifies a package in the remote Artifact Hub
+// instance.
+func path(kind hub.RepositoryKind, repoName, name string) string {
+	return fmt.Sprintf("/%s/%s/%s", hub.GetKindName(kind), url.PathEscape(repoName), url.PathEscape(name))
+}
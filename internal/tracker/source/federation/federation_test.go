@@ -0,0 +1,202 @@
+package federation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/repo"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/artifacthub/hub/internal/tracker/source"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	searchPath = "/api/v1/packages/search?deprecated=true&kind=0&limit=60&offset=0&org=org1&repo=repo1"
+	pkg1Path   = "/api/v1/packages/helm/repo1/pkg1"
+)
+
+func TestTrackerSource(t *testing.T) {
+	r := &hub.Repository{
+		RepositoryID: "repo-id",
+		Kind:         hub.Helm,
+		URL:          "https://hub.url/",
+		Data:         json.RawMessage(`{"federation": {"repositories": ["repo1"], "organizations": ["org1"]}}`),
+	}
+	remotePkg1V2 := `{
+		"package_id": "remote-pkg1-id",
+		"name": "pkg1",
+		"version": "2.0.0",
+		"digest": "digest-2.0.0",
+		"logo_image_id": "remote-logo-id",
+		"security_report_summary": {"high": 1},
+		"signature_verification": {"status": "verified", "kind": "cosign"},
+		"signed": true,
+		"signatures": ["cosign"],
+		"provenance": {"predicate_type": "https://slsa.dev/provenance/v1", "builder_id": "builder", "build_level": 3},
+		"available_versions": [{"version": "1.0.0"}, {"version": "2.0.0"}, {"version": "3.0.0-rc.1"}],
+		"data": {"key": "value"},
+		"stats": {"subscriptions": 1},
+		"repository": {"repository_id": "remote-repo1-id", "name": "repo1", "kind": 0, "organization_name": "org1"}
+	}`
+	remotePkg1V3 := `{
+		"package_id": "remote-pkg1-id",
+		"name": "pkg1",
+		"version": "3.0.0-rc.1",
+		"digest": "digest-3.0.0-rc.1",
+		"repository": {"repository_id": "remote-repo1-id", "name": "repo1", "kind": 0, "organization_name": "org1"}
+	}`
+
+	t.Run("remote instance not allowed", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup services and expectations
+		sw := source.NewTestsServicesWrapper()
+		i := &hub.TrackerSourceInput{
+			Repository: r,
+			Svc:        sw.Svc,
+		}
+
+		// Run test and check expectations
+		packages, err := NewTrackerSource(i).GetPackagesAvailable()
+		assert.True(t, errors.Is(err, repo.ErrFederationNotAllowed))
+		assert.Nil(t, packages)
+		sw.AssertExpectations(t)
+	})
+
+	t.Run("error searching remote packages", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup services and expectations
+		sw := source.NewTestsServicesWrapper()
+		sw.Svc.Cfg.Set("federation.allowedURLs", []string{"https://hub.url"})
+		i := &hub.TrackerSourceInput{
+			Repository: r,
+			Svc:        sw.Svc,
+		}
+		sw.Hc.On("Do", matchPath(searchPath)).Return(newResponse(http.StatusInternalServerError, ""), nil)
+
+		// Run test and check expectations
+		packages, err := NewTrackerSource(i).GetPackagesAvailable()
+		assert.True(t, errors.Is(err, errUnexpectedStatusCode))
+		assert.Nil(t, packages)
+		sw.AssertExpectations(t)
+	})
+
+	t.Run("error getting remote package, registered versions are kept", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup services and expectations
+		sw := source.NewTestsServicesWrapper()
+		sw.Svc.Cfg.Set("federation.allowedURLs", []string{"https://hub.url"})
+		i := &hub.TrackerSourceInput{
+			Repository: r,
+			PackagesRegistered: map[string]string{
+				"pkg1@1.0.0": "digest",
+				"pkg2@1.0.0": "digest",
+			},
+			Svc: sw.Svc,
+		}
+		sw.Hc.On("Do", matchPath(searchPath)).Return(newResponse(http.StatusOK, `{
+			"packages": [{"name": "pkg1", "repository": {"name": "repo1", "kind": 0}}]
+		}`), nil)
+		sw.Hc.On("Do", matchPath(pkg1Path)).Return(nil, tests.ErrFake)
+		sw.Ec.On("Append", i.Repository.RepositoryID, "error getting remote package pkg1: fake error for tests").Return()
+
+		// Run test and check expectations
+		packages, err := NewTrackerSource(i).GetPackagesAvailable()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]*hub.Package{
+			"pkg1@1.0.0": {
+				Name:       "pkg1",
+				Version:    "1.0.0",
+				Digest:     hub.HasNotChanged,
+				Repository: r,
+			},
+		}, packages)
+		sw.AssertExpectations(t)
+	})
+
+	t.Run("remote packages mirrored successfully", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup services and expectations
+		sw := source.NewTestsServicesWrapper()
+		sw.Svc.Cfg.Set("federation.allowedURLs", []string{"https://hub.url"})
+		i := &hub.TrackerSourceInput{
+			Repository: r,
+			PackagesRegistered: map[string]string{
+				"pkg1@1.0.0": "digest",
+			},
+			Svc: sw.Svc,
+		}
+		sw.Hc.On("Do", matchPath(searchPath)).Return(newResponse(http.StatusOK, `{
+			"packages": [
+				{"name": "pkg1", "repository": {"name": "repo1", "kind": 0}},
+				{"name": "pkg1", "repository": {"name": "repo2", "kind": 0}}
+			]
+		}`), nil)
+		sw.Hc.On("Do", matchPath(pkg1Path)).Return(newResponse(http.StatusOK, remotePkg1V2), nil)
+		sw.Hc.On("Do", matchPath(pkg1Path+"/3.0.0-rc.1")).Return(newResponse(http.StatusOK, remotePkg1V3), nil)
+		sw.Is.On("DownloadAndSaveImage", sw.Svc.Ctx, "https://hub.url/image/remote-logo-id").Return("local-logo-id", nil)
+		sw.Ec.On(
+			"Append",
+			i.Repository.RepositoryID,
+			"package pkg1 from remote repository repo2 skipped: already mirrored from remote repository repo1",
+		).Return()
+
+		// Run test and check expectations
+		packages, err := NewTrackerSource(i).GetPackagesAvailable()
+		assert.NoError(t, err)
+		assert.Len(t, packages, 3)
+		assert.Equal(t, &hub.Package{
+			Name:       "pkg1",
+			Version:    "1.0.0",
+			Digest:     hub.HasNotChanged,
+			Repository: r,
+		}, packages["pkg1@1.0.0"])
+		p := packages["pkg1@2.0.0"]
+		assert.Empty(t, p.PackageID)
+		assert.Equal(t, "local-logo-id", p.LogoImageID)
+		assert.Equal(t, r, p.Repository)
+		assert.Nil(t, p.AvailableVersions)
+		assert.Nil(t, p.Stats)
+		assert.False(t, p.Signed)
+		assert.Nil(t, p.Signatures)
+		assert.Nil(t, p.SignatureVerification)
+		assert.Nil(t, p.Provenance)
+		assert.Equal(t, &hub.SecurityReportSummary{High: 1}, p.SecurityReportSummary)
+		assert.NotEmpty(t, p.Digest)
+		assert.NotEqual(t, "digest-2.0.0", p.Digest)
+		assert.Equal(t, map[string]interface{}{
+			"key": "value",
+			"upstream": map[string]interface{}{
+				"url":               "https://hub.url/packages/helm/repo1/pkg1",
+				"package_id":        "remote-pkg1-id",
+				"repository_name":   "repo1",
+				"organization_name": "org1",
+			},
+		}, p.Data)
+		assert.Equal(t, "3.0.0-rc.1", packages["pkg1@3.0.0-rc.1"].Version)
+		assert.Empty(t, packages["pkg1@3.0.0-rc.1"].LogoImageID)
+		sw.AssertExpectations(t)
+	})
+}
+
+func matchPath(p string) interface{} {
+	return mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == "https://hub.url"+p
+	})
+}
+
+func newResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
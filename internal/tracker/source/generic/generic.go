@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
@@ -12,8 +13,11 @@ import (
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/oci"
 	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/hashicorp/go-multierror"
+	gkapis "github.com/open-policy-agent/gatekeeper/apis"
 	gk "github.com/open-policy-agent/gatekeeper/pkg/gator"
 	ignore "github.com/sabhiram/go-gitignore"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 const (
@@ -33,6 +37,14 @@ const (
 	// contains the raw policies.
 	OPAPoliciesKey = "policies"
 
+	// gatekeeperConstraintsGroup represents the api group of the Gatekeeper
+	// constraints.
+	gatekeeperConstraintsGroup = "constraints.gatekeeper.sh"
+
+	// gatekeeperTemplateFile represents the name of the file that contains
+	// the Gatekeeper constraint template.
+	gatekeeperTemplateFile = "template.yaml"
+
 	// falcoRulesSuffix is the suffix that each of the rules files in the
 	// package must use.
 	falcoRulesSuffix = "-rules.yaml"
@@ -45,16 +57,29 @@ const (
 // TrackerSource is a hub.TrackerSource implementation used by several kinds
 // of repositories.
 type TrackerSource struct {
-	i *hub.TrackerSourceInput
+	i  *hub.TrackerSourceInput
+	tg hub.OCITagsGetter
 }
 
 // NewTrackerSource creates a new TrackerSource instance.
 func NewTrackerSource(i *hub.TrackerSourceInput, opts ...func(s *TrackerSource)) *TrackerSource {
-	return &TrackerSource{i: i}
+	s := &TrackerSource{i: i}
+	for _, o := range opts {
+		o(s)
+	}
+	if s.tg == nil {
+		s.tg = &oci.TagsGetter{}
+	}
+	return s
 }
 
 // GetPackagesAvailable implements the TrackerSource interface.
 func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error) {
+	// Packages stored in an OCI registry are processed differently
+	if strings.HasPrefix(s.i.Repository.URL, hub.RepositoryOCIPrefix) {
+		return s.getOCIPackagesAvailable()
+	}
+
 	packagesAvailable := make(map[string]*hub.Package)
 
 	// Walk the path provided looking for available packages
@@ -79,7 +104,11 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 		)
 		if err != nil {
 			if !errors.Is(err, os.ErrNotExist) {
-				s.warn(fmt.Errorf("error getting package metadata (path: %s): %w", pkgPath, err))
+				s.warn(&hub.RepositoryError{
+					Path:  strings.TrimPrefix(pkgPath, s.i.BasePath),
+					Class: hub.ErrorClassMetadata,
+					Err:   fmt.Errorf("error getting package metadata (path: %s): %w", pkgPath, err),
+				})
 			}
 			return nil
 		}
@@ -87,7 +116,13 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 		// Prepare and store package version
 		p, err := PreparePackage(s.i.Repository, md, pkgPath)
 		if err != nil {
-			s.warn(err)
+			s.warn(&hub.RepositoryError{
+				PackageName: md.Name,
+				Version:     md.Version,
+				Path:        strings.TrimPrefix(pkgPath, s.i.BasePath),
+				Class:       hub.ErrorClassPackage,
+				Err:         err,
+			})
 			return nil
 		}
 		p.RelativePath = strings.TrimPrefix(pkgPath, s.i.BasePath)
@@ -96,7 +131,13 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 		// Prepare and store logo image when available
 		logoImageID, err := s.prepareLogoImage(md, pkgPath)
 		if err != nil {
-			s.warn(fmt.Errorf("error preparing package %s version %s logo image: %w", md.Name, md.Version, err))
+			s.warn(&hub.RepositoryError{
+				PackageName: md.Name,
+				Version:     md.Version,
+				Path:        p.RelativePath,
+				Class:       hub.ErrorClassLogo,
+				Err:         fmt.Errorf("error preparing package %s version %s logo image: %w", md.Name, md.Version, err),
+			})
 		} else {
 			p.LogoImageID = logoImageID
 		}
@@ -104,22 +145,37 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 		// Check if the package is signed (for applicable kinds)
 		switch p.Repository.Kind {
 		case hub.Kubewarden:
-			// We'll consider the package signed if all images are signed
+			// We'll consider the package signed if all images are signed. The
+			// package signature verification will be the one of the images
+			// with the worst result (invalid > unverified > verified)
 			signedImages := 0
+			var verification *hub.SignatureVerification
 			for _, entry := range p.ContainersImages {
-				hasCosignSignature, err := s.i.Svc.Sc.HasCosignSignature(s.i.Svc.Ctx, entry.Image, "", "")
+				v, err := s.i.Svc.Sc.VerifyCosignSignature(s.i.Svc.Ctx, entry.Image, "", "", nil)
 				if err != nil {
-					s.warn(fmt.Errorf(
-						"error checking package %s version %s image %s signature: %w",
-						md.Name, md.Version, entry.Image, err,
-					))
-				} else if hasCosignSignature {
+					s.warn(&hub.RepositoryError{
+						PackageName: md.Name,
+						Version:     md.Version,
+						Path:        p.RelativePath,
+						Class:       hub.ErrorClassSignature,
+						Err: fmt.Errorf(
+							"error checking package %s version %s image %s signature: %w",
+							md.Name, md.Version, entry.Image, err,
+						),
+					})
+				} else if v != nil {
 					signedImages++
+					if verification == nil ||
+						verification.Status == hub.SignatureVerified ||
+						v.Status == hub.SignatureInvalid {
+						verification = v
+					}
 				}
 			}
 			if len(p.ContainersImages) > 0 && signedImages == len(p.ContainersImages) {
 				p.Signed = true
 				p.Signatures = []string{oci.Cosign}
+				p.SignatureVerification = verification
 			}
 		}
 
@@ -161,7 +217,7 @@ func (s *TrackerSource) prepareLogoImage(md *hub.PackageMetadata, pkgPath string
 // logs it as a warning.
 func (s *TrackerSource) warn(err error) {
 	s.i.Svc.Logger.Warn().Err(err).Send()
-	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err.Error())
+	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err)
 }
 
 // PreparePackage prepares a package version using the metadata and the files
@@ -232,7 +288,7 @@ func prepareFalcoData(pkgPath string, ignorer ignore.IgnoreParser) (map[string]i
 // in the path provided, returning the resulting data structure.
 func prepareGatekeeperData(pkgPath string) (map[string]interface{}, error) {
 	// Read template file
-	templatePath := path.Join(pkgPath, "template.yaml")
+	templatePath := path.Join(pkgPath, gatekeeperTemplateFile)
 	template, err := os.ReadFile(templatePath)
 	if err != nil {
 		return nil, fmt.Errorf("error reading gatekeeper template file: %w", err)
@@ -283,6 +339,89 @@ func prepareGatekeeperData(pkgPath string) (map[string]interface{}, error) {
 	}, nil
 }
 
+// ValidateGatekeeperPackage checks that the Gatekeeper template and the
+// examples constraints and objects available in the package located in the
+// path provided are valid. These checks are stricter than the ones performed
+// when the package is processed by the tracker, so they are only used by the
+// linter.
+func ValidateGatekeeperPackage(pkgPath string) error {
+	pkgFS := os.DirFS(pkgPath)
+	templateKind, err := validateGatekeeperTemplate(pkgFS)
+	if err != nil {
+		return err
+	}
+	suites, err := gk.ReadSuites(pkgFS, ".", false)
+	if err != nil {
+		return fmt.Errorf("error reading gatekeeper suite: %w", err)
+	}
+	for _, suite := range suites {
+		for _, t := range suite.Tests {
+			if t.Constraint != "" {
+				if err := validateGatekeeperConstraint(pkgFS, t.Constraint, templateKind); err != nil {
+					return err
+				}
+			}
+			for _, c := range t.Cases {
+				if _, err := gk.ReadObject(pkgFS, c.Object); err != nil {
+					return pkg.NewValidationError(pkg.ErrInvalidValue, "invalid example file (%s): %w", c.Object, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateGatekeeperTemplate checks that the Gatekeeper template available in
+// the filesystem provided is a valid constraint template, returning the kind
+// of the constraints it defines.
+func validateGatekeeperTemplate(pkgFS fs.FS) (string, error) {
+	scheme := runtime.NewScheme()
+	if err := gkapis.AddToScheme(scheme); err != nil {
+		return "", err
+	}
+	template, err := gk.ReadTemplate(scheme, pkgFS, gatekeeperTemplateFile)
+	if err != nil {
+		return "", pkg.NewValidationError(pkg.ErrInvalidValue, "invalid gatekeeper template: %w", err)
+	}
+
+	var errs *multierror.Error
+	kind := template.Spec.CRD.Spec.Names.Kind
+	if kind == "" {
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "crd kind not provided"))
+	} else if template.Name != strings.ToLower(kind) {
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrInvalidValue, "invalid name: it must be the lowercase version of the crd kind"))
+	}
+	if len(template.Spec.Targets) == 0 {
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "targets not provided"))
+	}
+	for _, target := range template.Spec.Targets {
+		if target.Rego == "" {
+			errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "target %s rego not provided", target.Target))
+		}
+	}
+	if errs.ErrorOrNil() != nil {
+		return "", fmt.Errorf("invalid gatekeeper template: %w", errs)
+	}
+	return kind, nil
+}
+
+// validateGatekeeperConstraint checks that the constraint in the path provided
+// is a valid Gatekeeper constraint of the kind defined by the template.
+func validateGatekeeperConstraint(pkgFS fs.FS, constraintPath, templateKind string) error {
+	u, err := gk.ReadObject(pkgFS, constraintPath)
+	if err != nil {
+		return pkg.NewValidationError(pkg.ErrInvalidValue, "invalid constraint file (%s): %w", constraintPath, err)
+	}
+	gvk := u.GroupVersionKind()
+	if gvk.Group != gatekeeperConstraintsGroup {
+		return pkg.NewValidationError(pkg.ErrInvalidValue, "invalid constraint file (%s): invalid api group: %s", constraintPath, gvk.Group)
+	}
+	if gvk.Kind != templateKind {
+		return pkg.NewValidationError(pkg.ErrInvalidValue, "invalid constraint file (%s): kind %s does not match template kind %s", constraintPath, gvk.Kind, templateKind)
+	}
+	return nil
+}
+
 // prepareOPAData reads and formats OPA specific data available in the path
 // provided, returning the resulting data structure.
 func prepareOPAData(pkgPath string, ignorer ignore.IgnoreParser) (map[string]interface{}, error) {
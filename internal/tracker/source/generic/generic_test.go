@@ -1,14 +1,17 @@
 package generic
 
 import (
+	"errors"
 	"os"
 	"testing"
 
 	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/oci"
 	"github.com/artifacthub/hub/internal/pkg"
 	"github.com/artifacthub/hub/internal/tests"
 	"github.com/artifacthub/hub/internal/tracker/source"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTrackerSource(t *testing.T) {
@@ -337,4 +340,78 @@ func TestTrackerSource(t *testing.T) {
 		assert.NoError(t, err)
 		sw.AssertExpectations(t)
 	})
+
+	t.Run("kubewarden package returned, signature verification of the images merged", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup services and expectations
+		sw := source.NewTestsServicesWrapper()
+		i := &hub.TrackerSourceInput{
+			Repository: &hub.Repository{
+				Kind: hub.Kubewarden,
+			},
+			BasePath: "testdata/path11",
+			Svc:      sw.Svc,
+		}
+		unverified := &hub.SignatureVerification{Status: hub.SignatureUnverified, Kind: oci.Cosign}
+		sw.Sc.On("VerifyCosignSignature", sw.Svc.Ctx, "registry.io/org/policy1:v1.0.0", "", "", []byte(nil)).
+			Return(&hub.SignatureVerification{Status: hub.SignatureVerified, Kind: oci.Cosign}, nil)
+		sw.Sc.On("VerifyCosignSignature", sw.Svc.Ctx, "registry2.io/org/policy1:v1.0.0", "", "", []byte(nil)).
+			Return(unverified, nil)
+
+		// Run test and check expectations
+		packages, err := NewTrackerSource(i).GetPackagesAvailable()
+		require.NoError(t, err)
+		p := packages["policy1@1.0.0"]
+		require.NotNil(t, p)
+		assert.True(t, p.Signed)
+		assert.Equal(t, []string{oci.Cosign}, p.Signatures)
+		assert.Equal(t, unverified, p.SignatureVerification)
+		sw.AssertExpectations(t)
+	})
+
+	t.Run("kubewarden package returned, not all images signed", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup services and expectations
+		sw := source.NewTestsServicesWrapper()
+		i := &hub.TrackerSourceInput{
+			Repository: &hub.Repository{
+				Kind: hub.Kubewarden,
+			},
+			BasePath: "testdata/path11",
+			Svc:      sw.Svc,
+		}
+		sw.Sc.On("VerifyCosignSignature", sw.Svc.Ctx, "registry.io/org/policy1:v1.0.0", "", "", []byte(nil)).
+			Return(&hub.SignatureVerification{Status: hub.SignatureVerified, Kind: oci.Cosign}, nil)
+		sw.Sc.On("VerifyCosignSignature", sw.Svc.Ctx, "registry2.io/org/policy1:v1.0.0", "", "", []byte(nil)).
+			Return(nil, nil)
+
+		// Run test and check expectations
+		packages, err := NewTrackerSource(i).GetPackagesAvailable()
+		require.NoError(t, err)
+		p := packages["policy1@1.0.0"]
+		require.NotNil(t, p)
+		assert.False(t, p.Signed)
+		assert.Nil(t, p.SignatureVerification)
+		sw.AssertExpectations(t)
+	})
+}
+
+func TestValidateGatekeeperPackage(t *testing.T) {
+	t.Run("constraint kind does not match template kind", func(t *testing.T) {
+		t.Parallel()
+		err := ValidateGatekeeperPackage("testdata/path10")
+		assert.True(t, errors.Is(err, pkg.ErrInvalidValue))
+		assert.Equal(t, "invalid constraint file (samples/constraint.yaml): kind K8sRequiredAnnotations does not match template kind K8sRequiredLabels", err.Error())
+	})
+
+	t.Run("package is still accepted by the tracker", func(t *testing.T) {
+		t.Parallel()
+		md, err := pkg.GetPackageMetadata(hub.Gatekeeper, "testdata/path10/artifacthub-pkg")
+		require.NoError(t, err)
+		p, err := PreparePackage(&hub.Repository{Kind: hub.Gatekeeper}, md, "testdata/path10")
+		require.NoError(t, err)
+		assert.NotNil(t, p.Data[GatekeeperTemplateKey])
+	})
 }
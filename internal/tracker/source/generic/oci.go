@@ -0,0 +1,392 @@
+package generic
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/oci"
+	"github.com/artifacthub/hub/internal/pkg"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// PackageMetadataLayerMediaType represents the media type used for the
+	// layer that contains the package metadata (artifacthub-pkg.yml) in an
+	// OCI artifact.
+	PackageMetadataLayerMediaType = "application/vnd.cncf.artifacthub.package-metadata.layer.v1.yaml"
+
+	// PackageContentLayerMediaType represents the media type used for the
+	// layer that contains the package files in an OCI artifact.
+	PackageContentLayerMediaType = "application/vnd.cncf.artifacthub.package.layer.v1.tar+gzip"
+
+	// FalcoRulesLayerMediaType represents the media type used by Falco for
+	// the layer that contains the rules files in an OCI artifact.
+	FalcoRulesLayerMediaType = "application/vnd.cncf.falco.rulesfile.layer.v0.1+tar.gz"
+
+	// Manifest annotations used to prepare the package metadata of artifacts
+	// that do not include an Artifact Hub metadata layer.
+	ahAnnotationDisplayName = "io.artifacthub.displayName"
+	ahAnnotationKeywords    = "io.artifacthub.keywords"
+	ahAnnotationResources   = "io.artifacthub.resources"
+	kwAnnotationDescription = "io.kubewarden.policy.description"
+	kwAnnotationLicense     = "io.kubewarden.policy.license"
+	kwAnnotationSource      = "io.kubewarden.policy.source"
+	kwAnnotationTitle       = "io.kubewarden.policy.title"
+	kwAnnotationURL         = "io.kubewarden.policy.url"
+	kwAnnotationUsage       = "io.kubewarden.policy.usage"
+
+	// maxContentSize represents the maximum size allowed for the extracted
+	// content of a package distributed as an OCI artifact.
+	maxContentSize = 50 * 1024 * 1024
+)
+
+// getOCIPackagesAvailable returns the packages available in a repository
+// stored in an OCI registry. Each of the semver tags available in the
+// repository is considered a package version.
+func (s *TrackerSource) getOCIPackagesAvailable() (map[string]*hub.Package, error) {
+	packagesAvailable := make(map[string]*hub.Package)
+
+	// Get versions (tags) available in the repository
+	versions, err := s.tg.Tags(s.i.Svc.Ctx, s.i.Repository, true)
+	if err != nil {
+		return nil, fmt.Errorf("error getting repository available versions: %w", err)
+	}
+
+	// Prepare a package for each of the versions available
+	bypassDigestCheck := s.i.Svc.Cfg.GetBool("tracker.bypassDigestCheck")
+	registeredVersions := make(map[string]string)
+	for key := range s.i.PackagesRegistered {
+		name, version := pkg.ParseKey(key)
+		registeredVersions[version] = name
+	}
+	for _, version := range versions {
+		// Return ASAP if context is cancelled
+		select {
+		case <-s.i.Svc.Ctx.Done():
+			return nil, s.i.Svc.Ctx.Err()
+		default:
+		}
+
+		// Tags are considered immutable, so versions already registered
+		// don't need to be processed again
+		if name, ok := registeredVersions[version]; ok && !bypassDigestCheck {
+			p := &hub.Package{
+				Name:       name,
+				Version:    version,
+				Digest:     hub.HasNotChanged,
+				Repository: s.i.Repository,
+			}
+			packagesAvailable[pkg.BuildKey(p)] = p
+			continue
+		}
+
+		// Prepare and store package version
+		p, err := s.prepareOCIPackage(version)
+		if err != nil {
+			s.warn(&hub.RepositoryError{
+				Version: version,
+				Class:   hub.ErrorClassPackage,
+				Err:     fmt.Errorf("error preparing package (version: %s): %w", version, err),
+			})
+			continue
+		}
+		packagesAvailable[pkg.BuildKey(p)] = p
+	}
+
+	return packagesAvailable, nil
+}
+
+// prepareOCIPackage prepares a package version from the content of the OCI
+// artifact identified by the version (tag) provided.
+func (s *TrackerSource) prepareOCIPackage(version string) (*hub.Package, error) {
+	// Pull artifact content into a temporary directory
+	ref := fmt.Sprintf("%s:%s", strings.TrimPrefix(s.i.Repository.URL, hub.RepositoryOCIPrefix), version)
+	tmpDir, err := os.MkdirTemp("", "artifact-hub")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	digest, err := s.pullOCIArtifact(ref, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get package version metadata. When the artifact does not provide an
+	// Artifact Hub metadata file, the metadata is prepared from the manifest
+	// annotations (i.e. Kubewarden policies pushed with kwctl).
+	md, err := pkg.GetPackageMetadata(
+		s.i.Repository.Kind,
+		filepath.Join(tmpDir, hub.PackageMetadataFile),
+	)
+	if errors.Is(err, os.ErrNotExist) {
+		md, digest, err = s.prepareOCIMetadataFromAnnotations(ref, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting package metadata: %w", err)
+	}
+	sv1, _ := semver.NewVersion(md.Version)
+	sv2, _ := semver.NewVersion(version)
+	if sv1 == nil || sv2 == nil || !sv1.Equal(sv2) {
+		return nil, fmt.Errorf("metadata version (%s) does not match tag", md.Version)
+	}
+
+	// Prepare package from the artifact content
+	p, err := PreparePackage(s.i.Repository, md, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	if p.Digest == "" {
+		p.Digest = digest
+	}
+
+	// Prepare and store logo image when available
+	logoImageID, err := s.prepareLogoImage(md, tmpDir)
+	if err != nil {
+		s.warn(&hub.RepositoryError{
+			PackageName: md.Name,
+			Version:     md.Version,
+			Class:       hub.ErrorClassLogo,
+			Err:         fmt.Errorf("error preparing package %s version %s logo image: %w", md.Name, md.Version, err),
+		})
+	} else {
+		p.LogoImageID = logoImageID
+	}
+
+	// Check if the artifact is signed and verify its signature (keyless only,
+	// as no sign key can be provided for these artifacts)
+	verification, err := s.i.Svc.Sc.VerifyCosignSignature(
+		s.i.Svc.Ctx,
+		ref,
+		s.i.Repository.AuthUser,
+		s.i.Repository.AuthPass,
+		nil,
+	)
+	if err != nil {
+		s.warn(&hub.RepositoryError{
+			PackageName: md.Name,
+			Version:     md.Version,
+			Class:       hub.ErrorClassSignature,
+			Err:         fmt.Errorf("error checking package %s version %s signature: %w", md.Name, md.Version, err),
+		})
+	} else if verification != nil {
+		p.Signed = true
+		p.Signatures = []string{oci.Cosign}
+		p.SignatureVerification = verification
+	}
+
+	// Check if the artifact has a SLSA provenance attestation attached
+	provenance, err := s.i.Svc.Sc.GetProvenance(
+		s.i.Svc.Ctx,
+		ref,
+		s.i.Repository.AuthUser,
+		s.i.Repository.AuthPass,
+	)
+	if err != nil {
+		s.warn(&hub.RepositoryError{
+			PackageName: md.Name,
+			Version:     md.Version,
+			Class:       hub.ErrorClassProvenance,
+			Err:         fmt.Errorf("error getting package %s version %s provenance: %w", md.Name, md.Version, err),
+		})
+	} else {
+		p.Provenance = provenance
+	}
+
+	return p, nil
+}
+
+// pullOCIArtifact pulls the metadata and content layers of the OCI artifact
+// identified by the reference provided, storing them in the destination path.
+// The digest of the metadata layer is returned, or the digest of the manifest
+// when the metadata is only available in the content layer.
+func (s *TrackerSource) pullOCIArtifact(ref, dst string) (string, error) {
+	username := s.i.Repository.AuthUser
+	password := s.i.Repository.AuthPass
+
+	// Content layer (optional for kinds that only need the metadata)
+	var contentPulled bool
+	for _, mediaType := range contentLayerMediaTypes(s.i.Repository.Kind) {
+		_, data, err := s.i.Svc.Op.PullLayer(s.i.Svc.Ctx, ref, mediaType, username, password)
+		if err != nil {
+			if errors.Is(err, oci.ErrLayerNotFound) {
+				continue
+			}
+			return "", fmt.Errorf("error pulling content layer: %w", err)
+		}
+		if err := extractTarGz(data, dst); err != nil {
+			return "", fmt.Errorf("error extracting content layer: %w", err)
+		}
+		contentPulled = true
+		break
+	}
+
+	// Metadata layer (takes precedence over the metadata file included in
+	// the content layer, if any)
+	desc, data, err := s.i.Svc.Op.PullLayer(s.i.Svc.Ctx, ref, PackageMetadataLayerMediaType, username, password)
+	if err != nil {
+		if errors.Is(err, oci.ErrLayerNotFound) {
+			if !contentPulled {
+				return "", nil
+			}
+			_, digest, err := s.i.Svc.Op.PullManifest(s.i.Svc.Ctx, ref, username, password)
+			if err != nil {
+				return "", fmt.Errorf("error pulling manifest: %w", err)
+			}
+			return digest, nil
+		}
+		return "", fmt.Errorf("error pulling metadata layer: %w", err)
+	}
+	mdFile := filepath.Join(dst, hub.PackageMetadataFile+".yml")
+	_ = os.Remove(filepath.Join(dst, hub.PackageMetadataFile+".yaml"))
+	if err := os.WriteFile(mdFile, data, 0600); err != nil {
+		return "", fmt.Errorf("error writing metadata file: %w", err)
+	}
+
+	return desc.Digest.String(), nil
+}
+
+// prepareOCIMetadataFromAnnotations prepares the package metadata from the
+// annotations of the manifest of the OCI artifact identified by the reference
+// provided. Standard OCI annotations are used for all kinds, and Kubewarden
+// policies annotations take precedence over them when available. The digest
+// of the manifest is returned along with the metadata.
+func (s *TrackerSource) prepareOCIMetadataFromAnnotations(ref, version string) (*hub.PackageMetadata, string, error) {
+	manifest, digest, err := s.i.Svc.Op.PullManifest(
+		s.i.Svc.Ctx,
+		ref,
+		s.i.Repository.AuthUser,
+		s.i.Repository.AuthPass,
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("error pulling manifest: %w", err)
+	}
+	if len(manifest.Annotations) == 0 {
+		return nil, "", errors.New("metadata layer and annotations not found")
+	}
+	a := manifest.Annotations
+
+	// Prepare metadata from the annotations available
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if v := strings.TrimSpace(a[key]); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	repoPath := strings.TrimPrefix(s.i.Repository.URL, hub.RepositoryOCIPrefix)
+	md := &hub.PackageMetadata{
+		Version:     version,
+		Name:        first(kwAnnotationTitle, ocispec.AnnotationTitle),
+		DisplayName: first(ahAnnotationDisplayName),
+		CreatedAt:   first(ocispec.AnnotationCreated),
+		Description: first(kwAnnotationDescription, ocispec.AnnotationDescription),
+		License:     first(kwAnnotationLicense, ocispec.AnnotationLicenses),
+		HomeURL:     first(kwAnnotationURL, ocispec.AnnotationURL),
+		Readme:      first(kwAnnotationUsage),
+	}
+	if md.Name == "" {
+		md.Name = repoPath[strings.LastIndex(repoPath, "/")+1:]
+	}
+	if md.DisplayName == "" {
+		md.DisplayName = md.Name
+	}
+	if md.CreatedAt == "" {
+		md.CreatedAt = time.Now().Format(time.RFC3339)
+	}
+	if keywords := first(ahAnnotationKeywords); keywords != "" {
+		for _, keyword := range strings.Split(keywords, ",") {
+			if keyword = strings.TrimSpace(keyword); keyword != "" {
+				md.Keywords = append(md.Keywords, keyword)
+			}
+		}
+	}
+	if source := first(kwAnnotationSource, ocispec.AnnotationSource); source != "" {
+		md.Links = []*hub.Link{{Name: "source", URL: source}}
+	}
+	if s.i.Repository.Kind == hub.Kubewarden {
+		md.ContainersImages = []*hub.ContainerImage{{Name: "policy", Image: repoPath + ":" + version}}
+		if resources := first(ahAnnotationResources); resources != "" {
+			md.Annotations = map[string]string{"kubewarden/resources": resources}
+		}
+	}
+	if err := pkg.ValidatePackageMetadata(s.i.Repository.Kind, md); err != nil {
+		return nil, "", fmt.Errorf("error validating package metadata from annotations: %w", err)
+	}
+
+	return md, digest, nil
+}
+
+// contentLayerMediaTypes returns the media types of the layers that may
+// contain the package files for the repository kind provided.
+func contentLayerMediaTypes(kind hub.RepositoryKind) []string {
+	switch kind {
+	case hub.Falco:
+		return []string{PackageContentLayerMediaType, FalcoRulesLayerMediaType}
+	case hub.Kubewarden:
+		// Kubewarden policies artifacts contain the wasm module, all the
+		// information we need is provided in the metadata layer.
+		return nil
+	default:
+		return []string{PackageContentLayerMediaType}
+	}
+}
+
+// extractTarGz extracts the gzipped tarball provided into the destination
+// path. Only regular files and directories are extracted.
+func extractTarGz(data []byte, dst string) error {
+	gzr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer gzr.Close()
+	tr := tar.NewReader(gzr)
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, filepath.Clean("/"+hdr.Name)) // #nosec
+		if !strings.HasPrefix(target, filepath.Clean(dst)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid file path in archive: %s", hdr.Name)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			total += hdr.Size
+			if total > maxContentSize {
+				return errors.New("content too large")
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+			if err != nil {
+				return err
+			}
+			if _, err := io.CopyN(f, tr, hdr.Size); err != nil {
+				f.Close()
+				return err
+			}
+			f.Close()
+		}
+	}
+	return nil
+}
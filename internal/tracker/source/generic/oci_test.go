@@ -0,0 +1,333 @@
+package generic
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"path/filepath"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/oci"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/artifacthub/hub/internal/tracker/source"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackerSourceOCI(t *testing.T) {
+	ref := "registry.io/org/policies:1.0.0"
+	md := []byte(`
+version: 1.0.0
+name: pkg1
+displayName: Package 1
+createdAt: 2019-06-28T15:23:00Z
+description: Description
+digest: 0123456789
+`)
+
+	t.Run("error getting repository available versions", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup services and expectations
+		sw := source.NewTestsServicesWrapper()
+		i := &hub.TrackerSourceInput{
+			Repository: &hub.Repository{
+				Kind: hub.OPA,
+				URL:  "oci://registry.io/org/policies",
+			},
+			Svc: sw.Svc,
+		}
+		tg := &oci.TagsGetterMock{}
+		tg.On("Tags", i.Svc.Ctx, i.Repository, true).Return(nil, tests.ErrFake)
+
+		// Run test and check expectations
+		packages, err := NewTrackerSource(i, withOCITagsGetter(tg)).GetPackagesAvailable()
+		assert.Nil(t, packages)
+		assert.ErrorIs(t, err, tests.ErrFake)
+		sw.AssertExpectations(t)
+		tg.AssertExpectations(t)
+	})
+
+	t.Run("versions already registered are not processed again", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup services and expectations
+		sw := source.NewTestsServicesWrapper()
+		i := &hub.TrackerSourceInput{
+			Repository: &hub.Repository{
+				Kind: hub.OPA,
+				URL:  "oci://registry.io/org/policies",
+			},
+			PackagesRegistered: map[string]string{
+				"pkg1@1.0.0": "0123456789",
+			},
+			Svc: sw.Svc,
+		}
+		tg := &oci.TagsGetterMock{}
+		tg.On("Tags", i.Svc.Ctx, i.Repository, true).Return([]string{"1.0.0"}, nil)
+
+		// Run test and check expectations
+		p := &hub.Package{
+			Name:       "pkg1",
+			Version:    "1.0.0",
+			Digest:     hub.HasNotChanged,
+			Repository: i.Repository,
+		}
+		packages, err := NewTrackerSource(i, withOCITagsGetter(tg)).GetPackagesAvailable()
+		assert.Equal(t, map[string]*hub.Package{
+			pkg.BuildKey(p): p,
+		}, packages)
+		assert.NoError(t, err)
+		sw.AssertExpectations(t)
+		tg.AssertExpectations(t)
+	})
+
+	t.Run("error pulling content layer", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup services and expectations
+		sw := source.NewTestsServicesWrapper()
+		i := &hub.TrackerSourceInput{
+			Repository: &hub.Repository{
+				Kind: hub.OPA,
+				URL:  "oci://registry.io/org/policies",
+			},
+			Svc: sw.Svc,
+		}
+		tg := &oci.TagsGetterMock{}
+		tg.On("Tags", i.Svc.Ctx, i.Repository, true).Return([]string{"1.0.0"}, nil)
+		sw.Op.On("PullLayer", i.Svc.Ctx, ref, PackageContentLayerMediaType, "", "").
+			Return(ocispec.Descriptor{}, nil, tests.ErrFake)
+		expectedErr := "error preparing package (version: 1.0.0): error pulling content layer: fake error for tests"
+		sw.Ec.On("Append", i.Repository.RepositoryID, expectedErr).Return()
+
+		// Run test and check expectations
+		packages, err := NewTrackerSource(i, withOCITagsGetter(tg)).GetPackagesAvailable()
+		assert.Equal(t, map[string]*hub.Package{}, packages)
+		assert.NoError(t, err)
+		sw.AssertExpectations(t)
+		tg.AssertExpectations(t)
+	})
+
+	t.Run("metadata version does not match tag", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup services and expectations
+		sw := source.NewTestsServicesWrapper()
+		i := &hub.TrackerSourceInput{
+			Repository: &hub.Repository{
+				Kind: hub.OPA,
+				URL:  "oci://registry.io/org/policies",
+			},
+			Svc: sw.Svc,
+		}
+		tg := &oci.TagsGetterMock{}
+		tg.On("Tags", i.Svc.Ctx, i.Repository, true).Return([]string{"2.0.0"}, nil)
+		ref := "registry.io/org/policies:2.0.0"
+		sw.Op.On("PullLayer", i.Svc.Ctx, ref, PackageContentLayerMediaType, "", "").
+			Return(ocispec.Descriptor{}, nil, oci.ErrLayerNotFound)
+		sw.Op.On("PullLayer", i.Svc.Ctx, ref, PackageMetadataLayerMediaType, "", "").
+			Return(ocispec.Descriptor{}, md, nil)
+		expectedErr := "error preparing package (version: 2.0.0): metadata version (1.0.0) does not match tag"
+		sw.Ec.On("Append", i.Repository.RepositoryID, expectedErr).Return()
+
+		// Run test and check expectations
+		packages, err := NewTrackerSource(i, withOCITagsGetter(tg)).GetPackagesAvailable()
+		assert.Equal(t, map[string]*hub.Package{}, packages)
+		assert.NoError(t, err)
+		sw.AssertExpectations(t)
+		tg.AssertExpectations(t)
+	})
+
+	t.Run("opa package returned, no errors", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup services and expectations
+		sw := source.NewTestsServicesWrapper()
+		i := &hub.TrackerSourceInput{
+			Repository: &hub.Repository{
+				Kind: hub.OPA,
+				URL:  "oci://registry.io/org/policies",
+			},
+			Svc: sw.Svc,
+		}
+		tg := &oci.TagsGetterMock{}
+		tg.On("Tags", i.Svc.Ctx, i.Repository, true).Return([]string{"1.0.0"}, nil)
+		content := buildTarGz(t, map[string]string{
+			"README.md":           "# Package documentation\n",
+			"policies/p1.rego":    "policy content\n",
+			"artifacthub-pkg.yml": "ignored",
+		})
+		sw.Op.On("PullLayer", i.Svc.Ctx, ref, PackageContentLayerMediaType, "", "").
+			Return(ocispec.Descriptor{}, content, nil)
+		sw.Op.On("PullLayer", i.Svc.Ctx, ref, PackageMetadataLayerMediaType, "", "").
+			Return(ocispec.Descriptor{}, md, nil)
+		verification := &hub.SignatureVerification{Status: hub.SignatureUnverified, Kind: oci.Cosign}
+		sw.Sc.On("VerifyCosignSignature", i.Svc.Ctx, ref, "", "", []byte(nil)).Return(verification, nil)
+		provenance := &hub.Provenance{
+			PredicateType: "https://slsa.dev/provenance/v1",
+			BuilderID:     "https://github.com/actions/runner",
+			BuildLevel:    2,
+		}
+		sw.Sc.On("GetProvenance", i.Svc.Ctx, ref, "", "").Return(provenance, nil)
+
+		// Run test and check expectations
+		packages, err := NewTrackerSource(i, withOCITagsGetter(tg)).GetPackagesAvailable()
+		require.NoError(t, err)
+		require.Len(t, packages, 1)
+		p := packages["pkg1@1.0.0"]
+		require.NotNil(t, p)
+		assert.Equal(t, "0123456789", p.Digest)
+		assert.Equal(t, "# Package documentation\n", p.Readme)
+		assert.Equal(t, map[string]string{"policies/p1.rego": "policy content\n"}, p.Data[OPAPoliciesKey])
+		assert.True(t, p.Signed)
+		assert.Equal(t, []string{oci.Cosign}, p.Signatures)
+		assert.Equal(t, verification, p.SignatureVerification)
+		assert.Equal(t, provenance, p.Provenance)
+		sw.AssertExpectations(t)
+		tg.AssertExpectations(t)
+	})
+
+	t.Run("package metadata only available in content layer, manifest digest used", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup services and expectations
+		sw := source.NewTestsServicesWrapper()
+		i := &hub.TrackerSourceInput{
+			Repository: &hub.Repository{
+				Kind: hub.OPA,
+				URL:  "oci://registry.io/org/policies",
+			},
+			Svc: sw.Svc,
+		}
+		tg := &oci.TagsGetterMock{}
+		tg.On("Tags", i.Svc.Ctx, i.Repository, true).Return([]string{"1.0.0"}, nil)
+		content := buildTarGz(t, map[string]string{
+			"policies/p1.rego": "policy content\n",
+			"artifacthub-pkg.yml": `
+version: 1.0.0
+name: pkg1
+displayName: Package 1
+createdAt: 2019-06-28T15:23:00Z
+description: Description
+`,
+		})
+		sw.Op.On("PullLayer", i.Svc.Ctx, ref, PackageContentLayerMediaType, "", "").
+			Return(ocispec.Descriptor{}, content, nil)
+		sw.Op.On("PullLayer", i.Svc.Ctx, ref, PackageMetadataLayerMediaType, "", "").
+			Return(ocispec.Descriptor{}, nil, oci.ErrLayerNotFound)
+		sw.Op.On("PullManifest", i.Svc.Ctx, ref, "", "").Return(&ocispec.Manifest{}, "sha256:0123456789", nil)
+		sw.Sc.On("VerifyCosignSignature", i.Svc.Ctx, ref, "", "", []byte(nil)).Return(nil, nil)
+		sw.Sc.On("GetProvenance", i.Svc.Ctx, ref, "", "").Return(nil, nil)
+
+		// Run test and check expectations
+		packages, err := NewTrackerSource(i, withOCITagsGetter(tg)).GetPackagesAvailable()
+		require.NoError(t, err)
+		require.Len(t, packages, 1)
+		p := packages["pkg1@1.0.0"]
+		require.NotNil(t, p)
+		assert.Equal(t, "sha256:0123456789", p.Digest)
+		assert.Equal(t, map[string]string{"policies/p1.rego": "policy content\n"}, p.Data[OPAPoliciesKey])
+		sw.AssertExpectations(t)
+		tg.AssertExpectations(t)
+	})
+
+	t.Run("kubewarden package prepared from manifest annotations, no errors", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup services and expectations
+		sw := source.NewTestsServicesWrapper()
+		i := &hub.TrackerSourceInput{
+			Repository: &hub.Repository{
+				Kind: hub.Kubewarden,
+				URL:  "oci://registry.io/org/policies",
+			},
+			Svc: sw.Svc,
+		}
+		tg := &oci.TagsGetterMock{}
+		tg.On("Tags", i.Svc.Ctx, i.Repository, true).Return([]string{"1.0.0"}, nil)
+		sw.Op.On("PullLayer", i.Svc.Ctx, ref, PackageMetadataLayerMediaType, "", "").
+			Return(ocispec.Descriptor{}, nil, oci.ErrLayerNotFound)
+		sw.Op.On("PullManifest", i.Svc.Ctx, ref, "", "").Return(&ocispec.Manifest{
+			Annotations: map[string]string{
+				"io.artifacthub.displayName":       "Policy 1",
+				"io.artifacthub.keywords":          "pod, security",
+				"io.artifacthub.resources":         "Pod",
+				"io.kubewarden.policy.description": "Description",
+				"io.kubewarden.policy.license":     "Apache-2.0",
+				"io.kubewarden.policy.source":      "https://github.com/org/policy1",
+				"io.kubewarden.policy.title":       "policy1",
+				"io.kubewarden.policy.usage":       "# Usage",
+				ocispec.AnnotationCreated:          "2019-06-28T15:23:00Z",
+			},
+		}, "sha256:0123456789", nil)
+		sw.Sc.On("VerifyCosignSignature", i.Svc.Ctx, ref, "", "", []byte(nil)).Return(nil, nil)
+		sw.Sc.On("GetProvenance", i.Svc.Ctx, ref, "", "").Return(nil, nil)
+
+		// Run test and check expectations
+		packages, err := NewTrackerSource(i, withOCITagsGetter(tg)).GetPackagesAvailable()
+		require.NoError(t, err)
+		require.Len(t, packages, 1)
+		p := packages["policy1@1.0.0"]
+		require.NotNil(t, p)
+		assert.Equal(t, "sha256:0123456789", p.Digest)
+		assert.Equal(t, "Policy 1", p.DisplayName)
+		assert.Equal(t, "Description", p.Description)
+		assert.Equal(t, "Apache-2.0", p.License)
+		assert.Equal(t, "# Usage", p.Readme)
+		assert.Equal(t, []string{"pod", "security"}, p.Keywords)
+		assert.Equal(t, []*hub.Link{{Name: "source", URL: "https://github.com/org/policy1"}}, p.Links)
+		assert.Equal(t, []*hub.ContainerImage{{Name: "policy", Image: ref}}, p.ContainersImages)
+		assert.False(t, p.Signed)
+		sw.AssertExpectations(t)
+		tg.AssertExpectations(t)
+	})
+}
+
+func TestExtractTarGz(t *testing.T) {
+	t.Run("files paths are kept inside the destination path", func(t *testing.T) {
+		t.Parallel()
+		dst := t.TempDir()
+		data := buildTarGz(t, map[string]string{"../../file": "content"})
+		err := extractTarGz(data, dst)
+		assert.NoError(t, err)
+		assert.FileExists(t, filepath.Join(dst, "file"))
+	})
+
+	t.Run("invalid gzip data", func(t *testing.T) {
+		t.Parallel()
+		err := extractTarGz([]byte("invalid"), t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func buildTarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0600,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+	return buf.Bytes()
+}
+
+// withOCITagsGetter is a TrackerSource option used in tests to replace the OCI
+// tags getter with a mock.
+func withOCITagsGetter(tg hub.OCITagsGetter) func(s *TrackerSource) {
+	return func(s *TrackerSource) {
+		s.tg = tg
+	}
+}
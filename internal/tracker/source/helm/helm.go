@@ -70,7 +70,7 @@ var (
 	containersImagesRE = regexp.MustCompile(`^\s+(?:-\s+)?image:\s+(\S+)`)
 
 	// errInvalidAnnotation indicates that the annotation provided is not valid.
-	errInvalidAnnotation = errors.New("invalid annotation")
+	errInvalidAnnotation = pkg.NewValidationError(pkg.ErrInvalidValue, "invalid annotation")
 
 	// errRepositoryIndexMismatch indicates that the index.yaml file received
 	// does not match the one we were expecting.
@@ -146,7 +146,7 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 				}()
 				p, err := s.preparePackage(chartVersion)
 				if err != nil {
-					s.warn(chartVersion.Metadata, fmt.Errorf("error preparing package: %w", err))
+					s.warn(chartVersion.Metadata, hub.ErrorClassPackage, fmt.Errorf("error preparing package: %w", err))
 					return
 				}
 				mu.Lock()
@@ -254,7 +254,7 @@ func (s *TrackerSource) preparePackage(chartVersion *helmrepo.ChartVersion) (*hu
 	digest, ok := s.i.PackagesRegistered[pkg.BuildKey(p)]
 	if !ok || chartVersion.Digest != digest || bypassDigestCheck {
 		// Load chart from remote archive
-		chrt, err := LoadChartArchive(
+		chartData, err := GetChartArchive(
 			s.i.Svc.Ctx,
 			chartURL,
 			&LoadChartArchiveOptions{
@@ -267,6 +267,10 @@ func (s *TrackerSource) preparePackage(chartVersion *helmrepo.ChartVersion) (*hu
 		if err != nil {
 			return nil, fmt.Errorf("error loading chart (%s): %w", chartURL.String(), err)
 		}
+		chrt, err := loader.LoadArchive(bytes.NewReader(chartData))
+		if err != nil {
+			return nil, fmt.Errorf("error loading chart (%s): %w", chartURL.String(), err)
+		}
 		md := chrt.Metadata
 
 		// Validate chart version metadata for known issues and sanitize some strings
@@ -281,53 +285,45 @@ func (s *TrackerSource) preparePackage(chartVersion *helmrepo.ChartVersion) (*hu
 				p.LogoURL = md.Icon
 				p.LogoImageID = logoImageID
 			} else {
-				s.warn(md, fmt.Errorf("error getting logo image %s: %w", md.Icon, err))
+				s.warn(md, hub.ErrorClassLogo, fmt.Errorf("error getting logo image %s: %w", md.Icon, err))
 			}
 		}
 
-		// Check if the chart version is signed
-		var signatures []string
-		hasProvenanceFile, err := s.chartHasProvenanceFile(chartURL)
-		if err != nil {
-			s.warn(md, fmt.Errorf("error checking provenance file: %w", err))
-		}
-		if hasProvenanceFile {
-			signatures = append(signatures, prov)
+		// Enrich package with data available in chart archive
+		EnrichPackageFromChart(p, chrt)
+
+		// Enrich package with information from annotations
+		if err := EnrichPackageFromAnnotations(p, chrt.Metadata.Annotations); err != nil {
+			return nil, fmt.Errorf("error enriching package from annotations: %w", err)
 		}
+
+		// Check if the chart version is signed and verify its signatures. This
+		// must be done once the sign key has been read from the annotations.
+		s.checkSignatures(p, md, chartURL, chartData)
+
+		// Check if the OCI chart has a SLSA provenance attestation attached
 		if repo.SchemeIsOCI(chartURL) {
 			ref := strings.TrimPrefix(chartURL.String(), hub.RepositoryOCIPrefix)
-			hasCosignSignature, err := s.i.Svc.Sc.HasCosignSignature(
+			provenance, err := s.i.Svc.Sc.GetProvenance(
 				s.i.Svc.Ctx,
 				ref,
 				s.i.Repository.AuthUser,
 				s.i.Repository.AuthPass,
 			)
 			if err != nil {
-				s.warn(md, fmt.Errorf("error checking cosign signature: %w", err))
-			}
-			if hasCosignSignature {
-				signatures = append(signatures, oci.Cosign)
+				s.warn(md, hub.ErrorClassProvenance, fmt.Errorf("error getting provenance: %w", err))
+			} else {
+				p.Provenance = provenance
 			}
 		}
-		if len(signatures) > 0 {
-			p.Signed = true
-			p.Signatures = signatures
-		}
-
-		// Enrich package with data available in chart archive
-		EnrichPackageFromChart(p, chrt)
-
-		// Enrich package with information from annotations
-		if err := EnrichPackageFromAnnotations(p, chrt.Metadata.Annotations); err != nil {
-			return nil, fmt.Errorf("error enriching package from annotations: %w", err)
-		}
 	}
 
 	return p, nil
 }
 
-// chartHasProvenanceFile checks if a chart version has a provenance file.
-func (s *TrackerSource) chartHasProvenanceFile(chartURL *url.URL) (bool, error) {
+// getProvenanceFile returns the provenance file of the chart version, when
+// available.
+func (s *TrackerSource) getProvenanceFile(chartURL *url.URL) ([]byte, error) {
 	var data []byte
 
 	switch chartURL.Scheme {
@@ -339,15 +335,15 @@ func (s *TrackerSource) chartHasProvenanceFile(chartURL *url.URL) (bool, error)
 		}
 		resp, err := s.i.Svc.Hc.Do(req)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 		defer resp.Body.Close()
 		if resp.StatusCode != http.StatusOK {
-			return false, nil
+			return nil, nil
 		}
 		data, err = io.ReadAll(resp.Body)
 		if err != nil {
-			return false, fmt.Errorf("error reading provenance file: %w", err)
+			return nil, fmt.Errorf("error reading provenance file: %w", err)
 		}
 	case "oci":
 		var err error
@@ -360,28 +356,33 @@ func (s *TrackerSource) chartHasProvenanceFile(chartURL *url.URL) (bool, error)
 		)
 		if err != nil {
 			if errors.Is(err, oci.ErrLayerNotFound) {
-				return false, nil
+				return nil, nil
 			}
-			return false, fmt.Errorf("error pulling provenance layer: %w", err)
+			return nil, fmt.Errorf("error pulling provenance layer: %w", err)
 		}
 	default:
-		return false, nil
+		return nil, nil
 	}
 
 	if !bytes.Contains(data, []byte("PGP SIGNATURE")) {
-		return false, errors.New("invalid provenance file")
+		return nil, errors.New("invalid provenance file")
 	}
 
-	return true, nil
+	return data, nil
 }
 
 // warn is a helper that sends the error provided to the errors collector and
 // logs it as a warning.
-func (s *TrackerSource) warn(md *chart.Metadata, err error) {
-	err = fmt.Errorf("%w (package: %s version: %s)", err, md.Name, md.Version)
+func (s *TrackerSource) warn(md *chart.Metadata, class string, err error) {
+	err = &hub.RepositoryError{
+		PackageName: md.Name,
+		Version:     md.Version,
+		Class:       class,
+		Err:         fmt.Errorf("%w (package: %s version: %s)", err, md.Name, md.Version),
+	}
 	s.i.Svc.Logger.Warn().Err(err).Send()
 	if !md.Deprecated {
-		s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err.Error())
+		s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err)
 	}
 }
 
@@ -397,7 +398,17 @@ type LoadChartArchiveOptions struct {
 // LoadChartArchive loads a chart from a remote archive located at the url
 // provided.
 func LoadChartArchive(ctx context.Context, u *url.URL, o *LoadChartArchiveOptions) (*chart.Chart, error) {
-	var r io.Reader
+	data, err := GetChartArchive(ctx, u, o)
+	if err != nil {
+		return nil, err
+	}
+	return loader.LoadArchive(bytes.NewReader(data))
+}
+
+// GetChartArchive returns the content of the remote chart archive located at
+// the url provided.
+func GetChartArchive(ctx context.Context, u *url.URL, o *LoadChartArchiveOptions) ([]byte, error) {
+	var data []byte
 
 	switch u.Scheme {
 	case "http", "https":
@@ -424,14 +435,18 @@ func LoadChartArchive(ctx context.Context, u *url.URL, o *LoadChartArchiveOption
 		default:
 			return nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
 		}
-		r = resp.Body
+		data, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
 	case "oci":
 		op := o.Op
 		if op == nil {
 			op = oci.NewPuller(nil)
 		}
 		ref := strings.TrimPrefix(u.String(), hub.RepositoryOCIPrefix)
-		_, data, err := op.PullLayer(ctx, ref, ChartContentLayerMediaType, o.Username, o.Password)
+		var err error
+		_, data, err = op.PullLayer(ctx, ref, ChartContentLayerMediaType, o.Username, o.Password)
 		if err != nil {
 			if errors.Is(err, oci.ErrLayerNotFound) {
 				_, data, err = op.PullLayer(ctx, ref, legacyChartContentLayerMediaType, o.Username, o.Password)
@@ -442,17 +457,11 @@ func LoadChartArchive(ctx context.Context, u *url.URL, o *LoadChartArchiveOption
 				return nil, err
 			}
 		}
-		r = bytes.NewReader(data)
 	default:
 		return nil, repo.ErrSchemeNotSupported
 	}
 
-	// Load chart from reader previously set up
-	chrt, err := loader.LoadArchive(r)
-	if err != nil {
-		return nil, err
-	}
-	return chrt, nil
+	return data, nil
 }
 
 // EnrichPackageFromChart adds some extra information to the package from the
@@ -471,7 +480,7 @@ func EnrichPackageFromChart(p *hub.Package, chrt *chart.Chart) {
 	p.Data[apiVersionKey] = chrt.Metadata.APIVersion
 
 	// Containers images
-	imagesRefs, err := extractContainersImages(chrt)
+	imagesRefs, err := ExtractContainersImages(chrt)
 	if err == nil && len(imagesRefs) > 0 {
 		containersImages := make([]*hub.ContainerImage, 0, len(imagesRefs))
 		for _, imageRef := range imagesRefs {
@@ -545,10 +554,10 @@ func EnrichPackageFromChart(p *hub.Package, chrt *chart.Chart) {
 	p.Data[typeKey] = chrt.Metadata.Type
 }
 
-// extractContainersImages extracts the containers images references found in
+// ExtractContainersImages extracts the containers images references found in
 // the manifest generated as a result of Helm dry-run install with the default
 // values.
-func extractContainersImages(chrt *chart.Chart) (images []string, err error) {
+func ExtractContainersImages(chrt *chart.Chart) (images []string, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("panic running helm dry-run install: %v", r)
@@ -572,7 +581,14 @@ func extractContainersImages(chrt *chart.Chart) (images []string, err error) {
 	}
 
 	// Extract containers images from release manifest
-	s := bufio.NewScanner(strings.NewReader(release.Manifest))
+	return extractContainersImages(release.Manifest), nil
+}
+
+// extractContainersImages extracts the containers images references found in
+// the kubernetes manifest provided.
+func extractContainersImages(manifest string) []string {
+	var images []string
+	s := bufio.NewScanner(strings.NewReader(manifest))
 	for s.Scan() {
 		result := containersImagesRE.FindStringSubmatch(s.Text())
 		if result == nil {
@@ -583,8 +599,7 @@ func extractContainersImages(chrt *chart.Chart) (images []string, err error) {
 			images = append(images, image)
 		}
 	}
-
-	return images, nil
+	return images
 }
 
 // EnrichPackageFromAnnotations adds some extra information to the package from
@@ -1,7 +1,9 @@
 package helm
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -19,8 +21,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"       //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/armor" //nolint:staticcheck
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/provenance"
 	helmrepo "helm.sh/helm/v3/pkg/repo"
 )
 
@@ -500,6 +505,78 @@ func TestTrackerSource(t *testing.T) {
 		sw.AssertExpectations(t)
 	})
 
+	t.Run("one package returned, invalid provenance signature (http)", func(t *testing.T) {
+		t.Parallel()
+
+		// Setup services and expectations
+		sw := source.NewTestsServicesWrapper()
+		i := &hub.TrackerSourceInput{
+			Repository: &hub.Repository{
+				URL: "https://repo.url",
+			},
+			Svc: sw.Svc,
+		}
+		il := &repo.HelmIndexLoaderMock{}
+		il.On("LoadIndex", i.Repository).Return(&helmrepo.IndexFile{
+			Entries: map[string]helmrepo.ChartVersions{
+				"pkg1": []*helmrepo.ChartVersion{
+					{
+						Metadata: &chart.Metadata{
+							APIVersion: "v2",
+							Name:       "pkg1",
+							Version:    "1.0.0",
+						},
+						URLs: []string{
+							"https://repo.url/pkg1-1.0.0.tgz",
+						},
+					},
+				},
+			},
+		}, "", nil)
+		f, _ := os.Open("testdata/pkg1-1.0.0.tgz")
+		reqChart, _ := http.NewRequest("GET", "https://repo.url/pkg1-1.0.0.tgz", nil)
+		reqChart.Header.Set("Accept-Encoding", "*")
+		sw.Hc.On("Do", reqChart).Return(&http.Response{
+			Body:       f,
+			StatusCode: http.StatusOK,
+		}, nil)
+		key, provData, fingerprint := signChart(t, "testdata/pkg1-1.0.0.tgz")
+		reqProv, _ := http.NewRequest("GET", "https://repo.url/pkg1-1.0.0.tgz.prov", nil)
+		sw.Hc.On("Do", reqProv).Return(&http.Response{
+			Body:       io.NopCloser(bytes.NewReader(provData)),
+			StatusCode: http.StatusOK,
+		}, nil)
+		reqKey, _ := http.NewRequest("GET", "https://key.url", nil)
+		sw.Hc.On("Do", reqKey).Return(&http.Response{
+			Body:       io.NopCloser(bytes.NewReader(key)),
+			StatusCode: http.StatusOK,
+		}, nil)
+		verificationErr := "signer fingerprint " + fingerprint + " does not match the sign key fingerprint"
+		expectedErr := "invalid prov signature: " + verificationErr + " (package: pkg1 version: 1.0.0)"
+		sw.Is.On("DownloadAndSaveImage", sw.Svc.Ctx, logoImageURL).Return("logoImageID", nil)
+		sw.Ec.On("Append", i.Repository.RepositoryID, expectedErr).Return()
+
+		// Run test and check expectations
+		p := source.ClonePackage(basePkg)
+		p.Repository = i.Repository
+		p.LogoURL = logoImageURL
+		p.LogoImageID = "logoImageID"
+		p.Signed = true
+		p.Signatures = []string{"prov"}
+		p.SignatureVerification = &hub.SignatureVerification{
+			Status: hub.SignatureInvalid,
+			Kind:   prov,
+			Error:  verificationErr,
+		}
+		packages, err := NewTrackerSource(i, withIndexLoader(il)).GetPackagesAvailable()
+		assert.Equal(t, map[string]*hub.Package{
+			pkg.BuildKey(p): p,
+		}, packages)
+		assert.NoError(t, err)
+		il.AssertExpectations(t)
+		sw.AssertExpectations(t)
+	})
+
 	t.Run("one package returned, no errors (oci)", func(t *testing.T) {
 		t.Parallel()
 
@@ -514,7 +591,26 @@ func TestTrackerSource(t *testing.T) {
 		ref := strings.TrimPrefix(i.Repository.URL, hub.RepositoryOCIPrefix) + ":1.0.0"
 		tg := &oci.TagsGetterMock{}
 		tg.On("Tags", i.Svc.Ctx, i.Repository, true).Return([]string{"1.0.0"}, nil)
-		sw.Sc.On("HasCosignSignature", i.Svc.Ctx, ref, "", "").Return(true, nil)
+		reqKey, _ := http.NewRequest("GET", "https://key.url", nil)
+		sw.Hc.On("Do", reqKey).Return(&http.Response{
+			Body:       io.NopCloser(strings.NewReader("")),
+			StatusCode: http.StatusNotFound,
+		}, nil)
+		verification := &hub.SignatureVerification{
+			Status: hub.SignatureVerified,
+			Kind:   oci.Cosign,
+			Signer: "user1@email.com",
+			Issuer: "https://accounts.google.com",
+		}
+		sw.Sc.On("VerifyCosignSignature", i.Svc.Ctx, ref, "", "", []byte(nil)).Return(verification, nil)
+		provenance := &hub.Provenance{
+			PredicateType: "https://slsa.dev/provenance/v0.2",
+			BuilderID:     "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml@refs/tags/v1.9.0",
+			SourceRepo:    "https://github.com/org/repo",
+			SourceCommit:  "abcdef",
+			BuildLevel:    3,
+		}
+		sw.Sc.On("GetProvenance", i.Svc.Ctx, ref, "", "").Return(provenance, nil)
 		data, _ := os.ReadFile("testdata/pkg1-1.0.0.tgz")
 		sw.Op.On("PullLayer", mock.Anything, ref, ChartContentLayerMediaType, "", "").
 			Return(ocispec.Descriptor{}, data, nil)
@@ -531,6 +627,8 @@ func TestTrackerSource(t *testing.T) {
 		p.LogoImageID = "logoImageID"
 		p.Signed = true
 		p.Signatures = []string{"cosign"}
+		p.SignatureVerification = verification
+		p.Provenance = provenance
 		assert.Equal(t, map[string]*hub.Package{
 			pkg.BuildKey(p): p,
 		}, packages)
@@ -540,6 +638,72 @@ func TestTrackerSource(t *testing.T) {
 	})
 }
 
+func TestVerifyProvenance(t *testing.T) {
+	chartPath := "testdata/pkg1-1.0.0.tgz"
+	chartData, err := os.ReadFile(chartPath)
+	require.NoError(t, err)
+	key, provData, fingerprint := signChart(t, chartPath)
+	otherKey, _, _ := signChart(t, chartPath)
+
+	t.Run("signature verified", func(t *testing.T) {
+		t.Parallel()
+		v := verifyProvenance(provData, chartData, key, fingerprint)
+		assert.Equal(t, &hub.SignatureVerification{
+			Status:      hub.SignatureVerified,
+			Kind:        prov,
+			Signer:      "User 1 <user1@email.com>",
+			Fingerprint: fingerprint,
+		}, v)
+	})
+
+	t.Run("signature verified, fingerprint not provided", func(t *testing.T) {
+		t.Parallel()
+		v := verifyProvenance(provData, chartData, key, "")
+		assert.Equal(t, hub.SignatureVerified, v.Status)
+	})
+
+	t.Run("invalid sign key", func(t *testing.T) {
+		t.Parallel()
+		v := verifyProvenance(provData, chartData, []byte("invalid"), fingerprint)
+		assert.Equal(t, hub.SignatureUnverified, v.Status)
+		assert.Contains(t, v.Error, "invalid sign key")
+	})
+
+	t.Run("signed with a different key", func(t *testing.T) {
+		t.Parallel()
+		v := verifyProvenance(provData, chartData, otherKey, fingerprint)
+		assert.Equal(t, hub.SignatureInvalid, v.Status)
+		assert.Contains(t, v.Error, "error verifying provenance file signature")
+	})
+
+	t.Run("chart archive modified", func(t *testing.T) {
+		t.Parallel()
+		v := verifyProvenance(provData, []byte("modified"), key, fingerprint)
+		assert.Equal(t, &hub.SignatureVerification{
+			Status: hub.SignatureInvalid,
+			Kind:   prov,
+			Error:  "chart archive digest does not match the one in the provenance file",
+		}, v)
+	})
+}
+
+// signChart signs the chart archive provided using a new key, returning the
+// armored public key, the provenance file and the key fingerprint.
+func signChart(t *testing.T, chartPath string) (key, provData []byte, fingerprint string) {
+	t.Helper()
+	entity, err := openpgp.NewEntity("User 1", "", "user1@email.com", nil)
+	require.NoError(t, err)
+	var b bytes.Buffer
+	w, err := armor.Encode(&b, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+	signatory := &provenance.Signatory{Entity: entity}
+	sig, err := signatory.ClearSign(chartPath)
+	require.NoError(t, err)
+	return b.Bytes(), []byte(sig), fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)
+}
+
 func TestExtractContainersImages(t *testing.T) {
 	t.Run("valid chart", func(t *testing.T) {
 		t.Parallel()
@@ -551,7 +715,7 @@ func TestExtractContainersImages(t *testing.T) {
 		require.NoError(t, err)
 
 		// Extract containers images and check expectations
-		containersImages, err := extractContainersImages(chrt)
+		containersImages, err := ExtractContainersImages(chrt)
 		require.NoError(t, err)
 		assert.Equal(t, []string{
 			"postgres:12",
@@ -573,7 +737,7 @@ func TestExtractContainersImages(t *testing.T) {
 			Values: map[string]interface{}{},
 		}
 
-		containersImages, err := extractContainersImages(chrt)
+		containersImages, err := ExtractContainersImages(chrt)
 		assert.Nil(t, containersImages)
 		assert.Error(t, err)
 		assert.True(t, strings.HasPrefix(err.Error(), "panic running helm dry-run install"))
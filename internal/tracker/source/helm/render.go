@@ -0,0 +1,307 @@
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const (
+	// renderTimeout represents the maximum amount of time rendering a chart
+	// can take.
+	renderTimeout = 10 * time.Second
+
+	// renderMaxOutputSize represents the maximum size of the manifests
+	// generated when rendering a chart.
+	renderMaxOutputSize = 5 << 20
+
+	// renderReleaseName represents the name of the release used when
+	// rendering a chart.
+	renderReleaseName = "release-name"
+
+	// renderNamespace represents the namespace used when rendering a chart.
+	renderNamespace = "default"
+
+	// renderMaxConcurrency represents the maximum number of charts that can
+	// be rendered at the same time.
+	renderMaxConcurrency = 4
+
+	// renderMaxListSize represents the maximum number of items of the lists
+	// of integers generated by the template functions.
+	renderMaxListSize = 100000
+)
+
+var (
+	// errRenderTimeout indicates that the chart could not be rendered in the
+	// time allowed.
+	errRenderTimeout = errors.New("timeout rendering chart")
+
+	// errRenderOutputTooLarge indicates that the manifests generated when
+	// rendering the chart exceed the maximum size allowed.
+	errRenderOutputTooLarge = errors.New("rendered manifests exceed the maximum size allowed")
+
+	// renderWorkers limits the number of charts being rendered at the same
+	// time. A worker is only released once its process has exited.
+	renderWorkers = make(chan struct{}, renderMaxConcurrency)
+)
+
+// RenderChart renders the chart provided using a template engine equivalent
+// to Helm's one, using the values in the input provided to override the
+// chart's default ones. The chart is rendered without a Kubernetes client, so
+// lookups always return an empty result and no requests are made to any
+// cluster. Template functions that may access the network don't perform any
+// request (getHostByName always returns an empty string), and references to
+// remote schemas in the values schema are not resolved.
+//
+// Charts are rendered in separate worker processes, which are killed when the
+// rendering takes longer than the time allowed or uses too much memory.
+//
+// Errors found rendering the chart are returned as part of the result, as
+// they are expected when the values provided are not valid for the chart.
+func RenderChart(ctx context.Context, chrt *chart.Chart, input *hub.RenderChartInput) (*hub.RenderedChart, error) {
+	_, caps, err := prepareRenderInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &hub.RenderedChart{
+		Manifests:        make([]*hub.RenderedManifest, 0),
+		Errors:           make([]string, 0),
+		ContainersImages: make([]string, 0),
+	}
+	if chrt.Metadata.KubeVersion != "" &&
+		!chartutil.IsCompatibleRange(chrt.Metadata.KubeVersion, caps.KubeVersion.String()) {
+		result.Errors = append(result.Errors, fmt.Sprintf(
+			"chart requires kubeVersion %s which is incompatible with kubernetes %s",
+			chrt.Metadata.KubeVersion,
+			caps.KubeVersion.String(),
+		))
+		return result, nil
+	}
+	// Render chart templates
+	manifests, err := renderChartInWorker(ctx, chrt, input)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result, nil
+	}
+	var size int
+	for _, crd := range chrt.CRDObjects() {
+		manifests[crd.Filename] = string(crd.File.Data)
+	}
+	for name, content := range manifests {
+		if path.Base(name) == "NOTES.txt" || strings.TrimSpace(content) == "" {
+			continue
+		}
+		size += len(content)
+		if size > renderMaxOutputSize {
+			result.Manifests = make([]*hub.RenderedManifest, 0)
+			result.Errors = append(result.Errors, errRenderOutputTooLarge.Error())
+			return result, nil
+		}
+		result.Manifests = append(result.Manifests, &hub.RenderedManifest{
+			Name:    name,
+			Content: content,
+		})
+	}
+	sort.Slice(result.Manifests, func(i, j int) bool {
+		return result.Manifests[i].Name < result.Manifests[j].Name
+	})
+
+	// Extract containers images from the manifests generated
+	for _, m := range result.Manifests {
+		for _, image := range extractContainersImages(m.Content) {
+			if !contains(result.ContainersImages, image) {
+				result.ContainersImages = append(result.ContainersImages, image)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// prepareRenderInput prepares the values and capabilities used to render a
+// chart from the input provided.
+func prepareRenderInput(input *hub.RenderChartInput) (chartutil.Values, *chartutil.Capabilities, error) {
+	vals, err := chartutil.ReadValues([]byte(input.Values))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid values: %s", hub.ErrInvalidInput, err.Error())
+	}
+	caps := chartutil.DefaultCapabilities.Copy()
+	if input.KubeVersion != "" {
+		kubeVersion, err := chartutil.ParseKubeVersion(input.KubeVersion)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid kubernetes version", hub.ErrInvalidInput)
+		}
+		caps.KubeVersion = *kubeVersion
+	}
+	caps.APIVersions = append(caps.APIVersions, input.APIVersions...)
+	return vals, caps, nil
+}
+
+// renderChart renders the chart provided using the values and capabilities
+// provided. This function is run by the render worker processes.
+func renderChart(
+	chrt *chart.Chart,
+	vals chartutil.Values,
+	caps *chartutil.Capabilities,
+) (map[string]string, error) {
+	if err := removeSchemasRemoteRefs(chrt); err != nil {
+		return nil, err
+	}
+	if err := chartutil.ProcessDependencies(chrt, vals); err != nil {
+		return nil, fmt.Errorf("error processing dependencies: %w", err)
+	}
+	renderVals, err := chartutil.ToRenderValues(chrt, vals, chartutil.ReleaseOptions{
+		Name:      renderReleaseName,
+		Namespace: renderNamespace,
+		Revision:  1,
+		IsInstall: true,
+	}, caps)
+	if err != nil {
+		return nil, err
+	}
+	return renderTemplates(chrt, renderVals)
+}
+
+// repeat is a replacement of the sprig's repeat template function that fails
+// when the output would exceed the maximum size allowed.
+func repeat(count int, str string) (string, error) {
+	if count > 0 && len(str) > renderMaxOutputSize/count {
+		return "", errRenderOutputTooLarge
+	}
+	return strings.Repeat(str, count), nil
+}
+
+// until is a replacement of the sprig's until template function that fails
+// when the list would exceed the maximum size allowed.
+func until(count int) ([]int, error) {
+	step := 1
+	if count < 0 {
+		step = -1
+	}
+	return untilStep(0, count, step)
+}
+
+// untilStep is a replacement of the sprig's untilStep template function that
+// fails when the list would exceed the maximum size allowed.
+func untilStep(start, stop, step int) ([]int, error) {
+	if err := checkListSize(start, stop, step); err != nil {
+		return nil, err
+	}
+	v := []int{}
+	if stop < start {
+		if step >= 0 {
+			return v, nil
+		}
+		for i := start; i > stop; i += step {
+			v = append(v, i)
+		}
+		return v, nil
+	}
+	if step <= 0 {
+		return v, nil
+	}
+	for i := start; i < stop; i += step {
+		v = append(v, i)
+	}
+	return v, nil
+}
+
+// seq wraps the sprig's seq template function provided, failing when the
+// sequence would exceed the maximum size allowed.
+func seq(sprigSeq func(...int) string) func(...int) (string, error) {
+	return func(params ...int) (string, error) {
+		switch len(params) {
+		case 1:
+			if err := checkListSize(1, params[0], 1); err != nil {
+				return "", err
+			}
+		case 2:
+			if err := checkListSize(params[0], params[1], 1); err != nil {
+				return "", err
+			}
+		case 3:
+			if err := checkListSize(params[0], params[2], params[1]); err != nil {
+				return "", err
+			}
+		}
+		return sprigSeq(params...), nil
+	}
+}
+
+// checkListSize checks that the list of integers from start to stop, using
+// the step provided, does not exceed the maximum size allowed.
+func checkListSize(start, stop, step int) error {
+	if step < 0 {
+		step = -step
+	}
+	if step == 0 {
+		step = 1
+	}
+	distance := uint64(stop - start)
+	if stop < start {
+		distance = uint64(start - stop)
+	}
+	if distance/uint64(step) > renderMaxListSize {
+		return errRenderOutputTooLarge
+	}
+	return nil
+}
+
+// removeSchemasRemoteRefs removes the references to remote schemas from the
+// values schemas of the chart provided (and its dependencies), so that values
+// can be validated against them without accessing the network.
+func removeSchemasRemoteRefs(chrt *chart.Chart) error {
+	if len(chrt.Schema) > 0 {
+		schema, _, err := removeRemoteRefs(chrt.Schema)
+		if err != nil {
+			return fmt.Errorf("invalid values schema: %w", err)
+		}
+		chrt.Schema = schema
+	}
+	for _, dep := range chrt.Dependencies() {
+		if err := removeSchemasRemoteRefs(dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeRemoteRefs returns a copy of the JSON schema provided without the
+// references to remote schemas found in it, which are returned as well.
+func removeRemoteRefs(schema []byte) ([]byte, []string, error) {
+	var v interface{}
+	if err := json.Unmarshal(schema, &v); err != nil {
+		return nil, nil, err
+	}
+	var refs []string
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok && !strings.HasPrefix(ref, "#") {
+				refs = append(refs, ref)
+				delete(v, "$ref")
+			}
+			for _, e := range v {
+				walk(e)
+			}
+		case []interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(v)
+	schema, _ = json.Marshal(v)
+	return schema, refs, nil
+}
@@ -0,0 +1,412 @@
+package helm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Masterminds/sprig/v3"
+	"github.com/gobwas/glob"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"sigs.k8s.io/yaml"
+)
+
+// The template engine in this file is based on Helm's one, so charts are
+// rendered the same way they would be by Helm. Helm's engine does not allow
+// customizing the template functions used (in the Helm version used), so a
+// separate engine is needed to replace some of them when rendering charts on
+// behalf of users, without affecting any other sprig or Helm users.
+
+const (
+	// renderMaxNestedIncludes represents the maximum number of times a
+	// template can be included recursively.
+	renderMaxNestedIncludes = 1000
+
+	renderWarnStartDelim = "HELM_ERR_START"
+	renderWarnEndDelim   = "HELM_ERR_END"
+)
+
+// renderWarnRE is used to extract the message of the errors returned by the
+// required and fail template functions.
+var renderWarnRE = regexp.MustCompile(renderWarnStartDelim + `((?s).*)` + renderWarnEndDelim)
+
+// renderable represents a template that can be rendered.
+type renderable struct {
+	tpl      string
+	vals     chartutil.Values
+	basePath string
+}
+
+// renderTemplates renders the templates of the chart provided (and its
+// dependencies) using the values provided.
+func renderTemplates(chrt *chart.Chart, vals chartutil.Values) (map[string]string, error) {
+	tpls := make(map[string]renderable)
+	collectTemplates(chrt, tpls, vals)
+	return renderWithReferences(tpls, tpls)
+}
+
+// renderWithReferences renders the templates provided, which can reference
+// the templates in referenceTpls.
+func renderWithReferences(tpls, referenceTpls map[string]renderable) (rendered map[string]string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("rendering template failed: %v", r)
+		}
+	}()
+	t := template.New("gotpl")
+	t.Option("missingkey=zero")
+	t.Funcs(renderFuncMap(t, referenceTpls))
+
+	// Parse templates, favoring higher-level templates over nested ones
+	keys := sortTemplates(tpls)
+	for _, filename := range keys {
+		if _, err := t.New(filename).Parse(tpls[filename].tpl); err != nil {
+			return nil, cleanupParseError(filename, err)
+		}
+	}
+	for _, filename := range sortTemplates(referenceTpls) {
+		if t.Lookup(filename) == nil {
+			if _, err := t.New(filename).Parse(referenceTpls[filename].tpl); err != nil {
+				return nil, cleanupParseError(filename, err)
+			}
+		}
+	}
+
+	// Execute templates (partials are only rendered when included)
+	rendered = make(map[string]string, len(keys))
+	for _, filename := range keys {
+		if strings.HasPrefix(path.Base(filename), "_") {
+			continue
+		}
+		vals := tpls[filename].vals
+		vals["Template"] = chartutil.Values{"Name": filename, "BasePath": tpls[filename].basePath}
+		w := &limitedBuilder{max: renderMaxOutputSize}
+		if err := t.ExecuteTemplate(w, filename, vals); err != nil {
+			return nil, cleanupExecError(filename, err)
+		}
+		rendered[filename] = strings.ReplaceAll(w.String(), "<no value>", "")
+	}
+	return rendered, nil
+}
+
+// renderFuncMap returns the template functions available to the templates
+// rendered. It includes Helm's ones, replacing those that may access the
+// network or generate large outputs.
+func renderFuncMap(t *template.Template, referenceTpls map[string]renderable) template.FuncMap {
+	f := sprig.TxtFuncMap()
+	delete(f, "env")
+	delete(f, "expandenv")
+
+	includedNames := make(map[string]int)
+	extra := template.FuncMap{
+		"toToml":        toTOML,
+		"toYaml":        toYAML,
+		"fromYaml":      fromYAML,
+		"fromYamlArray": fromYAMLArray,
+		"toJson":        toJSON,
+		"fromJson":      fromJSON,
+		"fromJsonArray": fromJSONArray,
+		"include": func(name string, data interface{}) (string, error) {
+			if includedNames[name] > renderMaxNestedIncludes {
+				return "", fmt.Errorf("rendering template has a nested reference name: %s", name)
+			}
+			includedNames[name]++
+			defer func() { includedNames[name]-- }()
+			w := &limitedBuilder{max: renderMaxOutputSize}
+			err := t.ExecuteTemplate(w, name, data)
+			return w.String(), err
+		},
+		"tpl": func(tpl string, vals chartutil.Values) (string, error) {
+			basePath, err := vals.PathValue("Template.BasePath")
+			if err != nil {
+				return "", fmt.Errorf("cannot retrieve Template.Basepath from values inside tpl function: %s: %w", tpl, err)
+			}
+			templateName, err := vals.PathValue("Template.Name")
+			if err != nil {
+				return "", fmt.Errorf("cannot retrieve Template.Name from values inside tpl function: %s: %w", tpl, err)
+			}
+			tpls := map[string]renderable{
+				templateName.(string): {
+					tpl:      tpl,
+					vals:     vals,
+					basePath: basePath.(string),
+				},
+			}
+			result, err := renderWithReferences(tpls, referenceTpls)
+			if err != nil {
+				return "", fmt.Errorf("error during tpl function execution for %q: %w", tpl, err)
+			}
+			return result[templateName.(string)], nil
+		},
+		"required": func(warn string, val interface{}) (interface{}, error) {
+			if s, ok := val.(string); val == nil || (ok && s == "") {
+				return val, errors.New(renderWarnStartDelim + warn + renderWarnEndDelim)
+			}
+			return val, nil
+		},
+		"fail": func(msg string) (string, error) {
+			return "", errors.New(renderWarnStartDelim + msg + renderWarnEndDelim)
+		},
+		"lookup": func(string, string, string, string) (map[string]interface{}, error) {
+			return map[string]interface{}{}, nil
+		},
+
+		// Functions replaced to prevent templates from accessing the network
+		// (like newer Helm versions do when DNS lookups are not enabled) and
+		// to limit the output generated
+		"getHostByName": func(string) string { return "" },
+		"repeat":        repeat,
+		"until":         until,
+		"untilStep":     untilStep,
+		"seq":           seq(f["seq"].(func(...int) string)),
+	}
+	for k, v := range extra {
+		f[k] = v
+	}
+	return f
+}
+
+// collectTemplates collects the templates of the chart provided and its
+// dependencies, preparing the values available to each of them.
+func collectTemplates(c *chart.Chart, tpls map[string]renderable, vals chartutil.Values) map[string]interface{} {
+	subCharts := make(map[string]interface{})
+	next := map[string]interface{}{
+		"Chart": struct {
+			chart.Metadata
+			IsRoot bool
+		}{*c.Metadata, c.IsRoot()},
+		"Files":        newRenderFiles(c.Files),
+		"Release":      vals["Release"],
+		"Capabilities": vals["Capabilities"],
+		"Values":       make(chartutil.Values),
+		"Subcharts":    subCharts,
+	}
+	if c.IsRoot() {
+		next["Values"] = vals["Values"]
+	} else if vs, err := vals.Table("Values." + c.Name()); err == nil {
+		next["Values"] = vs
+	}
+	for _, child := range c.Dependencies() {
+		subCharts[child.Name()] = collectTemplates(child, tpls, next)
+	}
+	isLibrary := strings.EqualFold(c.Metadata.Type, "library")
+	for _, t := range c.Templates {
+		if isLibrary && !strings.HasPrefix(path.Base(t.Name), "_") {
+			continue
+		}
+		tpls[path.Join(c.ChartFullPath(), t.Name)] = renderable{
+			tpl:      string(t.Data),
+			vals:     next,
+			basePath: path.Join(c.ChartFullPath(), "templates"),
+		}
+	}
+	return next
+}
+
+// sortTemplates returns the names of the templates provided sorted by depth
+// (deeper first) and name.
+func sortTemplates(tpls map[string]renderable) []string {
+	keys := make([]string, 0, len(tpls))
+	for key := range tpls {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ci, cj := strings.Count(keys[i], "/"), strings.Count(keys[j], "/")
+		if ci == cj {
+			return keys[i] > keys[j]
+		}
+		return ci > cj
+	})
+	return keys
+}
+
+// cleanupParseError makes the template parse error provided more readable.
+func cleanupParseError(filename string, err error) error {
+	tokens := strings.Split(err.Error(), ": ")
+	if len(tokens) == 1 {
+		return fmt.Errorf("parse error in (%s): %s", filename, err)
+	}
+	return fmt.Errorf("parse error at (%s): %s", tokens[1], tokens[len(tokens)-1])
+}
+
+// cleanupExecError makes the template execution error provided more readable.
+func cleanupExecError(filename string, err error) error {
+	var execErr template.ExecError
+	if !errors.As(err, &execErr) {
+		return err
+	}
+	tokens := strings.SplitN(err.Error(), ": ", 3)
+	if len(tokens) != 3 {
+		return fmt.Errorf("execution error in (%s): %s", filename, err)
+	}
+	if parts := renderWarnRE.FindStringSubmatch(tokens[2]); len(parts) >= 2 {
+		return fmt.Errorf("execution error at (%s): %s", tokens[1], parts[1])
+	}
+	return err
+}
+
+// limitedBuilder is a strings builder that fails when the data written to it
+// exceeds the maximum size allowed.
+type limitedBuilder struct {
+	strings.Builder
+	max int
+}
+
+// Write implements the io.Writer interface.
+func (b *limitedBuilder) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.max {
+		return 0, errRenderOutputTooLarge
+	}
+	return b.Builder.Write(p)
+}
+
+// renderFiles represents the chart files that can be accessed from the
+// templates.
+type renderFiles map[string][]byte
+
+// newRenderFiles creates a new renderFiles instance from the files provided.
+func newRenderFiles(from []*chart.File) renderFiles {
+	files := make(renderFiles, len(from))
+	for _, f := range from {
+		files[f.Name] = f.Data
+	}
+	return files
+}
+
+// GetBytes returns the content of the file provided.
+func (f renderFiles) GetBytes(name string) []byte {
+	if v, ok := f[name]; ok {
+		return v
+	}
+	return []byte{}
+}
+
+// Get returns the content of the file provided as a string.
+func (f renderFiles) Get(name string) string {
+	return string(f.GetBytes(name))
+}
+
+// Glob returns the files matching the pattern provided.
+func (f renderFiles) Glob(pattern string) renderFiles {
+	g, err := glob.Compile(pattern, '/')
+	if err != nil {
+		g, _ = glob.Compile("**")
+	}
+	nf := make(renderFiles)
+	for name, content := range f {
+		if g.Match(name) {
+			nf[name] = content
+		}
+	}
+	return nf
+}
+
+// AsConfig returns the files as a yaml map suitable for the data section of a
+// ConfigMap.
+func (f renderFiles) AsConfig() string {
+	if f == nil {
+		return ""
+	}
+	m := make(map[string]string, len(f))
+	for k, v := range f {
+		m[path.Base(k)] = string(v)
+	}
+	return toYAML(m)
+}
+
+// AsSecrets returns the files base64 encoded as a yaml map suitable for the
+// data section of a Secret.
+func (f renderFiles) AsSecrets() string {
+	if f == nil {
+		return ""
+	}
+	m := make(map[string]string, len(f))
+	for k, v := range f {
+		m[path.Base(k)] = base64.StdEncoding.EncodeToString(v)
+	}
+	return toYAML(m)
+}
+
+// Lines returns the lines of the file provided.
+func (f renderFiles) Lines(name string) []string {
+	if f == nil || f[name] == nil {
+		return []string{}
+	}
+	return strings.Split(string(f[name]), "\n")
+}
+
+// toYAML marshals the value provided to yaml (errors are swallowed).
+func toYAML(v interface{}) string {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+// fromYAML unmarshals the yaml document provided into a map. Errors are
+// returned in the Error entry of the map.
+func fromYAML(str string) map[string]interface{} {
+	m := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(str), &m); err != nil {
+		m["Error"] = err.Error()
+	}
+	return m
+}
+
+// fromYAMLArray unmarshals the yaml array provided. Errors are returned as
+// the only item of the array.
+func fromYAMLArray(str string) []interface{} {
+	a := []interface{}{}
+	if err := yaml.Unmarshal([]byte(str), &a); err != nil {
+		a = []interface{}{err.Error()}
+	}
+	return a
+}
+
+// toTOML marshals the value provided to toml.
+func toTOML(v interface{}) string {
+	b := bytes.NewBuffer(nil)
+	if err := toml.NewEncoder(b).Encode(v); err != nil {
+		return err.Error()
+	}
+	return b.String()
+}
+
+// toJSON marshals the value provided to json (errors are swallowed).
+func toJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// fromJSON unmarshals the json document provided into a map. Errors are
+// returned in the Error entry of the map.
+func fromJSON(str string) map[string]interface{} {
+	m := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(str), &m); err != nil {
+		m["Error"] = err.Error()
+	}
+	return m
+}
+
+// fromJSONArray unmarshals the json array provided. Errors are returned as
+// the only item of the array.
+func fromJSONArray(str string) []interface{} {
+	a := []interface{}{}
+	if err := json.Unmarshal([]byte(str), &a); err != nil {
+		a = []interface{}{err.Error()}
+	}
+	return a
+}
@@ -0,0 +1,288 @@
+package helm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestMain(m *testing.M) {
+	HandleRenderWorker()
+	os.Exit(m.Run())
+}
+
+func TestRemoveRemoteRefs(t *testing.T) {
+	t.Parallel()
+	schema, refs, err := removeRemoteRefs([]byte(`{
+		"definitions": {"port": {"type": "integer"}},
+		"properties": {
+			"port": {"$ref": "#/definitions/port"},
+			"resources": {"$ref": "https://schemas.example.com/resources.json", "type": "object"},
+			"items": {"type": "array", "items": [{"$ref": "other.json"}]}
+		}
+	}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"definitions": {"port": {"type": "integer"}},
+		"properties": {
+			"port": {"$ref": "#/definitions/port"},
+			"resources": {"type": "object"},
+			"items": {"type": "array", "items": [{}]}
+		}
+	}`, string(schema))
+	assert.ElementsMatch(t, []string{"https://schemas.example.com/resources.json", "other.json"}, refs)
+
+	_, _, err = removeRemoteRefs([]byte("{"))
+	assert.Error(t, err)
+}
+
+func TestRenderChart(t *testing.T) {
+	ctx := context.Background()
+
+	// newChart returns a new chart instance, as charts may be modified when
+	// they are rendered
+	newChart := func(kubeVersion string, templates ...*chart.File) *chart.Chart {
+		return &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion:  chart.APIVersionV2,
+				Name:        "pkg1",
+				Version:     "1.0.0",
+				KubeVersion: kubeVersion,
+			},
+			Values: map[string]interface{}{
+				"image": map[string]interface{}{
+					"repository": "registry.io/pkg1",
+					"tag":        "1.0.0",
+				},
+				"sidecar": map[string]interface{}{
+					"enabled": false,
+				},
+			},
+			Templates: templates,
+			Files: []*chart.File{
+				{
+					Name: "crds/crd.yaml",
+					Data: []byte("kind: CustomResourceDefinition\n"),
+				},
+			},
+		}
+	}
+	deployment := &chart.File{
+		Name: "templates/deployment.yaml",
+		Data: []byte(`kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  containers:
+    - image: {{ .Values.image.repository }}:{{ .Values.image.tag }}
+    {{- if .Values.sidecar.enabled }}
+    - image: registry.io/sidecar:1.0.0
+    {{- end }}
+`),
+	}
+	kubeVersionCheck := &chart.File{
+		Name: "templates/kube-version.yaml",
+		Data: []byte(`{{- if semverCompare ">=1.25.0" .Capabilities.KubeVersion.Version }}kind: PodDisruptionBudget{{ end }}`),
+	}
+	notes := &chart.File{
+		Name: "templates/NOTES.txt",
+		Data: []byte("Installed {{ .Chart.Name }}"),
+	}
+
+	t.Run("no workers available", func(t *testing.T) {
+		// Not run in parallel, as it uses all workers
+		for i := 0; i < renderMaxConcurrency; i++ {
+			renderWorkers <- struct{}{}
+		}
+		defer func() {
+			for i := 0; i < renderMaxConcurrency; i++ {
+				<-renderWorkers
+			}
+		}()
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		r, err := RenderChart(ctx, newChart(""), &hub.RenderChartInput{})
+		require.NoError(t, err)
+		assert.Empty(t, r.Manifests)
+		assert.Equal(t, []string{errRenderTimeout.Error()}, r.Errors)
+	})
+
+	t.Run("rendering takes too long, worker killed", func(t *testing.T) {
+		// Not run in parallel, as it checks that the worker is released
+		tmpl := &chart.File{
+			Name: "templates/slow.yaml",
+			Data: []byte(`{{ tpl .Values.tpl . }}`),
+		}
+		ctx, cancel := context.WithTimeout(ctx, 1*time.Second)
+		defer cancel()
+		r, err := RenderChart(ctx, newChart("", tmpl), &hub.RenderChartInput{
+			Values: `tpl: '{{ range until 100000 }}{{ range until 100000 }}{{ end }}{{ end }}'`,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, r.Manifests)
+		assert.Equal(t, []string{errRenderTimeout.Error()}, r.Errors)
+		assert.Len(t, renderWorkers, 0)
+	})
+
+	t.Run("rendering uses too much memory, worker killed", func(t *testing.T) {
+		t.Parallel()
+		tmpl := &chart.File{
+			Name: "templates/memory.yaml",
+			Data: []byte(`{{ $s := "x" }}{{ range until 40 }}{{ $s = cat $s $s }}{{ end }}`),
+		}
+		r, err := RenderChart(ctx, newChart("", tmpl), &hub.RenderChartInput{})
+		require.NoError(t, err)
+		assert.Empty(t, r.Manifests)
+		assert.Equal(t, []string{errRenderMemoryLimit.Error()}, r.Errors)
+	})
+
+	t.Run("invalid values provided", func(t *testing.T) {
+		t.Parallel()
+		input := &hub.RenderChartInput{Values: "image: ["}
+		r, err := RenderChart(ctx, newChart("", deployment), input)
+		assert.True(t, errors.Is(err, hub.ErrInvalidInput))
+		assert.Nil(t, r)
+	})
+
+	t.Run("invalid kubernetes version provided", func(t *testing.T) {
+		t.Parallel()
+		input := &hub.RenderChartInput{KubeVersion: "invalid"}
+		r, err := RenderChart(ctx, newChart("", deployment), input)
+		assert.True(t, errors.Is(err, hub.ErrInvalidInput))
+		assert.Nil(t, r)
+	})
+
+	t.Run("chart incompatible with kubernetes version", func(t *testing.T) {
+		t.Parallel()
+		input := &hub.RenderChartInput{KubeVersion: "1.20.0"}
+		r, err := RenderChart(ctx, newChart(">=1.22.0", deployment), input)
+		require.NoError(t, err)
+		assert.Empty(t, r.Manifests)
+		assert.Equal(t, []string{
+			"chart requires kubeVersion >=1.22.0 which is incompatible with kubernetes v1.20.0",
+		}, r.Errors)
+	})
+
+	t.Run("template uses a function that may access the network", func(t *testing.T) {
+		t.Parallel()
+		tmpl := &chart.File{
+			Name: "templates/host.yaml",
+			Data: []byte(`host: {{ getHostByName "example.com" }}{{ tpl .Values.tpl . }}`),
+		}
+		r, err := RenderChart(ctx, newChart("", tmpl), &hub.RenderChartInput{
+			Values: `tpl: '{{ getHostByName "example.org" }}'`,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, r.Errors)
+		assert.Contains(t, r.Manifests, &hub.RenderedManifest{
+			Name:    "pkg1/templates/host.yaml",
+			Content: "host: ",
+		})
+	})
+
+	t.Run("template generates an output too large", func(t *testing.T) {
+		t.Parallel()
+		testCases := []string{
+			`{{ repeat 100000000 "data" }}`,
+			`{{ range until 1000000000 }}{{ end }}`,
+			`{{ range untilStep 0 1000000000 2 }}{{ end }}`,
+			`{{ seq 1000000000 }}`,
+		}
+		for _, data := range testCases {
+			tmpl := &chart.File{
+				Name: "templates/large.yaml",
+				Data: []byte(data),
+			}
+			r, err := RenderChart(ctx, newChart("", tmpl), &hub.RenderChartInput{})
+			require.NoError(t, err)
+			assert.Empty(t, r.Manifests)
+			require.Len(t, r.Errors, 1)
+			assert.Contains(t, r.Errors[0], errRenderOutputTooLarge.Error())
+		}
+	})
+
+	t.Run("error rendering template", func(t *testing.T) {
+		t.Parallel()
+		tmpl := &chart.File{
+			Name: "templates/required.yaml",
+			Data: []byte(`name: {{ required "name is required" .Values.name }}`),
+		}
+		r, err := RenderChart(ctx, newChart("", tmpl), &hub.RenderChartInput{})
+		require.NoError(t, err)
+		assert.Empty(t, r.Manifests)
+		require.Len(t, r.Errors, 1)
+		assert.Contains(t, r.Errors[0], "name is required")
+	})
+
+	t.Run("values do not match the values schema", func(t *testing.T) {
+		t.Parallel()
+		chrt := newChart("", deployment)
+		chrt.Schema = []byte(`{
+			"properties": {
+				"image": {
+					"properties": {
+						"tag": {"type": "string"}
+					}
+				},
+				"sidecar": {"$ref": "https://schemas.example.com/sidecar.json"}
+			}
+		}`)
+		input := &hub.RenderChartInput{Values: "image:\n  tag: 1\n"}
+		r, err := RenderChart(ctx, chrt, input)
+		require.NoError(t, err)
+		assert.Empty(t, r.Manifests)
+		require.Len(t, r.Errors, 1)
+		assert.Contains(t, r.Errors[0], "image.tag: Invalid type")
+	})
+
+	t.Run("chart rendered using default values", func(t *testing.T) {
+		t.Parallel()
+		r, err := RenderChart(ctx, newChart("", deployment, kubeVersionCheck, notes), &hub.RenderChartInput{})
+		require.NoError(t, err)
+		assert.Equal(t, &hub.RenderedChart{
+			Manifests: []*hub.RenderedManifest{
+				{
+					Name:    "pkg1/crds/crd.yaml",
+					Content: "kind: CustomResourceDefinition\n",
+				},
+				{
+					Name: "pkg1/templates/deployment.yaml",
+					Content: `kind: Deployment
+metadata:
+  name: release-name
+spec:
+  containers:
+    - image: registry.io/pkg1:1.0.0
+`,
+				},
+			},
+			Errors:           []string{},
+			ContainersImages: []string{"registry.io/pkg1:1.0.0"},
+		}, r)
+	})
+
+	t.Run("chart rendered using the values and capabilities provided", func(t *testing.T) {
+		t.Parallel()
+		input := &hub.RenderChartInput{
+			Values:      "image:\n  tag: 2.0.0\nsidecar:\n  enabled: true\n",
+			KubeVersion: "1.25.0",
+		}
+		r, err := RenderChart(ctx, newChart("", deployment, kubeVersionCheck), input)
+		require.NoError(t, err)
+		assert.Empty(t, r.Errors)
+		require.Len(t, r.Manifests, 3)
+		assert.Equal(t, "pkg1/templates/kube-version.yaml", r.Manifests[2].Name)
+		assert.Equal(t, "kind: PodDisruptionBudget", r.Manifests[2].Content)
+		assert.Equal(t, []string{
+			"registry.io/pkg1:2.0.0",
+			"registry.io/sidecar:1.0.0",
+		}, r.ContainersImages)
+	})
+}
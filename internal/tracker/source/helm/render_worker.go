@@ -0,0 +1,247 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime/debug"
+	"runtime/metrics"
+	"strings"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const (
+	// renderWorkerEnvVar represents the environment variable used to start
+	// a process as a render worker.
+	renderWorkerEnvVar = "HUB_RENDER_WORKER"
+
+	// renderWorkerMaxMemory represents the maximum amount of heap memory a
+	// render worker can use.
+	renderWorkerMaxMemory = 512 << 20
+
+	// renderWorkerMemoryExitCode represents the exit code used by the render
+	// workers when they exceed the maximum amount of memory allowed.
+	renderWorkerMemoryExitCode = 3
+
+	// renderWorkerMaxResultSize represents the maximum size of the result
+	// returned by a render worker (manifests may be escaped when encoded).
+	renderWorkerMaxResultSize = 8 * renderMaxOutputSize
+)
+
+var (
+	// errRenderMemoryLimit indicates that rendering the chart exceeded the
+	// maximum amount of memory allowed.
+	errRenderMemoryLimit = errors.New("rendering chart exceeded the maximum memory allowed")
+)
+
+// renderJob represents the information sent to a render worker to render a
+// chart.
+type renderJob struct {
+	Chart *renderJobChart       `json:"chart"`
+	Input *hub.RenderChartInput `json:"input"`
+}
+
+// renderJobChart represents a chart (and its dependencies) sent to a render
+// worker.
+type renderJobChart struct {
+	Metadata     *chart.Metadata        `json:"metadata"`
+	Lock         *chart.Lock            `json:"lock"`
+	Templates    []*chart.File          `json:"templates"`
+	Values       map[string]interface{} `json:"values"`
+	Schema       []byte                 `json:"schema"`
+	Files        []*chart.File          `json:"files"`
+	Dependencies []*renderJobChart      `json:"dependencies"`
+}
+
+// newRenderJobChart creates a new renderJobChart instance from the chart
+// provided.
+func newRenderJobChart(chrt *chart.Chart) *renderJobChart {
+	c := &renderJobChart{
+		Metadata:  chrt.Metadata,
+		Lock:      chrt.Lock,
+		Templates: chrt.Templates,
+		Values:    chrt.Values,
+		Schema:    chrt.Schema,
+		Files:     chrt.Files,
+	}
+	for _, dep := range chrt.Dependencies() {
+		c.Dependencies = append(c.Dependencies, newRenderJobChart(dep))
+	}
+	return c
+}
+
+// toChart returns the chart represented by the renderJobChart instance.
+func (c *renderJobChart) toChart() *chart.Chart {
+	chrt := &chart.Chart{
+		Metadata:  c.Metadata,
+		Lock:      c.Lock,
+		Templates: c.Templates,
+		Values:    c.Values,
+		Schema:    c.Schema,
+		Files:     c.Files,
+	}
+	for _, dep := range c.Dependencies {
+		chrt.AddDependency(dep.toChart())
+	}
+	return chrt
+}
+
+// renderJobResult represents the result returned by a render worker.
+type renderJobResult struct {
+	Manifests map[string]string `json:"manifests"`
+	Error     string            `json:"error"`
+}
+
+// HandleRenderWorker runs the current process as a render worker when it was
+// started for that purpose, exiting once the chart has been rendered. It must
+// be called at the beginning of the main function of the programs that render
+// charts, as render workers are started by executing the current program.
+func HandleRenderWorker() {
+	if os.Getenv(renderWorkerEnvVar) == "" {
+		return
+	}
+	os.Exit(runRenderWorker(os.Stdin, os.Stdout))
+}
+
+// runRenderWorker renders the chart in the job read from r, writing the
+// result to w. It returns the exit code of the worker process.
+func runRenderWorker(r io.Reader, w io.Writer) int {
+	// Make sure the worker does not outlive the time allowed or use too much
+	// memory, even if the process that started it is gone
+	time.AfterFunc(renderTimeout, func() { os.Exit(1) })
+	debug.SetMemoryLimit(renderWorkerMaxMemory)
+	go watchRenderWorkerMemory()
+
+	// Render chart
+	var job *renderJob
+	if err := json.NewDecoder(r).Decode(&job); err != nil || job.Chart == nil || job.Input == nil {
+		return 1
+	}
+	var result renderJobResult
+	vals, caps, err := prepareRenderInput(job.Input)
+	if err == nil {
+		result.Manifests, err = renderChart(job.Chart.toChart(), vals, caps)
+	}
+	if err != nil {
+		result = renderJobResult{Error: err.Error()}
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(result); err != nil {
+		return 1
+	}
+	if buf.Len() > renderWorkerMaxResultSize {
+		buf.Reset()
+		_ = enc.Encode(renderJobResult{Error: errRenderOutputTooLarge.Error()})
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// watchRenderWorkerMemory exits the render worker process when the heap
+// memory in use exceeds the maximum allowed.
+func watchRenderWorkerMemory() {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	for {
+		metrics.Read(sample)
+		if sample[0].Value.Kind() == metrics.KindUint64 && sample[0].Value.Uint64() > renderWorkerMaxMemory {
+			os.Exit(renderWorkerMemoryExitCode)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// renderChartInWorker renders the chart provided in a render worker process,
+// which is killed if it takes longer than the time allowed (waiting for a
+// worker included) or the context provided is cancelled.
+func renderChartInWorker(ctx context.Context, chrt *chart.Chart, input *hub.RenderChartInput) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, renderTimeout)
+	defer cancel()
+
+	// Wait for a worker to be available
+	select {
+	case renderWorkers <- struct{}{}:
+	case <-ctx.Done():
+		return nil, errRenderTimeout
+	}
+	defer func() { <-renderWorkers }()
+
+	// Prepare job
+	jobJSON, err := json.Marshal(&renderJob{
+		Chart: newRenderJobChart(chrt),
+		Input: input,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Start worker process and wait for the result
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	stdout := &limitedBuffer{max: renderWorkerMaxResultSize}
+	stderr := &limitedBuffer{max: 4096, truncate: true}
+	cmd := exec.CommandContext(ctx, executable) // #nosec
+	cmd.Env = []string{
+		renderWorkerEnvVar + "=1",
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + os.Getenv("HOME"),
+	}
+	cmd.Stdin = bytes.NewReader(jobJSON)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, errRenderTimeout
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == renderWorkerMemoryExitCode {
+			return nil, errRenderMemoryLimit
+		}
+		if errors.Is(err, errRenderOutputTooLarge) {
+			return nil, errRenderOutputTooLarge
+		}
+		return nil, fmt.Errorf("error rendering chart: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	var result *renderJobResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, fmt.Errorf("error rendering chart: invalid result: %w", err)
+	}
+	if result.Error != "" {
+		return nil, errors.New(result.Error)
+	}
+	return result.Manifests, nil
+}
+
+// limitedBuffer is a bytes buffer that fails when the data written to it
+// exceeds the maximum size allowed, or discards the data that does not fit in
+// it when truncate is enabled.
+type limitedBuffer struct {
+	bytes.Buffer
+	max      int
+	truncate bool
+}
+
+// Write implements the io.Writer interface.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.max {
+		if !b.truncate {
+			return 0, errRenderOutputTooLarge
+		}
+		_, _ = b.Buffer.Write(p[:b.max-b.Len()])
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,222 @@
+package helm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/oci"
+	"github.com/artifacthub/hub/internal/repo"
+	"golang.org/x/crypto/openpgp"           //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/clearsign" //nolint:staticcheck
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// maxSignKeySize represents the maximum size of the sign keys fetched from
+// the url provided in the signKey annotation.
+const maxSignKeySize = 1 << 20
+
+// checkSignatures checks if the chart version is signed, verifying the
+// signatures found when possible. Helm provenance files are verified using
+// the key available at the sign key url, whereas cosign signatures (OCI
+// charts only) are verified by the OCI signature checker.
+func (s *TrackerSource) checkSignatures(p *hub.Package, md *chart.Metadata, chartURL *url.URL, chartData []byte) {
+	var signatures []string
+	var verifications []*hub.SignatureVerification
+
+	// The sign key is fetched at most once
+	var signKey []byte
+	var signKeyErr error
+	var signKeyFetched bool
+	getKey := func() ([]byte, error) {
+		if !signKeyFetched {
+			signKey, signKeyErr = s.getSignKey(p.SignKey)
+			signKeyFetched = true
+		}
+		return signKey, signKeyErr
+	}
+
+	// Helm provenance file
+	provData, err := s.getProvenanceFile(chartURL)
+	if err != nil {
+		s.warn(md, hub.ErrorClassSignature, fmt.Errorf("error checking provenance file: %w", err))
+	}
+	if provData != nil {
+		signatures = append(signatures, prov)
+		var v *hub.SignatureVerification
+		if key, err := getKey(); err != nil {
+			v = &hub.SignatureVerification{
+				Status: hub.SignatureUnverified,
+				Kind:   prov,
+				Error:  err.Error(),
+			}
+		} else {
+			v = verifyProvenance(provData, chartData, key, p.SignKey.Fingerprint)
+		}
+		verifications = append(verifications, v)
+	}
+
+	// Cosign signature
+	if repo.SchemeIsOCI(chartURL) {
+		var cosignKey []byte
+		if key, err := getKey(); err == nil && bytes.Contains(key, []byte("-----BEGIN PUBLIC KEY-----")) {
+			cosignKey = key
+		}
+		ref := strings.TrimPrefix(chartURL.String(), hub.RepositoryOCIPrefix)
+		v, err := s.i.Svc.Sc.VerifyCosignSignature(
+			s.i.Svc.Ctx,
+			ref,
+			s.i.Repository.AuthUser,
+			s.i.Repository.AuthPass,
+			cosignKey,
+		)
+		if err != nil {
+			s.warn(md, hub.ErrorClassSignature, fmt.Errorf("error checking cosign signature: %w", err))
+		}
+		if v != nil {
+			signatures = append(signatures, oci.Cosign)
+			verifications = append(verifications, v)
+		}
+	}
+
+	if len(signatures) > 0 {
+		p.Signed = true
+		p.Signatures = signatures
+		p.SignatureVerification = selectSignatureVerification(verifications)
+		if p.SignatureVerification.Status == hub.SignatureInvalid {
+			s.warn(md, hub.ErrorClassSignature, fmt.Errorf(
+				"invalid %s signature: %s",
+				p.SignatureVerification.Kind,
+				p.SignatureVerification.Error,
+			))
+		}
+	}
+}
+
+// getSignKey fetches the sign key from the url provided in the sign key.
+func (s *TrackerSource) getSignKey(signKey *hub.SignKey) ([]byte, error) {
+	if signKey == nil || signKey.URL == "" {
+		return nil, errors.New("sign key not provided")
+	}
+	req, _ := http.NewRequest("GET", signKey.URL, nil)
+	req = req.WithContext(s.i.Svc.Ctx)
+	resp, err := s.i.Svc.Hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sign key: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error getting sign key: unexpected status code received: %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSignKeySize))
+	if err != nil {
+		return nil, fmt.Errorf("error reading sign key: %w", err)
+	}
+	return data, nil
+}
+
+// verifyProvenance verifies the provenance file provided using the key
+// provided (armored or binary OpenPGP keyring). The chart archive must match
+// one of the files hashes in the provenance file, and the fingerprint of the
+// signer must match the expected fingerprint, when provided.
+func verifyProvenance(provData, chartData, key []byte, fingerprint string) *hub.SignatureVerification {
+	invalid := func(err error) *hub.SignatureVerification {
+		return &hub.SignatureVerification{
+			Status: hub.SignatureInvalid,
+			Kind:   prov,
+			Error:  err.Error(),
+		}
+	}
+
+	// Load keyring
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+	if err != nil {
+		keyring, err = openpgp.ReadKeyRing(bytes.NewReader(key))
+		if err != nil {
+			return &hub.SignatureVerification{
+				Status: hub.SignatureUnverified,
+				Kind:   prov,
+				Error:  fmt.Sprintf("invalid sign key: %v", err),
+			}
+		}
+	}
+
+	// Verify provenance file signature
+	block, _ := clearsign.Decode(provData)
+	if block == nil {
+		return invalid(errors.New("invalid provenance file"))
+	}
+	signer, err := openpgp.CheckDetachedSignature(keyring, bytes.NewReader(block.Bytes), block.ArmoredSignature.Body)
+	if err != nil {
+		return invalid(fmt.Errorf("error verifying provenance file signature: %w", err))
+	}
+
+	// Check the chart archive matches the one signed
+	parts := bytes.Split(block.Plaintext, []byte("\n...\n"))
+	if len(parts) < 2 {
+		return invalid(errors.New("invalid provenance file: files section not found"))
+	}
+	var sums struct {
+		Files map[string]string `yaml:"files"`
+	}
+	if err := yaml.Unmarshal(parts[1], &sums); err != nil {
+		return invalid(fmt.Errorf("invalid provenance file: %w", err))
+	}
+	sum := sha256.Sum256(chartData)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	var digestFound bool
+	for _, fileDigest := range sums.Files {
+		if fileDigest == digest {
+			digestFound = true
+			break
+		}
+	}
+	if !digestFound {
+		return invalid(errors.New("chart archive digest does not match the one in the provenance file"))
+	}
+
+	// Check signer fingerprint matches the sign key one
+	signerFingerprint := fmt.Sprintf("%X", signer.PrimaryKey.Fingerprint)
+	if fingerprint != "" && strings.ToUpper(strings.ReplaceAll(fingerprint, " ", "")) != signerFingerprint {
+		return invalid(fmt.Errorf("signer fingerprint %s does not match the sign key fingerprint", signerFingerprint))
+	}
+
+	identities := make([]string, 0, len(signer.Identities))
+	for identity := range signer.Identities {
+		identities = append(identities, identity)
+	}
+	sort.Strings(identities)
+	return &hub.SignatureVerification{
+		Status:      hub.SignatureVerified,
+		Kind:        prov,
+		Signer:      strings.Join(identities, ", "),
+		Fingerprint: signerFingerprint,
+	}
+}
+
+// selectSignatureVerification selects the verification to store in the
+// package from the ones provided. Verified signatures take precedence over
+// invalid ones, and invalid ones over those that couldn't be verified.
+func selectSignatureVerification(verifications []*hub.SignatureVerification) *hub.SignatureVerification {
+	rank := map[string]int{
+		hub.SignatureVerified:   0,
+		hub.SignatureInvalid:    1,
+		hub.SignatureUnverified: 2,
+	}
+	var selected *hub.SignatureVerification
+	for _, v := range verifications {
+		if selected == nil || rank[v.Status] < rank[selected.Status] {
+			selected = v
+		}
+	}
+	return selected
+}
@@ -0,0 +1,164 @@
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/xeipuuv/gojsonschema"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// globalValuesKey represents the key used in Helm values to provide values
+// shared by the chart and its dependencies.
+const globalValuesKey = "global"
+
+// ValidateValues validates the values in the input provided against the
+// values schema and the default values of the chart provided. Values that do
+// not match the schema (once merged with the default ones) are reported as
+// errors, whereas keys that are neither in the default values nor defined in
+// the schema are reported as warnings. References to remote schemas in the
+// values schema are not resolved.
+func ValidateValues(chrt *chart.Chart, input *hub.ValuesValidationInput) (*hub.ValuesValidation, error) {
+	vals, err := chartutil.ReadValues([]byte(input.Values))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid values: %s", hub.ErrInvalidInput, err.Error())
+	}
+	v := &hub.ValuesValidation{
+		Errors:   make([]*hub.ValuesValidationIssue, 0),
+		Warnings: make([]*hub.ValuesValidationIssue, 0),
+	}
+
+	// Validate values against the chart's values schema
+	var schema map[string]interface{}
+	if len(chrt.Schema) > 0 {
+		schemaJSON, remoteRefs, err := removeRemoteRefs(chrt.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid values schema: %w", err)
+		}
+		for _, ref := range remoteRefs {
+			v.Warnings = append(v.Warnings, &hub.ValuesValidationIssue{
+				Message: fmt.Sprintf("remote schema reference not resolved: %s", ref),
+			})
+		}
+		_ = json.Unmarshal(schemaJSON, &schema)
+		mergedVals, err := chartutil.CoalesceValues(chrt, vals)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, err.Error())
+		}
+		mergedValsJSON, err := json.Marshal(mergedVals)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, err.Error())
+		}
+		result, err := gojsonschema.Validate(
+			gojsonschema.NewBytesLoader(schemaJSON),
+			gojsonschema.NewBytesLoader(mergedValsJSON),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error validating values: %w", err)
+		}
+		for _, e := range result.Errors() {
+			v.Errors = append(v.Errors, &hub.ValuesValidationIssue{
+				Pointer: schemaErrorPointer(e),
+				Message: e.Description(),
+			})
+		}
+	}
+
+	// Look for keys not found in the default values nor in the schema
+	knownKeys := map[string]struct{}{globalValuesKey: {}}
+	for _, dep := range chrt.Metadata.Dependencies {
+		knownKeys[dep.Name] = struct{}{}
+		if dep.Alias != "" {
+			knownKeys[dep.Alias] = struct{}{}
+		}
+	}
+	findUnknownKeys("", vals, chrt.Values, schema, knownKeys, &v.Warnings)
+
+	sortIssues := func(issues []*hub.ValuesValidationIssue) {
+		sort.SliceStable(issues, func(i, j int) bool {
+			return issues[i].Pointer < issues[j].Pointer
+		})
+	}
+	sortIssues(v.Errors)
+	sortIssues(v.Warnings)
+	v.Valid = len(v.Errors) == 0
+	return v, nil
+}
+
+// findUnknownKeys looks for keys in the values provided that are neither in
+// the default values nor defined in the schema provided, appending a warning
+// to the issues provided for each of them. Keys in the known keys provided are
+// not checked.
+func findUnknownKeys(
+	pointer string,
+	values, defaults, schema map[string]interface{},
+	knownKeys map[string]struct{},
+	issues *[]*hub.ValuesValidationIssue,
+) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	_, hasPatternProperties := schema["patternProperties"]
+	_, additionalPropertiesIsSchema := schema["additionalProperties"].(map[string]interface{})
+	_, hasRef := schema["$ref"]
+	anyKeyAllowed := hasPatternProperties || additionalPropertiesIsSchema || hasRef
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := knownKeys[key]; ok {
+			continue
+		}
+		keyPointer := pointer + "/" + escapeJSONPointerToken(key)
+		defaultValue, inDefaults := defaults[key]
+		propertySchema, inSchema := properties[key].(map[string]interface{})
+		if !inDefaults && !inSchema {
+			if !anyKeyAllowed {
+				*issues = append(*issues, &hub.ValuesValidationIssue{
+					Pointer: keyPointer,
+					Message: fmt.Sprintf("unknown key %s: not found in the default values nor in the values schema", key),
+				})
+			}
+			continue
+		}
+
+		// Check nested values when we know which keys are expected
+		nestedValues, ok := values[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		nestedDefaults, _ := defaultValue.(map[string]interface{})
+		_, nestedSchemaHasProperties := propertySchema["properties"]
+		if len(nestedDefaults) > 0 || nestedSchemaHasProperties {
+			findUnknownKeys(keyPointer, nestedValues, nestedDefaults, propertySchema, nil, issues)
+		}
+	}
+}
+
+// schemaErrorPointer returns a JSON pointer to the value the schema validation
+// error provided refers to.
+func schemaErrorPointer(e gojsonschema.ResultError) string {
+	const del = "\x00"
+	tokens := strings.Split(e.Context().String(del), del)[1:] // Skip root
+	switch e.Type() {
+	case "required", "additional_property_not_allowed":
+		if property, ok := e.Details()["property"].(string); ok {
+			tokens = append(tokens, property)
+		}
+	}
+	var pointer string
+	for _, token := range tokens {
+		pointer += "/" + escapeJSONPointerToken(token)
+	}
+	return pointer
+}
+
+// escapeJSONPointerToken escapes the JSON pointer reference token provided.
+func escapeJSONPointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
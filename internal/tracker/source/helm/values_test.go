@@ -0,0 +1,145 @@
+package helm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestValidateValues(t *testing.T) {
+	newChart := func(schema string) *chart.Chart {
+		chrt := &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion: chart.APIVersionV2,
+				Name:       "pkg1",
+				Version:    "1.0.0",
+				Dependencies: []*chart.Dependency{
+					{Name: "postgresql", Alias: "db"},
+				},
+			},
+			Values: map[string]interface{}{
+				"image": map[string]interface{}{
+					"repository": "registry.io/pkg1",
+					"tag":        "1.0.0",
+				},
+				"podAnnotations": map[string]interface{}{},
+				"replicas":       1,
+			},
+		}
+		if schema != "" {
+			chrt.Schema = []byte(schema)
+		}
+		return chrt
+	}
+	schema := `{
+		"type": "object",
+		"required": ["image"],
+		"properties": {
+			"image": {
+				"type": "object",
+				"required": ["repository"],
+				"properties": {
+					"repository": {"type": "string"},
+					"tag": {"type": "string"},
+					"pullPolicy": {"enum": ["Always", "IfNotPresent"]}
+				}
+			},
+			"replicas": {"type": "integer", "minimum": 1},
+			"resources": {"$ref": "https://schemas.example.com/resources.json"},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}}
+		}
+	}`
+
+	t.Run("invalid values provided", func(t *testing.T) {
+		t.Parallel()
+		v, err := ValidateValues(newChart(""), &hub.ValuesValidationInput{Values: "image: ["})
+		assert.True(t, errors.Is(err, hub.ErrInvalidInput))
+		assert.Nil(t, v)
+	})
+
+	t.Run("invalid values schema", func(t *testing.T) {
+		t.Parallel()
+		v, err := ValidateValues(newChart("{"), &hub.ValuesValidationInput{})
+		assert.Error(t, err)
+		assert.Nil(t, v)
+	})
+
+	t.Run("valid values, chart without schema", func(t *testing.T) {
+		t.Parallel()
+		input := &hub.ValuesValidationInput{
+			Values: "image:\n  tag: 2.0.0\npodAnnotations:\n  example.com/key: value\nglobal:\n  registry: r.io\ndb:\n  enabled: true\n",
+		}
+		v, err := ValidateValues(newChart(""), input)
+		require.NoError(t, err)
+		assert.Equal(t, &hub.ValuesValidation{
+			Valid:    true,
+			Errors:   []*hub.ValuesValidationIssue{},
+			Warnings: []*hub.ValuesValidationIssue{},
+		}, v)
+	})
+
+	t.Run("unknown keys, chart without schema", func(t *testing.T) {
+		t.Parallel()
+		input := &hub.ValuesValidationInput{
+			Values: "image:\n  tagg: 2.0.0\nreplica: 2\n",
+		}
+		v, err := ValidateValues(newChart(""), input)
+		require.NoError(t, err)
+		assert.Equal(t, &hub.ValuesValidation{
+			Valid:  true,
+			Errors: []*hub.ValuesValidationIssue{},
+			Warnings: []*hub.ValuesValidationIssue{
+				{
+					Pointer: "/image/tagg",
+					Message: "unknown key tagg: not found in the default values nor in the values schema",
+				},
+				{
+					Pointer: "/replica",
+					Message: "unknown key replica: not found in the default values nor in the values schema",
+				},
+			},
+		}, v)
+	})
+
+	t.Run("values do not match the schema", func(t *testing.T) {
+		t.Parallel()
+		input := &hub.ValuesValidationInput{
+			Values: "image:\n  repository: null\n  tag: 2\n  pullPolicy: Never\nreplicas: 0\nresources:\n  limits: {}\nlabels:\n  app/name: pkg1\n",
+		}
+		v, err := ValidateValues(newChart(schema), input)
+		require.NoError(t, err)
+		assert.False(t, v.Valid)
+		assert.Equal(t, []*hub.ValuesValidationIssue{
+			{Pointer: "/image/pullPolicy", Message: "image.pullPolicy must be one of the following: \"Always\", \"IfNotPresent\""},
+			{Pointer: "/image/repository", Message: "repository is required"},
+			{Pointer: "/image/tag", Message: "Invalid type. Expected: string, given: integer"},
+			{Pointer: "/replicas", Message: "Must be greater than or equal to 1"},
+		}, v.Errors)
+		assert.Equal(t, []*hub.ValuesValidationIssue{
+			{Pointer: "", Message: "remote schema reference not resolved: https://schemas.example.com/resources.json"},
+		}, v.Warnings)
+	})
+
+	t.Run("values match the schema", func(t *testing.T) {
+		t.Parallel()
+		input := &hub.ValuesValidationInput{
+			Values: "image:\n  pullPolicy: Always\nlabels:\n  app: pkg1\nresources:\n  limits: {}\n",
+		}
+		v, err := ValidateValues(newChart(schema), input)
+		require.NoError(t, err)
+		assert.True(t, v.Valid)
+		assert.Empty(t, v.Errors)
+		assert.Len(t, v.Warnings, 1)
+	})
+}
+
+func TestEscapeJSONPointerToken(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "key", escapeJSONPointerToken("key"))
+	assert.Equal(t, "example.com~1key", escapeJSONPointerToken("example.com/key"))
+	assert.Equal(t, "a~0b", escapeJSONPointerToken("a~b"))
+}
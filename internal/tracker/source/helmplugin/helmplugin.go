@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/artifacthub/hub/internal/hub"
@@ -57,7 +58,11 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 		md, err := GetMetadata(pluginMetadataPath)
 		if err != nil {
 			if !errors.Is(err, os.ErrNotExist) {
-				s.warn(fmt.Errorf("error getting plugin metadata (path: %s): %w", pluginMetadataPath, err))
+				s.warn(&hub.RepositoryError{
+					Path:  strings.TrimPrefix(pluginMetadataPath, s.i.BasePath),
+					Class: hub.ErrorClassMetadata,
+					Err:   fmt.Errorf("error getting plugin metadata (path: %s): %w", pluginMetadataPath, err),
+				})
 			}
 			return nil
 		}
@@ -65,7 +70,13 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 		// Prepare and store package version
 		p, err := PreparePackage(s.i.Repository, md, pkgPath)
 		if err != nil {
-			s.warn(fmt.Errorf("error preparing package %s version %s: %w", md.Name, md.Version, err))
+			s.warn(&hub.RepositoryError{
+				PackageName: md.Name,
+				Version:     md.Version,
+				Path:        strings.TrimPrefix(pkgPath, s.i.BasePath),
+				Class:       hub.ErrorClassPackage,
+				Err:         fmt.Errorf("error preparing package %s version %s: %w", md.Name, md.Version, err),
+			})
 			return nil
 		}
 		packagesAvailable[pkg.BuildKey(p)] = p
@@ -83,7 +94,7 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 // logs it as a warning.
 func (s *TrackerSource) warn(err error) {
 	s.i.Svc.Logger.Warn().Err(err).Send()
-	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err.Error())
+	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err)
 }
 
 // GetManifest reads and parses the plugin metadata file.
@@ -107,15 +118,15 @@ func validateMetadata(md *plugin.Metadata) error {
 	var errs *multierror.Error
 
 	if md.Name == "" {
-		errs = multierror.Append(errs, errors.New("name not provided"))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "name not provided"))
 	}
 	if md.Version == "" {
-		errs = multierror.Append(errs, errors.New("version not provided"))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "version not provided"))
 	} else if _, err := semver.NewVersion(md.Version); err != nil {
-		errs = multierror.Append(errs, fmt.Errorf("invalid version (semver expected): %w", err))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrInvalidValue, "invalid version (semver expected): %w", err))
 	}
 	if md.Description == "" {
-		errs = multierror.Append(errs, errors.New("description not provided"))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "description not provided"))
 	}
 
 	return errs.ErrorOrNil()
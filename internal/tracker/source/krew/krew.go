@@ -1,11 +1,11 @@
 package krew
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/artifacthub/hub/internal/hub"
@@ -47,7 +47,7 @@ const (
 
 var (
 	// errInvalidAnnotation indicates that the annotation provided is not valid.
-	errInvalidAnnotation = errors.New("invalid annotation")
+	errInvalidAnnotation = pkg.NewValidationError(pkg.ErrInvalidValue, "invalid annotation")
 )
 
 // TrackerSource is a hub.TrackerSource implementation for Krew plugins
@@ -88,18 +88,28 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 		pluginManifestPath := filepath.Join(pluginsPath, file.Name())
 		manifest, manifestRaw, err := GetManifest(pluginManifestPath)
 		if err != nil {
-			s.warn(fmt.Errorf("error getting package manifest (path: %s): %w", pluginManifestPath, err))
+			s.warn(&hub.RepositoryError{
+				Path:  strings.TrimPrefix(pluginManifestPath, s.i.BasePath),
+				Class: hub.ErrorClassMetadata,
+				Err:   fmt.Errorf("error getting package manifest (path: %s): %w", pluginManifestPath, err),
+			})
 			continue
 		}
 
 		// Prepare and store package version
 		p, err := PreparePackage(s.i.Repository, manifest, manifestRaw)
 		if err != nil {
-			s.warn(fmt.Errorf("error preparing package %s version %s: %w",
-				manifest.ObjectMeta.Name,
-				manifest.Spec.Version,
-				err,
-			))
+			s.warn(&hub.RepositoryError{
+				PackageName: manifest.ObjectMeta.Name,
+				Version:     manifest.Spec.Version,
+				Path:        strings.TrimPrefix(pluginManifestPath, s.i.BasePath),
+				Class:       hub.ErrorClassPackage,
+				Err: fmt.Errorf("error preparing package %s version %s: %w",
+					manifest.ObjectMeta.Name,
+					manifest.Spec.Version,
+					err,
+				),
+			})
 			continue
 		}
 		packagesAvailable[pkg.BuildKey(p)] = p
@@ -112,7 +122,7 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 // logs it as a warning.
 func (s *TrackerSource) warn(err error) {
 	s.i.Svc.Logger.Warn().Err(err).Send()
-	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err.Error())
+	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err)
 }
 
 // GetManifest reads and parses the plugin manifest.
@@ -136,15 +146,15 @@ func validateManifest(manifest *index.Plugin) error {
 	var errs *multierror.Error
 
 	if manifest.ObjectMeta.Name == "" {
-		errs = multierror.Append(errs, errors.New("name not provided"))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "name not provided"))
 	}
 	if manifest.Spec.Version == "" {
-		errs = multierror.Append(errs, errors.New("version not provided"))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "version not provided"))
 	} else if _, err := semver.NewVersion(manifest.Spec.Version); err != nil {
-		errs = multierror.Append(errs, fmt.Errorf("invalid version (semver expected): %w", err))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrInvalidValue, "invalid version (semver expected): %w", err))
 	}
 	if manifest.Spec.ShortDescription == "" {
-		errs = multierror.Append(errs, errors.New("description not provided"))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "description not provided"))
 	}
 
 	return errs.ErrorOrNil()
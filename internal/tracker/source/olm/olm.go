@@ -3,7 +3,6 @@ package olm
 import (
 	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -29,6 +28,7 @@ const (
 
 	formatKey           = "format"
 	isGlobalOperatorKey = "isGlobalOperator"
+	requiredAPIsKey     = "requiredAPIs"
 
 	// Artifact Hub special annotations
 	changesAnnotation         = "artifacthub.io/changes"
@@ -79,7 +79,11 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 		// Get package version metadata
 		md, err := GetMetadata(path)
 		if err != nil {
-			s.warn(fmt.Errorf("error getting package metadata: %w", err))
+			s.warn(&hub.RepositoryError{
+				Path:  strings.TrimPrefix(path, s.i.BasePath),
+				Class: hub.ErrorClassMetadata,
+				Err:   fmt.Errorf("error getting package metadata: %w", err),
+			})
 			return nil
 		}
 		if md == nil {
@@ -90,13 +94,25 @@ func (s *TrackerSource) GetPackagesAvailable() (map[string]*hub.Package, error)
 		// Prepare and store package version
 		p, err := PreparePackage(s.i.Repository, md)
 		if err != nil {
-			s.warn(fmt.Errorf("error preparing package %s version %s: %w", md.Name, md.Version, err))
+			s.warn(&hub.RepositoryError{
+				PackageName: md.Name,
+				Version:     md.Version,
+				Path:        strings.TrimPrefix(path, s.i.BasePath),
+				Class:       hub.ErrorClassPackage,
+				Err:         fmt.Errorf("error preparing package %s version %s: %w", md.Name, md.Version, err),
+			})
 			return nil
 		}
 		packagesAvailable[pkg.BuildKey(p)] = p
 		logoImageID, err := s.prepareLogoImage(md)
 		if err != nil {
-			s.warn(fmt.Errorf("error preparing package %s version %s logo image: %w", md.Name, md.Version, err))
+			s.warn(&hub.RepositoryError{
+				PackageName: md.Name,
+				Version:     md.Version,
+				Path:        strings.TrimPrefix(path, s.i.BasePath),
+				Class:       hub.ErrorClassLogo,
+				Err:         fmt.Errorf("error preparing package %s version %s logo image: %w", md.Name, md.Version, err),
+			})
 		} else {
 			p.LogoImageID = logoImageID
 		}
@@ -135,7 +151,7 @@ func (s *TrackerSource) prepareLogoImage(md *Metadata) (string, error) {
 // logs it as a warning.
 func (s *TrackerSource) warn(err error) {
 	s.i.Svc.Logger.Warn().Err(err).Send()
-	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err.Error())
+	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err)
 }
 
 // Metadata represents some information about an OLM operator version.
@@ -155,12 +171,12 @@ func (md *Metadata) validate() error {
 	var errs *multierror.Error
 
 	if md.Name == "" {
-		errs = multierror.Append(errs, errors.New("name not provided"))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "name not provided"))
 	}
 	if md.Version == "" {
-		errs = multierror.Append(errs, errors.New("version not provided"))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "version not provided"))
 	} else if _, err := semver.StrictNewVersion(md.Version); err != nil {
-		errs = multierror.Append(errs, fmt.Errorf("invalid version (semver expected): %w", err))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrInvalidValue, "invalid version (semver expected): %w", err))
 	}
 
 	return errs.ErrorOrNil()
@@ -346,7 +362,7 @@ func getContainersImages(
 	if v, ok := csv.Annotations[imagesWhitelistAnnotation]; ok {
 		var imagesWhitelist []string
 		if err := yaml.Unmarshal([]byte(v), &imagesWhitelist); err != nil {
-			return nil, fmt.Errorf("invalid imagesWhitelist value: %s", v)
+			return nil, pkg.NewValidationError(pkg.ErrInvalidValue, "invalid imagesWhitelist value: %s", v)
 		}
 		for _, image := range images {
 			if contains(imagesWhitelist, image.Image) {
@@ -465,7 +481,7 @@ func PreparePackage(r *hub.Repository, md *Metadata) (*hub.Package, error) {
 	if v, ok := md.CSV.Annotations[prereleaseAnnotation]; ok {
 		prerelease, err := strconv.ParseBool(v)
 		if err != nil {
-			return nil, fmt.Errorf("invalid prerelease value: %s", v)
+			return nil, pkg.NewValidationError(pkg.ErrInvalidValue, "invalid prerelease value: %s", v)
 		}
 		p.Prerelease = prerelease
 	}
@@ -474,7 +490,7 @@ func PreparePackage(r *hub.Repository, md *Metadata) (*hub.Package, error) {
 	if v, ok := md.CSV.Annotations[recommendationsAnnotation]; ok {
 		var recommendations []*hub.Recommendation
 		if err := yaml.Unmarshal([]byte(v), &recommendations); err != nil {
-			return nil, fmt.Errorf("invalid recommendations value: %s", v)
+			return nil, pkg.NewValidationError(pkg.ErrInvalidValue, "invalid recommendations value: %s", v)
 		}
 		p.Recommendations = recommendations
 	}
@@ -483,7 +499,7 @@ func PreparePackage(r *hub.Repository, md *Metadata) (*hub.Package, error) {
 	if v, ok := md.CSV.Annotations[screenshotsAnnotation]; ok {
 		var screenshots []*hub.Screenshot
 		if err := yaml.Unmarshal([]byte(v), &screenshots); err != nil {
-			return nil, fmt.Errorf("invalid screenshots value: %s", v)
+			return nil, pkg.NewValidationError(pkg.ErrInvalidValue, "invalid screenshots value: %s", v)
 		}
 		p.Screenshots = screenshots
 	}
@@ -492,7 +508,7 @@ func PreparePackage(r *hub.Repository, md *Metadata) (*hub.Package, error) {
 	if v, ok := md.CSV.Annotations[securityUpdatesAnnotation]; ok {
 		containsSecurityUpdates, err := strconv.ParseBool(v)
 		if err != nil {
-			return nil, fmt.Errorf("invalid containsSecurityUpdates value: %s", v)
+			return nil, pkg.NewValidationError(pkg.ErrInvalidValue, "invalid containsSecurityUpdates value: %s", v)
 		}
 		p.ContainsSecurityUpdates = containsSecurityUpdates
 	}
@@ -509,6 +525,27 @@ func PreparePackage(r *hub.Repository, md *Metadata) (*hub.Package, error) {
 		isGlobalOperatorKey: isGlobalOperator,
 	}
 
+	// Required CRDs and APIs (used to link the package to the ones providing
+	// them)
+	requiredAPIs := make([]map[string]string, 0)
+	for _, crd := range md.CSV.Spec.CustomResourceDefinitions.Required {
+		requiredAPIs = append(requiredAPIs, map[string]string{
+			"name":    crd.Name,
+			"version": crd.Version,
+			"kind":    crd.Kind,
+		})
+	}
+	for _, api := range md.CSV.Spec.APIServiceDefinitions.Required {
+		requiredAPIs = append(requiredAPIs, map[string]string{
+			"name":    api.Name + "." + api.Group,
+			"version": api.Version,
+			"kind":    api.Kind,
+		})
+	}
+	if len(requiredAPIs) > 0 {
+		p.Data[requiredAPIsKey] = requiredAPIs
+	}
+
 	return p, nil
 }
 
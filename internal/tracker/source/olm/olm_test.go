@@ -245,6 +245,22 @@ func TestTrackerSource(t *testing.T) {
 		}
 		p2 := source.ClonePackage(p1)
 		p2.Version = "0.2.0"
+		p2.Data = map[string]interface{}{
+			formatKey:           "bundle",
+			isGlobalOperatorKey: true,
+			requiredAPIsKey: []map[string]string{
+				{
+					"name":    "etcdclusters.etcd.database.coreos.com",
+					"version": "v1beta2",
+					"kind":    "EtcdCluster",
+				},
+				{
+					"name":    "pods.metrics.k8s.io",
+					"version": "v1beta1",
+					"kind":    "PodMetrics",
+				},
+			},
+		}
 		packages, err := NewTrackerSource(i).GetPackagesAvailable()
 		assert.Equal(t, map[string]*hub.Package{
 			pkg.BuildKey(p1): p1,
@@ -80,7 +80,7 @@ const (
 
 var (
 	// errInvalidAnnotation indicates that the annotation provided is not valid.
-	errInvalidAnnotation = errors.New("invalid annotation")
+	errInvalidAnnotation = pkg.NewValidationError(pkg.ErrInvalidValue, "invalid annotation")
 )
 
 // TrackerSource is a hub.TrackerSource implementation for Tekton repositories.
@@ -143,7 +143,12 @@ func (s *TrackerSource) processDirBasedCatalog() (map[string]*hub.Package, error
 		pkgBasePath := path.Join(s.i.BasePath, pkgName)
 		versions, err := os.ReadDir(pkgBasePath)
 		if err != nil {
-			s.warn(fmt.Errorf("error reading package %s versions: %w", pkgName, err))
+			s.warn(&hub.RepositoryError{
+				PackageName: pkgName,
+				Path:        strings.TrimPrefix(pkgBasePath, s.i.BasePath),
+				Class:       hub.ErrorClassPackage,
+				Err:         fmt.Errorf("error reading package %s versions: %w", pkgName, err),
+			})
 			continue
 		}
 		for _, v := range versions {
@@ -160,7 +165,13 @@ func (s *TrackerSource) processDirBasedCatalog() (map[string]*hub.Package, error
 			pkgPath := path.Join(pkgBasePath, v.Name())
 			manifest, manifestRaw, err := GetManifest(s.i.Repository.Kind, pkgName, pkgPath)
 			if err != nil {
-				s.warn(fmt.Errorf("error getting package manifest (path: %s): %w", pkgPath, err))
+				s.warn(&hub.RepositoryError{
+					PackageName: pkgName,
+					Version:     sv.String(),
+					Path:        strings.TrimPrefix(pkgPath, s.i.BasePath),
+					Class:       hub.ErrorClassMetadata,
+					Err:         fmt.Errorf("error getting package manifest (path: %s): %w", pkgPath, err),
+				})
 				continue
 			}
 
@@ -168,6 +179,7 @@ func (s *TrackerSource) processDirBasedCatalog() (map[string]*hub.Package, error
 			p, err := PreparePackage(&PreparePackageInput{
 				R:           s.i.Repository,
 				Tag:         "",
+				Commit:      s.i.RepositoryDigest,
 				Manifest:    manifest,
 				ManifestRaw: manifestRaw,
 				BasePath:    s.i.BasePath,
@@ -176,7 +188,13 @@ func (s *TrackerSource) processDirBasedCatalog() (map[string]*hub.Package, error
 				PkgVersion:  sv.String(),
 			})
 			if err != nil {
-				s.warn(fmt.Errorf("error preparing package %s version %s: %w", pkgName, v.Name(), err))
+				s.warn(&hub.RepositoryError{
+					PackageName: pkgName,
+					Version:     sv.String(),
+					Path:        strings.TrimPrefix(pkgPath, s.i.BasePath),
+					Class:       hub.ErrorClassPackage,
+					Err:         fmt.Errorf("error preparing package %s version %s: %w", pkgName, v.Name(), err),
+				})
 				continue
 			}
 			packagesAvailable[pkg.BuildKey(p)] = p
@@ -218,14 +236,22 @@ func (s *TrackerSource) processGitBasedCatalog() (map[string]*hub.Package, error
 		if err := wt.Checkout(&git.CheckoutOptions{
 			Hash: tag.Hash(),
 		}); err != nil {
-			s.warn(fmt.Errorf("error checking out tag %s: %w", tag.Name().Short(), err))
+			s.warn(&hub.RepositoryError{
+				Version: tag.Name().Short(),
+				Class:   hub.ErrorClassRepository,
+				Err:     fmt.Errorf("error checking out tag %s: %w", tag.Name().Short(), err),
+			})
 			return nil
 		}
 
 		// Process version packages
 		packages, err := os.ReadDir(s.i.BasePath)
 		if err != nil {
-			s.warn(fmt.Errorf("error reading catalog directory: %w", err))
+			s.warn(&hub.RepositoryError{
+				Version: tag.Name().Short(),
+				Class:   hub.ErrorClassRepository,
+				Err:     fmt.Errorf("error reading catalog directory: %w", err),
+			})
 			return nil
 		}
 		for _, p := range packages {
@@ -239,7 +265,13 @@ func (s *TrackerSource) processGitBasedCatalog() (map[string]*hub.Package, error
 			pkgPath := path.Join(s.i.BasePath, pkgName)
 			manifest, manifestRaw, err := GetManifest(s.i.Repository.Kind, pkgName, pkgPath)
 			if err != nil {
-				s.warn(fmt.Errorf("error getting package manifest (path: %s): %w", pkgPath, err))
+				s.warn(&hub.RepositoryError{
+					PackageName: pkgName,
+					Version:     sv.String(),
+					Path:        strings.TrimPrefix(pkgPath, s.i.BasePath),
+					Class:       hub.ErrorClassMetadata,
+					Err:         fmt.Errorf("error getting package manifest (path: %s): %w", pkgPath, err),
+				})
 				continue
 			}
 
@@ -255,7 +287,13 @@ func (s *TrackerSource) processGitBasedCatalog() (map[string]*hub.Package, error
 				PkgVersion:  sv.String(),
 			})
 			if err != nil {
-				s.warn(fmt.Errorf("error preparing package %s version %s: %w", pkgName, sv.String(), err))
+				s.warn(&hub.RepositoryError{
+					PackageName: pkgName,
+					Version:     sv.String(),
+					Path:        strings.TrimPrefix(pkgPath, s.i.BasePath),
+					Class:       hub.ErrorClassPackage,
+					Err:         fmt.Errorf("error preparing package %s version %s: %w", pkgName, sv.String(), err),
+				})
 				continue
 			}
 			packagesAvailable[pkg.BuildKey(p)] = p
@@ -271,7 +309,7 @@ func (s *TrackerSource) processGitBasedCatalog() (map[string]*hub.Package, error
 // logs it as a warning.
 func (s *TrackerSource) warn(err error) {
 	s.i.Svc.Logger.Warn().Err(err).Send()
-	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err.Error())
+	s.i.Svc.Ec.Append(s.i.Repository.RepositoryID, err)
 }
 
 // GetManifest reads, parses and validates the package manifest, which can be a
@@ -317,15 +355,15 @@ func validateManifest(manifest interface{}) error {
 
 	// Validate manifest data
 	if name == "" {
-		errs = multierror.Append(errs, errors.New("name not provided"))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "name not provided"))
 	}
 	if version == "" {
-		errs = multierror.Append(errs, errors.New("version not provided"))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "version not provided"))
 	} else if _, err := semver.NewVersion(version); err != nil {
-		errs = multierror.Append(errs, fmt.Errorf("invalid version (semver expected): %w", err))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrInvalidValue, "invalid version (semver expected): %w", err))
 	}
 	if description == "" {
-		errs = multierror.Append(errs, errors.New("description not provided"))
+		errs = multierror.Append(errs, pkg.NewValidationError(pkg.ErrFieldNotProvided, "description not provided"))
 	}
 
 	return errs.ErrorOrNil()
@@ -336,6 +374,7 @@ func validateManifest(manifest interface{}) error {
 type PreparePackageInput struct {
 	R           *hub.Repository
 	Tag         string
+	Commit      string
 	Manifest    interface{}
 	ManifestRaw []byte
 	BasePath    string
@@ -504,7 +543,7 @@ func prepareContentAndSourceLinks(i *PreparePackageInput) (string, string) {
 	var contentURL, sourceURL string
 	branch := i.Tag
 	if branch == "" {
-		branch = repo.GetBranch(i.R)
+		branch = repo.GetSourceRef(i.R, i.Commit)
 	}
 	pkgRelativePath := strings.TrimPrefix(i.PkgPath, i.BasePath)
 	switch host {
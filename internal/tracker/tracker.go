@@ -45,6 +45,8 @@ func (t *Tracker) Run() error {
 	// Initialize logs for this repository in the errors collector
 	t.logger.Debug().Msg("tracking repository")
 	t.svc.Ec.Init(t.r.RepositoryID)
+	var stats hub.TrackingRunStats
+	defer t.svc.Ec.SetTrackingRunStats(t.r.RepositoryID, &stats)
 
 	// Clone repository when applicable and get its metadata
 	tmpDir, packagesPath, err := t.cloneRepository()
@@ -57,7 +59,10 @@ func (t *Tracker) Run() error {
 	basePath := filepath.Join(tmpDir, packagesPath)
 	md, err := t.svc.Rm.GetMetadata(t.r, basePath)
 	if err != nil && !errors.Is(err, repo.ErrMetadataNotFound) {
-		t.warn(fmt.Errorf("error getting repository metadata: %w", err))
+		t.warn(&hub.RepositoryError{
+			Class: hub.ErrorClassRepository,
+			Err:   fmt.Errorf("error getting repository metadata: %w", err),
+		})
 	}
 
 	// Load packages already registered from this repository
@@ -101,18 +106,27 @@ func (t *Tracker) Run() error {
 		// Check if this package version is already registered
 		digest, ok := packagesRegistered[pkg.BuildKey(p)]
 		if ok && (p.Digest == digest || p.Digest == hub.HasNotChanged) && !bypassDigestCheck {
+			stats.PackagesSkipped++
 			continue
 		}
 
 		// Check if this package should be ignored
-		if shouldIgnorePackage(md, p.Name, p.Version) {
+		if ShouldIgnorePackage(md, p.Name, p.Version) {
+			stats.PackagesSkipped++
 			continue
 		}
 
 		// Register package
 		t.logger.Debug().Str("name", p.Name).Str("v", p.Version).Msg("registering package")
 		if err := t.svc.Pm.Register(t.svc.Ctx, p); err != nil {
-			t.warn(fmt.Errorf("error registering package %s version %s: %w", p.Name, p.Version, err))
+			t.warn(&hub.RepositoryError{
+				PackageName: p.Name,
+				Version:     p.Version,
+				Class:       hub.ErrorClassRegistration,
+				Err:         fmt.Errorf("error registering package %s version %s: %w", p.Name, p.Version, err),
+			})
+		} else {
+			stats.PackagesRegistered++
 		}
 	}
 
@@ -129,7 +143,7 @@ func (t *Tracker) Run() error {
 			// Unregister pkg if it's not available anymore or if it's ignored
 			name, version := pkg.ParseKey(key)
 			_, ok := packagesAvailable[key]
-			if !ok || shouldIgnorePackage(md, name, version) {
+			if !ok || ShouldIgnorePackage(md, name, version) {
 				t.logger.Debug().Str("name", name).Str("v", version).Msg("unregistering package")
 				p := &hub.Package{
 					Name:       name,
@@ -137,7 +151,14 @@ func (t *Tracker) Run() error {
 					Repository: t.r,
 				}
 				if err := t.svc.Pm.Unregister(t.svc.Ctx, p); err != nil {
-					t.warn(fmt.Errorf("error unregistering package %s version %s: %w", name, version, err))
+					t.warn(&hub.RepositoryError{
+						PackageName: name,
+						Version:     version,
+						Class:       hub.ErrorClassRegistration,
+						Err:         fmt.Errorf("error unregistering package %s version %s: %w", name, version, err),
+					})
+				} else {
+					stats.PackagesUnregistered++
 				}
 			}
 		}
@@ -145,7 +166,10 @@ func (t *Tracker) Run() error {
 
 	// Set verified publisher flag if needed
 	if err := setVerifiedPublisherFlag(t.svc.Ctx, t.svc.Rm, t.r, md); err != nil {
-		t.warn(fmt.Errorf("error setting verified publisher flag: %w", err))
+		t.warn(&hub.RepositoryError{
+			Class: hub.ErrorClassRepository,
+			Err:   fmt.Errorf("error setting verified publisher flag: %w", err),
+		})
 	}
 
 	// Update repository digest if needed
@@ -164,6 +188,11 @@ func (t *Tracker) cloneRepository() (string, string, error) {
 	var tmpDir, packagesPath string
 	var err error
 
+	// Repositories mirroring a remote Artifact Hub instance are not cloned
+	if repo.IsFederated(t.r) {
+		return tmpDir, packagesPath, err
+	}
+
 	switch t.r.Kind {
 	case
 		hub.Container,
@@ -176,15 +205,19 @@ func (t *Tracker) cloneRepository() (string, string, error) {
 			tmpDir, packagesPath, err = t.svc.Rc.CloneRepository(t.svc.Ctx, t.r)
 		}
 	case
-		hub.CoreDNS,
 		hub.Falco,
 		hub.Gatekeeper,
+		hub.Kubewarden,
+		hub.OPA:
+		if !strings.HasPrefix(t.r.URL, hub.RepositoryOCIPrefix) {
+			tmpDir, packagesPath, err = t.svc.Rc.CloneRepository(t.svc.Ctx, t.r)
+		}
+	case
+		hub.CoreDNS,
 		hub.HelmPlugin,
 		hub.KedaScaler,
 		hub.Keptn,
 		hub.Krew,
-		hub.Kubewarden,
-		hub.OPA,
 		hub.TBAction,
 		hub.TektonPipeline,
 		hub.TektonTask:
@@ -198,5 +231,5 @@ func (t *Tracker) cloneRepository() (string, string, error) {
 // logs it as a warning.
 func (t *Tracker) warn(err error) {
 	t.logger.Warn().Err(err).Send()
-	t.svc.Ec.Append(t.r.RepositoryID, err.Error())
+	t.svc.Ec.Append(t.r.RepositoryID, err)
 }
@@ -83,6 +83,7 @@ func TestTracker(t *testing.T) {
 		sw.svc.Cfg.Set("tracker.bypassDigestCheck", true)
 		sw.rm.On("GetRemoteDigest", sw.svc.Ctx, r).Return(r.Digest, nil)
 		sw.ec.On("Init", r.RepositoryID)
+		sw.ec.On("SetTrackingRunStats", r.RepositoryID, &hub.TrackingRunStats{})
 		sw.rm.On("GetMetadata", r, "").Return(nil, nil)
 		sw.rm.On("GetPackagesDigest", sw.svc.Ctx, r.RepositoryID).Return(nil, tests.ErrFake)
 
@@ -118,6 +119,7 @@ func TestTracker(t *testing.T) {
 				sw := newServicesWrapper()
 				sw.rm.On("GetRemoteDigest", sw.svc.Ctx, r).Return("", nil)
 				sw.ec.On("Init", r.RepositoryID)
+				sw.ec.On("SetTrackingRunStats", r.RepositoryID, &hub.TrackingRunStats{})
 				switch r.Kind {
 				case hub.OLM:
 					if strings.HasPrefix(r.URL, hub.RepositoryOCIPrefix) {
@@ -144,6 +146,7 @@ func TestTracker(t *testing.T) {
 		sw := newServicesWrapper()
 		sw.rm.On("GetRemoteDigest", sw.svc.Ctx, r1).Return("", nil)
 		sw.ec.On("Init", r1.RepositoryID)
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{})
 		sw.rm.On("GetMetadata", r1, "").Return(nil, nil)
 		sw.rm.On("GetPackagesDigest", sw.svc.Ctx, r1.RepositoryID).Return(nil, tests.ErrFake)
 
@@ -160,6 +163,7 @@ func TestTracker(t *testing.T) {
 		sw := newServicesWrapper()
 		sw.rm.On("GetRemoteDigest", sw.svc.Ctx, r1).Return("", nil)
 		sw.ec.On("Init", r1.RepositoryID)
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{})
 		sw.rm.On("GetMetadata", r1, "").Return(nil, nil)
 		sw.rm.On("GetPackagesDigest", sw.svc.Ctx, r1.RepositoryID).Return(nil, nil)
 		sw.src.On("GetPackagesAvailable").Return(nil, tests.ErrFake)
@@ -180,6 +184,7 @@ func TestTracker(t *testing.T) {
 		sw.rm.On("GetMetadata", r1, "").Return(nil, nil)
 		sw.rm.On("GetPackagesDigest", sw.svc.Ctx, r1.RepositoryID).Return(nil, nil)
 		sw.src.On("GetPackagesAvailable").Return(map[string]*hub.Package{}, nil)
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{})
 
 		// Run test and check expectations
 		err := New(sw.svc, r1, zerolog.Nop()).Run()
@@ -202,6 +207,7 @@ func TestTracker(t *testing.T) {
 		sw.pm.On("Register", sw.svc.Ctx, p1v1).Return(tests.ErrFake)
 		expectedErr := "error registering package pkg1 version 1.0.0: fake error for tests"
 		sw.ec.On("Append", r1.RepositoryID, expectedErr).Return()
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{})
 
 		// Run test and check expectations
 		err := New(sw.svc, r1, zerolog.Nop()).Run()
@@ -223,6 +229,7 @@ func TestTracker(t *testing.T) {
 		sw.src.On("GetPackagesAvailable").Return(map[string]*hub.Package{
 			pkg.BuildKey(p1v1): p1v1,
 		}, nil)
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{PackagesSkipped: 1})
 
 		// Run test and check expectations
 		err := New(sw.svc, r1, zerolog.Nop()).Run()
@@ -246,6 +253,7 @@ func TestTracker(t *testing.T) {
 		sw.src.On("GetPackagesAvailable").Return(map[string]*hub.Package{
 			pkg.BuildKey(p1v1): p,
 		}, nil)
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{PackagesSkipped: 1})
 
 		// Run test and check expectations
 		err := New(sw.svc, r1, zerolog.Nop()).Run()
@@ -271,6 +279,7 @@ func TestTracker(t *testing.T) {
 		sw.src.On("GetPackagesAvailable").Return(map[string]*hub.Package{
 			pkg.BuildKey(p1v1): p1v1,
 		}, nil)
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{PackagesSkipped: 1})
 
 		// Run test and check expectations
 		err := New(sw.svc, r1, zerolog.Nop()).Run()
@@ -291,6 +300,7 @@ func TestTracker(t *testing.T) {
 			pkg.BuildKey(p1v1): p1v1,
 		}, nil)
 		sw.pm.On("Register", sw.svc.Ctx, p1v1).Return(nil)
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{PackagesRegistered: 1})
 
 		// Run test and check expectations
 		err := New(sw.svc, r1, zerolog.Nop()).Run()
@@ -315,6 +325,7 @@ func TestTracker(t *testing.T) {
 			pkg.BuildKey(p1v1): p1v1,
 		}, nil)
 		sw.pm.On("Register", sw.svc.Ctx, p1v1).Return(nil)
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{PackagesRegistered: 1})
 
 		// Run test and check expectations
 		err := New(sw.svc, r1, zerolog.Nop()).Run()
@@ -337,6 +348,7 @@ func TestTracker(t *testing.T) {
 		}, nil)
 		sw.pm.On("Register", sw.svc.Ctx, p1v1).Return(nil)
 		sw.pm.On("Register", sw.svc.Ctx, p2v1).Return(nil)
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{PackagesRegistered: 2})
 
 		// Run test and check expectations
 		err := New(sw.svc, r1, zerolog.Nop()).Run()
@@ -362,6 +374,7 @@ func TestTracker(t *testing.T) {
 		sw.pm.On("Unregister", sw.svc.Ctx, p1v1).Return(tests.ErrFake)
 		expectedErr := "error unregistering package pkg1 version 1.0.0: fake error for tests"
 		sw.ec.On("Append", r1.RepositoryID, expectedErr).Return()
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{PackagesSkipped: 1})
 
 		// Run test and check expectations
 		err := New(sw.svc, r1, zerolog.Nop()).Run()
@@ -382,6 +395,7 @@ func TestTracker(t *testing.T) {
 			pkg.BuildKey(p1v2): "",
 		}, nil)
 		sw.src.On("GetPackagesAvailable").Return(nil, nil)
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{})
 
 		// Run test and check expectations
 		err := New(sw.svc, r1, zerolog.Nop()).Run()
@@ -405,6 +419,7 @@ func TestTracker(t *testing.T) {
 			pkg.BuildKey(p1v2): p1v2,
 		}, nil)
 		sw.pm.On("Unregister", sw.svc.Ctx, p1v1).Return(nil)
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{PackagesSkipped: 1, PackagesUnregistered: 1})
 
 		// Run test and check expectations
 		err := New(sw.svc, r1, zerolog.Nop()).Run()
@@ -436,6 +451,7 @@ func TestTracker(t *testing.T) {
 			pkg.BuildKey(p1v2): p1v2,
 		}, nil)
 		sw.pm.On("Unregister", sw.svc.Ctx, p1v1).Return(nil)
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{PackagesSkipped: 2, PackagesUnregistered: 1})
 
 		// Run test and check expectations
 		err := New(sw.svc, r1, zerolog.Nop()).Run()
@@ -458,6 +474,7 @@ func TestTracker(t *testing.T) {
 		sw.rm.On("SetVerifiedPublisher", sw.svc.Ctx, r1.RepositoryID, true).Return(tests.ErrFake)
 		expectedErr := "error setting verified publisher flag: error setting verified publisher flag: fake error for tests"
 		sw.ec.On("Append", r1.RepositoryID, expectedErr).Return()
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{})
 
 		// Run test and check expectations
 		err := New(sw.svc, r1, zerolog.Nop()).Run()
@@ -476,6 +493,7 @@ func TestTracker(t *testing.T) {
 		sw.rm.On("GetPackagesDigest", sw.svc.Ctx, r1.RepositoryID).Return(nil, nil)
 		sw.src.On("GetPackagesAvailable").Return(map[string]*hub.Package{}, nil)
 		sw.rm.On("UpdateDigest", sw.svc.Ctx, r1.RepositoryID, "digest").Return(tests.ErrFake)
+		sw.ec.On("SetTrackingRunStats", r1.RepositoryID, &hub.TrackingRunStats{})
 
 		// Run test and check expectations
 		err := New(sw.svc, r1, zerolog.Nop()).Run()
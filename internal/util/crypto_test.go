@@ -0,0 +1,44 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+
+	t.Run("encryption key not provided", func(t *testing.T) {
+		t.Parallel()
+		_, err := Encrypt("", []byte("data"))
+		assert.Error(t, err)
+		_, err = Decrypt("", "data")
+		assert.Error(t, err)
+	})
+
+	t.Run("data encrypted and decrypted successfully", func(t *testing.T) {
+		t.Parallel()
+		ciphertext, err := Encrypt("key", []byte("data"))
+		require.NoError(t, err)
+		assert.NotContains(t, ciphertext, "data")
+		data, err := Decrypt("key", ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data"), data)
+	})
+
+	t.Run("decrypting using a different key fails", func(t *testing.T) {
+		t.Parallel()
+		ciphertext, err := Encrypt("key", []byte("data"))
+		require.NoError(t, err)
+		_, err = Decrypt("key2", ciphertext)
+		assert.Equal(t, ErrInvalidCiphertext, err)
+	})
+
+	t.Run("invalid ciphertext", func(t *testing.T) {
+		t.Parallel()
+		_, err := Decrypt("key", "invalid")
+		assert.Equal(t, ErrInvalidCiphertext, err)
+	})
+}
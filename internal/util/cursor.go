@@ -0,0 +1,76 @@
+package util
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+)
+
+// EncodeCursor encodes the pagination cursor key provided (as returned by the
+// database) into an opaque value that can be handed to API consumers.
+func EncodeCursor(key []byte) string {
+	if len(key) == 0 {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(key)
+}
+
+// DecodeCursor decodes the opaque pagination cursor provided into the value
+// provided (a pointer to a struct describing the fields expected in the key),
+// returning the key that should be passed to the database. All fields are
+// required unless they are tagged with omitempty, and their values must match
+// the types of the struct fields, so that tampered or stale cursors are
+// rejected before reaching the database. A nil key is returned when the cursor
+// provided is empty.
+func DecodeCursor(cursor string, v interface{}) ([]byte, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	errInvalidCursor := fmt.Errorf("%w: %s", hub.ErrInvalidInput, "invalid cursor")
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	dec := json.NewDecoder(bytes.NewReader(key))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil || dec.More() {
+		return nil, errInvalidCursor
+	}
+	if !hasRequiredFields(key, reflect.TypeOf(v).Elem()) {
+		return nil, errInvalidCursor
+	}
+	return key, nil
+}
+
+// hasRequiredFields checks if the json object provided contains all the fields
+// required by the struct type provided, including the ones of nested structs.
+func hasRequiredFields(data []byte, t reflect.Type) bool {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil || obj == nil {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		value, ok := obj[name]
+		if !ok || string(value) == "null" {
+			if strings.Contains(opts, "omitempty") {
+				continue
+			}
+			return false
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && !hasRequiredFields(value, fieldType) {
+			return false
+		}
+	}
+	return true
+}
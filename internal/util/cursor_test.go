@@ -0,0 +1,83 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/satori/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCursor struct {
+	Name      string          `json:"name"`
+	PackageID uuid.UUID       `json:"package_id"`
+	TxID      int64           `json:"tx_id"`
+	Since     *testCursorNext `json:"since"`
+	After     *testCursorNext `json:"after,omitempty"`
+}
+
+type testCursorNext struct {
+	ChangeID int64  `json:"change_id"`
+	Version  string `json:"version,omitempty"`
+}
+
+func TestEncodeDecodeCursor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty cursor", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, "", EncodeCursor(nil))
+		key, err := DecodeCursor("", &testCursor{})
+		require.NoError(t, err)
+		assert.Nil(t, key)
+	})
+
+	t.Run("cursor encoded and decoded successfully", func(t *testing.T) {
+		t.Parallel()
+		data := []byte(`{
+			"name": "pkg1",
+			"package_id": "00000000-0000-0000-0000-000000000001",
+			"tx_id": 10,
+			"since": {"change_id": 1}
+		}`)
+		cursor := EncodeCursor(data)
+		assert.NotContains(t, cursor, "pkg1")
+		var c testCursor
+		key, err := DecodeCursor(cursor, &c)
+		require.NoError(t, err)
+		assert.Equal(t, data, key)
+		assert.Equal(t, testCursor{
+			Name:      "pkg1",
+			PackageID: uuid.FromStringOrNil("00000000-0000-0000-0000-000000000001"),
+			TxID:      10,
+			Since:     &testCursorNext{ChangeID: 1},
+		}, c)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		t.Parallel()
+		testCases := []string{
+			"invalid!",
+			EncodeCursor([]byte("invalid")),
+			EncodeCursor([]byte(`["pkg1"]`)),
+			EncodeCursor([]byte("null")),
+			EncodeCursor([]byte(`{"name": "pkg1"}`)),
+			EncodeCursor([]byte(`{"name": "pkg1", "package_id": "invalid", "tx_id": 1, "since": {"change_id": 1}}`)),
+			EncodeCursor([]byte(`{"name": "pkg1", "package_id": "00000000-0000-0000-0000-000000000001", "tx_id": "1", "since": {"change_id": 1}}`)),
+			EncodeCursor([]byte(`{"name": "pkg1", "package_id": "00000000-0000-0000-0000-000000000001", "tx_id": 1.5, "since": {"change_id": 1}}`)),
+			EncodeCursor([]byte(`{"name": null, "package_id": "00000000-0000-0000-0000-000000000001", "tx_id": 1, "since": {"change_id": 1}}`)),
+			EncodeCursor([]byte(`{"name": "pkg1", "package_id": "00000000-0000-0000-0000-000000000001", "tx_id": 1, "since": {}}`)),
+			EncodeCursor([]byte(`{"name": "pkg1", "package_id": "00000000-0000-0000-0000-000000000001", "tx_id": 1, "since": {"change_id": 1}, "after": {}}`)),
+			EncodeCursor([]byte(`{"name": "pkg1", "package_id": "00000000-0000-0000-0000-000000000001", "tx_id": 1, "since": {"change_id": 1}, "other": 1}`)),
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc, func(t *testing.T) {
+				t.Parallel()
+				_, err := DecodeCursor(tc, &testCursor{})
+				assert.ErrorIs(t, err, hub.ErrInvalidInput)
+			})
+		}
+	})
+}
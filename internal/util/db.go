@@ -19,6 +19,10 @@ const (
 	// DBLockKeyUpdatePackagesViews represents the lock key used when updating
 	// the packages views counters in the database.
 	DBLockKeyUpdatePackagesViews = 1
+
+	// DBLockKeyPruneChanges represents the lock key used when pruning the
+	// changes older than the retention period in the database.
+	DBLockKeyPruneChanges = 2
 )
 
 var (
@@ -121,6 +125,34 @@ func DBQueryJSONWithPagination(
 	}, nil
 }
 
+// DBQueryJSONWithCursor is a helper that executes the query provided and
+// returns a JSONQueryResult instance containing the json data returned from
+// the database, as well as the cursor to fetch the next page of results when
+// there are more available.
+func DBQueryJSONWithCursor(
+	ctx context.Context,
+	db hub.DB,
+	query string,
+	args ...interface{},
+) (*hub.JSONQueryResult, error) {
+	var dataJSON, nextCursorKey []byte
+	var totalCount int
+	if err := db.QueryRow(ctx, query, args...).Scan(&dataJSON, &totalCount, &nextCursorKey); err != nil {
+		if err.Error() == ErrDBInsufficientPrivilege.Error() {
+			return nil, hub.ErrInsufficientPrivilege
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, hub.ErrNotFound
+		}
+		return nil, err
+	}
+	return &hub.JSONQueryResult{
+		Data:       dataJSON,
+		TotalCount: totalCount,
+		NextCursor: EncodeCursor(nextCursorKey),
+	}, nil
+}
+
 // DBQueryUnmarshal is a helper that executes the query provided and unmarshals
 // the json data returned from the database into the value (v) provided.
 func DBQueryUnmarshal(ctx context.Context, db hub.DB, v interface{}, query string, args ...interface{}) error {
@@ -17,12 +17,18 @@ const (
 	addWebhookDBQ                 = `select add_webhook($1::uuid, $2::text, $3::jsonb)`
 	deleteWebhookDBQ              = `select delete_webhook($1::uuid, $2::uuid)`
 	getWebhooksSubscribedToPkgDBQ = `select get_webhooks_subscribed_to_package($1::int, $2::uuid)`
-	getOrgWebhooksDBQ             = `select * from get_org_webhooks($1::uuid, $2::text, $3::int, $4::int)`
-	getUserWebhooksDBQ            = `select * from get_user_webhooks($1::uuid, $2::int, $3::int)`
+	getOrgWebhooksDBQ             = `select * from get_org_webhooks($1::uuid, $2::text, $3::int, $4::int, $5::jsonb)`
+	getUserWebhooksDBQ            = `select * from get_user_webhooks($1::uuid, $2::int, $3::int, $4::jsonb)`
 	getWebhookDBQ                 = `select get_webhook($1::uuid, $2::uuid)`
 	updateWebhookDBQ              = `select update_webhook($1::uuid, $2::jsonb)`
 )
 
+// webhooksCursor represents the key of the cursor used to paginate webhooks.
+type webhooksCursor struct {
+	Name      string    `json:"name"`
+	WebhookID uuid.UUID `json:"webhook_id"`
+}
+
 // Manager provides an API to manage webhooks.
 type Manager struct {
 	db hub.DB
@@ -124,9 +130,13 @@ func (m *Manager) GetOwnedByOrgJSON(
 	if orgName == "" {
 		return nil, fmt.Errorf("%w: %s", hub.ErrInvalidInput, "organization name not provided")
 	}
+	cursorKey, err := util.DecodeCursor(p.Cursor, &webhooksCursor{})
+	if err != nil {
+		return nil, err
+	}
 
 	// Get webhooks from database
-	return util.DBQueryJSONWithPagination(ctx, m.db, getOrgWebhooksDBQ, userID, orgName, p.Limit, p.Offset)
+	return util.DBQueryJSONWithCursor(ctx, m.db, getOrgWebhooksDBQ, userID, orgName, p.Limit, p.Offset, cursorKey)
 }
 
 // GetOwnedByUserJSON returns the webhooks belonging to the requesting user as
@@ -134,8 +144,14 @@ func (m *Manager) GetOwnedByOrgJSON(
 func (m *Manager) GetOwnedByUserJSON(ctx context.Context, p *hub.Pagination) (*hub.JSONQueryResult, error) {
 	userID := ctx.Value(hub.UserIDKey).(string)
 
+	// Validate input
+	cursorKey, err := util.DecodeCursor(p.Cursor, &webhooksCursor{})
+	if err != nil {
+		return nil, err
+	}
+
 	// Get webhooks from database
-	return util.DBQueryJSONWithPagination(ctx, m.db, getUserWebhooksDBQ, userID, p.Limit, p.Offset)
+	return util.DBQueryJSONWithCursor(ctx, m.db, getUserWebhooksDBQ, userID, p.Limit, p.Offset, cursorKey)
 }
 
 // GetSubscribedTo returns the webhooks subscribed to the event provided.
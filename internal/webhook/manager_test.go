@@ -299,7 +299,7 @@ func TestGetOwnedByOrgJSON(t *testing.T) {
 	t.Run("database error", func(t *testing.T) {
 		t.Parallel()
 		db := &tests.DBMock{}
-		db.On("QueryRow", ctx, getOrgWebhooksDBQ, "userID", "orgName", 10, 1).Return(nil, tests.ErrFakeDB)
+		db.On("QueryRow", ctx, getOrgWebhooksDBQ, "userID", "orgName", 10, 1, []byte(nil)).Return(nil, tests.ErrFakeDB)
 		m := NewManager(db)
 
 		result, err := m.GetOwnedByOrgJSON(ctx, "orgName", p)
@@ -311,7 +311,7 @@ func TestGetOwnedByOrgJSON(t *testing.T) {
 	t.Run("org webhooks data returned successfully", func(t *testing.T) {
 		t.Parallel()
 		db := &tests.DBMock{}
-		db.On("QueryRow", ctx, getOrgWebhooksDBQ, "userID", "orgName", 10, 1).
+		db.On("QueryRow", ctx, getOrgWebhooksDBQ, "userID", "orgName", 10, 1, []byte(nil)).
 			Return([]interface{}{[]byte("dataJSON"), 1}, nil)
 		m := NewManager(db)
 
@@ -335,10 +335,18 @@ func TestGetOwnedByUserJSON(t *testing.T) {
 		})
 	})
 
+	t.Run("invalid cursor", func(t *testing.T) {
+		t.Parallel()
+		m := NewManager(nil)
+		result, err := m.GetOwnedByUserJSON(ctx, &hub.Pagination{Limit: 10, Cursor: "invalid!"})
+		assert.True(t, errors.Is(err, hub.ErrInvalidInput))
+		assert.Nil(t, result)
+	})
+
 	t.Run("database error", func(t *testing.T) {
 		t.Parallel()
 		db := &tests.DBMock{}
-		db.On("QueryRow", ctx, getUserWebhooksDBQ, "userID", 10, 1).Return(nil, tests.ErrFakeDB)
+		db.On("QueryRow", ctx, getUserWebhooksDBQ, "userID", 10, 1, []byte(nil)).Return(nil, tests.ErrFakeDB)
 		m := NewManager(db)
 
 		result, err := m.GetOwnedByUserJSON(ctx, p)
@@ -350,7 +358,7 @@ func TestGetOwnedByUserJSON(t *testing.T) {
 	t.Run("user webhooks data returned successfully", func(t *testing.T) {
 		t.Parallel()
 		db := &tests.DBMock{}
-		db.On("QueryRow", ctx, getUserWebhooksDBQ, "userID", 10, 1).Return([]interface{}{[]byte("dataJSON"), 1}, nil)
+		db.On("QueryRow", ctx, getUserWebhooksDBQ, "userID", 10, 1, []byte(nil)).Return([]interface{}{[]byte("dataJSON"), 1}, nil)
 		m := NewManager(db)
 
 		result, err := m.GetOwnedByUserJSON(ctx, p)
@@ -359,6 +367,24 @@ func TestGetOwnedByUserJSON(t *testing.T) {
 		assert.Equal(t, 1, result.TotalCount)
 		db.AssertExpectations(t)
 	})
+
+	t.Run("user webhooks data returned successfully using a cursor", func(t *testing.T) {
+		t.Parallel()
+		db := &tests.DBMock{}
+		db.On("QueryRow", ctx, getUserWebhooksDBQ, "userID", 10, 0, []byte(`{"name": "wh1", "webhook_id": "00000000-0000-0000-0000-000000000001"}`)).
+			Return([]interface{}{[]byte("dataJSON"), 3, []byte(`{"name": "wh2"}`)}, nil)
+		m := NewManager(db)
+
+		result, err := m.GetOwnedByUserJSON(ctx, &hub.Pagination{
+			Limit:  10,
+			Cursor: util.EncodeCursor([]byte(`{"name": "wh1", "webhook_id": "00000000-0000-0000-0000-000000000001"}`)),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), result.Data)
+		assert.Equal(t, 3, result.TotalCount)
+		assert.Equal(t, util.EncodeCursor([]byte(`{"name": "wh2"}`)), result.NextCursor)
+		db.AssertExpectations(t)
+	})
 }
 
 func TestGetSubscribedTo(t *testing.T) {
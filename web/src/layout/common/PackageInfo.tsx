@@ -233,6 +233,7 @@ const PackageInfo = (props: Props) => {
           <SignedBadge
             signed={props.package.signed}
             signatures={props.package.signatures}
+            signatureVerification={props.package.signatureVerification}
             repositoryKind={props.package.repository.kind}
             className="d-inline mt-3"
           />
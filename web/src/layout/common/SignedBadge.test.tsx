@@ -1,7 +1,7 @@
 import { render, screen } from '@testing-library/react';
 import userEvent from '@testing-library/user-event';
 
-import { Signature } from '../../types';
+import { Signature, SignatureVerificationStatus } from '../../types';
 import SignedBadge from './SignedBadge';
 
 describe('SignedBadge', () => {
@@ -111,6 +111,46 @@ describe('SignedBadge', () => {
     expect(screen.queryByRole('tooltip')).toBeNull();
   });
 
+  it('renders signer when signature has been verified', async () => {
+    render(
+      <SignedBadge
+        repositoryKind={0}
+        signed
+        signatures={[Signature.Prov]}
+        signatureVerification={{
+          status: SignatureVerificationStatus.Verified,
+          kind: Signature.Prov,
+          signer: 'User 1 <user1@email.com>',
+        }}
+      />
+    );
+
+    const badge = screen.getByTestId('elementWithTooltip');
+    await userEvent.hover(badge);
+
+    expect(await screen.findByRole('tooltip')).toBeInTheDocument();
+    expect(screen.getByText('This chart has a provenance file')).toBeInTheDocument();
+    expect(screen.getByText(/Signature verified/)).toBeInTheDocument();
+    expect(screen.getByText('User 1 <user1@email.com>')).toBeInTheDocument();
+  });
+
+  it('renders message when signature is not valid', async () => {
+    render(
+      <SignedBadge
+        repositoryKind={12}
+        signed
+        signatures={[Signature.Cosign]}
+        signatureVerification={{ status: SignatureVerificationStatus.Invalid, kind: Signature.Cosign }}
+      />
+    );
+
+    const badge = screen.getByTestId('elementWithTooltip');
+    await userEvent.hover(badge);
+
+    expect(await screen.findByRole('tooltip')).toBeInTheDocument();
+    expect(screen.getByText('The signature is not valid')).toBeInTheDocument();
+  });
+
   it('does not render label', () => {
     const { container } = render(<SignedBadge repositoryKind={0} signed={false} />);
     expect(container).toBeEmptyDOMElement();
@@ -4,7 +4,7 @@ import isUndefined from 'lodash/isUndefined';
 import { BsDot } from 'react-icons/bs';
 import { FaAward } from 'react-icons/fa';
 
-import { RepositoryKind, Signature } from '../../types';
+import { RepositoryKind, Signature, SignatureVerification, SignatureVerificationStatus } from '../../types';
 import ElementWithTooltip from './ElementWithTooltip';
 import Label from './Label';
 import styles from './SignedBadge.module.css';
@@ -12,6 +12,7 @@ import styles from './SignedBadge.module.css';
 interface Props {
   signed: null | boolean;
   signatures?: Signature[];
+  signatureVerification?: SignatureVerification;
   className?: string;
   repositoryKind?: RepositoryKind;
 }
@@ -50,6 +51,34 @@ const getMessage = (sign: Signature, repoKind?: RepositoryKind): JSX.Element | n
   }
 };
 
+const getVerificationMessage = (verification?: SignatureVerification): JSX.Element | null => {
+  if (isUndefined(verification)) return null;
+
+  switch (verification.status) {
+    case SignatureVerificationStatus.Verified:
+      return (
+        <span>
+          Signature verified
+          {verification.signer && (
+            <>
+              {' '}
+              by <span className="fw-bold">{verification.signer}</span>
+            </>
+          )}
+        </span>
+      );
+
+    case SignatureVerificationStatus.Invalid:
+      return <span>The signature is not valid</span>;
+
+    case SignatureVerificationStatus.Unverified:
+      return <span>The signature could not be verified</span>;
+
+    default:
+      return null;
+  }
+};
+
 const SIGNED_REPO_KINDS = [RepositoryKind.Helm, RepositoryKind.Container, RepositoryKind.Kubewarden];
 
 const SignedBadge = (props: Props) => {
@@ -81,7 +110,20 @@ const SignedBadge = (props: Props) => {
     return null;
   };
 
-  const message = getTooltipMessage();
+  const getMessageWithVerification = (): JSX.Element | null => {
+    const signaturesMessage = getTooltipMessage();
+    const verificationMessage = getVerificationMessage(props.signatureVerification);
+    if (isNull(verificationMessage)) return signaturesMessage;
+    if (isNull(signaturesMessage)) return verificationMessage;
+    return (
+      <>
+        {signaturesMessage}
+        <div className="mt-2 text-start">{verificationMessage}</div>
+      </>
+    );
+  };
+
+  const message = getMessageWithVerification();
 
   return (
     <ElementWithTooltip
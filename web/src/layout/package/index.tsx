@@ -473,6 +473,7 @@ const PackageView = (props: Props) => {
         repositoryKind={detail!.repository.kind}
         signed={detail!.signed}
         signatures={detail!.signatures}
+        signatureVerification={detail!.signatureVerification}
         className={`d-inline ${extraStyle}`}
       />
       <div className="d-none d-lg-inline">
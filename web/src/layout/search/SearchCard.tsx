@@ -244,6 +244,7 @@ const SearchCard = (props: Props) => {
                 <SignedBadge
                   signed={props.package.signed}
                   signatures={props.package.signatures}
+                  signatureVerification={props.package.signatureVerification}
                   repositoryKind={props.package.repository.kind}
                   className="d-inline mt-3"
                 />
@@ -38,6 +38,12 @@ export enum Signature {
   Cosign = 'cosign',
 }
 
+export enum SignatureVerificationStatus {
+  Verified = 'verified',
+  Unverified = 'unverified',
+  Invalid = 'invalid',
+}
+
 export enum VersioningOption {
   Git = 'git',
   Directory = 'directory',
@@ -130,6 +136,8 @@ export interface Package {
   allContainersImagesWhitelisted?: boolean;
   signKey?: HelmChartSignKey;
   signatures?: Signature[];
+  signatureVerification?: SignatureVerification;
+  provenance?: Provenance;
   screenshots?: Screenshot[];
   productionOrganizationsCount?: number;
   relativePath?: string;
@@ -145,6 +153,23 @@ export interface HelmChartSignKey {
   url: string;
 }
 
+export interface SignatureVerification {
+  status: SignatureVerificationStatus;
+  kind?: Signature;
+  signer?: string;
+  fingerprint?: string;
+  issuer?: string;
+  error?: string;
+}
+
+export interface Provenance {
+  predicateType: string;
+  builderId: string;
+  sourceRepo?: string;
+  sourceCommit?: string;
+  buildLevel: number;
+}
+
 export interface PackageStats {
   subscriptions: number;
   webhooks: number;